@@ -0,0 +1,515 @@
+// Package main implements a Bitcoin SV multi-address balance aggregator.
+//
+// This tool reads a list of addresses and WIFs, looks up their confirmed and
+// unconfirmed balances on WhatsOnChain, and reports each balance along with
+// the totals. It is meant for auditing wallet exports and taking treasury
+// snapshots, where lists run to hundreds of addresses.
+//
+// Features:
+//   - Addresses or WIFs from arguments, a file, or stdin, one per line
+//   - Optional label after each address ("address,label")
+//   - Bulk lookups (20 addresses per request) run concurrently
+//   - Local cache of recent balances to avoid repeat lookups
+//   - Duplicates reported once and counted once in the totals
+//   - Non-zero exit code when any input is invalid or any lookup fails
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	balance <address> <address>             # Balances of a few addresses
+//	balance -f treasury.csv                 # Addresses (and labels) from a file
+//	cat wallet-export.txt | balance         # Addresses or WIFs from stdin
+//	balance -f treasury.csv --max-age 0     # Ignore cached balances
+//	balance -f treasury.csv -j              # JSON output
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// satoshisPerBSV is the number of satoshis in one BSV.
+const satoshisPerBSV = 100000000
+
+// Command-line flags
+var (
+	testnet    bool          // Use testnet instead of mainnet
+	jsonOutput bool          // Output in JSON format
+	inputFile  string        // File with one address or WIF per line
+	workers    int           // Concurrent bulk lookups
+	maxAge     time.Duration // Maximum age of a cached balance
+	cachePath  string        // Balance cache file (default: ~/.bsv-cmd-line-utils/cache/balance-<network>.json)
+	noColor    bool          // Disable colored output
+)
+
+// entry is the balance of one input line.
+type entry struct {
+	Line        int    `json:"line"`
+	Address     string `json:"address,omitempty"`
+	Label       string `json:"label,omitempty"`
+	Confirmed   int64  `json:"confirmed"`
+	Unconfirmed int64  `json:"unconfirmed"`
+	Cached      bool   `json:"cached,omitempty"`    // Balance came from the local cache
+	Duplicate   bool   `json:"duplicate,omitempty"` // Address appeared on an earlier line and is not counted again
+	Error       string `json:"error,omitempty"`
+}
+
+// report holds every entry and the totals across unique addresses.
+type report struct {
+	Network     string   `json:"network"`
+	Entries     []*entry `json:"entries"`
+	Addresses   int      `json:"addresses"` // Unique addresses counted in the totals
+	Confirmed   int64    `json:"confirmed"`
+	Unconfirmed int64    `json:"unconfirmed"`
+	Total       int64    `json:"total"`
+	Errors      int      `json:"errors"`
+}
+
+// cached is a balance stored in the cache file.
+type cached struct {
+	Confirmed   int64     `json:"confirmed"`
+	Unconfirmed int64     `json:"unconfirmed"`
+	Fetched     time.Time `json:"fetched"`
+}
+
+// balanceCache maps addresses to recently fetched balances.
+type balanceCache struct {
+	path    string
+	Entries map[string]*cached `json:"entries"`
+}
+
+// rootCmd is the main cobra command for the balance tool.
+var rootCmd = &cobra.Command{
+	Use:   "balance [address|wif...]",
+	Short: "Aggregate balances across many addresses and keys",
+	Long: `A command line tool that reports the confirmed and unconfirmed balance of each
+address or WIF in a list, and the totals across them. Inputs are read from
+arguments, --file, or stdin, one per line, optionally followed by a comma and a
+label. Blank lines and lines starting with # are skipped.
+
+Balances are cached locally for --max-age; exits non-zero if any input is
+invalid or any lookup fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	lines, err := getLines(args)
+	if err != nil {
+		return err
+	}
+
+	if len(lines) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no addresses or WIFs provided")
+	}
+
+	if workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	path := cachePath
+	if path == "" {
+		if path, err = defaultCachePath(testnet); err != nil {
+			return err
+		}
+	}
+	cache, err := loadCache(path)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain has bulk balance lookups, whatever the data provider
+	r := aggregate(ctx, provider.WOC.Client, cache, lines, time.Now())
+	r.Network = networkName(!testnet)
+
+	if maxAge > 0 {
+		if err = cache.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if jsonOutput {
+		if err = encodeJSON(r); err != nil {
+			return err
+		}
+	} else {
+		printReport(r)
+	}
+
+	if r.Errors > 0 {
+		return fmt.Errorf("%d of %d inputs failed", r.Errors, len(r.Entries))
+	}
+	return nil
+}
+
+// getLines returns the inputs from arguments, --file, or stdin.
+func getLines(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	if inputFile != "" {
+		f, err := os.Open(inputFile) //nolint:gosec // user-specified file
+		if err != nil {
+			return nil, fmt.Errorf("opening input file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		return readLines(f)
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return readLines(os.Stdin)
+	}
+	return nil, nil
+}
+
+// readLines reads lines from r, keeping blank and comment lines as empty
+// strings so that line numbers in the report match the input.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			line = ""
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a line into an address and label. The first field may be
+// an address or a WIF; a WIF is converted to the P2PKH address for its
+// compression flag.
+func parseLine(line string, mainnet bool) (address, label string, err error) {
+	field, label, _ := strings.Cut(line, ",")
+	field = strings.TrimSpace(field)
+	label = strings.TrimSpace(label)
+
+	if addr, err := script.NewAddressFromString(field); err == nil {
+		// Re-encoding validates the checksum and the network
+		for _, m := range []bool{mainnet, !mainnet} {
+			canonical, err := script.NewAddressFromPublicKeyHash(addr.PublicKeyHash, m)
+			if err != nil {
+				return "", "", err
+			}
+			if canonical.AddressString == field && m == mainnet {
+				return field, label, nil
+			}
+			if canonical.AddressString == field {
+				return "", "", fmt.Errorf("%s address, expected %s", networkName(m), networkName(mainnet))
+			}
+		}
+		return "", "", fmt.Errorf("invalid address checksum")
+	}
+
+	w, err := keys.ParseWIF(field)
+	if err != nil {
+		return "", "", fmt.Errorf("not a valid address or WIF")
+	}
+	if w.Testnet == mainnet {
+		return "", "", fmt.Errorf("%s WIF, expected %s", w.Network(), networkName(mainnet))
+	}
+	addr, err := w.Address()
+	if err != nil {
+		return "", "", fmt.Errorf("deriving address: %w", err)
+	}
+	return addr.AddressString, label, nil
+}
+
+// aggregate resolves every line to an address, fills balances from the cache
+// or WhatsOnChain, and totals them across unique addresses.
+func aggregate(ctx context.Context, client whatsonchain.ClientInterface, cache *balanceCache, lines []string, now time.Time) *report {
+	r := &report{}
+	seen := make(map[string]*entry)
+	var lookup []string
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		e := &entry{Line: i + 1}
+		r.Entries = append(r.Entries, e)
+
+		address, label, err := parseLine(line, !testnet)
+		if err != nil {
+			e.Error = err.Error()
+			continue
+		}
+		e.Address, e.Label = address, label
+
+		if _, ok := seen[address]; ok {
+			e.Duplicate = true
+			continue
+		}
+		seen[address] = e
+
+		if c, ok := cache.get(address, maxAge, now); ok {
+			e.Confirmed, e.Unconfirmed, e.Cached = c.Confirmed, c.Unconfirmed, true
+			continue
+		}
+		lookup = append(lookup, address)
+	}
+
+	balances, failures := fetchBalances(ctx, client, lookup, workers)
+	for address, b := range balances {
+		seen[address].Confirmed, seen[address].Unconfirmed = b.Confirmed, b.Unconfirmed
+		cache.put(address, b, now)
+	}
+	for address, err := range failures {
+		seen[address].Error = err.Error()
+	}
+
+	// Duplicates mirror the first occurrence but are not totaled
+	for _, e := range r.Entries {
+		if e.Duplicate {
+			first := seen[e.Address]
+			e.Confirmed, e.Unconfirmed, e.Cached, e.Error = first.Confirmed, first.Unconfirmed, first.Cached, first.Error
+			continue
+		}
+		if e.Error != "" {
+			r.Errors++
+			continue
+		}
+		r.Addresses++
+		r.Confirmed += e.Confirmed
+		r.Unconfirmed += e.Unconfirmed
+	}
+	r.Total = r.Confirmed + r.Unconfirmed
+	return r
+}
+
+// fetchBalances looks up addresses in bulk batches, running up to n batches
+// at once. It returns the balances found and an error for each address whose
+// lookup failed.
+func fetchBalances(ctx context.Context, client whatsonchain.ClientInterface, addresses []string, n int) (map[string]*whatsonchain.AddressBalance, map[string]error) {
+	balances := make(map[string]*whatsonchain.AddressBalance)
+	failures := make(map[string]error)
+
+	batches := make(chan []string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				records, err := client.BulkBalance(ctx, &whatsonchain.AddressList{Addresses: batch})
+
+				mu.Lock()
+				if err != nil {
+					for _, address := range batch {
+						failures[address] = fmt.Errorf("lookup failed: %w", err)
+					}
+				}
+				for _, rec := range records {
+					switch {
+					case rec == nil:
+					case rec.Error != "":
+						failures[rec.Address] = errors.New(rec.Error)
+					case rec.Balance != nil:
+						balances[rec.Address] = rec.Balance
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for start := 0; start < len(addresses); start += whatsonchain.MaxAddressesForLookup {
+		end := min(start+whatsonchain.MaxAddressesForLookup, len(addresses))
+		batches <- addresses[start:end]
+	}
+	close(batches)
+	wg.Wait()
+
+	// Addresses missing from a successful response have no balance
+	for _, address := range addresses {
+		if _, ok := balances[address]; !ok && failures[address] == nil {
+			failures[address] = fmt.Errorf("no balance returned")
+		}
+	}
+	return balances, failures
+}
+
+// defaultCachePath returns ~/.bsv-cmd-line-utils/cache/balance-<network>.json.
+func defaultCachePath(testnet bool) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".bsv-cmd-line-utils", "cache", "balance-"+networkName(!testnet)+".json"), nil
+}
+
+// loadCache reads the cache file at path. A missing file is an empty cache.
+func loadCache(path string) (*balanceCache, error) {
+	cache := &balanceCache{path: path, Entries: make(map[string]*cached)}
+
+	data, err := os.ReadFile(path) //nolint:gosec // user-specified file
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading balance cache: %w", err)
+	}
+	if err = json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("parsing balance cache %s: %w", path, err)
+	}
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]*cached)
+	}
+	return cache, nil
+}
+
+// get returns the cached balance of address if it is younger than age.
+func (c *balanceCache) get(address string, age time.Duration, now time.Time) (*cached, bool) {
+	entry, ok := c.Entries[address]
+	if !ok || now.Sub(entry.Fetched) >= age {
+		return nil, false
+	}
+	return entry, true
+}
+
+// put stores a freshly fetched balance.
+func (c *balanceCache) put(address string, b *whatsonchain.AddressBalance, now time.Time) {
+	c.Entries[address] = &cached{Confirmed: b.Confirmed, Unconfirmed: b.Unconfirmed, Fetched: now}
+}
+
+// save writes the cache file, creating its directory if needed.
+func (c *balanceCache) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("writing balance cache: %w", err)
+	}
+	return nil
+}
+
+// networkName names the network for error messages.
+func networkName(mainnet bool) string {
+	if mainnet {
+		return "mainnet"
+	}
+	return "testnet"
+}
+
+// formatBSV renders satoshis as BSV with eight decimal places.
+func formatBSV(satoshis int64) string {
+	sign := ""
+	if satoshis < 0 {
+		sign, satoshis = "-", -satoshis
+	}
+	return fmt.Sprintf("%s%d.%08d", sign, satoshis/satoshisPerBSV, satoshis%satoshisPerBSV)
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printReport prints a table of balances followed by the totals.
+func printReport(r *report) {
+	fmt.Printf("%s\n", c(colorDim, fmt.Sprintf("%-5s %-35s %17s %17s  %s", "LINE", "ADDRESS", "CONFIRMED", "UNCONFIRMED", "LABEL")))
+	for _, e := range r.Entries {
+		if e.Error != "" && e.Address == "" {
+			fmt.Printf("%-5d %s\n", e.Line, c(colorRed, e.Error))
+			continue
+		}
+
+		row := fmt.Sprintf("%-5d %-35s %17s %17s  %s", e.Line, e.Address, formatBSV(e.Confirmed), formatBSV(e.Unconfirmed), e.Label)
+		switch {
+		case e.Error != "":
+			fmt.Printf("%-5d %-35s %s\n", e.Line, e.Address, c(colorRed, e.Error))
+		case e.Duplicate:
+			fmt.Printf("%s\n", c(colorDim, row+" (duplicate)"))
+		default:
+			fmt.Println(strings.TrimRight(row, " "))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%s %d on %s\n", c(colorDim, "Addresses:  "), r.Addresses, r.Network)
+	fmt.Printf("%s %s BSV\n", c(colorDim, "Confirmed:  "), formatBSV(r.Confirmed))
+	fmt.Printf("%s %s BSV\n", c(colorDim, "Unconfirmed:"), formatBSV(r.Unconfirmed))
+	fmt.Printf("%s %s BSV (%d satoshis)\n", c(colorDim, "Total:      "), c(colorGreen, formatBSV(r.Total)), r.Total)
+	if r.Errors > 0 {
+		fmt.Printf("%s %s\n", c(colorDim, "Errors:     "), c(colorRed, fmt.Sprintf("%d (not included in totals)", r.Errors)))
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().StringVarP(&inputFile, "file", "f", "", "File with one address or WIF per line")
+	rootCmd.Flags().IntVarP(&workers, "workers", "c", 3, "Concurrent bulk lookups (20 addresses each)")
+	rootCmd.Flags().DurationVar(&maxAge, "max-age", 5*time.Minute, "Reuse cached balances younger than this (0 disables the cache)")
+	rootCmd.Flags().StringVar(&cachePath, "cache-file", "", "Balance cache file (default: ~/.bsv-cmd-line-utils/cache/balance-<network>.json)")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the balance command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWIF     = "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"
+	testAddress = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	otherAddr   = "1MfQjr97hKaAvpVPJ4XsaHQDskjYhXxLW3"
+)
+
+// fakeClient serves bulk balances and counts requests.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	mu       sync.Mutex
+	balances map[string]*whatsonchain.AddressBalance
+	requests int
+	fail     bool
+}
+
+func (f *fakeClient) BulkBalance(_ context.Context, list *whatsonchain.AddressList) (whatsonchain.AddressBalances, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+	if f.fail {
+		return nil, fmt.Errorf("service unavailable")
+	}
+	if len(list.Addresses) > whatsonchain.MaxAddressesForLookup {
+		return nil, fmt.Errorf("too many addresses: %d", len(list.Addresses))
+	}
+
+	var records whatsonchain.AddressBalances
+	for _, address := range list.Addresses {
+		if b, ok := f.balances[address]; ok {
+			records = append(records, &whatsonchain.AddressBalanceRecord{Address: address, Balance: b})
+		} else {
+			records = append(records, &whatsonchain.AddressBalanceRecord{Address: address, Error: "unknown address"})
+		}
+	}
+	return records, nil
+}
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		line    string
+		mainnet bool
+		address string
+		label   string
+		err     string
+	}{
+		{"address", testAddress, true, testAddress, "", ""},
+		{"address with label", testAddress + ", cold storage", true, testAddress, "cold storage", ""},
+		{"wif", testWIF, true, testAddress, "", ""},
+		{"uncompressed wif", "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf", true, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm", "", ""},
+		{"testnet wif", "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", false, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", "", ""},
+		{"wrong network", testAddress, false, "", "", "mainnet address, expected testnet"},
+		{"wif on the wrong network", testWIF, false, "", "", "mainnet WIF, expected testnet"},
+		{"bad checksum", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMx", true, "", "", "invalid address checksum"},
+		{"garbage", "hello", true, "", "", "not a valid address or WIF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			address, label, err := parseLine(tt.line, tt.mainnet)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.address, address)
+			assert.Equal(t, tt.label, label)
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeClient{balances: map[string]*whatsonchain.AddressBalance{
+		testAddress: {Confirmed: 150000000, Unconfirmed: -2000},
+		otherAddr:   {Confirmed: 5000, Unconfirmed: 0},
+	}}
+	cache := &balanceCache{Entries: make(map[string]*cached)}
+	now := time.Now()
+
+	lines := []string{testAddress + ",treasury", "", testWIF, otherAddr, "not-an-address"}
+	r := aggregate(context.Background(), client, cache, lines, now)
+
+	require.Len(t, r.Entries, 4)
+	assert.Equal(t, 3, r.Entries[1].Line)
+	assert.True(t, r.Entries[1].Duplicate)
+	assert.Equal(t, int64(150000000), r.Entries[1].Confirmed)
+	assert.Equal(t, "treasury", r.Entries[0].Label)
+	assert.NotEmpty(t, r.Entries[3].Error)
+
+	assert.Equal(t, 2, r.Addresses)
+	assert.Equal(t, int64(150005000), r.Confirmed)
+	assert.Equal(t, int64(-2000), r.Unconfirmed)
+	assert.Equal(t, int64(150003000), r.Total)
+	assert.Equal(t, 1, r.Errors)
+	assert.Equal(t, 1, client.requests)
+
+	// A second run is served from the cache until it expires
+	r = aggregate(context.Background(), client, cache, lines[:1], now.Add(time.Minute))
+	assert.True(t, r.Entries[0].Cached)
+	assert.Equal(t, 1, client.requests)
+
+	r = aggregate(context.Background(), client, cache, lines[:1], now.Add(maxAge))
+	assert.False(t, r.Entries[0].Cached)
+	assert.Equal(t, 2, client.requests)
+}
+
+func TestFetchBalances(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeClient{balances: make(map[string]*whatsonchain.AddressBalance)}
+	addresses := make([]string, 45)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("addr%d", i)
+		client.balances[addresses[i]] = &whatsonchain.AddressBalance{Confirmed: int64(i)}
+	}
+	addresses = append(addresses, "missing")
+
+	balances, failures := fetchBalances(context.Background(), client, addresses, 3)
+	assert.Len(t, balances, 45)
+	assert.Equal(t, int64(44), balances["addr44"].Confirmed)
+	require.Len(t, failures, 1)
+	require.ErrorContains(t, failures["missing"], "unknown address")
+	assert.Equal(t, 3, client.requests)
+
+	client.fail = true
+	balances, failures = fetchBalances(context.Background(), client, addresses[:2], 1)
+	assert.Empty(t, balances)
+	require.Len(t, failures, 2)
+	require.ErrorContains(t, failures["addr0"], "service unavailable")
+}
+
+func TestCacheFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache", "balance-mainnet.json")
+	cache, err := loadCache(path)
+	require.NoError(t, err)
+	assert.Empty(t, cache.Entries)
+
+	now := time.Now().Truncate(time.Second)
+	cache.put(testAddress, &whatsonchain.AddressBalance{Confirmed: 10, Unconfirmed: 2}, now)
+	require.NoError(t, cache.save())
+
+	loaded, err := loadCache(path)
+	require.NoError(t, err)
+	got, ok := loaded.get(testAddress, time.Minute, now)
+	require.True(t, ok)
+	assert.Equal(t, int64(10), got.Confirmed)
+	assert.Equal(t, int64(2), got.Unconfirmed)
+
+	_, ok = loaded.get(testAddress, time.Minute, now.Add(time.Hour))
+	assert.False(t, ok)
+}
+
+func TestReadLines(t *testing.T) {
+	t.Parallel()
+
+	lines, err := readLines(strings.NewReader(testAddress + "\n# comment\n\n  " + otherAddr + " \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testAddress, "", "", otherAddr}, lines)
+}
+
+func TestFormatBSV(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "1.50000000", formatBSV(150000000))
+	assert.Equal(t, "0.00000001", formatBSV(1))
+	assert.Equal(t, "-0.00002000", formatBSV(-2000))
+}
@@ -0,0 +1,449 @@
+// Package main implements a Bitcoin SV block statistics reporter.
+//
+// Given a block height or hash, this tool fetches the block's transactions
+// from WhatsOnChain and summarizes them: transaction count, total fees,
+// transaction size distribution, a breakdown of output script types, and the
+// largest transactions. Large blocks are sampled up to a transaction limit;
+// the report says how many transactions were analyzed.
+//
+// Features:
+//   - Block lookup by height or hash
+//   - Total fees from the coinbase value minus the block subsidy
+//   - Size distribution in buckets, with min/median/average/max
+//   - Output type breakdown (P2PKH, data, multisig, ...) with values
+//   - Largest transactions by size
+//   - Bulk raw transaction lookups (20 per request)
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	blockstats 800000                       # Block by height
+//	blockstats <hash>                       # Block by hash
+//	blockstats 800000 -n 20000 --top 20     # Analyze more, show more
+//	blockstats 800000 -j                    # JSON output
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Block subsidy schedule
+const (
+	initialSubsidy  = 50 * 100000000 // Satoshis paid by the first coinbase
+	halvingInterval = 210000         // Blocks between subsidy halvings
+)
+
+// sizeBuckets are the upper bounds, in bytes, of the size distribution
+// buckets. The last bucket has no upper bound.
+var sizeBuckets = []struct {
+	label string
+	max   int
+}{
+	{"< 250 B", 250},
+	{"250 B - 1 KB", 1000},
+	{"1 - 10 KB", 10000},
+	{"10 - 100 KB", 100000},
+	{"100 KB - 1 MB", 1000000},
+	{">= 1 MB", 0},
+}
+
+// Command-line flags
+var (
+	testnet    bool // Use testnet instead of mainnet
+	jsonOutput bool // Output in JSON format
+	limit      int  // Maximum number of transactions to analyze
+	top        int  // Number of largest transactions to list
+	noColor    bool // Disable colored output
+)
+
+// bucket is one range of the size distribution.
+type bucket struct {
+	Range string `json:"range"`
+	Count int    `json:"count"`
+}
+
+// outputType totals the outputs of one script template.
+type outputType struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	Value uint64 `json:"value"`
+}
+
+// txSummary describes one of the largest transactions.
+type txSummary struct {
+	TxID    string `json:"txid"`
+	Size    int    `json:"size"`
+	Inputs  int    `json:"inputs"`
+	Outputs int    `json:"outputs"`
+	Value   uint64 `json:"value"` // Total output value
+}
+
+// stats is the block report.
+type stats struct {
+	Hash       string `json:"hash"`
+	Height     int64  `json:"height"`
+	Time       int64  `json:"time"`
+	Miner      string `json:"miner,omitempty"`
+	Size       int64  `json:"size"`
+	TxCount    int64  `json:"txCount"`
+	Analyzed   int    `json:"analyzed"` // Transactions fetched and included below
+	Subsidy    uint64 `json:"subsidy"`
+	Coinbase   uint64 `json:"coinbaseValue"`
+	TotalFees  uint64 `json:"totalFees"`
+	FeePerTx   uint64 `json:"feePerTx"`   // Average fee over non-coinbase transactions in the block
+	MinSize    int    `json:"minSize"`    // Smallest analyzed transaction, in bytes
+	MedianSize int    `json:"medianSize"` // Median analyzed transaction size, in bytes
+	AvgSize    int    `json:"avgSize"`    // Average analyzed transaction size, in bytes
+	MaxSize    int    `json:"maxSize"`    // Largest analyzed transaction, in bytes
+	Inputs     int    `json:"inputs"`
+	Outputs    int    `json:"outputs"`
+
+	Sizes   []bucket      `json:"sizes"`
+	Types   []*outputType `json:"outputTypes"`
+	Largest []*txSummary  `json:"largest"`
+}
+
+// rootCmd is the main cobra command for the blockstats tool.
+var rootCmd = &cobra.Command{
+	Use:   "blockstats <height|hash>",
+	Short: "Summarize the transactions in a block",
+	Long: `A command line tool that fetches a block's transactions from WhatsOnChain and
+reports the transaction count, total fees, size distribution, output type
+breakdown, and largest transactions. At most --limit transactions are fetched;
+fees always cover the whole block since they come from the coinbase.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("a block height or hash is required")
+	}
+
+	if limit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+	if top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain pages through a block's transactions, whatever the data provider
+	client := provider.WOC.Client
+	info, err := fetchBlock(ctx, client, strings.TrimSpace(args[0]))
+	if err != nil {
+		return err
+	}
+
+	txs, err := fetchTransactions(ctx, client, info, limit)
+	if err != nil {
+		return err
+	}
+
+	s := analyze(info, txs, !testnet, top)
+	if jsonOutput {
+		return encodeJSON(s)
+	}
+	printStats(s)
+	return nil
+}
+
+// fetchBlock looks up a block by height or hash.
+func fetchBlock(ctx context.Context, client whatsonchain.ClientInterface, id string) (*whatsonchain.BlockInfo, error) {
+	var info *whatsonchain.BlockInfo
+	var err error
+
+	switch {
+	case len(id) == 64 && cli.IsValidHex(id):
+		info, err = client.GetBlockByHash(ctx, id)
+	default:
+		height, perr := strconv.ParseInt(id, 10, 64)
+		if perr != nil || height < 0 {
+			return nil, fmt.Errorf("invalid block height or hash: %s", id)
+		}
+		info, err = client.GetBlockByHeight(ctx, height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching block: %w", err)
+	}
+	if info == nil || info.Hash == "" {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+	return info, nil
+}
+
+// fetchTransactions fetches up to maxTxs of the block's transactions, in block
+// order. Txids beyond the first page are listed with the block pages endpoint.
+func fetchTransactions(ctx context.Context, client whatsonchain.ClientInterface, info *whatsonchain.BlockInfo, maxTxs int) ([]*transaction.Transaction, error) {
+	txids := append([]string(nil), info.Tx...)
+	for page := 1; len(txids) < maxTxs && page <= len(info.Pages.URI); page++ {
+		more, err := client.GetBlockPages(ctx, info.Hash, page)
+		if err != nil {
+			return nil, fmt.Errorf("fetching block page %d: %w", page, err)
+		}
+		if len(more) == 0 {
+			break
+		}
+		txids = append(txids, more...)
+	}
+	if len(txids) > maxTxs {
+		txids = txids[:maxTxs]
+	}
+
+	txs := make([]*transaction.Transaction, 0, len(txids))
+	for start := 0; start < len(txids); start += whatsonchain.MaxTransactionsRaw {
+		end := min(start+whatsonchain.MaxTransactionsRaw, len(txids))
+		batch := txids[start:end]
+
+		list, err := client.BulkRawTransactionData(ctx, &whatsonchain.TxHashes{TxIDs: batch})
+		if err != nil {
+			return nil, fmt.Errorf("fetching transactions: %w", err)
+		}
+
+		// Responses are matched by txid, not position
+		byID := make(map[string]string, len(list))
+		for _, t := range list {
+			if t != nil {
+				byID[t.TxID] = t.Hex
+			}
+		}
+		for _, txid := range batch {
+			raw, ok := byID[txid]
+			if !ok || raw == "" {
+				return nil, fmt.Errorf("transaction %s missing from response", txid)
+			}
+			tx, err := transaction.NewTransactionFromHex(raw)
+			if err != nil {
+				return nil, fmt.Errorf("parsing transaction %s: %w", txid, err)
+			}
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// subsidy returns the block reward, excluding fees, at height.
+func subsidy(height int64) uint64 {
+	halvings := height / halvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+	return initialSubsidy >> uint(halvings)
+}
+
+// analyze builds the block report from the fetched transactions. The first
+// transaction must be the coinbase for fees to be computed.
+func analyze(info *whatsonchain.BlockInfo, txs []*transaction.Transaction, mainnet bool, topN int) *stats {
+	s := &stats{
+		Hash:     info.Hash,
+		Height:   info.Height,
+		Time:     info.Time,
+		Miner:    info.Miner,
+		Size:     info.Size,
+		TxCount:  info.TxCount,
+		Analyzed: len(txs),
+		Subsidy:  subsidy(info.Height),
+	}
+	if s.TxCount == 0 {
+		s.TxCount = int64(len(txs))
+	}
+
+	if len(txs) > 0 && txs[0].IsCoinbase() {
+		s.Coinbase = txs[0].TotalOutputSatoshis()
+		if s.Coinbase > s.Subsidy {
+			s.TotalFees = s.Coinbase - s.Subsidy
+		}
+		if s.TxCount > 1 {
+			s.FeePerTx = s.TotalFees / uint64(s.TxCount-1)
+		}
+	}
+
+	s.Sizes = make([]bucket, len(sizeBuckets))
+	for i, b := range sizeBuckets {
+		s.Sizes[i].Range = b.label
+	}
+
+	types := make(map[string]*outputType)
+	sizes := make([]int, 0, len(txs))
+	summaries := make([]*txSummary, 0, len(txs))
+	total := 0
+	for _, tx := range txs {
+		size := tx.Size()
+		sizes = append(sizes, size)
+		total += size
+		s.Sizes[bucketFor(size)].Count++
+		s.Inputs += len(tx.Inputs)
+		s.Outputs += len(tx.Outputs)
+
+		for _, out := range tx.Outputs {
+			typ := scripts.TemplateEmpty
+			if out.LockingScript != nil {
+				typ = scripts.Classify(out.LockingScript.Bytes(), mainnet).Type
+			}
+			t, ok := types[typ]
+			if !ok {
+				t = &outputType{Type: typ}
+				types[typ] = t
+			}
+			t.Count++
+			t.Value += out.Satoshis
+		}
+
+		summaries = append(summaries, &txSummary{
+			TxID:    tx.TxID().String(),
+			Size:    size,
+			Inputs:  len(tx.Inputs),
+			Outputs: len(tx.Outputs),
+			Value:   tx.TotalOutputSatoshis(),
+		})
+	}
+
+	if len(sizes) > 0 {
+		sort.Ints(sizes)
+		s.MinSize = sizes[0]
+		s.MaxSize = sizes[len(sizes)-1]
+		s.MedianSize = sizes[len(sizes)/2]
+		s.AvgSize = total / len(sizes)
+	}
+
+	for _, t := range types {
+		s.Types = append(s.Types, t)
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		if s.Types[i].Count != s.Types[j].Count {
+			return s.Types[i].Count > s.Types[j].Count
+		}
+		return s.Types[i].Type < s.Types[j].Type
+	})
+
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Size > summaries[j].Size })
+	if len(summaries) > topN {
+		summaries = summaries[:topN]
+	}
+	s.Largest = summaries
+	return s
+}
+
+// bucketFor returns the index of the size bucket holding size.
+func bucketFor(size int) int {
+	for i, b := range sizeBuckets {
+		if b.max == 0 || size < b.max {
+			return i
+		}
+	}
+	return len(sizeBuckets) - 1
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printStats prints a human-readable block report.
+func printStats(s *stats) {
+	fmt.Printf("%s %s\n", c(colorDim, "Block:       "), s.Hash)
+	fmt.Printf("%s %d\n", c(colorDim, "Height:      "), s.Height)
+	fmt.Printf("%s %s\n", c(colorDim, "Time:        "), time.Unix(s.Time, 0).UTC().Format(time.RFC3339))
+	if s.Miner != "" {
+		fmt.Printf("%s %s\n", c(colorDim, "Miner:       "), s.Miner)
+	}
+	fmt.Printf("%s %d bytes\n", c(colorDim, "Size:        "), s.Size)
+	fmt.Printf("%s %d\n", c(colorDim, "Transactions:"), s.TxCount)
+	fmt.Printf("%s %s satoshis (subsidy %d, coinbase %d)\n", c(colorDim, "Total fees:  "), c(colorGreen, strconv.FormatUint(s.TotalFees, 10)), s.Subsidy, s.Coinbase)
+	fmt.Printf("%s %d satoshis\n", c(colorDim, "Fee per tx:  "), s.FeePerTx)
+
+	fmt.Println()
+	if int64(s.Analyzed) < s.TxCount {
+		fmt.Println(c(colorDim, fmt.Sprintf("Analyzed the first %d of %d transactions (raise --limit for more)", s.Analyzed, s.TxCount)))
+		fmt.Println()
+	}
+
+	fmt.Printf("Transaction sizes (%d inputs, %d outputs)\n", s.Inputs, s.Outputs)
+	fmt.Printf("  min %d / median %d / avg %d / max %d bytes\n", s.MinSize, s.MedianSize, s.AvgSize, s.MaxSize)
+	for _, b := range s.Sizes {
+		fmt.Printf("  %-15s %8d  %s\n", b.Range, b.Count, bar(b.Count, s.Analyzed))
+	}
+
+	fmt.Println()
+	fmt.Println("Output types")
+	for _, t := range s.Types {
+		fmt.Printf("  %-15s %8d  %d satoshis\n", t.Type, t.Count, t.Value)
+	}
+
+	fmt.Println()
+	fmt.Println("Largest transactions")
+	for _, t := range s.Largest {
+		fmt.Printf("  %s  %9d bytes  %5d in  %5d out\n", t.TxID, t.Size, t.Inputs, t.Outputs)
+	}
+}
+
+// bar renders count as a proportion of total, up to 30 characters wide.
+func bar(count, total int) string {
+	if total == 0 || count == 0 {
+		return ""
+	}
+	return c(colorGreen, strings.Repeat("█", max(1, count*30/total)))
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().IntVarP(&limit, "limit", "n", 5000, "Maximum number of transactions to fetch and analyze")
+	rootCmd.Flags().IntVar(&top, "top", 10, "Number of largest transactions to list")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the blockstats command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
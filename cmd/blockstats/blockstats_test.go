@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+const (
+	// genesisCoinbase is the raw coinbase transaction of the genesis block.
+	genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	genesisTxID     = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testHash        = "00000000000000000000000000000000000000000000000000000000000000aa"
+)
+
+// fakeClient serves one block, its pages, and its raw transactions.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	block    *whatsonchain.BlockInfo
+	pages    map[int][]string
+	raw      map[string]string
+	requests int
+}
+
+func (f *fakeClient) GetBlockByHeight(_ context.Context, height int64) (*whatsonchain.BlockInfo, error) {
+	if f.block.Height != height {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	return f.block, nil
+}
+
+func (f *fakeClient) GetBlockByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	if f.block.Hash != hash {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return f.block, nil
+}
+
+func (f *fakeClient) GetBlockPages(_ context.Context, _ string, page int) (whatsonchain.BlockPagesInfo, error) {
+	return f.pages[page], nil
+}
+
+func (f *fakeClient) BulkRawTransactionData(_ context.Context, hashes *whatsonchain.TxHashes) (whatsonchain.TxList, error) {
+	f.requests++
+	var list whatsonchain.TxList
+	// Reverse the order to check that responses are matched by txid
+	for i := len(hashes.TxIDs) - 1; i >= 0; i-- {
+		txid := hashes.TxIDs[i]
+		list = append(list, &whatsonchain.TxInfo{TxID: txid, Hex: f.raw[txid]})
+	}
+	return list, nil
+}
+
+// testSpend returns a transaction with a P2PKH output of n*1000 satoshis, a
+// data output, and an unlocking script of padding bytes.
+func testSpend(t *testing.T, n byte, padding int) *transaction.Transaction {
+	t.Helper()
+
+	source, err := chainhash.NewHashFromHex(genesisTxID)
+	require.NoError(t, err)
+	unlock := script.Script(make([]byte, padding))
+	lock, err := script.NewFromHex("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+	require.NoError(t, err)
+	data, err := script.NewFromHex(fmt.Sprintf("006a01%02x", n))
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	tx.Inputs = []*transaction.TransactionInput{{SourceTXID: source, UnlockingScript: &unlock, SequenceNumber: 0xffffffff}}
+	tx.Outputs = []*transaction.TransactionOutput{
+		{Satoshis: 1000 * uint64(n), LockingScript: lock},
+		{Satoshis: 0, LockingScript: data},
+	}
+	return tx
+}
+
+// testBlock returns a fake block at height 0 holding the genesis coinbase and
+// the given transactions, with the last txids served from page 1.
+func testBlock(t *testing.T, paged int, txs ...*transaction.Transaction) *fakeClient {
+	t.Helper()
+
+	client := &fakeClient{
+		block: &whatsonchain.BlockInfo{Hash: testHash, Height: 0, TxCount: int64(len(txs) + 1), Size: 1000},
+		raw:   map[string]string{genesisTxID: genesisCoinbase},
+		pages: make(map[int][]string),
+	}
+	txids := []string{genesisTxID}
+	for _, tx := range txs {
+		txid := tx.TxID().String()
+		client.raw[txid] = tx.String()
+		txids = append(txids, txid)
+	}
+	split := len(txids) - paged
+	client.block.Tx = txids[:split]
+	if paged > 0 {
+		client.block.Pages.URI = []string{"/block/hash/" + testHash + "/page/1"}
+		client.pages[1] = txids[split:]
+	}
+	return client
+}
+
+func TestSubsidy(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, uint64(5000000000), subsidy(0))
+	assert.Equal(t, uint64(5000000000), subsidy(209999))
+	assert.Equal(t, uint64(2500000000), subsidy(210000))
+	assert.Equal(t, uint64(625000000), subsidy(630000))
+	assert.Equal(t, uint64(0), subsidy(64*210000))
+}
+
+func TestBucketFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, bucketFor(249))
+	assert.Equal(t, 1, bucketFor(250))
+	assert.Equal(t, 2, bucketFor(9999))
+	assert.Equal(t, 5, bucketFor(5000000))
+}
+
+func TestFetchBlock(t *testing.T) {
+	t.Parallel()
+
+	client := testBlock(t, 0)
+
+	info, err := fetchBlock(context.Background(), client, "0")
+	require.NoError(t, err)
+	assert.Equal(t, testHash, info.Hash)
+
+	info, err = fetchBlock(context.Background(), client, testHash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Height)
+
+	_, err = fetchBlock(context.Background(), client, "-1")
+	require.ErrorContains(t, err, "invalid block height or hash")
+
+	_, err = fetchBlock(context.Background(), client, "12")
+	require.ErrorContains(t, err, "fetching block")
+}
+
+func TestFetchTransactions(t *testing.T) {
+	t.Parallel()
+
+	spends := make([]*transaction.Transaction, 25)
+	for i := range spends {
+		spends[i] = testSpend(t, byte(i+1), 100)
+	}
+	client := testBlock(t, 5, spends...)
+
+	txs, err := fetchTransactions(context.Background(), client, client.block, 100)
+	require.NoError(t, err)
+	require.Len(t, txs, 26)
+	assert.Equal(t, genesisTxID, txs[0].TxID().String())
+	assert.Equal(t, spends[24].TxID().String(), txs[25].TxID().String())
+	assert.Equal(t, 2, client.requests)
+
+	// The limit stops before the paged txids are needed
+	txs, err = fetchTransactions(context.Background(), client, client.block, 3)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+	assert.Equal(t, spends[1].TxID().String(), txs[2].TxID().String())
+
+	delete(client.raw, genesisTxID)
+	_, err = fetchTransactions(context.Background(), client, client.block, 3)
+	require.ErrorContains(t, err, "missing from response")
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	coinbase, err := transaction.NewTransactionFromHex(genesisCoinbase)
+	require.NoError(t, err)
+	small := testSpend(t, 1, 10)
+	large := testSpend(t, 2, 2000)
+	client := testBlock(t, 0, small, large)
+	client.block.TxCount = 10
+
+	s := analyze(client.block, []*transaction.Transaction{coinbase, small, large}, true, 2)
+
+	assert.Equal(t, 3, s.Analyzed)
+	assert.Equal(t, int64(10), s.TxCount)
+	assert.Equal(t, uint64(5000000000), s.Subsidy)
+	assert.Equal(t, uint64(5000000000), s.Coinbase)
+	assert.Zero(t, s.TotalFees)
+	assert.Equal(t, 3, s.Inputs)
+	assert.Equal(t, 5, s.Outputs)
+
+	assert.Equal(t, small.Size(), s.MinSize)
+	assert.Equal(t, large.Size(), s.MaxSize)
+	assert.Equal(t, coinbase.Size(), s.MedianSize)
+	assert.Equal(t, 2, s.Sizes[0].Count)
+	assert.Equal(t, 1, s.Sizes[2].Count)
+
+	require.Len(t, s.Types, 3)
+	assert.Equal(t, &outputType{Type: scripts.TemplateData, Count: 2, Value: 0}, s.Types[0])
+	assert.Equal(t, &outputType{Type: scripts.TemplateP2PKH, Count: 2, Value: 3000}, s.Types[1])
+	assert.Equal(t, scripts.TemplateP2PK, s.Types[2].Type)
+
+	require.Len(t, s.Largest, 2)
+	assert.Equal(t, large.TxID().String(), s.Largest[0].TxID)
+	assert.Equal(t, genesisTxID, s.Largest[1].TxID)
+}
+
+func TestAnalyzeFees(t *testing.T) {
+	t.Parallel()
+
+	// A coinbase claiming more than the subsidy collected the difference in fees
+	coinbase, err := transaction.NewTransactionFromHex(genesisCoinbase)
+	require.NoError(t, err)
+	coinbase.Outputs[0].Satoshis = 5000000000 + 12345
+
+	info := &whatsonchain.BlockInfo{Hash: testHash, TxCount: 6}
+	s := analyze(info, []*transaction.Transaction{coinbase}, true, 10)
+	assert.Equal(t, uint64(12345), s.TotalFees)
+	assert.Equal(t, uint64(12345/5), s.FeePerTx)
+}
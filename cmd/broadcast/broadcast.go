@@ -2,12 +2,24 @@
 //
 // This tool broadcasts raw Bitcoin transactions to the BSV network via ARC endpoints
 // and optionally monitors their status until they reach a final state (MINED, REJECTED, etc.).
+// A miner's legacy mAPI endpoint, WhatsOnChain, Bitails, or an SV Node can be
+// selected as the broadcaster in config.yaml.
+// With --listen, it instead receives the status updates ARC pushes to a callback URL.
+// With --jobs, it reads a JSON array of broadcast jobs from stdin and prints a
+// matching JSON array of results, for workflow engines driving many broadcasts.
 //
 // Features:
 //   - Config-based mainnet/testnet endpoint management via config.yaml
 //   - Real-time transaction status monitoring with customizable polling
+//   - Monitoring follows config.yaml, reloaded when it changes or on SIGHUP
 //   - Support for stdin or command-line input
 //   - Automatic transaction lifecycle tracking
+//   - Raw, Extended Format (EF), or BEEF input; BEEF is validated before sending
+//   - Built-in ARC callback receiver (--listen) for push status updates
+//   - Idempotent retries: --skip-mined exits successfully if the transaction is already mined
+//   - mAPI broadcasting with signed-envelope verification, status monitoring, and fee quotes
+//   - Local policy pre-validation (sizes, dust, duplicate and non-push inputs) with clear messages
+//   - JSON job documents (--jobs) with a per-transaction callback URL, ARC wait-for status, and labels
 //
 // Usage:
 //
@@ -15,27 +27,86 @@
 //	broadcast -r "010000..."                  # Broadcast using flag
 //	broadcast -t -m                           # Testnet with monitoring
 //	broadcast -m -p 10                        # Monitor with 10s poll rate
+//	broadcast --skip-mined -r "010000..."     # Safe to retry: skips mined transactions
+//	broadcast --listen :8080 --callback-url https://my.host/callback  # Receive ARC callbacks
+//	broadcast --fee-quote                     # Show the mAPI miner's fee quote
+//	broadcast --no-policy-check -r "010000..." # Send even if local policy checks fail
+//	broadcast --jobs < jobs.json              # Broadcast a JSON job array, print JSON results
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/bsv-blockchain/go-sdk/transaction"
 	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/chain"
 	"github.com/mrz1836/go-template/internal/cli"
 	"github.com/mrz1836/go-template/internal/config"
+	"github.com/mrz1836/go-template/internal/mapi"
+	"github.com/mrz1836/go-template/internal/spv"
+	"github.com/mrz1836/go-template/internal/txinspect"
+	"github.com/mrz1836/go-whatsonchain"
 	"github.com/spf13/cobra"
 )
 
 // Command-line flags
 var (
-	testnet  bool   // Use testnet instead of mainnet
-	raw      string // Raw transaction hex provided via flag
-	monitor  bool   // Enable transaction status monitoring
-	pollRate int    // Polling interval in seconds for monitoring
+	testnet   bool   // Use testnet instead of mainnet
+	raw       string // Raw transaction hex provided via flag
+	monitor   bool   // Enable transaction status monitoring
+	pollRate  int    // Polling interval in seconds for monitoring
+	listen    string // Address to receive ARC callbacks on (e.g. ":8080")
+	callback  string // Public callback URL registered with ARC (default: derived from --listen)
+	skipMined bool   // Skip broadcasting if the transaction is already mined
+	feeQuote  bool   // Print the mAPI miner's fee quote instead of broadcasting
+	noPolicy  bool   // Skip the local policy checks before sending
+	jobs      bool   // Read a JSON array of broadcast jobs from stdin and print JSON results
 )
 
+// waitForStatuses are the ARC statuses a job can ask ARC to wait for.
+var waitForStatuses = map[string]bool{
+	arc.StatusReceived:           true,
+	arc.StatusStored:             true,
+	arc.StatusAnnouncedToNetwork: true,
+	arc.StatusSeenOnNetwork:      true,
+	arc.StatusMined:              true,
+}
+
+// job is one entry of a --jobs document.
+type job struct {
+	RawTx         string            `json:"rawtx"`                   // Raw, EF, or BEEF hex
+	Callback      string            `json:"callback,omitempty"`      // URL ARC posts status updates to
+	CallbackToken string            `json:"callbackToken,omitempty"` // Bearer token ARC sends to the callback
+	WaitFor       string            `json:"waitFor,omitempty"`       // ARC status to reach before ARC answers
+	Labels        map[string]string `json:"labels,omitempty"`        // Passed through to the result
+}
+
+// jobResult is the outcome of the job at the same index of a --jobs document.
+type jobResult struct {
+	TxID   string            `json:"txid,omitempty"`
+	Status string            `json:"status,omitempty"`
+	Info   string            `json:"info,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// minedLookup returns the height txid is mined at and which service reported
+// it, or 0 if it is not mined.
+type minedLookup func(txid string) (int64, string, error)
+
 // rootCmd is the main cobra command for the broadcast tool.
 var rootCmd = &cobra.Command{
 	Use:   "broadcast",
@@ -50,18 +121,48 @@ var rootCmd = &cobra.Command{
 // 1. Loads configuration from config.yaml
 // 2. Reads transaction hex from flag or stdin
 // 3. Validates the hex string
-// 4. Broadcasts the transaction to ARC
+// 4. Broadcasts the transaction through the configured broadcaster (ARC by default)
 func run() error {
+	ctx := context.Background()
+
 	// Load configuration from config.yaml
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("loading configuration: %w", err)
 	}
 
-	// Validate config
-	if err := cfg.Validate(testnet); err != nil {
+	provider, err := chain.New(ctx, cfg, testnet)
+	if err != nil {
 		return err
 	}
+	if monitor && listen != "" {
+		return fmt.Errorf("--monitor and --listen cannot be used together")
+	}
+	_, isARC := provider.Broadcaster.(*chain.ARC)
+	mapiBroadcaster, isMAPI := provider.Broadcaster.(*chain.MAPI)
+	if listen != "" && !isARC {
+		return fmt.Errorf("--listen requires the ARC broadcaster with a URL in config.yaml")
+	}
+	if monitor && !isARC && !isMAPI {
+		return fmt.Errorf("--monitor requires the ARC or mAPI broadcaster with a URL in config.yaml")
+	}
+	if jobs {
+		if raw != "" || monitor || listen != "" || feeQuote {
+			return fmt.Errorf("--jobs cannot be used with --raw, --monitor, --listen, or --fee-quote")
+		}
+		return runJobs(ctx, provider, os.Stdin)
+	}
+	if feeQuote {
+		if !isMAPI {
+			return fmt.Errorf("--fee-quote requires the mAPI broadcaster in config.yaml")
+		}
+		quote, err := mapiBroadcaster.Client.GetFeeQuote()
+		if err != nil {
+			return fmt.Errorf("fetching fee quote: %w", err)
+		}
+		printFeeQuote(quote)
+		return nil
+	}
 
 	// Get transaction from raw flag or stdin
 	txString, err := getTransactionHex()
@@ -78,10 +179,138 @@ func run() error {
 		return fmt.Errorf("input is not a valid hex string")
 	}
 
+	txid, err := checkTransaction(os.Stdout, txString)
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf("Transaction hex: %s\n", txString)
 
-	// Broadcast transaction using ARC
-	return broadcastTransaction(cfg, txString)
+	if skipMined {
+		height, source, err := newMinedLookup(ctx, provider)(txid)
+		if err != nil {
+			// Broadcasting a mined transaction is refused, not harmful, so carry on
+			fmt.Fprintf(os.Stderr, "Warning: could not check whether the transaction is mined: %v\n", err)
+		} else if height > 0 {
+			fmt.Printf("✓ Transaction already mined at height %d (per %s); not broadcasting\n", height, source)
+			return nil
+		}
+	}
+
+	if listen != "" {
+		return broadcastWithCallbacks(ctx, provider.Broadcaster.(*chain.ARC), txString, txid)
+	}
+	return broadcastTransaction(ctx, provider.Broadcaster, txString)
+}
+
+// runJobs broadcasts each job of the JSON array read from r in order and
+// prints a JSON array with the result of each at the same index. A failed job
+// does not stop the others; the run fails afterwards if any did.
+func runJobs(ctx context.Context, provider *chain.Provider, r io.Reader) error {
+	list, err := readJobs(r)
+	if err != nil {
+		return err
+	}
+
+	broadcaster := provider.Broadcaster
+	var mined minedLookup
+	if skipMined {
+		mined = newMinedLookup(ctx, provider)
+	}
+
+	results := make([]jobResult, len(list))
+	failed := 0
+	for i, j := range list {
+		results[i].Labels = j.Labels
+		resp, err := broadcastJob(ctx, broadcaster, mined, j)
+		if err != nil {
+			results[i].Error = err.Error()
+			failed++
+			continue
+		}
+		results[i].TxID = resp.TxID
+		results[i].Status = resp.Status
+		results[i].Info = resp.Info
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed", failed, len(list))
+	}
+	return nil
+}
+
+// readJobs decodes a --jobs document. Unknown fields are refused, so a
+// misspelled option fails rather than being silently ignored.
+func readJobs(r io.Reader) ([]job, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var list []job
+	if err := dec.Decode(&list); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no jobs provided")
+		}
+		return nil, fmt.Errorf("parsing jobs: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no jobs provided")
+	}
+	return list, nil
+}
+
+// broadcastJob checks and broadcasts one job's transaction, registering its
+// callback and wait-for status with ARC. With mined set, a transaction that
+// is already mined is reported as MINED without being sent.
+func broadcastJob(ctx context.Context, broadcaster chain.Broadcaster, mined minedLookup, j job) (*chain.BroadcastResult, error) {
+	rawTx := strings.TrimSpace(j.RawTx)
+	if rawTx == "" {
+		return nil, fmt.Errorf("no transaction provided")
+	}
+	if !cli.IsValidHex(rawTx) {
+		return nil, fmt.Errorf("rawtx is not a valid hex string")
+	}
+
+	arcBroadcaster, isARC := broadcaster.(*chain.ARC)
+	if (j.Callback != "" || j.WaitFor != "") && !isARC {
+		return nil, fmt.Errorf("callback and waitFor require the ARC broadcaster")
+	}
+	waitFor := strings.ToUpper(j.WaitFor)
+	if waitFor != "" && !waitForStatuses[waitFor] {
+		return nil, fmt.Errorf("unknown waitFor status %q", j.WaitFor)
+	}
+
+	// Progress notes go to stderr, keeping stdout for the results
+	txid, err := checkTransaction(os.Stderr, rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if mined != nil {
+		height, source, err := mined(txid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not check whether %s is mined: %v\n", txid, err)
+		} else if height > 0 {
+			return &chain.BroadcastResult{
+				TxID:   txid,
+				Status: arc.StatusMined,
+				Info:   fmt.Sprintf("already mined at height %d (per %s); not broadcast", height, source),
+			}, nil
+		}
+	}
+
+	if isARC {
+		arcBroadcaster.Client.SetCallback(j.Callback, j.CallbackToken)
+		arcBroadcaster.Client.SetWaitFor(waitFor)
+	}
+	resp, err := broadcaster.Broadcast(ctx, rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting transaction: %w", err)
+	}
+	return resp, nil
 }
 
 // getTransactionHex reads transaction hex from flag or stdin.
@@ -92,51 +321,287 @@ func getTransactionHex() (string, error) {
 	return cli.ReadHexFromReader(os.Stdin)
 }
 
-// broadcastTransaction sends a raw transaction to the ARC network.
-// It selects the appropriate endpoint (mainnet/testnet) based on the --testnet flag,
-// creates an ARC client, broadcasts the transaction, and displays the result.
-// If --monitor flag is set, it will continuously poll the transaction status.
-func broadcastTransaction(cfg *config.Config, rawTx string) error {
-	arcConfig := cfg.GetARCConfig(testnet)
+// checkTransaction parses the transaction before it is sent, so malformed input
+// fails locally, and returns its txid, noting its format to w. BEEF must also
+// carry a complete, consistent ancestry, and unless --no-policy-check is set
+// the transaction must pass checkPolicy.
+func checkTransaction(w io.Writer, txHex string) (string, error) {
+	b, err := hex.DecodeString(txHex)
+	if err != nil {
+		return "", fmt.Errorf("decoding hex: %w", err)
+	}
+
+	tx, format, err := spv.ParseTransaction(b)
+	if err != nil {
+		return "", err
+	}
+	txid := tx.TxID().String()
+	fmt.Fprintf(w, "Format: %s, TxID: %s\n", strings.ToUpper(format), txid)
 
+	if format == spv.FormatBEEF {
+		report, err := spv.ValidateBEEF(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid BEEF: %w", err)
+		}
+		fmt.Fprintf(w, "BEEF: %d transaction(s), %d BUMP(s)\n", report.Transactions, report.BUMPs)
+	}
+
+	if !noPolicy {
+		if err = checkPolicy(tx); err != nil {
+			return "", err
+		}
+	}
+	return txid, nil
+}
+
+// checkPolicy refuses a transaction that SV Node's default policy rejects,
+// naming every rule it breaks, since ARC's errors for these cases are often
+// terse or generic.
+func checkPolicy(tx *transaction.Transaction) error {
+	violations := txinspect.PolicyViolations(tx)
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("transaction breaks node policy (use --no-policy-check to send anyway):\n  %s", strings.Join(violations, "\n  "))
+}
+
+// newMinedLookup returns a minedLookup that asks ARC, when it is the
+// broadcaster, and the provider's WhatsOnChain.
+func newMinedLookup(ctx context.Context, provider *chain.Provider) minedLookup {
+	var arcClient *arc.ARCClient
+	if arcBroadcaster, ok := provider.Broadcaster.(*chain.ARC); ok {
+		arcClient = arcBroadcaster.Client
+	}
+	return func(txid string) (int64, string, error) {
+		return minedHeight(ctx, arcClient, provider.WOC.Client, txid)
+	}
+}
+
+// minedHeight looks txid up in ARC, when it is the broadcaster, and then in
+// WhatsOnChain, which also knows transactions broadcast elsewhere. It returns
+// the block height and which service reported it, or 0 if the transaction is
+// not mined or not known.
+func minedHeight(ctx context.Context, arcClient *arc.ARCClient, woc whatsonchain.ClientInterface, txid string) (int64, string, error) {
+	// ARC only reports transactions submitted to it, so any error falls through
+	if arcClient != nil {
+		status, err := arcClient.GetTransactionStatus(txid)
+		if err == nil && status.TxStatus == arc.StatusMined && status.BlockHeight > 0 {
+			return status.BlockHeight, "ARC", nil
+		}
+	}
+
+	info, err := woc.GetTxByHash(ctx, txid)
+	if err != nil {
+		if errors.Is(err, whatsonchain.ErrTransactionNotFound) {
+			return 0, "", nil
+		}
+		if last := woc.LastRequest(); last != nil && last.StatusCode == http.StatusNotFound {
+			return 0, "", nil
+		}
+		return 0, "", fmt.Errorf("looking up %s on WhatsOnChain: %w", txid, err)
+	}
+	if info.Confirmations > 0 && info.BlockHeight > 0 {
+		return info.BlockHeight, "WhatsOnChain", nil
+	}
+	return 0, "", nil
+}
+
+// broadcastTransaction sends a raw transaction through the broadcaster selected
+// for the network (mainnet/testnet) and displays the result.
+// If --monitor flag is set, it will continuously poll the transaction status from ARC.
+func broadcastTransaction(ctx context.Context, broadcaster chain.Broadcaster, rawTx string) error {
 	if testnet {
 		fmt.Println("Using testnet configuration")
 	} else {
 		fmt.Println("Using mainnet configuration")
 	}
 
-	// Create ARC client
-	client := arc.NewARCClient(arcConfig.URL, arcConfig.APIKey)
-
-	fmt.Println("Broadcasting transaction to ARC...")
+	arcBroadcaster, isARC := broadcaster.(*chain.ARC)
+	mapiBroadcaster, isMAPI := broadcaster.(*chain.MAPI)
+	switch {
+	case isARC:
+		fmt.Println("Broadcasting transaction to ARC...")
+	case isMAPI:
+		fmt.Println("Broadcasting transaction to mAPI...")
+	default:
+		fmt.Println("Broadcasting transaction...")
+	}
 
 	// Broadcast the transaction
-	resp, err := client.BroadcastTransaction(rawTx)
+	resp, err := broadcaster.Broadcast(ctx, rawTx)
 	if err != nil {
 		return fmt.Errorf("broadcasting transaction: %w", err)
 	}
 
 	fmt.Printf("✓ Transaction broadcast successful!\n")
 	fmt.Printf("  TxID: %s\n", resp.TxID)
-	fmt.Printf("  Status: %s\n", resp.TxStatus)
-	fmt.Printf("  Description: %s\n", arc.GetStatusDescription(resp.TxStatus))
-	if resp.ExtraInfo != "" {
-		fmt.Printf("  Info: %s\n", resp.ExtraInfo)
+	fmt.Printf("  Status: %s\n", resp.Status)
+	if isARC {
+		fmt.Printf("  Description: %s\n", arc.GetStatusDescription(resp.Status))
+	}
+	if resp.Info != "" {
+		fmt.Printf("  Info: %s\n", resp.Info)
 	}
 
 	// Monitor transaction status if requested
-	if monitor {
-		monitorTransaction(client, resp.TxID)
+	if monitor && isMAPI {
+		monitorMAPITransaction(mapiBroadcaster.Client, resp.TxID)
+	} else if monitor {
+		monitorTransaction(arcBroadcaster.Client, resp.TxID)
 	}
 
 	return nil
 }
 
+// callbackReceiver passes on the ARC callbacks for the transactions this run
+// broadcast and logs any others it receives.
+type callbackReceiver struct {
+	mu      sync.Mutex
+	txids   map[string]bool
+	updates chan *arc.Callback
+}
+
+// newCallbackReceiver creates a receiver expecting callbacks for txids.
+func newCallbackReceiver(txids ...string) *callbackReceiver {
+	r := &callbackReceiver{txids: make(map[string]bool), updates: make(chan *arc.Callback, 16)}
+	for _, txid := range txids {
+		r.txids[txid] = true
+	}
+	return r
+}
+
+// receive handles a callback, dropping it when the update queue is full so a
+// flood of posts cannot block the server.
+func (r *callbackReceiver) receive(cb *arc.Callback) {
+	r.mu.Lock()
+	known := r.txids[cb.TxID]
+	r.mu.Unlock()
+
+	if !known {
+		fmt.Fprintf(os.Stderr, "Ignoring callback for unknown txid %s (%s)\n", cb.TxID, cb.TxStatus)
+		return
+	}
+	select {
+	case r.updates <- cb:
+	default:
+		fmt.Fprintf(os.Stderr, "Dropping callback for %s (%s): too many pending updates\n", cb.TxID, cb.TxStatus)
+	}
+}
+
+// defaultCallbackURL derives a callback URL from the listen address. ARC must
+// be able to reach it, so a public --callback-url is needed for remote ARC.
+func defaultCallbackURL(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid --listen address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/callback", nil
+}
+
+// broadcastWithCallbacks starts an HTTP server for ARC callbacks, broadcasts
+// the transaction with that callback URL registered, and prints each status
+// update for it until it reaches a final state or the user presses Ctrl+C.
+func broadcastWithCallbacks(ctx context.Context, broadcaster *chain.ARC, rawTx, txid string) error {
+	callbackURL := callback
+	if callbackURL == "" {
+		var err error
+		if callbackURL, err = defaultCallbackURL(listen); err != nil {
+			return err
+		}
+	}
+
+	// A random token keeps anyone but ARC from posting updates
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("generating callback token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	receiver := newCallbackReceiver(txid)
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", listen, err)
+	}
+	server := &http.Server{
+		Handler:           arc.CallbackHandler(token, receiver.receive),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Callback server error: %v\n", err)
+		}
+	}()
+	defer server.Close()
+
+	fmt.Printf("Listening for ARC callbacks on %s\n", ln.Addr())
+	fmt.Printf("Callback URL: %s\n", callbackURL)
+
+	broadcaster.Client.SetCallback(callbackURL, token)
+	if err := broadcastTransaction(ctx, broadcaster, rawTx); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	fmt.Printf("\nWaiting for callbacks...\n")
+	fmt.Println("Press Ctrl+C to stop listening")
+	fmt.Println()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case cb := <-receiver.updates:
+			printCallback(cb)
+			if arc.IsTransactionFinal(cb.TxStatus) {
+				fmt.Printf("\n✓ Transaction reached final state: %s\n", cb.TxStatus)
+				return nil
+			}
+		}
+	}
+}
+
+// printCallback prints a timestamped status update from an ARC callback.
+func printCallback(cb *arc.Callback) {
+	timestamp := time.Now().Format("15:04:05")
+	fmt.Printf("[%s] Status: %s - %s\n", timestamp, cb.TxStatus, arc.GetStatusDescription(cb.TxStatus))
+
+	if cb.BlockHash != "" {
+		fmt.Printf("         Block Hash: %s\n", cb.BlockHash)
+		fmt.Printf("         Block Height: %d\n", cb.BlockHeight)
+	}
+	if cb.ExtraInfo != "" {
+		fmt.Printf("         Info: %s\n", cb.ExtraInfo)
+	}
+	for _, competing := range cb.CompetingTxs {
+		fmt.Printf("         Competing TxID: %s\n", competing)
+	}
+}
+
 // monitorTransaction continuously polls the transaction status until it reaches a final state.
 // Final states are: MINED, REJECTED, or DOUBLE_SPEND_ATTEMPTED.
 // The polling interval is controlled by the --poll-rate flag (default: 5 seconds).
 // Displays timestamped status updates and block information if available.
 func monitorTransaction(client *arc.ARCClient, txid string) {
+	// Revalidate with the status's ETag, so an unchanged status is a cheap 304
+	client.SetStatusCache(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := watchConfig(ctx)
+	apply := func(cfg *config.Config) {
+		arcConfig := cfg.GetARCConfig(testnet)
+		if arcConfig.URL == "" {
+			fmt.Fprintf(os.Stderr, "Reloaded config.yaml has no ARC URL; still polling the previous endpoint\n")
+			return
+		}
+		client.SetEndpoint(arcConfig.URL, arc.ParseAPIKey(arcConfig.APIKey))
+		fmt.Fprintf(os.Stderr, "Reloaded config.yaml; polling %s\n", arcConfig.URL)
+	}
+
 	fmt.Printf("\nMonitoring transaction status (polling every %d seconds)...\n", pollRate)
 	fmt.Println("Press Ctrl+C to stop monitoring")
 	fmt.Println()
@@ -148,7 +613,7 @@ func monitorTransaction(client *arc.ARCClient, txid string) {
 		status, err := client.GetTransactionStatus(txid)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting transaction status: %v\n", err)
-			<-ticker.C
+			nextPoll(ticker, reloads, apply)
 			continue
 		}
 
@@ -166,17 +631,124 @@ func monitorTransaction(client *arc.ARCClient, txid string) {
 			break
 		}
 
-		<-ticker.C
+		nextPoll(ticker, reloads, apply)
+	}
+}
+
+// monitorMAPITransaction polls the miner's mAPI status for the transaction
+// until it is mined or the miner no longer reports it.
+func monitorMAPITransaction(client *mapi.Client, txid string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := watchConfig(ctx)
+	apply := func(cfg *config.Config) {
+		mapiConfig := cfg.GetMAPIConfig(testnet)
+		if mapiConfig.URL == "" {
+			fmt.Fprintf(os.Stderr, "Reloaded config.yaml has no mAPI URL; still polling the previous endpoint\n")
+			return
+		}
+		client.SetEndpoint(mapiConfig.URL, mapiConfig.APIKey)
+		fmt.Fprintf(os.Stderr, "Reloaded config.yaml; polling %s\n", mapiConfig.URL)
+	}
+
+	fmt.Printf("\nMonitoring transaction status via mAPI (polling every %d seconds)...\n", pollRate)
+	fmt.Println("Press Ctrl+C to stop monitoring")
+	fmt.Println()
+
+	ticker := time.NewTicker(time.Duration(pollRate) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		status, err := client.GetTransactionStatus(txid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting transaction status: %v\n", err)
+			nextPoll(ticker, reloads, apply)
+			continue
+		}
+
+		description, final := describeMAPIStatus(status)
+		timestamp := time.Now().Format("15:04:05")
+		fmt.Printf("[%s] Status: %s\n", timestamp, description)
+		if status.BlockHash != "" {
+			fmt.Printf("         Block Hash: %s\n", status.BlockHash)
+			fmt.Printf("         Block Height: %d\n", status.BlockHeight)
+		}
+
+		if final {
+			fmt.Printf("\n✓ Transaction reached final state: %s\n", description)
+			break
+		}
+
+		nextPoll(ticker, reloads, apply)
+	}
+}
+
+// watchConfig reloads config.yaml while a transaction is monitored, so an
+// endpoint or API key rotated meanwhile is picked up; see config.Watch.
+func watchConfig(ctx context.Context) <-chan *config.Config {
+	return config.Watch(ctx, "", config.ReloadInterval, func(err error) {
+		fmt.Fprintf(os.Stderr, "Keeping the current configuration: %v\n", err)
+	})
+}
+
+// nextPoll waits for the ticker, applying each configuration reloaded
+// meanwhile.
+func nextPoll(ticker *time.Ticker, reloads <-chan *config.Config, apply func(*config.Config)) {
+	for {
+		select {
+		case <-ticker.C:
+			return
+		case cfg, ok := <-reloads:
+			if !ok {
+				reloads = nil
+				continue
+			}
+			apply(cfg)
+		}
+	}
+}
+
+// describeMAPIStatus summarizes an mAPI status and reports whether it is
+// final: mined, or refused by the miner.
+func describeMAPIStatus(status *mapi.TransactionStatus) (string, bool) {
+	switch {
+	case status.ReturnResult != mapi.ResultSuccess:
+		return "REJECTED - " + status.ResultDescription, true
+	case status.Confirmations > 0:
+		return fmt.Sprintf("MINED - %d confirmation(s)", status.Confirmations), true
+	default:
+		return "IN MEMPOOL - Transaction accepted by the miner, not yet mined", false
+	}
+}
+
+// printFeeQuote prints a miner's mAPI fee quote.
+func printFeeQuote(quote *mapi.FeeQuote) {
+	fmt.Printf("Miner ID: %s\n", quote.MinerID)
+	fmt.Printf("Quoted at: %s (expires %s)\n", quote.Timestamp, quote.ExpiryTime)
+	fmt.Printf("Chain tip: %d (%s)\n", quote.CurrentHighestBlockHeight, quote.CurrentHighestBlockHash)
+	for _, fee := range quote.Fees {
+		fmt.Printf("  %-8s mining %.2f sat/KB, relay %.2f sat/KB\n",
+			fee.FeeType+":", fee.MiningFee.FeePerKb(), fee.RelayFee.FeePerKb())
 	}
 }
 
 // init initializes the cobra command flags.
 // This function is automatically called by Go before main() executes.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	rootCmd.Flags().StringVarP(&raw, "raw", "r", "", "Raw transaction hex to broadcast")
 	rootCmd.Flags().BoolVarP(&monitor, "monitor", "m", false, "Monitor transaction status until final state")
 	rootCmd.Flags().IntVarP(&pollRate, "poll-rate", "p", 5, "Polling rate in seconds for monitoring (default: 5)")
 	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet configuration from config.yaml")
+	rootCmd.Flags().StringVar(&listen, "listen", "", "Receive ARC status callbacks on this address (e.g. :8080)")
+	rootCmd.Flags().StringVar(&callback, "callback-url", "", "Public callback URL ARC posts to (default: http://<listen address>/callback)")
+	rootCmd.Flags().BoolVar(&skipMined, "skip-mined", false, "Exit successfully without broadcasting if the transaction is already mined")
+	rootCmd.Flags().BoolVar(&feeQuote, "fee-quote", false, "Print the mAPI miner's fee quote instead of broadcasting")
+	rootCmd.Flags().BoolVar(&noPolicy, "no-policy-check", false, "Send without checking sizes, dust, and inputs against node policy first")
+	rootCmd.Flags().BoolVar(&jobs, "jobs", false, "Read a JSON array of broadcast jobs from stdin and print a JSON array of results")
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the broadcast command.
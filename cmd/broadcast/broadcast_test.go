@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/config"
+	"github.com/mrz1836/go-template/internal/mapi"
+)
+
+// fakeClient serves transaction details from WhatsOnChain.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	txs map[string]*whatsonchain.TxInfo
+	err error
+}
+
+func (f *fakeClient) GetTxByHash(_ context.Context, hash string) (*whatsonchain.TxInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if info, ok := f.txs[hash]; ok {
+		return info, nil
+	}
+	return nil, whatsonchain.ErrTransactionNotFound
+}
+
+func (f *fakeClient) LastRequest() *whatsonchain.LastRequest {
+	return nil
+}
+
+// fakeBroadcaster accepts every transaction it is sent.
+type fakeBroadcaster struct {
+	sent []string
+}
+
+func (f *fakeBroadcaster) Broadcast(_ context.Context, rawTx string) (*chain.BroadcastResult, error) {
+	f.sent = append(f.sent, rawTx)
+	return &chain.BroadcastResult{TxID: testTxID, Status: chain.StatusAccepted}, nil
+}
+
+func TestDefaultCallbackURL(t *testing.T) {
+	t.Parallel()
+
+	url, err := defaultCallbackURL(":8080")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/callback", url)
+
+	url, err = defaultCallbackURL("203.0.113.5:9000")
+	require.NoError(t, err)
+	assert.Equal(t, "http://203.0.113.5:9000/callback", url)
+
+	_, err = defaultCallbackURL("8080")
+	require.Error(t, err)
+}
+
+func TestCallbackReceiver(t *testing.T) {
+	t.Parallel()
+
+	r := newCallbackReceiver("abc")
+	r.receive(&arc.Callback{TxID: "other", TxStatus: arc.StatusMined})
+	r.receive(&arc.Callback{TxID: "abc", TxStatus: arc.StatusSeenOnNetwork})
+
+	require.Len(t, r.updates, 1)
+	cb := <-r.updates
+	assert.Equal(t, arc.StatusSeenOnNetwork, cb.TxStatus)
+
+	// A full queue drops updates rather than blocking
+	for range cap(r.updates) + 1 {
+		r.receive(&arc.Callback{TxID: "abc", TxStatus: arc.StatusMined})
+	}
+	assert.Len(t, r.updates, cap(r.updates))
+}
+
+func TestDescribeMAPIStatus(t *testing.T) {
+	t.Parallel()
+
+	description, final := describeMAPIStatus(&mapi.TransactionStatus{ReturnResult: mapi.ResultSuccess})
+	assert.Contains(t, description, "IN MEMPOOL")
+	assert.False(t, final)
+
+	description, final = describeMAPIStatus(&mapi.TransactionStatus{ReturnResult: mapi.ResultSuccess, Confirmations: 3})
+	assert.Equal(t, "MINED - 3 confirmation(s)", description)
+	assert.True(t, final)
+
+	description, final = describeMAPIStatus(&mapi.TransactionStatus{ReturnResult: mapi.ResultFailure, ResultDescription: "No such mempool or blockchain transaction"})
+	assert.Equal(t, "REJECTED - No such mempool or blockchain transaction", description)
+	assert.True(t, final)
+}
+
+func TestNextPoll(t *testing.T) {
+	t.Parallel()
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	reloads := make(chan *config.Config, 1)
+	reloads <- &config.Config{ARCMainnet: config.ARCConfig{URL: "https://rotated.example.com"}}
+
+	var applied []string
+	nextPoll(ticker, reloads, func(cfg *config.Config) { applied = append(applied, cfg.ARCMainnet.URL) })
+	assert.Equal(t, []string{"https://rotated.example.com"}, applied)
+
+	// A closed channel leaves only the ticker to wait for
+	close(reloads)
+	nextPoll(ticker, reloads, func(*config.Config) { t.Error("applied a configuration from a closed channel") })
+}
+
+func TestMinedHeight(t *testing.T) {
+	t.Parallel()
+
+	const (
+		minedTx   = "aa"
+		mempoolTx = "bb"
+		arcTx     = "cc"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/tx/" + arcTx:
+			_, _ = w.Write([]byte(`{"txid":"cc","txStatus":"MINED","blockHeight":850000}`))
+		case "/v1/tx/" + mempoolTx:
+			_, _ = w.Write([]byte(`{"txid":"bb","txStatus":"SEEN_ON_NETWORK"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":404,"error":"Transaction not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	arcClient := arc.NewARCClient(server.URL, "")
+	woc := &fakeClient{txs: map[string]*whatsonchain.TxInfo{
+		minedTx:   {TxID: minedTx, BlockHeight: 849000, Confirmations: 1001},
+		mempoolTx: {TxID: mempoolTx},
+	}}
+
+	tests := []struct {
+		name      string
+		arcClient *arc.ARCClient
+		txid      string
+		height    int64
+		source    string
+	}{
+		{"mined per ARC", arcClient, arcTx, 850000, "ARC"},
+		{"unknown to ARC, mined per WhatsOnChain", arcClient, minedTx, 849000, "WhatsOnChain"},
+		{"mined per WhatsOnChain without ARC", nil, minedTx, 849000, "WhatsOnChain"},
+		{"in the mempool", arcClient, mempoolTx, 0, ""},
+		{"unknown", arcClient, "dd", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			height, source, err := minedHeight(context.Background(), tt.arcClient, woc, tt.txid)
+			require.NoError(t, err)
+			assert.Equal(t, tt.height, height)
+			assert.Equal(t, tt.source, source)
+		})
+	}
+
+	t.Run("lookup failure", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := minedHeight(context.Background(), nil, &fakeClient{err: errors.New("rate limited")}, minedTx)
+		require.ErrorContains(t, err, "rate limited")
+	})
+}
+
+func TestCheckPolicy(t *testing.T) {
+	t.Parallel()
+
+	const sourceTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	p2pkh, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	require.NoError(t, err)
+
+	newTx := func(t *testing.T, sats uint64, inputs int) *transaction.Transaction {
+		t.Helper()
+		tx := transaction.NewTransaction()
+		for range inputs {
+			require.NoError(t, tx.AddInputFrom(sourceTxID, 0, p2pkh.String(), 1000, nil))
+			tx.Inputs[len(tx.Inputs)-1].UnlockingScript = &script.Script{}
+		}
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: sats, LockingScript: p2pkh})
+		return tx
+	}
+
+	require.NoError(t, checkPolicy(newTx(t, 1000, 1)))
+
+	err = checkPolicy(newTx(t, 0, 2))
+	require.ErrorContains(t, err, "breaks node policy")
+	require.ErrorContains(t, err, "\n  input #1 spends "+sourceTxID+":0, already spent by input #0")
+	require.ErrorContains(t, err, "\n  output #0 is 0 sats, below the 1 sat dust limit")
+}
+
+func TestReadJobs(t *testing.T) {
+	t.Parallel()
+
+	list, err := readJobs(strings.NewReader(`[{"rawtx":"00","callback":"https://my.host/cb","waitFor":"MINED","labels":{"order":"42"}}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []job{{RawTx: "00", Callback: "https://my.host/cb", WaitFor: "MINED", Labels: map[string]string{"order": "42"}}}, list)
+
+	_, err = readJobs(strings.NewReader(`[{"rawtx":"00","callbackUrl":"https://my.host/cb"}]`))
+	require.ErrorContains(t, err, `parsing jobs: json: unknown field "callbackUrl"`)
+
+	_, err = readJobs(strings.NewReader(`[]`))
+	require.EqualError(t, err, "no jobs provided")
+
+	_, err = readJobs(strings.NewReader(""))
+	require.EqualError(t, err, "no jobs provided")
+}
+
+func TestBroadcastJob(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("broadcasts through any broadcaster", func(t *testing.T) {
+		t.Parallel()
+
+		b := &fakeBroadcaster{}
+		resp, err := broadcastJob(ctx, b, nil, job{RawTx: " " + testTx + "\n"})
+		require.NoError(t, err)
+		assert.Equal(t, testTxID, resp.TxID)
+		assert.Equal(t, []string{testTx}, b.sent)
+	})
+
+	t.Run("invalid jobs are not sent", func(t *testing.T) {
+		t.Parallel()
+
+		b := &fakeBroadcaster{}
+		_, err := broadcastJob(ctx, b, nil, job{})
+		require.EqualError(t, err, "no transaction provided")
+		_, err = broadcastJob(ctx, b, nil, job{RawTx: "zz"})
+		require.EqualError(t, err, "rawtx is not a valid hex string")
+		_, err = broadcastJob(ctx, b, nil, job{RawTx: testTx, WaitFor: "MINED"})
+		require.EqualError(t, err, "callback and waitFor require the ARC broadcaster")
+		_, err = broadcastJob(ctx, chain.NewARC("http://127.0.0.1:0", ""), nil, job{RawTx: testTx, WaitFor: "LATER"})
+		require.EqualError(t, err, `unknown waitFor status "LATER"`)
+		assert.Empty(t, b.sent)
+	})
+
+	t.Run("registers the callback and wait-for status with ARC", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "https://my.host/cb", r.Header.Get("X-CallbackUrl"))
+			assert.Equal(t, "secret", r.Header.Get("X-CallbackToken"))
+			assert.Equal(t, arc.StatusSeenOnNetwork, r.Header.Get("X-WaitFor"))
+			_ = json.NewEncoder(w).Encode(arc.TransactionResponse{TxID: testTxID, TxStatus: arc.StatusSeenOnNetwork})
+		}))
+		defer server.Close()
+
+		resp, err := broadcastJob(ctx, chain.NewARC(server.URL, ""), nil, job{
+			RawTx:         testTx,
+			Callback:      "https://my.host/cb",
+			CallbackToken: "secret",
+			WaitFor:       "seen_on_network",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, arc.StatusSeenOnNetwork, resp.Status)
+	})
+
+	t.Run("skips mined transactions", func(t *testing.T) {
+		t.Parallel()
+
+		b := &fakeBroadcaster{}
+		mined := func(txid string) (int64, string, error) {
+			assert.Equal(t, testTxID, txid)
+			return 800000, "WhatsOnChain", nil
+		}
+		resp, err := broadcastJob(ctx, b, mined, job{RawTx: testTx})
+		require.NoError(t, err)
+		assert.Equal(t, &chain.BroadcastResult{
+			TxID:   testTxID,
+			Status: arc.StatusMined,
+			Info:   "already mined at height 800000 (per WhatsOnChain); not broadcast",
+		}, resp)
+		assert.Empty(t, b.sent)
+	})
+}
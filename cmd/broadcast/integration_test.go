@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/httpmock"
+)
+
+// The integration tests run the command with the config.yaml beside them
+// against recorded ARC responses in testdata; see internal/httpmock to
+// re-record them. The transaction spends private key 1's output to itself.
+const (
+	testTxID = "d45a84e34a6cf93e9b5b7d1ef1ab91ea08a7ff8daa9fab5b7bdb4796a563b7ed"
+	testTx   = "01000000014ae4ec7c27f8d2ddac333161aab1674ad22f0db237d42cb4239d9285c09056a9000000006b483045022100a61d9fe2c01335b0326463dce18b1867a489320047888c29d09d8f35cf05c5dc022014c310c49a43da5f9ff86a87b8c734988c7ae2fdbb472db5e2bc8d76cee1dcc241210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798ffffffff01ac260000000000001976a914751e76e8199196d454941c45d1b3a323f1433bd688ac00000000"
+)
+
+func TestIntegrationBroadcast(t *testing.T) {
+	httpmock.Install(t, "broadcast")
+
+	out, err := httpmock.Execute(t, rootCmd, "-r", testTx)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Broadcasting transaction to ARC...")
+	assert.Contains(t, out, "✓ Transaction broadcast successful!")
+	assert.Contains(t, out, "TxID: "+testTxID)
+	assert.Contains(t, out, "Status: SEEN_ON_NETWORK")
+}
+
+func TestIntegrationRejected(t *testing.T) {
+	httpmock.Install(t, "rejected")
+
+	out, err := httpmock.Execute(t, rootCmd, "-r", testTx)
+	require.ErrorContains(t, err, "broadcasting transaction: broadcasting via ARC:")
+	require.ErrorContains(t, err, "HTTP status 461")
+	assert.NotContains(t, out, "successful")
+}
+
+func TestIntegrationJobs(t *testing.T) {
+	httpmock.Install(t, "jobs")
+
+	input := `[
+		{"rawtx": "` + testTx + `", "waitFor": "SEEN_ON_NETWORK", "labels": {"order": "42"}},
+		{"rawtx": "not hex", "labels": {"order": "43"}}
+	]`
+	out, err := httpmock.ExecuteWithInput(t, rootCmd, input, "--jobs")
+	require.EqualError(t, err, "1 of 2 jobs failed")
+
+	var results []jobResult
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	assert.Equal(t, []jobResult{
+		{TxID: testTxID, Status: "SEEN_ON_NETWORK", Labels: map[string]string{"order": "42"}},
+		{Error: "rawtx is not a valid hex string", Labels: map[string]string{"order": "43"}},
+	}, results)
+}
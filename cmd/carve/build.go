@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// changeVout returns the index of the change output of builder's most
+// recent build, or -1 for none. Send-all (amount == 0) pays the destination
+// as the builder's change, so it has none.
+func changeVout(builder *txbuilder.Builder, amount uint64) int {
+	if amount == 0 {
+		return -1
+	}
+	return builder.Change
+}
+
+// newBuilder creates the transaction builder configured from flags.
+func newBuilder() *txbuilder.Builder {
+	builder := &txbuilder.Builder{
+		FeePerKb: feePerKb, Testnet: testnet, AbsorbChange: absorb, Unsigned: unsigned,
+		LockTime: lockTime, Sequence: &sequence, DryRun: dryRun, FeeFloor: &minFee,
+		SigHash: sigHashes.all, InputSigHash: sigHashes.inputs, MaxFee: maxFee, MaxFeePerKb: maxPerKb,
+		Sort: bip69,
+	}
+	if diag.level >= levelDebug {
+		builder.Logf = func(format string, args ...any) { diag.printf(levelDebug, format, args...) }
+	}
+	return builder
+}
+
+// selectAppropriateUTXOs selects UTXOs based on the target amount: the
+// payments' total when there are any, otherwise --sats.
+func selectAppropriateUTXOs(builder *txbuilder.Builder, utxos []*txbuilder.UTXO, payments []*transaction.TransactionOutput) ([]*txbuilder.UTXO, error) {
+	if payments != nil {
+		// SelectUTXOs budgets for one payment output; add the fee for the others
+		target := paymentsTotal(payments)
+		if fee, base := builder.EstimateFee(1, payments), builder.CalculateFee(1, 2); fee > base {
+			target += fee - base
+		}
+		selected, err := builder.SelectUTXOs(utxos, target)
+		if err != nil {
+			return nil, fmt.Errorf("UTXO selection failed: %w", err)
+		}
+		return selected, nil
+	}
+
+	if sats == 0 {
+		// Send all funds - use all UTXOs, and any the builder requires
+		diag.printf(levelInfo, "Sending all available funds")
+		return append(slices.Clone(builder.Required), utxos...), nil
+	}
+
+	// Select minimum UTXOs needed to cover the amount
+	selected, err := builder.SelectUTXOs(utxos, sats)
+	if err != nil {
+		return nil, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	return selected, nil
+}
+
+// buildTransaction constructs and signs a BSV transaction spending utxos
+// from funds.
+func buildTransaction(builder *txbuilder.Builder, funds *funding, destAddrStr string, utxos []*txbuilder.UTXO, amount uint64, numOutputs int) (*transaction.Transaction, error) {
+	// Parse destination address
+	destAddr, err := script.NewAddressFromString(destAddrStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination address: %w", err)
+	}
+
+	inputs := fundedInputs(funds, utxos)
+
+	// For send-all (amount == 0), remaining funds go to the DESTINATION address.
+	// For normal sends, change goes back to the SOURCE address.
+	changeAddr := funds.change
+	if amount == 0 {
+		changeAddr = destAddr
+	}
+
+	return builder.Build(inputs, destAddr, amount, numOutputs, changeAddr)
+}
+
+// buildPaymentsTransaction constructs and signs a transaction spending utxos
+// from funds to payments, in order, with change back to funds.
+func buildPaymentsTransaction(builder *txbuilder.Builder, funds *funding, utxos []*txbuilder.UTXO, payments []*transaction.TransactionOutput) (*transaction.Transaction, error) {
+	return builder.BuildOutputs(fundedInputs(funds, utxos), payments, funds.change)
+}
+
+// fundedInputs pairs each of utxos with the key of the address it came from,
+// in that address's key format, or with the --from address when unsigned.
+// --input-template outputs get their locking script and unlocking.
+func fundedInputs(funds *funding, utxos []*txbuilder.UTXO) []txbuilder.Input {
+	inputs := make([]txbuilder.Input, 0, len(utxos))
+	for _, utxo := range utxos {
+		if t := funds.templates[outpoint(utxo)]; t != nil {
+			inputs = append(inputs, txbuilder.Input{UTXO: utxo, Key: t.key, LockingScript: t.lockingScript, Unlocking: t.unlocking})
+			continue
+		}
+		inputs = append(inputs, txbuilder.Input{UTXO: utxo, Key: funds.key(utxo), Address: funds.from, Uncompressed: funds.uncompressed[outpoint(utxo)]})
+	}
+	return inputs
+}
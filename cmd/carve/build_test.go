@@ -0,0 +1,134 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+func TestBuildTransaction(t *testing.T) {
+	t.Parallel()
+
+	const destAddr = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	dest, err := script.NewAddressFromString(destAddr)
+	require.NoError(t, err)
+
+	key, source := chaintest.Source(t)
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}
+
+	t.Run("change returns to the source address", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 4000, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 2)
+
+		assert.Equal(t, uint64(4000), tx.Outputs[0].Satoshis)
+		assert.True(t, paysTo(t, tx.Outputs[0].LockingScript, dest))
+		assert.Equal(t, uint64(10000-4000-txbuilder.MinFee), tx.Outputs[1].Satoshis)
+		assert.True(t, paysTo(t, tx.Outputs[1].LockingScript, source))
+	})
+
+	t.Run("send-all pays the destination", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 0, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+
+		assert.Equal(t, uint64(10000-txbuilder.MinFee), tx.Outputs[0].Satoshis)
+		assert.True(t, paysTo(t, tx.Outputs[0].LockingScript, dest))
+	})
+
+	t.Run("small change is absorbed into the fee", func(t *testing.T) {
+		t.Parallel()
+
+		absorber := &txbuilder.Builder{FeePerKb: 100, AbsorbChange: 1000}
+		tx, err := buildTransaction(absorber, singleKeyFunding(key, source, utxos), destAddr, utxos, 9500, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, uint64(10000-9500-txbuilder.MinFee), absorber.Absorbed)
+
+		fee, err := tx.GetFee()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(500), fee)
+	})
+
+	t.Run("uncompressed key spends from its own address", func(t *testing.T) {
+		t.Parallel()
+
+		uncompressed, err := script.NewAddressFromPublicKeyWithCompression(key.PubKey(), true, false)
+		require.NoError(t, err)
+		uncompressedBuilder := &txbuilder.Builder{FeePerKb: 100, Uncompressed: true}
+		tx, err := buildTransaction(uncompressedBuilder, singleKeyFunding(key, uncompressed, utxos), destAddr, utxos, 4000, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 2)
+		assert.True(t, paysTo(t, tx.Outputs[1].LockingScript, uncompressed))
+
+		chunks, err := tx.Inputs[0].UnlockingScript.Chunks()
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, key.PubKey().Uncompressed(), chunks[1].Data)
+	})
+
+	t.Run("invalid destination address", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), "not-an-address", utxos, 0, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid destination address")
+	})
+}
+
+func TestSelectAppropriateUTXOsFeeFloor(t *testing.T) {
+	t.Parallel()
+
+	recipients := make([]string, 10)
+	for i := range recipients {
+		recipients[i] = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa:100"
+	}
+	payments, err := parseRecipients(recipients)
+	require.NoError(t, err)
+
+	// Ten payments cost 31 satoshis more than one at 100 sat/kB, which only
+	// shows below the default minimum fee
+	floor := uint64(0)
+	builder := &txbuilder.Builder{FeePerKb: 100, FeeFloor: &floor}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 1040}, {TxHash: testTxID, TxPos: 1, Value: 1000}}
+
+	selected, err := selectAppropriateUTXOs(builder, utxos, payments)
+	require.NoError(t, err)
+	assert.Len(t, selected, 2)
+}
+
+func TestBuildPaymentsTransaction(t *testing.T) {
+	t.Parallel()
+
+	key, source := chaintest.Source(t)
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}
+	payments, err := parseRecipients([]string{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa:1000", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH:2500"})
+	require.NoError(t, err)
+
+	selected, err := selectAppropriateUTXOs(builder, utxos, payments)
+	require.NoError(t, err)
+	tx, err := buildPaymentsTransaction(builder, singleKeyFunding(key, source, selected), selected, payments)
+	require.NoError(t, err)
+	require.Len(t, tx.Outputs, 3)
+
+	assert.Equal(t, uint64(1000), tx.Outputs[0].Satoshis)
+	assert.Equal(t, uint64(2500), tx.Outputs[1].Satoshis)
+	assert.True(t, paysTo(t, tx.Outputs[2].LockingScript, source))
+	assert.Equal(t, uint64(10000-3500-txbuilder.MinFee), tx.Outputs[2].Satoshis)
+
+	plan, err := buildPlan(tx, selected, source.AddressString, builder.Change)
+	require.NoError(t, err)
+	require.NotNil(t, plan.Change)
+	assert.Equal(t, uint32(2), plan.Change.TxPos)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// cacheTTL is how long the UTXO cache trusts itself over the provider: a
+// spend the provider has not seen by then was likely never broadcast, and
+// change it has not listed by then was likely dropped.
+const cacheTTL = time.Hour
+
+// spentOutput is an outpoint spent by an earlier run, kept in the UTXO
+// cache until the provider stops listing it.
+type spentOutput struct {
+	Address string    `json:"address"`
+	TxID    string    `json:"txid"` // Transaction that spends it
+	At      time.Time `json:"at"`
+}
+
+// pendingOutput is change created by an earlier run, kept in the UTXO cache
+// until the provider lists it.
+type pendingOutput struct {
+	Address string      `json:"address"`
+	UTXO    *chain.UTXO `json:"utxo"`
+	At      time.Time   `json:"at"`
+}
+
+// fetchedUTXOs is an address's UTXOs as the provider last listed them.
+type fetchedUTXOs struct {
+	UTXOs []*chain.UTXO `json:"utxos"`
+	At    time.Time     `json:"at"`
+}
+
+// utxoCache carries what carve knows about UTXOs from run to run, so back
+// to back runs neither select an output already spent nor miss change
+// before the provider catches up with the mempool. Spent and Pending are
+// keyed by outpoint, Fetched by address.
+type utxoCache struct {
+	path    string
+	served  map[string]string         // Address of each UTXO served this run, by outpoint
+	Fetched map[string]*fetchedUTXOs  `json:"fetched"`
+	Spent   map[string]*spentOutput   `json:"spent"`
+	Pending map[string]*pendingOutput `json:"pending"`
+}
+
+// defaultCacheFile returns ~/.bsv-cmd-line-utils/cache/carve-<network>.json.
+func defaultCacheFile(testnet bool) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".bsv-cmd-line-utils", "cache", "carve-"+networkName(testnet)+".json"), nil
+}
+
+// openCache reads the UTXO cache at path, or the network's default file when
+// path is empty. A missing file is an empty cache.
+func openCache(path string, testnet bool) (*utxoCache, error) {
+	if path == "" {
+		var err error
+		if path, err = defaultCacheFile(testnet); err != nil {
+			return nil, err
+		}
+	}
+	c := &utxoCache{path: path}
+
+	data, err := os.ReadFile(path) //nolint:gosec // user-specified file
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading UTXO cache: %w", err)
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("parsing UTXO cache %s: %w (remove it, or use --no-cache)", path, err)
+		}
+	}
+	c.served = make(map[string]string)
+	if c.Fetched == nil {
+		c.Fetched = make(map[string]*fetchedUTXOs)
+	}
+	if c.Spent == nil {
+		c.Spent = make(map[string]*spentOutput)
+	}
+	if c.Pending == nil {
+		c.Pending = make(map[string]*pendingOutput)
+	}
+	return c, nil
+}
+
+// apply returns the UTXOs of address the provider listed, less those spent by
+// earlier runs, plus change from earlier runs the provider has yet to list.
+// Entries the listing shows to be settled, and those older than cacheTTL,
+// are dropped first.
+func (c *utxoCache) apply(address string, listed []*chain.UTXO, now time.Time) []*chain.UTXO {
+	onList := make(map[string]bool, len(listed))
+	for _, u := range listed {
+		onList[outpoint(u)] = true
+	}
+	// Pending change goes once listed or spent, so it is pruned before the spends
+	for op, p := range c.Pending {
+		if p.Address == address && (onList[op] || c.Spent[op] != nil || now.Sub(p.At) >= cacheTTL) {
+			delete(c.Pending, op)
+		}
+	}
+	for op, s := range c.Spent {
+		if s.Address == address && (!onList[op] || now.Sub(s.At) >= cacheTTL) {
+			delete(c.Spent, op)
+		}
+	}
+
+	utxos := make([]*chain.UTXO, 0, len(listed))
+	var skipped int
+	for _, u := range listed {
+		if c.Spent[outpoint(u)] != nil {
+			skipped++
+			continue
+		}
+		utxos = append(utxos, u)
+	}
+	var added []string
+	for op, p := range c.Pending {
+		if p.Address == address {
+			added = append(added, op)
+		}
+	}
+	sort.Strings(added)
+	for _, op := range added {
+		utxos = append(utxos, c.Pending[op].UTXO)
+	}
+	if skipped > 0 || len(added) > 0 {
+		diag.printf(levelInfo, "UTXO cache: left out %d spent by earlier runs, added %d unlisted change output(s)", skipped, len(added))
+	}
+
+	for _, u := range utxos {
+		c.served[outpoint(u)] = address
+	}
+	return utxos
+}
+
+// record marks inputs as spent by tx and adds its change, paying
+// changeAddr, as pending. A nil cache records nothing.
+func (c *utxoCache) record(tx *transaction.Transaction, inputs []*txbuilder.UTXO, change *chain.UTXO, changeAddr string, now time.Time) {
+	if c == nil {
+		return
+	}
+	txid := tx.TxID().String()
+	for _, u := range inputs {
+		op := outpoint(u)
+		address, ok := c.served[op]
+		if !ok {
+			if p := c.Pending[op]; p != nil {
+				address, ok = p.Address, true
+			}
+		}
+		if ok {
+			c.Spent[op] = &spentOutput{Address: address, TxID: txid, At: now}
+		}
+	}
+	if change != nil {
+		c.Pending[outpoint(change)] = &pendingOutput{Address: changeAddr, UTXO: change, At: now}
+	}
+}
+
+// save writes the cache file via a temporary file and rename, so a
+// concurrent run never reads half of it.
+func (c *utxoCache) save() error {
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".carve-*")
+	if err != nil {
+		return fmt.Errorf("writing UTXO cache: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // no-op after a successful rename
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing UTXO cache: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("writing UTXO cache: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("writing UTXO cache: %w", err)
+	}
+	return nil
+}
+
+// cachedProvider serves UTXOs through a utxoCache. When the provider fails,
+// such as on a rate limit, the address's last listing is used instead if it
+// is younger than cacheTTL.
+type cachedProvider struct {
+	chain.UTXOProvider
+	cache *utxoCache
+	now   func() time.Time
+}
+
+// UTXOs returns the UTXOs of address, adjusted by the cache.
+func (p *cachedProvider) UTXOs(ctx context.Context, address string) ([]*chain.UTXO, error) {
+	now := p.now()
+	listed, err := p.UTXOProvider.UTXOs(ctx, address)
+	if err != nil {
+		last := p.cache.Fetched[address]
+		if last == nil || now.Sub(last.At) >= cacheTTL {
+			return nil, err
+		}
+		diag.printf(levelNormal, "Warning: %v; using the UTXOs listed %s ago", err, now.Sub(last.At).Round(time.Second))
+		listed = last.UTXOs
+	} else {
+		p.cache.Fetched[address] = &fetchedUTXOs{UTXOs: listed, At: now}
+	}
+	return p.cache.apply(address, listed, now), nil
+}
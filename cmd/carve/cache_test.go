@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// downProvider fails every call, as a rate-limited API does.
+type downProvider struct{}
+
+func (downProvider) UTXOs(context.Context, string) ([]*txbuilder.UTXO, error) {
+	return nil, errors.New("status 429")
+}
+
+func TestUTXOCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	newCache := func(t *testing.T) *utxoCache {
+		c, err := openCache(filepath.Join(t.TempDir(), "cache.json"), false)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("leaves out spent outputs and adds pending change", func(t *testing.T) {
+		t.Parallel()
+
+		c := newCache(t)
+		c.Spent["aa:0"] = &spentOutput{Address: "addr", TxID: "cc", At: now}
+		c.Pending["cc:1"] = &pendingOutput{Address: "addr", UTXO: &chain.UTXO{TxHash: "cc", TxPos: 1, Value: 500}, At: now}
+		c.Pending["dd:1"] = &pendingOutput{Address: "other", UTXO: &chain.UTXO{TxHash: "dd", TxPos: 1}, At: now}
+
+		utxos := c.apply("addr", []*chain.UTXO{{TxHash: "aa", TxPos: 0}, {TxHash: "bb", TxPos: 0}}, now.Add(time.Minute))
+		require.Len(t, utxos, 2)
+		assert.Equal(t, "bb:0", outpoint(utxos[0]))
+		assert.Equal(t, "cc:1", outpoint(utxos[1]))
+		assert.Len(t, c.Pending, 2)
+	})
+
+	t.Run("drops entries the provider has caught up with", func(t *testing.T) {
+		t.Parallel()
+
+		c := newCache(t)
+		c.Spent["aa:0"] = &spentOutput{Address: "addr", TxID: "cc", At: now}
+		c.Pending["cc:1"] = &pendingOutput{Address: "addr", UTXO: &chain.UTXO{TxHash: "cc", TxPos: 1}, At: now}
+
+		utxos := c.apply("addr", []*chain.UTXO{{TxHash: "cc", TxPos: 1}}, now.Add(time.Minute))
+		require.Len(t, utxos, 1)
+		assert.Empty(t, c.Spent)
+		assert.Empty(t, c.Pending)
+	})
+
+	t.Run("drops entries older than the TTL", func(t *testing.T) {
+		t.Parallel()
+
+		c := newCache(t)
+		c.Spent["aa:0"] = &spentOutput{Address: "addr", TxID: "cc", At: now}
+		c.Pending["cc:1"] = &pendingOutput{Address: "addr", UTXO: &chain.UTXO{TxHash: "cc", TxPos: 1}, At: now}
+
+		utxos := c.apply("addr", []*chain.UTXO{{TxHash: "aa", TxPos: 0}}, now.Add(cacheTTL))
+		require.Len(t, utxos, 1)
+		assert.Equal(t, "aa:0", outpoint(utxos[0]))
+		assert.Empty(t, c.Spent)
+		assert.Empty(t, c.Pending)
+	})
+
+	t.Run("records inputs and change across saves", func(t *testing.T) {
+		t.Parallel()
+
+		c := newCache(t)
+		c.apply("addr", []*chain.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}, now)
+		tx := transaction.NewTransaction()
+		change := &chain.UTXO{TxHash: "ee", TxPos: 1, Value: 8900}
+		c.record(tx, []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0}}, change, "addr", now)
+		require.NoError(t, c.save())
+
+		reopened, err := openCache(c.path, false)
+		require.NoError(t, err)
+		require.Contains(t, reopened.Spent, testTxID+":0")
+		assert.Equal(t, tx.TxID().String(), reopened.Spent[testTxID+":0"].TxID)
+		require.Contains(t, reopened.Pending, "ee:1")
+		assert.Equal(t, uint64(8900), reopened.Pending["ee:1"].UTXO.Value)
+	})
+
+	t.Run("falls back to the last listing when the provider fails", func(t *testing.T) {
+		t.Parallel()
+
+		c := newCache(t)
+		c.Fetched["addr"] = &fetchedUTXOs{UTXOs: []*chain.UTXO{{TxHash: "aa", TxPos: 0}}, At: now}
+
+		p := &cachedProvider{UTXOProvider: downProvider{}, cache: c, now: func() time.Time { return now.Add(time.Minute) }}
+		utxos, err := p.UTXOs(context.Background(), "addr")
+		require.NoError(t, err)
+		assert.Len(t, utxos, 1)
+
+		p.now = func() time.Time { return now.Add(cacheTTL) }
+		_, err = p.UTXOs(context.Background(), "addr")
+		require.EqualError(t, err, "status 429")
+	})
+
+	t.Run("rejects a corrupt file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "cache.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+		_, err := openCache(path, false)
+		require.ErrorContains(t, err, "use --no-cache")
+	})
+}
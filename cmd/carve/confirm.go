@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// coinbaseMaturity is the number of confirmations a coinbase output needs
+// before consensus lets it be spent.
+const coinbaseMaturity = 100
+
+// unconfirmedParents returns the txids, once each, of the mempool
+// transactions that created any of utxos.
+func unconfirmedParents(utxos []*txbuilder.UTXO) []string {
+	seen := make(map[string]bool)
+	var parents []string
+	for _, u := range utxos {
+		if u.Height == 0 && !seen[u.TxHash] {
+			seen[u.TxHash] = true
+			parents = append(parents, u.TxHash)
+		}
+	}
+	return parents
+}
+
+// confirmations returns how many blocks have confirmed u at tip, counting
+// its own block; an unconfirmed UTXO has none.
+func confirmations(u *txbuilder.UTXO, tip uint32) int {
+	if u.Height <= 0 {
+		return 0
+	}
+	// A provider's UTXOs can be a block ahead of its chain tip
+	return int(max(int64(tip)-u.Height+1, 1))
+}
+
+// filterSpendable drops the UTXOs with fewer than minConf confirmations, and
+// coinbase outputs without coinbaseMaturity, which consensus will not let
+// be spent. Only the parents of UTXOs young enough to be immature coinbase
+// outputs are fetched, once each.
+func filterSpendable(ctx context.Context, headers chain.HeaderSource, txs chain.TxFetcher, utxos []*txbuilder.UTXO, minConf int) ([]*txbuilder.UTXO, error) {
+	tip, err := headers.TipHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the chain tip: %w", err)
+	}
+
+	kept := make([]*txbuilder.UTXO, 0, len(utxos))
+	coinbase := make(map[string]bool)
+	var shallow, immature int
+	for _, u := range utxos {
+		conf := confirmations(u, tip)
+		if conf < minConf {
+			shallow++
+			diag.printf(levelDebug, "Skipping %s:%d with %d confirmation(s)", u.TxHash, u.TxPos, conf)
+			continue
+		}
+		if conf > 0 && conf < coinbaseMaturity {
+			isCoinbase, ok := coinbase[u.TxHash]
+			if !ok {
+				raw, err := txs.RawTx(ctx, u.TxHash)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fetch %s to check coinbase maturity: %w", u.TxHash, err)
+				}
+				parent, err := transaction.NewTransactionFromHex(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid transaction %s: %w", u.TxHash, err)
+				}
+				isCoinbase = parent.IsCoinbase()
+				coinbase[u.TxHash] = isCoinbase
+			}
+			if isCoinbase {
+				immature++
+				diag.printf(levelDebug, "Skipping coinbase output %s:%d with %d of %d confirmations", u.TxHash, u.TxPos, conf, coinbaseMaturity)
+				continue
+			}
+		}
+		kept = append(kept, u)
+	}
+
+	if shallow > 0 {
+		diag.printf(levelInfo, "Skipped %d UTXO(s) with fewer than %d confirmation(s)", shallow, minConf)
+	}
+	if immature > 0 {
+		diag.printf(levelNormal, "Skipped %d immature coinbase output(s), spendable after %d confirmations", immature, coinbaseMaturity)
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no spendable UTXOs: %d with fewer than %d confirmation(s) and %d immature coinbase output(s) skipped", shallow, minConf, immature)
+	}
+	return kept, nil
+}
+
+// waitForConfirmation polls the source addresses' UTXOs until every one of
+// selected is confirmed. It fails if a selected output stops being unspent,
+// such as when its parent is dropped from the mempool.
+func waitForConfirmation(ctx context.Context, provider chain.UTXOProvider, addrs []string, selected []*txbuilder.UTXO, interval time.Duration) error {
+	for {
+		heights := make(map[string]int64)
+		for _, addr := range addrs {
+			utxos, err := provider.UTXOs(ctx, addr)
+			if err != nil {
+				return fmt.Errorf("failed to fetch UTXOs: %w", err)
+			}
+			for _, u := range utxos {
+				heights[outpoint(u)] = u.Height
+			}
+		}
+
+		var pending []*txbuilder.UTXO
+		for _, u := range selected {
+			height, ok := heights[outpoint(u)]
+			if !ok {
+				return fmt.Errorf("input %s:%d is no longer unspent", u.TxHash, u.TxPos)
+			}
+			if height == 0 {
+				pending = append(pending, u)
+			}
+		}
+		parents := unconfirmedParents(pending)
+		if len(parents) == 0 {
+			diag.printf(levelNormal, "All inputs confirmed")
+			return nil
+		}
+		diag.printf(levelNormal, "Waiting for %d unconfirmed transaction(s), checking every %s...", len(parents), interval)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for confirmation: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// pollProvider returns one UTXO set per call, repeating the last.
+type pollProvider struct {
+	sets  [][]*txbuilder.UTXO
+	calls int
+}
+
+func (p *pollProvider) UTXOs(context.Context, string) ([]*txbuilder.UTXO, error) {
+	set := p.sets[min(p.calls, len(p.sets)-1)]
+	p.calls++
+	return set, nil
+}
+
+func TestFilterSpendable(t *testing.T) {
+	t.Parallel()
+
+	_, source := chaintest.Source(t)
+	lock, err := p2pkh.Lock(source)
+	require.NoError(t, err)
+
+	coinbase := transaction.NewTransaction()
+	coinbase.AddInput(&transaction.TransactionInput{
+		SourceTXID:       &chainhash.Hash{},
+		SourceTxOutIndex: transaction.DefaultSequenceNumber,
+		UnlockingScript:  &script.Script{0x01, 0x01},
+		SequenceNumber:   transaction.DefaultSequenceNumber,
+	})
+	coinbase.AddOutput(&transaction.TransactionOutput{Satoshis: 5000, LockingScript: lock})
+	spend := transaction.NewTransaction()
+	require.NoError(t, spend.AddInputFrom(testTxID, 0, lock.String(), 6000, nil))
+	spend.AddOutput(&transaction.TransactionOutput{Satoshis: 5000, LockingScript: lock})
+
+	provider := chain.NewMock()
+	provider.Headers[1000] = &block.Header{}
+	provider.AddTx(coinbase)
+	provider.AddTx(spend)
+
+	unconfirmed := &txbuilder.UTXO{TxHash: spend.TxID().String(), TxPos: 0, Value: 5000}
+	oneConf := &txbuilder.UTXO{TxHash: spend.TxID().String(), TxPos: 0, Value: 5000, Height: 1000}
+	sixConf := &txbuilder.UTXO{TxHash: spend.TxID().String(), TxPos: 0, Value: 5000, Height: 995}
+	immature := &txbuilder.UTXO{TxHash: coinbase.TxID().String(), TxPos: 0, Value: 5000, Height: 950}
+	// Deep enough that its unregistered parent is never fetched
+	mature := &txbuilder.UTXO{TxHash: testTxID, TxPos: 0, Value: 5000, Height: 901}
+	utxos := []*txbuilder.UTXO{unconfirmed, oneConf, sixConf, immature, mature}
+
+	t.Run("skips immature coinbase outputs", func(t *testing.T) {
+		t.Parallel()
+
+		kept, err := filterSpendable(context.Background(), provider, provider, utxos, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []*txbuilder.UTXO{unconfirmed, oneConf, sixConf, mature}, kept)
+	})
+
+	t.Run("skips UTXOs below the minimum confirmations", func(t *testing.T) {
+		t.Parallel()
+
+		kept, err := filterSpendable(context.Background(), provider, provider, utxos, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []*txbuilder.UTXO{oneConf, sixConf, mature}, kept)
+
+		kept, err = filterSpendable(context.Background(), provider, provider, utxos, 6)
+		require.NoError(t, err)
+		assert.Equal(t, []*txbuilder.UTXO{sixConf, mature}, kept)
+	})
+
+	t.Run("fails when nothing is spendable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := filterSpendable(context.Background(), provider, provider, []*txbuilder.UTXO{unconfirmed, immature}, 1)
+		require.EqualError(t, err, "no spendable UTXOs: 1 with fewer than 1 confirmation(s) and 1 immature coinbase output(s) skipped")
+	})
+}
+
+func TestUnconfirmedParents(t *testing.T) {
+	t.Parallel()
+
+	utxos := []*txbuilder.UTXO{
+		{TxHash: "aa", TxPos: 0, Height: 100},
+		{TxHash: "bb", TxPos: 0},
+		{TxHash: "bb", TxPos: 1},
+		{TxHash: "cc", TxPos: 0},
+	}
+	assert.Equal(t, []string{"bb", "cc"}, unconfirmedParents(utxos))
+	assert.Empty(t, unconfirmedParents(utxos[:1]))
+}
+
+func TestWaitForConfirmation(t *testing.T) {
+	t.Parallel()
+
+	selected := []*txbuilder.UTXO{{TxHash: "aa", TxPos: 0}, {TxHash: "bb", TxPos: 1}}
+
+	t.Run("returns once every input confirms", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &pollProvider{sets: [][]*txbuilder.UTXO{
+			{{TxHash: "aa", TxPos: 0}, {TxHash: "bb", TxPos: 1}},
+			{{TxHash: "aa", TxPos: 0, Height: 101}, {TxHash: "bb", TxPos: 1}},
+			{{TxHash: "aa", TxPos: 0, Height: 101}, {TxHash: "bb", TxPos: 1, Height: 101}, {TxHash: "cc", TxPos: 0}},
+		}}
+		require.NoError(t, waitForConfirmation(context.Background(), provider, []string{"addr"}, selected, time.Millisecond))
+		assert.Equal(t, 3, provider.calls)
+	})
+
+	t.Run("fails when an input is no longer unspent", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &pollProvider{sets: [][]*txbuilder.UTXO{{{TxHash: "aa", TxPos: 0}}}}
+		err := waitForConfirmation(context.Background(), provider, []string{"addr"}, selected, time.Millisecond)
+		require.ErrorContains(t, err, "bb:1 is no longer unspent")
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		provider := &pollProvider{sets: [][]*txbuilder.UTXO{{{TxHash: "aa", TxPos: 0}, {TxHash: "bb", TxPos: 1}}}}
+		err := waitForConfirmation(ctx, provider, []string{"addr"}, selected, time.Hour)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
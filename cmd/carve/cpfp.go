@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// printPackage reports the fee rate an unconfirmed parent of parentSize bytes
+// paying parentFee and its child tx reach together, the rate a miner weighs
+// them at.
+func printPackage(d *diagnostics, tx *transaction.Transaction, parentSize int, parentFee uint64) {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return
+	}
+	size := tx.Size()
+	if unsigned || dryRun {
+		size = txbuilder.EstimateSize(tx)
+	}
+	total := parentSize + size
+	d.printf(levelInfo, "Package: parent %d bytes paying %d satoshis, child %d bytes paying %d; %.3f sat/byte together",
+		parentSize, parentFee, size, fee, float64(parentFee+fee)/float64(total))
+}
+
+// cpfpParent fetches the unconfirmed transaction txid that --cpfp bumps, and
+// the transactions its inputs spend, to learn the fee it pays. Its outputs
+// among funds' UTXOs move out of them, as they are spent whatever else is
+// selected; they are returned with the parent's size and fee.
+func cpfpParent(ctx context.Context, fetcher chain.TxFetcher, funds *funding, txid string) ([]*txbuilder.UTXO, int, uint64, error) {
+	var outputs []*txbuilder.UTXO
+	kept := funds.utxos[:0]
+	for _, u := range funds.utxos {
+		if !strings.EqualFold(u.TxHash, txid) {
+			kept = append(kept, u)
+			continue
+		}
+		if u.Height > 0 {
+			return nil, 0, 0, fmt.Errorf("--cpfp %s: the transaction is already confirmed", txid)
+		}
+		outputs = append(outputs, u)
+	}
+	if len(outputs) == 0 {
+		return nil, 0, 0, fmt.Errorf("--cpfp %s: none of its outputs are unspent at %s", txid, strings.Join(funds.addrs, ", "))
+	}
+
+	rawTx, err := fetcher.RawTx(ctx, txid)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("--cpfp %s: %w", txid, err)
+	}
+	parent, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("--cpfp %s: parsing transaction: %w", txid, err)
+	}
+
+	// The fee is what the parent's inputs leave over after its outputs
+	sources := make(map[string]*transaction.Transaction)
+	var totalIn uint64
+	for _, in := range parent.Inputs {
+		sourceID := in.SourceTXID.String()
+		source, ok := sources[sourceID]
+		if !ok {
+			raw, err := fetcher.RawTx(ctx, sourceID)
+			if err != nil {
+				return nil, 0, 0, fmt.Errorf("--cpfp %s: fetching input %s: %w", txid, sourceID, err)
+			}
+			if source, err = transaction.NewTransactionFromHex(raw); err != nil {
+				return nil, 0, 0, fmt.Errorf("--cpfp %s: parsing input %s: %w", txid, sourceID, err)
+			}
+			sources[sourceID] = source
+		}
+		if int(in.SourceTxOutIndex) >= len(source.Outputs) {
+			return nil, 0, 0, fmt.Errorf("--cpfp %s: input %s:%d does not exist", txid, sourceID, in.SourceTxOutIndex)
+		}
+		totalIn += source.Outputs[in.SourceTxOutIndex].Satoshis
+	}
+	if totalIn < parent.TotalOutputSatoshis() {
+		return nil, 0, 0, fmt.Errorf("--cpfp %s: its outputs pay more than its inputs hold", txid)
+	}
+	fee := totalIn - parent.TotalOutputSatoshis()
+
+	funds.utxos = kept
+	size := parent.Size()
+	diag.printf(levelInfo, "--cpfp %s: %d bytes paying %d satoshis (%.3f sat/byte); spending its %d output(s)",
+		txid, size, fee, float64(fee)/float64(size), len(outputs))
+	return outputs, size, fee, nil
+}
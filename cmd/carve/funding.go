@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// maxGapLimit is the largest --gap-limit accepted.
+const maxGapLimit = 1000
+
+// funding is where a transaction's inputs come from: the source addresses,
+// their UTXOs, and the key that spends each UTXO.
+type funding struct {
+	addrs        []string                  // Source addresses holding the UTXOs
+	utxos        []*txbuilder.UTXO         // Spendable UTXOs of every source address
+	keys         map[string]*ec.PrivateKey // Key spending each UTXO, by outpoint
+	uncompressed map[string]bool           // UTXOs paying their key's uncompressed address, by outpoint
+	change       *script.Address           // Receives change
+	changeKey    *ec.PrivateKey            // Key of the change address, which spends change under --chain
+	from         *script.Address           // Address of every UTXO when there are no keys, for --unsigned
+	templates    map[string]*templateSpend // --input-template outputs, by outpoint
+	signers      []*ec.PrivateKey          // Every WIF key, whether or not its address holds UTXOs
+}
+
+// templateSpend is how an --input-template output is unlocked: by a P2PK
+// signature of key, or by a fixed unlocking script.
+type templateSpend struct {
+	lockingScript *script.Script
+	key           *ec.PrivateKey
+	unlocking     *script.Script
+}
+
+// singleKeyFunding returns funding from one address whose key spends every
+// UTXO, and which receives change.
+func singleKeyFunding(key *ec.PrivateKey, addr *script.Address, utxos []*txbuilder.UTXO) *funding {
+	f := &funding{change: addr, changeKey: key}
+	f.add(key, addr, utxos)
+	return f
+}
+
+// add records utxos, held by addr and spent by key. An address of the key's
+// uncompressed public key marks them as needing uncompressed signatures.
+func (f *funding) add(key *ec.PrivateKey, addr *script.Address, utxos []*txbuilder.UTXO) {
+	if f.keys == nil {
+		f.keys = make(map[string]*ec.PrivateKey, len(utxos))
+		f.uncompressed = make(map[string]bool)
+	}
+	uncompressed := bytes.Equal(addr.PublicKeyHash, crypto.Hash160(key.PubKey().Uncompressed()))
+	f.addrs = append(f.addrs, addr.AddressString)
+	f.utxos = append(f.utxos, utxos...)
+	for _, u := range utxos {
+		f.keys[outpoint(u)] = key
+		if uncompressed {
+			f.uncompressed[outpoint(u)] = true
+		}
+	}
+}
+
+// addChange records u, an output paying the change address, as spent by
+// the change key.
+func (f *funding) addChange(u *txbuilder.UTXO) {
+	f.keys[outpoint(u)] = f.changeKey
+	if bytes.Equal(f.change.PublicKeyHash, crypto.Hash160(f.changeKey.PubKey().Uncompressed())) {
+		f.uncompressed[outpoint(u)] = true
+	}
+}
+
+// key returns the key that spends u.
+func (f *funding) key(u *txbuilder.UTXO) *ec.PrivateKey {
+	return f.keys[outpoint(u)]
+}
+
+// outpoint returns u's outpoint as txid:vout.
+func outpoint(u *txbuilder.UTXO) string {
+	return fmt.Sprintf("%s:%d", u.TxHash, u.TxPos)
+}
+
+// wifFunding funds the transaction from the addresses of wifList, each
+// input signed by the key of the address it came from. Change goes to the
+// first WIF's address. With several WIFs, an address without UTXOs is
+// skipped, as long as one has some.
+func wifFunding(ctx context.Context, builder *txbuilder.Builder, wifList []string) (*funding, error) {
+	if len(wifList) == 1 {
+		privKey, sourceAddress, compressed, err := deriveKeyAndAddress(wifList[0])
+		if err != nil {
+			return nil, err
+		}
+		builder.Uncompressed = !compressed
+
+		utxos, err := fetchUTXOs(ctx, builder, sourceAddress.AddressString)
+		if err != nil {
+			return nil, err
+		}
+		f := singleKeyFunding(privKey, sourceAddress, utxos)
+		f.signers = []*ec.PrivateKey{privKey}
+		return f, nil
+	}
+
+	f := &funding{}
+	seen := make(map[string]bool, len(wifList))
+	for i, w := range wifList {
+		privKey, sourceAddress, compressed, err := deriveKeyAndAddress(w)
+		if err != nil {
+			return nil, fmt.Errorf("WIF %d: %w", i+1, err)
+		}
+		f.signers = append(f.signers, privKey)
+		if f.change == nil {
+			f.change, f.changeKey = sourceAddress, privKey
+		}
+		if seen[sourceAddress.AddressString] {
+			continue
+		}
+		seen[sourceAddress.AddressString] = true
+
+		utxos, err := builder.FetchUTXOs(ctx, sourceAddress.AddressString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch UTXOs for %s: %w", sourceAddress.AddressString, err)
+		}
+		diag.printf(levelInfo, "%s: %d UTXO(s)", sourceAddress.AddressString, len(utxos))
+		if len(utxos) == 0 {
+			continue
+		}
+		// Selection sizes every input for the larger uncompressed signature
+		// when any key needs it, so the fee never falls short
+		builder.Uncompressed = builder.Uncompressed || !compressed
+		f.add(privKey, sourceAddress, utxos)
+	}
+
+	// --input-template outputs can fund the transaction alone
+	if len(f.utxos) == 0 && len(templated) == 0 {
+		return nil, fmt.Errorf("no UTXOs found for any of the %d WIF addresses", len(wifList))
+	}
+	diag.printf(levelInfo, "Found %d UTXO(s) across %d address(es)", len(f.utxos), len(f.addrs))
+	return f, nil
+}
+
+// addressFunding funds an unsigned transaction from addr, which also
+// receives change.
+func addressFunding(ctx context.Context, builder *txbuilder.Builder, addrStr string) (*funding, error) {
+	addr, err := script.NewAddressFromString(addrStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --from address: %w", err)
+	}
+	// Re-encoding on the chosen network checks the address's prefix
+	if canonical, err := script.NewAddressFromPublicKeyHash(addr.PublicKeyHash, !builder.Testnet); err != nil || canonical.AddressString != addrStr {
+		return nil, fmt.Errorf("--from address %s is not a %s address", addrStr, networkName(builder.Testnet))
+	}
+	diag.printf(levelInfo, "Source address: %s", addr.AddressString)
+
+	utxos, err := fetchUTXOs(ctx, builder, addr.AddressString)
+	if err != nil {
+		return nil, err
+	}
+	return &funding{addrs: []string{addr.AddressString}, utxos: utxos, change: addr, from: addr}, nil
+}
+
+// scanXprv funds the transaction from the addresses of the children of path
+// under the extended private key. It fetches their UTXOs in order and stops
+// once gap addresses in a row hold none; an address counts as unused when it
+// has no UTXOs, whatever its history. Change goes to the first unused address.
+func scanXprv(ctx context.Context, builder *txbuilder.Builder, extended, path string, gap int) (*funding, error) {
+	parent, err := deriveXprvPath(extended, path, builder.Testnet)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(path, "/")
+
+	f := &funding{}
+	spinner := cli.NewProgress("Scanning addresses under "+base, 0)
+	for i, unused := uint32(0), 0; unused < gap && i < bip32.HardenedKeyStart; i++ {
+		child, err := parent.Child(i)
+		if err != nil {
+			spinner.Finish()
+			return nil, fmt.Errorf("deriving %s/%d: %w", base, i, err)
+		}
+		key, err := child.ECPrivKey()
+		if err != nil {
+			spinner.Finish()
+			return nil, fmt.Errorf("deriving %s/%d: %w", base, i, err)
+		}
+		addr, err := keys.Address(key.PubKey(), builder.Testnet, true)
+		if err != nil {
+			spinner.Finish()
+			return nil, fmt.Errorf("generating address %s/%d: %w", base, i, err)
+		}
+
+		utxos, err := builder.FetchUTXOs(ctx, addr.AddressString)
+		spinner.Add(1)
+		if err != nil {
+			spinner.Finish()
+			return nil, fmt.Errorf("failed to fetch UTXOs for %s: %w", addr.AddressString, err)
+		}
+		if len(utxos) == 0 {
+			unused++
+			if f.change == nil {
+				f.change, f.changeKey = addr, key
+			}
+			continue
+		}
+
+		unused = 0
+		diag.printf(levelInfo, "%s/%d %s: %d UTXO(s)", base, i, addr.AddressString, len(utxos))
+		f.add(key, addr, utxos)
+	}
+	spinner.Finish()
+
+	if len(f.utxos) == 0 {
+		return nil, fmt.Errorf("no UTXOs found under %s within a gap limit of %d", base, gap)
+	}
+	diag.printf(levelInfo, "Found %d UTXO(s) across %d address(es)", len(f.utxos), len(f.addrs))
+	diag.printf(levelInfo, "Change address: %s", f.change.AddressString)
+	return f, nil
+}
+
+// deriveXprvPath parses an extended private key, which must be for the
+// testnet network when testnet is set, and derives the key at path, written
+// as m/0 or 44'/236'/0'/0 with ' marking hardened steps.
+func deriveXprvPath(extended, path string, testnet bool) (*bip32.ExtendedKey, error) {
+	key, err := bip32.NewKeyFromString(extended)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --xprv: %w", err)
+	}
+	if !key.IsPrivate() {
+		return nil, fmt.Errorf("--xprv is an extended public key; spending needs the private key")
+	}
+	net, network := &chaincfg.MainNet, "mainnet"
+	if testnet {
+		net, network = &chaincfg.TestNet, "testnet"
+	}
+	if !key.IsForNet(net) {
+		return nil, fmt.Errorf("--xprv is not a %s key", network)
+	}
+
+	rel := strings.TrimSuffix(path, "/")
+	if rel == "m" {
+		rel = ""
+	} else {
+		rel = strings.TrimPrefix(rel, "m/")
+	}
+	child, err := key.DeriveChildFromPath(rel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --path %q: %w", path, err)
+	}
+	return child, nil
+}
+
+// deriveKeyAndAddress parses wifStr and derives the source address. An
+// uncompressed WIF derives the address of the uncompressed public key.
+func deriveKeyAndAddress(wifStr string) (*ec.PrivateKey, *script.Address, bool, error) {
+	w, err := keys.ParseWIF(wifStr)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to parse WIF: %w", err)
+	}
+
+	diag.printf(levelInfo, "Testnet: %t", testnet)
+	diag.printf(levelInfo, "Compressed: %t", w.Compressed)
+
+	// Derive the source address on the --testnet network, whatever the WIF's prefix
+	sourceAddress, err := keys.Address(w.Key.PubKey(), testnet, w.Compressed)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	diag.printf(levelInfo, "Source address: %s", sourceAddress.AddressString)
+
+	return w.Key, sourceAddress, w.Compressed, nil
+}
+
+// fetchUTXOs retrieves UTXOs from the builder's provider and validates them,
+// showing a spinner on a terminal while the provider answers.
+func fetchUTXOs(ctx context.Context, builder *txbuilder.Builder, addr string) ([]*txbuilder.UTXO, error) {
+	fetching = cli.NewProgress("Fetching UTXOs for "+addr, 0)
+	utxos, err := builder.FetchUTXOs(ctx, addr)
+	fetching.Finish()
+	fetching = nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+
+	if len(utxos) == 0 && len(templated) == 0 {
+		return nil, fmt.Errorf("no UTXOs found for address %s", addr)
+	}
+
+	diag.printf(levelInfo, "Found %d UTXO(s)", len(utxos))
+
+	return utxos, nil
+}
+
+// offlineUTXO is one entry of a --utxos file.
+type offlineUTXO struct {
+	TxID          string `json:"txid"`
+	Vout          uint32 `json:"vout"`
+	Satoshis      uint64 `json:"satoshis"`
+	LockingScript string `json:"lockingScript"`
+}
+
+// offlineUTXOs serves the UTXOs of a --utxos file by the address their P2PKH
+// locking script pays.
+type offlineUTXOs map[string][]*txbuilder.UTXO
+
+// UTXOs returns the listed UTXOs paying address.
+func (o offlineUTXOs) UTXOs(_ context.Context, address string) ([]*txbuilder.UTXO, error) {
+	return o[address], nil
+}
+
+// readUTXOFile reads a JSON array of UTXOs from path, or stdin for "-", for
+// building without network access. Every entry is validated, and the error
+// reports each invalid one. Each must be P2PKH, as carve signs only P2PKH
+// inputs, and is listed under its address on the testnet or mainnet network.
+func readUTXOFile(path string, testnet bool) (offlineUTXOs, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // user-specified input file
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading UTXOs: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var entries []offlineUTXO
+	if err = dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parsing UTXOs: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no UTXOs in %s", path)
+	}
+
+	utxos := make(offlineUTXOs)
+	seen := make(map[string]bool, len(entries))
+	var invalid []error
+	for i, e := range entries {
+		addr, err := offlineAddress(e, testnet)
+		if err == nil && seen[fmt.Sprintf("%s:%d", e.TxID, e.Vout)] {
+			err = fmt.Errorf("%s:%d is listed twice", e.TxID, e.Vout)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("utxo %d: %w", i+1, err))
+			continue
+		}
+		u := &txbuilder.UTXO{TxHash: e.TxID, TxPos: e.Vout, Value: e.Satoshis}
+		seen[outpoint(u)] = true
+		utxos[addr] = append(utxos[addr], u)
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%d of %d UTXOs in %s are invalid:\n%w", len(invalid), len(entries), path, errors.Join(invalid...))
+	}
+	return utxos, nil
+}
+
+// offlineAddress validates a --utxos entry and returns the address its
+// locking script pays.
+func offlineAddress(e offlineUTXO, testnet bool) (string, error) {
+	if _, err := chainhash.NewHashFromHex(e.TxID); err != nil || len(e.TxID) != 64 {
+		return "", fmt.Errorf("txid %q is not 64 hex characters", e.TxID)
+	}
+	if e.Satoshis == 0 {
+		return "", fmt.Errorf("satoshis must be positive")
+	}
+	lockingScript, err := script.NewFromHex(e.LockingScript)
+	if err != nil {
+		return "", fmt.Errorf("lockingScript is not hex: %w", err)
+	}
+	if !lockingScript.IsP2PKH() {
+		return "", fmt.Errorf("lockingScript is not P2PKH")
+	}
+	pkh, err := lockingScript.PublicKeyHash()
+	if err != nil {
+		return "", err
+	}
+	addr, err := script.NewAddressFromPublicKeyHash(pkh, !testnet)
+	if err != nil {
+		return "", err
+	}
+	return addr.AddressString, nil
+}
+
+// pickOutpoints returns the UTXOs of funds at the --spend outpoints, in the
+// order given. Each must be an unspent output of a source address.
+func pickOutpoints(funds *funding, specs []string) ([]*txbuilder.UTXO, error) {
+	byOutpoint := make(map[string]*txbuilder.UTXO, len(funds.utxos))
+	for _, u := range funds.utxos {
+		byOutpoint[outpoint(u)] = u
+	}
+
+	picked := make([]*txbuilder.UTXO, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		txid, voutStr, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		vout, err := strconv.ParseUint(voutStr, 10, 32)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid --spend %q: expected txid:vout", spec)
+		}
+		if _, err = chainhash.NewHashFromHex(txid); err != nil || len(txid) != 64 {
+			return nil, fmt.Errorf("invalid --spend %q: txid is not 64 hex characters", spec)
+		}
+		op := fmt.Sprintf("%s:%d", strings.ToLower(txid), vout)
+		if seen[op] {
+			return nil, fmt.Errorf("--spend %s is listed twice", op)
+		}
+		seen[op] = true
+		u, ok := byOutpoint[op]
+		if !ok {
+			return nil, fmt.Errorf("--spend %s is not an unspent output of %s", op, strings.Join(funds.addrs, ", "))
+		}
+		picked = append(picked, u)
+	}
+	diag.printf(levelInfo, "Spending the %d UTXO(s) given with --spend", len(picked))
+	return picked, nil
+}
+
+// inputTemplate is a parsed --input-template: the outpoint, and the fixed
+// unlocking script, or nil for a P2PK signature.
+type inputTemplate struct {
+	txid      string
+	vout      uint32
+	unlocking *script.Script
+}
+
+// parseInputTemplates parses --input-template values, each txid:vout:p2pk
+// or txid:vout:script:<unlocking script hex>.
+func parseInputTemplates(specs []string) ([]inputTemplate, error) {
+	templates := make([]inputTemplate, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(strings.TrimSpace(spec), ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid --input-template %q: expected txid:vout:p2pk or txid:vout:script:<hex>", spec)
+		}
+		txid := strings.ToLower(parts[0])
+		if _, err := chainhash.NewHashFromHex(txid); err != nil || len(txid) != 64 {
+			return nil, fmt.Errorf("invalid --input-template %q: txid is not 64 hex characters", spec)
+		}
+		vout, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --input-template %q: vout is not a number", spec)
+		}
+		t := inputTemplate{txid: txid, vout: uint32(vout)}
+		switch name, hexScript, _ := strings.Cut(parts[2], ":"); strings.ToLower(name) {
+		case "p2pk":
+		case "script":
+			if t.unlocking, err = script.NewFromHex(hexScript); err != nil || len(*t.unlocking) == 0 {
+				return nil, fmt.Errorf("invalid --input-template %q: the unlocking script is not hex", spec)
+			}
+		default:
+			return nil, fmt.Errorf("invalid --input-template %q: unknown template %q, expected p2pk or script:<hex>", spec, name)
+		}
+		op := fmt.Sprintf("%s:%d", txid, vout)
+		if seen[op] {
+			return nil, fmt.Errorf("--input-template %s is listed twice", op)
+		}
+		seen[op] = true
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// addTemplates looks up the outputs of templates through fetcher and adds
+// them to funds, taking them out of its P2PKH UTXOs if listed there. A P2PK
+// output must pay the key of one of the WIFs. It returns them as UTXOs,
+// unconfirmed as far as carve knows.
+func addTemplates(ctx context.Context, fetcher chain.TxFetcher, funds *funding, templates []inputTemplate) ([]*txbuilder.UTXO, error) {
+	funds.templates = make(map[string]*templateSpend, len(templates))
+	utxos := make([]*txbuilder.UTXO, 0, len(templates))
+	for _, t := range templates {
+		op := fmt.Sprintf("%s:%d", t.txid, t.vout)
+		rawTx, err := fetcher.RawTx(ctx, t.txid)
+		if err != nil {
+			return nil, fmt.Errorf("--input-template %s: %w", op, err)
+		}
+		parent, err := transaction.NewTransactionFromHex(rawTx)
+		if err != nil {
+			return nil, fmt.Errorf("--input-template %s: parsing transaction: %w", op, err)
+		}
+		if int(t.vout) >= len(parent.Outputs) {
+			return nil, fmt.Errorf("--input-template %s: the transaction has %d output(s)", op, len(parent.Outputs))
+		}
+		out := parent.Outputs[t.vout]
+
+		spend := &templateSpend{lockingScript: out.LockingScript, unlocking: t.unlocking}
+		if t.unlocking == nil {
+			if !out.LockingScript.IsP2PK() {
+				return nil, fmt.Errorf("--input-template %s: the output is not P2PK", op)
+			}
+			if spend.key = funds.p2pkKey(out.LockingScript); spend.key == nil {
+				return nil, fmt.Errorf("--input-template %s: the output pays a key none of the WIFs hold", op)
+			}
+		}
+		funds.templates[op] = spend
+		utxos = append(utxos, &txbuilder.UTXO{TxHash: t.txid, TxPos: t.vout, Value: out.Satoshis})
+	}
+
+	// An output given a template is spent by it alone
+	kept := funds.utxos[:0]
+	for _, u := range funds.utxos {
+		if funds.templates[outpoint(u)] == nil {
+			kept = append(kept, u)
+		}
+	}
+	funds.utxos = kept
+	diag.printf(levelInfo, "Spending the %d output(s) given with --input-template", len(utxos))
+	return utxos, nil
+}
+
+// p2pkKey returns the WIF key lockingScript, a P2PK script, pays with its
+// compressed or uncompressed public key, or nil if none does.
+func (f *funding) p2pkKey(lockingScript *script.Script) *ec.PrivateKey {
+	pubKey := (*lockingScript)[1 : len(*lockingScript)-1]
+	for _, key := range f.signers {
+		if bytes.Equal(pubKey, key.PubKey().Compressed()) || bytes.Equal(pubKey, key.PubKey().Uncompressed()) {
+			return key
+		}
+	}
+	return nil
+}
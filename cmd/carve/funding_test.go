@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+func TestReadUTXOFile(t *testing.T) {
+	t.Parallel()
+
+	const lock = "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac" // 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "utxos.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("groups UTXOs by the address they pay", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, `[
+  {"txid": "`+testTxID+`", "vout": 0, "satoshis": 10000, "lockingScript": "`+lock+`"},
+  {"txid": "`+testTxID+`", "vout": 1, "satoshis": 500, "lockingScript": "`+lock+`"}
+]`)
+		utxos, err := readUTXOFile(path, false)
+		require.NoError(t, err)
+		got, err := utxos.UTXOs(context.Background(), "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+		require.NoError(t, err)
+		assert.Equal(t, []*txbuilder.UTXO{
+			{TxHash: testTxID, TxPos: 0, Value: 10000},
+			{TxHash: testTxID, TxPos: 1, Value: 500},
+		}, got)
+
+		// The same script pays a different address on testnet
+		testnetUTXOs, err := readUTXOFile(path, true)
+		require.NoError(t, err)
+		assert.Len(t, testnetUTXOs["mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"], 2)
+	})
+
+	t.Run("reports every invalid entry", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, `[
+  {"txid": "`+testTxID+`", "vout": 0, "satoshis": 10000, "lockingScript": "`+lock+`"},
+  {"txid": "abc", "vout": 0, "satoshis": 1, "lockingScript": "`+lock+`"},
+  {"txid": "`+testTxID+`", "vout": 2, "satoshis": 0, "lockingScript": "`+lock+`"},
+  {"txid": "`+testTxID+`", "vout": 3, "satoshis": 1, "lockingScript": "006a"},
+  {"txid": "`+testTxID+`", "vout": 0, "satoshis": 10000, "lockingScript": "`+lock+`"}
+]`)
+		_, err := readUTXOFile(path, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "4 of 5 UTXOs in "+path+" are invalid")
+		assert.Contains(t, err.Error(), `utxo 2: txid "abc" is not 64 hex characters`)
+		assert.Contains(t, err.Error(), "utxo 3: satoshis must be positive")
+		assert.Contains(t, err.Error(), "utxo 4: lockingScript is not P2PKH")
+		assert.Contains(t, err.Error(), "utxo 5: "+testTxID+":0 is listed twice")
+	})
+
+	t.Run("refuses unknown fields and empty lists", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readUTXOFile(write(t, `[{"tx_hash": "`+testTxID+`"}]`), false)
+		require.ErrorContains(t, err, "parsing UTXOs")
+		_, err = readUTXOFile(write(t, `[]`), false)
+		require.ErrorContains(t, err, "no UTXOs in")
+	})
+}
+
+func TestPickOutpoints(t *testing.T) {
+	t.Parallel()
+
+	funds := &funding{
+		addrs: []string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		utxos: []*txbuilder.UTXO{
+			{TxHash: testTxID, TxPos: 0, Value: 1000},
+			{TxHash: testTxID, TxPos: 1, Value: 2000},
+			{TxHash: testTxID, TxPos: 2, Value: 3000},
+		},
+	}
+
+	picked, err := pickOutpoints(funds, []string{testTxID + ":2", strings.ToUpper(testTxID) + ":0"})
+	require.NoError(t, err)
+	assert.Equal(t, []*txbuilder.UTXO{funds.utxos[2], funds.utxos[0]}, picked)
+
+	for spec, want := range map[string]string{
+		testTxID:        `invalid --spend "` + testTxID + `": expected txid:vout`,
+		testTxID + ":x": `invalid --spend "` + testTxID + `:x": expected txid:vout`,
+		"abcd:0":        `invalid --spend "abcd:0": txid is not 64 hex characters`,
+		testTxID + ":3": "--spend " + testTxID + ":3 is not an unspent output of 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+	} {
+		_, err := pickOutpoints(funds, []string{spec})
+		require.EqualError(t, err, want, spec)
+	}
+
+	_, err = pickOutpoints(funds, []string{testTxID + ":1", testTxID + ":1"})
+	require.EqualError(t, err, "--spend "+testTxID+":1 is listed twice")
+}
+
+// testMaster returns the master key of BIP32 test vector 1.
+func testMaster(t *testing.T, net *chaincfg.Params) *bip32.ExtendedKey {
+	t.Helper()
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+	master, err := bip32.NewMaster(seed, net)
+	require.NoError(t, err)
+	return master
+}
+
+// testChild returns the key and mainnet address at path under master.
+func testChild(t *testing.T, master *bip32.ExtendedKey, path string) (*ec.PrivateKey, *script.Address) {
+	t.Helper()
+	child, err := master.DeriveChildFromPath(path)
+	require.NoError(t, err)
+	key, err := child.ECPrivKey()
+	require.NoError(t, err)
+	addr, err := keys.Address(key.PubKey(), false, true)
+	require.NoError(t, err)
+	return key, addr
+}
+
+func TestScanXprv(t *testing.T) {
+	t.Parallel()
+
+	master := testMaster(t, &chaincfg.MainNet)
+	key0, addr0 := testChild(t, master, "0/0")
+	_, addr1 := testChild(t, master, "0/1")
+	key2, addr2 := testChild(t, master, "0/2")
+
+	provider := chain.NewMock()
+	provider.Unspent[addr0.AddressString] = []*chain.UTXO{{TxHash: testTxID, TxPos: 0, Value: 3000, Height: 100}}
+	provider.Unspent[addr2.AddressString] = []*chain.UTXO{{TxHash: testTxID, TxPos: 1, Value: 4000, Height: 100}}
+
+	t.Run("finds UTXOs past a gap shorter than the limit", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		funds, err := scanXprv(context.Background(), builder, master.String(), "m/0", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{addr0.AddressString, addr2.AddressString}, funds.addrs)
+		require.Len(t, funds.utxos, 2)
+		assert.Equal(t, key0, funds.key(funds.utxos[0]))
+		assert.Equal(t, key2, funds.key(funds.utxos[1]))
+		assert.Equal(t, addr1.AddressString, funds.change.AddressString)
+	})
+
+	t.Run("stops at the gap limit", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		funds, err := scanXprv(context.Background(), builder, master.String(), "m/0", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{addr0.AddressString}, funds.addrs)
+	})
+
+	t.Run("signs each input with its own key", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		funds, err := scanXprv(context.Background(), builder, master.String(), "m/0", 2)
+		require.NoError(t, err)
+		tx, err := buildTransaction(builder, funds, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", funds.utxos, 5000, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Inputs, 2)
+		require.Len(t, tx.Outputs, 2)
+		assert.True(t, paysTo(t, tx.Outputs[1].LockingScript, addr1))
+
+		for i, in := range tx.Inputs {
+			prev := &transaction.TransactionOutput{Satoshis: *in.SourceTxSatoshis(), LockingScript: in.SourceTxScript()}
+			err := interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, prev),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			)
+			require.NoError(t, err, "input %d", i)
+		}
+	})
+
+	t.Run("fails when no address in range is funded", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		_, err := scanXprv(context.Background(), builder, master.String(), "m/1", 5)
+		require.EqualError(t, err, "no UTXOs found under m/1 within a gap limit of 5")
+	})
+}
+
+func TestWIFFunding(t *testing.T) {
+	t.Parallel()
+
+	key1, compressed1 := chaintest.Source(t)
+	uncompressed1, err := keys.Address(key1.PubKey(), false, false)
+	require.NoError(t, err)
+	key2, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000002")
+	require.NoError(t, err)
+	compressed2, err := keys.Address(key2.PubKey(), false, true)
+	require.NoError(t, err)
+
+	provider := chain.NewMock()
+	provider.Unspent[compressed1.AddressString] = []*chain.UTXO{{TxHash: testTxID, TxPos: 0, Value: 3000, Height: 100}}
+	provider.Unspent[uncompressed1.AddressString] = []*chain.UTXO{{TxHash: testTxID, TxPos: 1, Value: 4000, Height: 100}}
+	wifList := []string{
+		keys.EncodeWIF(key2, false, true), // No UTXOs, but still receives change
+		keys.EncodeWIF(key1, false, true),
+		keys.EncodeWIF(key1, false, false),
+		keys.EncodeWIF(key1, false, true), // Repeated keys are fetched once
+	}
+
+	t.Run("signs each input with the key of its address", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		funds, err := wifFunding(context.Background(), builder, wifList)
+		require.NoError(t, err)
+		assert.Equal(t, []string{compressed1.AddressString, uncompressed1.AddressString}, funds.addrs)
+		assert.Equal(t, compressed2.AddressString, funds.change.AddressString)
+		assert.True(t, builder.Uncompressed)
+
+		tx, err := buildTransaction(builder, funds, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", funds.utxos, 5000, 1)
+		require.NoError(t, err)
+		require.Len(t, tx.Inputs, 2)
+		require.Len(t, tx.Outputs, 2)
+		assert.True(t, paysTo(t, tx.Outputs[1].LockingScript, compressed2))
+
+		for i, in := range tx.Inputs {
+			prev := &transaction.TransactionOutput{Satoshis: *in.SourceTxSatoshis(), LockingScript: in.SourceTxScript()}
+			err := interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, prev),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			)
+			require.NoError(t, err, "input %d", i)
+		}
+	})
+
+	t.Run("fails when no address is funded", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		_, err := wifFunding(context.Background(), builder, wifList[:1:1])
+		require.EqualError(t, err, "no UTXOs found for address "+compressed2.AddressString)
+
+		_, err = wifFunding(context.Background(), builder, []string{wifList[0], wifList[0]})
+		require.EqualError(t, err, "no UTXOs found for any of the 2 WIF addresses")
+	})
+
+	t.Run("reports which WIF is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, UTXOs: provider}
+		_, err := wifFunding(context.Background(), builder, []string{wifList[1], "not-a-wif"})
+		require.ErrorContains(t, err, "WIF 2: failed to parse WIF")
+	})
+}
+
+func TestDeriveXprvPath(t *testing.T) {
+	t.Parallel()
+
+	master := testMaster(t, &chaincfg.MainNet)
+	for _, path := range []string{"m", "m/", "m/0", "0", "m/44'/236'/0'/0", "44'/236'/0'/0/"} {
+		rel := strings.Trim(strings.TrimPrefix(path, "m"), "/")
+		want, err := master.DeriveChildFromPath(rel)
+		require.NoError(t, err)
+		got, err := deriveXprvPath(master.String(), path, false)
+		require.NoError(t, err, path)
+		assert.Equal(t, want.String(), got.String(), path)
+	}
+
+	xpub, err := master.Neuter()
+	require.NoError(t, err)
+	for name, tc := range map[string]struct {
+		key, path string
+		testnet   bool
+		err       string
+	}{
+		"not a key":        {"xprvnope", "m/0", false, "failed to parse --xprv"},
+		"public key":       {xpub.String(), "m/0", false, "--xprv is an extended public key"},
+		"wrong network":    {master.String(), "m/0", true, "--xprv is not a testnet key"},
+		"bad path element": {master.String(), "m/x", false, `invalid --path "m/x"`},
+	} {
+		_, err := deriveXprvPath(tc.key, tc.path, tc.testnet)
+		require.ErrorContains(t, err, tc.err, name)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+// lockTimeThreshold is the smallest nLockTime read as a Unix time rather
+// than a block height.
+const lockTimeThreshold = 500000000
+
+// parseLockDate returns the timestamp nLockTime of an RFC3339 time, which
+// must fall in the range nLockTime reads as a Unix time rather than a height.
+func parseLockDate(value string) (uint32, error) {
+	when, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --locktime-date %q: expected an RFC3339 time such as 2027-01-02T15:04:05Z", value)
+	}
+	if unix := when.Unix(); unix < lockTimeThreshold || unix > math.MaxUint32 {
+		return 0, fmt.Errorf("--locktime-date %s is outside the times nLockTime can hold, %s to %s", value,
+			time.Unix(lockTimeThreshold, 0).UTC().Format(time.RFC3339), time.Unix(math.MaxUint32, 0).UTC().Format(time.RFC3339))
+	}
+	return uint32(when.Unix()), nil //nolint:gosec // range checked above
+}
+
+// tipLockTime returns the nLockTime of --locktime-tip: the height of the
+// chain tip plus --locktime-offset. Locked to the tip, a transaction only fits
+// the next block, so a miner gains nothing by re-mining the last one to take
+// its fees (anti-fee-sniping).
+func tipLockTime(ctx context.Context, headers chain.HeaderSource) (uint32, error) {
+	tip, err := headers.TipHeight(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch the chain tip: %w", err)
+	}
+	height := int64(tip) + int64(lockOffset)
+	if height < 1 || height >= lockTimeThreshold {
+		return 0, fmt.Errorf("--locktime-offset %d puts the lock time at %d, which is not a block height", lockOffset, height)
+	}
+	diag.printf(levelInfo, "Lock time: block %d (tip %d, offset %d)", height, tip, lockOffset)
+	return uint32(height), nil //nolint:gosec // range checked above
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+
+	"github.com/mrz1836/go-template/internal/multisig"
+)
+
+// parseRecipients parses --to values, each an address and a satoshi amount
+// separated by a colon, into payment outputs in the order given.
+func parseRecipients(specs []string) ([]*transaction.TransactionOutput, error) {
+	payments := make([]*transaction.TransactionOutput, 0, len(specs))
+	for _, spec := range specs {
+		addrStr, amountStr, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --to %q: expected address:sats", spec)
+		}
+		payment, err := paymentOutput(addrStr, amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --to %q: %w", spec, err)
+		}
+		payments = append(payments, payment)
+	}
+	return payments, nil
+}
+
+// customOutputs parses the --p2pk, --multisig and --script-out outputs, in
+// that order, so data outputs given with --script-out come last.
+func customOutputs() ([]*transaction.TransactionOutput, error) {
+	outputs, err := parseP2PKOutputs(p2pkOut)
+	if err != nil {
+		return nil, err
+	}
+	multisigs, err := parseMultisigOutputs(msigOut)
+	if err != nil {
+		return nil, err
+	}
+	scripts, err := parseScriptOutputs(scriptOut)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(outputs, multisigs...), scripts...), nil
+}
+
+// parseP2PKOutputs parses --p2pk values, each a hex public key and a
+// satoshi amount separated by a colon, into outputs paying the key as given,
+// compressed or not.
+func parseP2PKOutputs(specs []string) ([]*transaction.TransactionOutput, error) {
+	outputs := make([]*transaction.TransactionOutput, 0, len(specs))
+	for _, spec := range specs {
+		pubHex, amountStr, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --p2pk %q: expected pubkey:sats", spec)
+		}
+		if _, err := ec.PublicKeyFromString(pubHex); err != nil {
+			return nil, fmt.Errorf("invalid --p2pk %q: %w", spec, err)
+		}
+		amount, err := strconv.ParseUint(amountStr, 10, 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("invalid --p2pk %q: sats must be a positive whole number", spec)
+		}
+		pubKey, _ := hex.DecodeString(pubHex)
+		lockingScript := &script.Script{}
+		if err = lockingScript.AppendPushData(pubKey); err != nil {
+			return nil, err
+		}
+		if err = lockingScript.AppendOpcodes(script.OpCHECKSIG); err != nil {
+			return nil, err
+		}
+		diag.printf(levelInfo, "Paying %d sats to public key %s", amount, strings.ToLower(pubHex))
+		outputs = append(outputs, &transaction.TransactionOutput{Satoshis: amount, LockingScript: lockingScript})
+	}
+	return outputs, nil
+}
+
+// parseMultisigOutputs parses --multisig values, each the required signature
+// count, comma-separated hex public keys and a satoshi amount separated by
+// colons, into bare m-of-n multisig outputs. Keys keep the order given, in
+// which signatures must later appear.
+func parseMultisigOutputs(specs []string) ([]*transaction.TransactionOutput, error) {
+	outputs := make([]*transaction.TransactionOutput, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid --multisig %q: expected m:pub1,pub2,...:sats", spec)
+		}
+		m, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid --multisig %q: m must be a whole number", spec)
+		}
+		var pubKeys []*ec.PublicKey
+		for _, pubHex := range strings.Split(parts[1], ",") {
+			pubKey, err := ec.PublicKeyFromString(strings.TrimSpace(pubHex))
+			if err != nil {
+				return nil, fmt.Errorf("invalid --multisig %q: public key %q: %w", spec, pubHex, err)
+			}
+			pubKeys = append(pubKeys, pubKey)
+		}
+		amount, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("invalid --multisig %q: sats must be a positive whole number", spec)
+		}
+		lockingScript, err := multisig.LockingScript(m, pubKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --multisig %q: %w", spec, err)
+		}
+		diag.printf(levelInfo, "Paying %d sats to a %d-of-%d multisig", amount, m, len(pubKeys))
+		outputs = append(outputs, &transaction.TransactionOutput{Satoshis: amount, LockingScript: lockingScript})
+	}
+	return outputs, nil
+}
+
+// parseScriptOutputs parses --script-out values, each a locking script in
+// hex and a satoshi amount separated by a colon, into outputs in the order
+// given. Only data outputs, starting OP_RETURN or OP_FALSE OP_RETURN, may
+// carry zero satoshis.
+func parseScriptOutputs(specs []string) ([]*transaction.TransactionOutput, error) {
+	outputs := make([]*transaction.TransactionOutput, 0, len(specs))
+	for _, spec := range specs {
+		hexStr, amountStr, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --script-out %q: expected hex:sats", spec)
+		}
+		lockingScript, err := script.NewFromHex(hexStr)
+		if err != nil || len(*lockingScript) == 0 {
+			return nil, fmt.Errorf("invalid --script-out %q: the locking script is not hex", spec)
+		}
+		if _, err = lockingScript.Chunks(); err != nil {
+			return nil, fmt.Errorf("invalid --script-out %q: %w", spec, err)
+		}
+		amount, err := strconv.ParseUint(amountStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --script-out %q: sats must be a whole number", spec)
+		}
+		if amount == 0 && !lockingScript.IsData() {
+			return nil, fmt.Errorf("invalid --script-out %q: only OP_RETURN data outputs may carry 0 sats", spec)
+		}
+		diag.printf(levelInfo, "Paying %d sats to a %d-byte locking script", amount, len(*lockingScript))
+		outputs = append(outputs, &transaction.TransactionOutput{Satoshis: amount, LockingScript: lockingScript})
+	}
+	return outputs, nil
+}
+
+// recipientRow is one recipient read from a --recipients-file, before its
+// fields are validated.
+type recipientRow struct {
+	where   string // Line or entry number, for error messages
+	address string
+	amount  string
+	label   string
+	err     error // Set when the row itself is malformed
+}
+
+// jsonRecipient is one entry of a JSON --recipients-file.
+type jsonRecipient struct {
+	Address  string      `json:"address"`
+	Satoshis json.Number `json:"satoshis"`
+	Label    string      `json:"label"`
+}
+
+// readRecipientsFile reads payment outputs from path: a JSON array of
+// {"address", "satoshis", "label"} objects when it ends in .json, otherwise
+// CSV rows of address,satoshis[,label]. Every row is validated, and the
+// error reports each invalid one.
+func readRecipientsFile(path string) ([]*transaction.TransactionOutput, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-specified input file
+	if err != nil {
+		return nil, fmt.Errorf("reading recipients: %w", err)
+	}
+
+	var rows []recipientRow
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		rows, err = decodeJSONRecipients(data)
+	} else {
+		rows, err = decodeCSVRecipients(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no recipients in %s", path)
+	}
+
+	payments := make([]*transaction.TransactionOutput, 0, len(rows))
+	var invalid []error
+	for _, row := range rows {
+		err := row.err
+		if err == nil {
+			var payment *transaction.TransactionOutput
+			if payment, err = paymentOutput(row.address, row.amount); err == nil {
+				payments = append(payments, payment)
+				continue
+			}
+		}
+		invalid = append(invalid, fmt.Errorf("%s: %w", row.where, err))
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%d of %d recipients in %s are invalid:\n%w", len(invalid), len(rows), path, errors.Join(invalid...))
+	}
+
+	for i, row := range rows {
+		if row.label != "" {
+			diag.printf(levelInfo, "Paying %d sats to %s (%s)", payments[i].Satoshis, strings.TrimSpace(row.address), row.label)
+		} else {
+			diag.printf(levelInfo, "Paying %d sats to %s", payments[i].Satoshis, strings.TrimSpace(row.address))
+		}
+	}
+	return payments, nil
+}
+
+// decodeCSVRecipients reads address,satoshis[,label] rows. Blank lines, lines
+// starting with # and a header row whose first field is "address" are skipped.
+func decodeCSVRecipients(data []byte) ([]recipientRow, error) {
+	r := csv.NewReader(strings.NewReader(string(data)))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var rows []recipientRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(rows) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		row := recipientRow{where: fmt.Sprintf("line %d", line), address: record[0]}
+		switch len(record) {
+		case 2, 3:
+			row.amount = record[1]
+			if len(record) == 3 {
+				row.label = strings.TrimSpace(record[2])
+			}
+		default:
+			row.err = fmt.Errorf("expected address,satoshis[,label], got %d field(s)", len(record))
+		}
+		rows = append(rows, row)
+	}
+}
+
+// decodeJSONRecipients reads an array of recipient objects.
+func decodeJSONRecipients(data []byte) ([]recipientRow, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	var entries []jsonRecipient
+	if err := dec.Decode(&entries); err != nil {
+		return nil, err
+	}
+	rows := make([]recipientRow, 0, len(entries))
+	for i, e := range entries {
+		rows = append(rows, recipientRow{
+			where:   fmt.Sprintf("recipient %d", i+1),
+			address: e.Address,
+			amount:  e.Satoshis.String(),
+			label:   e.Label,
+		})
+	}
+	return rows, nil
+}
+
+// paymentOutput builds a P2PKH output paying amountStr satoshis to addrStr.
+func paymentOutput(addrStr, amountStr string) (*transaction.TransactionOutput, error) {
+	addr, err := script.NewAddressFromString(strings.TrimSpace(addrStr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	amount, err := strconv.ParseUint(strings.TrimSpace(amountStr), 10, 64)
+	if err != nil || amount == 0 {
+		return nil, fmt.Errorf("sats must be a positive whole number")
+	}
+	lockingScript, err := p2pkh.Lock(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction.TransactionOutput{Satoshis: amount, LockingScript: lockingScript}, nil
+}
+
+// splitPayments splits amount into n equal outputs as --split does, the
+// last taking the remainder, paying addrs round-robin: output i goes to
+// addrs[i%len(addrs)].
+func splitPayments(addrs []string, amount uint64, n int) ([]*transaction.TransactionOutput, error) {
+	scripts := make([]*script.Script, 0, len(addrs))
+	for _, addrStr := range addrs {
+		addr, err := script.NewAddressFromString(strings.TrimSpace(addrStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --address %q: %w", addrStr, err)
+		}
+		lockingScript, err := p2pkh.Lock(addr)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, lockingScript)
+	}
+
+	each := amount / uint64(n) //nolint:gosec // n is validated positive
+	if each == 0 {
+		return nil, fmt.Errorf("--sats %d is too small to split into %d outputs", amount, n)
+	}
+	payments := make([]*transaction.TransactionOutput, 0, n)
+	for i := range n {
+		payments = append(payments, &transaction.TransactionOutput{Satoshis: each, LockingScript: scripts[i%len(scripts)]})
+	}
+	payments[n-1].Satoshis += amount % uint64(n) //nolint:gosec // n is validated positive
+	return payments, nil
+}
+
+// paymentsTotal returns the satoshis paid by payments.
+func paymentsTotal(payments []*transaction.TransactionOutput) uint64 {
+	var total uint64
+	for _, p := range payments {
+		total += p.Satoshis
+	}
+	return total
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/multisig"
+)
+
+func TestParseRecipients(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pays each recipient in order", func(t *testing.T) {
+		t.Parallel()
+
+		payments, err := parseRecipients([]string{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa:1000", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH:2500"})
+		require.NoError(t, err)
+		require.Len(t, payments, 2)
+
+		first, err := script.NewAddressFromString("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+		require.NoError(t, err)
+		second, err := script.NewAddressFromString("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1000), payments[0].Satoshis)
+		assert.True(t, paysTo(t, payments[0].LockingScript, first))
+		assert.Equal(t, uint64(2500), payments[1].Satoshis)
+		assert.True(t, paysTo(t, payments[1].LockingScript, second))
+		assert.Equal(t, uint64(3500), paymentsTotal(payments))
+	})
+
+	for _, tc := range []struct {
+		spec, wantErr string
+	}{
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "expected address:sats"},
+		{"not-an-address:1000", "invalid address"},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa:0", "sats must be a positive whole number"},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa:-5", "sats must be a positive whole number"},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseRecipients([]string{tc.spec})
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSplitPayments(t *testing.T) {
+	t.Parallel()
+
+	const addrA, addrB, addrC = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"
+
+	t.Run("pays the addresses in turn, the remainder last", func(t *testing.T) {
+		t.Parallel()
+
+		payments, err := splitPayments([]string{addrA, addrB, addrC}, 10001, 5)
+		require.NoError(t, err)
+		require.Len(t, payments, 5)
+
+		for i, want := range []string{addrA, addrB, addrC, addrA, addrB} {
+			addr, err := script.NewAddressFromString(want)
+			require.NoError(t, err)
+			assert.True(t, paysTo(t, payments[i].LockingScript, addr), "output %d", i)
+		}
+		for _, p := range payments[:4] {
+			assert.Equal(t, uint64(2000), p.Satoshis)
+		}
+		assert.Equal(t, uint64(2001), payments[4].Satoshis)
+		assert.Equal(t, uint64(10001), paymentsTotal(payments))
+	})
+
+	t.Run("rejects an invalid address", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := splitPayments([]string{addrA, ""}, 1000, 2)
+		require.ErrorContains(t, err, `invalid --address ""`)
+	})
+
+	t.Run("rejects outputs of no satoshis", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := splitPayments([]string{addrA, addrB}, 3, 4)
+		require.EqualError(t, err, "--sats 3 is too small to split into 4 outputs")
+	})
+}
+
+func TestParseScriptOutputs(t *testing.T) {
+	t.Parallel()
+
+	// OP_SHA256 <32 bytes> OP_EQUAL, a hash puzzle, and an OP_FALSE OP_RETURN data output
+	puzzle := "a820" + strings.Repeat("ab", 32) + "87"
+	outputs, err := parseScriptOutputs([]string{puzzle + ":546", "006a0568656c6c6f:0"})
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	assert.Equal(t, puzzle, outputs[0].LockingScript.String())
+	assert.Equal(t, uint64(546), outputs[0].Satoshis)
+	assert.True(t, outputs[1].LockingScript.IsData())
+	assert.Equal(t, uint64(0), outputs[1].Satoshis)
+	assert.Equal(t, uint64(546), paymentsTotal(outputs))
+
+	for _, tc := range []struct {
+		spec, wantErr string
+	}{
+		{puzzle, "expected hex:sats"},
+		{"zz:1000", "the locking script is not hex"},
+		{":1000", "the locking script is not hex"},
+		{"4c:1000", "invalid --script-out"},
+		{puzzle + ":-5", "sats must be a whole number"},
+		{puzzle + ":0", "only OP_RETURN data outputs may carry 0 sats"},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseScriptOutputs([]string{tc.spec})
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestParseP2PKOutputs(t *testing.T) {
+	t.Parallel()
+
+	key, _ := chaintest.Source(t)
+	compressed := hex.EncodeToString(key.PubKey().Compressed())
+	uncompressed := hex.EncodeToString(key.PubKey().Uncompressed())
+
+	outputs, err := parseP2PKOutputs([]string{compressed + ":1000", uncompressed + ":2000"})
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	assert.True(t, outputs[0].LockingScript.IsP2PK())
+	assert.Equal(t, "21"+compressed+"ac", outputs[0].LockingScript.String())
+	assert.Equal(t, uint64(1000), outputs[0].Satoshis)
+	assert.Equal(t, "41"+uncompressed+"ac", outputs[1].LockingScript.String())
+
+	for _, tc := range []struct {
+		spec, wantErr string
+	}{
+		{compressed, "expected pubkey:sats"},
+		{"02abcd:1000", "invalid --p2pk"},
+		{compressed + ":0", "sats must be a positive whole number"},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseP2PKOutputs([]string{tc.spec})
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestParseMultisigOutputs(t *testing.T) {
+	t.Parallel()
+
+	var pubs []string
+	for _, k := range []string{"01", "02", "03"} {
+		key, err := ec.PrivateKeyFromHex(strings.Repeat("0", 62) + k)
+		require.NoError(t, err)
+		pubs = append(pubs, hex.EncodeToString(key.PubKey().Compressed()))
+	}
+
+	outputs, err := parseMultisigOutputs([]string{"2:" + strings.Join(pubs, ",") + ":5000"})
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	assert.Equal(t, uint64(5000), outputs[0].Satoshis)
+	m, pubKeys, err := multisig.ParseLockingScript(outputs[0].LockingScript)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m)
+	require.Len(t, pubKeys, 3)
+	assert.Equal(t, pubs[2], hex.EncodeToString(pubKeys[2].Compressed()))
+
+	for _, tc := range []struct {
+		spec, wantErr string
+	}{
+		{"2:" + pubs[0] + "," + pubs[1], "expected m:pub1,pub2,...:sats"},
+		{"x:" + pubs[0] + ":1000", "m must be a whole number"},
+		{"1:" + pubs[0] + ",zz:1000", `public key "zz"`},
+		{"3:" + pubs[0] + "," + pubs[1] + ":1000", "required signatures must be between 1 and 2"},
+		{"1:" + pubs[0] + "," + pubs[0] + ":1000", "duplicate public key"},
+		{"1:" + pubs[0] + ":0", "sats must be a positive whole number"},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseMultisigOutputs([]string{tc.spec})
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestReadRecipientsFile(t *testing.T) {
+	t.Parallel()
+
+	write := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("csv rows with a header, comments and labels", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "payroll.csv", `address,satoshis,label
+# March payroll
+1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa,1000,"Smith, J."
+
+1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH, 2500
+`)
+		payments, err := readRecipientsFile(path)
+		require.NoError(t, err)
+		require.Len(t, payments, 2)
+		assert.Equal(t, uint64(1000), payments[0].Satoshis)
+		assert.Equal(t, uint64(2500), payments[1].Satoshis)
+	})
+
+	t.Run("json entries", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "airdrop.json", `[
+  {"address": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "satoshis": 1000, "label": "alice"},
+  {"address": "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "satoshis": 2500}
+]`)
+		payments, err := readRecipientsFile(path)
+		require.NoError(t, err)
+		require.Len(t, payments, 2)
+
+		_, source := chaintest.Source(t)
+		assert.True(t, paysTo(t, payments[1].LockingScript, source))
+		assert.Equal(t, uint64(3500), paymentsTotal(payments))
+	})
+
+	t.Run("reports every invalid row", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "bad.csv", `1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa,1000
+not-an-address,1000
+1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH,0
+1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH
+`)
+		_, err := readRecipientsFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 of 4 recipients in "+path+" are invalid")
+		assert.Contains(t, err.Error(), "line 2: invalid address")
+		assert.Contains(t, err.Error(), "line 3: sats must be a positive whole number")
+		assert.Contains(t, err.Error(), "line 4: expected address,satoshis[,label], got 1 field(s)")
+	})
+
+	t.Run("json entries are numbered", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "bad.json", `[{"address": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"}]`)
+		_, err := readRecipientsFile(path)
+		require.ErrorContains(t, err, "recipient 1: sats must be a positive whole number")
+	})
+
+	t.Run("unknown json fields", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "typo.json", `[{"address": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "sats": 1000}]`)
+		_, err := readRecipientsFile(path)
+		require.ErrorContains(t, err, "parsing "+path)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "empty.csv", "address,satoshis\n")
+		_, err := readRecipientsFile(path)
+		require.ErrorContains(t, err, "no recipients in")
+	})
+}
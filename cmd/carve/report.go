@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Plan records the outpoints a transaction consumes and the change outpoint it
+// creates, so wallet state can be tracked across cycles without re-querying.
+type Plan struct {
+	TxID    string        `json:"txid"`
+	Network string        `json:"network"`
+	Address string        `json:"address"` // Change address; with a single --wif, also the inputs' address
+	Spent   []*chain.UTXO `json:"spent"`
+	Change  *chain.UTXO   `json:"change,omitempty"` // Unconfirmed, so its height is 0
+	Fee     uint64        `json:"fee"`
+}
+
+// DryRun is what --dry-run prints: the transaction carve would build, before
+// it is signed.
+type DryRun struct {
+	Network  string        `json:"network"`
+	Inputs   []*chain.UTXO `json:"inputs"`
+	Outputs  []txOutput    `json:"outputs"`
+	Size     int           `json:"size"` // Estimated bytes once signed
+	Fee      uint64        `json:"fee"`
+	FeeRate  float64       `json:"feeRate"`            // Satoshis per kilobyte of the estimated size
+	Absorbed uint64        `json:"absorbed,omitempty"` // Change added to the fee by --absorb-change
+}
+
+// Result is what --json prints: the signed transaction and what it spends
+// and pays.
+type Result struct {
+	TxID      string                 `json:"txid"`
+	RawTx     string                 `json:"rawtx"`
+	EF        string                 `json:"ef,omitempty"`   // Extended Format hex, with --ef
+	BEEF      string                 `json:"beef,omitempty"` // BEEF hex, with --beef
+	Network   string                 `json:"network"`
+	Size      int                    `json:"size"`
+	Fee       uint64                 `json:"fee"`
+	FeeRate   float64                `json:"feeRate"` // Satoshis per kilobyte of the signed size
+	Inputs    []*chain.UTXO          `json:"inputs"`
+	Outputs   []txOutput             `json:"outputs"`
+	Change    *chain.UTXO            `json:"change,omitempty"`    // Unconfirmed, so its height is 0
+	Absorbed  uint64                 `json:"absorbed,omitempty"`  // Change added to the fee by --absorb-change
+	Broadcast *chain.BroadcastResult `json:"broadcast,omitempty"` // The broadcaster's response, with --broadcast
+}
+
+// txOutput is an output of a --dry-run or --json transaction. Outputs other
+// than P2PKH have their locking script in place of an address.
+type txOutput struct {
+	Address  string `json:"address,omitempty"`
+	Script   string `json:"script,omitempty"`
+	Satoshis uint64 `json:"satoshis"`
+	Change   bool   `json:"change,omitempty"`
+}
+
+// buildPlan records the inputs tx spends and its change output at index
+// change; send-all and absorbed change create none (-1).
+func buildPlan(tx *transaction.Transaction, spent []*txbuilder.UTXO, sourceAddr string, change int) (*Plan, error) {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute fee: %w", err)
+	}
+
+	return &Plan{
+		TxID:    tx.TxID().String(),
+		Network: networkName(testnet),
+		Address: sourceAddr,
+		Spent:   spent,
+		Change:  changeOf(tx, change),
+		Fee:     fee,
+	}, nil
+}
+
+// buildDryRun describes the unsigned tx spending selected, with its change
+// output at index change as in buildPlan.
+func buildDryRun(tx *transaction.Transaction, selected []*txbuilder.UTXO, change int, absorbed uint64) (*DryRun, error) {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute fee: %w", err)
+	}
+
+	outputs, err := describeOutputs(tx, change)
+	if err != nil {
+		return nil, err
+	}
+	size := txbuilder.EstimateSize(tx)
+	return &DryRun{
+		Network:  networkName(testnet),
+		Inputs:   inputOrder(tx, selected),
+		Outputs:  outputs,
+		Size:     size,
+		Fee:      fee,
+		FeeRate:  feeRate(fee, size),
+		Absorbed: absorbed,
+	}, nil
+}
+
+// buildResult describes the signed tx spending selected, with its change
+// output at index change as in buildPlan.
+func buildResult(tx *transaction.Transaction, selected []*txbuilder.UTXO, change int, absorbed uint64) (*Result, error) {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute fee: %w", err)
+	}
+	outputs, err := describeOutputs(tx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	size := tx.Size()
+	result := &Result{
+		TxID:     tx.TxID().String(),
+		RawTx:    tx.String(),
+		Network:  networkName(testnet),
+		Size:     size,
+		Fee:      fee,
+		FeeRate:  feeRate(fee, size),
+		Inputs:   inputOrder(tx, selected),
+		Outputs:  outputs,
+		Absorbed: absorbed,
+		Change:   changeOf(tx, change),
+	}
+	return result, nil
+}
+
+// inputOrder returns selected in the order tx spends them, which --bip69
+// may have changed.
+func inputOrder(tx *transaction.Transaction, selected []*txbuilder.UTXO) []*txbuilder.UTXO {
+	byOutpoint := make(map[string]*txbuilder.UTXO, len(selected))
+	for _, u := range selected {
+		byOutpoint[outpoint(u)] = u
+	}
+	ordered := make([]*txbuilder.UTXO, 0, len(tx.Inputs))
+	for _, in := range tx.Inputs {
+		if u := byOutpoint[fmt.Sprintf("%s:%d", in.SourceTXID, in.SourceTxOutIndex)]; u != nil {
+			ordered = append(ordered, u)
+		}
+	}
+	return ordered
+}
+
+// describeOutputs lists the address, or locking script when not P2PKH, and
+// amount of each of tx's outputs, marking the one at index change.
+func describeOutputs(tx *transaction.Transaction, change int) ([]txOutput, error) {
+	outputs := make([]txOutput, 0, len(tx.Outputs))
+	for i, out := range tx.Outputs {
+		if !out.LockingScript.IsP2PKH() {
+			outputs = append(outputs, txOutput{Script: out.LockingScript.String(), Satoshis: out.Satoshis})
+			continue
+		}
+		pkh, err := out.LockingScript.PublicKeyHash()
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		addr, err := script.NewAddressFromPublicKeyHash(pkh, !testnet)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		outputs = append(outputs, txOutput{
+			Address:  addr.AddressString,
+			Satoshis: out.Satoshis,
+			Change:   i == change,
+		})
+	}
+	return outputs, nil
+}
+
+// feeRate returns fee in satoshis per kilobyte of size, to two decimals.
+func feeRate(fee uint64, size int) float64 {
+	return math.Round(float64(fee)*1000/float64(size)*100) / 100
+}
+
+// printJSON prints v on stdout as indented JSON; what names it in errors.
+func printJSON(v any, what string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", what, err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// writePlan writes plan to path as indented JSON.
+func writePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err = os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// printSummary reports the fee and any change absorbed into it to d: by
+// default only with --absorb-change, and always from -v up.
+func printSummary(d *diagnostics, tx *transaction.Transaction, absorbed uint64) {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return
+	}
+	level := levelInfo
+	if absorb > 0 {
+		level = levelNormal
+	}
+	switch {
+	case absorbed > 0:
+		d.printf(level, "Fee: %d satoshis (includes %d satoshis of absorbed change)", fee, absorbed)
+	case absorb > 0:
+		d.printf(level, "Fee: %d satoshis (no change absorbed)", fee)
+	default:
+		// Unsigned, the size is the estimate the fee was based on
+		size := tx.Size()
+		if unsigned || dryRun {
+			size = txbuilder.EstimateSize(tx)
+		}
+		d.printf(level, "Fee: %d satoshis, %d input(s), %d output(s), %d bytes", fee, len(tx.Inputs), len(tx.Outputs), size)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+func TestBuildPlan(t *testing.T) {
+	t.Parallel()
+
+	const destAddr = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	key, source := chaintest.Source(t)
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 100}}
+
+	t.Run("records the spent inputs and change outpoint", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100}
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 1000, 2)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 3)
+
+		plan, err := buildPlan(tx, utxos, source.AddressString, builder.Change)
+		require.NoError(t, err)
+		assert.Equal(t, tx.TxID().String(), plan.TxID)
+		assert.Equal(t, source.AddressString, plan.Address)
+		assert.Equal(t, utxos, plan.Spent)
+		assert.Equal(t, uint64(txbuilder.MinFee), plan.Fee)
+		require.NotNil(t, plan.Change)
+		assert.Equal(t, &txbuilder.UTXO{TxHash: plan.TxID, TxPos: 2, Value: 10000 - 1000 - txbuilder.MinFee}, plan.Change)
+	})
+
+	t.Run("send-all creates no change", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 0, 1)
+		require.NoError(t, err)
+
+		plan, err := buildPlan(tx, utxos, source.AddressString, changeVout(builder, 0))
+		require.NoError(t, err)
+		assert.Nil(t, plan.Change)
+	})
+
+	t.Run("absorbed change creates no change", func(t *testing.T) {
+		t.Parallel()
+
+		absorber := &txbuilder.Builder{FeePerKb: 100, AbsorbChange: 1000}
+		tx, err := buildTransaction(absorber, singleKeyFunding(key, source, utxos), destAddr, utxos, 9500, 1)
+		require.NoError(t, err)
+
+		plan, err := buildPlan(tx, utxos, source.AddressString, absorber.Change)
+		require.NoError(t, err)
+		assert.Nil(t, plan.Change)
+		assert.Equal(t, uint64(500), plan.Fee)
+	})
+}
+
+func TestBuildDryRun(t *testing.T) {
+	t.Parallel()
+
+	const destAddr = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	key, source := chaintest.Source(t)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 100}}
+
+	t.Run("describes the unsigned transaction", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 1000, DryRun: true}
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 1000, 2)
+		require.NoError(t, err)
+		assert.Nil(t, tx.Inputs[0].UnlockingScript)
+
+		preview, err := buildDryRun(tx, utxos, builder.Change, builder.Absorbed)
+		require.NoError(t, err)
+		assert.Equal(t, "mainnet", preview.Network)
+		assert.Equal(t, utxos, preview.Inputs)
+		assert.Equal(t, 148+3*34+10, preview.Size)
+		assert.Equal(t, uint64(260), preview.Fee)
+		assert.InDelta(t, 1000, preview.FeeRate, 0.01)
+		assert.Equal(t, []txOutput{
+			{Address: destAddr, Satoshis: 500},
+			{Address: destAddr, Satoshis: 500},
+			{Address: source.AddressString, Satoshis: 10000 - 1000 - 260, Change: true},
+		}, preview.Outputs)
+	})
+
+	t.Run("send-all has no change", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100, DryRun: true}
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 0, 1)
+		require.NoError(t, err)
+
+		preview, err := buildDryRun(tx, utxos, changeVout(builder, 0), builder.Absorbed)
+		require.NoError(t, err)
+		assert.Equal(t, []txOutput{{Address: destAddr, Satoshis: 10000 - txbuilder.MinFee}}, preview.Outputs)
+	})
+}
+
+func TestBuildResult(t *testing.T) {
+	t.Parallel()
+
+	const destAddr = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	key, source := chaintest.Source(t)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 100}}
+
+	t.Run("describes the signed transaction and its change", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100}
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 1000, 1)
+		require.NoError(t, err)
+
+		result, err := buildResult(tx, utxos, builder.Change, builder.Absorbed)
+		require.NoError(t, err)
+		assert.Equal(t, tx.TxID().String(), result.TxID)
+		assert.Equal(t, tx.String(), result.RawTx)
+		assert.Equal(t, "mainnet", result.Network)
+		assert.Equal(t, tx.Size(), result.Size)
+		assert.Equal(t, uint64(txbuilder.MinFee), result.Fee)
+		assert.InDelta(t, float64(txbuilder.MinFee)*1000/float64(tx.Size()), result.FeeRate, 0.01)
+		assert.Equal(t, utxos, result.Inputs)
+		assert.Equal(t, []txOutput{
+			{Address: destAddr, Satoshis: 1000},
+			{Address: source.AddressString, Satoshis: 10000 - 1000 - txbuilder.MinFee, Change: true},
+		}, result.Outputs)
+		assert.Equal(t, &chain.UTXO{TxHash: result.TxID, TxPos: 1, Value: 10000 - 1000 - txbuilder.MinFee}, result.Change)
+	})
+
+	t.Run("send-all has no change", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100}
+		tx, err := buildTransaction(builder, singleKeyFunding(key, source, utxos), destAddr, utxos, 0, 1)
+		require.NoError(t, err)
+
+		result, err := buildResult(tx, utxos, changeVout(builder, 0), builder.Absorbed)
+		require.NoError(t, err)
+		assert.Nil(t, result.Change)
+		assert.Equal(t, []txOutput{{Address: destAddr, Satoshis: 10000 - txbuilder.MinFee}}, result.Outputs)
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+)
+
+// sigHashFlags are the SIGHASH flags given with --sighash: one for every
+// input (0 = ALL|FORKID), and overrides by input index.
+type sigHashFlags struct {
+	all    sighash.Flag
+	inputs map[int]sighash.Flag
+}
+
+// parseSigHashes parses --sighash values, each a flag for every input or
+// INDEX=FLAG for the input at INDEX.
+func parseSigHashes(specs []string) (sigHashFlags, error) {
+	var flags sigHashFlags
+	for _, spec := range specs {
+		name, index := spec, -1
+		if before, after, ok := strings.Cut(spec, "="); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(before))
+			if err != nil || n < 0 {
+				return sigHashFlags{}, fmt.Errorf("--sighash %q: input index must be a non-negative integer", spec)
+			}
+			name, index = after, n
+		}
+		flag, err := parseSigHash(name)
+		if err != nil {
+			return sigHashFlags{}, fmt.Errorf("--sighash %q: %w", spec, err)
+		}
+
+		switch {
+		case index < 0 && flags.all != 0:
+			return sigHashFlags{}, fmt.Errorf("--sighash %q: the flag for every input is already given", spec)
+		case index < 0:
+			flags.all = flag
+		case flags.inputs[index] != 0:
+			return sigHashFlags{}, fmt.Errorf("--sighash %q: input %d already has a flag", spec, index)
+		default:
+			if flags.inputs == nil {
+				flags.inputs = make(map[int]sighash.Flag)
+			}
+			flags.inputs[index] = flag
+		}
+	}
+	return flags, nil
+}
+
+// parseSigHash parses a SIGHASH flag such as ALL|FORKID or
+// single+anyonecanpay+forkid: exactly one of ALL, NONE and SINGLE, plus
+// optionally ANYONECANPAY and FORKID, joined by | or +. A SIGHASH_ prefix on
+// each part is allowed.
+func parseSigHash(name string) (sighash.Flag, error) {
+	var base, extra sighash.Flag
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '|' || r == '+' }) {
+		part = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(part)), "SIGHASH_")
+		var bit sighash.Flag
+		switch part {
+		case "ALL":
+			bit = sighash.All
+		case "NONE":
+			bit = sighash.None
+		case "SINGLE":
+			bit = sighash.Single
+		case "ANYONECANPAY":
+			bit = sighash.AnyOneCanPay
+		case "FORKID":
+			bit = sighash.ForkID
+		default:
+			return 0, fmt.Errorf("unknown SIGHASH flag %q: use ALL, NONE or SINGLE, plus ANYONECANPAY or FORKID", part)
+		}
+		if bit&sighash.Mask != 0 {
+			if base != 0 {
+				return 0, fmt.Errorf("only one of ALL, NONE and SINGLE may be given")
+			}
+			base = bit
+			continue
+		}
+		if extra&bit != 0 {
+			return 0, fmt.Errorf("%s is given twice", part)
+		}
+		extra |= bit
+	}
+	if base == 0 {
+		return 0, fmt.Errorf("one of ALL, NONE and SINGLE is required")
+	}
+	return base | extra, nil
+}
+
+// warnLegacySigHash warns about --sighash flags without FORKID, whose
+// signatures use the original pre-fork digest.
+func warnLegacySigHash(flags sigHashFlags) {
+	all := []sighash.Flag{flags.all}
+	for _, flag := range flags.inputs {
+		all = append(all, flag)
+	}
+	for _, flag := range all {
+		if flag != 0 && !flag.Has(sighash.ForkID) {
+			diag.printf(levelNormal, "Warning: --sighash %s has no FORKID, so it signs the original pre-fork digest, which nodes may reject", flag)
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSigHashes(t *testing.T) {
+	t.Parallel()
+
+	flags, err := parseSigHashes([]string{"SINGLE|ANYONECANPAY|FORKID", "1=sighash_all+sighash_forkid", "2=NONE"})
+	require.NoError(t, err)
+	assert.Equal(t, sigHashFlags{
+		all:    sighash.SingleForkID | sighash.AnyOneCanPay,
+		inputs: map[int]sighash.Flag{1: sighash.AllForkID, 2: sighash.None},
+	}, flags)
+
+	flags, err = parseSigHashes(nil)
+	require.NoError(t, err)
+	assert.Equal(t, sigHashFlags{}, flags)
+
+	for spec, want := range map[string]string{
+		"ANYONECANPAY|FORKID": "one of ALL, NONE and SINGLE is required",
+		"ALL|NONE":            "only one of ALL, NONE and SINGLE may be given",
+		"ALL|FORKID|FORKID":   "FORKID is given twice",
+		"ALL|ANYONE":          `unknown SIGHASH flag "ANYONE"`,
+		"-1=ALL":              "input index must be a non-negative integer",
+	} {
+		_, err = parseSigHashes([]string{spec})
+		require.ErrorContains(t, err, want, spec)
+	}
+
+	_, err = parseSigHashes([]string{"ALL|FORKID", "NONE|FORKID"})
+	require.ErrorContains(t, err, "the flag for every input is already given")
+	_, err = parseSigHashes([]string{"0=ALL|FORKID", "0=NONE|FORKID"})
+	require.ErrorContains(t, err, "input 0 already has a flag")
+}
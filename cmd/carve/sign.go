@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// UnsignedTx is what --unsigned prints and carve sign reads: an unsigned
+// transaction and the outputs its inputs spend, in input order, which a
+// signature commits to.
+type UnsignedTx struct {
+	Network string        `json:"network"`
+	Tx      string        `json:"tx"` // Raw hex with empty unlocking scripts
+	Inputs  []offlineUTXO `json:"inputs"`
+	Fee     uint64        `json:"fee"` // Fee once signed, as inputs are sized for compressed keys
+}
+
+// SigningRequest is what carve digests prints and carve assemble reads: an
+// unsigned transaction and, for each input, the digest an external signer
+// signs, with the signature and public key it returns filled in.
+type SigningRequest struct {
+	Network string        `json:"network"`
+	Tx      string        `json:"tx"` // Raw hex with empty unlocking scripts
+	Inputs  []digestInput `json:"inputs"`
+	Fee     uint64        `json:"fee"`
+}
+
+// digestInput is one input of a SigningRequest: the output it spends, the
+// SIGHASH flag and digest to sign, and once signed, the signature and the
+// public key that made it.
+type digestInput struct {
+	offlineUTXO
+	SigHash   string `json:"sighash"`             // Flag such as ALL|FORKID
+	Digest    string `json:"digest"`              // Hex of the 32 bytes to sign as is, without hashing them again
+	Signature string `json:"signature,omitempty"` // DER hex, with or without the SIGHASH byte
+	PubKey    string `json:"pubkey,omitempty"`    // Compressed public key hex (default: --pubkey)
+}
+
+// signCmd signs the output of --unsigned with the key of its inputs' address.
+var signCmd = &cobra.Command{
+	Use:   "sign [file|-]",
+	Short: "Sign a transaction built with --unsigned",
+	Long: "Reads the JSON that carve --unsigned prints, from a file or stdin, and prints the signed raw transaction hex. " +
+		"Every input must pay the WIF's compressed address, so the key never needs to reach the machine that built the transaction",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var given []string
+		if wif != "" {
+			given = []string{wif}
+		}
+		resolved, err := resolveWIFs(cmd, given)
+		if err != nil {
+			return err
+		}
+		if len(resolved) != 1 {
+			return fmt.Errorf("carve sign takes one WIF, got %d", len(resolved))
+		}
+		path := "-"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		payload, err := readUnsigned(path)
+		if err != nil {
+			return err
+		}
+		flags, err := parseSigHashes(sigSpecs)
+		if err != nil {
+			return usageError(cmd, err)
+		}
+		warnLegacySigHash(flags)
+		tx, err := signUnsigned(payload, resolved[0], flags)
+		if err != nil {
+			return err
+		}
+		return printTx(context.Background(), tx)
+	},
+}
+
+// digestsCmd prints the digests an external signer must sign for the output
+// of --unsigned.
+var digestsCmd = &cobra.Command{
+	Use:   "digests [file|-]",
+	Short: "Print the SIGHASH digest of each input of a transaction built with --unsigned",
+	Long: "Reads the JSON that carve --unsigned prints, from a file or stdin, and prints it with each input's SIGHASH digest " +
+		"for an HSM or hardware wallet to sign. Fill in each input's signature and public key and pass the file to carve assemble, " +
+		"so the key never reaches carve at all",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "-"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		payload, err := readUnsigned(path)
+		if err != nil {
+			return err
+		}
+		flags, err := parseSigHashes(sigSpecs)
+		if err != nil {
+			return usageError(cmd, err)
+		}
+		warnLegacySigHash(flags)
+		req, err := signingRequest(payload, flags)
+		if err != nil {
+			return err
+		}
+		return printJSON(req, "signing request")
+	},
+}
+
+// assembleCmd builds the signed transaction from a carve digests request
+// whose signatures an external signer filled in.
+var assembleCmd = &cobra.Command{
+	Use:   "assemble [file|-]",
+	Short: "Assemble a transaction from the signatures an external signer added to carve digests output",
+	Long: "Reads the JSON that carve digests prints, with a DER signature and public key added to each input, from a file or stdin, " +
+		"checks every signature against the transaction, and prints the signed raw transaction hex",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := "-"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		req, err := readSigningRequest(path)
+		if err != nil {
+			return err
+		}
+		tx, err := assembleSigned(req, pubKeyHex)
+		if err != nil {
+			return err
+		}
+		return printTx(context.Background(), tx)
+	},
+}
+
+// readUnsigned reads an UnsignedTx from path, or stdin for "-".
+func readUnsigned(path string) (*UnsignedTx, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // user-specified input file
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading unsigned transaction: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var payload UnsignedTx
+	if err = dec.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("parsing unsigned transaction: %w", err)
+	}
+	return &payload, nil
+}
+
+// signUnsigned signs every input of payload's transaction with the WIF's key.
+// Each listed input must match the transaction's outpoint at its index and
+// pay the key's compressed address on the payload's network.
+func signUnsigned(payload *UnsignedTx, wifStr string, flags sigHashFlags) (*transaction.Transaction, error) {
+	tx, err := parseUnsigned(payload.Network, payload.Tx, payload.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	w, err := keys.ParseWIF(wifStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if !w.Compressed {
+		return nil, fmt.Errorf("the WIF is for an uncompressed key; --unsigned sizes the fee for compressed keys")
+	}
+	addr, err := keys.Address(w.Key.PubKey(), payload.Network == "testnet", true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive address: %w", err)
+	}
+	lock, err := p2pkh.Lock(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &txbuilder.Builder{SigHash: flags.all, InputSigHash: flags.inputs}
+	if err = signer.CheckSigHashes(tx); err != nil {
+		return nil, err
+	}
+	for i, input := range tx.Inputs {
+		if !bytes.Equal(*input.SourceTxOutput().LockingScript, *lock) {
+			return nil, fmt.Errorf("input %d does not pay %s, the WIF's address", i, addr.AddressString)
+		}
+		flag := signer.SigHashFor(i)
+		if input.UnlockingScriptTemplate, err = p2pkh.Unlock(w.Key, &flag); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Sign(); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return tx, nil
+}
+
+// parseUnsigned parses an unsigned transaction and gives each input the
+// output listed for it, which must be the transaction's outpoint at that
+// index, on the mainnet or testnet network.
+func parseUnsigned(network, rawTx string, inputs []offlineUTXO) (*transaction.Transaction, error) {
+	if network != "mainnet" && network != "testnet" {
+		return nil, fmt.Errorf("unknown network %q", network)
+	}
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unsigned transaction: %w", err)
+	}
+	if len(inputs) != len(tx.Inputs) {
+		return nil, fmt.Errorf("%d input(s) listed for a transaction with %d", len(inputs), len(tx.Inputs))
+	}
+	for i, input := range tx.Inputs {
+		listed := inputs[i]
+		if listed.TxID != input.SourceTXID.String() || listed.Vout != input.SourceTxOutIndex {
+			return nil, fmt.Errorf("input %d is %s:%d, but %s:%d is listed", i, input.SourceTXID, input.SourceTxOutIndex, listed.TxID, listed.Vout)
+		}
+		lock, err := script.NewFromHex(listed.LockingScript)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: invalid locking script: %w", i, err)
+		}
+		input.SetSourceTxOutput(&transaction.TransactionOutput{Satoshis: listed.Satoshis, LockingScript: lock})
+	}
+	return tx, nil
+}
+
+// signingRequest computes the SIGHASH digest of every input of payload's
+// transaction, each of which must be P2PKH, for an external signer.
+func signingRequest(payload *UnsignedTx, flags sigHashFlags) (*SigningRequest, error) {
+	tx, err := parseUnsigned(payload.Network, payload.Tx, payload.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	signer := &txbuilder.Builder{SigHash: flags.all, InputSigHash: flags.inputs}
+	if err = signer.CheckSigHashes(tx); err != nil {
+		return nil, err
+	}
+
+	req := &SigningRequest{Network: payload.Network, Tx: payload.Tx, Inputs: make([]digestInput, 0, len(tx.Inputs)), Fee: payload.Fee}
+	for i, input := range tx.Inputs {
+		if !input.SourceTxOutput().LockingScript.IsP2PKH() {
+			return nil, fmt.Errorf("input %d is not P2PKH, the only kind carve assembles", i)
+		}
+		flag := signer.SigHashFor(i)
+		digest, err := tx.CalcInputSignatureHash(uint32(i), flag) //nolint:gosec // input counts fit in uint32
+		if err != nil {
+			return nil, fmt.Errorf("input %d: computing the digest: %w", i, err)
+		}
+		req.Inputs = append(req.Inputs, digestInput{offlineUTXO: payload.Inputs[i], SigHash: flag.String(), Digest: hex.EncodeToString(digest)})
+	}
+	return req, nil
+}
+
+// readSigningRequest reads a SigningRequest from path, or stdin for "-".
+func readSigningRequest(path string) (*SigningRequest, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // user-specified input file
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading signing request: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var req SigningRequest
+	if err = dec.Decode(&req); err != nil {
+		return nil, fmt.Errorf("parsing signing request: %w", err)
+	}
+	return &req, nil
+}
+
+// assembleSigned builds the P2PKH unlocking script of every input of req's
+// transaction from its signature and public key, defaultPub when it lists
+// none. Each input's digest is computed again, so a signature over anything
+// else, or a key the input does not pay, fails before any hex is printed.
+func assembleSigned(req *SigningRequest, defaultPub string) (*transaction.Transaction, error) {
+	listed := make([]offlineUTXO, 0, len(req.Inputs))
+	for _, in := range req.Inputs {
+		listed = append(listed, in.offlineUTXO)
+	}
+	tx, err := parseUnsigned(req.Network, req.Tx, listed)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, input := range tx.Inputs {
+		in := req.Inputs[i]
+		lock := input.SourceTxOutput().LockingScript
+		if !lock.IsP2PKH() {
+			return nil, fmt.Errorf("input %d is not P2PKH, the only kind carve assembles", i)
+		}
+		flag, err := parseSigHash(in.SigHash)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		digest, err := tx.CalcInputSignatureHash(uint32(i), flag) //nolint:gosec // input counts fit in uint32
+		if err != nil {
+			return nil, fmt.Errorf("input %d: computing the digest: %w", i, err)
+		}
+		if !strings.EqualFold(in.Digest, hex.EncodeToString(digest)) {
+			return nil, fmt.Errorf("input %d: the digest listed is not the transaction's", i)
+		}
+		if in.Signature == "" {
+			return nil, fmt.Errorf("input %d has no signature", i)
+		}
+
+		pubHex := cmp.Or(in.PubKey, defaultPub)
+		if pubHex == "" {
+			return nil, fmt.Errorf("input %d has no public key; add one or pass --pubkey", i)
+		}
+		pub, err := ec.PublicKeyFromString(pubHex)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: invalid public key: %w", i, err)
+		}
+		if len(pubHex) != 2*33 {
+			return nil, fmt.Errorf("input %d: the public key is uncompressed; --unsigned sizes the fee for compressed keys", i)
+		}
+		if !bytes.Equal(crypto.Hash160(pub.Compressed()), (*lock)[3:23]) {
+			return nil, fmt.Errorf("input %d does not pay the public key's address", i)
+		}
+
+		sig, err := parseExternalSignature(in.Signature, flag)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		if !sig.Verify(digest, pub) {
+			return nil, fmt.Errorf("input %d: the signature does not verify against its digest and public key", i)
+		}
+
+		unlock := &script.Script{}
+		if err = unlock.AppendPushData(append(sig.Serialize(), byte(flag))); err != nil {
+			return nil, err
+		}
+		if err = unlock.AppendPushData(pub.Compressed()); err != nil {
+			return nil, err
+		}
+		input.UnlockingScript = unlock
+	}
+	return tx, nil
+}
+
+// parseExternalSignature parses a strict DER signature in hex, allowing the
+// SIGHASH byte flag after it, as some signers append it.
+func parseExternalSignature(sigHex string, flag sighash.Flag) (*ec.Signature, error) {
+	raw, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return nil, fmt.Errorf("the signature is not hex: %w", err)
+	}
+	sig, err := ec.ParseDERSignature(raw)
+	if err != nil && len(raw) > 0 && raw[len(raw)-1] == byte(flag) {
+		sig, err = ec.ParseDERSignature(raw[:len(raw)-1])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid DER signature: %w", err)
+	}
+	return sig, nil
+}
+
+// printUnsigned writes tx and the outputs it spends to stdout as an
+// UnsignedTx for carve sign.
+func printUnsigned(tx *transaction.Transaction) error {
+	fee, err := tx.GetFee()
+	if err != nil {
+		return fmt.Errorf("failed to compute fee: %w", err)
+	}
+	payload := &UnsignedTx{Network: networkName(testnet), Tx: tx.String(), Inputs: make([]offlineUTXO, 0, len(tx.Inputs)), Fee: fee}
+	for _, input := range tx.Inputs {
+		source := input.SourceTxOutput()
+		payload.Inputs = append(payload.Inputs, offlineUTXO{
+			TxID:          input.SourceTXID.String(),
+			Vout:          input.SourceTxOutIndex,
+			Satoshis:      source.Satoshis,
+			LockingScript: source.LockingScript.String(),
+		})
+	}
+
+	return printJSON(payload, "unsigned transaction")
+}
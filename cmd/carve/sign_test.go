@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+func TestSignUnsigned(t *testing.T) {
+	t.Parallel()
+
+	_, source := chaintest.Source(t)
+	builder := &txbuilder.Builder{FeePerKb: 100, Unsigned: true}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}
+	funds := &funding{addrs: []string{source.AddressString}, utxos: utxos, change: source, from: source}
+	tx, err := buildTransaction(builder, funds, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", utxos, 1000, 1)
+	require.NoError(t, err)
+
+	payload := func() *UnsignedTx {
+		return &UnsignedTx{Network: "mainnet", Tx: tx.String(), Inputs: []offlineUTXO{{
+			TxID:          testTxID,
+			Vout:          0,
+			Satoshis:      10000,
+			LockingScript: "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+		}}}
+	}
+
+	t.Run("signs inputs paying the key's address", func(t *testing.T) {
+		t.Parallel()
+		signed, err := signUnsigned(payload(), testWIF, sigHashFlags{})
+		require.NoError(t, err)
+		require.NotNil(t, signed.Inputs[0].UnlockingScript)
+		assert.Equal(t, tx.Outputs, signed.Outputs)
+	})
+
+	t.Run("rejects another key", func(t *testing.T) {
+		t.Parallel()
+		_, err := signUnsigned(payload(), "L1uyy5qTuGrVXrmrsvHWHgVzW9kKdrp27wBC7Vs6nZDTF2BRUVwy", sigHashFlags{})
+		require.ErrorContains(t, err, "input 0 does not pay")
+
+		_, err = signUnsigned(payload(), "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf", sigHashFlags{})
+		require.ErrorContains(t, err, "uncompressed key")
+	})
+
+	t.Run("rejects inputs that do not match the transaction", func(t *testing.T) {
+		t.Parallel()
+		p := payload()
+		p.Inputs[0].Vout = 1
+		_, err := signUnsigned(p, testWIF, sigHashFlags{})
+		require.ErrorContains(t, err, "input 0 is "+testTxID+":0, but "+testTxID+":1 is listed")
+
+		p = payload()
+		p.Inputs = append(p.Inputs, p.Inputs[0])
+		_, err = signUnsigned(p, testWIF, sigHashFlags{})
+		require.EqualError(t, err, "2 input(s) listed for a transaction with 1")
+
+		p = payload()
+		p.Network = "regtest"
+		_, err = signUnsigned(p, testWIF, sigHashFlags{})
+		require.EqualError(t, err, `unknown network "regtest"`)
+	})
+}
+
+func TestAssembleSigned(t *testing.T) {
+	t.Parallel()
+
+	key, source := chaintest.Source(t)
+	builder := &txbuilder.Builder{FeePerKb: 100, Unsigned: true}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}
+	funds := &funding{addrs: []string{source.AddressString}, utxos: utxos, change: source, from: source}
+	tx, err := buildTransaction(builder, funds, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", utxos, 1000, 1)
+	require.NoError(t, err)
+	payload := &UnsignedTx{Network: "mainnet", Tx: tx.String(), Inputs: []offlineUTXO{{
+		TxID:          testTxID,
+		Vout:          0,
+		Satoshis:      10000,
+		LockingScript: "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+	}}}
+	want, err := signUnsigned(payload, testWIF, sigHashFlags{})
+	require.NoError(t, err)
+
+	// signed plays the external signer, signing each digest with key
+	signed := func(t *testing.T) *SigningRequest {
+		t.Helper()
+		req, err := signingRequest(payload, sigHashFlags{})
+		require.NoError(t, err)
+		for i := range req.Inputs {
+			digest, err := hex.DecodeString(req.Inputs[i].Digest)
+			require.NoError(t, err)
+			sig, err := key.Sign(digest)
+			require.NoError(t, err)
+			req.Inputs[i].Signature = hex.EncodeToString(sig.Serialize())
+		}
+		return req
+	}
+	pub := hex.EncodeToString(key.PubKey().Compressed())
+
+	t.Run("lists each input's digest and flag", func(t *testing.T) {
+		t.Parallel()
+		req, err := signingRequest(payload, sigHashFlags{})
+		require.NoError(t, err)
+		require.Len(t, req.Inputs, 1)
+		assert.Equal(t, "ALL|FORKID", req.Inputs[0].SigHash)
+		assert.Len(t, req.Inputs[0].Digest, 64)
+		assert.Equal(t, payload.Inputs[0], req.Inputs[0].offlineUTXO)
+	})
+
+	t.Run("assembles the transaction carve sign would", func(t *testing.T) {
+		t.Parallel()
+		req := signed(t)
+		tx, err := assembleSigned(req, pub)
+		require.NoError(t, err)
+		assert.Equal(t, want.String(), tx.String())
+
+		// The public key may come with each input, and the SIGHASH byte after the signature
+		req = signed(t)
+		req.Inputs[0].PubKey = pub
+		req.Inputs[0].Signature += "41"
+		tx, err = assembleSigned(req, "")
+		require.NoError(t, err)
+		assert.Equal(t, want.String(), tx.String())
+	})
+
+	t.Run("rejects what does not match the transaction", func(t *testing.T) {
+		t.Parallel()
+		req := signed(t)
+		req.Inputs[0].Signature = ""
+		_, err := assembleSigned(req, pub)
+		require.EqualError(t, err, "input 0 has no signature")
+
+		_, err = assembleSigned(signed(t), "")
+		require.EqualError(t, err, "input 0 has no public key; add one or pass --pubkey")
+
+		other, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000002")
+		require.NoError(t, err)
+		_, err = assembleSigned(signed(t), hex.EncodeToString(other.PubKey().Compressed()))
+		require.EqualError(t, err, "input 0 does not pay the public key's address")
+
+		_, err = assembleSigned(signed(t), hex.EncodeToString(key.PubKey().Uncompressed()))
+		require.ErrorContains(t, err, "the public key is uncompressed")
+
+		req = signed(t)
+		req.Inputs[0].SigHash = "NONE|FORKID"
+		_, err = assembleSigned(req, pub)
+		require.EqualError(t, err, "input 0: the digest listed is not the transaction's")
+
+		// A signature of another digest, here the NONE one, fails to verify
+		req = signed(t)
+		none, err := signingRequest(payload, sigHashFlags{all: sighash.NoneForkID})
+		require.NoError(t, err)
+		digest, err := hex.DecodeString(none.Inputs[0].Digest)
+		require.NoError(t, err)
+		sig, err := key.Sign(digest)
+		require.NoError(t, err)
+		req.Inputs[0].Signature = hex.EncodeToString(sig.Serialize())
+		_, err = assembleSigned(req, pub)
+		require.EqualError(t, err, "input 0: the signature does not verify against its digest and public key")
+
+		req = signed(t)
+		req.Inputs[0].Signature = "3044"
+		_, err = assembleSigned(req, pub)
+		require.ErrorContains(t, err, "input 0: invalid DER signature")
+	})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// maxChain is the largest --chain accepted. Nodes limit how many unconfirmed
+// ancestors a mempool transaction may have, so longer chains are rejected.
+const maxChain = 1000
+
+// linkChain sets each --chain transaction as the source of the inputs of
+// later ones that spend it, so their BEEF carries it instead of fetching it
+// from a provider that has yet to see it.
+func linkChain(txs []*transaction.Transaction) {
+	byID := make(map[string]*transaction.Transaction, len(txs))
+	for _, tx := range txs {
+		for _, in := range tx.Inputs {
+			if parent := byID[in.SourceTXID.String()]; parent != nil {
+				in.SourceTransaction = parent
+			}
+		}
+		byID[tx.TxID().String()] = tx
+	}
+}
+
+// changeOf returns tx's change output at index vout, or nil for none (-1).
+func changeOf(tx *transaction.Transaction, vout int) *chain.UTXO {
+	if vout < 0 {
+		return nil
+	}
+	return &chain.UTXO{
+		TxHash: tx.TxID().String(),
+		TxPos:  uint32(vout), //nolint:gosec // output indexes are 32-bit
+		Value:  tx.Outputs[vout].Satoshis,
+	}
+}
+
+// buildChain builds n transactions paying the same outputs, the first
+// spending selected and each later one the change of the one before, signed
+// by the change address's key. build returns a transaction with the index of
+// its change output. It returns them in order with the UTXOs each spends and
+// the index of each one's change.
+func buildChain(funds *funding, selected []*txbuilder.UTXO, n int, build func([]*txbuilder.UTXO) (*transaction.Transaction, int, error)) ([]*transaction.Transaction, [][]*txbuilder.UTXO, []int, error) {
+	txs := make([]*transaction.Transaction, 0, n)
+	spent := make([][]*txbuilder.UTXO, 0, n)
+	changes := make([]int, 0, n)
+	utxos := selected
+	for i := 1; ; i++ {
+		tx, vout, err := build(utxos)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("transaction %d of %d: %w", i, n, err)
+		}
+		txs, spent, changes = append(txs, tx), append(spent, utxos), append(changes, vout)
+		if i == n {
+			return txs, spent, changes, nil
+		}
+
+		change := changeOf(tx, vout)
+		if change == nil {
+			return nil, nil, nil, fmt.Errorf("transaction %d of %d left no change to chain from", i, n)
+		}
+		funds.addChange(change)
+		diag.printf(levelInfo, "Transaction %d of %d: %s, %d sats of change to spend next", i, n, change.TxHash, change.Value)
+		utxos = []*txbuilder.UTXO{change}
+	}
+}
+
+// emitChain prints the transactions of a --chain in order: their hex, one per
+// line, or with --json an array of results. With --broadcast each is sent in
+// turn, stopping at the first the broadcaster rejects, as the rest spend its
+// change.
+func emitChain(ctx context.Context, broadcaster chain.Broadcaster, txs []*transaction.Transaction, spent [][]*txbuilder.UTXO, changes []int) error {
+	if beefOut {
+		linkChain(txs)
+	}
+	results := make([]*Result, 0, len(txs))
+	for i, tx := range txs {
+		printSummary(diag, tx, 0)
+		var result *Result
+		if jsonOut {
+			var err error
+			if result, err = buildResult(tx, spent[i], changes[i], 0); err != nil {
+				return err
+			}
+			if err = addEncoding(ctx, result, tx); err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		if !broadcast {
+			continue
+		}
+		resp, err := broadcastTx(ctx, broadcaster, tx)
+		if err != nil {
+			return fmt.Errorf("transaction %d of %d, after %d broadcast: %w", i+1, len(txs), i, err)
+		}
+		if jsonOut {
+			result.Broadcast = resp
+			continue
+		}
+		fmt.Printf("TxID:   %s\n", resp.TxID)
+		fmt.Printf("Status: %s\n", resp.Status)
+		if resp.Info != "" {
+			fmt.Printf("Info:   %s\n", resp.Info)
+		}
+	}
+
+	switch {
+	case jsonOut:
+		return printJSON(results, "results")
+	case !broadcast:
+		// Printed last, so a failed run never leaves a partial chain in the pipe
+		for _, tx := range txs {
+			if err := printTx(ctx, tx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
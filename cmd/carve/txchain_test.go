@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+func TestBuildChain(t *testing.T) {
+	t.Parallel()
+
+	const destAddr = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	key, source := chaintest.Source(t)
+	uncompressed, err := keys.Address(key.PubKey(), false, false)
+	require.NoError(t, err)
+
+	for name, addr := range map[string]*script.Address{"compressed": source, "uncompressed": uncompressed} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &txbuilder.Builder{FeePerKb: 100, Uncompressed: addr == uncompressed}
+			funds := singleKeyFunding(key, addr, []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 100}})
+			build := func(utxos []*txbuilder.UTXO) (*transaction.Transaction, int, error) {
+				tx, err := buildTransaction(builder, funds, destAddr, utxos, 1000, 1)
+				return tx, changeVout(builder, 1000), err
+			}
+
+			txs, spent, changes, err := buildChain(funds, funds.utxos, 3, build)
+			require.NoError(t, err)
+			require.Len(t, txs, 3)
+			require.Len(t, spent, 3)
+			assert.Equal(t, []int{1, 1, 1}, changes)
+			for i, tx := range txs {
+				require.Len(t, tx.Inputs, 1)
+				require.Len(t, tx.Outputs, 2)
+				assert.Equal(t, uint64(1000), tx.Outputs[0].Satoshis)
+				assert.True(t, paysTo(t, tx.Outputs[1].LockingScript, addr))
+				if i > 0 {
+					prev := txs[i-1]
+					assert.Equal(t, prev.TxID().String(), tx.Inputs[0].SourceTXID.String())
+					assert.Equal(t, uint32(1), tx.Inputs[0].SourceTxOutIndex)
+					assert.Equal(t, prev.Outputs[1].Satoshis, spent[i][0].Value)
+				}
+				err := interpreter.NewEngine().Execute(
+					interpreter.WithTx(tx, 0, &transaction.TransactionOutput{Satoshis: *tx.Inputs[0].SourceTxSatoshis(), LockingScript: tx.Inputs[0].SourceTxScript()}),
+					interpreter.WithForkID(),
+					interpreter.WithAfterGenesis(),
+				)
+				require.NoError(t, err, "transaction %d", i+1)
+			}
+			// 10000 less three payments and three minimum fees
+			assert.Equal(t, uint64(6700), txs[2].Outputs[1].Satoshis)
+		})
+	}
+
+	t.Run("fails once the change runs out", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &txbuilder.Builder{FeePerKb: 100}
+		funds := singleKeyFunding(key, source, []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 2500, Height: 100}})
+		build := func(utxos []*txbuilder.UTXO) (*transaction.Transaction, int, error) {
+			tx, err := buildTransaction(builder, funds, destAddr, utxos, 1000, 1)
+			return tx, changeVout(builder, 1000), err
+		}
+		_, _, _, err := buildChain(funds, funds.utxos, 3, build)
+		require.ErrorContains(t, err, "transaction 3 of 3: insufficient funds")
+
+		// Exactly spent, the second leaves nothing for a third
+		funds = singleKeyFunding(key, source, []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 2200, Height: 100}})
+		_, _, _, err = buildChain(funds, funds.utxos, 3, build)
+		require.EqualError(t, err, "transaction 2 of 3 left no change to chain from")
+	})
+}
+
+func TestLinkChain(t *testing.T) {
+	t.Parallel()
+
+	first := transaction.NewTransaction()
+	require.NoError(t, first.AddInputFrom(testTxID, 0, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", 10000, nil))
+	first.AddOutput(&transaction.TransactionOutput{Satoshis: 9000, LockingScript: &script.Script{script.OpTRUE}})
+	second := transaction.NewTransaction()
+	require.NoError(t, second.AddInputFrom(first.TxID().String(), 0, "51", 9000, nil))
+
+	linkChain([]*transaction.Transaction{first, second})
+	assert.Nil(t, first.Inputs[0].SourceTransaction)
+	assert.Same(t, first, second.Inputs[0].SourceTransaction)
+}
@@ -0,0 +1,334 @@
+// Package main implements a Bitcoin SV outpoint reference converter.
+//
+// Explorers and APIs show txids in display byte order, the reverse of the
+// order the hash has inside raw transactions, and serialized outpoints put a
+// little-endian output index after the internal-order txid. Mixing the two up
+// is a common pipeline bug; this tool converts references between the forms
+// in bulk.
+//
+// Features:
+//   - txid:vout in display byte order, as explorers show it (also txid.vout and txid_vout)
+//   - txid:vout in internal (reversed) byte order, as raw transactions hold it
+//   - 36-byte serialized outpoints: internal-order txid and little-endian vout
+//   - Bare txids, converted between byte orders
+//   - Automatic detection, or --from for input that could be either byte order
+//   - Batch mode for lists on stdin, one result per line
+//   - Non-zero exit code when any input is invalid
+//   - JSON output support
+//
+// Usage:
+//
+//	chainref <txid>:<vout>                      # Every form of an outpoint
+//	chainref <72-hex-outpoint>                  # Decode a serialized outpoint
+//	chainref <txid>:0 --to raw                  # Serialize for a raw transaction
+//	chainref <hex>:1 --from reversed            # Internal byte order to display
+//	cat outpoints.txt | chainref --to raw       # Convert a list, one per line
+//	chainref <txid> --to reversed               # Txid as raw transaction bytes hold it
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/cli"
+)
+
+// Reference formats
+const (
+	formatAuto     = "auto"
+	formatOutpoint = "outpoint" // txid:vout in display byte order
+	formatReversed = "reversed" // txid:vout in internal byte order
+	formatRaw      = "raw"      // 36-byte serialized outpoint
+)
+
+// rawOutpointSize is the size of a serialized outpoint: txid and vout.
+const rawOutpointSize = chainhash.HashSize + 4
+
+// Command-line flags
+var (
+	from       string // Input format: auto, outpoint, reversed, or raw
+	to         string // Output format: outpoint, reversed, or raw; all when empty
+	jsonOutput bool   // Output in JSON format
+)
+
+// ref is one reference in every form.
+type ref struct {
+	Input    string  `json:"input"`
+	Valid    bool    `json:"valid"`
+	Error    string  `json:"error,omitempty"`
+	From     string  `json:"from,omitempty"`
+	TxID     string  `json:"txid,omitempty"` // Display byte order
+	Vout     *uint32 `json:"vout,omitempty"` // Unset for a bare txid
+	Outpoint string  `json:"outpoint,omitempty"`
+	Reversed string  `json:"reversed,omitempty"`
+	Raw      string  `json:"raw,omitempty"` // Unset for a bare txid
+}
+
+// rootCmd is the main cobra command for the chainref tool.
+var rootCmd = &cobra.Command{
+	Use:   "chainref [ref...]",
+	Short: "Convert txid and outpoint references between byte orders and formats",
+	Long: `A command line tool that converts outpoint references between txid:vout in
+display byte order (as explorers show it), txid:vout in internal byte order (as
+raw transactions hold it), and 36-byte serialized outpoints. Bare txids are
+converted between byte orders. A list on stdin is converted one per line.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	switch from {
+	case formatAuto, formatOutpoint, formatReversed, formatRaw:
+	default:
+		return fmt.Errorf("invalid --from format %q: must be auto, outpoint, reversed, or raw", from)
+	}
+	switch to {
+	case "", formatOutpoint, formatReversed, formatRaw:
+	default:
+		return fmt.Errorf("invalid --to format %q: must be outpoint, reversed, or raw", to)
+	}
+
+	inputs, err := getInputs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(inputs) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no reference provided")
+	}
+
+	results := make([]*ref, 0, len(inputs))
+	invalid := 0
+	for _, in := range inputs {
+		r := convert(in, from)
+		if r.Valid && to == formatRaw && r.Raw == "" {
+			r.Valid, r.Error = false, "a serialized outpoint needs an output index"
+		}
+		if !r.Valid {
+			invalid++
+		}
+		results = append(results, r)
+	}
+
+	switch {
+	case jsonOutput && len(results) == 1:
+		if err := encodeJSON(results[0]); err != nil {
+			return err
+		}
+	case jsonOutput:
+		if err := encodeJSON(results); err != nil {
+			return err
+		}
+	case to != "":
+		for _, r := range results {
+			printAs(r, to)
+		}
+	default:
+		for i, r := range results {
+			if i > 0 {
+				fmt.Println()
+			}
+			printDetail(r)
+		}
+	}
+
+	if invalid > 0 {
+		if len(results) == 1 {
+			return fmt.Errorf("%s", results[0].Error)
+		}
+		return fmt.Errorf("%d of %d references invalid", invalid, len(results))
+	}
+	return nil
+}
+
+// getInputs returns the inputs from arguments, or one per line from stdin.
+func getInputs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return readLines(os.Stdin)
+	}
+	return nil, nil
+}
+
+// readLines reads non-empty, non-comment lines from r.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return lines, nil
+}
+
+// convert parses input in format, detecting it when format is auto, and
+// fills in every other form.
+func convert(input, format string) *ref {
+	r := &ref{Input: input}
+	hash, vout, detected, err := parse(input, format)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	r.Valid, r.From, r.Vout = true, detected, vout
+	r.TxID = hash.String()
+	internal := hex.EncodeToString(hash[:])
+	if vout == nil {
+		r.Outpoint, r.Reversed = r.TxID, internal
+		return r
+	}
+
+	index := strconv.FormatUint(uint64(*vout), 10)
+	r.Outpoint = r.TxID + ":" + index
+	r.Reversed = internal + ":" + index
+	raw := make([]byte, rawOutpointSize)
+	copy(raw, hash[:])
+	binary.LittleEndian.PutUint32(raw[chainhash.HashSize:], *vout)
+	r.Raw = hex.EncodeToString(raw)
+	return r
+}
+
+// parse decodes a reference into the txid hash and output index, which is
+// nil for a bare txid, and reports the format it was read as. In auto, a
+// 72-character hex string is a serialized outpoint and anything else is in
+// display byte order.
+func parse(input, format string) (*chainhash.Hash, *uint32, string, error) {
+	txid, index, hasIndex := splitRef(input)
+	if format == formatAuto {
+		format = formatOutpoint
+		if !hasIndex && len(txid) == rawOutpointSize*2 {
+			format = formatRaw
+		}
+	}
+
+	if format == formatRaw {
+		if hasIndex {
+			return nil, nil, format, fmt.Errorf("a serialized outpoint has no separate output index")
+		}
+		b, err := hex.DecodeString(txid)
+		if err != nil || len(b) != rawOutpointSize {
+			return nil, nil, format, fmt.Errorf("not a %d-byte serialized outpoint", rawOutpointSize)
+		}
+		hash, err := chainhash.NewHash(b[:chainhash.HashSize])
+		if err != nil {
+			return nil, nil, format, err
+		}
+		vout := binary.LittleEndian.Uint32(b[chainhash.HashSize:])
+		return hash, &vout, format, nil
+	}
+
+	b, err := hex.DecodeString(txid)
+	if err != nil || len(b) != chainhash.HashSize {
+		return nil, nil, format, fmt.Errorf("not a %d-byte txid", chainhash.HashSize)
+	}
+	if format == formatOutpoint {
+		// Display order is the reverse of the hash's internal order
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+	}
+	hash, err := chainhash.NewHash(b)
+	if err != nil {
+		return nil, nil, format, err
+	}
+	if !hasIndex {
+		return hash, nil, format, nil
+	}
+
+	n, err := strconv.ParseUint(index, 10, 32)
+	if err != nil {
+		return nil, nil, format, fmt.Errorf("invalid output index %q", index)
+	}
+	vout := uint32(n)
+	return hash, &vout, format, nil
+}
+
+// splitRef splits a reference at its last ':', '.' or '_' into the txid and
+// output index.
+func splitRef(input string) (string, string, bool) {
+	i := strings.LastIndexAny(input, ":._")
+	if i < 0 {
+		return strings.ToLower(input), "", false
+	}
+	return strings.ToLower(input[:i]), input[i+1:], true
+}
+
+// printAs prints r in format, or its error to stderr.
+func printAs(r *ref, format string) {
+	if !r.Valid {
+		fmt.Fprintf(os.Stderr, "✗ %s: %s\n", r.Input, r.Error)
+		return
+	}
+	switch format {
+	case formatOutpoint:
+		fmt.Println(r.Outpoint)
+	case formatReversed:
+		fmt.Println(r.Reversed)
+	case formatRaw:
+		fmt.Println(r.Raw)
+	}
+}
+
+// printDetail prints every form of r.
+func printDetail(r *ref) {
+	if !r.Valid {
+		fmt.Printf("✗ %s\n  %s\n", r.Input, r.Error)
+		return
+	}
+	fmt.Printf("Input:    %s (%s)\n", r.Input, r.From)
+	fmt.Printf("Outpoint: %s\n", r.Outpoint)
+	fmt.Printf("Reversed: %s\n", r.Reversed)
+	if r.Raw != "" {
+		fmt.Printf("Raw:      %s\n", r.Raw)
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVar(&from, "from", formatAuto, "Input format: auto, outpoint, reversed, or raw")
+	rootCmd.Flags().StringVar(&to, "to", "", "Print only this form: outpoint, reversed, or raw")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the chainref command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
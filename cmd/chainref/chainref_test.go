@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testTxID is the genesis coinbase txid in display byte order.
+	testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	// testInternal is the same txid in internal byte order.
+	testInternal = "3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	raw := testInternal + "05000000"
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		from   string
+	}{
+		{"display outpoint", testTxID + ":5", formatAuto, formatOutpoint},
+		{"dot separator", testTxID + ".5", formatAuto, formatOutpoint},
+		{"underscore separator", testTxID + "_5", formatAuto, formatOutpoint},
+		{"uppercase hex", "4A5E1E4BAAB89F3A32518A88C31BC87F618F76673E2CC77AB2127B7AFDEDA33B:5", formatAuto, formatOutpoint},
+		{"reversed outpoint", testInternal + ":5", formatReversed, formatReversed},
+		{"serialized outpoint", raw, formatAuto, formatRaw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := convert(tt.input, tt.format)
+			require.True(t, r.Valid, r.Error)
+			assert.Equal(t, tt.from, r.From)
+			assert.Equal(t, testTxID, r.TxID)
+			require.NotNil(t, r.Vout)
+			assert.Equal(t, uint32(5), *r.Vout)
+			assert.Equal(t, testTxID+":5", r.Outpoint)
+			assert.Equal(t, testInternal+":5", r.Reversed)
+			assert.Equal(t, raw, r.Raw)
+		})
+	}
+
+	t.Run("bare txid", func(t *testing.T) {
+		t.Parallel()
+
+		r := convert(testTxID, formatAuto)
+		require.True(t, r.Valid, r.Error)
+		assert.Nil(t, r.Vout)
+		assert.Equal(t, testTxID, r.Outpoint)
+		assert.Equal(t, testInternal, r.Reversed)
+		assert.Empty(t, r.Raw)
+
+		r = convert(testInternal, formatReversed)
+		require.True(t, r.Valid, r.Error)
+		assert.Equal(t, testTxID, r.TxID)
+	})
+
+	t.Run("large output index", func(t *testing.T) {
+		t.Parallel()
+
+		r := convert(testTxID+":4294967295", formatAuto)
+		require.True(t, r.Valid, r.Error)
+		assert.Equal(t, testInternal+"ffffffff", r.Raw)
+	})
+}
+
+func TestConvertInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		err    string
+	}{
+		{"short txid", "abcd:0", formatAuto, "not a 32-byte txid"},
+		{"not hex", "zz" + testTxID[2:] + ":0", formatAuto, "not a 32-byte txid"},
+		{"bad index", testTxID + ":x", formatAuto, `invalid output index "x"`},
+		{"index overflow", testTxID + ":4294967296", formatAuto, "invalid output index"},
+		{"short raw", testInternal, formatRaw, "not a 36-byte serialized outpoint"},
+		{"raw with index", testInternal + "00000000:1", formatRaw, "no separate output index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := convert(tt.input, tt.format)
+			assert.False(t, r.Valid)
+			assert.Contains(t, r.Error, tt.err)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package main implements a Bitcoin SV transaction format converter.
+//
+// This tool translates a transaction between raw hex, Extended Format (EF,
+// BRC-30) and BEEF (BRC-62). The input format is detected automatically.
+// Converting to EF needs each input's source output and converting to BEEF
+// needs every unconfirmed ancestor plus a merkle proof for each confirmed
+// one. Missing transactions are fetched from the data provider in config.yaml
+// and merkle proofs from WhatsOnChain.
+//
+// Features:
+//   - Automatic detection of raw, EF, and BEEF (V1, V2, Atomic) input
+//   - Accepts a txid and fetches the transaction
+//   - Fetches missing source transactions and merkle proofs
+//   - TSC proofs converted to BUMPs for BEEF
+//   - --no-fetch for strictly offline conversion
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	convert <rawtx> --to ef                 # Raw to Extended Format
+//	convert <rawtx> --to beef               # Raw to BEEF, fetching ancestors
+//	convert <beef> --to raw                 # BEEF back to raw hex
+//	convert <txid> --to beef                # Fetch a transaction as BEEF
+//	carve -w <WIF> -a <addr> | convert --to ef | broadcast
+//	convert <beef> --to ef --no-fetch       # Offline; BEEF already has sources
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+// Command-line flags
+var (
+	testnet    bool   // Use testnet instead of mainnet
+	jsonOutput bool   // Output in JSON format
+	toFormat   string // Output format: raw, ef, or beef
+	noFetch    bool   // Fail instead of fetching missing data
+	maxDepth   int    // Maximum unconfirmed ancestor depth for BEEF
+)
+
+// result is a converted transaction.
+type result struct {
+	TxID    string `json:"txid"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Fetched int    `json:"fetched"` // Transactions and proofs fetched
+	Hex     string `json:"hex"`
+}
+
+// rootCmd is the main cobra command for the convert tool.
+var rootCmd = &cobra.Command{
+	Use:   "convert [tx|txid]",
+	Short: "Convert a transaction between raw, EF, and BEEF",
+	Long: `A command line tool that converts a transaction between raw hex, Extended
+Format (EF), and BEEF. The input format is detected automatically; a txid is
+fetched from the data provider in config.yaml. Source transactions and merkle
+proofs (always from WhatsOnChain) the target format needs are fetched unless
+--no-fetch is set.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	input, err := getInput(args)
+	if err != nil {
+		return err
+	}
+
+	if input == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no transaction provided")
+	}
+
+	if !cli.IsValidHex(input) {
+		return fmt.Errorf("input is not a valid hex string")
+	}
+
+	to := strings.ToLower(toFormat)
+	if to != spv.FormatRaw && to != spv.FormatEF && to != spv.FormatBEEF {
+		return fmt.Errorf("invalid --to format %q: must be raw, ef, or beef", toFormat)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain serves the merkle proofs BEEF needs, whatever the data provider
+	r := spv.NewResolver(provider.WOC.Client)
+	r.Txs = provider
+	r.NoFetch = noFetch
+	r.MaxDepth = maxDepth
+	res, err := convert(ctx, r, input, to)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return encodeJSON(res)
+	}
+	fmt.Println(res.Hex)
+	return nil
+}
+
+// getInput returns the input from arguments or stdin.
+func getInput(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// convert parses input, fetches whatever format to needs, and serializes it.
+func convert(ctx context.Context, r *spv.Resolver, input, to string) (*result, error) {
+	b, err := hex.DecodeString(input)
+	if err != nil {
+		return nil, fmt.Errorf("decoding hex: %w", err)
+	}
+
+	var tx *transaction.Transaction
+	from := spv.DetectFormat(b)
+	if from == spv.FormatTxID {
+		tx, err = r.Fetch(ctx, input, to == spv.FormatBEEF)
+	} else {
+		tx, _, err = spv.ParseTransaction(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{TxID: tx.TxID().String(), From: from, To: to}
+	switch to {
+	case spv.FormatRaw:
+		res.Hex = tx.Hex()
+	case spv.FormatEF:
+		res.Hex, err = r.EF(ctx, tx)
+	case spv.FormatBEEF:
+		res.Hex, err = r.BEEF(ctx, tx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res.Fetched = r.Fetched
+	return res, nil
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().StringVar(&toFormat, "to", spv.FormatEF, "Output format: raw, ef, or beef")
+	rootCmd.Flags().BoolVar(&noFetch, "no-fetch", false, "Fail instead of fetching missing source transactions or proofs")
+	rootCmd.Flags().IntVarP(&maxDepth, "depth", "d", spv.DefaultMaxDepth, "Maximum depth of unconfirmed ancestors to include in BEEF")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the convert command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/spv"
+	"github.com/mrz1836/go-template/internal/spv/spvtest"
+)
+
+// newResolver returns a resolver for client.
+func newResolver(client whatsonchain.ClientInterface, noFetch bool) *spv.Resolver {
+	r := spv.NewResolver(client)
+	r.NoFetch = noFetch
+	return r
+}
+
+func TestConvertToEF(t *testing.T) {
+	t.Parallel()
+
+	client, child, _ := spvtest.Chain(t)
+
+	res, err := convert(context.Background(), newResolver(client, false), child.Hex(), spv.FormatEF)
+	require.NoError(t, err)
+	assert.Equal(t, spv.FormatRaw, res.From)
+	assert.Equal(t, child.TxID().String(), res.TxID)
+	assert.Equal(t, 1, res.Fetched)
+
+	ef, err := transaction.NewTransactionFromHex(res.Hex)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5000000000), *ef.Inputs[0].SourceTxSatoshis())
+	assert.Equal(t, child.TxID().String(), ef.TxID().String())
+
+	// EF converts back to raw without fetching
+	back, err := convert(context.Background(), newResolver(client, true), res.Hex, spv.FormatRaw)
+	require.NoError(t, err)
+	assert.Equal(t, spv.FormatEF, back.From)
+	assert.Equal(t, child.Hex(), back.Hex)
+
+	_, err = convert(context.Background(), newResolver(client, true), child.Hex(), spv.FormatEF)
+	require.ErrorIs(t, err, spv.ErrNoFetch)
+}
+
+func TestConvertToBEEF(t *testing.T) {
+	t.Parallel()
+
+	client, child, grandchild := spvtest.Chain(t)
+
+	res, err := convert(context.Background(), newResolver(client, false), grandchild.Hex(), spv.FormatBEEF)
+	require.NoError(t, err)
+	assert.Equal(t, grandchild.TxID().String(), res.TxID)
+
+	tx, err := transaction.NewTransactionFromBEEFHex(res.Hex)
+	require.NoError(t, err)
+	assert.Equal(t, child.TxID().String(), tx.Inputs[0].SourceTransaction.TxID().String())
+
+	// BEEF already carries every ancestor, so EF needs no fetching
+	ef, err := convert(context.Background(), newResolver(client, true), res.Hex, spv.FormatEF)
+	require.NoError(t, err)
+	assert.Equal(t, spv.FormatBEEF, ef.From)
+	assert.Zero(t, ef.Fetched)
+
+	raw, err := convert(context.Background(), newResolver(client, true), res.Hex, spv.FormatRaw)
+	require.NoError(t, err)
+	assert.Equal(t, grandchild.Hex(), raw.Hex)
+}
+
+func TestConvertTxID(t *testing.T) {
+	t.Parallel()
+
+	client, _, _ := spvtest.Chain(t)
+
+	res, err := convert(context.Background(), newResolver(client, false), spvtest.GenesisTxID, spv.FormatBEEF)
+	require.NoError(t, err)
+	assert.Equal(t, spv.FormatTxID, res.From)
+
+	tx, err := transaction.NewTransactionFromBEEFHex(res.Hex)
+	require.NoError(t, err)
+	assert.NotNil(t, tx.MerklePath)
+
+	_, err = convert(context.Background(), newResolver(client, true), spvtest.GenesisTxID, spv.FormatRaw)
+	require.ErrorIs(t, err, spv.ErrNoFetch)
+}
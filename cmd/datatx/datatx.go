@@ -0,0 +1,532 @@
+// Package main implements uploading and downloading files stored on-chain
+// with the B:// and Bcat OP_RETURN protocols.
+//
+// Files that fit in one output are written with B://. Larger files are split
+// into Bcat parts, each in its own transaction, followed by a Bcat transaction
+// that references the parts in order. The part transactions are chained so
+// the whole upload is funded from one UTXO selection.
+//
+// Features:
+//   - Upload with automatic B:// or Bcat selection
+//   - MIME type detection from the file extension or content
+//   - Size-aware fees, change back to the funding address
+//   - UTXOs, downloads and broadcasts through the providers in config.yaml, or raw hex output
+//   - Download and reassembly of B:// and Bcat files
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	datatx put -w <WIF> photo.jpg             # Upload a file
+//	datatx put -w <WIF> big.mp4 -c 50000      # Upload with 50 KB Bcat parts
+//	cat notes.md | datatx put -w <WIF> - --name notes.md
+//	datatx put -w <WIF> a.txt --no-broadcast  # Print the signed txs instead
+//	datatx get <txid> -o photo.jpg            # Download to a file
+//	datatx get <txid> | less                  # Download to stdout
+//	datatx get <txid> --info -j               # Show metadata only
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/datatx"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// defaultChunkSize is the largest file written with B:// and the Bcat part size.
+const defaultChunkSize = 100000
+
+// Command-line flags
+var (
+	testnet     bool   // Use testnet instead of mainnet
+	jsonOutput  bool   // Output in JSON format
+	wif         string // WIF private key funding the upload
+	feePerKb    uint64 // Fee rate in satoshis per kilobyte
+	mimeType    string // MIME type override
+	filename    string // Filename override
+	chunkSize   int    // Maximum bytes per data output
+	forceBcat   bool   // Use Bcat even if the file fits in one output
+	noBroadcast bool   // Print the signed transactions instead of broadcasting them
+	debug       bool   // Enable verbose debug logging
+	outputPath  string // Write downloaded data to this file instead of stdout
+	infoOnly    bool   // Show metadata without downloading the data
+)
+
+// upload is a signed upload and its summary.
+type upload struct {
+	Txs      []*transaction.Transaction `json:"-"`
+	TxID     string                     `json:"txid"`
+	Protocol string                     `json:"protocol"`
+	Filename string                     `json:"filename,omitempty"`
+	MimeType string                     `json:"mimeType"`
+	Size     int                        `json:"size"`
+	Parts    []string                   `json:"parts,omitempty"`
+	Fee      uint64                     `json:"fee"`
+}
+
+// download is a reassembled file and its metadata.
+type download struct {
+	*datatx.Payload
+	TxID string `json:"txid"`
+	Size int    `json:"size"`
+}
+
+// rootCmd is the main cobra command for the datatx tool.
+var rootCmd = &cobra.Command{
+	Use:   "datatx",
+	Short: "Upload and download files with the B:// and Bcat protocols",
+	Long: `A command line tool that writes files on-chain in OP_RETURN outputs and reads
+them back. Small files use B://, larger files are split into Bcat parts.`,
+}
+
+// putCmd uploads a file.
+var putCmd = &cobra.Command{
+	Use:   "put <file>",
+	Short: "Write a file on-chain (use - for stdin)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wif == "" {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--wif is required")
+		}
+		if chunkSize < 1 {
+			return fmt.Errorf("--chunk-size must be at least 1")
+		}
+		return runPut(args[0])
+	},
+}
+
+// getCmd downloads a file.
+var getCmd = &cobra.Command{
+	Use:   "get <txid>",
+	Short: "Read a B:// or Bcat file back from the chain",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runGet(args[0])
+	},
+}
+
+// runPut builds, and unless --no-broadcast is set broadcasts, an upload.
+func runPut(path string) error {
+	f, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if key.Testnet != testnet {
+		return fmt.Errorf("the WIF is for %s; --testnet must match it", key.Network())
+	}
+	source, err := key.Address()
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	up, err := buildUpload(builder, key, source, utxos, f, chunkSize, forceBcat)
+	if err != nil {
+		return err
+	}
+
+	if noBroadcast {
+		for _, tx := range up.Txs {
+			fmt.Println(tx.String())
+		}
+		return nil
+	}
+
+	if err = broadcastAll(ctx, provider, up.Txs); err != nil {
+		return err
+	}
+	return printUpload(up)
+}
+
+// readFile reads the file to upload and fills in its metadata.
+func readFile(path string) (*datatx.File, error) {
+	var data []byte
+	var err error
+	name := filename
+
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // user-specified input file
+		if name == "" {
+			name = filepath.Base(path)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("input is empty")
+	}
+
+	mediaType, encoding := detectType(name, data)
+	if mimeType != "" {
+		mediaType = mimeType
+	}
+
+	return &datatx.File{Data: data, MimeType: mediaType, Encoding: encoding, Filename: name}, nil
+}
+
+// detectType returns the media type and encoding of a file, from its
+// extension when known and from its content otherwise.
+func detectType(name string, data []byte) (mediaType, encoding string) {
+	full := mime.TypeByExtension(filepath.Ext(name))
+	if full == "" {
+		full = http.DetectContentType(data)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(full)
+	if err != nil {
+		return "application/octet-stream", "binary"
+	}
+	if charset := params["charset"]; charset != "" {
+		return mediaType, strings.ToLower(charset)
+	}
+	return mediaType, "binary"
+}
+
+// buildUpload builds the signed transactions of an upload: one B://
+// transaction, or chained Bcat part transactions followed by the Bcat
+// transaction. Change from each transaction funds the next.
+func buildUpload(builder *txbuilder.Builder, key *keys.WIF, source *script.Address, utxos []*txbuilder.UTXO, f *datatx.File, size int, bcat bool) (*upload, error) {
+	up := &upload{Filename: f.Filename, MimeType: f.MimeType, Size: len(f.Data)}
+
+	var scripts []*script.Script
+	if !bcat && len(f.Data) <= size {
+		up.Protocol = datatx.ProtocolB
+		s, err := datatx.BScript(f)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, s)
+	} else {
+		up.Protocol = datatx.ProtocolBcat
+		for _, chunk := range datatx.Chunk(f.Data, size) {
+			s, err := datatx.BcatPartScript(chunk)
+			if err != nil {
+				return nil, err
+			}
+			scripts = append(scripts, s)
+		}
+	}
+
+	// Fund every transaction up front. The Bcat script is estimated with
+	// placeholder txids, which have the same size as the real ones.
+	var target uint64
+	for _, s := range scripts {
+		target += builder.EstimateFee(1, dataOutputs(s))
+	}
+	if up.Protocol == datatx.ProtocolBcat {
+		placeholders := make([]string, len(scripts))
+		for i := range placeholders {
+			placeholders[i] = strings.Repeat("00", 32)
+		}
+		s, err := datatx.BcatScript(f, "", "", placeholders)
+		if err != nil {
+			return nil, err
+		}
+		target += builder.EstimateFee(1, dataOutputs(s))
+	}
+
+	selected, err := builder.SelectUTXOs(utxos, target)
+	if err != nil {
+		return nil, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	var funded uint64
+	for _, utxo := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: utxo, Key: key.Key, Uncompressed: !key.Compressed})
+		funded += utxo.Value
+	}
+
+	build := func(s *script.Script) (*transaction.Transaction, error) {
+		if len(inputs) == 0 {
+			return nil, fmt.Errorf("insufficient funds: nothing left to fund transaction %d", len(up.Txs)+1)
+		}
+		tx, err := builder.BuildOutputs(inputs, dataOutputs(s), source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build transaction %d: %w", len(up.Txs)+1, err)
+		}
+		up.Txs = append(up.Txs, tx)
+		inputs = changeInput(tx, key)
+		return tx, nil
+	}
+
+	for _, s := range scripts {
+		tx, err := build(s)
+		if err != nil {
+			return nil, err
+		}
+		if up.Protocol == datatx.ProtocolBcat {
+			up.Parts = append(up.Parts, tx.TxID().String())
+		}
+	}
+
+	if up.Protocol == datatx.ProtocolBcat {
+		s, err := datatx.BcatScript(f, "", "", up.Parts)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = build(s); err != nil {
+			return nil, err
+		}
+	}
+
+	final := up.Txs[len(up.Txs)-1]
+	up.TxID = final.TxID().String()
+	up.Fee = funded
+	if len(inputs) > 0 {
+		up.Fee -= inputs[0].UTXO.Value
+	}
+
+	return up, nil
+}
+
+// dataOutputs wraps a data script in a zero-value output.
+func dataOutputs(s *script.Script) []*transaction.TransactionOutput {
+	return []*transaction.TransactionOutput{{Satoshis: 0, LockingScript: s}}
+}
+
+// changeInput returns the change output of tx as the input of the next
+// transaction in the chain, or nil if tx has no change.
+func changeInput(tx *transaction.Transaction, key *keys.WIF) []txbuilder.Input {
+	last := len(tx.Outputs) - 1
+	out := tx.Outputs[last]
+	if out.LockingScript.IsData() || out.Satoshis == 0 {
+		return nil
+	}
+	utxo := &txbuilder.UTXO{TxHash: tx.TxID().String(), TxPos: uint32(last), Value: out.Satoshis} //nolint:gosec // output count fits in uint32
+	return []txbuilder.Input{{UTXO: utxo, Key: key.Key, Uncompressed: !key.Compressed}}
+}
+
+// broadcastAll broadcasts the transactions in order, drawing a progress bar
+// on a terminal and logging each one otherwise.
+func broadcastAll(ctx context.Context, broadcaster chain.Broadcaster, txs []*transaction.Transaction) error {
+	bar := cli.NewProgress("Broadcasting", len(txs))
+	defer bar.Finish()
+	for i, tx := range txs {
+		resp, err := broadcaster.Broadcast(ctx, tx.String())
+		if err != nil {
+			return fmt.Errorf("broadcasting transaction %d of %d (%d already broadcast): %w", i+1, len(txs), i, err)
+		}
+		if bar.Enabled() {
+			bar.Add(1)
+			continue
+		}
+		log.Printf("Broadcast %d/%d: %s (%s)\n", i+1, len(txs), resp.TxID, resp.Status)
+	}
+	return nil
+}
+
+// printUpload prints the summary of a broadcast upload.
+func printUpload(up *upload) error {
+	if jsonOutput {
+		return encodeJSON(up)
+	}
+
+	protocol := "B://"
+	if up.Protocol == datatx.ProtocolBcat {
+		protocol = fmt.Sprintf("Bcat (%d parts)", len(up.Parts))
+	}
+	fmt.Printf("✓ Uploaded %s (%d bytes, %s) via %s\n", displayName(up.Filename), up.Size, up.MimeType, protocol)
+	fmt.Printf("  TxID: %s\n", up.TxID)
+	fmt.Printf("  Fee:  %d satoshis\n", up.Fee)
+	return nil
+}
+
+// runGet downloads a file and writes it out.
+func runGet(txid string) error {
+	if len(txid) != 64 || !cli.IsValidHex(txid) {
+		return fmt.Errorf("invalid transaction ID: %s", txid)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	dl, data, err := fetchFile(ctx, provider, txid)
+	if err != nil {
+		return err
+	}
+
+	if infoOnly {
+		return printInfo(dl)
+	}
+
+	if outputPath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err = os.WriteFile(outputPath, data, 0o644); err != nil { //nolint:gosec // downloaded files are not secret
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	log.Printf("Wrote %d bytes to %s (%s)\n", len(data), outputPath, dl.MimeType)
+	return nil
+}
+
+// fetchFile fetches a B:// or Bcat transaction from txs and reassembles its data.
+func fetchFile(ctx context.Context, txs chain.TxFetcher, txid string) (*download, []byte, error) {
+	tx, err := fetchTx(ctx, txs, txid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := datatx.Find(tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("transaction %s: %w", txid, err)
+	}
+
+	data := payload.Data
+	if payload.Protocol == datatx.ProtocolBcat {
+		var buf bytes.Buffer
+		for i, partID := range payload.Parts {
+			log.Printf("Fetching part %d/%d: %s\n", i+1, len(payload.Parts), partID)
+			part, err := fetchTx(ctx, txs, partID)
+			if err != nil {
+				return nil, nil, err
+			}
+			p, err := datatx.Find(part)
+			if err != nil {
+				return nil, nil, fmt.Errorf("part %s: %w", partID, err)
+			}
+			if p.Protocol != datatx.ProtocolBcatPart {
+				return nil, nil, fmt.Errorf("part %s is not a Bcat part", partID)
+			}
+			buf.Write(p.Data)
+		}
+		data = buf.Bytes()
+	}
+
+	return &download{Payload: payload, TxID: txid, Size: len(data)}, data, nil
+}
+
+// fetchTx fetches and parses a transaction.
+func fetchTx(ctx context.Context, txs chain.TxFetcher, txid string) (*transaction.Transaction, error) {
+	rawTx, err := txs.RawTx(ctx, txid)
+	if err != nil {
+		return nil, fmt.Errorf("getting raw transaction %s: %w", txid, err)
+	}
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("parsing transaction %s: %w", txid, err)
+	}
+	return tx, nil
+}
+
+// printInfo prints the metadata of a stored file.
+func printInfo(dl *download) error {
+	if jsonOutput {
+		return encodeJSON(dl)
+	}
+
+	protocol := "B://"
+	switch dl.Protocol {
+	case datatx.ProtocolBcat:
+		protocol = fmt.Sprintf("Bcat (%d parts)", len(dl.Parts))
+	case datatx.ProtocolBcatPart:
+		protocol = "Bcat part"
+	}
+
+	fmt.Printf("TxID:      %s\n", dl.TxID)
+	fmt.Printf("Protocol:  %s\n", protocol)
+	fmt.Printf("Filename:  %s\n", displayName(dl.Filename))
+	fmt.Printf("MIME Type: %s\n", dl.MimeType)
+	if dl.Encoding != "" {
+		fmt.Printf("Encoding:  %s\n", dl.Encoding)
+	}
+	if dl.Flag != "" {
+		fmt.Printf("Flag:      %s\n", dl.Flag)
+	}
+	fmt.Printf("Size:      %d bytes\n", dl.Size)
+	return nil
+}
+
+// displayName returns a printable filename.
+func displayName(name string) string {
+	if name == "" {
+		return "(unnamed)"
+	}
+	return name
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra commands and flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	putCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key funding the upload (required)")
+	putCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	putCmd.Flags().StringVarP(&mimeType, "mime", "m", "", "MIME type (default: detected from extension or content)")
+	putCmd.Flags().StringVar(&filename, "name", "", "Filename to store (default: base name of the file)")
+	putCmd.Flags().IntVarP(&chunkSize, "chunk-size", "c", defaultChunkSize, "Largest B:// file and Bcat part size in bytes")
+	putCmd.Flags().BoolVar(&forceBcat, "bcat", false, "Use Bcat even if the file fits in one output")
+	putCmd.Flags().BoolVar(&noBroadcast, "no-broadcast", false, "Print the signed transactions (one hex per line, in broadcast order)")
+	putCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	getCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write the data to a file (default: stdout)")
+	getCmd.Flags().BoolVar(&infoOnly, "info", false, "Show metadata without writing the data")
+
+	rootCmd.AddCommand(putCmd, getCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the datatx command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
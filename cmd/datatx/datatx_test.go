@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/datatx"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// serve returns a mock provider serving txs.
+func serve(txs []*transaction.Transaction) *chain.Mock {
+	m := chain.NewMock()
+	for _, tx := range txs {
+		m.AddTx(tx)
+	}
+	return m
+}
+
+func TestDetectType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantType     string
+		wantEncoding string
+	}{
+		{"notes.txt", []byte("hi"), "text/plain", "utf-8"},
+		{"image.png", []byte{0x89, 'P', 'N', 'G'}, "image/png", "binary"},
+		{"", []byte("<html><body>hi</body></html>"), "text/html", "utf-8"},
+		{"", []byte{0x00, 0x01, 0x02}, "application/octet-stream", "binary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantType, func(t *testing.T) {
+			t.Parallel()
+
+			mediaType, encoding := detectType(tt.name, tt.data)
+			assert.Equal(t, tt.wantType, mediaType)
+			assert.Equal(t, tt.wantEncoding, encoding)
+		})
+	}
+}
+
+func TestBuildUpload(t *testing.T) {
+	t.Parallel()
+
+	priv, source := chaintest.Source(t)
+	key := &keys.WIF{Key: priv, Compressed: true}
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 100000}}
+
+	t.Run("small file uses B://", func(t *testing.T) {
+		t.Parallel()
+
+		f := &datatx.File{Data: []byte("hello world"), MimeType: "text/plain", Encoding: "utf-8", Filename: "hello.txt"}
+		up, err := buildUpload(builder, key, source, utxos, f, 1000, false)
+		require.NoError(t, err)
+
+		require.Len(t, up.Txs, 1)
+		assert.Equal(t, datatx.ProtocolB, up.Protocol)
+		assert.Equal(t, up.Txs[0].TxID().String(), up.TxID)
+		assert.Equal(t, uint64(txbuilder.MinFee), up.Fee)
+
+		dl, data, err := fetchFile(context.Background(), serve(up.Txs), up.TxID)
+		require.NoError(t, err)
+		assert.Equal(t, f.Data, data)
+		assert.Equal(t, "hello.txt", dl.Filename)
+	})
+
+	t.Run("large file is chained into Bcat parts", func(t *testing.T) {
+		t.Parallel()
+
+		payload := bytes.Repeat([]byte("0123456789"), 250)
+		f := &datatx.File{Data: payload, MimeType: "application/octet-stream", Encoding: "binary", Filename: "blob.bin"}
+		up, err := buildUpload(builder, key, source, utxos, f, 1000, false)
+		require.NoError(t, err)
+
+		// Three parts plus the Bcat transaction
+		require.Len(t, up.Txs, 4)
+		assert.Equal(t, datatx.ProtocolBcat, up.Protocol)
+		require.Len(t, up.Parts, 3)
+
+		// Each transaction spends the previous one's change
+		for i := 1; i < len(up.Txs); i++ {
+			require.Len(t, up.Txs[i].Inputs, 1)
+			assert.Equal(t, up.Txs[i-1].TxID().String(), up.Txs[i].Inputs[0].SourceTXID.String())
+		}
+
+		// Every satoshi is either fee or final change
+		final := up.Txs[len(up.Txs)-1]
+		change := final.Outputs[len(final.Outputs)-1].Satoshis
+		assert.Equal(t, uint64(100000), up.Fee+change)
+
+		dl, data, err := fetchFile(context.Background(), serve(up.Txs), up.TxID)
+		require.NoError(t, err)
+		assert.Equal(t, payload, data)
+		assert.Equal(t, "blob.bin", dl.Filename)
+		assert.Equal(t, len(payload), dl.Size)
+	})
+
+	t.Run("forced Bcat for a small file", func(t *testing.T) {
+		t.Parallel()
+
+		f := &datatx.File{Data: []byte("tiny"), MimeType: "text/plain"}
+		up, err := buildUpload(builder, key, source, utxos, f, 1000, true)
+		require.NoError(t, err)
+		assert.Equal(t, datatx.ProtocolBcat, up.Protocol)
+		assert.Len(t, up.Txs, 2)
+	})
+
+	t.Run("uncompressed key", func(t *testing.T) {
+		t.Parallel()
+
+		uncompressed := &keys.WIF{Key: priv}
+		addr, err := uncompressed.Address()
+		require.NoError(t, err)
+		f := &datatx.File{Data: []byte("tiny"), MimeType: "text/plain"}
+		up, err := buildUpload(builder, uncompressed, addr, utxos, f, 1000, true)
+		require.NoError(t, err)
+
+		// The change spent by the Bcat transaction is signed the same way
+		for _, tx := range up.Txs {
+			chunks, err := tx.Inputs[0].UnlockingScript.Chunks()
+			require.NoError(t, err)
+			assert.Len(t, chunks[1].Data, 65)
+		}
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		t.Parallel()
+
+		f := &datatx.File{Data: bytes.Repeat([]byte{1}, 5000), MimeType: "application/octet-stream"}
+		_, err := buildUpload(builder, key, source, []*txbuilder.UTXO{{TxHash: testTxID, Value: 150}}, f, 1000, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+	})
+}
+
+func TestFetchFileErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing transaction", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := fetchFile(context.Background(), serve(nil), testTxID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("no data output", func(t *testing.T) {
+		t.Parallel()
+
+		_, source := chaintest.Source(t)
+		tx := transaction.NewTransaction()
+		require.NoError(t, tx.PayToAddress(source.AddressString, 1000))
+
+		_, _, err := fetchFile(context.Background(), serve([]*transaction.Transaction{tx}), tx.TxID().String())
+		require.ErrorIs(t, err, datatx.ErrNotFound)
+	})
+}
@@ -0,0 +1,454 @@
+// Package main implements a Bitcoin SV address inspector and converter.
+//
+// This tool decodes addresses, WIF keys, public keys, hash160s and locking
+// scripts, reporting what the input is, which network it belongs to, and its
+// hash160. It derives the equivalent representations: mainnet and testnet
+// addresses, the P2PKH locking script, and its WhatsOnChain script hash.
+//
+// Features:
+//   - Detects P2PKH and P2SH addresses, WIFs, public keys, hash160s, locking
+//     scripts and script hashes
+//   - Base58Check checksum and version byte validation
+//   - Mainnet/testnet counterparts of any address
+//   - Locking script and WhatsOnChain script hash for each address
+//   - Batch mode for validating address lists, one result per line
+//   - Non-zero exit code when any input is invalid
+//   - JSON output support
+//
+// Usage:
+//
+//	decodeaddr <address>                    # Inspect an address
+//	decodeaddr <wif>                        # Inspect a WIF's address
+//	decodeaddr 76a914...88ac                # Inspect a locking script
+//	decodeaddr a b c                        # Batch mode for several inputs
+//	cat export.txt | decodeaddr             # Validate a list, one per line
+//	cat export.txt | decodeaddr -j          # JSON array of results
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// Base58Check version bytes
+const (
+	mainnetP2PKH byte = 0x00
+	testnetP2PKH byte = 0x6f
+	mainnetP2SH  byte = 0x05
+	testnetP2SH  byte = 0xc4
+)
+
+// Input types
+const (
+	typeP2PKH      = "p2pkh-address"
+	typeP2SH       = "p2sh-address"
+	typeWIF        = "wif"
+	typePubKey     = "pubkey"
+	typeHash160    = "hash160"
+	typeScript     = "script"
+	typeScriptHash = "script-hash"
+)
+
+// Networks
+const (
+	networkMain = "mainnet"
+	networkTest = "testnet"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Command-line flags
+var (
+	batch      bool // Force one-line-per-input output
+	jsonOutput bool // Output in JSON format
+	noColor    bool // Disable colored output
+)
+
+// decoded describes one input and its equivalent representations.
+type decoded struct {
+	Input       string `json:"input"`
+	Valid       bool   `json:"valid"`
+	Error       string `json:"error,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Network     string `json:"network,omitempty"` // Network encoded in the input, if any
+	Template    string `json:"template,omitempty"`
+	Hash160     string `json:"hash160,omitempty"`
+	PubKey      string `json:"pubkey,omitempty"`
+	Compressed  *bool  `json:"compressed,omitempty"`
+	Mainnet     string `json:"mainnet,omitempty"` // Mainnet address
+	Testnet     string `json:"testnet,omitempty"` // Testnet address
+	Script      string `json:"lockingScript,omitempty"`
+	ScriptHash  string `json:"scriptHash,omitempty"` // WhatsOnChain script hash of the locking script
+	Description string `json:"description,omitempty"`
+}
+
+// rootCmd is the main cobra command for the decodeaddr tool.
+var rootCmd = &cobra.Command{
+	Use:   "decodeaddr [input...]",
+	Short: "Decode and convert BSV addresses, WIFs, public keys and scripts",
+	Long: `A command line tool that identifies an address, WIF, public key, hash160,
+locking script or script hash, validates it, and shows its network, hash160 and
+equivalent mainnet/testnet addresses. Several inputs, or a list on stdin, are
+reported one per line and the exit code is non-zero if any is invalid.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	inputs, err := getInputs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(inputs) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no input provided")
+	}
+
+	results := make([]*decoded, 0, len(inputs))
+	invalid := 0
+	for _, in := range inputs {
+		d := decode(in)
+		if !d.Valid {
+			invalid++
+		}
+		results = append(results, d)
+	}
+
+	single := len(results) == 1 && !batch
+	switch {
+	case jsonOutput && single:
+		if err := encodeJSON(results[0]); err != nil {
+			return err
+		}
+	case jsonOutput:
+		if err := encodeJSON(results); err != nil {
+			return err
+		}
+	case single:
+		printDetail(results[0])
+	default:
+		for _, d := range results {
+			printLine(d)
+		}
+	}
+
+	if invalid > 0 {
+		if single {
+			return fmt.Errorf("%s", results[0].Error)
+		}
+		return fmt.Errorf("%d of %d inputs invalid", invalid, len(results))
+	}
+	return nil
+}
+
+// getInputs returns the inputs from arguments, or one per line from stdin.
+func getInputs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return readLines(os.Stdin)
+	}
+	return nil, nil
+}
+
+// readLines reads non-empty, non-comment lines from r.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return lines, nil
+}
+
+// decode identifies and decodes one input.
+func decode(input string) *decoded {
+	d := &decoded{Input: strings.TrimSpace(input)}
+
+	var err error
+	if isHex(d.Input) {
+		err = decodeHex(d)
+	} else {
+		err = decodeBase58(d)
+	}
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	d.Valid = true
+	return d
+}
+
+// decodeBase58 decodes a Base58Check address or WIF.
+func decodeBase58(d *decoded) error {
+	b, err := base58.Decode(d.Input)
+	if err != nil || len(b) < 5 {
+		return fmt.Errorf("not a recognized address, key, or script")
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	if !bytes.Equal(crypto.Sha256d(payload)[:4], sum) {
+		return fmt.Errorf("invalid Base58Check checksum")
+	}
+	version, body := payload[0], payload[1:]
+
+	switch version {
+	case mainnetP2PKH, testnetP2PKH:
+		if len(body) != 20 {
+			return fmt.Errorf("invalid address length: %d bytes", len(body))
+		}
+		d.Type = typeP2PKH
+		d.Network = networkFor(version == mainnetP2PKH)
+		return fillHash160(d, body)
+
+	case mainnetP2SH, testnetP2SH:
+		if len(body) != 20 {
+			return fmt.Errorf("invalid address length: %d bytes", len(body))
+		}
+		d.Type = typeP2SH
+		d.Network = networkFor(version == mainnetP2SH)
+		fillP2SH(d, body)
+		return nil
+
+	case keys.MainnetWIFPrefix, keys.TestnetWIFPrefix:
+		w, err := keys.ParseWIF(d.Input)
+		if err != nil {
+			return err
+		}
+		addr, err := w.Address()
+		if err != nil {
+			return err
+		}
+		d.Type = typeWIF
+		d.Network = w.Network()
+		d.PubKey = w.PublicKeyHex()
+		d.Compressed = &w.Compressed
+		return fillHash160(d, addr.PublicKeyHash)
+	}
+
+	return fmt.Errorf("unknown version byte 0x%02x", version)
+}
+
+// decodeHex decodes a public key, hash160, script hash or locking script.
+func decodeHex(d *decoded) error {
+	b, err := hex.DecodeString(d.Input)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %w", err)
+	}
+
+	switch {
+	case len(b) == 20:
+		d.Type = typeHash160
+		return fillHash160(d, b)
+
+	case len(b) == 33 || len(b) == 65:
+		if _, err := ec.ParsePubKey(b); err == nil {
+			d.Type = typePubKey
+			d.PubKey = d.Input
+			compressed := len(b) == 33
+			d.Compressed = &compressed
+			return fillHash160(d, crypto.Hash160(b))
+		}
+
+	case len(b) == 32:
+		d.Type = typeScriptHash
+		d.ScriptHash = d.Input
+		d.Description = "a SHA-256 script hash cannot be converted back to an address"
+		return nil
+	}
+
+	info := scripts.Classify(b, true)
+	d.Type = typeScript
+	d.Template = info.Type
+
+	switch info.Type {
+	case scripts.TemplateP2PKH:
+		err = fillHash160(d, b[3:23])
+	case scripts.TemplateP2PK:
+		d.PubKey = info.PubKeys[0]
+		pubKey, _ := hex.DecodeString(d.PubKey)
+		err = fillHash160(d, crypto.Hash160(pubKey))
+	case scripts.TemplateP2SH:
+		fillP2SH(d, b[2:22])
+	case scripts.TemplateNonStandard, scripts.TemplateEmpty:
+		return fmt.Errorf("not a recognized address, key, or script")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The input is the locking script; derived P2PKH fields describe its address
+	d.Script = d.Input
+	d.ScriptHash = scripts.ComputeHashes(b).ScriptHash
+	return nil
+}
+
+// fillHash160 sets the hash160 and the P2PKH representations derived from it.
+func fillHash160(d *decoded, hash []byte) error {
+	mainnet, err := script.NewAddressFromPublicKeyHash(hash, true)
+	if err != nil {
+		return err
+	}
+	testnet, err := script.NewAddressFromPublicKeyHash(hash, false)
+	if err != nil {
+		return err
+	}
+	lock := append(append([]byte{script.OpDUP, script.OpHASH160, script.OpDATA20}, hash...), script.OpEQUALVERIFY, script.OpCHECKSIG)
+
+	d.Hash160 = hex.EncodeToString(hash)
+	d.Mainnet = mainnet.AddressString
+	d.Testnet = testnet.AddressString
+	d.Script = hex.EncodeToString(lock)
+	d.ScriptHash = scripts.ComputeHashes(lock).ScriptHash
+	return nil
+}
+
+// fillP2SH sets the hash160 and the P2SH representations derived from it.
+func fillP2SH(d *decoded, hash []byte) {
+	lock := append(append([]byte{script.OpHASH160, script.OpDATA20}, hash...), script.OpEQUAL)
+
+	d.Hash160 = hex.EncodeToString(hash)
+	d.Mainnet = base58Check(mainnetP2SH, hash)
+	d.Testnet = base58Check(testnetP2SH, hash)
+	d.Script = hex.EncodeToString(lock)
+	d.ScriptHash = scripts.ComputeHashes(lock).ScriptHash
+	d.Description = "P2SH outputs are no longer spendable as P2SH since the Genesis upgrade"
+}
+
+// base58Check encodes version and payload with a checksum.
+func base58Check(version byte, payload []byte) string {
+	b := append([]byte{version}, payload...)
+	return base58.Encode(append(b, crypto.Sha256d(b)[:4]...))
+}
+
+// networkFor names the network of a version byte.
+func networkFor(mainnet bool) string {
+	if mainnet {
+		return networkMain
+	}
+	return networkTest
+}
+
+// isHex reports whether s is non-empty, even-length hex.
+func isHex(s string) bool {
+	if s == "" || len(s)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printDetail prints every field of a single result.
+func printDetail(d *decoded) {
+	if !d.Valid {
+		fmt.Printf("%s %s\n", c(colorRed, "✗"), d.Input)
+		fmt.Printf("  %s\n", c(colorRed, d.Error))
+		return
+	}
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Printf("%s %s\n", c(colorDim, fmt.Sprintf("%-15s", label+":")), value)
+		}
+	}
+	field("Input", d.Input)
+	typ := d.Type
+	if d.Template != "" {
+		typ += " (" + d.Template + ")"
+	}
+	field("Type", typ)
+	field("Network", d.Network)
+	field("Public key", d.PubKey)
+	if d.Compressed != nil {
+		field("Compressed", fmt.Sprintf("%t", *d.Compressed))
+	}
+	field("Hash160", d.Hash160)
+	field("Mainnet", d.Mainnet)
+	field("Testnet", d.Testnet)
+	field("Locking script", d.Script)
+	field("Script hash", d.ScriptHash)
+	if d.Description != "" {
+		fmt.Printf("\n%s\n", c(colorDim, "Note: "+d.Description))
+	}
+}
+
+// printLine prints a one-line result for batch mode.
+func printLine(d *decoded) {
+	if !d.Valid {
+		fmt.Printf("%s %s  %s\n", c(colorRed, "✗"), d.Input, c(colorRed, d.Error))
+		return
+	}
+	detail := d.Type
+	if d.Network != "" {
+		detail += " " + d.Network
+	}
+	fmt.Printf("%s %s  %s\n", c(colorGreen, "✓"), d.Input, c(colorDim, detail))
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&batch, "batch", "b", false, "Report one line per input even for a single input")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the decodeaddr command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
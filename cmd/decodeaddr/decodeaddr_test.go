@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHash160 = "751e76e8199196d454941c45d1b3a323f1433bd6"
+	testMainnet = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	testTestnet = "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"
+	testPubKey  = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		typ      string
+		network  string
+		template string
+	}{
+		{"mainnet address", testMainnet, typeP2PKH, networkMain, ""},
+		{"testnet address", testTestnet, typeP2PKH, networkTest, ""},
+		{"compressed WIF", "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", typeWIF, networkMain, ""},
+		{"testnet WIF", "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", typeWIF, networkTest, ""},
+		{"public key", testPubKey, typePubKey, "", ""},
+		{"hash160", testHash160, typeHash160, "", ""},
+		{"p2pkh script", "76a914" + testHash160 + "88ac", typeScript, "", "p2pkh"},
+		{"p2pk script", "21" + testPubKey + "ac", typeScript, "", "p2pk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := decode(tt.input)
+			require.True(t, d.Valid, d.Error)
+			assert.Equal(t, tt.typ, d.Type)
+			assert.Equal(t, tt.network, d.Network)
+			assert.Equal(t, tt.template, d.Template)
+			assert.Equal(t, testHash160, d.Hash160)
+			assert.Equal(t, testMainnet, d.Mainnet)
+			assert.Equal(t, testTestnet, d.Testnet)
+			assert.NotEmpty(t, d.ScriptHash)
+		})
+	}
+}
+
+func TestDecodeP2SH(t *testing.T) {
+	t.Parallel()
+
+	d := decode("3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy")
+	require.True(t, d.Valid, d.Error)
+	assert.Equal(t, typeP2SH, d.Type)
+	assert.Equal(t, "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb", d.Hash160)
+	assert.Equal(t, "2N9hLwkSqr1cPQAPxbrGVUjxyjD11G2e1he", d.Testnet)
+	assert.Equal(t, "a914b472a266d0bd89c13706a4132ccfb16f7c3b9fcb87", d.Script)
+
+	// The script form decodes to the same addresses
+	s := decode(d.Script)
+	require.True(t, s.Valid, s.Error)
+	assert.Equal(t, "p2sh", s.Template)
+	assert.Equal(t, d.Mainnet, s.Mainnet)
+	assert.Equal(t, d.ScriptHash, s.ScriptHash)
+}
+
+func TestDecodeScriptHash(t *testing.T) {
+	t.Parallel()
+
+	p2pkh := decode(testMainnet)
+	d := decode(p2pkh.ScriptHash)
+	require.True(t, d.Valid, d.Error)
+	assert.Equal(t, typeScriptHash, d.Type)
+	assert.Empty(t, d.Mainnet)
+	assert.NotEmpty(t, d.Description)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"bad checksum", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMx", "checksum"},
+		{"not base58", "0OIl", "not a recognized"},
+		{"unknown version", "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", "unknown version byte"},
+		{"non-standard script", "515193", "not a recognized"},
+		{"odd-length hex is base58", "abc", "not a recognized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := decode(tt.input)
+			assert.False(t, d.Valid)
+			assert.Contains(t, d.Error, tt.err)
+		})
+	}
+}
+
+func TestReadLines(t *testing.T) {
+	t.Parallel()
+
+	lines, err := readLines(strings.NewReader("  " + testMainnet + "\n\n# comment\n" + testTestnet + "\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testMainnet, testTestnet}, lines)
+}
@@ -0,0 +1,324 @@
+// Package main implements a Bitcoin SV double-spend detector.
+//
+// Given a txid or a raw transaction, this tool looks up who spent each of the
+// transaction's inputs on WhatsOnChain. An input spent by a different
+// transaction, in the mempool or in a block, is a conflict. It complements
+// ARC's DOUBLE_SPEND_ATTEMPTED status by checking proactively, including for
+// transactions that have not been broadcast yet.
+//
+// Features:
+//   - Accepts a txid or raw transaction hex (argument or stdin)
+//   - Transactions fetched by txid from the data provider in config.yaml
+//   - Bulk spent-output lookups (20 inputs per request)
+//   - Reports whether each conflicting spend is in the mempool or mined
+//   - Non-zero exit code when a conflict is found
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	doubles <txid>                       # Check a broadcast transaction
+//	doubles 0100000001...                # Check a raw transaction before broadcasting
+//	carve -w <WIF> -a <addr> | doubles   # Check a freshly built transaction
+//	doubles <txid> -j                    # JSON output
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Input statuses
+const (
+	statusUnspent  = "unspent"  // Output not spent by any transaction
+	statusSpent    = "spent"    // Output spent by this transaction
+	statusConflict = "conflict" // Output spent by a different transaction
+	statusCoinbase = "coinbase" // Coinbase input, nothing to check
+)
+
+// Command-line flags
+var (
+	testnet    bool // Use testnet instead of mainnet
+	jsonOutput bool // Output in JSON format
+	noColor    bool // Disable colored output
+)
+
+// inputResult is the spend status of one input's previous output.
+type inputResult struct {
+	Index     int    `json:"index"`
+	PrevTxID  string `json:"prevTxid"`
+	Vout      uint32 `json:"vout"`
+	Status    string `json:"status"`
+	SpentBy   string `json:"spentBy,omitempty"`   // Spending transaction, if any
+	SpentVin  int    `json:"spentVin,omitempty"`  // Input index in the spending transaction
+	Confirmed bool   `json:"confirmed,omitempty"` // Spending transaction is mined
+	Height    int64  `json:"height,omitempty"`    // Block height of the spending transaction
+}
+
+// report holds the outcome of a double-spend check.
+type report struct {
+	TxID      string         `json:"txid"`
+	Inputs    []*inputResult `json:"inputs"`
+	Conflicts int            `json:"conflicts"`
+}
+
+// rootCmd is the main cobra command for the doubles tool.
+var rootCmd = &cobra.Command{
+	Use:   "doubles [txid|rawtx]",
+	Short: "Detect double spends of a transaction's inputs",
+	Long: `A command line tool that checks each input of a transaction for competing
+spends in the mempool and in blocks via WhatsOnChain. The transaction may be given
+as a txid or as raw hex, so unbroadcast transactions can be checked too.
+
+Exits non-zero if any input is spent by a different transaction.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	input, err := getInput(args)
+	if err != nil {
+		return err
+	}
+
+	if input == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no txid or raw transaction provided")
+	}
+
+	if !cli.IsValidHex(input) {
+		return fmt.Errorf("input is not a valid hex string")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	tx, err := loadTransaction(ctx, provider, input)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain looks up spent outputs, whatever the data provider
+	r, err := check(ctx, provider.WOC.Client, tx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	} else {
+		printReport(r)
+	}
+
+	if r.Conflicts > 0 {
+		return fmt.Errorf("%d input(s) double-spent", r.Conflicts)
+	}
+	return nil
+}
+
+// getInput retrieves the txid or raw transaction from argument or stdin.
+func getInput(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// loadTransaction parses raw transaction hex, or fetches the transaction from
+// txs when given a txid.
+func loadTransaction(ctx context.Context, txs chain.TxFetcher, input string) (*transaction.Transaction, error) {
+	rawTx := input
+	if len(input) == chainhash.MaxHashStringSize {
+		var err error
+		if rawTx, err = txs.RawTx(ctx, input); err != nil {
+			return nil, fmt.Errorf("getting raw transaction: %w", err)
+		}
+	}
+
+	tx, err := transaction.NewTransactionFromHex(strings.TrimSpace(rawTx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// check looks up the spend status of every input of tx.
+func check(ctx context.Context, client whatsonchain.ClientInterface, tx *transaction.Transaction) (*report, error) {
+	txid := tx.TxID().String()
+	r := &report{TxID: txid, Inputs: make([]*inputResult, 0, len(tx.Inputs))}
+
+	byOutpoint := make(map[string]*inputResult, len(tx.Inputs))
+	var utxos []whatsonchain.BulkSpentUTXO
+	for i, in := range tx.Inputs {
+		res := &inputResult{Index: i, PrevTxID: in.SourceTXID.String(), Vout: in.SourceTxOutIndex, Status: statusUnspent}
+		r.Inputs = append(r.Inputs, res)
+
+		if in.SourceTXID.IsEqual(&chainhash.Hash{}) {
+			res.Status = statusCoinbase
+			continue
+		}
+		byOutpoint[outpoint(res.PrevTxID, int(res.Vout))] = res
+		utxos = append(utxos, whatsonchain.BulkSpentUTXO{TxID: res.PrevTxID, Vout: int(res.Vout)})
+	}
+
+	for start := 0; start < len(utxos); start += whatsonchain.MaxTransactionsUTXO {
+		end := min(start+whatsonchain.MaxTransactionsUTXO, len(utxos))
+		results, err := client.BulkSpentOutputs(ctx, &whatsonchain.BulkSpentOutputRequest{UTXOs: utxos[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("looking up spent outputs: %w", err)
+		}
+
+		for _, sr := range results {
+			res := byOutpoint[outpoint(sr.TxID, sr.Vout)]
+			if res == nil || sr.Spent == nil || sr.Spent.TxID == "" {
+				continue
+			}
+			res.SpentBy = sr.Spent.TxID
+			res.SpentVin = sr.Spent.Vin
+			if sr.Spent.TxID == txid {
+				res.Status = statusSpent
+			} else {
+				res.Status = statusConflict
+				r.Conflicts++
+			}
+		}
+	}
+
+	// Look up where each spending transaction is, once per transaction
+	located := make(map[string]*whatsonchain.TxInfo)
+	for _, res := range r.Inputs {
+		if res.SpentBy == "" {
+			continue
+		}
+		info, ok := located[res.SpentBy]
+		if !ok {
+			var err error
+			if info, err = client.GetTxByHash(ctx, res.SpentBy); err != nil && !notFound(client, err) {
+				return nil, fmt.Errorf("looking up spending transaction %s: %w", res.SpentBy, err)
+			}
+			located[res.SpentBy] = info
+		}
+		if info != nil && info.Confirmations > 0 {
+			res.Confirmed = true
+			res.Height = info.BlockHeight
+		}
+	}
+
+	return r, nil
+}
+
+// notFound reports whether err means the transaction is not known.
+func notFound(client whatsonchain.ClientInterface, err error) bool {
+	if errors.Is(err, whatsonchain.ErrTransactionNotFound) {
+		return true
+	}
+	last := client.LastRequest()
+	return last != nil && last.StatusCode == http.StatusNotFound
+}
+
+// outpoint formats a txid:vout key.
+func outpoint(txid string, vout int) string {
+	return fmt.Sprintf("%s:%d", txid, vout)
+}
+
+// c wraps text in an ANSI color code unless color output is disabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// location describes where a spending transaction is.
+func location(res *inputResult) string {
+	if res.Confirmed {
+		return fmt.Sprintf("block %d", res.Height)
+	}
+	return "mempool"
+}
+
+// printReport prints the check result in human-readable form.
+func printReport(r *report) {
+	fmt.Printf("%s %s\n\n", c(colorDim, "Transaction:"), r.TxID)
+
+	for _, res := range r.Inputs {
+		prefix := fmt.Sprintf("Input %d %s", res.Index, c(colorDim, fmt.Sprintf("%s:%d", res.PrevTxID, res.Vout)))
+		switch res.Status {
+		case statusConflict:
+			fmt.Printf("%s %s\n", c(colorRed, "✗"), prefix)
+			fmt.Printf("    %s spent by %s (input %d, %s)\n", c(colorRed, "CONFLICT"), res.SpentBy, res.SpentVin, location(res))
+		case statusSpent:
+			fmt.Printf("%s %s\n", c(colorGreen, "✓"), prefix)
+			fmt.Printf("    spent by this transaction (%s)\n", location(res))
+		case statusCoinbase:
+			fmt.Printf("%s %s\n", c(colorDim, "-"), prefix)
+			fmt.Println("    coinbase input")
+		default:
+			fmt.Printf("%s %s\n", c(colorGreen, "✓"), prefix)
+			fmt.Println("    unspent")
+		}
+	}
+
+	fmt.Println()
+	if r.Conflicts > 0 {
+		fmt.Println(c(colorRed, fmt.Sprintf("✗ %d of %d input(s) double-spent", r.Conflicts, len(r.Inputs))))
+	} else {
+		fmt.Println(c(colorGreen, "✓ No conflicts found"))
+	}
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the doubles command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTxA     = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testTxB     = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	testRival   = "9b0fc92260312ce44e74ef369f5c66bbb85848f2eddd5a7a1cde251e54ccfdd5"
+	testAddress = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+)
+
+// fakeClient serves canned spent outputs and transaction info; all other
+// client methods are unimplemented.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	spent    map[string]*whatsonchain.SpentOutput
+	txs      map[string]*whatsonchain.TxInfo
+	requests int
+	err      error
+}
+
+func (f *fakeClient) BulkSpentOutputs(_ context.Context, req *whatsonchain.BulkSpentOutputRequest) (whatsonchain.BulkSpentOutputResponse, error) {
+	f.requests++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var resp whatsonchain.BulkSpentOutputResponse
+	for _, u := range req.UTXOs {
+		resp = append(resp, whatsonchain.BulkSpentOutputResult{TxID: u.TxID, Vout: u.Vout, Spent: f.spent[outpoint(u.TxID, u.Vout)]})
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) GetTxByHash(_ context.Context, hash string) (*whatsonchain.TxInfo, error) {
+	if info, ok := f.txs[hash]; ok {
+		return info, nil
+	}
+	return nil, whatsonchain.ErrTransactionNotFound
+}
+
+func (f *fakeClient) LastRequest() *whatsonchain.LastRequest {
+	return nil
+}
+
+// spendingTx builds a transaction spending the given outpoints.
+func spendingTx(t *testing.T, outpoints ...whatsonchain.BulkSpentUTXO) *transaction.Transaction {
+	t.Helper()
+
+	tx := transaction.NewTransaction()
+	for _, op := range outpoints {
+		hash, err := chainhash.NewHashFromHex(op.TxID)
+		require.NoError(t, err)
+		tx.AddInput(&transaction.TransactionInput{SourceTXID: hash, SourceTxOutIndex: uint32(op.Vout)}) //nolint:gosec // test vout
+	}
+	require.NoError(t, tx.PayToAddress(testAddress, 1000))
+	return tx
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("unbroadcast transaction with unspent inputs", func(t *testing.T) {
+		t.Parallel()
+
+		tx := spendingTx(t, whatsonchain.BulkSpentUTXO{TxID: testTxA, Vout: 0})
+		r, err := check(ctx, &fakeClient{}, tx)
+		require.NoError(t, err)
+
+		require.Len(t, r.Inputs, 1)
+		assert.Equal(t, statusUnspent, r.Inputs[0].Status)
+		assert.Zero(t, r.Conflicts)
+	})
+
+	t.Run("inputs spent by the transaction itself", func(t *testing.T) {
+		t.Parallel()
+
+		tx := spendingTx(t, whatsonchain.BulkSpentUTXO{TxID: testTxA, Vout: 1})
+		self := tx.TxID().String()
+		client := &fakeClient{
+			spent: map[string]*whatsonchain.SpentOutput{outpoint(testTxA, 1): {TxID: self, Vin: 0}},
+			txs:   map[string]*whatsonchain.TxInfo{self: {Confirmations: 3, BlockHeight: 800000}},
+		}
+
+		r, err := check(ctx, client, tx)
+		require.NoError(t, err)
+		assert.Equal(t, statusSpent, r.Inputs[0].Status)
+		assert.True(t, r.Inputs[0].Confirmed)
+		assert.Equal(t, int64(800000), r.Inputs[0].Height)
+		assert.Zero(t, r.Conflicts)
+	})
+
+	t.Run("competing spends in the mempool and in a block", func(t *testing.T) {
+		t.Parallel()
+
+		tx := spendingTx(t,
+			whatsonchain.BulkSpentUTXO{TxID: testTxA, Vout: 0},
+			whatsonchain.BulkSpentUTXO{TxID: testTxB, Vout: 2},
+		)
+		client := &fakeClient{
+			spent: map[string]*whatsonchain.SpentOutput{
+				outpoint(testTxA, 0): {TxID: testRival, Vin: 0},
+				outpoint(testTxB, 2): {TxID: testTxA, Vin: 4},
+			},
+			txs: map[string]*whatsonchain.TxInfo{testTxA: {Confirmations: 1, BlockHeight: 900000}},
+		}
+
+		r, err := check(ctx, client, tx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, r.Conflicts)
+
+		assert.Equal(t, statusConflict, r.Inputs[0].Status)
+		assert.Equal(t, testRival, r.Inputs[0].SpentBy)
+		assert.False(t, r.Inputs[0].Confirmed)
+		assert.Equal(t, "mempool", location(r.Inputs[0]))
+
+		assert.Equal(t, statusConflict, r.Inputs[1].Status)
+		assert.Equal(t, 4, r.Inputs[1].SpentVin)
+		assert.Equal(t, "block 900000", location(r.Inputs[1]))
+	})
+
+	t.Run("coinbase inputs are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		tx := spendingTx(t, whatsonchain.BulkSpentUTXO{TxID: (&chainhash.Hash{}).String(), Vout: 0})
+		client := &fakeClient{}
+		r, err := check(ctx, client, tx)
+		require.NoError(t, err)
+		assert.Equal(t, statusCoinbase, r.Inputs[0].Status)
+		assert.Zero(t, client.requests)
+	})
+
+	t.Run("lookups are batched", func(t *testing.T) {
+		t.Parallel()
+
+		outpoints := make([]whatsonchain.BulkSpentUTXO, whatsonchain.MaxTransactionsUTXO+1)
+		for i := range outpoints {
+			outpoints[i] = whatsonchain.BulkSpentUTXO{TxID: testTxA, Vout: i}
+		}
+		client := &fakeClient{}
+		r, err := check(ctx, client, spendingTx(t, outpoints...))
+		require.NoError(t, err)
+		assert.Len(t, r.Inputs, len(outpoints))
+		assert.Equal(t, 2, client.requests)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		t.Parallel()
+
+		tx := spendingTx(t, whatsonchain.BulkSpentUTXO{TxID: testTxA, Vout: 0})
+		_, err := check(ctx, &fakeClient{err: errors.New("boom")}, tx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "looking up spent outputs")
+	})
+}
@@ -0,0 +1,295 @@
+// Package main implements a Bitcoin SV transaction fee auditor.
+//
+// Given a raw transaction, this tool resolves the value of every input, computes
+// the fee and the fee rate in satoshis per kilobyte, and compares it against the
+// mining fee of the configured ARC node. It exits non-zero when the transaction
+// underpays, so it can be used as a pre-broadcast gate in CI pipelines.
+//
+// Features:
+//   - Accepts raw or Extended Format (EF) transaction hex (argument or stdin)
+//   - Input values taken from EF source outputs, or fetched from the data provider in config.yaml
+//   - Mining fee read from the ARC policy endpoint, or set with --min-rate
+//   - Non-zero exit code when the fee is below the required minimum
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	feecheck 0100000001...                   # Check against the ARC policy
+//	carve -w <WIF> -a <addr> | feecheck      # Check a freshly built transaction
+//	feecheck 0100000001... --min-rate 100    # Check against 100 sat/kB, no ARC needed
+//	feecheck 0100000001... -j                # JSON output
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/config"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Command-line flags
+var (
+	testnet    bool   // Use testnet instead of mainnet
+	jsonOutput bool   // Output in JSON format
+	noColor    bool   // Disable colored output
+	minRate    uint64 // Required fee rate in sat/kB, overrides the ARC policy
+)
+
+// report holds the outcome of a fee audit.
+type report struct {
+	TxID        string  `json:"txid"`
+	Size        int     `json:"size"`
+	Inputs      int     `json:"inputs"`
+	Fetched     int     `json:"fetched"` // Parent transactions fetched to resolve input values
+	InputTotal  uint64  `json:"inputTotal"`
+	OutputTotal uint64  `json:"outputTotal"`
+	Fee         uint64  `json:"fee"`
+	Rate        float64 `json:"rate"`        // Paid fee rate in sat/kB
+	MinRate     float64 `json:"minRate"`     // Required fee rate in sat/kB
+	RequiredFee uint64  `json:"requiredFee"` // Minimum fee for this transaction's size
+	Source      string  `json:"source"`      // Where the required rate came from
+	OK          bool    `json:"ok"`
+}
+
+// rootCmd is the main cobra command for the feecheck tool.
+var rootCmd = &cobra.Command{
+	Use:   "feecheck [rawtx]",
+	Short: "Audit a transaction's fee against ARC policy",
+	Long: `A command line tool that computes the fee and fee rate of a raw transaction
+and compares it against the mining fee of the ARC node in config.yaml. Input values
+are read from Extended Format transactions or fetched from the data provider
+in config.yaml (WhatsOnChain by default).
+
+Exits non-zero if the transaction pays less than the required fee.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	input, err := getInput(args)
+	if err != nil {
+		return err
+	}
+
+	if input == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no transaction provided")
+	}
+
+	if !cli.IsValidHex(input) {
+		return fmt.Errorf("input is not a valid hex string")
+	}
+
+	tx, err := transaction.NewTransactionFromHex(input)
+	if err != nil {
+		return fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	miningFee, source, err := requiredRate(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	fetched, err := resolveInputs(ctx, provider, tx)
+	if err != nil {
+		return err
+	}
+
+	r, err := audit(tx, miningFee)
+	if err != nil {
+		return err
+	}
+	r.Fetched = fetched
+	r.Source = source
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	} else {
+		printReport(r)
+	}
+
+	if !r.OK {
+		return fmt.Errorf("fee of %d satoshis is below the required %d satoshis", r.Fee, r.RequiredFee)
+	}
+	return nil
+}
+
+// getInput retrieves the raw transaction from argument or stdin.
+func getInput(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// requiredRate returns the mining fee to check against and where it came from.
+// The --min-rate flag takes precedence over the ARC policy.
+func requiredRate(cmd *cobra.Command) (arc.MiningFee, string, error) {
+	if cmd.Flags().Changed("min-rate") {
+		return arc.MiningFee{Satoshis: minRate, Bytes: 1000}, "flag", nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return arc.MiningFee{}, "", fmt.Errorf("loading configuration: %w", err)
+	}
+	if err := cfg.Validate(testnet); err != nil {
+		return arc.MiningFee{}, "", err
+	}
+
+	arcConfig := cfg.GetARCConfig(testnet)
+	policy, err := arc.NewARCClient(arcConfig.URL, arcConfig.APIKey).GetPolicy()
+	if err != nil {
+		return arc.MiningFee{}, "", fmt.Errorf("fetching ARC policy: %w", err)
+	}
+	if policy.Policy.MiningFee.Bytes == 0 {
+		return arc.MiningFee{}, "", fmt.Errorf("ARC policy has no mining fee")
+	}
+	return policy.Policy.MiningFee, arcConfig.URL, nil
+}
+
+// resolveInputs attaches source outputs to inputs that lack them by fetching each
+// parent transaction once from txs. It returns the number of transactions fetched.
+func resolveInputs(ctx context.Context, txs chain.TxFetcher, tx *transaction.Transaction) (int, error) {
+	parents := make(map[string]*transaction.Transaction)
+	for i, in := range tx.Inputs {
+		if in.SourceTxOutput() != nil {
+			continue
+		}
+
+		txid := in.SourceTXID.String()
+		parent, ok := parents[txid]
+		if !ok {
+			raw, err := txs.RawTx(ctx, txid)
+			if err != nil {
+				return 0, fmt.Errorf("getting input %d source transaction: %w", i, err)
+			}
+			if parent, err = transaction.NewTransactionFromHex(raw); err != nil {
+				return 0, fmt.Errorf("failed to parse source transaction %s: %w", txid, err)
+			}
+			parents[txid] = parent
+		}
+
+		if int(in.SourceTxOutIndex) >= len(parent.Outputs) {
+			return 0, fmt.Errorf("input %d spends output %d of %s, which has %d outputs", i, in.SourceTxOutIndex, txid, len(parent.Outputs))
+		}
+		in.SetSourceTxOutput(parent.Outputs[in.SourceTxOutIndex])
+	}
+	return len(parents), nil
+}
+
+// audit computes the fee of tx, whose inputs must all be resolved, and checks it
+// against miningFee. The required fee is rounded down, as ARC does.
+func audit(tx *transaction.Transaction, miningFee arc.MiningFee) (*report, error) {
+	r := &report{
+		TxID:    tx.TxID().String(),
+		Size:    tx.Size(),
+		Inputs:  len(tx.Inputs),
+		MinRate: miningFee.FeePerKb(),
+	}
+
+	for i, in := range tx.Inputs {
+		sats := in.SourceTxSatoshis()
+		if sats == nil {
+			return nil, fmt.Errorf("input %d value is unknown", i)
+		}
+		r.InputTotal += *sats
+	}
+	for _, out := range tx.Outputs {
+		r.OutputTotal += out.Satoshis
+	}
+
+	if r.OutputTotal > r.InputTotal {
+		return nil, fmt.Errorf("outputs (%d satoshis) exceed inputs (%d satoshis)", r.OutputTotal, r.InputTotal)
+	}
+
+	r.Fee = r.InputTotal - r.OutputTotal
+	r.Rate = float64(r.Fee) * 1000 / float64(r.Size)
+	if miningFee.Bytes > 0 {
+		r.RequiredFee = uint64(r.Size) * miningFee.Satoshis / miningFee.Bytes //nolint:gosec // size is never negative
+	}
+	r.OK = r.Fee >= r.RequiredFee
+	return r, nil
+}
+
+// c wraps text in an ANSI color code unless color output is disabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printReport prints the audit result in human-readable form.
+func printReport(r *report) {
+	fmt.Printf("%s %s\n", c(colorDim, "Transaction:"), r.TxID)
+	fmt.Printf("%s %d bytes, %d input(s)\n", c(colorDim, "Size:       "), r.Size, r.Inputs)
+	fmt.Printf("%s %d satoshis\n", c(colorDim, "Inputs:     "), r.InputTotal)
+	fmt.Printf("%s %d satoshis\n", c(colorDim, "Outputs:    "), r.OutputTotal)
+	fmt.Printf("%s %d satoshis (%.2f sat/kB)\n", c(colorDim, "Fee:        "), r.Fee, r.Rate)
+	fmt.Printf("%s %d satoshis (%.2f sat/kB, %s)\n", c(colorDim, "Required:   "), r.RequiredFee, r.MinRate, r.Source)
+
+	fmt.Println()
+	if r.OK {
+		fmt.Println(c(colorGreen, "✓ Fee meets policy"))
+	} else {
+		fmt.Println(c(colorRed, fmt.Sprintf("✗ Underpaying by %d satoshis", r.RequiredFee-r.Fee)))
+	}
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.Flags().Uint64Var(&minRate, "min-rate", 0, "Required fee rate in sat/kB (skips the ARC policy lookup)")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the feecheck command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
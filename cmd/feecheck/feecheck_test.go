@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+const testAddress = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+
+// parentTx builds a transaction paying the given amounts to the test address.
+func parentTx(t *testing.T, amounts ...uint64) *transaction.Transaction {
+	t.Helper()
+
+	tx := transaction.NewTransaction()
+	for _, amount := range amounts {
+		require.NoError(t, tx.PayToAddress(testAddress, amount))
+	}
+	return tx
+}
+
+// childTx builds a transaction spending the given outputs of parent and paying amount.
+func childTx(t *testing.T, parent *transaction.Transaction, amount uint64, vouts ...uint32) *transaction.Transaction {
+	t.Helper()
+
+	hash, err := chainhash.NewHashFromHex(parent.TxID().String())
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	for _, vout := range vouts {
+		tx.AddInput(&transaction.TransactionInput{SourceTXID: hash, SourceTxOutIndex: vout})
+	}
+	require.NoError(t, tx.PayToAddress(testAddress, amount))
+	return tx
+}
+
+func TestResolveInputs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("fetches each parent once", func(t *testing.T) {
+		t.Parallel()
+
+		parent := parentTx(t, 1000, 2000)
+		tx := childTx(t, parent, 2500, 0, 1)
+		txs := chain.NewMock()
+		txs.AddTx(parent)
+
+		fetched, err := resolveInputs(ctx, txs, tx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fetched)
+		assert.Len(t, txs.Fetched, 1)
+		assert.Equal(t, uint64(1000), *tx.Inputs[0].SourceTxSatoshis())
+		assert.Equal(t, uint64(2000), *tx.Inputs[1].SourceTxSatoshis())
+	})
+
+	t.Run("extended format needs no lookups", func(t *testing.T) {
+		t.Parallel()
+
+		parent := parentTx(t, 5000)
+		tx := childTx(t, parent, 4000, 0)
+		tx.Inputs[0].SetSourceTxOutput(parent.Outputs[0])
+		tx.Inputs[0].UnlockingScript = parent.Outputs[0].LockingScript
+
+		ef, err := tx.EFHex()
+		require.NoError(t, err)
+		parsed, err := transaction.NewTransactionFromHex(ef)
+		require.NoError(t, err)
+
+		txs := chain.NewMock()
+		fetched, err := resolveInputs(ctx, txs, parsed)
+		require.NoError(t, err)
+		assert.Zero(t, fetched)
+		assert.Empty(t, txs.Fetched)
+		assert.Equal(t, uint64(5000), *parsed.Inputs[0].SourceTxSatoshis())
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		t.Parallel()
+
+		tx := childTx(t, parentTx(t, 1000), 500, 0)
+		_, err := resolveInputs(ctx, chain.NewMock(), tx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("output index out of range", func(t *testing.T) {
+		t.Parallel()
+
+		parent := parentTx(t, 1000)
+		tx := childTx(t, parent, 500, 3)
+		txs := chain.NewMock()
+		txs.AddTx(parent)
+
+		_, err := resolveInputs(ctx, txs, tx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has 1 outputs")
+	})
+}
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	resolved := func(t *testing.T, in, out uint64) *transaction.Transaction {
+		t.Helper()
+		parent := parentTx(t, in)
+		tx := childTx(t, parent, out, 0)
+		tx.Inputs[0].SetSourceTxOutput(parent.Outputs[0])
+		return tx
+	}
+
+	t.Run("pays the policy rate", func(t *testing.T) {
+		t.Parallel()
+
+		tx := resolved(t, 10000, 9900)
+		r, err := audit(tx, arc.MiningFee{Satoshis: 1, Bytes: 1000})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(100), r.Fee)
+		assert.Equal(t, tx.Size(), r.Size)
+		assert.InDelta(t, 100*1000/float64(tx.Size()), r.Rate, 0.0001)
+		assert.Zero(t, r.RequiredFee)
+		assert.True(t, r.OK)
+	})
+
+	t.Run("underpays", func(t *testing.T) {
+		t.Parallel()
+
+		tx := resolved(t, 10000, 9999)
+		r, err := audit(tx, arc.MiningFee{Satoshis: 100, Bytes: 1000})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), r.Fee)
+		assert.Equal(t, uint64(tx.Size())*100/1000, r.RequiredFee)
+		assert.InDelta(t, 100.0, r.MinRate, 0.0001)
+		assert.False(t, r.OK)
+	})
+
+	t.Run("outputs exceed inputs", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := audit(resolved(t, 1000, 2000), arc.MiningFee{Satoshis: 1, Bytes: 1000})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceed inputs")
+	})
+
+	t.Run("unresolved input", func(t *testing.T) {
+		t.Parallel()
+
+		tx := childTx(t, parentTx(t, 1000), 500, 0)
+		_, err := audit(tx, arc.MiningFee{Satoshis: 1, Bytes: 1000})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "value is unknown")
+	})
+}
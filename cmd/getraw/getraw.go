@@ -7,8 +7,13 @@
 // Features:
 //   - Mainnet/testnet support via --testnet flag
 //   - Flexible input: argument, flag, or stdin
-//   - Direct integration with WhatsOnChain API
+//   - Direct integration with WhatsOnChain API, or the data provider in config.yaml
 //   - Easy chaining with other tools (e.g., prettytx)
+//   - Extended Format (EF) or BEEF output via --format
+//   - Decoded output via --pretty (as prettytx prints it) or --json
+//   - Resumable bulk export of an address's full history via `getraw address`
+//   - Raw block header, or every transaction of a block in order, via `getraw block`
+//   - Local cache of <txid>.hex files via --if-missing, downloading only what it lacks
 //
 // Usage:
 //
@@ -17,15 +22,40 @@
 //	echo <txid> | getraw             # Fetch from stdin
 //	getraw <txid> -t                 # Fetch from testnet
 //	getraw <txid> | prettytx         # Chain with prettytx
+//	getraw <txid> -f beef            # Fetch as BEEF with ancestors and proofs
+//	getraw <txid> --pretty           # Fetch and decode, like piping to prettytx
+//	getraw <txid> --json             # Fetch and decode as JSON
+//	getraw address <addr> --export dir/ # Save every transaction of an address
+//	getraw block <hash|height>       # Fetch a raw block header
+//	getraw block <hash|height> --txs # Stream every transaction of a block
+//	getraw <txid> --if-missing dir/  # Read dir/<txid>.hex, or fetch and save it
+//	getraw block <hash> --txs --if-missing dir/ # Download only the block's new transactions
 package main
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-template/internal/chain"
 	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/headers"
+	"github.com/mrz1836/go-template/internal/spv"
+	"github.com/mrz1836/go-template/internal/txinspect"
 	"github.com/mrz1836/go-whatsonchain"
 	"github.com/spf13/cobra"
 )
@@ -34,8 +64,20 @@ import (
 var (
 	testnet bool   // Use testnet instead of mainnet
 	txid    string // Transaction ID provided via flag
+	format  string // Output format: raw, ef, or beef
+	pretty  bool   // Decode the transaction for reading instead of printing hex
+	jsonOut bool   // Decode the transaction as JSON instead of printing hex
+	noColor bool   // Disable colored --pretty output
+
+	exportDir string // Directory to export an address's transactions into
+	workers   int    // Concurrent bulk transaction downloads
+	blockTxs  bool   // Stream a block's transactions instead of its header
+	ifMissing string // Cache directory of <txid>.hex files to read before downloading
 )
 
+// indexFile is the export file listing the address's full history.
+const indexFile = "history.json"
+
 // rootCmd is the main cobra command for the getraw tool.
 var rootCmd = &cobra.Command{
 	Use:   "getraw [txid]",
@@ -58,10 +100,57 @@ var rootCmd = &cobra.Command{
 			return fmt.Errorf("txid is not a valid hex string: %s", transactionID)
 		}
 
+		format = strings.ToLower(format)
+		if format != spv.FormatRaw && format != spv.FormatEF && format != spv.FormatBEEF {
+			return fmt.Errorf("invalid --format %q: must be raw, ef, or beef", format)
+		}
+		if pretty && jsonOut {
+			return fmt.Errorf("--pretty cannot be used with --json")
+		}
+		if ifMissing != "" && format != spv.FormatRaw {
+			return fmt.Errorf("--if-missing caches raw transactions; it cannot be used with --format %s", format)
+		}
+
 		return getRawFromWhatsOnChain(transactionID)
 	},
 }
 
+// addressCmd exports every transaction involving an address.
+var addressCmd = &cobra.Command{
+	Use:   "address <address>",
+	Short: "Export an address's full transaction history",
+	Long: "Downloads the raw hex of every confirmed and unconfirmed transaction involving an address into <txid>.hex files, " +
+		"with the history listed in " + indexFile + ". Transactions already in the directory are skipped, so an interrupted export can be resumed by running it again",
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if _, err := script.NewAddressFromString(args[0]); err != nil {
+			return fmt.Errorf("invalid address %q: %w", args[0], err)
+		}
+		if workers < 1 {
+			return fmt.Errorf("--workers must be at least 1")
+		}
+		return exportAddress(args[0])
+	},
+}
+
+// blockCmd prints a block's raw header or streams its transactions.
+var blockCmd = &cobra.Command{
+	Use:   "block <hash|height>",
+	Short: "Fetch a block's raw header or transactions",
+	Long: "Prints the raw 80-byte header of a block, or with --txs the raw hex of every transaction in the block, " +
+		"one per line in block order. Transactions are downloaded concurrently in bulk batches but written in order",
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if workers < 1 {
+			return fmt.Errorf("--workers must be at least 1")
+		}
+		if ifMissing != "" && !blockTxs {
+			return fmt.Errorf("--if-missing requires --txs")
+		}
+		return getBlock(args[0])
+	},
+}
+
 // getTransactionID retrieves the transaction ID from argument, flag, or stdin.
 func getTransactionID(cmd *cobra.Command, args []string) (string, error) {
 	// Get txid from command line argument if provided
@@ -86,45 +175,605 @@ func getTransactionID(cmd *cobra.Command, args []string) (string, error) {
 
 // getRawFromWhatsOnChain fetches raw transaction data from the WhatsOnChain API.
 // It creates a client for the appropriate network (mainnet/testnet) based on the
-// --testnet flag, queries the API for the transaction, and prints the raw hex to stdout.
+// --testnet flag, queries the configured data provider (WhatsOnChain by default) for
+// the transaction, and prints the raw hex to stdout.
+// With --format ef or beef, the source transactions (and for BEEF, the merkle proofs)
+// are fetched as well and the transaction is printed in that format.
 //
 // Logs the chain and network information to stderr.
 // Outputs the raw transaction hex to stdout for easy piping to other tools.
+//
+// With --if-missing, a cached copy in that directory is printed without any
+// network call, and a downloaded transaction is saved there.
 func getRawFromWhatsOnChain(txid string) error {
 	ctx := context.Background()
 
-	var client whatsonchain.ClientInterface
-	var err error
-
-	// Create client based on testnet flag
-	if testnet {
-		client, err = whatsonchain.NewClient(ctx, whatsonchain.WithNetwork(whatsonchain.NetworkTest))
-	} else {
-		client, err = whatsonchain.NewClient(ctx, whatsonchain.WithNetwork(whatsonchain.NetworkMain))
+	if ifMissing != "" {
+		rawTx, err := cachedTransaction(ifMissing, txid)
+		if err != nil {
+			return err
+		}
+		if rawTx != "" {
+			log.Printf("Using %s\n", txPath(ifMissing, txid))
+			return printTransaction(rawTx)
+		}
 	}
 
+	// Create providers based on config.yaml and the testnet flag
+	provider, err := chain.Load(ctx, testnet)
 	if err != nil {
-		return fmt.Errorf("creating WhatsOnChain client: %w", err)
+		return err
 	}
 
-	log.Printf("Chain: %s, Network: %s\n", client.Chain(), client.Network())
+	log.Printf("Chain: %s, Network: %s\n", provider.WOC.Client.Chain(), provider.WOC.Client.Network())
+
+	// BEEF needs merkle proofs, which only WhatsOnChain serves
+	if format != spv.FormatRaw {
+		r := spv.NewResolver(provider.WOC.Client)
+		r.Txs = provider
+		out, err := formatTransaction(ctx, r, txid, format)
+		if err != nil {
+			return err
+		}
+		return printTransaction(out)
+	}
 
-	// Get raw transaction data
-	rawTx, err := client.GetRawTransactionData(ctx, txid)
+	// Get raw transaction data from the configured data provider
+	rawTx, err := provider.RawTx(ctx, txid)
 	if err != nil {
 		return fmt.Errorf("getting raw transaction: %w", err)
 	}
 
-	// Print the raw transaction hex
-	fmt.Println(rawTx)
+	if ifMissing != "" {
+		if err = cacheTransaction(ifMissing, txid, rawTx); err != nil {
+			return err
+		}
+	}
+	return printTransaction(rawTx)
+}
+
+// printTransaction prints the fetched transaction hex, or with --pretty or
+// --json decodes it first. EF and BEEF decode with their input values.
+func printTransaction(txHex string) error {
+	if !pretty && !jsonOut {
+		fmt.Println(txHex)
+		return nil
+	}
+
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return fmt.Errorf("decoding hex: %w", err)
+	}
+	tx, txFormat, err := spv.ParseTransaction(txBytes)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		data, err := json.MarshalIndent(txinspect.Inspect(tx, txFormat, testnet), "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printer := &txinspect.Printer{W: os.Stdout, NoColor: noColor, Testnet: testnet}
+	printer.Print(tx, txFormat, nil)
+	return nil
+}
+
+// formatTransaction fetches a transaction and serializes it as EF or BEEF.
+func formatTransaction(ctx context.Context, r *spv.Resolver, txid, format string) (string, error) {
+	tx, err := r.Fetch(ctx, txid, format == spv.FormatBEEF)
+	if err != nil {
+		return "", err
+	}
+	if format == spv.FormatBEEF {
+		return r.BEEF(ctx, tx)
+	}
+	return r.EF(ctx, tx)
+}
+
+// exportAddress lists an address's history and downloads the transactions not
+// yet in the export directory. Progress is drawn as a bar on a terminal and
+// logged to stderr otherwise; an interrupt stops the export and leaves every
+// finished file in place.
+func exportAddress(address string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Only WhatsOnChain lists address history, whatever the data provider
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	woc := provider.WOC
+	log.Printf("Chain: %s, Network: %s\n", woc.Client.Chain(), woc.Client.Network())
+
+	history, err := woc.History(ctx, address)
+	if err != nil {
+		return fmt.Errorf("listing history of %s: %w", address, err)
+	}
+
+	if err = os.MkdirAll(exportDir, 0o750); err != nil {
+		return fmt.Errorf("creating export directory: %w", err)
+	}
+	index, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+	if err = writeFile(filepath.Join(exportDir, indexFile), append(index, '\n')); err != nil {
+		return err
+	}
+
+	pending, err := missingTransactions(history, exportDir)
+	if err != nil {
+		return err
+	}
+	total := len(pending)
+	log.Printf("%d transactions in history, %d to download\n", len(history), total)
+	if total == 0 {
+		return nil
+	}
+
+	var done int
+	bar := cli.NewProgress("Exporting", total)
+	exported, failures := exportTransactions(ctx, woc.Client, pending, exportDir, workers, func(n int) {
+		if bar.Enabled() {
+			bar.Add(n)
+			return
+		}
+		done += n
+		log.Printf("Exported %d/%d\n", done, total)
+	})
+	bar.Finish()
+	if err = ctx.Err(); err != nil {
+		return fmt.Errorf("export interrupted after %d of %d transactions; run again to resume", exported, total)
+	}
+	if len(failures) > 0 {
+		txids := make([]string, 0, len(failures))
+		for txid := range failures {
+			txids = append(txids, txid)
+		}
+		sort.Strings(txids)
+		for _, txid := range txids {
+			log.Printf("%s: %v\n", txid, failures[txid])
+		}
+		return fmt.Errorf("%d of %d transactions failed; run again to retry", len(failures), total)
+	}
+	return nil
+}
+
+// missingTransactions returns the txids in history, once each and in order,
+// that have no file in dir yet.
+func missingTransactions(history []*chain.HistoryTx, dir string) ([]string, error) {
+	seen := make(map[string]bool, len(history))
+	var missing []string
+	for _, h := range history {
+		if seen[h.TxHash] {
+			continue
+		}
+		seen[h.TxHash] = true
+
+		_, err := os.Stat(txPath(dir, h.TxHash))
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.ErrNotExist):
+			missing = append(missing, h.TxHash)
+		default:
+			return nil, fmt.Errorf("checking %s: %w", h.TxHash, err)
+		}
+	}
+	return missing, nil
+}
+
+// exportTransactions downloads txids in bulk batches, running up to n batches
+// at once, and writes each to <txid>.hex in dir once its hex hashes to its txid.
+// progress is called with the size of each finished batch. It returns the
+// number of files written and an error for each transaction that was not.
+func exportTransactions(ctx context.Context, client whatsonchain.ClientInterface, txids []string, dir string, n int,
+	progress func(int),
+) (int, map[string]error) {
+	failures := make(map[string]error)
+	exported := 0
+
+	batches := make(chan []string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				errs := exportBatch(ctx, client, batch, dir)
+
+				mu.Lock()
+				for txid, err := range errs {
+					failures[txid] = err
+				}
+				exported += len(batch) - len(errs)
+				if progress != nil {
+					progress(len(batch))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for start := 0; start < len(txids) && ctx.Err() == nil; start += whatsonchain.MaxTransactionsRaw {
+		end := min(start+whatsonchain.MaxTransactionsRaw, len(txids))
+		batches <- txids[start:end]
+	}
+	close(batches)
+	wg.Wait()
+
+	return exported, failures
+}
+
+// exportBatch downloads one bulk batch and writes its transactions, returning
+// an error for each txid that was not written.
+func exportBatch(ctx context.Context, client whatsonchain.ClientInterface, batch []string, dir string) map[string]error {
+	raws, errs := downloadBatch(ctx, client, batch)
+	for txid, raw := range raws {
+		if err := writeFile(txPath(dir, txid), []byte(raw+"\n")); err != nil {
+			errs[txid] = err
+		}
+	}
+	return errs
+}
+
+// downloadBatch downloads one bulk batch, returning the raw hex of each
+// transaction that hashes to its txid and an error for each txid that does not.
+func downloadBatch(ctx context.Context, client whatsonchain.ClientInterface, batch []string) (map[string]string, map[string]error) {
+	raws := make(map[string]string, len(batch))
+	errs := make(map[string]error)
+	list, err := client.BulkRawTransactionData(ctx, &whatsonchain.TxHashes{TxIDs: batch})
+	if err != nil {
+		for _, txid := range batch {
+			errs[txid] = fmt.Errorf("download failed: %w", err)
+		}
+		return raws, errs
+	}
+
+	// Responses are matched by txid, not position
+	byID := make(map[string]string, len(list))
+	for _, t := range list {
+		if t != nil {
+			byID[t.TxID] = strings.TrimSpace(t.Hex)
+		}
+	}
+	for _, txid := range batch {
+		raw := byID[txid]
+		if raw == "" {
+			errs[txid] = fmt.Errorf("missing from response")
+			continue
+		}
+		tx, err := transaction.NewTransactionFromHex(raw)
+		if err != nil {
+			errs[txid] = fmt.Errorf("parsing transaction: %w", err)
+			continue
+		}
+		if got := tx.TxID().String(); got != txid {
+			errs[txid] = fmt.Errorf("response hashes to %s", got)
+			continue
+		}
+		raws[txid] = raw
+	}
+	return raws, errs
+}
+
+// cachedTransaction returns the raw hex of txid saved in dir, or "" when there
+// is no copy or the copy is stale: unparsable, or hashing to another txid, as
+// a file from an interrupted or older tool may be. A stale copy is logged and
+// replaced by the next download.
+func cachedTransaction(dir, txid string) (string, error) {
+	data, err := os.ReadFile(txPath(dir, txid)) //nolint:gosec // cache path built from a hex txid
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading cached %s: %w", txid, err)
+	}
+
+	raw := strings.TrimSpace(string(data))
+	if tx, err := transaction.NewTransactionFromHex(raw); err != nil || tx.TxID().String() != txid {
+		log.Printf("Cached %s is stale; downloading it again\n", txPath(dir, txid))
+		return "", nil
+	}
+	return raw, nil
+}
+
+// cacheTransaction saves the raw hex of txid in dir once it hashes to txid.
+func cacheTransaction(dir, txid, raw string) error {
+	tx, err := transaction.NewTransactionFromHex(raw)
+	if err != nil {
+		return fmt.Errorf("parsing transaction: %w", err)
+	}
+	if got := tx.TxID().String(); got != txid {
+		return fmt.Errorf("response hashes to %s, not %s", got, txid)
+	}
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return writeFile(txPath(dir, txid), []byte(raw+"\n"))
+}
+
+// getBlock prints the raw header of the block identified by id, or with --txs
+// streams its transactions to stdout. An interrupt stops the stream.
+func getBlock(id string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Only WhatsOnChain serves blocks and their transaction pages, whatever the data provider
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	woc := provider.WOC
+	log.Printf("Chain: %s, Network: %s\n", woc.Client.Chain(), woc.Client.Network())
+
+	info, err := fetchBlock(ctx, woc.Client, id)
+	if err != nil {
+		return err
+	}
+
+	if !blockTxs {
+		header, err := headers.HeaderFromBlockInfo(info)
+		if err != nil {
+			return err
+		}
+		fmt.Println(header.Hex())
+		return nil
+	}
+
+	log.Printf("Block %d (%s): %d transactions\n", info.Height, info.Hash, info.TxCount)
+	if ifMissing != "" {
+		if err = os.MkdirAll(ifMissing, 0o750); err != nil {
+			return fmt.Errorf("creating cache directory: %w", err)
+		}
+	}
+	bar := cli.NewProgress("Streaming", int(info.TxCount))
+	streamed, err := streamBlock(ctx, woc.Client, info, workers, ifMissing, os.Stdout, bar.Add)
+	bar.Finish()
+	if err != nil {
+		return fmt.Errorf("streaming block after %d of %d transactions: %w", streamed, info.TxCount, err)
+	}
+	if int64(streamed) != info.TxCount {
+		return fmt.Errorf("block lists %d transactions but %d were streamed", info.TxCount, streamed)
+	}
+	return nil
+}
+
+// fetchBlock looks up a block by height or hash.
+func fetchBlock(ctx context.Context, client whatsonchain.ClientInterface, id string) (*whatsonchain.BlockInfo, error) {
+	var info *whatsonchain.BlockInfo
+	var err error
+
+	switch {
+	case len(id) == 64 && cli.IsValidHex(id):
+		info, err = client.GetBlockByHash(ctx, id)
+	default:
+		height, perr := strconv.ParseInt(id, 10, 64)
+		if perr != nil || height < 0 {
+			return nil, fmt.Errorf("invalid block height or hash: %s", id)
+		}
+		info, err = client.GetBlockByHeight(ctx, height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching block: %w", err)
+	}
+	if info == nil || info.Hash == "" {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+	return info, nil
+}
+
+// blockBatch is a bulk batch of a block's txids and, once downloaded, its result.
+type blockBatch struct {
+	txids []string
+	raws  []string
+	err   error
+	done  chan struct{}
+}
+
+// streamBlock writes the raw hex of every transaction in the block to w, one
+// per line in block order. Txids are listed a page at a time and downloaded
+// by n workers in bulk batches; at most 2n batches are in flight, so memory
+// stays bounded however large the block. With a cache dir, transactions
+// already saved there are read instead of downloaded, and the rest are saved.
+// progress, if non-nil, is called with the size of each batch once written.
+// It returns the number written.
+func streamBlock(ctx context.Context, client whatsonchain.ClientInterface, info *whatsonchain.BlockInfo, n int, dir string,
+	w io.Writer, progress func(int),
+) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	jobs := make(chan *blockBatch)
+	ordered := make(chan *blockBatch, 2*n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				b.raws, b.err = fetchBlockBatch(ctx, client, b.txids, dir)
+				close(b.done)
+			}
+		}()
+	}
+
+	// List txids page by page, queueing each batch in order before handing
+	// it to a worker; a listing failure is queued as a failed batch
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		defer close(ordered)
+
+		queue := func(b *blockBatch) bool {
+			select {
+			case ordered <- b:
+			case <-ctx.Done():
+				return false
+			}
+			if b.err != nil {
+				return false
+			}
+			select {
+			case jobs <- b:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		txids := info.Tx
+		for page := 0; ; page++ {
+			if page > 0 {
+				if page > len(info.Pages.URI) {
+					return
+				}
+				more, err := client.GetBlockPages(ctx, info.Hash, page)
+				if err != nil {
+					b := &blockBatch{err: fmt.Errorf("fetching block page %d: %w", page, err), done: make(chan struct{})}
+					close(b.done)
+					queue(b)
+					return
+				}
+				txids = more
+			}
+			for start := 0; start < len(txids); start += whatsonchain.MaxTransactionsRaw {
+				end := min(start+whatsonchain.MaxTransactionsRaw, len(txids))
+				if !queue(&blockBatch{txids: txids[start:end], done: make(chan struct{})}) {
+					return
+				}
+			}
+		}
+	}()
+
+	written := 0
+	for b := range ordered {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return written, ctx.Err()
+		}
+		if b.err != nil {
+			return written, b.err
+		}
+		for _, raw := range b.raws {
+			if _, err := fmt.Fprintln(w, raw); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if progress != nil {
+			progress(len(b.raws))
+		}
+	}
+	return written, ctx.Err()
+}
+
+// fetchBlockBatch downloads one bulk batch, returning the raw hex in batch
+// order, or an error if any transaction is missing or does not hash to its txid.
+// With a cache dir, only the transactions not saved there are downloaded, and
+// those are saved.
+func fetchBlockBatch(ctx context.Context, client whatsonchain.ClientInterface, batch []string, dir string) ([]string, error) {
+	raws := make(map[string]string, len(batch))
+	var errs map[string]error
+	missing := batch
+	if dir != "" {
+		missing = nil
+		for _, txid := range batch {
+			raw, err := cachedTransaction(dir, txid)
+			switch {
+			case err != nil:
+				return nil, err
+			case raw != "":
+				raws[txid] = raw
+			default:
+				missing = append(missing, txid)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		var downloaded map[string]string
+		downloaded, errs = downloadBatch(ctx, client, missing)
+		for txid, raw := range downloaded {
+			if dir != "" {
+				if err := writeFile(txPath(dir, txid), []byte(raw+"\n")); err != nil {
+					return nil, err
+				}
+			}
+			raws[txid] = raw
+		}
+	}
+
+	ordered := make([]string, 0, len(batch))
+	for _, txid := range batch {
+		if err := errs[txid]; err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txid, err)
+		}
+		ordered = append(ordered, raws[txid])
+	}
+	return ordered, nil
+}
+
+// txPath returns the export file for txid.
+func txPath(dir, txid string) string {
+	return filepath.Join(dir, txid+".hex")
+}
+
+// writeFile writes data to path via a temporary file and rename, so an
+// interrupted export never leaves a partial file that would be skipped on resume.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // no-op after a successful rename
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", filepath.Base(path), err)
+	}
 	return nil
 }
 
 // init initializes the cobra command flags.
 // This function is automatically called by Go before main() executes.
 func init() {
-	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
 	rootCmd.Flags().StringVarP(&txid, "txid", "i", "", "Transaction ID to retrieve")
+	rootCmd.Flags().StringVarP(&format, "format", "f", spv.FormatRaw, "Output format: raw, ef, or beef")
+	rootCmd.Flags().BoolVarP(&pretty, "pretty", "p", false, "Decode the transaction for reading, as prettytx prints it")
+	rootCmd.Flags().BoolVarP(&jsonOut, "json", "j", false, "Decode the transaction as JSON")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored --pretty output")
+	rootCmd.Flags().StringVar(&ifMissing, "if-missing", "", "Read the transaction from <txid>.hex in this directory, or download and save it there")
+
+	addressCmd.Flags().StringVarP(&exportDir, "export", "e", "", "Directory to write <txid>.hex files and "+indexFile+" into")
+	addressCmd.Flags().IntVarP(&workers, "workers", "c", 3, "Concurrent bulk downloads (20 transactions each)")
+	_ = addressCmd.MarkFlagRequired("export")
+
+	blockCmd.Flags().BoolVar(&blockTxs, "txs", false, "Stream the raw hex of every transaction, one per line, instead of the header")
+	blockCmd.Flags().IntVarP(&workers, "workers", "c", 3, "Concurrent bulk downloads (20 transactions each)")
+	blockCmd.Flags().StringVar(&ifMissing, "if-missing", "", "With --txs, read transactions saved in this directory and save the ones downloaded")
+
+	rootCmd.AddCommand(addressCmd, blockCmd)
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the getraw command.
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+// genesisTxID is the txid of the genesis coinbase, used as a source outpoint.
+const genesisTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// fakeClient serves raw transactions through the bulk endpoint, and block
+// pages of txids.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	raw     map[string]string
+	err     error
+	pages   map[int][]string
+	pageErr error
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (f *fakeClient) GetBlockPages(_ context.Context, _ string, page int) (whatsonchain.BlockPagesInfo, error) {
+	if f.pageErr != nil {
+		return nil, f.pageErr
+	}
+	return f.pages[page], nil
+}
+
+func (f *fakeClient) BulkRawTransactionData(_ context.Context, hashes *whatsonchain.TxHashes) (whatsonchain.TxList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, hashes.TxIDs)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var list whatsonchain.TxList
+	// Reverse the order to check that responses are matched by txid
+	for i := len(hashes.TxIDs) - 1; i >= 0; i-- {
+		txid := hashes.TxIDs[i]
+		if raw, ok := f.raw[txid]; ok {
+			list = append(list, &whatsonchain.TxInfo{TxID: txid, Hex: raw})
+		}
+	}
+	return list, nil
+}
+
+// testTxs returns n distinct transactions keyed by txid, and their txids in order.
+func testTxs(t *testing.T, n int) (map[string]string, []string) {
+	t.Helper()
+
+	source, err := chainhash.NewHashFromHex(genesisTxID)
+	require.NoError(t, err)
+	raw := make(map[string]string, n)
+	txids := make([]string, 0, n)
+	for i := range n {
+		data, err := script.NewFromHex(fmt.Sprintf("006a02%04x", i))
+		require.NoError(t, err)
+		tx := transaction.NewTransaction()
+		tx.Inputs = []*transaction.TransactionInput{{SourceTXID: source, UnlockingScript: &script.Script{}, SequenceNumber: 0xffffffff}}
+		tx.Outputs = []*transaction.TransactionOutput{{Satoshis: 0, LockingScript: data}}
+
+		txid := tx.TxID().String()
+		raw[txid] = tx.Hex()
+		txids = append(txids, txid)
+	}
+	return raw, txids
+}
+
+func TestExportTransactions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes every transaction in batches", func(t *testing.T) {
+		t.Parallel()
+
+		raw, txids := testTxs(t, 45)
+		client := &fakeClient{raw: raw}
+		dir := t.TempDir()
+
+		var mu sync.Mutex
+		done := 0
+		exported, failures := exportTransactions(context.Background(), client, txids, dir, 2, func(n int) {
+			mu.Lock()
+			done += n
+			mu.Unlock()
+		})
+		require.Empty(t, failures)
+		assert.Equal(t, 45, exported)
+		assert.Equal(t, 45, done)
+		assert.Len(t, client.batches, 3)
+		for _, batch := range client.batches {
+			assert.LessOrEqual(t, len(batch), whatsonchain.MaxTransactionsRaw)
+		}
+
+		for _, txid := range txids {
+			data, err := os.ReadFile(txPath(dir, txid))
+			require.NoError(t, err)
+			assert.Equal(t, raw[txid]+"\n", string(data))
+		}
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 45, "no temporary files are left behind")
+	})
+
+	t.Run("reports missing and mismatched transactions", func(t *testing.T) {
+		t.Parallel()
+
+		raw, txids := testTxs(t, 3)
+		raw[txids[1]] = raw[txids[2]]
+		delete(raw, txids[2])
+		dir := t.TempDir()
+
+		exported, failures := exportTransactions(context.Background(), &fakeClient{raw: raw}, txids, dir, 1, nil)
+		assert.Equal(t, 1, exported)
+		require.Len(t, failures, 2)
+		require.ErrorContains(t, failures[txids[1]], "response hashes to "+txids[2])
+		require.ErrorContains(t, failures[txids[2]], "missing from response")
+		assert.NoFileExists(t, txPath(dir, txids[1]))
+		assert.FileExists(t, txPath(dir, txids[0]))
+	})
+
+	t.Run("reports every txid of a failed batch", func(t *testing.T) {
+		t.Parallel()
+
+		_, txids := testTxs(t, 2)
+		exported, failures := exportTransactions(context.Background(), &fakeClient{err: errors.New("rate limited")}, txids, t.TempDir(), 1, nil)
+		assert.Zero(t, exported)
+		require.Len(t, failures, 2)
+		require.ErrorContains(t, failures[txids[0]], "rate limited")
+	})
+}
+
+func TestMissingTransactions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, writeFile(txPath(dir, "aa"), []byte("00\n")))
+
+	history := []*chain.HistoryTx{
+		{TxHash: "aa", Height: 100},
+		{TxHash: "bb", Height: 101},
+		{TxHash: "cc"},
+		{TxHash: "bb"},
+	}
+	missing, err := missingTransactions(history, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bb", "cc"}, missing)
+}
+
+func TestStreamBlock(t *testing.T) {
+	t.Parallel()
+
+	// The first page holds 25 txids and two more pages hold the rest
+	newBlock := func(t *testing.T) (*fakeClient, *whatsonchain.BlockInfo, []string) {
+		raw, txids := testTxs(t, 70)
+		info := &whatsonchain.BlockInfo{Hash: "blockhash", Tx: txids[:25], TxCount: 70}
+		info.Pages.URI = []string{"/block/hash/blockhash/page/1", "/block/hash/blockhash/page/2"}
+		client := &fakeClient{raw: raw, pages: map[int][]string{1: txids[25:50], 2: txids[50:]}}
+		return client, info, txids
+	}
+
+	t.Run("writes every transaction in block order", func(t *testing.T) {
+		t.Parallel()
+
+		client, info, txids := newBlock(t)
+		var out bytes.Buffer
+		progressed := 0
+		written, err := streamBlock(context.Background(), client, info, 3, "", &out, func(n int) { progressed += n })
+		require.NoError(t, err)
+		assert.Equal(t, 70, written)
+		assert.Equal(t, 70, progressed)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 70)
+		for i, txid := range txids {
+			assert.Equal(t, client.raw[txid], lines[i], "line %d", i)
+		}
+		for _, batch := range client.batches {
+			assert.LessOrEqual(t, len(batch), whatsonchain.MaxTransactionsRaw)
+		}
+	})
+
+	t.Run("stops at a missing transaction", func(t *testing.T) {
+		t.Parallel()
+
+		client, info, txids := newBlock(t)
+		delete(client.raw, txids[30])
+		var out bytes.Buffer
+		written, err := streamBlock(context.Background(), client, info, 2, "", &out, nil)
+		require.ErrorContains(t, err, txids[30]+": missing from response")
+		assert.Equal(t, 25, written, "batches before the failing one are written")
+	})
+
+	t.Run("reads cached transactions and saves the rest", func(t *testing.T) {
+		t.Parallel()
+
+		client, info, txids := newBlock(t)
+		dir := t.TempDir()
+		for _, txid := range txids[:20] {
+			require.NoError(t, writeFile(txPath(dir, txid), []byte(client.raw[txid]+"\n")))
+		}
+		// A copy that hashes to another txid is downloaded again
+		require.NoError(t, writeFile(txPath(dir, txids[3]), []byte(client.raw[txids[4]]+"\n")))
+
+		var out bytes.Buffer
+		written, err := streamBlock(context.Background(), client, info, 2, dir, &out, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 70, written)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 70)
+		var downloaded []string
+		for _, batch := range client.batches {
+			downloaded = append(downloaded, batch...)
+		}
+		assert.Len(t, downloaded, 51)
+		assert.Contains(t, downloaded, txids[3])
+		assert.NotContains(t, downloaded, txids[0])
+		for i, txid := range txids {
+			assert.Equal(t, client.raw[txid], lines[i], "line %d", i)
+			data, err := os.ReadFile(txPath(dir, txid))
+			require.NoError(t, err)
+			assert.Equal(t, client.raw[txid]+"\n", string(data))
+		}
+	})
+
+	t.Run("stops at a page failure", func(t *testing.T) {
+		t.Parallel()
+
+		client, info, _ := newBlock(t)
+		client.pageErr = errors.New("rate limited")
+		var out bytes.Buffer
+		written, err := streamBlock(context.Background(), client, info, 2, "", &out, nil)
+		require.ErrorContains(t, err, "fetching block page 1: rate limited")
+		assert.Equal(t, 25, written)
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/httpmock"
+)
+
+// Transactions in the recorded WhatsOnChain responses: a parent paying 10000
+// satoshis to private key 1's address, and a child spending it.
+const (
+	parentTxID = "a95690c085929d23b42cd437b20d2fd24a67b1aa613133acddd2f8277cece44a"
+	childTxID  = "d45a84e34a6cf93e9b5b7d1ef1ab91ea08a7ff8daa9fab5b7bdb4796a563b7ed"
+	childHex   = "01000000014ae4ec7c27f8d2ddac333161aab1674ad22f0db237d42cb4239d9285c09056a9000000006b483045022100a61d9fe2c01335b0326463dce18b1867a489320047888c29d09d8f35cf05c5dc022014c310c49a43da5f9ff86a87b8c734988c7ae2fdbb472db5e2bc8d76cee1dcc241210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798ffffffff01ac260000000000001976a914751e76e8199196d454941c45d1b3a323f1433bd688ac00000000"
+)
+
+// The integration tests run the command against recorded WhatsOnChain
+// responses in testdata; see internal/httpmock to re-record them.
+
+func TestIntegrationRaw(t *testing.T) {
+	httpmock.Install(t, "raw")
+
+	out, err := httpmock.Execute(t, rootCmd, childTxID)
+	require.NoError(t, err)
+	assert.Equal(t, childHex+"\n", out)
+}
+
+func TestIntegrationJSON(t *testing.T) {
+	httpmock.Install(t, "raw")
+
+	out, err := httpmock.Execute(t, rootCmd, "--txid", childTxID, "--json")
+	require.NoError(t, err)
+	var decoded struct {
+		TxID string `json:"txid"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, childTxID, decoded.TxID)
+}
+
+func TestIntegrationEF(t *testing.T) {
+	httpmock.Install(t, "ef")
+
+	out, err := httpmock.Execute(t, rootCmd, childTxID, "--format", "ef")
+	require.NoError(t, err)
+	tx, err := transaction.NewTransactionFromHex(strings.TrimSpace(out))
+	require.NoError(t, err)
+	assert.Equal(t, childTxID, tx.TxID().String())
+	assert.Equal(t, parentTxID, tx.Inputs[0].SourceTXID.String())
+	assert.Equal(t, uint64(10000), *tx.Inputs[0].SourceTxSatoshis())
+}
+
+func TestIntegrationIfMissing(t *testing.T) {
+	httpmock.Install(t, "raw")
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	out, err := httpmock.Execute(t, rootCmd, childTxID, "--if-missing", dir)
+	require.NoError(t, err)
+	assert.Equal(t, childHex+"\n", out)
+	data, err := os.ReadFile(filepath.Join(dir, childTxID+".hex"))
+	require.NoError(t, err)
+	assert.Equal(t, childHex+"\n", string(data))
+
+	// The cassette holds one response, so the second run must use the cache
+	out, err = httpmock.Execute(t, rootCmd, childTxID, "--if-missing", dir)
+	require.NoError(t, err)
+	assert.Equal(t, childHex+"\n", out)
+}
+
+func TestIntegrationNotFound(t *testing.T) {
+	httpmock.Install(t, "not_found")
+
+	out, err := httpmock.Execute(t, rootCmd, parentTxID)
+	require.ErrorContains(t, err, "getting raw transaction")
+	assert.Empty(t, out)
+}
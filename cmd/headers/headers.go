@@ -0,0 +1,436 @@
+// Package main implements a local Bitcoin SV block header store.
+//
+// This tool syncs block headers into a local file, verifying chain continuity
+// and proof of work for every header before it is stored, and answers queries
+// by height or hash. The verified store is the trust anchor for `spv --local`.
+//
+// Features:
+//   - Sync from WhatsOnChain, a Block Headers Service, or the headers provider in config.yaml
+//   - Start from genesis or from a checkpoint height
+//   - Follows chain reorganizations
+//   - Query headers by height or hash
+//   - Full re-verification of the stored chain
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	headers sync --from 900000         # Sync from a checkpoint using WhatsOnChain
+//	headers sync -s https://bhs.example # Sync from a Block Headers Service
+//	headers tip                        # Show the local tip
+//	headers get 900123                 # Show the header at a height
+//	headers get <block hash> -j        # Show a header by hash as JSON
+//	headers get 900123 --raw           # Print the raw 80-byte header hex
+//	headers verify                     # Re-verify the stored chain
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/config"
+	"github.com/mrz1836/go-template/internal/headers"
+)
+
+// sourceWOC selects WhatsOnChain as the sync source.
+const sourceWOC = "woc"
+
+// Default headers requested per source call
+const (
+	defaultWOCBatch      = 100
+	defaultBHSBatch      = 2000
+	defaultProviderBatch = 100
+)
+
+// Command-line flags
+var (
+	testnet    bool   // Use testnet instead of mainnet
+	storePath  string // Header store file (default: ~/.bsv-cmd-line-utils/headers/<network>.bin)
+	jsonOutput bool   // Output in JSON format
+	source     string // Sync source: "woc" or a Block Headers Service URL
+	apiKey     string // Block Headers Service API key
+	from       uint32 // Checkpoint height for an empty store
+	batch      int    // Headers requested per source call
+	rawOutput  bool   // Print only the raw header hex
+	verifyFrom uint32 // Height to start re-verification from
+)
+
+// headerInfo is the display form of a stored header.
+type headerInfo struct {
+	Height     uint32 `json:"height"`
+	Hash       string `json:"hash"`
+	PrevHash   string `json:"previousHash"`
+	MerkleRoot string `json:"merkleRoot"`
+	Version    int32  `json:"version"`
+	Time       uint32 `json:"time"`
+	Bits       string `json:"bits"`
+	Nonce      uint32 `json:"nonce"`
+	Hex        string `json:"hex"`
+}
+
+// rootCmd is the main cobra command for the headers tool.
+var rootCmd = &cobra.Command{
+	Use:   "headers",
+	Short: "Sync and query a local, verified block header store",
+	Long: `A command line tool that syncs block headers into a local file and answers
+queries by height or hash. Every header is checked for chain continuity and proof
+of work before it is stored, so the store can be used as the trust anchor for spv.`,
+}
+
+// syncCmd extends the local store to the source's tip.
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync headers from WhatsOnChain or a Block Headers Service",
+	Long: `Sync headers from WhatsOnChain or a Block Headers Service.
+
+The source defaults to the headers-mainnet/headers-testnet URL in config.yaml,
+falling back to the headers provider (providers.headers, WhatsOnChain by
+default). WhatsOnChain serves one header per request, so use --from to start an
+empty store at a recent checkpoint height.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runSync()
+	},
+}
+
+// tipCmd shows the local tip.
+var tipCmd = &cobra.Command{
+	Use:   "tip",
+	Short: "Show the local chain tip",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		store, err := openStore(true)
+		if err != nil {
+			return err
+		}
+		_, height, err := store.Tip()
+		if err != nil {
+			return err
+		}
+		return printHeader(store, height)
+	},
+}
+
+// getCmd shows a header by height or hash.
+var getCmd = &cobra.Command{
+	Use:   "get <height|hash>",
+	Short: "Show a stored header by height or hash",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		store, err := openStore(true)
+		if err != nil {
+			return err
+		}
+		height, err := resolveHeight(store, args[0])
+		if err != nil {
+			return err
+		}
+		return printHeader(store, height)
+	},
+}
+
+// verifyCmd re-verifies the stored chain.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-verify chain continuity and proof of work of the stored headers",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runVerify()
+	},
+}
+
+// openStore opens the header store for the selected network.
+// Query commands pass requireHeaders to fail early with a hint on an empty store.
+func openStore(requireHeaders bool) (*headers.Store, error) {
+	path := storePath
+	if path == "" {
+		var err error
+		if path, err = headers.DefaultPath(testnet); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := headers.Open(path, testnet)
+	if err != nil {
+		return nil, err
+	}
+	if requireHeaders && store.Len() == 0 {
+		return nil, fmt.Errorf("%w (%s): run 'headers sync' first", headers.ErrEmpty, path)
+	}
+	return store, nil
+}
+
+// newSource builds the sync source from flags, falling back to config.yaml and
+// then its headers provider.
+func newSource(ctx context.Context) (headers.Source, int, error) {
+	url, key := source, apiKey
+	if url == "" {
+		if cfg, err := config.Load(); err == nil {
+			hc := cfg.GetHeadersConfig(testnet)
+			url = hc.URL
+			if key == "" {
+				key = hc.APIKey
+			}
+		}
+	}
+
+	if url != "" && url != sourceWOC {
+		log.Printf("Source: Block Headers Service (%s)\n", url)
+		return headers.NewBHSSource(url, key), defaultBHSBatch, nil
+	}
+
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, isWOC := provider.HeaderSource.(*chain.WOC); !isWOC && url != sourceWOC {
+		log.Printf("Source: headers provider in config.yaml\n")
+		return &providerSource{headers: provider.HeaderSource}, defaultProviderBatch, nil
+	}
+	log.Printf("Source: WhatsOnChain (%s)\n", provider.WOC.Client.Network())
+	return headers.NewWOCSource(provider.WOC.Client), defaultWOCBatch, nil
+}
+
+// providerSource syncs headers from a chain provider, such as an SV Node,
+// fetching each header by height.
+type providerSource struct {
+	headers chain.HeaderSource
+}
+
+// HeaderAt returns the best-chain header at height.
+func (p *providerSource) HeaderAt(ctx context.Context, height uint32) (*block.Header, error) {
+	return p.headers.HeaderByHeight(ctx, height)
+}
+
+// Next returns up to limit headers following prev, once prev is confirmed to
+// still be the provider's header at prevHeight.
+func (p *providerSource) Next(ctx context.Context, prev chainhash.Hash, prevHeight uint32, limit int) ([]*block.Header, error) {
+	tip, err := p.headers.TipHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tip < prevHeight {
+		return nil, fmt.Errorf("provider tip %d is below the local tip %d", tip, prevHeight)
+	}
+
+	current, err := p.headers.HeaderByHeight(ctx, prevHeight)
+	if err != nil {
+		return nil, err
+	}
+	if current.Hash() != prev {
+		return nil, headers.ErrNotOnChain
+	}
+
+	var result []*block.Header
+	for height := prevHeight + 1; height <= tip && len(result) < limit; height++ {
+		header, err := p.headers.HeaderByHeight(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, header)
+	}
+	return result, nil
+}
+
+// runSync syncs the store and reports the new tip.
+// Interrupting the sync keeps every header stored so far.
+func runSync() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	store, err := openStore(false)
+	if err != nil {
+		return err
+	}
+
+	src, defaultBatch, err := newSource(ctx)
+	if err != nil {
+		return err
+	}
+	return syncStore(ctx, store, src, defaultBatch)
+}
+
+// syncStore extends store from src, requesting defaultBatch headers per call
+// unless --batch is set, and reports the new tip.
+func syncStore(ctx context.Context, store *headers.Store, src headers.Source, defaultBatch int) error {
+	size := batch
+	if size <= 0 {
+		size = defaultBatch
+	}
+
+	added, err := headers.Sync(ctx, store, src, headers.SyncOptions{
+		From:  from,
+		Batch: size,
+		Progress: func(height uint32) {
+			log.Printf("Synced to height %d\n", height)
+		},
+	})
+	if err != nil {
+		if added > 0 {
+			log.Printf("Stored %d new header(s) before the error\n", added)
+		}
+		return fmt.Errorf("sync failed: %w", err)
+	}
+
+	_, height, err := store.Tip()
+	if err != nil {
+		return err
+	}
+	log.Printf("Added %d header(s) to %s\n", added, store.Path())
+	return printHeader(store, height)
+}
+
+// runVerify re-verifies the stored chain and reports its total work.
+func runVerify() error {
+	store, err := openStore(true)
+	if err != nil {
+		return err
+	}
+
+	tipHeight, err := store.TipHeight()
+	if err != nil {
+		return err
+	}
+
+	start := max(verifyFrom, store.Base())
+	err = store.Verify(start, func(height uint32) {
+		log.Printf("Verified to height %d\n", height)
+	})
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	work, err := store.ChainWork(start, tipHeight)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return encodeJSON(map[string]any{
+			"valid":     true,
+			"from":      start,
+			"to":        tipHeight,
+			"headers":   tipHeight - start + 1,
+			"chainWork": work.Text(16),
+		})
+	}
+
+	fmt.Printf("✓ %d headers verified (%d to %d)\n", tipHeight-start+1, start, tipHeight)
+	fmt.Printf("  Work: 0x%s\n", work.Text(16))
+	return nil
+}
+
+// resolveHeight interprets a query as a height or a block hash.
+func resolveHeight(store *headers.Store, query string) (uint32, error) {
+	if len(query) == chainhash.MaxHashStringSize && cli.IsValidHex(query) {
+		hash, err := chainhash.NewHashFromHex(query)
+		if err != nil {
+			return 0, err
+		}
+		height, ok := store.HeightOf(*hash)
+		if !ok {
+			return 0, fmt.Errorf("block %s is not in the local store", query)
+		}
+		return height, nil
+	}
+
+	height, err := strconv.ParseUint(query, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid height or block hash: %s", query)
+	}
+	if !store.Has(uint32(height)) {
+		return 0, fmt.Errorf("height %d is not in the local store", height)
+	}
+	return uint32(height), nil
+}
+
+// newHeaderInfo builds the display form of a header.
+func newHeaderInfo(header *block.Header, height uint32) *headerInfo {
+	hash := header.Hash()
+	return &headerInfo{
+		Height:     height,
+		Hash:       hash.String(),
+		PrevHash:   header.PrevHash.String(),
+		MerkleRoot: header.MerkleRoot.String(),
+		Version:    header.Version,
+		Time:       header.Timestamp,
+		Bits:       fmt.Sprintf("%08x", header.Bits),
+		Nonce:      header.Nonce,
+		Hex:        header.Hex(),
+	}
+}
+
+// printHeader prints the stored header at height.
+func printHeader(store *headers.Store, height uint32) error {
+	header, err := store.Header(height)
+	if err != nil {
+		return err
+	}
+	info := newHeaderInfo(header, height)
+
+	switch {
+	case rawOutput:
+		fmt.Println(info.Hex)
+	case jsonOutput:
+		return encodeJSON(info)
+	default:
+		fmt.Printf("Height:      %d\n", info.Height)
+		fmt.Printf("Hash:        %s\n", info.Hash)
+		fmt.Printf("Previous:    %s\n", info.PrevHash)
+		fmt.Printf("Merkle Root: %s\n", info.MerkleRoot)
+		fmt.Printf("Time:        %d\n", info.Time)
+		fmt.Printf("Bits:        %s\n", info.Bits)
+		fmt.Printf("Nonce:       %d\n", info.Nonce)
+		fmt.Printf("Version:     %d\n", info.Version)
+	}
+	return nil
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra commands and flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentFlags().StringVarP(&storePath, "file", "f", "", "Header store file (default: ~/.bsv-cmd-line-utils/headers/<network>.bin)")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	syncCmd.Flags().StringVarP(&source, "source", "s", "", `Sync source: "woc" or a Block Headers Service URL (default: config.yaml, then woc)`)
+	syncCmd.Flags().StringVarP(&apiKey, "api-key", "k", "", "Block Headers Service API key")
+	syncCmd.Flags().Uint32Var(&from, "from", 0, "Checkpoint height to start an empty store at (0 = genesis)")
+	syncCmd.Flags().IntVarP(&batch, "batch", "b", 0, "Headers requested per source call (default: 100 for woc, 2000 for BHS)")
+
+	tipCmd.Flags().BoolVar(&rawOutput, "raw", false, "Print only the raw 80-byte header hex")
+	getCmd.Flags().BoolVar(&rawOutput, "raw", false, "Print only the raw 80-byte header hex")
+
+	verifyCmd.Flags().Uint32Var(&verifyFrom, "from", 0, "Height to start verification from (default: store base)")
+
+	rootCmd.AddCommand(syncCmd, tipCmd, getCmd, verifyCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the headers command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/headers"
+)
+
+// testHeaderHex are the first three mainnet block headers.
+var testHeaderHex = []string{
+	"0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c",
+	"010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299",
+	"010000004860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000d5fdcc541e25de1c7a5addedf24858b8bb665c9f36ef744ee42c316022c90f9bb0bc6649ffff001d08d2bd61",
+}
+
+// testHeaders decodes testHeaderHex.
+func testHeaders(t *testing.T) []*block.Header {
+	t.Helper()
+
+	var result []*block.Header
+	for _, raw := range testHeaderHex {
+		header, err := block.NewHeaderFromHex(raw)
+		require.NoError(t, err)
+		result = append(result, header)
+	}
+	return result
+}
+
+// fakeSource serves a fixed best chain and counts Next calls.
+type fakeSource struct {
+	chain []*block.Header
+	calls int
+}
+
+func (f *fakeSource) HeaderAt(_ context.Context, height uint32) (*block.Header, error) {
+	if int(height) >= len(f.chain) {
+		return nil, fmt.Errorf("no header at %d", height)
+	}
+	return f.chain[height], nil
+}
+
+func (f *fakeSource) Next(_ context.Context, _ chainhash.Hash, prevHeight uint32, limit int) ([]*block.Header, error) {
+	f.calls++
+	start := int(prevHeight) + 1
+	end := min(start+limit, len(f.chain))
+	if start >= end {
+		return nil, nil
+	}
+	return f.chain[start:end], nil
+}
+
+// useStore points --file at a fresh store path and resets the query flags.
+func useStore(t *testing.T) {
+	t.Helper()
+
+	storePath = filepath.Join(t.TempDir(), "mainnet.bin")
+	t.Cleanup(func() {
+		storePath, from, batch, jsonOutput, rawOutput, verifyFrom = "", 0, 0, false, false, 0
+	})
+}
+
+// syncedStore syncs the test chain into a fresh store.
+func syncedStore(t *testing.T) *headers.Store {
+	t.Helper()
+
+	useStore(t)
+	store, err := openStore(false)
+	require.NoError(t, err)
+	require.NoError(t, syncStore(context.Background(), store, &fakeSource{chain: testHeaders(t)}, 100))
+	return store
+}
+
+func TestSyncStore(t *testing.T) {
+	hdrs := testHeaders(t)
+
+	t.Run("syncs to the source tip", func(t *testing.T) {
+		store := syncedStore(t)
+		assert.Equal(t, 3, store.Len())
+		tip, height, err := store.Tip()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), height)
+		assert.Equal(t, hdrs[2].Hash(), tip.Hash())
+	})
+
+	t.Run("batch flag overrides the source default", func(t *testing.T) {
+		useStore(t)
+		store, err := openStore(false)
+		require.NoError(t, err)
+
+		batch = 1
+		src := &fakeSource{chain: hdrs}
+		require.NoError(t, syncStore(context.Background(), store, src, 100))
+		assert.Equal(t, 3, store.Len())
+		assert.Equal(t, 3, src.calls)
+	})
+
+	t.Run("resyncing an up-to-date store adds nothing", func(t *testing.T) {
+		store := syncedStore(t)
+		require.NoError(t, syncStore(context.Background(), store, &fakeSource{chain: hdrs}, 100))
+		assert.Equal(t, 3, store.Len())
+	})
+
+	t.Run("source failure", func(t *testing.T) {
+		useStore(t)
+		store, err := openStore(false)
+		require.NoError(t, err)
+
+		from = 5
+		err = syncStore(context.Background(), store, &fakeSource{chain: hdrs}, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sync failed")
+		assert.Equal(t, 0, store.Len())
+	})
+}
+
+func TestOpenStoreEmpty(t *testing.T) {
+	useStore(t)
+
+	_, err := openStore(true)
+	require.ErrorIs(t, err, headers.ErrEmpty)
+	assert.Contains(t, err.Error(), "run 'headers sync' first")
+
+	for _, cmd := range []struct {
+		name string
+		run  func() error
+	}{
+		{"tip", func() error { return tipCmd.RunE(tipCmd, nil) }},
+		{"get", func() error { return getCmd.RunE(getCmd, []string{"0"}) }},
+		{"verify", func() error { return verifyCmd.RunE(verifyCmd, nil) }},
+	} {
+		t.Run(cmd.name, func(t *testing.T) {
+			require.ErrorIs(t, cmd.run(), headers.ErrEmpty)
+		})
+	}
+}
+
+func TestResolveHeight(t *testing.T) {
+	store := syncedStore(t)
+	hdrs := testHeaders(t)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    uint32
+		wantErr string
+	}{
+		{"height", "1", 1, ""},
+		{"genesis hash", headers.MainnetGenesis, 0, ""},
+		{"block hash", hdrs[2].Hash().String(), 2, ""},
+		{"height above tip", "3", 0, "height 3 is not in the local store"},
+		{"unknown hash", "000000000000000000000000000000000000000000000000000000000000abcd", 0, "not in the local store"},
+		{"invalid", "tip", 0, "invalid height or block hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			height, err := resolveHeight(store, tt.query)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, height)
+		})
+	}
+}
+
+func TestNewHeaderInfo(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+	info := newHeaderInfo(hdrs[1], 1)
+
+	assert.Equal(t, uint32(1), info.Height)
+	assert.Equal(t, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", info.Hash)
+	assert.Equal(t, headers.MainnetGenesis, info.PrevHash)
+	assert.Equal(t, "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098", info.MerkleRoot)
+	assert.Equal(t, int32(1), info.Version)
+	assert.Equal(t, uint32(1231469665), info.Time)
+	assert.Equal(t, "1d00ffff", info.Bits)
+	assert.Equal(t, uint32(2573394689), info.Nonce)
+	assert.Equal(t, testHeaderHex[1], info.Hex)
+}
+
+func TestQueryCommands(t *testing.T) {
+	syncedStore(t)
+	hdrs := testHeaders(t)
+
+	require.NoError(t, tipCmd.RunE(tipCmd, nil))
+	require.NoError(t, getCmd.RunE(getCmd, []string{hdrs[1].Hash().String()}))
+	require.NoError(t, verifyCmd.RunE(verifyCmd, nil))
+
+	jsonOutput = true
+	require.NoError(t, getCmd.RunE(getCmd, []string{"2"}))
+	require.NoError(t, verifyCmd.RunE(verifyCmd, nil))
+
+	err := getCmd.RunE(getCmd, []string{"7"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "height 7 is not in the local store")
+
+	verifyFrom = 1
+	require.NoError(t, verifyCmd.RunE(verifyCmd, nil))
+}
+
+func TestProviderSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	hdrs := testHeaders(t)
+	mock := chain.NewMock()
+	for height, header := range hdrs {
+		mock.Headers[uint32(height)] = header
+	}
+	src := &providerSource{headers: mock}
+
+	t.Run("header at height", func(t *testing.T) {
+		t.Parallel()
+		header, err := src.HeaderAt(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, hdrs[1].Hash(), header.Hash())
+	})
+
+	t.Run("next headers up to the limit", func(t *testing.T) {
+		t.Parallel()
+		next, err := src.Next(ctx, hdrs[0].Hash(), 0, 1)
+		require.NoError(t, err)
+		require.Len(t, next, 1)
+		assert.Equal(t, hdrs[1].Hash(), next[0].Hash())
+
+		next, err = src.Next(ctx, hdrs[0].Hash(), 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, next, 2)
+	})
+
+	t.Run("at the tip", func(t *testing.T) {
+		t.Parallel()
+		next, err := src.Next(ctx, hdrs[2].Hash(), 2, 10)
+		require.NoError(t, err)
+		assert.Empty(t, next)
+	})
+
+	t.Run("prev not on chain", func(t *testing.T) {
+		t.Parallel()
+		_, err := src.Next(ctx, hdrs[2].Hash(), 1, 10)
+		require.ErrorIs(t, err, headers.ErrNotOnChain)
+	})
+
+	t.Run("provider behind the local tip", func(t *testing.T) {
+		t.Parallel()
+		_, err := src.Next(ctx, chainhash.Hash{}, 5, 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "below the local tip 5")
+	})
+
+	t.Run("syncs a store", func(t *testing.T) {
+		t.Parallel()
+		store, err := headers.Open(filepath.Join(t.TempDir(), "mainnet.bin"), false)
+		require.NoError(t, err)
+		added, err := headers.Sync(ctx, store, src, headers.SyncOptions{Batch: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, added)
+	})
+}
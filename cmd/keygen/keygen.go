@@ -7,45 +7,69 @@
 // Features:
 //   - Mainnet/testnet support via --testnet flag
 //   - Compressed/uncompressed key format via --uncompressed flag
+//   - Both forms in one record via --both, for legacy uncompressed imports
 //   - Generate multiple key pairs via --count flag
 //   - JSON output format via --json flag
 //   - Cryptographically secure key generation using the BSV SDK
+//   - Bulk WIF-to-address derivation via `keygen derive`
+//   - Key rotation manifest via --manifest, with role labels and optionally
+//     without private keys (--no-secrets)
+//   - Keys written straight into an encrypted wallet via --into-wallet, labeled
+//     and marked as generated by keygen, instead of printed
 //
 // Usage:
 //
 //	keygen                          # Generate single mainnet key pair
 //	keygen -t                       # Generate testnet key pair
 //	keygen -u                       # Generate with uncompressed public key
+//	keygen -b                       # Compressed and uncompressed forms together
 //	keygen -c 5                     # Generate 5 key pairs
 //	keygen -j                       # Output in JSON format
 //	keygen -t -c 3 -j               # Generate 3 testnet keys in JSON
+//	keygen -c 2 --manifest keys.json --label hot --label cold --no-secrets
+//	keygen -c 5 --into-wallet default --label payroll  # Add keys to a wallet
+//	keygen derive -f wifs.txt       # Addresses and pubkeys of existing WIFs
+//	cat wifs.txt | keygen derive -f - -j
 package main
 
 import (
+	"bufio"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
-	"github.com/bsv-blockchain/go-sdk/script"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
 	"github.com/spf13/cobra"
-)
 
-// Network prefixes for WIF encoding
-const (
-	mainnetWIFPrefix = 0x80
-	testnetWIFPrefix = 0xef
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/wallet"
 )
 
 // Command-line flags
 var (
-	testnet      bool // Use testnet instead of mainnet
-	uncompressed bool // Generate uncompressed keys
-	count        int  // Number of key pairs to generate
-	jsonOutput   bool // Output in JSON format
+	testnet      bool     // Use testnet instead of mainnet
+	uncompressed bool     // Generate uncompressed keys
+	both         bool     // Include the uncompressed form alongside the compressed one
+	count        int      // Number of key pairs to generate
+	jsonOutput   bool     // Output in JSON format
+	fromFile     string   // File of WIFs to derive, one per line ("-" for stdin)
+	manifest     string   // Write a key rotation manifest to this file
+	labels       []string // Role labels for the manifest or wallet, one for all keys or one per key
+	noSecrets    bool     // Leave private keys and WIFs out of the manifest
+	intoWallet   string   // Name of the wallet to add the keys to instead of printing them
 )
 
+// derivationRandom records that a key was generated from the system's secure
+// random source rather than derived from a seed.
+const derivationRandom = "random"
+
 // KeyPair holds the generated key information.
 type KeyPair struct {
 	PrivateKey string `json:"privateKey"` // Private key in hex format
@@ -54,6 +78,48 @@ type KeyPair struct {
 	Address    string `json:"address"`    // P2PKH address
 	Network    string `json:"network"`    // Network name (mainnet/testnet)
 	Compressed bool   `json:"compressed"` // Whether the key is compressed
+
+	Uncompressed *KeyForm `json:"uncompressed,omitempty"` // Uncompressed form, with --both
+}
+
+// KeyForm holds the public key, WIF, and address of one compression form of a key.
+type KeyForm struct {
+	PublicKey string `json:"publicKey"`     // Public key in hex format
+	WIF       string `json:"wif,omitempty"` // Private key in WIF format
+	Address   string `json:"address"`       // P2PKH address
+}
+
+// Manifest is an auditable record of a batch of generated keys.
+type Manifest struct {
+	Created time.Time     `json:"created"` // When the keys were generated
+	Secrets bool          `json:"secrets"` // Whether private keys and WIFs are included
+	Keys    []ManifestKey `json:"keys"`
+}
+
+// ManifestKey is one key in a manifest. The private key and WIFs are empty
+// with --no-secrets.
+type ManifestKey struct {
+	Label        string   `json:"label,omitempty"`        // Intended role, from --label
+	Fingerprint  string   `json:"fingerprint"`            // HASH160 of the public key, as in the address
+	Derivation   string   `json:"derivation"`             // How the key was produced
+	Network      string   `json:"network"`                // Network name (mainnet/testnet)
+	Compressed   bool     `json:"compressed"`             // Whether the key is compressed
+	PublicKey    string   `json:"publicKey"`              // Public key in hex format
+	Address      string   `json:"address"`                // P2PKH address
+	PrivateKey   string   `json:"privateKey,omitempty"`   // Private key in hex format
+	WIF          string   `json:"wif,omitempty"`          // Private key in WIF format
+	Uncompressed *KeyForm `json:"uncompressed,omitempty"` // Uncompressed form, with --both
+}
+
+// DerivedKey holds the public key and address of an existing WIF. The WIF's
+// own network and compression flag decide which address its funds are on.
+type DerivedKey struct {
+	Line       int    `json:"line"`                // Line number in the input
+	PublicKey  string `json:"publicKey,omitempty"` // Public key in hex format
+	Address    string `json:"address,omitempty"`   // P2PKH address
+	Network    string `json:"network,omitempty"`   // Network name (mainnet/testnet)
+	Compressed bool   `json:"compressed"`          // Whether the key is compressed
+	Error      string `json:"error,omitempty"`     // Why the line could not be parsed
 }
 
 // rootCmd is the main cobra command for the keygen tool.
@@ -68,21 +134,91 @@ cryptographically secure random number generation.`,
 	},
 }
 
+// deriveCmd derives addresses for existing WIFs.
+var deriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derive public keys and addresses from existing WIFs",
+	Long: `Reads WIF private keys, one per line, and prints the public key and P2PKH
+address of each without generating new keys. Blank lines and lines starting
+with # are skipped. Results keep the input's line numbers, and WIFs are never
+echoed, so the output can be shared for reconciliation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDerive()
+	},
+}
+
 // run handles the main execution flow.
 func run() error {
 	// Validate count
 	if count < 1 || count > 100 {
 		return fmt.Errorf("count must be between 1 and 100")
 	}
+	if both && uncompressed {
+		return fmt.Errorf("--both cannot be used with --uncompressed")
+	}
+	if manifest == "" && noSecrets {
+		return fmt.Errorf("--no-secrets requires --manifest")
+	}
+	if manifest == "" && intoWallet == "" && len(labels) > 0 {
+		return fmt.Errorf("--label requires --manifest or --into-wallet")
+	}
+	if len(labels) > 1 && len(labels) != count {
+		return fmt.Errorf("--label must be given once, or once per key (%d)", count)
+	}
+	if intoWallet != "" && (uncompressed || both) {
+		return fmt.Errorf("--into-wallet stores compressed keys only; it cannot be used with --uncompressed or --both")
+	}
+
+	// Open the wallet first, so a wrong password or network fails before any
+	// keys are generated
+	var (
+		w              *wallet.Wallet
+		path           string
+		walletPassword []byte
+	)
+	if intoWallet != "" {
+		var err error
+		if w, path, walletPassword, err = openWallet(intoWallet); err != nil {
+			return err
+		}
+	}
 
 	// Generate key pairs
+	bar := cli.NewProgress("Generating", count)
 	keyPairs := make([]KeyPair, 0, count)
 	for i := 0; i < count; i++ {
 		kp, err := generateKeyPair()
 		if err != nil {
+			bar.Finish()
 			return fmt.Errorf("generating key pair: %w", err)
 		}
 		keyPairs = append(keyPairs, kp)
+		bar.Add(1)
+	}
+	bar.Finish()
+
+	now := time.Now().UTC()
+	if manifest != "" {
+		m, err := buildManifest(keyPairs, labels, now, !noSecrets)
+		if err != nil {
+			return err
+		}
+		if err = writeManifest(manifest, m); err != nil {
+			return err
+		}
+	}
+
+	if w != nil {
+		added, err := addToWallet(w, keyPairs, labels, now)
+		if err != nil {
+			return err
+		}
+		if err = wallet.Save(path, w, walletPassword); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Added %d key(s) to wallet %s\n", len(added), path)
+		return outputWalletKeys(added)
 	}
 
 	// Output results
@@ -92,6 +228,123 @@ func run() error {
 	return outputText(keyPairs)
 }
 
+// buildManifest records keyPairs with their labels: labels[0] for every key
+// when there is one, otherwise labels[i] for key i. Private keys and WIFs are
+// kept only when secrets is set.
+func buildManifest(keyPairs []KeyPair, labels []string, created time.Time, secrets bool) (*Manifest, error) {
+	m := &Manifest{Created: created, Secrets: secrets, Keys: make([]ManifestKey, 0, len(keyPairs))}
+	for i, kp := range keyPairs {
+		pub, err := hex.DecodeString(kp.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("decoding public key: %w", err)
+		}
+
+		key := ManifestKey{
+			Fingerprint: hex.EncodeToString(crypto.Hash160(pub)),
+			Derivation:  derivationRandom,
+			Network:     kp.Network,
+			Compressed:  kp.Compressed,
+			PublicKey:   kp.PublicKey,
+			Address:     kp.Address,
+		}
+		key.Label = labelFor(labels, i)
+		if secrets {
+			key.PrivateKey, key.WIF = kp.PrivateKey, kp.WIF
+		}
+		if kp.Uncompressed != nil {
+			form := *kp.Uncompressed
+			if !secrets {
+				form.WIF = ""
+			}
+			key.Uncompressed = &form
+		}
+		m.Keys = append(m.Keys, key)
+	}
+	return m, nil
+}
+
+// labelFor returns the label of key i: labels[0] for every key when there is
+// one, otherwise labels[i].
+func labelFor(labels []string, i int) string {
+	switch {
+	case len(labels) == 1:
+		return labels[0]
+	case i < len(labels):
+		return labels[i]
+	}
+	return ""
+}
+
+// openWallet loads the named wallet with the password from the environment or
+// a prompt, and checks it belongs to the network keys are generated for.
+func openWallet(name string) (*wallet.Wallet, string, []byte, error) {
+	path, err := wallet.DefaultPath(name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if _, err = os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil, fmt.Errorf("wallet %s does not exist: run 'wallet create --name %s' first", path, name)
+	}
+
+	password, err := wallet.ReadPassword("Wallet password: ")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	w, err := wallet.Load(path, password)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if w.Testnet() != testnet {
+		return nil, "", nil, fmt.Errorf("wallet %s is a %snet wallet; --testnet must match it", path, w.Network)
+	}
+	return w, path, password, nil
+}
+
+// addToWallet imports keyPairs into w with their labels, as chosen by
+// labelFor, marking each as generated by keygen at created.
+func addToWallet(w *wallet.Wallet, keyPairs []KeyPair, labels []string, created time.Time) ([]*wallet.Key, error) {
+	added := make([]*wallet.Key, 0, len(keyPairs))
+	for i, kp := range keyPairs {
+		key, err := w.Import(kp.WIF, labelFor(labels, i), created)
+		if err != nil {
+			return nil, fmt.Errorf("adding key to wallet: %w", err)
+		}
+		key.Origin = wallet.OriginKeygen
+		added = append(added, key)
+	}
+	return added, nil
+}
+
+// outputWalletKeys prints the addresses of keys added to a wallet, or with
+// --json their wallet records without the WIFs.
+func outputWalletKeys(added []*wallet.Key) error {
+	if jsonOutput {
+		public := make([]wallet.Key, 0, len(added))
+		for _, k := range added {
+			key := *k
+			key.WIF = ""
+			public = append(public, key)
+		}
+		return outputJSON(public)
+	}
+	for _, k := range added {
+		fmt.Println(k.Address)
+	}
+	return nil
+}
+
+// writeManifest writes m to path as indented JSON, readable only by the owner.
+func writeManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err = os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
+
 // generateKeyPair creates a new BSV key pair.
 func generateKeyPair() (KeyPair, error) {
 	// Generate new private key
@@ -100,75 +353,124 @@ func generateKeyPair() (KeyPair, error) {
 		return KeyPair{}, fmt.Errorf("creating private key: %w", err)
 	}
 
-	// Get public key
-	pubKey := privKey.PubKey()
-
-	// Determine WIF prefix based on network
-	wifPrefix := byte(mainnetWIFPrefix)
-	if testnet {
-		wifPrefix = testnetWIFPrefix
+	// Encode for the network, without the compression flag for uncompressed keys
+	w := &keys.WIF{Key: privKey, Testnet: testnet, Compressed: !uncompressed}
+	address, err := w.Address()
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("creating address: %w", err)
 	}
 
-	// Get WIF with appropriate prefix
-	wif := privKey.WifPrefix(wifPrefix)
+	kp := KeyPair{
+		PrivateKey: privKey.Hex(),
+		PublicKey:  w.PublicKeyHex(),
+		WIF:        keys.EncodeWIF(privKey, w.Testnet, w.Compressed),
+		Address:    address.AddressString,
+		Network:    w.Network(),
+		Compressed: w.Compressed,
+	}
 
-	// Handle uncompressed WIF (remove compression flag byte before checksum)
-	if uncompressed {
-		wif, err = generateUncompressedWIF(privKey.Serialize(), wifPrefix)
+	if both {
+		legacy, err := keys.Address(privKey.PubKey(), testnet, false)
 		if err != nil {
-			return KeyPair{}, fmt.Errorf("generating uncompressed WIF: %w", err)
+			return KeyPair{}, fmt.Errorf("creating uncompressed address: %w", err)
+		}
+		kp.Uncompressed = &KeyForm{
+			PublicKey: keys.PublicKeyHex(privKey.PubKey(), false),
+			WIF:       keys.EncodeWIF(privKey, testnet, false),
+			Address:   legacy.AddressString,
 		}
 	}
+	return kp, nil
+}
 
-	// Get public key hex (compressed or uncompressed)
-	var pubKeyHex string
-	if uncompressed {
-		pubKeyHex = hex.EncodeToString(pubKey.Uncompressed())
-	} else {
-		pubKeyHex = hex.EncodeToString(pubKey.Compressed())
+// runDerive derives the keys listed in --from-file and prints them. Every
+// line is reported; unparsable ones fail the command after the others print.
+func runDerive() error {
+	var r io.Reader = os.Stdin
+	if fromFile != "-" {
+		f, err := os.Open(fromFile)
+		if err != nil {
+			return fmt.Errorf("opening WIF file: %w", err)
+		}
+		defer f.Close()
+		r = f
 	}
 
-	// Generate address
-	mainnet := !testnet
-	address, err := script.NewAddressFromPublicKeyWithCompression(pubKey, mainnet, !uncompressed)
+	derived, err := deriveKeys(r)
 	if err != nil {
-		return KeyPair{}, fmt.Errorf("creating address: %w", err)
+		return err
 	}
 
-	// Determine network name
-	network := "mainnet"
-	if testnet {
-		network = "testnet"
+	failed := 0
+	for _, d := range derived {
+		if d.Error != "" {
+			failed++
+		}
 	}
 
-	return KeyPair{
-		PrivateKey: privKey.Hex(),
-		PublicKey:  pubKeyHex,
-		WIF:        wif,
-		Address:    address.AddressString,
-		Network:    network,
-		Compressed: !uncompressed,
-	}, nil
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(derived); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range derived {
+			if d.Error != "" {
+				fmt.Fprintf(os.Stderr, "line %d: %s\n", d.Line, d.Error)
+				continue
+			}
+			fmt.Printf("%s\t%s\n", d.Address, d.PublicKey)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d WIFs could not be parsed", failed, len(derived))
+	}
+	return nil
 }
 
-// generateUncompressedWIF creates a WIF string for an uncompressed key.
-// Uncompressed WIF does not include the 0x01 compression flag byte.
-func generateUncompressedWIF(privKeyBytes []byte, prefix byte) (string, error) {
-	// WIF format: prefix (1 byte) + private key (32 bytes) + checksum (4 bytes)
-	// Note: No compression flag for uncompressed keys
-	payload := make([]byte, 1+len(privKeyBytes))
-	payload[0] = prefix
-	copy(payload[1:], privKeyBytes)
+// deriveKey derives the public key and address of one WIF.
+func deriveKey(line int, wif string) DerivedKey {
+	d := DerivedKey{Line: line}
+	w, err := keys.ParseWIF(wif)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	address, err := w.Address()
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	d.PublicKey, d.Address, d.Network, d.Compressed = w.PublicKeyHex(), address.AddressString, w.Network(), w.Compressed
+	return d
+}
+
+// deriveKeys derives the public key and address of each WIF in r, one per
+// line, skipping blank lines and # comments.
+func deriveKeys(r io.Reader) ([]DerivedKey, error) {
+	var derived []DerivedKey
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
 
-	// Add checksum
-	return script.Base58EncodeMissingChecksum(payload), nil
+		derived = append(derived, deriveKey(line, text))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading WIFs: %w", err)
+	}
+	return derived, nil
 }
 
-// outputJSON prints key pairs in JSON format.
-func outputJSON(keyPairs []KeyPair) error {
+// outputJSON prints v, the key pairs or wallet keys, in JSON format.
+func outputJSON(v any) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(keyPairs)
+	return encoder.Encode(v)
 }
 
 // outputText prints key pairs in human-readable format.
@@ -185,6 +487,11 @@ func outputText(keyPairs []KeyPair) error {
 		fmt.Printf("WIF: %s\n", kp.WIF)
 		fmt.Printf("Address: %s\n", kp.Address)
 		fmt.Printf("Compressed: %t\n", kp.Compressed)
+		if u := kp.Uncompressed; u != nil {
+			fmt.Printf("Public Key (uncompressed hex): %s\n", u.PublicKey)
+			fmt.Printf("WIF (uncompressed): %s\n", u.WIF)
+			fmt.Printf("Address (uncompressed): %s\n", u.Address)
+		}
 
 		if i < len(keyPairs)-1 {
 			fmt.Println("---")
@@ -197,10 +504,24 @@ func outputText(keyPairs []KeyPair) error {
 
 // init initializes the cobra command flags.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Generate testnet keys (default: mainnet)")
 	rootCmd.Flags().BoolVarP(&uncompressed, "uncompressed", "u", false, "Generate uncompressed keys (default: compressed)")
+	rootCmd.Flags().BoolVarP(&both, "both", "b", false, "Also output the uncompressed public key, WIF, and address of each key")
 	rootCmd.Flags().IntVarP(&count, "count", "c", 1, "Number of key pairs to generate (1-100)")
-	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().StringVar(&manifest, "manifest", "", "Write a key rotation manifest (fingerprints, labels, creation time) to this JSON file")
+	rootCmd.Flags().StringArrayVar(&labels, "label", nil, "Role label for the manifest or wallet: once for every key, or once per key (can repeat)")
+	rootCmd.Flags().BoolVar(&noSecrets, "no-secrets", false, "Leave private keys and WIFs out of the manifest")
+	rootCmd.Flags().StringVar(&intoWallet, "into-wallet", "", "Add the keys to this wallet (password from "+wallet.PasswordEnv+" or a prompt) instead of printing them")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	deriveCmd.Flags().StringVarP(&fromFile, "from-file", "f", "", `File of WIFs, one per line ("-" for stdin)`)
+	_ = deriveCmd.MarkFlagRequired("from-file")
+
+	rootCmd.AddCommand(deriveCmd)
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the keygen command.
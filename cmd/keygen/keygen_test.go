@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/wallet"
+)
+
+func TestDeriveKeys(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"# cold storage",
+		"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn",
+		"",
+		"  91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgwmaKkrx  ",
+		"not-a-wif",
+	}, "\n")
+
+	derived, err := deriveKeys(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, derived, 3)
+
+	assert.Equal(t, DerivedKey{
+		Line:       2,
+		PublicKey:  "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		Address:    "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		Network:    "mainnet",
+		Compressed: true,
+	}, derived[0])
+
+	assert.Equal(t, 4, derived[1].Line)
+	assert.Equal(t, "mtoKs9V381UAhUia3d7Vb9GNak8Qvmcsme", derived[1].Address)
+	assert.Equal(t, "testnet", derived[1].Network)
+	assert.False(t, derived[1].Compressed)
+	assert.Len(t, derived[1].PublicKey, 130)
+
+	assert.Equal(t, 5, derived[2].Line)
+	assert.NotEmpty(t, derived[2].Error)
+	assert.Empty(t, derived[2].Address)
+}
+
+func TestGenerateKeyPair(t *testing.T) {
+	// Note: This test manipulates the global testnet and uncompressed flags
+	// and should not run in parallel with other tests that use them
+	defer func() { testnet, uncompressed = false, false }()
+
+	for _, tt := range []struct{ testnet, uncompressed bool }{{false, false}, {true, true}} {
+		testnet, uncompressed = tt.testnet, tt.uncompressed
+
+		kp, err := generateKeyPair()
+		require.NoError(t, err)
+
+		// The generated WIF derives back to the same key pair
+		derived, err := deriveKeys(strings.NewReader(kp.WIF))
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		assert.Equal(t, kp.Address, derived[0].Address)
+		assert.Equal(t, kp.PublicKey, derived[0].PublicKey)
+		assert.Equal(t, kp.Network, derived[0].Network)
+		assert.Equal(t, !tt.uncompressed, derived[0].Compressed)
+		assert.Nil(t, kp.Uncompressed)
+	}
+}
+
+func TestGenerateKeyPairBoth(t *testing.T) {
+	// Note: This test manipulates the global testnet and both flags
+	// and should not run in parallel with other tests that use them
+	defer func() { testnet, both = false, false }()
+
+	for _, net := range []bool{false, true} {
+		testnet, both = net, true
+
+		kp, err := generateKeyPair()
+		require.NoError(t, err)
+		assert.True(t, kp.Compressed)
+		require.NotNil(t, kp.Uncompressed)
+		assert.NotEqual(t, kp.Address, kp.Uncompressed.Address)
+
+		// Both WIFs are the same key, each deriving to its own form
+		derived, err := deriveKeys(strings.NewReader(kp.WIF + "\n" + kp.Uncompressed.WIF))
+		require.NoError(t, err)
+		require.Len(t, derived, 2)
+		assert.Equal(t, kp.Address, derived[0].Address)
+		assert.True(t, derived[0].Compressed)
+		assert.Equal(t, kp.Uncompressed.Address, derived[1].Address)
+		assert.Equal(t, kp.Uncompressed.PublicKey, derived[1].PublicKey)
+		assert.False(t, derived[1].Compressed)
+		assert.Equal(t, kp.Network, derived[1].Network)
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	t.Parallel()
+
+	keyPairs := []KeyPair{
+		{
+			PrivateKey: "0000000000000000000000000000000000000000000000000000000000000001",
+			PublicKey:  "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			WIF:        "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn",
+			Address:    "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+			Network:    "mainnet",
+			Compressed: true,
+			Uncompressed: &KeyForm{
+				PublicKey: "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+				WIF:       "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf",
+				Address:   "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm",
+			},
+		},
+		{
+			PrivateKey: "0000000000000000000000000000000000000000000000000000000000000002",
+			PublicKey:  "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+			WIF:        "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU74NMTptX4",
+			Address:    "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP",
+			Network:    "mainnet",
+			Compressed: true,
+		},
+	}
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("with secrets and a label per key", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := buildManifest(keyPairs, []string{"hot", "cold"}, created, true)
+		require.NoError(t, err)
+		assert.True(t, m.Secrets)
+		assert.Equal(t, created, m.Created)
+		require.Len(t, m.Keys, 2)
+
+		// The fingerprint is the address's public key hash
+		assert.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", m.Keys[0].Fingerprint)
+		assert.Equal(t, "hot", m.Keys[0].Label)
+		assert.Equal(t, "cold", m.Keys[1].Label)
+		assert.Equal(t, derivationRandom, m.Keys[0].Derivation)
+		assert.Equal(t, keyPairs[0].WIF, m.Keys[0].WIF)
+		assert.Equal(t, keyPairs[0].PrivateKey, m.Keys[0].PrivateKey)
+		require.NotNil(t, m.Keys[0].Uncompressed)
+		assert.Equal(t, keyPairs[0].Uncompressed.WIF, m.Keys[0].Uncompressed.WIF)
+	})
+
+	t.Run("without secrets and one label for all", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := buildManifest(keyPairs, []string{"treasury"}, created, false)
+		require.NoError(t, err)
+		assert.False(t, m.Secrets)
+
+		data, err := json.Marshal(m)
+		require.NoError(t, err)
+		for _, kp := range keyPairs {
+			assert.NotContains(t, string(data), kp.PrivateKey)
+			assert.NotContains(t, string(data), kp.WIF)
+		}
+		assert.NotContains(t, string(data), keyPairs[0].Uncompressed.WIF)
+		assert.Equal(t, keyPairs[0].Uncompressed.Address, m.Keys[0].Uncompressed.Address)
+		assert.Equal(t, "treasury", m.Keys[1].Label)
+
+		// The key pairs themselves keep their secrets
+		assert.NotEmpty(t, keyPairs[0].Uncompressed.WIF)
+	})
+
+	t.Run("writes an owner-only file", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := buildManifest(keyPairs, nil, created, false)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, writeManifest(path, m))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		var read Manifest
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &read))
+		assert.Equal(t, m.Keys, read.Keys)
+		assert.Empty(t, read.Keys[0].Label)
+	})
+}
+
+func TestAddToWallet(t *testing.T) {
+	t.Parallel()
+
+	keyPairs := []KeyPair{
+		{WIF: "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{WIF: "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU74NMTptX4", Address: "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"},
+	}
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("labels and marks each key", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := wallet.New(false)
+		require.NoError(t, err)
+		added, err := addToWallet(w, keyPairs, []string{"payroll"}, created)
+		require.NoError(t, err)
+		require.Len(t, added, 2)
+		for i, key := range added {
+			assert.Equal(t, keyPairs[i].Address, key.Address)
+			assert.Equal(t, "payroll", key.Label)
+			assert.Equal(t, wallet.OriginKeygen, key.Origin)
+			assert.Equal(t, created, key.Created)
+			assert.Same(t, key, w.Key(key.Address))
+		}
+	})
+
+	t.Run("refuses a key already in the wallet", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := wallet.New(false)
+		require.NoError(t, err)
+		_, err = w.Import(keyPairs[1].WIF, "", created)
+		require.NoError(t, err)
+		_, err = addToWallet(w, keyPairs, nil, created)
+		require.ErrorContains(t, err, "adding key to wallet: address 1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP is already in the wallet")
+	})
+}
+
+func TestLabelFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, labelFor(nil, 0))
+	assert.Equal(t, "hot", labelFor([]string{"hot"}, 3))
+	assert.Equal(t, "cold", labelFor([]string{"hot", "cold"}, 1))
+}
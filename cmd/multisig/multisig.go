@@ -0,0 +1,560 @@
+// Package main implements an m-of-n multisig coordinator for Bitcoin SV.
+//
+// This tool manages treasury-style spends that need signatures from several
+// keys held on different machines. A spend is captured in a proposal file:
+// the unsigned transaction plus the multisig script it spends. The proposal
+// is passed from signer to signer (or signed in parallel and merged) and is
+// finalized once enough signatures are collected.
+//
+// Features:
+//   - Bare m-of-n locking scripts from up to 16 public keys
+//   - Funding a multisig script from a WIF's UTXOs
+//   - Proposals built from the script's UTXOs via WhatsOnChain
+//   - Offline signing, with signatures verified before finalizing
+//   - Merging proposal copies signed in parallel
+//   - Funding and broadcasting through the providers in config.yaml (ARC by default)
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	multisig create -m 2 <pubkey1> <pubkey2> <pubkey3>             # Build a 2-of-3 script
+//	multisig fund <script> -w <WIF> -s 100000                      # Send funds to the script
+//	multisig propose <script> -a <address> -s 50000 -o spend.json  # Propose a spend
+//	multisig sign spend.json -w <WIF>                              # Add a signature
+//	multisig finalize spend.json --broadcast                       # Assemble and broadcast
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/multisig"
+	"github.com/mrz1836/go-template/internal/scripts"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Command-line flags
+var (
+	testnet    bool   // Use testnet instead of mainnet
+	jsonOutput bool   // Output in JSON format
+	required   int    // Signatures required (m)
+	wif        string // WIF private key
+	sats       uint64 // Amount in satoshis
+	toAddress  string // Destination address
+	feePerKb   uint64 // Fee rate in satoshis per kilobyte
+	output     string // Output file for proposals
+	broadcast  bool   // Broadcast the finalized transaction
+	debug      bool   // Enable debug logging
+)
+
+// scriptInfo describes a multisig locking script.
+type scriptInfo struct {
+	Script     string   `json:"script"`
+	ASM        string   `json:"asm"`
+	ScriptHash string   `json:"scriptHash"` // WhatsOnChain script hash
+	Required   int      `json:"required"`
+	PubKeys    []string `json:"pubKeys"`
+}
+
+// rootCmd is the main cobra command for the multisig tool.
+var rootCmd = &cobra.Command{
+	Use:   "multisig",
+	Short: "Coordinate m-of-n multisig spends",
+	Long: `A command line tool for m-of-n multisig treasuries. It builds multisig locking
+scripts, funds them, proposes spends as files that can be passed between machines,
+collects signatures from each cosigner, and finalizes and broadcasts the result.`,
+}
+
+// createCmd builds a multisig locking script.
+var createCmd = &cobra.Command{
+	Use:   "create <pubkey>...",
+	Short: "Build an m-of-n locking script from public keys",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if required == 0 {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--required is required")
+		}
+		return runCreate(args)
+	},
+}
+
+// fundCmd sends funds to a multisig script.
+var fundCmd = &cobra.Command{
+	Use:   "fund <script>",
+	Short: "Send funds from a WIF's UTXOs to a multisig script",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wif == "" || sats == 0 {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--wif and --sats are required")
+		}
+		return runFund(args[0])
+	},
+}
+
+// proposeCmd builds an unsigned spend of a multisig script.
+var proposeCmd = &cobra.Command{
+	Use:   "propose <script>",
+	Short: "Propose a spend of a multisig script's UTXOs",
+	Long: `Builds an unsigned transaction spending the script's UTXOs to --address and
+writes it as a proposal. Change returns to the multisig script. With --sats 0 every
+UTXO is swept to --address.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toAddress == "" {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--address is required")
+		}
+		return runPropose(args[0])
+	},
+}
+
+// signCmd adds a signature to a proposal.
+var signCmd = &cobra.Command{
+	Use:   "sign <proposal|->",
+	Short: "Sign a proposal with a cosigner key",
+	Long: `Adds the key's signature to every input of the proposal. The proposal file is
+updated in place unless --output is given; a proposal read from stdin is written
+to stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wif == "" {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--wif is required")
+		}
+		return runSign(args[0])
+	},
+}
+
+// finalizeCmd assembles a signed proposal into a transaction.
+var finalizeCmd = &cobra.Command{
+	Use:   "finalize <proposal>...",
+	Short: "Merge signed proposals and assemble the transaction",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFinalize(args)
+	},
+}
+
+// runCreate builds and prints a locking script from hex public keys.
+func runCreate(args []string) error {
+	pubKeys := make([]*ec.PublicKey, 0, len(args))
+	for _, arg := range args {
+		pk, err := ec.PublicKeyFromString(arg)
+		if err != nil {
+			return fmt.Errorf("invalid public key %q: %w", arg, err)
+		}
+		pubKeys = append(pubKeys, pk)
+	}
+
+	lock, err := multisig.LockingScript(required, pubKeys)
+	if err != nil {
+		return err
+	}
+	info, err := describe(lock)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return encodeJSON(info)
+	}
+	printScript(info)
+	return nil
+}
+
+// runFund pays sats from the --wif key's UTXOs to a multisig script.
+func runFund(scriptHex string) error {
+	lock, err := parseScript(scriptHex)
+	if err != nil {
+		return err
+	}
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if key.Testnet != testnet {
+		return fmt.Errorf("the WIF is for %s; --testnet must match it", key.Network())
+	}
+	source, err := key.Address()
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	tx, err := buildFunding(builder, key, source, utxos, lock, sats)
+	if err != nil {
+		return err
+	}
+	return emit(ctx, provider, tx)
+}
+
+// runPropose builds a proposal spending a multisig script's UTXOs.
+func runPropose(scriptHex string) error {
+	lock, err := parseScript(scriptHex)
+	if err != nil {
+		return err
+	}
+	dest, err := script.NewAddressFromString(toAddress)
+	if err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	destScript, err := p2pkh.Lock(dest)
+	if err != nil {
+		return fmt.Errorf("failed to build destination script: %w", err)
+	}
+
+	// Only WhatsOnChain lists UTXOs by script hash, whatever the data provider
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	utxos, err := fetchScriptUTXOs(ctx, provider.WOC.Client, lock)
+	if err != nil {
+		return err
+	}
+
+	// Sweeping sends everything to the destination instead of back to the script
+	var outputs []*transaction.TransactionOutput
+	change := lock
+	if sats > 0 {
+		outputs = []*transaction.TransactionOutput{{Satoshis: sats, LockingScript: destScript}}
+	} else {
+		change = destScript
+	}
+
+	tx, err := multisig.BuildSpend(lock, utxos, outputs, change, feePerKb)
+	if err != nil {
+		return err
+	}
+	p, err := multisig.NewProposal(lock, tx, testnet)
+	if err != nil {
+		return err
+	}
+
+	if err := writeProposal(p, output); err != nil {
+		return err
+	}
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Proposal written to %s (%d input(s), %d satoshis fee)\n", output, len(tx.Inputs), fee(tx))
+	}
+	return nil
+}
+
+// runSign adds the --wif key's signatures to a proposal.
+func runSign(path string) error {
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+
+	p, err := readProposal(path)
+	if err != nil {
+		return err
+	}
+	if key.Testnet != p.Testnet {
+		return fmt.Errorf("the WIF is for %s, but the proposal is for the other network", key.Network())
+	}
+	if err := p.Sign(key.Key); err != nil {
+		return err
+	}
+
+	dest := output
+	if dest == "" && path != "-" {
+		dest = path
+	}
+	if err := writeProposal(p, dest); err != nil {
+		return err
+	}
+
+	m, err := p.Required()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Signed: %d of %d signatures collected\n", len(p.Signers()), m)
+	return nil
+}
+
+// runFinalize merges proposals, assembles the transaction and optionally broadcasts it.
+func runFinalize(paths []string) error {
+	p, err := readProposal(paths[0])
+	if err != nil {
+		return err
+	}
+	for _, path := range paths[1:] {
+		other, err := readProposal(path)
+		if err != nil {
+			return err
+		}
+		if err := p.Merge(other); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	testnet = p.Testnet
+
+	tx, err := p.Finalize()
+	if err != nil {
+		return err
+	}
+	return emit(context.Background(), nil, tx)
+}
+
+// emit prints a signed transaction, or broadcasts it with --broadcast through
+// provider, loaded from config.yaml when nil.
+func emit(ctx context.Context, provider *chain.Provider, tx *transaction.Transaction) error {
+	if !broadcast {
+		if jsonOutput {
+			return encodeJSON(map[string]any{"txid": tx.TxID().String(), "hex": tx.String(), "fee": fee(tx)})
+		}
+		fmt.Println(tx.String())
+		return nil
+	}
+
+	if provider == nil {
+		var err error
+		if provider, err = chain.Load(ctx, testnet); err != nil {
+			return err
+		}
+	}
+	resp, err := provider.Broadcaster.Broadcast(ctx, tx.String())
+	if err != nil {
+		return fmt.Errorf("broadcasting transaction: %w", err)
+	}
+
+	if jsonOutput {
+		return encodeJSON(resp)
+	}
+	fmt.Printf("TxID:   %s\n", resp.TxID)
+	fmt.Printf("Status: %s\n", resp.Status)
+	return nil
+}
+
+// buildFunding builds and signs a transaction paying amount from utxos to lock,
+// with change back to source.
+func buildFunding(builder *txbuilder.Builder, key *keys.WIF, source *script.Address, utxos []*txbuilder.UTXO, lock *script.Script, amount uint64) (*transaction.Transaction, error) {
+	outputs := []*transaction.TransactionOutput{{Satoshis: amount, LockingScript: lock}}
+
+	// SelectUTXOs budgets for a P2PKH payment; add the fee for the larger multisig output
+	target := amount
+	if est, base := builder.EstimateFee(1, outputs), builder.CalculateFee(1, 2); est > base {
+		target += est - base
+	}
+	selected, err := builder.SelectUTXOs(utxos, target)
+	if err != nil {
+		return nil, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	for _, u := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: u, Key: key.Key, Uncompressed: !key.Compressed})
+	}
+
+	tx, err := builder.BuildOutputs(inputs, outputs, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// fetchScriptUTXOs returns the unspent outputs locked by lock.
+func fetchScriptUTXOs(ctx context.Context, client whatsonchain.ClientInterface, lock *script.Script) ([]*txbuilder.UTXO, error) {
+	hash := scripts.ComputeHashes(lock.Bytes()).ScriptHash
+	records, err := client.GetScriptUnspentTransactions(ctx, hash)
+	if err != nil && !errors.Is(err, whatsonchain.ErrScriptNotFound) {
+		return nil, fmt.Errorf("fetching script UTXOs: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no UTXOs found for script hash %s", hash)
+	}
+
+	utxos := make([]*txbuilder.UTXO, 0, len(records))
+	for _, r := range records {
+		utxos = append(utxos, &txbuilder.UTXO{
+			TxHash: r.TxHash,
+			TxPos:  uint32(r.TxPos), //nolint:gosec // output index fits in uint32
+			Value:  uint64(r.Value), //nolint:gosec // satoshi values are non-negative
+			Height: r.Height,
+		})
+	}
+	return utxos, nil
+}
+
+// describe summarizes a multisig locking script.
+func describe(lock *script.Script) (*scriptInfo, error) {
+	m, pubKeys, err := multisig.ParseLockingScript(lock)
+	if err != nil {
+		return nil, err
+	}
+	info := &scriptInfo{
+		Script:     lock.String(),
+		ASM:        lock.ToASM(),
+		ScriptHash: scripts.ComputeHashes(lock.Bytes()).ScriptHash,
+		Required:   m,
+	}
+	for _, pk := range pubKeys {
+		info.PubKeys = append(info.PubKeys, hex.EncodeToString(pk.Compressed()))
+	}
+	return info, nil
+}
+
+// parseScript parses a hex multisig locking script.
+func parseScript(scriptHex string) (*script.Script, error) {
+	lock, err := script.NewFromHex(scriptHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script hex: %w", err)
+	}
+	if _, _, err := multisig.ParseLockingScript(lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// readProposal reads a proposal from a file, or stdin for "-".
+func readProposal(path string) (*multisig.Proposal, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // user-specified proposal file
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading proposal: %w", err)
+	}
+
+	var p multisig.Proposal
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing proposal %s: %w", path, err)
+	}
+	if p.Version != multisig.ProposalVersion {
+		return nil, fmt.Errorf("unsupported proposal version %d", p.Version)
+	}
+	return &p, nil
+}
+
+// writeProposal writes a proposal to path, or stdout when path is empty or "-".
+func writeProposal(p *multisig.Proposal, path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing proposal: %w", err)
+	}
+	return nil
+}
+
+// fee returns the fee of a transaction whose inputs carry their source outputs.
+func fee(tx *transaction.Transaction) uint64 {
+	var in uint64
+	for _, input := range tx.Inputs {
+		if out := input.SourceTxOutput(); out != nil {
+			in += out.Satoshis
+		}
+	}
+	if out := tx.TotalOutputSatoshis(); in > out {
+		return in - out
+	}
+	return 0
+}
+
+// printScript prints a locking script summary in human-readable form.
+func printScript(info *scriptInfo) {
+	fmt.Printf("Type:        %d-of-%d multisig\n", info.Required, len(info.PubKeys))
+	fmt.Printf("Script:      %s\n", info.Script)
+	fmt.Printf("ASM:         %s\n", info.ASM)
+	fmt.Printf("Script hash: %s\n", info.ScriptHash)
+	fmt.Println("Public keys:")
+	for i, pk := range info.PubKeys {
+		fmt.Printf("  %d. %s\n", i+1, pk)
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	createCmd.Flags().IntVarP(&required, "required", "m", 0, "Signatures required to spend (m)")
+
+	fundCmd.Flags().StringVarP(&wif, "wif", "w", "", "Funding WIF private key")
+	fundCmd.Flags().Uint64VarP(&sats, "sats", "s", 0, "Amount in satoshis")
+	fundCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	proposeCmd.Flags().StringVarP(&toAddress, "address", "a", "", "Destination address")
+	proposeCmd.Flags().Uint64VarP(&sats, "sats", "s", 0, "Amount in satoshis (0 sweeps every UTXO)")
+	proposeCmd.Flags().StringVarP(&output, "output", "o", "", "Write the proposal to a file instead of stdout")
+
+	signCmd.Flags().StringVarP(&wif, "wif", "w", "", "Cosigner WIF private key")
+	signCmd.Flags().StringVarP(&output, "output", "o", "", "Write the signed proposal to a file (default: update in place)")
+
+	for _, cmd := range []*cobra.Command{fundCmd, proposeCmd} {
+		cmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	}
+	for _, cmd := range []*cobra.Command{fundCmd, finalizeCmd} {
+		cmd.Flags().BoolVar(&broadcast, "broadcast", false, "Broadcast the transaction via ARC instead of printing it")
+	}
+
+	rootCmd.AddCommand(createCmd, fundCmd, proposeCmd, signCmd, finalizeCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the multisig command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
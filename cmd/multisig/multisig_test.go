@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/multisig"
+	"github.com/mrz1836/go-template/internal/scripts"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// fakeClient serves script UTXOs keyed by script hash.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	unspent map[string]whatsonchain.ScriptList
+}
+
+func (f *fakeClient) GetScriptUnspentTransactions(_ context.Context, scriptHash string) (whatsonchain.ScriptList, error) {
+	return f.unspent[scriptHash], nil
+}
+
+// testLock returns three deterministic keys and their 2-of-3 locking script.
+func testLock(t *testing.T) ([]*ec.PrivateKey, *script.Script) {
+	t.Helper()
+
+	privs := make([]*ec.PrivateKey, 3)
+	pubKeys := make([]*ec.PublicKey, 3)
+	for i := range privs {
+		b := make([]byte, 32)
+		b[31] = byte(i + 1)
+		privs[i], pubKeys[i] = ec.PrivateKeyFromBytes(b)
+	}
+	lock, err := multisig.LockingScript(2, pubKeys)
+	require.NoError(t, err)
+	return privs, lock
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	_, lock := testLock(t)
+	info, err := describe(lock)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, info.Required)
+	require.Len(t, info.PubKeys, 3)
+	assert.Equal(t, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", info.PubKeys[0])
+	assert.Equal(t, lock.String(), info.Script)
+	assert.Equal(t, scripts.ComputeHashes(lock.Bytes()).ScriptHash, info.ScriptHash)
+	assert.Contains(t, info.ASM, "OP_CHECKMULTISIG")
+
+	_, err = parseScript("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+	require.Error(t, err)
+}
+
+func TestFetchScriptUTXOs(t *testing.T) {
+	t.Parallel()
+
+	_, lock := testLock(t)
+	hash := scripts.ComputeHashes(lock.Bytes()).ScriptHash
+	client := &fakeClient{unspent: map[string]whatsonchain.ScriptList{
+		hash: {{TxHash: testTxID, TxPos: 2, Value: 5000, Height: 800000}},
+	}}
+
+	utxos, err := fetchScriptUTXOs(context.Background(), client, lock)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	assert.Equal(t, &txbuilder.UTXO{TxHash: testTxID, TxPos: 2, Value: 5000, Height: 800000}, utxos[0])
+
+	_, other := testLock(t)
+	client.unspent = nil
+	_, err = fetchScriptUTXOs(context.Background(), client, other)
+	require.ErrorContains(t, err, "no UTXOs found")
+}
+
+func TestBuildFunding(t *testing.T) {
+	t.Parallel()
+
+	privs, lock := testLock(t)
+	key := &keys.WIF{Key: privs[0], Compressed: true}
+	source, err := key.Address()
+	require.NoError(t, err)
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 100000}}
+
+	tx, err := buildFunding(builder, key, source, utxos, lock, 25000)
+	require.NoError(t, err)
+	require.Len(t, tx.Outputs, 2)
+	assert.Equal(t, uint64(25000), tx.Outputs[0].Satoshis)
+	assert.True(t, tx.Outputs[0].LockingScript.Equals(lock))
+	assert.Positive(t, fee(tx))
+
+	_, err = buildFunding(builder, key, source, utxos, lock, 100000)
+	require.ErrorContains(t, err, "insufficient funds")
+}
+
+func TestProposalFiles(t *testing.T) {
+	t.Parallel()
+
+	privs, lock := testLock(t)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 20000}}
+	tx, err := multisig.BuildSpend(lock, utxos, []*transaction.TransactionOutput{{Satoshis: 5000, LockingScript: lock}}, lock, 100)
+	require.NoError(t, err)
+	p, err := multisig.NewProposal(lock, tx, true)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "spend.json")
+	require.NoError(t, writeProposal(p, path))
+	require.NoError(t, p.Sign(privs[1]))
+	require.NoError(t, writeProposal(p, path+".signed"))
+
+	// Copies read back from disk merge into a finalizable proposal
+	unsigned, err := readProposal(path)
+	require.NoError(t, err)
+	assert.True(t, unsigned.Testnet)
+	assert.Empty(t, unsigned.Signers())
+	require.NoError(t, unsigned.Sign(privs[2]))
+
+	signed, err := readProposal(path + ".signed")
+	require.NoError(t, err)
+	require.NoError(t, unsigned.Merge(signed))
+
+	final, err := unsigned.Finalize()
+	require.NoError(t, err)
+	require.Len(t, final.Inputs, 1)
+	assert.NotEmpty(t, final.Inputs[0].UnlockingScript.Bytes())
+	assert.Equal(t, uint64(20000-5000)-final.Outputs[1].Satoshis, fee(final))
+
+	_, err = readProposal(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,266 @@
+// Package main implements a one-step OP_RETURN data transaction builder for Bitcoin SV.
+//
+// This tool puts data in a single OP_FALSE OP_RETURN output, funds it from a
+// WIF with change back to the WIF's address, signs it, and prints the raw hex
+// or broadcasts it: the task that otherwise takes carve plus hand-built script
+// work.
+//
+// Features:
+//   - Each argument becomes its own push, so protocol prefixes and fields stay separate
+//   - Text, hex (--hex), or stdin ("-") data
+//   - Funding from a compressed or uncompressed WIF with change back to its address
+//   - Raw hex on stdout for piping into broadcast, or --broadcast in the same step
+//   - UTXOs and broadcasting through the providers in config.yaml
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	opreturn -w <WIF> "hello world"                  # Print the signed transaction hex
+//	opreturn -w <WIF> "hello world" --broadcast      # Build and broadcast in one step
+//	opreturn -w <WIF> <prefix> "hello" text/plain    # One push per argument
+//	opreturn -w <WIF> --hex 01020304 cafe            # Hex pushes
+//	cat note.txt | opreturn -w <WIF> -               # Data from stdin
+//	opreturn -w <WIF> "hello" | broadcast -m         # Pipe into broadcast
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Command-line flags
+var (
+	wif        string // WIF private key funding the transaction
+	hexData    bool   // Arguments are hex instead of text
+	testnet    bool   // Use testnet instead of mainnet
+	feePerKb   uint64 // Fee rate in satoshis per kilobyte
+	broadcast  bool   // Broadcast the transaction instead of printing it
+	jsonOutput bool   // Output in JSON format
+	debug      bool   // Enable verbose debug logging
+)
+
+// dataTx is a signed data transaction.
+type dataTx struct {
+	TxID      string `json:"txid"`
+	Hex       string `json:"hex,omitempty"` // Left out once broadcast
+	Fee       uint64 `json:"fee"`
+	DataBytes int    `json:"dataBytes"` // Total bytes pushed, without push opcodes
+	Status    string `json:"status,omitempty"`
+}
+
+// rootCmd is the main cobra command for the opreturn tool.
+var rootCmd = &cobra.Command{
+	Use:   "opreturn <data>...",
+	Short: "Build, fund, and sign an OP_RETURN data transaction in one step",
+	Long: `A command line tool that puts its arguments in a single OP_FALSE OP_RETURN
+output, one push per argument, funds it from a WIF with change back to the
+WIF's address, and prints the signed transaction hex or broadcasts it.
+Use - as an argument to push stdin.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || wif == "" {
+			cmd.SetOut(os.Stderr)
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("data and --wif are required")
+		}
+		return run(args)
+	},
+}
+
+// run builds the data transaction and prints or broadcasts it.
+func run(args []string) error {
+	pushes, err := readPushes(args, hexData, os.Stdin)
+	if err != nil {
+		return err
+	}
+	lock, err := dataScript(pushes)
+	if err != nil {
+		return err
+	}
+
+	w, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	source, err := keys.Address(w.Key.PubKey(), testnet, w.Compressed)
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider, Uncompressed: !w.Compressed}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	tx, fee, err := buildDataTx(builder, w.Key, source, utxos, lock)
+	if err != nil {
+		return err
+	}
+	result := &dataTx{TxID: tx.TxID().String(), Hex: tx.String(), Fee: fee, DataBytes: dataBytes(pushes)}
+
+	if !broadcast {
+		if jsonOutput {
+			return encodeJSON(result)
+		}
+		fmt.Println(result.Hex)
+		return nil
+	}
+
+	resp, err := provider.Broadcaster.Broadcast(ctx, result.Hex)
+	if err != nil {
+		return fmt.Errorf("broadcasting transaction: %w", err)
+	}
+	result.Hex, result.Status = "", resp.Status
+	if resp.TxID != "" {
+		result.TxID = resp.TxID
+	}
+
+	if jsonOutput {
+		return encodeJSON(result)
+	}
+	fmt.Printf("TxID:   %s\n", result.TxID)
+	fmt.Printf("Fee:    %d satoshis\n", result.Fee)
+	fmt.Printf("Data:   %d bytes in %d push(es)\n", result.DataBytes, len(pushes))
+	fmt.Printf("Status: %s\n", result.Status)
+	return nil
+}
+
+// readPushes turns each argument into one push: its text, or its bytes with
+// asHex. An argument of "-" reads stdin in its place, at most once.
+func readPushes(args []string, asHex bool, stdin io.Reader) ([][]byte, error) {
+	pushes := make([][]byte, 0, len(args))
+	readStdin := false
+	for i, arg := range args {
+		data := []byte(arg)
+		if arg == "-" {
+			if readStdin {
+				return nil, fmt.Errorf("stdin (-) can only be pushed once")
+			}
+			readStdin = true
+			var err error
+			if data, err = io.ReadAll(stdin); err != nil {
+				return nil, fmt.Errorf("reading stdin: %w", err)
+			}
+		}
+
+		if asHex {
+			cleaned := strings.Join(strings.Fields(string(data)), "")
+			b, err := hex.DecodeString(cleaned)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d is not valid hex: %w", i+1, err)
+			}
+			data = b
+		}
+		pushes = append(pushes, data)
+	}
+	return pushes, nil
+}
+
+// dataScript builds the OP_FALSE OP_RETURN script pushing each of pushes.
+func dataScript(pushes [][]byte) (*script.Script, error) {
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.OpFALSE, script.OpRETURN); err != nil {
+		return nil, err
+	}
+	for i, data := range pushes {
+		if err := s.AppendPushData(data); err != nil {
+			return nil, fmt.Errorf("failed to push argument %d: %w", i+1, err)
+		}
+	}
+	return s, nil
+}
+
+// dataBytes returns the total size of pushes.
+func dataBytes(pushes [][]byte) int {
+	n := 0
+	for _, data := range pushes {
+		n += len(data)
+	}
+	return n
+}
+
+// buildDataTx builds and signs a transaction with the zero-value data output
+// lock, funded from utxos with change back to source. It returns the
+// transaction and its fee.
+func buildDataTx(builder *txbuilder.Builder, key *ec.PrivateKey, source *script.Address, utxos []*txbuilder.UTXO, lock *script.Script) (*transaction.Transaction, uint64, error) {
+	outputs := []*transaction.TransactionOutput{{Satoshis: 0, LockingScript: lock}}
+
+	// The data output's fee is the amount selection must cover beyond the inputs
+	selected, err := builder.SelectUTXOs(utxos, builder.EstimateFee(1, outputs))
+	if err != nil {
+		return nil, 0, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	var totalIn uint64
+	for _, u := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: u, Key: key, Uncompressed: builder.Uncompressed})
+		totalIn += u.Value
+	}
+
+	tx, err := builder.BuildOutputs(inputs, outputs, source)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, totalIn - tx.TotalOutputSatoshis(), nil
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key funding the transaction (required)")
+	rootCmd.Flags().BoolVarP(&hexData, "hex", "x", false, "Decode each argument as hex instead of pushing it as text")
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	rootCmd.Flags().BoolVar(&broadcast, "broadcast", false, "Broadcast the transaction instead of printing its hex")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the opreturn command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+func TestReadPushes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pushes each argument as text", func(t *testing.T) {
+		t.Parallel()
+		pushes, err := readPushes([]string{"hello", "text/plain"}, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("hello"), []byte("text/plain")}, pushes)
+	})
+
+	t.Run("decodes hex and stdin", func(t *testing.T) {
+		t.Parallel()
+		pushes, err := readPushes([]string{"cafe", "-"}, true, strings.NewReader("01 02\n03\n"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{{0xca, 0xfe}, {0x01, 0x02, 0x03}}, pushes)
+	})
+
+	t.Run("rejects bad hex and a second stdin", func(t *testing.T) {
+		t.Parallel()
+		_, err := readPushes([]string{"cafe", "zz"}, true, nil)
+		require.ErrorContains(t, err, "argument 2 is not valid hex")
+
+		_, err = readPushes([]string{"-", "-"}, false, strings.NewReader("x"))
+		require.ErrorContains(t, err, "only be pushed once")
+	})
+}
+
+func TestDataScript(t *testing.T) {
+	t.Parallel()
+
+	s, err := dataScript([][]byte{[]byte("hello"), {0xca, 0xfe}})
+	require.NoError(t, err)
+	assert.Equal(t, "006a0568656c6c6f02cafe", s.String())
+	assert.True(t, s.IsData())
+}
+
+func TestBuildDataTx(t *testing.T) {
+	t.Parallel()
+
+	lock, err := dataScript([][]byte{[]byte("hello world")})
+	require.NoError(t, err)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}
+
+	for _, compressed := range []bool{true, false} {
+		w, err := keys.ParseWIF(keys.EncodeWIF(testKey(t), false, compressed))
+		require.NoError(t, err)
+		source, err := keys.Address(w.Key.PubKey(), false, compressed)
+		require.NoError(t, err)
+
+		builder := &txbuilder.Builder{FeePerKb: 100, Uncompressed: !compressed}
+		tx, fee, err := buildDataTx(builder, w.Key, source, utxos, lock)
+		require.NoError(t, err)
+
+		require.Len(t, tx.Outputs, 2, "data and change")
+		assert.Equal(t, lock.String(), tx.Outputs[0].LockingScript.String())
+		assert.Zero(t, tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(10000)-fee, tx.Outputs[1].Satoshis)
+		change, err := tx.Outputs[1].LockingScript.Address()
+		require.NoError(t, err)
+		assert.Equal(t, source.AddressString, change.AddressString)
+		assert.GreaterOrEqual(t, fee, uint64(txbuilder.MinFee))
+	}
+
+	_, _, err = buildDataTx(&txbuilder.Builder{FeePerKb: 100}, testKey(t), nil, []*txbuilder.UTXO{{TxHash: testTxID, Value: 10}}, lock)
+	require.ErrorContains(t, err, "insufficient funds")
+}
+
+// testKey returns a deterministic private key.
+func testKey(t *testing.T) *ec.PrivateKey {
+	t.Helper()
+	key, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	return key
+}
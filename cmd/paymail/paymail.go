@@ -0,0 +1,504 @@
+// Package main implements a paymail client for Bitcoin SV.
+//
+// This tool resolves paymail handles (alias@domain.tld) and pays them. It
+// discovers the handle's host and capabilities, looks up the identity key,
+// requests payment destinations, and delivers payments either peer-to-peer to
+// the receiver's host or by broadcasting through the broadcaster in config.yaml.
+//
+// Features:
+//   - Capability discovery with SRV host lookup
+//   - PKI lookup of a handle's identity public key
+//   - Basic and P2P payment destination requests
+//   - P2P transaction submission with signed sender metadata
+//   - UTXOs and basic payment broadcasts through the providers in config.yaml
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	paymail capabilities alice@example.com             # List the host's capabilities
+//	paymail pki alice@example.com                      # Show the identity key
+//	paymail resolve alice@example.com -s 1000          # Show where a payment would go
+//	paymail pay alice@example.com -w <WIF> -s 1000     # Pay 1000 satoshis
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/paymail"
+	"github.com/mrz1836/go-template/internal/scripts"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Payment methods
+const (
+	methodP2P   = "p2p"   // Transaction delivered to the receiver's host
+	methodBasic = "basic" // Transaction broadcast by the sender
+)
+
+// Command-line flags
+var (
+	testnet     bool   // Use testnet instead of mainnet
+	jsonOutput  bool   // Output in JSON format
+	wif         string // Sender WIF private key
+	sats        uint64 // Amount in satoshis
+	sender      string // Sender paymail handle
+	senderName  string // Sender display name
+	note        string // Payment note or purpose
+	feePerKb    uint64 // Fee rate in satoshis per kilobyte
+	noBroadcast bool   // Print the signed transaction instead of sending it
+	debug       bool   // Enable debug logging
+)
+
+// destination is where a payment to a handle must go.
+type destination struct {
+	Handle    string               `json:"handle"`
+	Method    string               `json:"method"`
+	Reference string               `json:"reference,omitempty"` // P2P reference to submit the transaction with
+	Outputs   []*destinationOutput `json:"outputs"`
+}
+
+// destinationOutput is one output of a destination.
+type destinationOutput struct {
+	Script   string `json:"script"`
+	Satoshis uint64 `json:"satoshis"`
+	Address  string `json:"address,omitempty"`
+}
+
+// paymentResult is the outcome of a payment.
+type paymentResult struct {
+	Handle   string `json:"handle"`
+	Method   string `json:"method"`
+	TxID     string `json:"txid"`
+	Satoshis uint64 `json:"satoshis"`
+	Fee      uint64 `json:"fee"`
+	Status   string `json:"status,omitempty"` // Broadcast status for basic payments
+	Note     string `json:"note,omitempty"`   // Receiver's note for P2P payments
+}
+
+// rootCmd is the main cobra command for the paymail tool.
+var rootCmd = &cobra.Command{
+	Use:   "paymail",
+	Short: "Resolve and pay paymail handles",
+	Long: `A command line tool that discovers paymail capabilities, looks up identity keys,
+resolves payment destinations and pays paymail handles. Payments are delivered P2P
+to the receiver's host when supported, otherwise broadcast through the broadcaster
+in config.yaml.`,
+}
+
+// capabilitiesCmd lists the capabilities of a paymail host.
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities <handle|domain>",
+	Short: "List the capabilities of a paymail host",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCapabilities(args[0])
+	},
+}
+
+// pkiCmd looks up the identity key of a handle.
+var pkiCmd = &cobra.Command{
+	Use:   "pki <handle>",
+	Short: "Look up the identity public key of a handle",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPKI(args[0])
+	},
+}
+
+// resolveCmd requests a payment destination without paying.
+var resolveCmd = &cobra.Command{
+	Use:   "resolve <handle>",
+	Short: "Request a payment destination for a handle",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runResolve(args[0])
+	},
+}
+
+// payCmd pays a handle.
+var payCmd = &cobra.Command{
+	Use:   "pay <handle>",
+	Short: "Pay a handle from a WIF's UTXOs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wif == "" || sats == 0 {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--wif and --sats are required")
+		}
+		return runPay(args[0])
+	},
+}
+
+// runCapabilities discovers and prints the capabilities of a handle's or domain's host.
+func runCapabilities(target string) error {
+	domain := target
+	if _, d, ok := strings.Cut(target, "@"); ok {
+		domain = d
+	}
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	ctx := context.Background()
+	client := paymail.NewClient()
+	host := client.Host(ctx, domain)
+	caps, err := client.Capabilities(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("discovering capabilities: %w", err)
+	}
+
+	if jsonOutput {
+		return encodeJSON(struct {
+			Domain string `json:"domain"`
+			Host   string `json:"host"`
+			*paymail.Capabilities
+		}{domain, host, caps})
+	}
+
+	fmt.Printf("Domain:   %s\n", domain)
+	fmt.Printf("Host:     %s\n", host)
+	fmt.Printf("bsvalias: %s\n\n", caps.BSVAlias)
+
+	ids := make([]string, 0, len(caps.Capabilities))
+	for id := range caps.Capabilities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Printf("%-24s %-14s %v\n", paymail.CapabilityName(id), id, caps.Capabilities[id])
+	}
+	return nil
+}
+
+// runPKI looks up and prints the identity key of a handle.
+func runPKI(handle string) error {
+	ctx := context.Background()
+	client, caps, h, err := discover(ctx, handle)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.PKI(ctx, caps, h)
+	if err != nil {
+		return fmt.Errorf("looking up identity key: %w", err)
+	}
+
+	if jsonOutput {
+		return encodeJSON(resp)
+	}
+	fmt.Printf("Handle:     %s\n", h)
+	fmt.Printf("Public key: %s\n", resp.PubKey)
+	return nil
+}
+
+// runResolve requests and prints a payment destination for a handle.
+func runResolve(handle string) error {
+	var key *ec.PrivateKey
+	if wif != "" {
+		w, err := keys.ParseWIF(wif)
+		if err != nil {
+			return fmt.Errorf("failed to parse WIF: %w", err)
+		}
+		key = w.Key
+	}
+
+	ctx := context.Background()
+	client, caps, h, err := discover(ctx, handle)
+	if err != nil {
+		return err
+	}
+
+	dest, err := resolve(ctx, client, caps, h, key, sats)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return encodeJSON(dest)
+	}
+	printDestination(dest)
+	return nil
+}
+
+// runPay pays sats to a handle from the UTXOs of the --wif key.
+func runPay(handle string) error {
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if key.Testnet != testnet {
+		return fmt.Errorf("the WIF is for %s; --testnet must match it", key.Network())
+	}
+	source, err := key.Address()
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	ctx := context.Background()
+	client, caps, h, err := discover(ctx, handle)
+	if err != nil {
+		return err
+	}
+
+	dest, err := resolve(ctx, client, caps, h, key.Key, sats)
+	if err != nil {
+		return err
+	}
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	tx, fee, err := buildPayment(builder, key, source, utxos, dest)
+	if err != nil {
+		return err
+	}
+
+	if noBroadcast {
+		fmt.Println(tx.String())
+		return nil
+	}
+
+	result := &paymentResult{Handle: h.String(), Method: dest.Method, TxID: tx.TxID().String(), Satoshis: total(dest), Fee: fee}
+	if dest.Method == methodP2P {
+		p2p := &paymail.P2PTransaction{
+			Hex:       tx.String(),
+			Reference: dest.Reference,
+			Metadata:  &paymail.P2PMetadata{Sender: sender, Note: note},
+		}
+		if err := p2p.Sign(key.Key, result.TxID); err != nil {
+			return err
+		}
+		resp, err := client.SendP2PTransaction(ctx, caps, h, p2p)
+		if err != nil {
+			return fmt.Errorf("submitting transaction to %s: %w", h.Domain, err)
+		}
+		result.Note = resp.Note
+	} else {
+		resp, err := provider.Broadcast(ctx, tx.String())
+		if err != nil {
+			return fmt.Errorf("broadcasting transaction: %w", err)
+		}
+		result.Status = resp.Status
+	}
+
+	if jsonOutput {
+		return encodeJSON(result)
+	}
+	fmt.Printf("Paid %d satoshis to %s (%s)\n", result.Satoshis, result.Handle, result.Method)
+	fmt.Printf("TxID: %s\n", result.TxID)
+	fmt.Printf("Fee:  %d satoshis\n", result.Fee)
+	if result.Status != "" {
+		fmt.Printf("Status: %s\n", result.Status)
+	}
+	if result.Note != "" {
+		fmt.Printf("Note: %s\n", result.Note)
+	}
+	return nil
+}
+
+// discover parses a handle and fetches its host's capabilities.
+func discover(ctx context.Context, handle string) (*paymail.Client, *paymail.Capabilities, paymail.Handle, error) {
+	h, err := paymail.ParseHandle(handle)
+	if err != nil {
+		return nil, nil, paymail.Handle{}, err
+	}
+
+	client := paymail.NewClient()
+	caps, err := client.Capabilities(ctx, h.Domain)
+	if err != nil {
+		return nil, nil, paymail.Handle{}, fmt.Errorf("discovering capabilities: %w", err)
+	}
+	return client, caps, h, nil
+}
+
+// resolve requests a payment destination for h, preferring P2P when the host
+// supports both P2P destinations and P2P transaction submission. Basic requests
+// are signed with key when given; hosts requiring sender validation need one.
+func resolve(ctx context.Context, client *paymail.Client, caps *paymail.Capabilities, h paymail.Handle, key *ec.PrivateKey, amount uint64) (*destination, error) {
+	dest := &destination{Handle: h.String()}
+
+	if amount > 0 && caps.Has(paymail.CapabilityP2PPaymentDestination) && caps.Has(paymail.CapabilityP2PReceiveTransaction) {
+		resp, err := client.P2PPaymentDestination(ctx, caps, h, amount)
+		if err != nil {
+			return nil, fmt.Errorf("requesting P2P payment destination: %w", err)
+		}
+		dest.Method = methodP2P
+		dest.Reference = resp.Reference
+		for _, out := range resp.Outputs {
+			dest.Outputs = append(dest.Outputs, newDestinationOutput(out.Script, out.Satoshis))
+		}
+		return dest, nil
+	}
+
+	if sender == "" {
+		return nil, fmt.Errorf("%s does not support P2P payments; --sender is required for a basic payment request", h.Domain)
+	}
+	req := &paymail.PaymentRequest{
+		SenderName:   senderName,
+		SenderHandle: sender,
+		DT:           time.Now().UTC().Format(time.RFC3339),
+		Amount:       amount,
+		Purpose:      note,
+	}
+	if key != nil {
+		if err := req.Sign(key); err != nil {
+			return nil, err
+		}
+	} else if caps.Has(paymail.CapabilitySenderValidation) {
+		return nil, fmt.Errorf("%s requires signed requests; provide --wif", h.Domain)
+	}
+
+	resp, err := client.PaymentDestination(ctx, caps, h, req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting payment destination: %w", err)
+	}
+	dest.Method = methodBasic
+	dest.Outputs = []*destinationOutput{newDestinationOutput(resp.Output, amount)}
+	return dest, nil
+}
+
+// newDestinationOutput describes an output script, with its address for standard scripts.
+func newDestinationOutput(scriptHex string, satoshis uint64) *destinationOutput {
+	out := &destinationOutput{Script: scriptHex, Satoshis: satoshis}
+	if b, err := hex.DecodeString(scriptHex); err == nil {
+		if info := scripts.Classify(b, !testnet); len(info.Addresses) > 0 {
+			out.Address = info.Addresses[0]
+		}
+	}
+	return out
+}
+
+// buildPayment builds and signs a transaction paying dest from utxos, with change
+// back to source. It returns the transaction and its fee.
+func buildPayment(builder *txbuilder.Builder, key *keys.WIF, source *script.Address, utxos []*txbuilder.UTXO, dest *destination) (*transaction.Transaction, uint64, error) {
+	outputs := make([]*transaction.TransactionOutput, 0, len(dest.Outputs))
+	for i, out := range dest.Outputs {
+		s, err := script.NewFromHex(out.Script)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid script in destination output %d: %w", i, err)
+		}
+		outputs = append(outputs, &transaction.TransactionOutput{Satoshis: out.Satoshis, LockingScript: s})
+	}
+
+	amount := total(dest)
+	if amount == 0 {
+		return nil, 0, fmt.Errorf("destination has no value to pay")
+	}
+
+	// SelectUTXOs budgets for one payment output; add the fee for any larger outputs
+	target := amount
+	if fee, base := builder.EstimateFee(1, outputs), builder.CalculateFee(1, 2); fee > base {
+		target += fee - base
+	}
+	selected, err := builder.SelectUTXOs(utxos, target)
+	if err != nil {
+		return nil, 0, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	var totalIn uint64
+	for _, u := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: u, Key: key.Key, Uncompressed: !key.Compressed})
+		totalIn += u.Value
+	}
+
+	tx, err := builder.BuildOutputs(inputs, outputs, source)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, totalIn - tx.TotalOutputSatoshis(), nil
+}
+
+// total returns the value of all outputs of dest.
+func total(dest *destination) uint64 {
+	var sum uint64
+	for _, out := range dest.Outputs {
+		sum += out.Satoshis
+	}
+	return sum
+}
+
+// printDestination prints a payment destination in human-readable form.
+func printDestination(dest *destination) {
+	fmt.Printf("Handle: %s\n", dest.Handle)
+	fmt.Printf("Method: %s\n", dest.Method)
+	if dest.Reference != "" {
+		fmt.Printf("Reference: %s\n", dest.Reference)
+	}
+	for i, out := range dest.Outputs {
+		fmt.Printf("\nOutput %d:\n", i)
+		if out.Satoshis > 0 {
+			fmt.Printf("  Satoshis: %d\n", out.Satoshis)
+		}
+		if out.Address != "" {
+			fmt.Printf("  Address:  %s\n", out.Address)
+		}
+		fmt.Printf("  Script:   %s\n", out.Script)
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	for _, cmd := range []*cobra.Command{resolveCmd, payCmd} {
+		cmd.Flags().StringVarP(&wif, "wif", "w", "", "Sender WIF private key (signs requests and funds payments)")
+		cmd.Flags().Uint64VarP(&sats, "sats", "s", 0, "Amount in satoshis")
+		cmd.Flags().StringVar(&sender, "sender", "", "Sender paymail handle")
+		cmd.Flags().StringVar(&senderName, "sender-name", "", "Sender display name (basic payments)")
+		cmd.Flags().StringVar(&note, "note", "", "Payment note or purpose")
+	}
+	payCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	payCmd.Flags().BoolVar(&noBroadcast, "no-broadcast", false, "Print the signed transaction hex without sending it")
+	payCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	rootCmd.AddCommand(capabilitiesCmd, pkiCmd, resolveCmd, payCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the paymail command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const (
+	testTxID   = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testScript = "76a914e2a623699e81b291c0327f408fea765d534baa2a88ac"
+)
+
+func TestNewDestinationOutput(t *testing.T) {
+	t.Parallel()
+
+	out := newDestinationOutput(testScript, 1000)
+	assert.Equal(t, uint64(1000), out.Satoshis)
+	assert.Equal(t, "1MfQjr97hKaAvpVPJ4XsaHQDskjYhXxLW3", out.Address)
+
+	out = newDestinationOutput("006a0568656c6c6f", 0)
+	assert.Empty(t, out.Address)
+}
+
+func TestBuildPayment(t *testing.T) {
+	t.Parallel()
+
+	priv, source := chaintest.Source(t)
+	key := &keys.WIF{Key: priv, Compressed: true}
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 100000}}
+
+	t.Run("pays every destination output", func(t *testing.T) {
+		t.Parallel()
+
+		dest := &destination{Method: methodP2P, Outputs: []*destinationOutput{
+			{Script: testScript, Satoshis: 3000},
+			{Script: testScript, Satoshis: 2000},
+		}}
+
+		tx, fee, err := buildPayment(builder, key, source, utxos, dest)
+		require.NoError(t, err)
+
+		require.Len(t, tx.Outputs, 3)
+		assert.Equal(t, uint64(3000), tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(2000), tx.Outputs[1].Satoshis)
+		assert.Equal(t, testScript, tx.Outputs[0].LockingScript.String())
+
+		// Change returns to the source address
+		change := tx.Outputs[2]
+		lock, err := p2pkh.Lock(source)
+		require.NoError(t, err)
+		assert.Equal(t, lock.String(), change.LockingScript.String())
+		assert.Equal(t, uint64(100000), 5000+fee+change.Satoshis)
+		assert.Equal(t, uint64(5000), total(dest))
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		t.Parallel()
+
+		dest := &destination{Outputs: []*destinationOutput{{Script: testScript, Satoshis: 200000}}}
+		_, _, err := buildPayment(builder, key, source, utxos, dest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+	})
+
+	t.Run("invalid script", func(t *testing.T) {
+		t.Parallel()
+
+		dest := &destination{Outputs: []*destinationOutput{{Script: "zz", Satoshis: 1000}}}
+		_, _, err := buildPayment(builder, key, source, utxos, dest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid script")
+	})
+
+	t.Run("nothing to pay", func(t *testing.T) {
+		t.Parallel()
+
+		dest := &destination{Outputs: []*destinationOutput{{Script: testScript}}}
+		_, _, err := buildPayment(builder, key, source, utxos, dest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no value")
+	})
+}
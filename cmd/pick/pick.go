@@ -7,8 +7,11 @@
 //   - Extract complete serialized inputs or outputs
 //   - Extract individual fields (scripts, values, prevtxid, sequence, etc.)
 //   - Extract transaction-level fields (version, locktime, txid)
+//   - Extract coinbase fields (BIP34 height, miner tag, raw script)
+//   - Count outputs matching address, script, or value predicates
 //   - Support for multiple selections in one call
 //   - Flexible input: argument, flag, or stdin
+//   - Stream mode applying the selectors to every transaction on stdin, one hex per line
 //
 // Usage:
 //
@@ -17,31 +20,44 @@
 //	pick <rawtx> --input 0 --input 1            # Get first two inputs
 //	pick <rawtx> --version --locktime           # Get version and locktime
 //	echo <rawtx> | pick --txid                  # Get transaction ID from stdin
+//	pick <coinbase> --coinbase-height --coinbase-tag # Block height and miner tag
+//	pick <rawtx> --count-matching 'value>1000'  # Count and indices of matching outputs
+//	pick <rawtx> --count-matching 'script^=006a' # Count data outputs
 //	getraw <txid> | pick --output 0             # Chain with getraw
+//	getraw block <hash> --txs | pick --stream --prefix-txid --output-value 0  # Every tx in a block
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/bsv-blockchain/go-sdk/script"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
 	"github.com/mrz1836/go-template/internal/cli"
 	"github.com/spf13/cobra"
 )
 
 // Command-line flags
 var (
-	raw string // Raw transaction hex provided via flag
+	raw        string // Raw transaction hex provided via flag
+	stream     bool   // Read one transaction hex per stdin line
+	prefixTxID bool   // Prefix each result line with the transaction's txid
 
 	// Output selectors (can be used multiple times)
-	outputs       []int // Complete serialized outputs
-	outputScripts []int // Output locking scripts only
-	outputValues  []int // Output values only
+	outputs       []int    // Complete serialized outputs
+	outputScripts []int    // Output locking scripts only
+	outputValues  []int    // Output values only
+	countMatching []string // Predicates to count matching outputs by
 
 	// Input selectors (can be used multiple times)
 	inputs         []int // Complete serialized inputs
@@ -54,8 +70,20 @@ var (
 	getVersion  bool // Get version field
 	getLocktime bool // Get locktime field
 	getTxID     bool // Get transaction ID
+
+	// Coinbase selectors
+	getCoinbaseHeight bool // Get BIP34 block height
+	getCoinbaseTag    bool // Get miner tag text
+	getCoinbaseScript bool // Get raw coinbase script
 )
 
+// minTagRun is the shortest run of printable characters in a coinbase script
+// taken as part of the miner tag; shorter runs are usually extranonce bytes.
+const minTagRun = 4
+
+// outputPredicate reports whether an output matches a --count-matching condition.
+type outputPredicate func(*transaction.TransactionOutput) bool
+
 // rootCmd is the main cobra command for the pick tool.
 var rootCmd = &cobra.Command{
 	Use:   "pick [rawtx]",
@@ -64,7 +92,12 @@ var rootCmd = &cobra.Command{
 and outputs them as hex strings for pipeline processing.
 
 Supports selecting outputs, inputs, and transaction-level fields.
-Multiple selections can be combined in one call.`,
+Multiple selections can be combined in one call.
+
+With --stream, reads one transaction hex per stdin line and prints the
+selections for each in turn. A transaction that cannot be parsed or lacks a
+selected index is reported on stderr and skipped; the exit status is non-zero
+if any failed.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return run(cmd, args)
@@ -79,6 +112,25 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no selector specified")
 	}
 
+	predicates, err := parsePredicates(countMatching)
+	if err != nil {
+		return err
+	}
+
+	if stream {
+		if len(args) > 0 || raw != "" {
+			return fmt.Errorf("--stream reads transactions from stdin; it cannot be used with an argument or --raw")
+		}
+		processed, failed, err := streamTransactions(os.Stdin, os.Stdout, os.Stderr, predicates)
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d transactions failed", failed, processed)
+		}
+		return nil
+	}
+
 	// Get transaction hex
 	txHex, err := getTransactionHex(args)
 	if err != nil {
@@ -90,24 +142,94 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no transaction provided")
 	}
 
-	// Validate hex
+	tx, err := parseTransaction(txHex)
+	if err != nil {
+		return err
+	}
+
+	// Extract and output selected elements
+	if !prefixTxID {
+		return extract(os.Stdout, tx, predicates)
+	}
+	var buf bytes.Buffer
+	if err = extract(&buf, tx, predicates); err != nil {
+		return err
+	}
+	return writePrefixed(os.Stdout, tx.TxID().String(), buf.Bytes())
+}
+
+// parseTransaction validates and parses a transaction's hex.
+func parseTransaction(txHex string) (*transaction.Transaction, error) {
 	if !cli.IsValidHex(txHex) {
-		return fmt.Errorf("input is not a valid hex string")
+		return nil, fmt.Errorf("input is not a valid hex string")
 	}
 
-	// Parse the transaction
 	txBytes, err := hex.DecodeString(txHex)
 	if err != nil {
-		return fmt.Errorf("decoding hex: %w", err)
+		return nil, fmt.Errorf("decoding hex: %w", err)
 	}
 
 	tx, err := transaction.NewTransactionFromBytes(txBytes)
 	if err != nil {
-		return fmt.Errorf("parsing transaction: %w", err)
+		return nil, fmt.Errorf("parsing transaction: %w", err)
 	}
+	return tx, nil
+}
 
-	// Extract and output selected elements
-	return extractAndOutput(tx)
+// streamTransactions applies the selectors to each transaction hex line read
+// from r, writing each transaction's results to w as one block once all of its
+// selections succeed. Blank lines are skipped; a line that fails is reported
+// on errW by line number and skipped. It returns the number of transactions
+// read and the number that failed.
+func streamTransactions(r io.Reader, w, errW io.Writer, predicates []outputPredicate) (processed, failed int, err error) {
+	// Lines are read whole, however large the transaction
+	reader := bufio.NewReader(r)
+	var buf bytes.Buffer
+	for lineNum := 1; ; lineNum++ {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return processed, failed, fmt.Errorf("reading stdin: %w", readErr)
+		}
+
+		if txHex := strings.TrimSpace(line); txHex != "" {
+			processed++
+			buf.Reset()
+			tx, err := parseTransaction(txHex)
+			if err == nil {
+				err = extract(&buf, tx, predicates)
+			}
+			switch {
+			case err != nil:
+				failed++
+				fmt.Fprintf(errW, "line %d: %v\n", lineNum, err)
+			case prefixTxID:
+				if err = writePrefixed(w, tx.TxID().String(), buf.Bytes()); err != nil {
+					return processed, failed, err
+				}
+			default:
+				if _, err = w.Write(buf.Bytes()); err != nil {
+					return processed, failed, err
+				}
+			}
+		}
+
+		if readErr != nil {
+			return processed, failed, nil
+		}
+	}
+}
+
+// writePrefixed writes each line of results to w prefixed with txid and a tab.
+func writePrefixed(w io.Writer, txid string, results []byte) error {
+	for _, line := range strings.SplitAfter(string(results), "\n") {
+		if line == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s", txid, line); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // hasAnySelector checks if any selection flag was provided.
@@ -115,6 +237,7 @@ func hasAnySelector() bool {
 	return len(outputs) > 0 ||
 		len(outputScripts) > 0 ||
 		len(outputValues) > 0 ||
+		len(countMatching) > 0 ||
 		len(inputs) > 0 ||
 		len(inputScripts) > 0 ||
 		len(inputPrevTxIDs) > 0 ||
@@ -122,7 +245,10 @@ func hasAnySelector() bool {
 		len(inputSequences) > 0 ||
 		getVersion ||
 		getLocktime ||
-		getTxID
+		getTxID ||
+		getCoinbaseHeight ||
+		getCoinbaseTag ||
+		getCoinbaseScript
 }
 
 // getTransactionHex reads transaction hex from argument, flag, stdin, or file URL.
@@ -180,15 +306,16 @@ func resolveInput(input string) (string, error) {
 	return input, nil
 }
 
-// extractAndOutput extracts selected elements and prints them to stdout.
-func extractAndOutput(tx *transaction.Transaction) error {
+// extract writes the selected elements of tx to w, one per line, with the
+// parsed --count-matching predicates in order.
+func extract(w io.Writer, tx *transaction.Transaction, predicates []outputPredicate) error {
 	// Transaction-level fields
 	if getVersion {
-		fmt.Println(encodeUint32LE(tx.Version))
+		fmt.Fprintln(w, encodeUint32LE(tx.Version))
 	}
 
 	if getTxID {
-		fmt.Println(tx.TxID().String())
+		fmt.Fprintln(w, tx.TxID().String())
 	}
 
 	// Output selections
@@ -197,7 +324,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range outputScripts {
@@ -205,7 +332,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range outputValues {
@@ -213,7 +340,11 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
+	}
+
+	for _, match := range predicates {
+		fmt.Fprintln(w, formatMatches(matchingOutputs(tx, match)))
 	}
 
 	// Input selections
@@ -222,7 +353,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range inputScripts {
@@ -230,7 +361,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range inputPrevTxIDs {
@@ -238,7 +369,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range inputPrevOuts {
@@ -246,7 +377,7 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
 	}
 
 	for _, idx := range inputSequences {
@@ -254,12 +385,33 @@ func extractAndOutput(tx *transaction.Transaction) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(hex)
+		fmt.Fprintln(w, hex)
+	}
+
+	// Coinbase selections
+	if getCoinbaseHeight || getCoinbaseTag || getCoinbaseScript {
+		coinbase, err := getCoinbaseInput(tx)
+		if err != nil {
+			return err
+		}
+		if getCoinbaseHeight {
+			height, err := decodeCoinbaseHeight(coinbase)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, height)
+		}
+		if getCoinbaseTag {
+			fmt.Fprintln(w, minerTag(coinbase))
+		}
+		if getCoinbaseScript {
+			fmt.Fprintln(w, hex.EncodeToString(coinbase))
+		}
 	}
 
 	// Locktime (output last to match transaction order)
 	if getLocktime {
-		fmt.Println(encodeUint32LE(tx.LockTime))
+		fmt.Fprintln(w, encodeUint32LE(tx.LockTime))
 	}
 
 	return nil
@@ -298,6 +450,157 @@ func getOutputValue(tx *transaction.Transaction, idx int) (string, error) {
 	return encodeUint64LE(output.Satoshis), nil
 }
 
+// Output matching functions
+
+// parsePredicates parses each --count-matching expression, in order.
+func parsePredicates(exprs []string) ([]outputPredicate, error) {
+	predicates := make([]outputPredicate, 0, len(exprs))
+	for _, expr := range exprs {
+		match, err := parsePredicate(expr)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, match)
+	}
+	return predicates, nil
+}
+
+// parsePredicate parses a --count-matching expression: comma-separated
+// conditions that must all hold. A condition is address=<addr>,
+// script=<hex>, script^=<hex> (starts with), or value compared with
+// =, >, <, >=, or <= against satoshis.
+func parsePredicate(expr string) (outputPredicate, error) {
+	var conditions []outputPredicate
+	for _, cond := range strings.Split(expr, ",") {
+		cond = strings.TrimSpace(cond)
+		field, op, operand := splitCondition(cond)
+		if field == "" || op == "" {
+			return nil, fmt.Errorf("invalid condition %q: expected <field><op><value>", cond)
+		}
+
+		condition, err := parseCondition(field, op, operand)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition %q: %w", cond, err)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return func(output *transaction.TransactionOutput) bool {
+		for _, condition := range conditions {
+			if !condition(output) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// splitCondition splits a condition into its field name, operator, and operand.
+func splitCondition(cond string) (field, op, operand string) {
+	i := strings.IndexAny(cond, "=<>^")
+	if i <= 0 {
+		return "", "", ""
+	}
+	j := i
+	for j < len(cond) && strings.ContainsRune("=<>^", rune(cond[j])) {
+		j++
+	}
+	return strings.TrimSpace(cond[:i]), cond[i:j], strings.TrimSpace(cond[j:])
+}
+
+// parseCondition builds the predicate for one field, operator, and operand.
+func parseCondition(field, op, operand string) (outputPredicate, error) {
+	switch field {
+	case "address":
+		if op != "=" {
+			return nil, fmt.Errorf("address only supports =")
+		}
+		addr, err := script.NewAddressFromString(operand)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+		lock, err := p2pkh.Lock(addr)
+		if err != nil {
+			return nil, fmt.Errorf("building locking script: %w", err)
+		}
+		return func(output *transaction.TransactionOutput) bool {
+			return output.LockingScript != nil && output.LockingScript.Equals(lock)
+		}, nil
+
+	case "script":
+		prefix := strings.ToLower(operand)
+		if !cli.IsValidHex(prefix) {
+			return nil, fmt.Errorf("script must be hex")
+		}
+		switch op {
+		case "=":
+			return func(output *transaction.TransactionOutput) bool {
+				return lockingScriptHex(output) == prefix
+			}, nil
+		case "^=":
+			return func(output *transaction.TransactionOutput) bool {
+				return strings.HasPrefix(lockingScriptHex(output), prefix)
+			}, nil
+		}
+		return nil, fmt.Errorf("script only supports = and ^=")
+
+	case "value":
+		sats, err := strconv.ParseUint(operand, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value must be satoshis: %w", err)
+		}
+		var compare func(uint64) bool
+		switch op {
+		case "=":
+			compare = func(v uint64) bool { return v == sats }
+		case ">":
+			compare = func(v uint64) bool { return v > sats }
+		case "<":
+			compare = func(v uint64) bool { return v < sats }
+		case ">=":
+			compare = func(v uint64) bool { return v >= sats }
+		case "<=":
+			compare = func(v uint64) bool { return v <= sats }
+		default:
+			return nil, fmt.Errorf("value only supports =, >, <, >=, and <=")
+		}
+		return func(output *transaction.TransactionOutput) bool {
+			return compare(output.Satoshis)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown field %q (expected address, script, or value)", field)
+}
+
+// lockingScriptHex returns an output's locking script as hex, or "" when it has none.
+func lockingScriptHex(output *transaction.TransactionOutput) string {
+	if output.LockingScript == nil {
+		return ""
+	}
+	return output.LockingScript.String()
+}
+
+// matchingOutputs returns the indices of the outputs that match.
+func matchingOutputs(tx *transaction.Transaction, match outputPredicate) []int {
+	var indices []int
+	for i, output := range tx.Outputs {
+		if match(output) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// formatMatches formats the match count followed by the matched indices,
+// separated by spaces, e.g. "2 0 3".
+func formatMatches(indices []int) string {
+	fields := make([]string, 0, len(indices)+1)
+	fields = append(fields, strconv.Itoa(len(indices)))
+	for _, i := range indices {
+		fields = append(fields, strconv.Itoa(i))
+	}
+	return strings.Join(fields, " ")
+}
+
 // Input extraction functions
 
 func getSerializedInput(tx *transaction.Transaction, idx int) (string, error) {
@@ -352,6 +655,99 @@ func getInputSequence(tx *transaction.Transaction, idx int) (string, error) {
 	return encodeUint32LE(input.SequenceNumber), nil
 }
 
+// Coinbase extraction functions
+
+// getCoinbaseInput returns the unlocking script of a coinbase's only input,
+// which carries arbitrary miner data rather than a signature.
+func getCoinbaseInput(tx *transaction.Transaction) ([]byte, error) {
+	if !tx.IsCoinbase() {
+		return nil, fmt.Errorf("transaction is not a coinbase")
+	}
+	if tx.Inputs[0].UnlockingScript == nil {
+		return nil, nil
+	}
+	return *tx.Inputs[0].UnlockingScript, nil
+}
+
+// decodeCoinbaseHeight decodes the block height BIP34 requires as the first
+// push of the coinbase script. Blocks before BIP34 activated (227931 on
+// mainnet) may start with anything, so the result is only meaningful after it.
+func decodeCoinbaseHeight(coinbase []byte) (uint64, error) {
+	if len(coinbase) == 0 {
+		return 0, fmt.Errorf("coinbase script is empty")
+	}
+
+	op := coinbase[0]
+	switch {
+	case op == script.Op0:
+		return 0, nil
+	case op >= script.Op1 && op <= script.Op16:
+		return uint64(op - script.Op1 + 1), nil
+	case op > 8:
+		return 0, fmt.Errorf("coinbase script does not start with a height push (opcode 0x%02x)", op)
+	case len(coinbase) < 1+int(op):
+		return 0, fmt.Errorf("coinbase height push is truncated")
+	}
+
+	// Script numbers are little-endian with the sign in the top bit
+	num := coinbase[1 : 1+op]
+	if num[len(num)-1]&0x80 != 0 {
+		return 0, fmt.Errorf("coinbase height is negative")
+	}
+	var height uint64
+	for i := len(num) - 1; i >= 0; i-- {
+		height = height<<8 | uint64(num[i])
+	}
+	return height, nil
+}
+
+// minerTag returns the printable text miners put in the coinbase script,
+// such as a pool name. Runs of at least minTagRun printable characters are
+// joined with spaces. When the script is a sequence of pushes the push data is
+// searched, so length bytes are not mistaken for text; otherwise the bytes
+// after the height push are searched as they are.
+func minerTag(coinbase []byte) string {
+	segments := [][]byte{coinbase}
+	if chunks, err := script.DecodeScript(coinbase); err == nil && len(chunks) > 0 && allPushes(chunks) {
+		segments = segments[:0]
+		for _, chunk := range chunks[1:] {
+			segments = append(segments, chunk.Data)
+		}
+	} else if len(coinbase) > 0 && coinbase[0] <= 8 && len(coinbase) > int(coinbase[0]) {
+		segments[0] = coinbase[1+coinbase[0]:]
+	}
+
+	var runs []string
+	for _, segment := range segments {
+		start := -1
+		for i := 0; i <= len(segment); i++ {
+			if i < len(segment) && segment[i] >= 0x20 && segment[i] <= 0x7e {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				if run := strings.TrimSpace(string(segment[start:i])); len(run) >= minTagRun {
+					runs = append(runs, run)
+				}
+			}
+			start = -1
+		}
+	}
+	return strings.Join(runs, " ")
+}
+
+// allPushes reports whether every chunk pushes data.
+func allPushes(chunks []*script.ScriptChunk) bool {
+	for _, chunk := range chunks {
+		if chunk.Op > script.OpPUSHDATA4 {
+			return false
+		}
+	}
+	return true
+}
+
 // Encoding helpers
 
 func encodeUint32LE(v uint32) string {
@@ -368,13 +764,18 @@ func encodeUint64LE(v uint64) string {
 
 // init initializes the cobra command flags.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	// Transaction input
 	rootCmd.Flags().StringVarP(&raw, "raw", "r", "", "Raw transaction hex")
+	rootCmd.Flags().BoolVar(&stream, "stream", false, "Read one transaction hex per stdin line and pick from each")
+	rootCmd.Flags().BoolVar(&prefixTxID, "prefix-txid", false, "Prefix each result line with the transaction's txid and a tab")
 
 	// Output selectors
 	rootCmd.Flags().IntSliceVarP(&outputs, "output", "o", nil, "Select complete serialized output at index (can repeat)")
 	rootCmd.Flags().IntSliceVar(&outputScripts, "output-script", nil, "Select output locking script at index (can repeat)")
 	rootCmd.Flags().IntSliceVar(&outputValues, "output-value", nil, "Select output value at index (can repeat)")
+	rootCmd.Flags().StringArrayVar(&countMatching, "count-matching", nil, "Count outputs matching comma-separated conditions, e.g. 'address=<addr>,value>546', printing the count and indices (can repeat)")
 
 	// Input selectors
 	rootCmd.Flags().IntSliceVarP(&inputs, "input", "i", nil, "Select complete serialized input at index (can repeat)")
@@ -387,6 +788,13 @@ func init() {
 	rootCmd.Flags().BoolVarP(&getVersion, "version", "v", false, "Select transaction version (4-byte LE hex)")
 	rootCmd.Flags().BoolVarP(&getLocktime, "locktime", "l", false, "Select transaction locktime (4-byte LE hex)")
 	rootCmd.Flags().BoolVar(&getTxID, "txid", false, "Select transaction ID")
+
+	// Coinbase selectors
+	rootCmd.Flags().BoolVar(&getCoinbaseHeight, "coinbase-height", false, "Select the coinbase's BIP34 block height (decimal)")
+	rootCmd.Flags().BoolVar(&getCoinbaseTag, "coinbase-tag", false, "Select the coinbase's miner tag (printable text)")
+	rootCmd.Flags().BoolVar(&getCoinbaseScript, "coinbase-script", false, "Select the raw coinbase script")
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the pick command.
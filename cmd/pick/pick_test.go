@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+)
+
+// genesisCoinbase is the raw coinbase transaction of the genesis block.
+const genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+func TestGetCoinbaseInput(t *testing.T) {
+	t.Parallel()
+
+	tx, err := transaction.NewTransactionFromHex(genesisCoinbase)
+	require.NoError(t, err)
+	coinbase, err := getCoinbaseInput(tx)
+	require.NoError(t, err)
+	assert.Equal(t, "04ffff001d01044554", hex.EncodeToString(coinbase[:9]))
+	assert.Equal(t, "The Times 03/Jan/2009 Chancellor on brink of second bailout for banks", minerTag(coinbase))
+
+	// Spending a real outpoint makes it an ordinary transaction
+	tx.Inputs[0].SourceTXID, err = chainhash.NewHashFromHex(tx.TxID().String())
+	require.NoError(t, err)
+	_, err = getCoinbaseInput(tx)
+	require.ErrorContains(t, err, "not a coinbase")
+}
+
+func TestDecodeCoinbaseHeight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		expected uint64
+		err      string
+	}{
+		{"three-byte height", "0300350c2f7461616c2e636f6d2f", 800000, ""},
+		{"one-byte height", "016400", 100, ""},
+		{"sign padding", "02ff00", 255, ""},
+		{"small height opcode", "5a", 10, ""},
+		{"height zero", "00", 0, ""},
+		{"empty", "", 0, "empty"},
+		{"not a push", "6a00", 0, "does not start with a height push"},
+		{"truncated", "0300350", 0, "truncated"},
+		{"negative", "0180", 0, "negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			coinbase, _ := hex.DecodeString(tt.script)
+			height, err := decodeCoinbaseHeight(coinbase)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, height)
+		})
+	}
+}
+
+func TestMinerTag(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"0300350c2f7461616c2e636f6d2f":                   "/taal.com/",
+		"0300350c2f7461616c2f0a0b0c2f4d696e6564206279":   "/taal/ /Mined by",
+		"0300350c414243ff0102":                           "",
+		"0300350c20202020414243":                         "",
+		"0300350c2f53565f506f6f6c2f0000000000000000ffff": "/SV_Pool/",
+	}
+	for script, expected := range tests {
+		coinbase, err := hex.DecodeString(script)
+		require.NoError(t, err)
+		assert.Equal(t, expected, minerTag(coinbase), script)
+	}
+}
+
+func TestCountMatching(t *testing.T) {
+	t.Parallel()
+
+	_, addr := chaintest.Source(t)
+	lock, err := p2pkh.Lock(addr)
+	require.NoError(t, err)
+	data, err := script.NewFromHex("006a0568656c6c6f")
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	tx.Outputs = []*transaction.TransactionOutput{
+		{Satoshis: 1000, LockingScript: lock},
+		{Satoshis: 0, LockingScript: data},
+		{Satoshis: 546, LockingScript: lock},
+		{Satoshis: 5000, LockingScript: data},
+	}
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"address=" + addr.AddressString, "2 0 2"},
+		{"address=" + addr.AddressString + ",value>546", "1 0"},
+		{"script^=006A", "2 1 3"},
+		{"script=006a0568656c6c6f", "2 1 3"},
+		{"script^=76a914,value<=546", "1 2"},
+		{"value>=1000", "2 0 3"},
+		{"value=0", "1 1"},
+		{"value<0", "0"},
+	}
+	for _, tt := range tests {
+		match, err := parsePredicate(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.expected, formatMatches(matchingOutputs(tx, match)), tt.expr)
+	}
+
+	for expr, msg := range map[string]string{
+		"value":           "expected <field><op><value>",
+		"fee>10":          "unknown field",
+		"address>x":       "address only supports =",
+		"address=nope":    "invalid address",
+		"script>00":       "script only supports",
+		"script^=zz":      "script must be hex",
+		"value^=1":        "value only supports",
+		"value>lots":      "value must be satoshis",
+		"value>1,bogus=1": "unknown field",
+	} {
+		_, err := parsePredicate(expr)
+		require.ErrorContains(t, err, msg, expr)
+	}
+}
+
+func TestStreamTransactions(t *testing.T) {
+	// Selectors are package flags, so this test cannot run in parallel
+	oldValues, oldTxID, oldPrefix := outputValues, getTxID, prefixTxID
+	t.Cleanup(func() { outputValues, getTxID, prefixTxID = oldValues, oldTxID, oldPrefix })
+	outputValues, getTxID = []int{0}, false
+
+	genesisTxID := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	noOutputs := transaction.NewTransaction().Hex()
+	input := genesisCoinbase + "\n\nnot-hex\n" + noOutputs + "\n" + genesisCoinbase
+
+	var out, errOut bytes.Buffer
+	processed, failed, err := streamTransactions(strings.NewReader(input), &out, &errOut, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, processed)
+	assert.Equal(t, 2, failed)
+	assert.Equal(t, "00f2052a01000000\n00f2052a01000000\n", out.String())
+	assert.Contains(t, errOut.String(), "line 3: input is not a valid hex string\n")
+	assert.Contains(t, errOut.String(), "line 4: output index 0 out of range")
+
+	prefixTxID, getTxID = true, true
+	out.Reset()
+	processed, failed, err = streamTransactions(strings.NewReader(genesisCoinbase+"\n"), &out, &errOut, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Zero(t, failed)
+	assert.Equal(t, genesisTxID+"\t"+genesisTxID+"\n"+genesisTxID+"\t00f2052a01000000\n", out.String())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/httpmock"
+	"github.com/mrz1836/go-template/internal/txinspect"
+)
+
+// The integration tests run the command against recorded WhatsOnChain
+// responses in testdata; see internal/httpmock to re-record them. The child
+// transaction spends a 10000-satoshi output of private key 1's address and
+// pays 9900 satoshis back to it.
+const childHex = "01000000014ae4ec7c27f8d2ddac333161aab1674ad22f0db237d42cb4239d9285c09056a9000000006b483045022100a61d9fe2c01335b0326463dce18b1867a489320047888c29d09d8f35cf05c5dc022014c310c49a43da5f9ff86a87b8c734988c7ae2fdbb472db5e2bc8d76cee1dcc241210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798ffffffff01ac260000000000001976a914751e76e8199196d454941c45d1b3a323f1433bd688ac00000000"
+
+func TestIntegrationPrevouts(t *testing.T) {
+	httpmock.Install(t, "prevouts")
+
+	out, err := httpmock.Execute(t, rootCmd, "-r", childHex, "--prevouts", "--no-color")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Value: 10000 sats")
+	assert.Contains(t, out, "Total out: 9900 sats")
+	assert.Contains(t, out, "Total in:  10000 sats")
+	assert.Contains(t, out, "Fee:       100 sats")
+	assert.Contains(t, out, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH  spent 10000, received 9900, net -100 sats")
+}
+
+func TestIntegrationNoPrevouts(t *testing.T) {
+	out, err := httpmock.Execute(t, rootCmd, "-r", childHex, "--no-color")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Total out: 9900 sats")
+	assert.NotContains(t, out, "Total in:")
+	assert.Contains(t, out, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH  received 9900 sats")
+}
+
+func TestIntegrationJSON(t *testing.T) {
+	httpmock.Install(t, "prevouts")
+
+	out, err := httpmock.Execute(t, rootCmd, "-r", childHex, "--prevouts", "--json")
+	require.NoError(t, err)
+
+	var report txinspect.Report
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, "raw", report.Format)
+	assert.Equal(t, uint32(1), report.Version)
+	assert.Equal(t, len(childHex)/2, report.Size)
+	require.Len(t, report.Inputs, 1)
+	assert.Equal(t, "a95690c085929d23b42cd437b20d2fd24a67b1aa613133acddd2f8277cece44a", report.Inputs[0].PrevTxID)
+	assert.Equal(t, txinspect.SpendP2PKH, report.Inputs[0].SpendType)
+	assert.Equal(t, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", report.Inputs[0].Address)
+	require.NotNil(t, report.Inputs[0].Satoshis)
+	assert.Equal(t, uint64(10000), *report.Inputs[0].Satoshis)
+	require.Len(t, report.Outputs, 1)
+	assert.Equal(t, uint64(9900), report.Outputs[0].Satoshis)
+	assert.Equal(t, "p2pkh", report.Outputs[0].Template.Type)
+	assert.Equal(t, []string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"}, report.Outputs[0].Template.Addresses)
+	assert.Nil(t, report.Outputs[0].Status)
+	require.NotNil(t, report.Summary.Fee)
+	assert.Equal(t, int64(100), *report.Summary.Fee)
+}
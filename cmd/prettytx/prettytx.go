@@ -7,11 +7,21 @@
 // Features:
 //   - Colorized output for better readability (can be disabled)
 //   - Detailed breakdown of all transaction components
-//   - Script hex display for inputs and outputs
+//   - Script hex and ASM disassembly for inputs and outputs, or ASM alone with --asm-only
 //   - Address extraction for P2PKH scripts (inputs and outputs)
+//   - Input spend type: P2PKH, P2PK, multisig, data-only, custom, or unsigned
 //   - Satoshi to BSV conversion
-//   - Locktime interpretation (block height vs timestamp)
+//   - Locktime interpretation (block height vs timestamp) and whether it is enforced
+//   - Sequence decoding: final, non-final, and BIP68 relative locktime
+//   - Lint section flagging dust outputs, oversized scripts, non-push unlocking
+//     scripts, and duplicate inputs before broadcast
 //   - Support for stdin or command-line input
+//   - Accepts raw, Extended Format (EF), or BEEF hex; EF and BEEF show input values
+//   - Optional spent status of each output, and the spending txid, from WhatsOnChain
+//   - Summary of total value and value per address, with the fee and each
+//     address's net flow when source outputs are known or fetched
+//   - Data outputs decoded by protocol: B://, Bcat, MAP, AIP, Run, and other Bitcom prefixes
+//   - Machine-readable JSON of the whole breakdown with --json, for pipelines and tests
 //
 // Usage:
 //
@@ -19,38 +29,42 @@
 //	echo "010000..." | prettytx               # Parse from stdin
 //	prettytx -r "010000..."                   # Parse using flag
 //	prettytx --no-color                       # Disable colors
+//	prettytx --asm-only                       # Scripts as opcodes, without hex
+//	getraw <txid> | prettytx --spent-status   # Show which outputs are spent
+//	getraw <txid> | prettytx --prevouts       # Fetch input values for net flow
+//	getraw <txid> | prettytx --json          # Decode as JSON for jq and tests
 //	carve -w <WIF> -a <addr> | prettytx       # Chain with carve
 package main
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
-	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
-	"github.com/bsv-blockchain/go-sdk/script"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
 	"github.com/spf13/cobra"
 	"golang.design/x/clipboard"
 
+	"github.com/mrz1836/go-template/internal/chain"
 	"github.com/mrz1836/go-template/internal/cli"
-)
-
-// ANSI color codes for terminal output styling
-const (
-	colorReset = "\033[0m"  // Reset to default
-	colorRed   = "\033[31m" // Red text (errors)
-	colorGreen = "\033[32m" // Green text (values, addresses)
-	colorWhite = "\033[37m" // White text (headers, structure)
-	colorDim   = "\033[2m"  // Dimmed text (labels, annotations)
+	"github.com/mrz1836/go-template/internal/spv"
+	"github.com/mrz1836/go-template/internal/txinspect"
 )
 
 // Command-line flags
 var (
-	raw     string // Raw transaction hex provided via flag
-	noColor bool   // Disable colored output
-	compact bool   // Enable compact output mode
+	raw      string // Raw transaction hex provided via flag
+	noColor  bool   // Disable colored output
+	compact  bool   // Enable compact output mode
+	spent    bool   // Look up the spent status of each output
+	prevouts bool   // Fetch the source outputs of inputs that lack them
+	testnet  bool   // Use testnet for lookups and addresses instead of mainnet
+	jsonOut  bool   // Print the decoded transaction as JSON instead of the colorized breakdown
+	asmOnly  bool   // Show scripts as ASM without their hex
 )
 
 // rootCmd is the main cobra command for the prettytx tool.
@@ -121,16 +135,7 @@ func readFromClipboard() (string, error) {
 	return content, nil
 }
 
-// c applies ANSI color codes to text if color output is enabled.
-// Returns plain text if --no-color flag is set, otherwise returns colorized text.
-func c(color, text string) string {
-	if noColor {
-		return text
-	}
-	return color + text + colorReset
-}
-
-// parseTransaction decodes and displays a raw Bitcoin transaction in human-readable format.
+// parseTransaction decodes and displays a raw, EF, or BEEF transaction in human-readable format.
 func parseTransaction(rawTx string) error {
 	// Decode hex to bytes
 	txBytes, err := hex.DecodeString(rawTx)
@@ -138,324 +143,121 @@ func parseTransaction(rawTx string) error {
 		return fmt.Errorf("decoding hex: %w", err)
 	}
 
-	// Parse transaction using BSV SDK
-	tx, err := transaction.NewTransactionFromBytes(txBytes)
+	// Parse raw, EF, or BEEF
+	tx, format, err := spv.ParseTransaction(txBytes)
 	if err != nil {
-		return fmt.Errorf("parsing transaction: %w", err)
-	}
-
-	// Display transaction breakdown
-	printHeader(tx.TxID().String())
-	printVersion(tx)
-	printInputs(tx)
-	printOutputs(tx)
-	printLocktime(tx)
-	printFooter(tx)
-
-	return nil
-}
-
-// printHeader prints the transaction breakdown header.
-func printHeader(txid string) {
-	fmt.Printf("%s %s\n",
-		c(colorDim, "TX ID:"),
-		c(colorGreen, txid))
-	fmt.Println(c(colorWhite, "────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────"))
-}
-
-// printVersion prints the transaction version.
-func printVersion(tx *transaction.Transaction) {
-	fmt.Printf("%s %d %s\n",
-		c(colorDim, "Version:"),
-		tx.Version,
-		c(colorDim, fmt.Sprintf("(0x%08x)", tx.Version)))
-}
-
-// printInputs prints the transaction inputs section.
-func printInputs(tx *transaction.Transaction) {
-	inputCount := len(tx.Inputs)
-	fmt.Printf("%s %d\n", c(colorDim, "Inputs:"), inputCount)
-
-	if inputCount == 0 {
-		return
-	}
-
-	for i, input := range tx.Inputs {
-		printInput(i, input)
-	}
-}
-
-// printInput prints a single transaction input.
-func printInput(index int, input *transaction.TransactionInput) {
-	fmt.Printf("\n%s\n", c(colorWhite, fmt.Sprintf("INPUT #%d", index)))
-
-	// Previous transaction ID and output index on same line
-	if input.SourceTXID != nil {
-		fmt.Printf("  %s %s:%d\n",
-			c(colorDim, "Prev:"),
-			c(colorGreen, input.SourceTXID.String()),
-			input.SourceTxOutIndex)
-	} else {
-		fmt.Printf("  %s %s\n",
-			c(colorDim, "Prev:"),
-			c(colorRed, "(null)"))
-	}
-
-	// Script
-	printUnlockingScript(input.UnlockingScript)
-
-	// Sequence number
-	fmt.Printf("  %s %d %s\n",
-		c(colorDim, "Sequence:"),
-		input.SequenceNumber,
-		c(colorDim, fmt.Sprintf("(0x%08x)", input.SequenceNumber)))
-}
-
-// truncateHex truncates a hex string if compact mode is enabled and it exceeds maxLen.
-func truncateHex(hexStr string, maxLen int) string {
-	if !compact || len(hexStr) <= maxLen {
-		return hexStr
-	}
-	return hexStr[:maxLen] + "..."
-}
-
-// printUnlockingScript prints the unlocking script details for an input.
-func printUnlockingScript(unlockingScript *script.Script) {
-	if unlockingScript == nil {
-		fmt.Printf("  %s %s\n", c(colorDim, "Script:"), c(colorDim, "(empty)"))
-		return
-	}
-
-	scriptBytes := *unlockingScript
-	scriptHex := scriptBytes.String()
-	scriptLen := len(scriptBytes)
-
-	fmt.Printf("  %s %s %s\n",
-		c(colorDim, "Script:"),
-		c(colorDim, truncateHex(scriptHex, 64)),
-		c(colorDim, fmt.Sprintf("(%d bytes)", scriptLen)))
-
-	// Try to extract address from P2PKH unlocking script
-	addr := extractAddressFromUnlockingScript(unlockingScript, true)
-	if addr != "" {
-		fmt.Printf("  %s %s\n", c(colorDim, "Address:"), c(colorGreen, addr))
-	}
-}
-
-// printOutputs prints the transaction outputs section.
-func printOutputs(tx *transaction.Transaction) {
-	outputCount := len(tx.Outputs)
-	fmt.Printf("%s %d\n", c(colorDim, "Outputs:"), outputCount)
-
-	if outputCount == 0 {
-		return
-	}
-
-	for i, output := range tx.Outputs {
-		printOutput(i, output)
+		return err
 	}
-}
-
-// printOutput prints a single transaction output.
-func printOutput(index int, output *transaction.TransactionOutput) {
-	fmt.Printf("\n%s\n", c(colorWhite, fmt.Sprintf("OUTPUT #%d", index)))
-
-	// Value in satoshis
-	satoshis := output.Satoshis
-	btc := float64(satoshis) / 100000000.0
-	fmt.Printf("  %s %s %s\n",
-		c(colorDim, "Value:"),
-		c(colorGreen, fmt.Sprintf("%d sats", satoshis)),
-		c(colorDim, fmt.Sprintf("(%.8f BSV)", btc)))
-
-	// Locking script
-	printLockingScript(output.LockingScript)
-}
 
-// printLockingScript prints the locking script details for an output.
-func printLockingScript(lockingScript *script.Script) {
-	if lockingScript == nil {
-		fmt.Printf("  %s %s\n", c(colorDim, "Script:"), c(colorDim, "(empty)"))
-		return
+	// Look up source outputs and output spentness before printing, so a
+	// failure prints nothing
+	if prevouts {
+		if err = fetchPrevouts(tx); err != nil {
+			return err
+		}
 	}
-
-	scriptBytes := *lockingScript
-	scriptHex := scriptBytes.String()
-	scriptLen := len(scriptBytes)
-
-	fmt.Printf("  %s %s %s\n",
-		c(colorDim, "Script:"),
-		c(colorDim, truncateHex(scriptHex, 64)),
-		c(colorDim, fmt.Sprintf("(%d bytes)", scriptLen)))
-
-	// Try to extract P2PKH address
-	addr := extractP2PKHAddress(lockingScript, true)
-	if addr != "" {
-		fmt.Printf("  %s %s\n", c(colorDim, "Address:"), c(colorGreen, addr))
+	var status map[int]*txinspect.OutputStatus
+	if spent {
+		if status, err = lookupSpent(tx); err != nil {
+			return err
+		}
 	}
-}
 
-// printLocktime prints the transaction locktime.
-func printLocktime(tx *transaction.Transaction) {
-	lockInfo := ""
-	if tx.LockTime == 0 {
-		lockInfo = "(Not locked)"
-	} else if tx.LockTime < 500000000 {
-		lockInfo = fmt.Sprintf("(Block %d)", tx.LockTime)
-	} else {
-		lockInfo = fmt.Sprintf("(Timestamp %d)", tx.LockTime)
+	if jsonOut {
+		report := txinspect.Inspect(tx, format, testnet)
+		report.SetStatus(status)
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
 	}
 
-	fmt.Printf("\n%s %d %s\n",
-		c(colorDim, "nLockTime:"),
-		tx.LockTime,
-		c(colorDim, lockInfo))
-}
+	// Display transaction breakdown
+	printer := &txinspect.Printer{W: os.Stdout, NoColor: noColor, Compact: compact, ASMOnly: asmOnly, Testnet: testnet}
+	printer.Print(tx, format, status)
 
-// printFooter prints the transaction footer with TXID.
-func printFooter(tx *transaction.Transaction) {
-	fmt.Println(c(colorWhite, "────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────"))
-	fmt.Printf("%s %s\n",
-		c(colorDim, "TX ID:"),
-		c(colorGreen, tx.TxID().String()))
+	return nil
 }
 
-// isP2PKH checks if a locking script is a standard P2PKH (Pay-to-PubKey-Hash) script.
-// P2PKH scripts have the pattern: OP_DUP OP_HASH160 <20-byte-hash> OP_EQUALVERIFY OP_CHECKSIG
-// This is exactly 25 bytes: 76 a9 14 <20 bytes> 88 ac
-func isP2PKH(scriptBytes *script.Script) bool {
-	if scriptBytes == nil {
-		return false
+// fetchPrevouts attaches the source output of each input that lacks one,
+// fetching the source transactions from the data provider in config.yaml.
+func fetchPrevouts(tx *transaction.Transaction) error {
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
 	}
-
-	bytes := []byte(*scriptBytes)
-
-	// Check length (must be exactly 25 bytes)
-	if len(bytes) != 25 {
-		return false
+	r := spv.NewResolver(provider.WOC.Client)
+	r.Txs = provider
+	if err = r.Sources(ctx, tx); err != nil {
+		return fmt.Errorf("fetching source outputs: %w", err)
 	}
-
-	// Check the P2PKH pattern
-	return bytes[0] == 0x76 && // OP_DUP
-		bytes[1] == 0xa9 && // OP_HASH160
-		bytes[2] == 0x14 && // Push 20 bytes
-		bytes[23] == 0x88 && // OP_EQUALVERIFY
-		bytes[24] == 0xac // OP_CHECKSIG
+	return nil
 }
 
-// extractP2PKHAddress extracts the address from a P2PKH locking script.
-// Returns the address string if successful, empty string otherwise.
-func extractP2PKHAddress(scriptBytes *script.Script, mainnet bool) string {
-	if !isP2PKH(scriptBytes) {
-		return ""
-	}
-
-	bytes := []byte(*scriptBytes)
-
-	// Extract the 20-byte public key hash (bytes 3-22)
-	pubKeyHash := bytes[3:23]
-
-	// Create an address from the hash
-	addr, err := script.NewAddressFromPublicKeyHash(pubKeyHash, mainnet)
+// lookupSpent looks up the spent status of tx's outputs on the network's
+// WhatsOnChain, the only provider with spent-output lookups.
+func lookupSpent(tx *transaction.Transaction) (map[int]*txinspect.OutputStatus, error) {
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
 	if err != nil {
-		return ""
+		return nil, err
 	}
-
-	return addr.AddressString
+	return spentStatus(ctx, provider.WOC.Client, tx)
 }
 
-// extractAddressFromUnlockingScript attempts to extract an address from a P2PKH unlocking script.
-// P2PKH unlocking scripts contain: <signature> <pubKey>
-// This function extracts the public key and derives the address from it.
-// Returns the address string if successful, empty string otherwise.
-func extractAddressFromUnlockingScript(scriptBytes *script.Script, mainnet bool) string {
-	if scriptBytes == nil {
-		return ""
-	}
-
-	bytes := []byte(*scriptBytes)
-	if len(bytes) == 0 {
-		return ""
-	}
-
-	// Parse the script to extract the public key
-	pubKeyBytes := extractPublicKeyFromScript(bytes)
-	if len(pubKeyBytes) == 0 {
-		return ""
-	}
-
-	// Try to parse the public key
-	pubKey, err := ec.ParsePubKey(pubKeyBytes)
-	if err != nil {
-		return ""
-	}
-
-	// Derive the address from the public key
-	addr, err := script.NewAddressFromPublicKey(pubKey, mainnet)
-	if err != nil {
-		return ""
+// spentStatus looks up whether each spendable output of tx has been spent,
+// in batches of the bulk endpoint's limit. Outputs of a transaction
+// WhatsOnChain has never seen are reported unspent.
+func spentStatus(ctx context.Context, client whatsonchain.ClientInterface, tx *transaction.Transaction) (map[int]*txinspect.OutputStatus, error) {
+	txid := tx.TxID().String()
+	status := make(map[int]*txinspect.OutputStatus, len(tx.Outputs))
+	var utxos []whatsonchain.BulkSpentUTXO
+	for i, output := range tx.Outputs {
+		status[i] = &txinspect.OutputStatus{}
+		if output.LockingScript != nil && output.LockingScript.IsData() {
+			status[i].Unspendable = true
+			continue
+		}
+		utxos = append(utxos, whatsonchain.BulkSpentUTXO{TxID: txid, Vout: i})
 	}
 
-	return addr.AddressString
-}
-
-// extractPublicKeyFromScript parses a script to extract the public key.
-// In a typical P2PKH unlocking script:
-// - First comes the signature (variable length, typically ~72 bytes)
-// - Then comes the public key (33 or 65 bytes)
-func extractPublicKeyFromScript(bytes []byte) []byte {
-	var pubKeyBytes []byte
-	i := 0
-
-	for i < len(bytes) {
-		if i >= len(bytes) {
-			break
+	for start := 0; start < len(utxos); start += whatsonchain.MaxTransactionsUTXO {
+		end := min(start+whatsonchain.MaxTransactionsUTXO, len(utxos))
+		results, err := client.BulkSpentOutputs(ctx, &whatsonchain.BulkSpentOutputRequest{UTXOs: utxos[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("looking up spent outputs: %w", err)
 		}
 
-		opcode := bytes[i]
-		i++
-
-		// Handle push data opcodes
-		if opcode > 0 && opcode <= 75 {
-			// Direct push of N bytes
-			length := int(opcode)
-			if i+length > len(bytes) {
-				break
-			}
-			data := bytes[i : i+length]
-			i += length
-
-			// Check if this looks like a public key (33 or 65 bytes)
-			if length == 33 || length == 65 {
-				pubKeyBytes = data
-			}
-		} else if opcode == 0x4c { // OP_PUSHDATA1
-			if i >= len(bytes) {
-				break
-			}
-			length := int(bytes[i])
-			i++
-			if i+length > len(bytes) {
-				break
-			}
-			data := bytes[i : i+length]
-			i += length
-
-			if length == 33 || length == 65 {
-				pubKeyBytes = data
+		for _, sr := range results {
+			s := status[sr.Vout]
+			if sr.TxID != txid || s == nil || sr.Spent == nil || sr.Spent.TxID == "" {
+				continue
 			}
+			s.SpentBy = sr.Spent.TxID
+			s.SpentVin = sr.Spent.Vin
 		}
 	}
-
-	return pubKeyBytes
+	return status, nil
 }
 
 // init initializes the cobra command flags.
 // This function is automatically called by Go before main() executes.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	rootCmd.Flags().StringVarP(&raw, "raw", "r", "", "Raw transaction hex to parse")
 	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
 	rootCmd.Flags().BoolVarP(&compact, "compact", "c", false, "Enable compact output with truncated scripts")
+	rootCmd.Flags().BoolVar(&spent, "spent-status", false, "Look up whether each output is spent on WhatsOnChain")
+	rootCmd.Flags().BoolVar(&prevouts, "prevouts", false, "Fetch input source outputs from the data provider for input values, fee, and net flow")
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet for lookups and addresses")
+	rootCmd.Flags().BoolVar(&asmOnly, "asm-only", false, "Show scripts as ASM (OP_DUP OP_HASH160 ...) without their hex")
+	rootCmd.Flags().BoolVarP(&jsonOut, "json", "j", false, "Print the decoded transaction as JSON instead of the colorized breakdown")
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the prettytx command.
@@ -0,0 +1,284 @@
+// Package main implements a Bitcoin SV script assembler and disassembler.
+//
+// This tool converts between script hex and ASM in both directions. The
+// direction is detected automatically (hex input is disassembled, anything else
+// is assembled) or can be forced. It also validates opcodes, computes script
+// hashes, and recognizes standard locking script templates.
+//
+// Features:
+//   - Hex → ASM disassembly and ASM → hex assembly
+//   - Opcode validation (truncated pushes, undefined opcodes, unbalanced IF)
+//   - SHA-256, WhatsOnChain script hash, and HASH160 computation
+//   - Template recognition (P2PKH, P2PK, P2SH, bare multisig, OP_RETURN data)
+//   - One script per stdin line for pipeline use with pick
+//   - JSON output support
+//
+// Usage:
+//
+//	scriptasm 76a914...88ac                          # Disassemble hex to ASM
+//	scriptasm "OP_DUP OP_HASH160 <hex> OP_EQUALVERIFY OP_CHECKSIG"  # Assemble ASM to hex
+//	scriptasm -i 76a914...88ac                       # Show template, hashes, and issues
+//	scriptasm -j 76a914...88ac                       # JSON report
+//	getraw <txid> | pick --output-script 0 | scriptasm   # Chain with pick
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Command-line flags
+var (
+	toASM      bool // Force hex → ASM
+	toHex      bool // Force ASM → hex
+	info       bool // Show template, hashes, and validation issues
+	strict     bool // Exit non-zero if validation issues are found
+	testnet    bool // Derive testnet addresses for recognized templates
+	jsonOutput bool // Output in JSON format
+	noColor    bool // Disable colored output
+)
+
+// report holds the full analysis of a single script.
+type report struct {
+	Hex      string               `json:"hex"`
+	ASM      string               `json:"asm"`
+	Size     int                  `json:"size"`
+	Template scripts.TemplateInfo `json:"template"`
+	Hashes   scripts.Hashes       `json:"hashes"`
+	Issues   []string             `json:"issues,omitempty"`
+}
+
+// rootCmd is the main cobra command for the scriptasm tool.
+var rootCmd = &cobra.Command{
+	Use:   "scriptasm [script]",
+	Short: "Convert Bitcoin scripts between hex and ASM",
+	Long: `A command line tool that converts Bitcoin SV scripts between hex and ASM,
+validates opcodes, computes script hashes, and recognizes standard templates.
+
+Hex input is disassembled and any other input is assembled, unless --to-asm or
+--to-hex is given. When reading from stdin, each line is processed separately.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	if toASM && toHex {
+		return fmt.Errorf("--to-asm and --to-hex are mutually exclusive")
+	}
+
+	inputs, err := getInputs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(inputs) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no script provided")
+	}
+
+	reports := make([]*report, 0, len(inputs))
+	issueCount := 0
+	for _, input := range inputs {
+		r, err := analyze(input)
+		if err != nil {
+			return err
+		}
+		issueCount += len(r.Issues)
+		reports = append(reports, r)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if len(reports) == 1 {
+			err = enc.Encode(reports[0])
+		} else {
+			err = enc.Encode(reports)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		for i, r := range reports {
+			printReport(r, inputs[i])
+		}
+	}
+
+	if strict && issueCount > 0 {
+		return fmt.Errorf("%d validation issue(s) found", issueCount)
+	}
+	return nil
+}
+
+// getInputs retrieves scripts from argument or stdin (one script per line).
+func getInputs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return []string{strings.TrimSpace(args[0])}, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) != 0 {
+		return nil, nil
+	}
+
+	var inputs []string
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			inputs = append(inputs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+
+	return inputs, nil
+}
+
+// isHexInput decides the conversion direction for an input string.
+func isHexInput(input string) bool {
+	if toASM {
+		return true
+	}
+	if toHex {
+		return false
+	}
+	return len(input)%2 == 0 && cli.IsValidHex(input)
+}
+
+// analyze converts an input script and builds its full report.
+func analyze(input string) (*report, error) {
+	var scriptBytes []byte
+	var err error
+
+	if isHexInput(input) {
+		scriptBytes, err = hex.DecodeString(input)
+		if err != nil {
+			return nil, fmt.Errorf("decoding hex: %w", err)
+		}
+	} else {
+		scriptBytes, err = scripts.Assemble(input)
+		if err != nil {
+			return nil, fmt.Errorf("assembling script: %w", err)
+		}
+	}
+
+	r := &report{
+		Hex:      hex.EncodeToString(scriptBytes),
+		Size:     len(scriptBytes),
+		Template: scripts.Classify(scriptBytes, !testnet),
+		Hashes:   scripts.ComputeHashes(scriptBytes),
+		Issues:   scripts.ValidateOpcodes(scriptBytes),
+	}
+
+	// A script that fails to parse has no ASM; the parse error is already an issue
+	if r.ASM, err = scripts.Disassemble(scriptBytes); err != nil {
+		r.ASM = ""
+	}
+
+	return r, nil
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printReport prints the converted script, plus analysis details with --info.
+func printReport(r *report, input string) {
+	if !info {
+		if isHexInput(input) {
+			fmt.Println(r.ASM)
+		} else {
+			fmt.Println(r.Hex)
+		}
+		for _, issue := range r.Issues {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", issue)
+		}
+		return
+	}
+
+	fmt.Printf("%s %s\n", c(colorDim, "Hex:"), r.Hex)
+	fmt.Printf("%s %s\n", c(colorDim, "ASM:"), r.ASM)
+	fmt.Printf("%s %d bytes\n", c(colorDim, "Size:"), r.Size)
+	fmt.Printf("%s %s\n", c(colorDim, "Template:"), c(colorGreen, r.Template.Type))
+
+	if r.Template.RequiredSigs > 0 {
+		fmt.Printf("  %s %d of %d\n", c(colorDim, "Required:"), r.Template.RequiredSigs, len(r.Template.PubKeys))
+	}
+	for _, addr := range r.Template.Addresses {
+		fmt.Printf("  %s %s\n", c(colorDim, "Address:"), c(colorGreen, addr))
+	}
+	for _, pk := range r.Template.PubKeys {
+		fmt.Printf("  %s %s\n", c(colorDim, "PubKey:"), pk)
+	}
+	if r.Template.ScriptHash != "" {
+		fmt.Printf("  %s %s\n", c(colorDim, "Script Hash:"), r.Template.ScriptHash)
+	}
+	for i, d := range r.Template.Data {
+		fmt.Printf("  %s %s\n", c(colorDim, fmt.Sprintf("Data[%d]:", i)), d)
+	}
+
+	fmt.Printf("%s\n", c(colorDim, "Hashes:"))
+	fmt.Printf("  %s %s\n", c(colorDim, "SHA-256:"), r.Hashes.SHA256)
+	fmt.Printf("  %s %s\n", c(colorDim, "Script Hash (WoC):"), r.Hashes.ScriptHash)
+	fmt.Printf("  %s %s\n", c(colorDim, "HASH160:"), r.Hashes.Hash160)
+
+	if len(r.Issues) == 0 {
+		fmt.Printf("%s %s\n", c(colorDim, "Validation:"), c(colorGreen, "OK"))
+	} else {
+		fmt.Printf("%s\n", c(colorDim, "Validation:"))
+		for _, issue := range r.Issues {
+			fmt.Printf("  %s\n", c(colorRed, issue))
+		}
+	}
+	fmt.Println()
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&toASM, "to-asm", "d", false, "Force disassembly (input is hex)")
+	rootCmd.Flags().BoolVarP(&toHex, "to-hex", "a", false, "Force assembly (input is ASM)")
+	rootCmd.Flags().BoolVarP(&info, "info", "i", false, "Show template, hashes, and validation details")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero if any validation issue is found")
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Derive testnet addresses for recognized templates")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the scriptasm command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// P2PKH locking script for private key 1, in both forms.
+const (
+	p2pkhHex = "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"
+	p2pkhASM = "OP_DUP OP_HASH160 751e76e8199196d454941c45d1b3a323f1433bd6 OP_EQUALVERIFY OP_CHECKSIG"
+)
+
+// The tests set the package-level direction flags analyze reads, so they must
+// not run in parallel.
+
+func TestAnalyzeConversion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		toASM   bool
+		toHex   bool
+		wantHex string
+		wantASM string
+	}{
+		{"hex to asm", p2pkhHex, false, false, p2pkhHex, p2pkhASM},
+		{"uppercase hex to asm", "76A914751E76E8199196D454941C45D1B3A323F1433BD688AC", false, false, p2pkhHex, p2pkhASM},
+		{"asm to hex", p2pkhASM, false, false, p2pkhHex, p2pkhASM},
+		{"data push to hex", "OP_RETURN 68656c6c6f", false, false, "6a0568656c6c6f", "OP_RETURN 68656c6c6f"},
+		{"to-hex assembles a hex-looking push", "0068", false, true, "020068", "0068"},
+		{"to-asm disassembles", "51", true, false, "51", "OP_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toASM, toHex = tt.toASM, tt.toHex
+			t.Cleanup(func() { toASM, toHex = false, false })
+
+			r, err := analyze(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHex, r.Hex)
+			assert.Equal(t, tt.wantASM, r.ASM)
+			assert.Equal(t, len(tt.wantHex)/2, r.Size)
+		})
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		toASM   bool
+		wantErr string
+	}{
+		{"unknown opcode name", "OP_DUP OP_NOPE", false, "assembling script"},
+		{"odd-length hex", "abc", true, "decoding hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toASM = tt.toASM
+			t.Cleanup(func() { toASM = false })
+
+			_, err := analyze(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("undefined opcode byte", func(t *testing.T) {
+		r, err := analyze("51ba")
+		require.NoError(t, err)
+		require.Len(t, r.Issues, 1)
+		assert.Contains(t, r.Issues[0], "undefined opcode 0xba")
+	})
+
+	t.Run("truncated push", func(t *testing.T) {
+		r, err := analyze("0568")
+		require.NoError(t, err)
+		assert.NotEmpty(t, r.Issues)
+		assert.Empty(t, r.ASM)
+	})
+}
+
+func TestAnalyzeHashes(t *testing.T) {
+	r, err := analyze(p2pkhHex)
+	require.NoError(t, err)
+
+	assert.Equal(t, scripts.Hashes{
+		SHA256:     "3f4ae1eb3e75f3578491811d27eb59e23a512cf90c73b13c6acd4499f7c4d28b",
+		ScriptHash: "8bd2c4f79944cd6a3cb1730cf92c513ae259eb271d81918457f3753eebe14a3f",
+		Hash160:    "cd7b44d0b03f2d026d1e586d7ae18903b0d385f6",
+	}, r.Hashes)
+}
+
+func TestAnalyzeTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		testnet bool
+		want    scripts.TemplateInfo
+	}{
+		{
+			"p2pkh mainnet", p2pkhHex, false,
+			scripts.TemplateInfo{Type: scripts.TemplateP2PKH, Addresses: []string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"}},
+		},
+		{
+			"p2pkh testnet", p2pkhHex, true,
+			scripts.TemplateInfo{Type: scripts.TemplateP2PKH, Addresses: []string{"mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"}},
+		},
+		{
+			"p2sh", "OP_HASH160 751e76e8199196d454941c45d1b3a323f1433bd6 OP_EQUAL", false,
+			scripts.TemplateInfo{Type: scripts.TemplateP2SH, ScriptHash: "751e76e8199196d454941c45d1b3a323f1433bd6"},
+		},
+		{
+			"safe data", "OP_FALSE OP_RETURN 68656c6c6f 776f726c64", false,
+			scripts.TemplateInfo{Type: scripts.TemplateData, Safe: true, Data: []string{"68656c6c6f", "776f726c64"}},
+		},
+		{
+			"nonstandard", "OP_1 OP_ADD", false,
+			scripts.TemplateInfo{Type: scripts.TemplateNonStandard},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testnet = tt.testnet
+			t.Cleanup(func() { testnet = false })
+
+			r, err := analyze(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r.Template)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package main implements a Bitcoin SV message signer for proof-of-key-ownership workflows.
+//
+// This tool signs an arbitrary message with a WIF private key and outputs the
+// base64-encoded signature. Two signature formats are supported: the classic
+// Bitcoin Signed Message (BSM) compact signature, which can be verified against
+// an address, and BRC-77 signed messages, which embed the signer's identity key.
+//
+// Features:
+//   - Bitcoin Signed Message (BSM) signatures (default)
+//   - BRC-77 signatures for anyone or for a specific recipient public key
+//   - Respects the WIF compression flag for BSM signatures
+//   - Message from argument, flag, file, or stdin
+//   - JSON output support
+//
+// Usage:
+//
+//	signmsg -w <WIF> "hello world"              # Sign a message (BSM)
+//	signmsg -w <WIF> -f statement.txt           # Sign a file's contents
+//	echo -n "hello" | signmsg -w <WIF>          # Sign from stdin
+//	signmsg -w <WIF> --brc77 "hello"            # BRC-77 signature (anyone can verify)
+//	signmsg -w <WIF> --brc77 -r <pubkey> "hi"   # BRC-77 signature for a specific verifier
+//	signmsg -w <WIF> -j "hello"                 # JSON output
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	"github.com/bsv-blockchain/go-sdk/message"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+)
+
+// Signature formats
+const (
+	formatBSM   = "bsm"
+	formatBRC77 = "brc77"
+)
+
+// Command-line flags
+var (
+	wif        string // WIF private key for signing
+	msg        string // Message provided via flag
+	file       string // Path to a file whose contents are signed
+	brc77      bool   // Produce a BRC-77 signature instead of BSM
+	recipient  string // Optional BRC-77 verifier public key (hex)
+	jsonOutput bool   // Output in JSON format
+)
+
+// signResult holds the output of a signing operation.
+type signResult struct {
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey"`
+	Format    string `json:"format"`
+	Recipient string `json:"recipient,omitempty"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// rootCmd is the main cobra command for the signmsg tool.
+var rootCmd = &cobra.Command{
+	Use:   "signmsg [message]",
+	Short: "Sign a message with a BSV private key",
+	Long: `A command line tool that signs a message with a WIF private key, producing either
+a Bitcoin Signed Message (BSM) signature or a BRC-77 signed message.
+
+The message is taken from the argument, --message, --file, or stdin (in that order).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	if wif == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("--wif is required")
+	}
+
+	if recipient != "" && !brc77 {
+		return fmt.Errorf("--recipient can only be used with --brc77")
+	}
+
+	data, err := getMessage(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := signMessage(wif, data)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	fmt.Println(result.Signature)
+	return nil
+}
+
+// getMessage retrieves the message bytes from argument, flag, file, or stdin.
+// The message is returned exactly as provided; no whitespace is trimmed, since
+// any change to the bytes would invalidate the signature.
+func getMessage(args []string) ([]byte, error) {
+	if len(args) > 0 {
+		return []byte(args[0]), nil
+	}
+
+	if msg != "" {
+		return []byte(msg), nil
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading message file: %w", err)
+		}
+		return data, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading message from stdin: %w", err)
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("no message provided")
+}
+
+// signMessage signs data with the given WIF using the selected signature format.
+func signMessage(wifString string, data []byte) (*signResult, error) {
+	w, err := keys.ParseWIF(wifString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse WIF: %w", err)
+	}
+
+	addr, err := w.Address()
+	if err != nil {
+		return nil, fmt.Errorf("deriving address: %w", err)
+	}
+
+	result := &signResult{
+		Address:   addr.AddressString,
+		PublicKey: w.PublicKeyHex(),
+		Message:   string(data),
+	}
+
+	var sig []byte
+	if brc77 {
+		var verifier *ec.PublicKey
+		if recipient != "" {
+			verifier, err = ec.PublicKeyFromString(recipient)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient public key: %w", err)
+			}
+			result.Recipient = hex.EncodeToString(verifier.Compressed())
+		}
+
+		result.Format = formatBRC77
+		sig, err = message.Sign(data, w.Key, verifier)
+	} else {
+		result.Format = formatBSM
+		sig, err = bsm.SignMessageWithCompression(w.Key, data, w.Compressed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	result.Signature = base64.StdEncoding.EncodeToString(sig)
+	return result, nil
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key to sign with (required)")
+	rootCmd.Flags().StringVarP(&msg, "message", "m", "", "Message to sign")
+	rootCmd.Flags().StringVarP(&file, "file", "f", "", "Sign the contents of a file")
+	rootCmd.Flags().BoolVar(&brc77, "brc77", false, "Produce a BRC-77 signed message instead of BSM")
+	rootCmd.Flags().StringVarP(&recipient, "recipient", "r", "", "BRC-77 verifier public key in hex (default: anyone can verify)")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the signmsg command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	"github.com/bsv-blockchain/go-sdk/message"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Private key 1 in each WIF form, with its addresses and public key.
+const (
+	testWIF            = "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"
+	testAddr           = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	testPubKey         = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	uncompressedWIF    = "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+	uncompressedAddr   = "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"
+	uncompressedPubKey = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	testnetWIF         = "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"
+	testnetAddr        = "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"
+	otherAddr          = "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"
+)
+
+// decodeSignature decodes a base64 signature from a signResult.
+func decodeSignature(t *testing.T, result *signResult) []byte {
+	t.Helper()
+	sig, err := base64.StdEncoding.DecodeString(result.Signature)
+	require.NoError(t, err)
+	return sig
+}
+
+// The tests set the package-level flags signMessage and getMessage read, so
+// they must not run in parallel.
+
+func TestSignMessageBSM(t *testing.T) {
+	data := []byte("I own this key")
+
+	tests := []struct {
+		name   string
+		wif    string
+		addr   string
+		pubKey string
+		signer string // Mainnet address the signature recovers to
+	}{
+		{"compressed mainnet", testWIF, testAddr, testPubKey, testAddr},
+		{"uncompressed mainnet", uncompressedWIF, uncompressedAddr, uncompressedPubKey, uncompressedAddr},
+		{"compressed testnet", testnetWIF, testnetAddr, testPubKey, testAddr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := signMessage(tt.wif, data)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.addr, result.Address)
+			assert.Equal(t, tt.pubKey, result.PublicKey)
+			assert.Equal(t, formatBSM, result.Format)
+			assert.Equal(t, string(data), result.Message)
+
+			sig := decodeSignature(t, result)
+			require.NoError(t, bsm.VerifyMessage(tt.signer, sig, data))
+			require.Error(t, bsm.VerifyMessage(tt.signer, sig, []byte("tampered")))
+			require.Error(t, bsm.VerifyMessage(otherAddr, sig, data))
+		})
+	}
+}
+
+func TestSignMessageBRC77(t *testing.T) {
+	brc77 = true
+	t.Cleanup(func() { brc77, recipient = false, "" })
+
+	data := []byte("hello")
+
+	t.Run("anyone can verify", func(t *testing.T) {
+		result, err := signMessage(testWIF, data)
+		require.NoError(t, err)
+		assert.Equal(t, formatBRC77, result.Format)
+		assert.Empty(t, result.Recipient)
+
+		valid, err := message.Verify(data, decodeSignature(t, result), nil)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("for a recipient", func(t *testing.T) {
+		verifier, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+		recipient = strings.ToUpper(verifier.PubKey().ToDERHex())
+
+		result, err := signMessage(testWIF, data)
+		require.NoError(t, err)
+		assert.Equal(t, verifier.PubKey().ToDERHex(), result.Recipient)
+
+		valid, err := message.Verify(data, decodeSignature(t, result), verifier)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		recipient = "zz"
+		_, err := signMessage(testWIF, data)
+		require.ErrorContains(t, err, "invalid recipient public key")
+	})
+}
+
+func TestSignMessageInvalidWIF(t *testing.T) {
+	for _, key := range []string{"", "notawif", testAddr} {
+		_, err := signMessage(key, []byte("hello"))
+		require.ErrorContains(t, err, "failed to parse WIF", key)
+	}
+}
+
+func TestGetMessage(t *testing.T) {
+	t.Cleanup(func() { msg, file = "", "" })
+
+	// The trailing newline is part of the file, so it must be signed too
+	path := filepath.Join(t.TempDir(), "statement.txt")
+	require.NoError(t, os.WriteFile(path, []byte("statement of ownership\n"), 0o600))
+
+	t.Run("argument wins", func(t *testing.T) {
+		msg, file = "flag", path
+		data, err := getMessage([]string{"arg"})
+		require.NoError(t, err)
+		assert.Equal(t, "arg", string(data))
+	})
+
+	t.Run("message flag before file", func(t *testing.T) {
+		msg, file = "flag", path
+		data, err := getMessage(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "flag", string(data))
+	})
+
+	t.Run("file contents verbatim", func(t *testing.T) {
+		msg, file = "", path
+		data, err := getMessage(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "statement of ownership\n", string(data))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		msg, file = "", filepath.Join(t.TempDir(), "missing.txt")
+		_, err := getMessage(nil)
+		require.ErrorContains(t, err, "reading message file")
+	})
+}
@@ -0,0 +1,338 @@
+// Package main implements a UTXO fan-out utility for Bitcoin SV.
+//
+// This tool spends a WIF's UTXOs into many equal outputs, so a wallet can
+// fund that many transactions at once without them chaining on each other.
+// Outputs go back to the WIF's own address, or to addresses derived from an
+// extended public key. Large splits are chunked across chained transactions:
+// the change of each funds the next, and the last returns change to the WIF.
+//
+// Features:
+//   - N outputs of a fixed size, chunked at --per-tx outputs per transaction
+//   - Outputs to the WIF's address or to xpub-derived 0/i addresses
+//   - One UTXO selection funds the whole chain, with fees estimated per transaction
+//   - Spends from compressed or uncompressed WIF keys
+//   - Raw hex output, one transaction per line, or --broadcast in order
+//   - UTXOs and broadcasting through the providers in config.yaml
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	split -w <WIF> -n 100 -s 1000                   # 100 outputs of 1000 sats to the WIF's address
+//	split -w <WIF> -n 5000 -s 500 --per-tx 1000     # Five chained transactions
+//	split -w <WIF> -n 50 -s 2000 --xpub <xpub>      # Outputs to the xpub's 0/0 .. 0/49
+//	split -w <WIF> -n 100 -s 1000 --broadcast       # Build and broadcast in order
+//	split -w <WIF> -n 100 -s 1000 -j                # JSON with txids, fee, and hex
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Split limits
+const (
+	maxOutputs   = 100000 // Most outputs one split creates
+	maxPerTx     = 10000  // Most outputs per transaction
+	receiveChain = bip32.DefaultExternalChain
+)
+
+// Command-line flags
+var (
+	wif        string // WIF private key funding the split
+	count      int    // Number of outputs to create
+	sats       uint64 // Satoshis per output
+	perTx      int    // Most outputs per transaction
+	xpub       string // Extended public key whose 0/i addresses receive the outputs
+	testnet    bool   // Use testnet instead of mainnet
+	feePerKb   uint64 // Fee rate in satoshis per kilobyte
+	broadcast  bool   // Broadcast the transactions instead of printing them
+	jsonOutput bool   // Output in JSON format
+	debug      bool   // Enable verbose debug logging
+)
+
+// splitResult is a signed split and its summary.
+type splitResult struct {
+	Txs     []*transaction.Transaction `json:"-"`
+	TxIDs   []string                   `json:"txids"`
+	Outputs int                        `json:"outputs"`
+	Sats    uint64                     `json:"satsPerOutput"`
+	Fee     uint64                     `json:"fee"`
+	Change  uint64                     `json:"change"`        // Returned to the WIF by the last transaction
+	Hex     []string                   `json:"hex,omitempty"` // Without --broadcast
+}
+
+// rootCmd is the main cobra command for the split tool.
+var rootCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Fan a WIF's funds out into many equal UTXOs",
+	Long: `A command line tool that spends a WIF's UTXOs into --count outputs of --sats
+each, back to the WIF's address or to addresses derived from --xpub, chunked
+across chained transactions of at most --per-tx outputs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFlags(); err != nil {
+			return err
+		}
+		return run()
+	},
+}
+
+// validateFlags checks flag values and combinations.
+func validateFlags() error {
+	switch {
+	case wif == "":
+		return fmt.Errorf("--wif is required")
+	case count < 1 || count > maxOutputs:
+		return fmt.Errorf("--count must be between 1 and %d", maxOutputs)
+	case sats < 1:
+		return fmt.Errorf("--sats must be at least 1")
+	case perTx < 1 || perTx > maxPerTx:
+		return fmt.Errorf("--per-tx must be between 1 and %d", maxPerTx)
+	}
+	return nil
+}
+
+// run builds the split and prints or broadcasts it.
+func run() error {
+	w, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	source, err := keys.Address(w.Key.PubKey(), testnet, w.Compressed)
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	dests := []*script.Address{source}
+	if xpub != "" {
+		if dests, err = deriveAddresses(xpub, count, testnet); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider, Uncompressed: !w.Compressed}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	result, err := buildSplit(builder, w.Key, source, utxos, dests, count, sats, perTx)
+	if err != nil {
+		return err
+	}
+
+	if !broadcast {
+		for _, tx := range result.Txs {
+			result.Hex = append(result.Hex, tx.String())
+		}
+		if jsonOutput {
+			return encodeJSON(result)
+		}
+		for _, raw := range result.Hex {
+			fmt.Println(raw)
+		}
+		return nil
+	}
+
+	if err = broadcastAll(ctx, provider.Broadcaster, result.Txs); err != nil {
+		return err
+	}
+	if jsonOutput {
+		return encodeJSON(result)
+	}
+	fmt.Printf("✓ Split into %d outputs of %d satoshis across %d transaction(s)\n", result.Outputs, result.Sats, len(result.TxIDs))
+	for _, txid := range result.TxIDs {
+		fmt.Printf("  TxID: %s\n", txid)
+	}
+	fmt.Printf("  Fee:    %d satoshis\n", result.Fee)
+	fmt.Printf("  Change: %d satoshis\n", result.Change)
+	return nil
+}
+
+// deriveAddresses returns the addresses at 0/0 through 0/(n-1) of an extended
+// public key, which must be for the testnet network when testnet is set.
+func deriveAddresses(extended string, n int, testnet bool) ([]*script.Address, error) {
+	key, err := bip32.NewKeyFromString(extended)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --xpub: %w", err)
+	}
+	net, network := &chaincfg.MainNet, "mainnet"
+	if testnet {
+		net, network = &chaincfg.TestNet, "testnet"
+	}
+	if !key.IsForNet(net) {
+		return nil, fmt.Errorf("--xpub is not a %s key", network)
+	}
+
+	receive, err := key.Child(receiveChain)
+	if err != nil {
+		return nil, fmt.Errorf("deriving receive chain: %w", err)
+	}
+	addrs := make([]*script.Address, 0, n)
+	for i := range n {
+		child, err := receive.Child(uint32(i)) //nolint:gosec // n is at most maxOutputs
+		if err != nil {
+			return nil, fmt.Errorf("deriving %d/%d: %w", receiveChain, i, err)
+		}
+		pub, err := child.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("deriving %d/%d: %w", receiveChain, i, err)
+		}
+		addr, err := keys.Address(pub, testnet, true)
+		if err != nil {
+			return nil, fmt.Errorf("generating address %d/%d: %w", receiveChain, i, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// splitOutputs returns the n outputs of amount sats each, paying dests in
+// turn from index first.
+func splitOutputs(dests []*script.Address, first, n int, amount uint64) ([]*transaction.TransactionOutput, error) {
+	outputs := make([]*transaction.TransactionOutput, 0, n)
+	for i := first; i < first+n; i++ {
+		lock, err := p2pkh.Lock(dests[i%len(dests)])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create locking script: %w", err)
+		}
+		outputs = append(outputs, &transaction.TransactionOutput{Satoshis: amount, LockingScript: lock})
+	}
+	return outputs, nil
+}
+
+// buildSplit builds the signed transactions paying n outputs of amount sats
+// to dests in turn, at most per outputs in each. One UTXO selection funds the
+// chain: the change of each transaction funds the next, and the last
+// returns its change to source.
+func buildSplit(builder *txbuilder.Builder, key *ec.PrivateKey, source *script.Address, utxos []*txbuilder.UTXO, dests []*script.Address, n int, amount uint64, per int) (*splitResult, error) {
+	result := &splitResult{Outputs: n, Sats: amount}
+
+	var chunks [][]*transaction.TransactionOutput
+	var target uint64
+	for first := 0; first < n; first += per {
+		outputs, err := splitOutputs(dests, first, min(per, n-first), amount)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, outputs)
+		target += uint64(len(outputs))*amount + builder.EstimateFee(1, outputs)
+	}
+
+	selected, err := builder.SelectUTXOs(utxos, target)
+	if err != nil {
+		return nil, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	var funded uint64
+	for _, utxo := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: utxo, Key: key, Uncompressed: builder.Uncompressed})
+		funded += utxo.Value
+	}
+
+	for i, outputs := range chunks {
+		if len(inputs) == 0 {
+			return nil, fmt.Errorf("insufficient funds: nothing left to fund transaction %d of %d", i+1, len(chunks))
+		}
+		tx, err := builder.BuildOutputs(inputs, outputs, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build transaction %d of %d: %w", i+1, len(chunks), err)
+		}
+		result.Txs = append(result.Txs, tx)
+		result.TxIDs = append(result.TxIDs, tx.TxID().String())
+		inputs = changeInput(tx, len(outputs), key, builder.Uncompressed)
+	}
+
+	if len(inputs) > 0 {
+		result.Change = inputs[0].UTXO.Value
+	}
+	result.Fee = funded - uint64(n)*amount - result.Change
+	return result, nil
+}
+
+// changeInput returns the change output of tx, which follows its payments
+// outputs, as the input of the next transaction, or nil if tx has no change.
+func changeInput(tx *transaction.Transaction, payments int, key *ec.PrivateKey, uncompressed bool) []txbuilder.Input {
+	if len(tx.Outputs) <= payments {
+		return nil
+	}
+	last := len(tx.Outputs) - 1
+	utxo := &txbuilder.UTXO{TxHash: tx.TxID().String(), TxPos: uint32(last), Value: tx.Outputs[last].Satoshis} //nolint:gosec // output count fits in uint32
+	return []txbuilder.Input{{UTXO: utxo, Key: key, Uncompressed: uncompressed}}
+}
+
+// broadcastAll broadcasts the transactions in order, so each one's parent is
+// already known, drawing a progress bar on a terminal.
+func broadcastAll(ctx context.Context, broadcaster chain.Broadcaster, txs []*transaction.Transaction) error {
+	bar := cli.NewProgress("Broadcasting", len(txs))
+	defer bar.Finish()
+	for i, tx := range txs {
+		if _, err := broadcaster.Broadcast(ctx, tx.String()); err != nil {
+			return fmt.Errorf("broadcasting transaction %d of %d (%d already broadcast): %w", i+1, len(txs), i, err)
+		}
+		bar.Add(1)
+	}
+	return nil
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key funding the split (required)")
+	rootCmd.Flags().IntVarP(&count, "count", "n", 0, fmt.Sprintf("Number of outputs to create (1-%d, required)", maxOutputs))
+	rootCmd.Flags().VarP((*cli.Amount)(&sats), "sats", "s", "Amount per output, in satoshis or with a unit like 1500sat or 0.001bsv (required)")
+	rootCmd.Flags().IntVar(&perTx, "per-tx", 1000, fmt.Sprintf("Most outputs per transaction (1-%d)", maxPerTx))
+	rootCmd.Flags().StringVar(&xpub, "xpub", "", "Send the outputs to this extended public key's 0/i addresses instead of the WIF's")
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	rootCmd.Flags().BoolVar(&broadcast, "broadcast", false, "Broadcast the transactions in order instead of printing them")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the split command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
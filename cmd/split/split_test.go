@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// testXPub is BIP32 test vector 1's m/0H public key.
+const testXPub = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+
+func TestBuildSplit(t *testing.T) {
+	t.Parallel()
+
+	key, source := chaintest.Source(t)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 100000}}
+
+	t.Run("chains transactions through their change", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := buildSplit(&txbuilder.Builder{FeePerKb: 100}, key, source, utxos, []*script.Address{source}, 25, 1000, 10)
+		require.NoError(t, err)
+		require.Len(t, result.Txs, 3)
+		assert.Len(t, result.TxIDs, 3)
+
+		var paid uint64
+		for i, tx := range result.Txs {
+			payments := []int{10, 10, 5}[i]
+			require.Len(t, tx.Outputs, payments+1, "payments and change")
+			for _, out := range tx.Outputs[:payments] {
+				assert.Equal(t, uint64(1000), out.Satoshis)
+				paid += out.Satoshis
+			}
+			if i > 0 {
+				parent := result.Txs[i-1]
+				assert.Equal(t, parent.TxID().String(), tx.Inputs[0].SourceTXID.String())
+				assert.Equal(t, uint32(len(parent.Outputs)-1), tx.Inputs[0].SourceTxOutIndex)
+			}
+		}
+		assert.Equal(t, uint64(25000), paid)
+		assert.Equal(t, result.Txs[2].Outputs[5].Satoshis, result.Change)
+		assert.Equal(t, uint64(100000), paid+result.Fee+result.Change)
+	})
+
+	t.Run("pays derived addresses in turn", func(t *testing.T) {
+		t.Parallel()
+
+		dests, err := deriveAddresses(testXPub, 2, false)
+		require.NoError(t, err)
+		require.Len(t, dests, 2)
+		assert.NotEqual(t, dests[0].AddressString, dests[1].AddressString)
+
+		result, err := buildSplit(&txbuilder.Builder{FeePerKb: 100}, key, source, utxos, dests, 2, 1000, 10)
+		require.NoError(t, err)
+		require.Len(t, result.Txs, 1)
+		for i, want := range dests {
+			addr, err := result.Txs[0].Outputs[i].LockingScript.Address()
+			require.NoError(t, err)
+			assert.Equal(t, want.AddressString, addr.AddressString)
+		}
+	})
+
+	t.Run("fails without enough funds", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := buildSplit(&txbuilder.Builder{FeePerKb: 100}, key, source, utxos, []*script.Address{source}, 200, 1000, 50)
+		require.ErrorContains(t, err, "insufficient funds")
+	})
+}
+
+func TestDeriveAddresses(t *testing.T) {
+	t.Parallel()
+
+	_, err := deriveAddresses(testXPub, 1, true)
+	require.ErrorContains(t, err, "not a testnet key")
+
+	_, err = deriveAddresses("xpub-not-a-key", 1, false)
+	require.ErrorContains(t, err, "failed to parse --xpub")
+}
+
+// recordingBroadcaster records broadcasts, failing from the fail-th one.
+type recordingBroadcaster struct {
+	sent []string
+	fail int
+}
+
+func (r *recordingBroadcaster) Broadcast(_ context.Context, rawTx string) (*chain.BroadcastResult, error) {
+	if r.fail > 0 && len(r.sent)+1 >= r.fail {
+		return nil, errors.New("rejected")
+	}
+	r.sent = append(r.sent, rawTx)
+	return &chain.BroadcastResult{Status: chain.StatusAccepted}, nil
+}
+
+func TestBroadcastAll(t *testing.T) {
+	t.Parallel()
+
+	txs := []*transaction.Transaction{transaction.NewTransaction(), transaction.NewTransaction(), transaction.NewTransaction()}
+	txs[1].LockTime = 1
+	txs[2].LockTime = 2
+
+	ok := &recordingBroadcaster{}
+	require.NoError(t, broadcastAll(context.Background(), ok, txs))
+	assert.Equal(t, []string{txs[0].String(), txs[1].String(), txs[2].String()}, ok.sent)
+
+	failing := &recordingBroadcaster{fail: 2}
+	err := broadcastAll(context.Background(), failing, txs)
+	require.ErrorContains(t, err, "broadcasting transaction 2 of 3 (1 already broadcast): rejected")
+	assert.Len(t, failing.sent, 1)
+}
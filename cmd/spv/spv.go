@@ -0,0 +1,622 @@
+// Package main implements a Bitcoin SV merkle proof (SPV) verifier.
+//
+// This tool verifies that a transaction is included in a block without trusting
+// a single API response. It fetches (or accepts) the transaction's merkle proof,
+// recomputes the merkle root, rebuilds and hashes the block header, checks the
+// header's proof of work, and walks the following headers to confirm they link
+// to it and to measure the work built on top of it.
+//
+// Features:
+//   - TSC merkle proofs fetched from WhatsOnChain or loaded from a file
+//   - BUMP (BRC-74) merkle paths via --bump
+//   - Header proof-of-work and chain continuity checks
+//   - Accumulated chain work over the verified depth
+//   - Mainnet/testnet support
+//   - Non-zero exit code when verification fails
+//   - JSON output support
+//
+// Usage:
+//
+//	spv <txid>                          # Fetch proof and header, verify
+//	spv <txid> -d 12                    # Require 12 linked headers on top
+//	spv <txid> --proof proof.json       # Verify a saved TSC proof
+//	spv <txid> --bump <hex>             # Verify a BUMP merkle path
+//	spv <txid> --header <80-byte hex>   # Supply the block header yourself
+//	txstatus <txid> -m && spv <txid>    # Verify once txstatus reports it mined
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/headers"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Command-line flags
+var (
+	testnet    bool   // Use testnet instead of mainnet
+	txid       string // Transaction ID provided via flag
+	proofFile  string // Path to a TSC merkle proof (JSON)
+	bumpHex    string // BUMP (BRC-74) merkle path in hex
+	headerHex  string // Raw 80-byte block header in hex
+	depth      int    // Number of following headers that must link to the block
+	local      bool   // Verify against the local header store instead of fetched headers
+	storePath  string // Local header store file (default: headers tool default)
+	jsonOutput bool   // Output in JSON format
+	noColor    bool   // Disable colored output
+)
+
+// tscProof is a TSC merkle proof as returned by WhatsOnChain.
+type tscProof struct {
+	Index  int      `json:"index"`
+	Nodes  []string `json:"nodes"`
+	Target string   `json:"target"`
+	TxOrID string   `json:"txOrId"`
+}
+
+// spvResult holds the outcome of an SPV verification.
+type spvResult struct {
+	TxID          string   `json:"txid"`
+	BlockHash     string   `json:"blockHash,omitempty"`
+	BlockHeight   int64    `json:"blockHeight,omitempty"`
+	MerkleRoot    string   `json:"merkleRoot,omitempty"`
+	ComputedRoot  string   `json:"computedRoot,omitempty"`
+	ProofValid    bool     `json:"proofValid"`
+	HeaderValid   bool     `json:"headerValid"`
+	ChainValid    bool     `json:"chainValid"`
+	ChainDepth    int      `json:"chainDepth"`
+	ChainWork     string   `json:"chainWork,omitempty"`
+	Confirmations int64    `json:"confirmations,omitempty"`
+	Valid         bool     `json:"valid"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// rootCmd is the main cobra command for the spv tool.
+var rootCmd = &cobra.Command{
+	Use:   "spv [txid]",
+	Short: "Verify a transaction's merkle proof against the header chain",
+	Long: `A command line tool that verifies a transaction's merkle proof, recomputes the
+merkle root, checks the containing block header's proof of work, and confirms that
+the following headers link to it. Exits non-zero if any check fails.
+
+The proof is fetched from WhatsOnChain unless --proof or --bump is given. The block
+header is fetched unless --header is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	transactionID, err := getTransactionID(args)
+	if err != nil {
+		return err
+	}
+
+	if transactionID == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no txid provided")
+	}
+
+	if len(transactionID) != 64 || !cli.IsValidHex(transactionID) {
+		return fmt.Errorf("invalid txid: %s", transactionID)
+	}
+
+	if proofFile != "" && bumpHex != "" {
+		return fmt.Errorf("--proof and --bump are mutually exclusive")
+	}
+
+	if depth < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain serves TSC proofs and linked block info, whatever the data provider
+	client := provider.WOC.Client
+	var store *headers.Store
+	if local {
+		if store, err = openStore(); err != nil {
+			return err
+		}
+	}
+
+	result := verify(ctx, client, store, transactionID)
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		printResult(result)
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("SPV verification failed")
+	}
+	return nil
+}
+
+// getTransactionID retrieves the transaction ID from argument, flag, or stdin.
+func getTransactionID(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	if txid != "" {
+		return txid, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// openStore opens the local header store used with --local.
+func openStore() (*headers.Store, error) {
+	path := storePath
+	if path == "" {
+		var err error
+		if path, err = headers.DefaultPath(testnet); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := headers.Open(path, testnet)
+	if err != nil {
+		return nil, err
+	}
+	if store.Len() == 0 {
+		return nil, fmt.Errorf("%w (%s): run 'headers sync' first", headers.ErrEmpty, path)
+	}
+	return store, nil
+}
+
+// verify runs every SPV check and collects the results.
+// When store is non-nil, the block header and chain come from the local store
+// instead of WhatsOnChain. Each failed check is recorded in Errors; later checks
+// still run where possible.
+func verify(ctx context.Context, client whatsonchain.ClientInterface, store *headers.Store, transactionID string) *spvResult {
+	result := &spvResult{TxID: transactionID}
+	fail := func(format string, a ...any) *spvResult {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, a...))
+		return result
+	}
+
+	// Step 1: compute the merkle root from the proof, and find which block it targets
+	computed, blockRef, err := computeProofRoot(ctx, client, transactionID)
+	if err != nil {
+		return fail("merkle proof: %v", err)
+	}
+	result.ComputedRoot = computed.String()
+
+	if store != nil {
+		return verifyWithStore(result, store, computed, blockRef, depth)
+	}
+
+	// Step 2: obtain the block header and its metadata
+	header, info, err := resolveHeader(ctx, client, blockRef)
+	if err != nil {
+		return fail("block header: %v", err)
+	}
+	blockHash := header.Hash()
+	result.BlockHash = blockHash.String()
+	result.MerkleRoot = header.MerkleRoot.String()
+	if info != nil {
+		result.BlockHeight = info.Height
+		result.Confirmations = info.Confirmations
+	}
+
+	// Step 3: the proof must commit to the header's merkle root
+	result.ProofValid = computed.IsEqual(&header.MerkleRoot)
+	if !result.ProofValid {
+		fail("computed merkle root %s does not match header merkle root %s", result.ComputedRoot, result.MerkleRoot)
+	}
+
+	// Step 4: the header must carry valid proof of work
+	if err = headers.CheckProofOfWork(header); err != nil {
+		fail("header: %v", err)
+	} else {
+		result.HeaderValid = true
+	}
+
+	// Step 5: the following headers must link back to this block
+	work, linked, err := verifyChain(ctx, client, header, info, depth)
+	result.ChainDepth = linked
+	if work != nil {
+		result.ChainWork = work.Text(16)
+	}
+	if err != nil {
+		fail("header chain: %v", err)
+	} else {
+		result.ChainValid = true
+	}
+
+	result.Valid = result.ProofValid && result.HeaderValid && result.ChainValid
+	return result
+}
+
+// verifyWithStore checks a computed merkle root against the local header store.
+// Stored headers were verified when they were synced, so the chain check only
+// needs the store to extend want headers past the block.
+func verifyWithStore(result *spvResult, store *headers.Store, computed *chainhash.Hash, ref blockReference, want int) *spvResult {
+	height, err := storeHeight(store, ref)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("local headers: %v", err))
+		return result
+	}
+
+	header, err := store.Header(height)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("local headers: %v", err))
+		return result
+	}
+	blockHash := header.Hash()
+	result.BlockHash = blockHash.String()
+	result.BlockHeight = int64(height)
+	result.MerkleRoot = header.MerkleRoot.String()
+
+	tipHeight, err := store.TipHeight()
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("local headers: %v", err))
+		return result
+	}
+	result.Confirmations = int64(tipHeight-height) + 1
+
+	result.ProofValid = computed.IsEqual(&header.MerkleRoot)
+	if !result.ProofValid {
+		result.Errors = append(result.Errors, fmt.Sprintf("computed merkle root %s does not match header merkle root %s", result.ComputedRoot, result.MerkleRoot))
+	}
+
+	if err = headers.CheckProofOfWork(header); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("header: %v", err))
+	} else {
+		result.HeaderValid = true
+	}
+
+	result.ChainDepth = min(want, int(tipHeight-height))
+	work, err := store.ChainWork(height, height+uint32(result.ChainDepth)) //nolint:gosec // depth is non-negative
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("header chain: %v", err))
+	} else {
+		result.ChainWork = work.Text(16)
+	}
+	if result.ChainDepth < want {
+		result.Errors = append(result.Errors, fmt.Sprintf("header chain: local store has %d header(s) on top, need %d (run 'headers sync')", result.ChainDepth, want))
+	} else if err == nil {
+		result.ChainValid = true
+	}
+
+	result.Valid = result.ProofValid && result.HeaderValid && result.ChainValid
+	return result
+}
+
+// storeHeight finds the stored height of the block a proof commits to.
+func storeHeight(store *headers.Store, ref blockReference) (uint32, error) {
+	raw := headerHex
+	if raw == "" {
+		raw = ref.header
+	}
+
+	var hashHex string
+	switch {
+	case raw != "":
+		header, err := block.NewHeaderFromHex(strings.TrimSpace(raw))
+		if err != nil {
+			return 0, err
+		}
+		hashHex = header.Hash().String()
+	case ref.hash != "":
+		hashHex = ref.hash
+	default:
+		// BUMP proofs identify the block by height only
+		height := uint32(ref.height) //nolint:gosec // BUMP heights are 32-bit
+		if !store.Has(height) {
+			return 0, fmt.Errorf("height %d is not in the local store (run 'headers sync')", height)
+		}
+		return height, nil
+	}
+
+	hash, err := chainhash.NewHashFromHex(hashHex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block hash: %w", err)
+	}
+	height, ok := store.HeightOf(*hash)
+	if !ok {
+		return 0, fmt.Errorf("block %s is not in the local store (run 'headers sync')", hashHex)
+	}
+	return height, nil
+}
+
+// blockReference identifies the block a proof commits to, by hash, height, or raw header.
+type blockReference struct {
+	hash   string
+	height int64
+	header string
+}
+
+// computeProofRoot loads or fetches the merkle proof and computes the merkle root.
+func computeProofRoot(ctx context.Context, client whatsonchain.ClientInterface, transactionID string) (*chainhash.Hash, blockReference, error) {
+	if bumpHex != "" {
+		mp, err := spv.ParseBUMP(bumpHex)
+		if err != nil {
+			return nil, blockReference{}, err
+		}
+		root, err := spv.BUMPRoot(mp, transactionID)
+		if err != nil {
+			return nil, blockReference{}, err
+		}
+		return root, blockReference{height: int64(mp.BlockHeight)}, nil
+	}
+
+	proof, err := loadTSCProof(ctx, client, transactionID)
+	if err != nil {
+		return nil, blockReference{}, err
+	}
+
+	if proof.TxOrID != "" && len(proof.TxOrID) == 64 && !strings.EqualFold(proof.TxOrID, transactionID) {
+		return nil, blockReference{}, fmt.Errorf("proof is for txid %s", proof.TxOrID)
+	}
+
+	root, err := spv.TSCRoot(transactionID, proof.Index, proof.Nodes)
+	if err != nil {
+		return nil, blockReference{}, err
+	}
+
+	ref := blockReference{hash: proof.Target}
+	if len(proof.Target) == block.HeaderSize*2 {
+		ref = blockReference{header: proof.Target}
+	}
+	return root, ref, nil
+}
+
+// loadTSCProof reads a TSC proof from --proof, or fetches it from WhatsOnChain.
+func loadTSCProof(ctx context.Context, client whatsonchain.ClientInterface, transactionID string) (*tscProof, error) {
+	if proofFile != "" {
+		data, err := os.ReadFile(proofFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading proof file: %w", err)
+		}
+		return parseTSCProof(data)
+	}
+
+	results, err := client.GetMerkleProofTSC(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching proof: %w", err)
+	}
+	if len(results) == 0 || results[0] == nil {
+		return nil, fmt.Errorf("no proof available (transaction may be unconfirmed)")
+	}
+
+	return &tscProof{
+		Index:  results[0].Index,
+		Nodes:  results[0].Nodes,
+		Target: results[0].Target,
+		TxOrID: results[0].TxOrID,
+	}, nil
+}
+
+// parseTSCProof decodes a TSC proof given either as an object or a single-element array.
+func parseTSCProof(data []byte) (*tscProof, error) {
+	var proof tscProof
+	if err := json.Unmarshal(data, &proof); err == nil {
+		return &proof, nil
+	}
+
+	var proofs []tscProof
+	if err := json.Unmarshal(data, &proofs); err != nil {
+		return nil, fmt.Errorf("invalid TSC proof JSON: %w", err)
+	}
+	if len(proofs) == 0 {
+		return nil, fmt.Errorf("proof file contains no proofs")
+	}
+	return &proofs[0], nil
+}
+
+// resolveHeader obtains the block header for a proof's block reference.
+// Fetched headers are rebuilt from their fields and must hash to the claimed block hash.
+func resolveHeader(ctx context.Context, client whatsonchain.ClientInterface, ref blockReference) (*block.Header, *whatsonchain.BlockInfo, error) {
+	// A supplied header is trusted as the anchor; metadata is looked up best-effort
+	raw := headerHex
+	if raw == "" {
+		raw = ref.header
+	}
+	if raw != "" {
+		header, err := block.NewHeaderFromHex(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, nil, err
+		}
+		hash := header.Hash()
+		info, _ := client.GetHeaderByHash(ctx, hash.String())
+		return header, info, nil
+	}
+
+	var info *whatsonchain.BlockInfo
+	var err error
+	switch {
+	case ref.hash != "":
+		info, err = client.GetHeaderByHash(ctx, ref.hash)
+	case ref.height > 0:
+		info, err = client.GetBlockByHeight(ctx, ref.height)
+	default:
+		return nil, nil, fmt.Errorf("proof does not identify a block")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching header: %w", err)
+	}
+
+	header, err := headers.HeaderFromBlockInfo(info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, info, nil
+}
+
+// verifyChain walks up to want headers after the anchor, checking that each one
+// links to its predecessor and has valid proof of work. It returns the total work
+// of the anchor plus the verified headers and the number of headers linked.
+func verifyChain(ctx context.Context, client whatsonchain.ClientInterface, anchor *block.Header, anchorInfo *whatsonchain.BlockInfo, want int) (*big.Int, int, error) {
+	total, err := headers.Work(anchor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if want == 0 {
+		return total, 0, nil
+	}
+
+	if anchorInfo == nil {
+		return total, 0, fmt.Errorf("block is not known to the header source")
+	}
+	if anchorInfo.Confirmations <= 0 {
+		return total, 0, fmt.Errorf("block is not on the best chain")
+	}
+	if anchorInfo.Confirmations <= int64(want) {
+		return total, 0, fmt.Errorf("only %d confirmation(s), need %d headers on top", anchorInfo.Confirmations, want)
+	}
+
+	prev := anchor
+	next := anchorInfo.NextBlockHash
+	for linked := 0; linked < want; linked++ {
+		if next == "" {
+			return total, linked, fmt.Errorf("chain ends after %d header(s)", linked)
+		}
+
+		info, err := client.GetHeaderByHash(ctx, next)
+		if err != nil {
+			return total, linked, fmt.Errorf("fetching header %s: %w", next, err)
+		}
+
+		header, err := headers.HeaderFromBlockInfo(info)
+		if err != nil {
+			return total, linked, err
+		}
+		if !header.PrevHash.IsEqual(ptr(prev.Hash())) {
+			return total, linked, fmt.Errorf("header %s does not link to %s", info.Hash, prev.Hash().String())
+		}
+		if err = headers.CheckProofOfWork(header); err != nil {
+			return total, linked, fmt.Errorf("header %s: %w", info.Hash, err)
+		}
+
+		work, err := headers.Work(header)
+		if err != nil {
+			return total, linked, err
+		}
+		total.Add(total, work)
+
+		prev = header
+		next = info.NextBlockHash
+	}
+
+	return total, want, nil
+}
+
+// ptr returns a pointer to a copy of a hash.
+func ptr(h chainhash.Hash) *chainhash.Hash {
+	return &h
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// check renders a pass/fail marker.
+func check(ok bool) string {
+	if ok {
+		return c(colorGreen, "✓")
+	}
+	return c(colorRed, "✗")
+}
+
+// printResult prints a human-readable verification report.
+func printResult(r *spvResult) {
+	fmt.Printf("%s %s\n", c(colorDim, "Transaction:"), r.TxID)
+	if r.BlockHash != "" {
+		fmt.Printf("%s %s\n", c(colorDim, "Block:"), r.BlockHash)
+	}
+	if r.BlockHeight > 0 {
+		fmt.Printf("%s %d (%d confirmations)\n", c(colorDim, "Height:"), r.BlockHeight, r.Confirmations)
+	}
+	if r.MerkleRoot != "" {
+		fmt.Printf("%s %s\n", c(colorDim, "Merkle Root:"), r.MerkleRoot)
+	}
+	fmt.Println()
+
+	fmt.Printf("%s Merkle proof\n", check(r.ProofValid))
+	fmt.Printf("%s Header proof of work\n", check(r.HeaderValid))
+	fmt.Printf("%s Header chain (%d/%d linked)\n", check(r.ChainValid), r.ChainDepth, depth)
+	if r.ChainWork != "" {
+		fmt.Printf("  %s 0x%s\n", c(colorDim, "Work:"), r.ChainWork)
+	}
+
+	for _, e := range r.Errors {
+		fmt.Printf("  %s\n", c(colorRed, e))
+	}
+
+	fmt.Println()
+	if r.Valid {
+		fmt.Println(c(colorGreen, "✓ Transaction is confirmed (SPV valid)"))
+	} else {
+		fmt.Println(c(colorRed, "✗ SPV verification failed"))
+	}
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().StringVarP(&txid, "txid", "i", "", "Transaction ID to verify")
+	rootCmd.Flags().StringVarP(&proofFile, "proof", "p", "", "Path to a TSC merkle proof (JSON) instead of fetching it")
+	rootCmd.Flags().StringVar(&bumpHex, "bump", "", "BUMP (BRC-74) merkle path in hex instead of fetching a proof")
+	rootCmd.Flags().StringVar(&headerHex, "header", "", "Raw 80-byte block header in hex instead of fetching it")
+	rootCmd.Flags().IntVarP(&depth, "depth", "d", 6, "Number of following headers that must link to the block")
+	rootCmd.Flags().BoolVarP(&local, "local", "l", false, "Verify against the local header store (see the headers tool)")
+	rootCmd.Flags().StringVar(&storePath, "headers-file", "", "Local header store file (default: ~/.bsv-cmd-line-utils/headers/<network>.bin)")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the spv command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
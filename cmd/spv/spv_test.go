@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/headers"
+)
+
+const genesisCoinbase = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// genesisInfo and block1Info are the first two mainnet headers as WhatsOnChain reports them.
+func genesisInfo() *whatsonchain.BlockInfo {
+	return &whatsonchain.BlockInfo{
+		Hash:          "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+		Height:        0,
+		Version:       1,
+		MerkleRoot:    genesisCoinbase,
+		Time:          1231006505,
+		Bits:          "1d00ffff",
+		Nonce:         2083236893,
+		Confirmations: 900000,
+		NextBlockHash: "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+	}
+}
+
+func block1Info() *whatsonchain.BlockInfo {
+	return &whatsonchain.BlockInfo{
+		Hash:              "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+		Height:            1,
+		Version:           1,
+		PreviousBlockHash: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+		MerkleRoot:        "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098",
+		Time:              1231469665,
+		Bits:              "1d00ffff",
+		Nonce:             2573394689,
+		Confirmations:     899999,
+	}
+}
+
+// headerClient serves headers by hash; all other client methods are unimplemented.
+type headerClient struct {
+	whatsonchain.ClientInterface
+
+	headers map[string]*whatsonchain.BlockInfo
+}
+
+func (h *headerClient) GetHeaderByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	if info, ok := h.headers[hash]; ok {
+		return info, nil
+	}
+	return nil, fmt.Errorf("header %s not found", hash)
+}
+
+// mustHash parses a display-order hash.
+func mustHash(t *testing.T, s string) *chainhash.Hash {
+	t.Helper()
+	h, err := chainhash.NewHashFromHex(s)
+	require.NoError(t, err)
+	return h
+}
+
+func TestParseTSCProof(t *testing.T) {
+	t.Parallel()
+
+	proof, err := parseTSCProof([]byte(`{"index":3,"nodes":["*"],"target":"abcd"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, proof.Index)
+	assert.Equal(t, []string{"*"}, proof.Nodes)
+
+	proof, err = parseTSCProof([]byte(`[{"index":1,"nodes":[],"target":"ef"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, proof.Index)
+	assert.Equal(t, "ef", proof.Target)
+
+	_, err = parseTSCProof([]byte(`[]`))
+	require.Error(t, err)
+
+	_, err = parseTSCProof([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestVerifyChain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	anchor, err := headers.HeaderFromBlockInfo(genesisInfo())
+	require.NoError(t, err)
+
+	t.Run("links to next header", func(t *testing.T) {
+		t.Parallel()
+		client := &headerClient{headers: map[string]*whatsonchain.BlockInfo{block1Info().Hash: block1Info()}}
+
+		work, linked, err := verifyChain(ctx, client, anchor, genesisInfo(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, linked)
+		assert.Equal(t, "200020002", work.Text(16))
+	})
+
+	t.Run("depth zero skips lookups", func(t *testing.T) {
+		t.Parallel()
+		work, linked, err := verifyChain(ctx, &headerClient{}, anchor, nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 0, linked)
+		assert.Equal(t, "100010001", work.Text(16))
+	})
+
+	t.Run("chain ends early", func(t *testing.T) {
+		t.Parallel()
+		client := &headerClient{headers: map[string]*whatsonchain.BlockInfo{block1Info().Hash: block1Info()}}
+
+		_, linked, err := verifyChain(ctx, client, anchor, genesisInfo(), 2)
+		require.Error(t, err)
+		assert.Equal(t, 1, linked)
+		assert.Contains(t, err.Error(), "chain ends")
+	})
+
+	t.Run("not enough confirmations", func(t *testing.T) {
+		t.Parallel()
+		info := genesisInfo()
+		info.Confirmations = 3
+
+		_, _, err := verifyChain(ctx, &headerClient{}, anchor, info, 6)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 3 confirmation(s)")
+	})
+
+	t.Run("orphaned block", func(t *testing.T) {
+		t.Parallel()
+		info := genesisInfo()
+		info.Confirmations = -1
+
+		_, _, err := verifyChain(ctx, &headerClient{}, anchor, info, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not on the best chain")
+	})
+
+	t.Run("broken link", func(t *testing.T) {
+		t.Parallel()
+		other := *anchor
+		other.Timestamp++
+		client := &headerClient{headers: map[string]*whatsonchain.BlockInfo{block1Info().Hash: block1Info()}}
+
+		_, linked, err := verifyChain(ctx, client, &other, genesisInfo(), 1)
+		require.Error(t, err)
+		assert.Equal(t, 0, linked)
+		assert.Contains(t, err.Error(), "does not link")
+	})
+}
+
+func TestResolveHeaderFromRawHeader(t *testing.T) {
+	t.Parallel()
+
+	header, err := block.NewHeaderFromHex("0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c")
+	require.NoError(t, err)
+
+	got, info, err := resolveHeader(context.Background(), &headerClient{}, blockReference{header: header.Hex()})
+	require.NoError(t, err)
+	assert.Nil(t, info)
+	assert.Equal(t, header.Hash(), got.Hash())
+}
+
+// newTestStore creates a local header store holding genesis and block 1.
+func newTestStore(t *testing.T) *headers.Store {
+	t.Helper()
+
+	store, err := headers.Open(filepath.Join(t.TempDir(), "mainnet.bin"), false)
+	require.NoError(t, err)
+
+	genesis, err := headers.HeaderFromBlockInfo(genesisInfo())
+	require.NoError(t, err)
+	block1, err := headers.HeaderFromBlockInfo(block1Info())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Init(genesis, 0))
+	require.NoError(t, store.Append([]*block.Header{block1}))
+	return store
+}
+
+func TestVerifyWithStore(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	root := mustHash(t, genesisCoinbase)
+
+	t.Run("valid with one header on top", func(t *testing.T) {
+		t.Parallel()
+		result := &spvResult{TxID: genesisCoinbase}
+		verifyWithStore(result, store, root, blockReference{hash: genesisInfo().Hash}, 1)
+
+		assert.True(t, result.Valid, result.Errors)
+		assert.Equal(t, int64(2), result.Confirmations)
+		assert.Equal(t, 1, result.ChainDepth)
+		assert.Equal(t, "200020002", result.ChainWork)
+	})
+
+	t.Run("wrong merkle root", func(t *testing.T) {
+		t.Parallel()
+		result := &spvResult{TxID: genesisCoinbase}
+		verifyWithStore(result, store, root, blockReference{hash: block1Info().Hash}, 0)
+
+		assert.False(t, result.Valid)
+		assert.False(t, result.ProofValid)
+	})
+
+	t.Run("unknown block", func(t *testing.T) {
+		t.Parallel()
+		result := &spvResult{TxID: genesisCoinbase}
+		verifyWithStore(result, store, root, blockReference{height: 5}, 1)
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0], "not in the local store")
+	})
+
+	t.Run("not enough headers on top", func(t *testing.T) {
+		t.Parallel()
+		result := &spvResult{TxID: genesisCoinbase}
+		verifyWithStore(result, store, root, blockReference{hash: genesisInfo().Hash}, 6)
+
+		assert.True(t, result.ProofValid)
+		assert.False(t, result.ChainValid)
+		assert.Equal(t, 1, result.ChainDepth)
+	})
+}
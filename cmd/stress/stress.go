@@ -0,0 +1,481 @@
+// Package main implements a testnet transaction throughput generator.
+//
+// This tool load-tests ARC endpoints and the infrastructure behind them. It
+// splits one funding UTXO into a number of independent chains, then builds and
+// broadcasts transactions at a target rate, each spending the previous
+// transaction of its chain. Live statistics report accepted and rejected
+// transactions and broadcast latency percentiles.
+//
+// Features:
+//   - Testnet only; mainnet is never used
+//   - Pre-split of the largest funding UTXO into parallel chains
+//   - Funding UTXOs from the data provider in config.yaml
+//   - Paced broadcasting at a target TPS across the chains
+//   - Stops after a duration, a transaction count, or Ctrl-C
+//   - Live statistics with p50/p90/p99 broadcast latency
+//   - JSON summary output support
+//
+// Usage:
+//
+//	stress -w <testnet WIF>                        # 10 TPS for 60 seconds over 10 chains
+//	stress -w <WIF> --tps 50 -c 100 -d 5m          # 50 TPS over 100 chains for 5 minutes
+//	stress -w <WIF> -n 1000                        # Stop after 1000 transactions
+//	stress -w <WIF> -j > run.json                  # JSON summary
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	chaindata "github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/config"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Command-line flags
+var (
+	wif        string        // Testnet WIF private key funding the run
+	tps        float64       // Target transactions per second
+	duration   time.Duration // How long to generate load (0 for no limit)
+	count      int           // Stop after this many transactions (0 for no limit)
+	numChains  int           // Number of parallel transaction chains
+	feePerKb   uint64        // Fee rate in satoshis per kilobyte
+	jsonOutput bool          // Output the final summary in JSON format
+	quiet      bool          // Suppress the live statistics line
+	debug      bool          // Enable verbose debug logging
+)
+
+// broadcaster submits raw transactions; satisfied by *arc.ARCClient.
+type broadcaster interface {
+	BroadcastTransaction(rawTx string) (*arc.TransactionResponse, error)
+}
+
+// outcome classifies a broadcast.
+type outcome int
+
+// Broadcast outcomes
+const (
+	accepted outcome = iota // ARC accepted the transaction
+	rejected                // ARC refused the transaction
+	failed                  // The request itself failed
+)
+
+// chain is a sequence of transactions, each spending the previous one's only output.
+type chain struct {
+	utxo *txbuilder.UTXO // Output the next transaction spends
+}
+
+// stats collects broadcast results. It is safe for concurrent use.
+type stats struct {
+	mu        sync.Mutex
+	start     time.Time
+	accepted  int
+	rejected  int
+	errors    int
+	exhausted int // Chains retired because their output could not pay another fee
+	skipped   int // Ticks with no idle chain to send from
+	latencies []time.Duration
+	lastError string
+}
+
+// summary is a point-in-time view of the statistics.
+type summary struct {
+	Elapsed   float64 `json:"elapsedSeconds"`
+	Sent      int     `json:"sent"`
+	Accepted  int     `json:"accepted"`
+	Rejected  int     `json:"rejected"`
+	Errors    int     `json:"errors"`
+	Exhausted int     `json:"exhaustedChains"`
+	Skipped   int     `json:"skippedTicks"`
+	TPS       float64 `json:"tps"` // Accepted transactions per second
+	P50       float64 `json:"p50Ms"`
+	P90       float64 `json:"p90Ms"`
+	P99       float64 `json:"p99Ms"`
+	LastError string  `json:"lastError,omitempty"`
+}
+
+// runner builds and broadcasts chain transactions.
+type runner struct {
+	bc      broadcaster
+	builder *txbuilder.Builder
+	key     *keys.WIF
+	addr    *script.Address
+	stats   *stats
+	live    atomic.Int32 // Chains still able to send
+}
+
+// rootCmd is the main cobra command for the stress tool.
+var rootCmd = &cobra.Command{
+	Use:   "stress",
+	Short: "Generate testnet transaction load against ARC",
+	Long: `A command line tool for load-testing ARC endpoints on testnet. It splits the
+largest UTXO of the WIF's testnet address into --chains outputs, then broadcasts
+chained transactions at --tps, printing live acceptance and latency statistics.
+Requires an arc-testnet entry in config.yaml.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if wif == "" {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("--wif is required")
+		}
+		if tps <= 0 {
+			return fmt.Errorf("--tps must be positive")
+		}
+		if numChains < 1 {
+			return fmt.Errorf("--chains must be at least 1")
+		}
+		return run()
+	},
+}
+
+// run splits the funding UTXO and generates load until a limit is reached.
+func run() error {
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if !key.Testnet {
+		return fmt.Errorf("the WIF is for mainnet; stress only runs on testnet")
+	}
+	addr, err := key.Address()
+	if err != nil {
+		return fmt.Errorf("failed to derive address: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if err = cfg.Validate(true); err != nil {
+		return err
+	}
+	arcConfig := cfg.GetARCConfig(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Funding UTXOs come from the data provider; broadcasts always go to the
+	// ARC endpoint under test
+	provider, err := chaindata.Load(ctx, true)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: true, UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, addr.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for testnet address %s", addr.AddressString)
+	}
+
+	r := &runner{
+		bc:      arc.NewARCClient(arcConfig.URL, arcConfig.APIKey),
+		builder: builder,
+		key:     key,
+		addr:    addr,
+		stats:   &stats{},
+	}
+
+	chains, err := r.split(utxos, numChains)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Split into %d chains of %d satoshis (%d transactions each at most)\n",
+		len(chains), chains[0].utxo.Value, chains[0].utxo.Value/txbuilder.MinFee)
+
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	if !quiet {
+		go r.report(done)
+	}
+	r.run(ctx, chains, tps, count)
+	close(done)
+
+	s := r.stats.summary()
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	if !quiet {
+		fmt.Fprintln(os.Stderr)
+	}
+	printSummary(s)
+	return nil
+}
+
+// split broadcasts a transaction splitting the largest UTXO into n equal
+// chain outputs and returns the chains.
+func (r *runner) split(utxos []*txbuilder.UTXO, n int) ([]*chain, error) {
+	largest := utxos[0]
+	for _, u := range utxos[1:] {
+		if u.Value > largest.Value {
+			largest = u
+		}
+	}
+
+	fee := r.builder.CalculateFee(1, n+1)
+	perChain := (largest.Value - min(fee, largest.Value)) / uint64(n) //nolint:gosec // n is positive
+	if perChain <= txbuilder.MinFee {
+		return nil, fmt.Errorf("UTXO of %d satoshis is too small to split into %d chains", largest.Value, n)
+	}
+
+	inputs := []txbuilder.Input{{UTXO: largest, Key: r.key.Key, Uncompressed: !r.key.Compressed}}
+	tx, err := r.builder.Build(inputs, r.addr, perChain*uint64(n), n, r.addr) //nolint:gosec // n is positive
+	if err != nil {
+		return nil, fmt.Errorf("failed to build split transaction: %w", err)
+	}
+	resp, err := r.bc.BroadcastTransaction(tx.String())
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting split transaction: %w", err)
+	}
+	if resp.TxStatus == arc.StatusRejected || resp.TxStatus == arc.StatusDoubleSpend {
+		return nil, fmt.Errorf("split transaction %s: %s %s", resp.TxID, resp.TxStatus, resp.ExtraInfo)
+	}
+
+	txid := tx.TxID().String()
+	chains := make([]*chain, n)
+	for i := range chains {
+		chains[i] = &chain{utxo: &txbuilder.UTXO{TxHash: txid, TxPos: uint32(i), Value: tx.Outputs[i].Satoshis}} //nolint:gosec // output count fits in uint32
+	}
+	return chains, nil
+}
+
+// run sends one transaction per tick from an idle chain until ctx is done,
+// limit transactions have been sent, or every chain is retired. It waits for
+// in-flight broadcasts before returning.
+func (r *runner) run(ctx context.Context, chains []*chain, rate float64, limit int) {
+	r.stats.mu.Lock()
+	r.stats.start = time.Now()
+	r.stats.mu.Unlock()
+	r.live.Store(int32(len(chains))) //nolint:gosec // chain count fits in int32
+
+	ready := make(chan *chain, len(chains))
+	for _, c := range chains {
+		ready <- c
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for sent := 0; limit == 0 || sent < limit; {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if r.live.Load() == 0 {
+			return
+		}
+
+		select {
+		case c := <-ready:
+			sent++
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if r.step(c) {
+					ready <- c
+				} else {
+					r.live.Add(-1)
+				}
+			}()
+		default:
+			r.stats.skip()
+		}
+	}
+}
+
+// step builds and broadcasts the next transaction of c. It reports whether
+// the chain can continue.
+func (r *runner) step(c *chain) bool {
+	inputs := []txbuilder.Input{{UTXO: c.utxo, Key: r.key.Key, Uncompressed: !r.key.Compressed}}
+	tx, err := r.builder.Build(inputs, r.addr, 0, 0, r.addr)
+	if err != nil || len(tx.Outputs) == 0 {
+		r.stats.exhaust()
+		return false
+	}
+
+	start := time.Now()
+	resp, err := r.bc.BroadcastTransaction(tx.String())
+	latency := time.Since(start)
+
+	switch {
+	case err != nil && strings.HasPrefix(err.Error(), "ARC error"):
+		// ARC answered with an error body: the transaction was refused
+		r.stats.record(latency, rejected, err.Error())
+		return false
+	case err != nil:
+		r.stats.record(latency, failed, err.Error())
+		return false
+	case resp.TxStatus == arc.StatusRejected || resp.TxStatus == arc.StatusDoubleSpend:
+		r.stats.record(latency, rejected, strings.TrimSpace(resp.TxStatus+" "+resp.ExtraInfo))
+		return false
+	}
+
+	r.stats.record(latency, accepted, "")
+	c.utxo = &txbuilder.UTXO{TxHash: tx.TxID().String(), TxPos: 0, Value: tx.Outputs[0].Satoshis}
+	return true
+}
+
+// report prints a live statistics line to stderr every second until done is closed.
+func (r *runner) report(done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s := r.stats.summary()
+			fmt.Fprintf(os.Stderr, "\r%6.1fs  sent %d  accepted %d  rejected %d  errors %d  %.1f tps  p50 %.0fms  p99 %.0fms   ",
+				s.Elapsed, s.Sent, s.Accepted, s.Rejected, s.Errors, s.TPS, s.P50, s.P99)
+		}
+	}
+}
+
+// record adds one broadcast result.
+func (s *stats) record(latency time.Duration, result outcome, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies = append(s.latencies, latency)
+	switch result {
+	case accepted:
+		s.accepted++
+	case rejected:
+		s.rejected++
+		s.lastError = msg
+	default:
+		s.errors++
+		s.lastError = msg
+	}
+}
+
+// exhaust counts a chain retired for lack of funds.
+func (s *stats) exhaust() {
+	s.mu.Lock()
+	s.exhausted++
+	s.mu.Unlock()
+}
+
+// skip counts a tick with no idle chain.
+func (s *stats) skip() {
+	s.mu.Lock()
+	s.skipped++
+	s.mu.Unlock()
+}
+
+// summary returns the current statistics.
+func (s *stats) summary() *summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := &summary{
+		Sent:      len(s.latencies),
+		Accepted:  s.accepted,
+		Rejected:  s.rejected,
+		Errors:    s.errors,
+		Exhausted: s.exhausted,
+		Skipped:   s.skipped,
+		LastError: s.lastError,
+	}
+	if !s.start.IsZero() {
+		sum.Elapsed = time.Since(s.start).Seconds()
+	}
+	if sum.Elapsed > 0 {
+		sum.TPS = float64(s.accepted) / sum.Elapsed
+	}
+
+	sorted := make([]time.Duration, len(s.latencies))
+	copy(sorted, s.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sum.P50 = ms(percentile(sorted, 50))
+	sum.P90 = ms(percentile(sorted, 90))
+	sum.P99 = ms(percentile(sorted, 99))
+	return sum
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+// ms converts a duration to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// printSummary prints the final statistics.
+func printSummary(s *summary) {
+	fmt.Printf("Elapsed:   %.1fs\n", s.Elapsed)
+	fmt.Printf("Sent:      %d\n", s.Sent)
+	fmt.Printf("Accepted:  %d (%.1f tps)\n", s.Accepted, s.TPS)
+	fmt.Printf("Rejected:  %d\n", s.Rejected)
+	fmt.Printf("Errors:    %d\n", s.Errors)
+	fmt.Printf("Latency:   p50 %.0fms  p90 %.0fms  p99 %.0fms\n", s.P50, s.P90, s.P99)
+	if s.Skipped > 0 {
+		fmt.Printf("Skipped:   %d tick(s) with every chain busy (add --chains to reach the target rate)\n", s.Skipped)
+	}
+	if s.Exhausted > 0 {
+		fmt.Printf("Exhausted: %d chain(s) ran out of funds\n", s.Exhausted)
+	}
+	if s.LastError != "" {
+		fmt.Printf("Last error: %s\n", s.LastError)
+	}
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "Testnet WIF private key funding the run")
+	rootCmd.Flags().Float64Var(&tps, "tps", 10, "Target transactions per second")
+	rootCmd.Flags().DurationVarP(&duration, "duration", "d", time.Minute, "How long to generate load (0 for no limit)")
+	rootCmd.Flags().IntVarP(&count, "count", "n", 0, "Stop after this many transactions (0 for no limit)")
+	rootCmd.Flags().IntVarP(&numChains, "chains", "c", 10, "Number of parallel transaction chains")
+	rootCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the final summary in JSON format")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress the live statistics line")
+	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the stress command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// fakeBroadcaster accepts transactions unless told to fail, and records them.
+type fakeBroadcaster struct {
+	mu     sync.Mutex
+	txs    []*transaction.Transaction
+	status string
+	err    error
+}
+
+func (f *fakeBroadcaster) BroadcastTransaction(rawTx string) (*arc.TransactionResponse, error) {
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.txs = append(f.txs, tx)
+	status := f.status
+	if status == "" {
+		status = arc.StatusSeenOnNetwork
+	}
+	return &arc.TransactionResponse{TxID: tx.TxID().String(), TxStatus: status}, nil
+}
+
+// testRunner returns a runner with a deterministic testnet key.
+func testRunner(t *testing.T, bc broadcaster) *runner {
+	t.Helper()
+
+	key, err := keys.ParseWIF("cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA")
+	require.NoError(t, err)
+	addr, err := key.Address()
+	require.NoError(t, err)
+	return &runner{bc: bc, builder: &txbuilder.Builder{FeePerKb: 100, Testnet: true}, key: key, addr: addr, stats: &stats{}}
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	bc := &fakeBroadcaster{}
+	r := testRunner(t, bc)
+	utxos := []*txbuilder.UTXO{
+		{TxHash: testTxID, TxPos: 0, Value: 5000},
+		{TxHash: testTxID, TxPos: 1, Value: 100000},
+	}
+
+	chains, err := r.split(utxos, 4)
+	require.NoError(t, err)
+	require.Len(t, chains, 4)
+	require.Len(t, bc.txs, 1)
+
+	split := bc.txs[0]
+	require.Len(t, split.Inputs, 1)
+	assert.Equal(t, uint32(1), split.Inputs[0].SourceTxOutIndex)
+	for i, c := range chains {
+		assert.Equal(t, split.TxID().String(), c.utxo.TxHash)
+		assert.Equal(t, uint32(i), c.utxo.TxPos) //nolint:gosec // test index
+		assert.Equal(t, uint64(24975), c.utxo.Value)
+	}
+
+	t.Run("too small", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := testRunner(t, &fakeBroadcaster{}).split(utxos[:1], 100)
+		require.ErrorContains(t, err, "too small")
+	})
+
+	t.Run("rejected split", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := testRunner(t, &fakeBroadcaster{status: arc.StatusRejected}).split(utxos, 4)
+		require.ErrorContains(t, err, arc.StatusRejected)
+	})
+}
+
+func TestStep(t *testing.T) {
+	t.Parallel()
+
+	t.Run("advances the chain", func(t *testing.T) {
+		t.Parallel()
+
+		bc := &fakeBroadcaster{}
+		r := testRunner(t, bc)
+		c := &chain{utxo: &txbuilder.UTXO{TxHash: testTxID, Value: 1000}}
+
+		require.True(t, r.step(c))
+		require.True(t, r.step(c))
+		require.Len(t, bc.txs, 2)
+		assert.Equal(t, bc.txs[0].TxID().String(), bc.txs[1].Inputs[0].SourceTXID.String())
+		assert.Equal(t, bc.txs[1].TxID().String(), c.utxo.TxHash)
+		assert.Equal(t, uint64(800), c.utxo.Value)
+		assert.Equal(t, 2, r.stats.summary().Accepted)
+	})
+
+	t.Run("exhausted chain", func(t *testing.T) {
+		t.Parallel()
+
+		r := testRunner(t, &fakeBroadcaster{})
+		assert.False(t, r.step(&chain{utxo: &txbuilder.UTXO{TxHash: testTxID, Value: 100}}))
+		assert.Equal(t, 1, r.stats.summary().Exhausted)
+	})
+
+	t.Run("classifies failures", func(t *testing.T) {
+		t.Parallel()
+
+		r := testRunner(t, &fakeBroadcaster{status: arc.StatusDoubleSpend})
+		assert.False(t, r.step(&chain{utxo: &txbuilder.UTXO{TxHash: testTxID, Value: 1000}}))
+
+		r.bc = &fakeBroadcaster{err: errors.New("ARC error: fee too low (HTTP 465, code: 465)")}
+		assert.False(t, r.step(&chain{utxo: &txbuilder.UTXO{TxHash: testTxID, Value: 1000}}))
+
+		r.bc = &fakeBroadcaster{err: errors.New("failed to send request: timeout")}
+		assert.False(t, r.step(&chain{utxo: &txbuilder.UTXO{TxHash: testTxID, Value: 1000}}))
+
+		s := r.stats.summary()
+		assert.Equal(t, 3, s.Sent)
+		assert.Equal(t, 2, s.Rejected)
+		assert.Equal(t, 1, s.Errors)
+		assert.Equal(t, "failed to send request: timeout", s.LastError)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	chains := func(n int, value uint64) []*chain {
+		cs := make([]*chain, n)
+		for i := range cs {
+			cs[i] = &chain{utxo: &txbuilder.UTXO{TxHash: testTxID, TxPos: uint32(i), Value: value}} //nolint:gosec // test index
+		}
+		return cs
+	}
+
+	t.Run("stops at the count", func(t *testing.T) {
+		t.Parallel()
+
+		bc := &fakeBroadcaster{}
+		r := testRunner(t, bc)
+		r.run(context.Background(), chains(3, 10000), 1000, 12)
+
+		assert.Len(t, bc.txs, 12)
+		assert.Equal(t, 12, r.stats.summary().Accepted)
+	})
+
+	t.Run("stops when every chain is retired", func(t *testing.T) {
+		t.Parallel()
+
+		r := testRunner(t, &fakeBroadcaster{status: arc.StatusRejected})
+		r.run(context.Background(), chains(2, 10000), 1000, 0)
+
+		s := r.stats.summary()
+		assert.Equal(t, 2, s.Rejected)
+		assert.Equal(t, 0, s.Accepted)
+	})
+
+	t.Run("stops when the context ends", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		r := testRunner(t, &fakeBroadcaster{})
+		r.run(ctx, chains(1, 1_000_000), 100, 0)
+
+		assert.Positive(t, r.stats.summary().Accepted)
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 90*time.Millisecond, percentile(sorted, 90))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	assert.Equal(t, 7*time.Millisecond, percentile([]time.Duration{7 * time.Millisecond}, 99))
+}
@@ -0,0 +1,512 @@
+// Package main implements on-chain timestamping of files for Bitcoin SV.
+//
+// This tool anchors a file's SHA-256 hash in an OP_RETURN output, proving the
+// file existed no later than the block that mines the transaction. Verifying
+// checks that the transaction carries the file's hash, that the transaction's
+// merkle proof commits to a block header, and that the header has valid proof
+// of work. The block time is the timestamp.
+//
+// Features:
+//   - SHA-256 anchoring in a single OP_FALSE OP_RETURN output
+//   - Funding from a WIF with change back to its address
+//   - UTXOs and broadcasts through the providers in config.yaml, or raw hex output
+//   - Verification against TSC proofs from WhatsOnChain or a BUMP
+//   - Header proof-of-work check
+//   - Mainnet/testnet support
+//   - Non-zero exit code when verification fails
+//   - JSON output support
+//
+// Usage:
+//
+//	timestamp contract.pdf -w <WIF>              # Anchor a file's hash
+//	timestamp contract.pdf -w <WIF> --no-broadcast
+//	cat notes.md | timestamp - -w <WIF>          # Anchor stdin
+//	timestamp verify contract.pdf <txid>         # Check a file against a txid
+//	timestamp verify contract.pdf <txid> --bump <hex>
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/headers"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/spv"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// Command-line flags
+var (
+	testnet     bool   // Use testnet instead of mainnet
+	jsonOutput  bool   // Output in JSON format
+	wif         string // WIF private key funding the timestamp
+	feePerKb    uint64 // Fee rate in satoshis per kilobyte
+	noBroadcast bool   // Print the signed transaction instead of broadcasting it
+	debug       bool   // Enable verbose debug logging
+	bumpHex     string // BUMP (BRC-74) merkle path in hex
+	noColor     bool   // Disable colored output
+)
+
+// stamp is a broadcast timestamp transaction.
+type stamp struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+	TxID   string `json:"txid"`
+	Fee    uint64 `json:"fee"`
+	Status string `json:"status,omitempty"`
+}
+
+// verification holds the outcome of checking a file against a timestamp.
+type verification struct {
+	File          string   `json:"file"`
+	SHA256        string   `json:"sha256"`
+	TxID          string   `json:"txid"`
+	Output        int      `json:"output"` // Index of the output carrying the hash, -1 if absent
+	HashFound     bool     `json:"hashFound"`
+	BlockHash     string   `json:"blockHash,omitempty"`
+	BlockHeight   int64    `json:"blockHeight,omitempty"`
+	BlockTime     int64    `json:"blockTime,omitempty"` // Unix time of the block
+	Confirmations int64    `json:"confirmations,omitempty"`
+	ProofValid    bool     `json:"proofValid"`
+	HeaderValid   bool     `json:"headerValid"`
+	Valid         bool     `json:"valid"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// rootCmd is the main cobra command for the timestamp tool.
+var rootCmd = &cobra.Command{
+	Use:   "timestamp <file>",
+	Short: "Anchor a file's SHA-256 hash on-chain",
+	Long: `A command line tool that anchors a file's SHA-256 hash in an OP_RETURN output,
+funded from a WIF, and verifies files against those anchors. Use - to read the
+file from stdin.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || wif == "" {
+			cmd.Help() //nolint:errcheck
+			return fmt.Errorf("a file and --wif are required")
+		}
+		return runStamp(args[0])
+	},
+}
+
+// verifyCmd checks a file against a timestamp transaction.
+var verifyCmd = &cobra.Command{
+	Use:   "verify <file> <txid>",
+	Short: "Check a file against a timestamp transaction and its merkle proof",
+	Long: `Checks that the transaction carries the file's SHA-256 hash in a data output,
+that its merkle proof commits to a block header, and that the header has valid
+proof of work. The proof is fetched from WhatsOnChain unless --bump is given.
+Exits non-zero if any check fails.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runVerify(args[0], strings.TrimSpace(args[1]))
+	},
+}
+
+// runStamp builds, and unless --no-broadcast is set broadcasts, a timestamp.
+func runStamp(path string) error {
+	digest, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.ParseWIF(wif)
+	if err != nil {
+		return fmt.Errorf("failed to parse WIF: %w", err)
+	}
+	if key.Testnet != testnet {
+		return fmt.Errorf("the WIF is for %s; --testnet must match it", key.Network())
+	}
+	source, err := key.Address()
+	if err != nil {
+		return fmt.Errorf("failed to derive source address: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: testnet, UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+
+	utxos, err := builder.FetchUTXOs(ctx, source.AddressString)
+	if err != nil {
+		return fmt.Errorf("failed to fetch UTXOs: %w", err)
+	}
+	if len(utxos) == 0 {
+		return fmt.Errorf("no UTXOs found for address %s", source.AddressString)
+	}
+
+	tx, fee, err := buildStamp(builder, key, source, utxos, digest)
+	if err != nil {
+		return err
+	}
+
+	if noBroadcast {
+		fmt.Println(tx.String())
+		return nil
+	}
+
+	resp, err := provider.Broadcast(ctx, tx.String())
+	if err != nil {
+		return fmt.Errorf("broadcasting transaction: %w", err)
+	}
+
+	result := &stamp{File: path, SHA256: hex.EncodeToString(digest), TxID: resp.TxID, Fee: fee, Status: resp.Status}
+	if jsonOutput {
+		return encodeJSON(result)
+	}
+	fmt.Printf("File:   %s\n", result.File)
+	fmt.Printf("SHA256: %s\n", result.SHA256)
+	fmt.Printf("TxID:   %s\n", result.TxID)
+	fmt.Printf("Fee:    %d satoshis\n", result.Fee)
+	fmt.Printf("Status: %s\n", result.Status)
+	fmt.Printf("\nVerify once mined: timestamp verify %s %s\n", result.File, result.TxID)
+	return nil
+}
+
+// runVerify checks a file against a timestamp transaction.
+func runVerify(path, txid string) error {
+	if len(txid) != 64 || !cli.IsValidHex(txid) {
+		return fmt.Errorf("invalid txid: %s", txid)
+	}
+
+	digest, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain serves TSC proofs and block info, whatever the data provider
+	result := verify(ctx, provider, provider.WOC.Client, txid, digest)
+	result.File = path
+
+	if jsonOutput {
+		if err := encodeJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printVerification(result)
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("timestamp verification failed")
+	}
+	return nil
+}
+
+// hashFile returns the SHA-256 of a file, or of stdin for "-".
+func hashFile(path string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path) //nolint:gosec // user-specified file
+		if err != nil {
+			return nil, fmt.Errorf("opening file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		r = f
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// stampScript builds the OP_FALSE OP_RETURN <sha256> output script.
+func stampScript(digest []byte) (*script.Script, error) {
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.OpFALSE, script.OpRETURN); err != nil {
+		return nil, err
+	}
+	if err := s.AppendPushData(digest); err != nil {
+		return nil, fmt.Errorf("failed to push hash: %w", err)
+	}
+	return s, nil
+}
+
+// buildStamp builds and signs a transaction anchoring digest, funded from utxos
+// with change back to source. It returns the transaction and its fee.
+func buildStamp(builder *txbuilder.Builder, key *keys.WIF, source *script.Address, utxos []*txbuilder.UTXO, digest []byte) (*transaction.Transaction, uint64, error) {
+	s, err := stampScript(digest)
+	if err != nil {
+		return nil, 0, err
+	}
+	outputs := []*transaction.TransactionOutput{{Satoshis: 0, LockingScript: s}}
+
+	selected, err := builder.SelectUTXOs(utxos, builder.EstimateFee(1, outputs))
+	if err != nil {
+		return nil, 0, fmt.Errorf("UTXO selection failed: %w", err)
+	}
+
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	var totalIn uint64
+	for _, u := range selected {
+		inputs = append(inputs, txbuilder.Input{UTXO: u, Key: key.Key, Uncompressed: !key.Compressed})
+		totalIn += u.Value
+	}
+
+	tx, err := builder.BuildOutputs(inputs, outputs, source)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, totalIn - tx.TotalOutputSatoshis(), nil
+}
+
+// findDigest returns the index of the first data output with a push equal to
+// digest, or -1 if there is none.
+func findDigest(tx *transaction.Transaction, digest []byte) int {
+	for i, out := range tx.Outputs {
+		s := out.LockingScript
+		if s == nil || !s.IsData() {
+			continue
+		}
+
+		b := *s
+		offset := 1
+		if b[0] == script.OpFALSE {
+			offset = 2
+		}
+		rest := script.Script(b[offset:])
+		for pos := 0; pos < len(rest); {
+			op, err := rest.ReadOp(&pos)
+			if err != nil {
+				break
+			}
+			if bytes.Equal(op.Data, digest) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// verify runs every timestamp check, fetching the transaction from txs and its
+// proof and header from client, and collects the results. Each failed check is
+// recorded in Errors; later checks still run where possible.
+func verify(ctx context.Context, txs chain.TxFetcher, client whatsonchain.ClientInterface, txid string, digest []byte) *verification {
+	result := &verification{SHA256: hex.EncodeToString(digest), TxID: txid, Output: -1}
+	fail := func(format string, a ...any) *verification {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, a...))
+		return result
+	}
+
+	// Step 1: the transaction must carry the file's hash
+	rawTx, err := txs.RawTx(ctx, txid)
+	if err != nil {
+		return fail("fetching transaction: %v", err)
+	}
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return fail("parsing transaction: %v", err)
+	}
+	if tx.TxID().String() != txid {
+		return fail("fetched transaction hashes to %s", tx.TxID().String())
+	}
+	result.Output = findDigest(tx, digest)
+	result.HashFound = result.Output >= 0
+	if !result.HashFound {
+		fail("transaction does not contain the file's SHA-256 hash")
+	}
+
+	// Step 2: the merkle proof must commit to the block header
+	root, header, info, err := proveInclusion(ctx, client, txid)
+	if err != nil {
+		return fail("merkle proof: %v", err)
+	}
+	blockHash := header.Hash()
+	result.BlockHash = blockHash.String()
+	result.BlockTime = int64(header.Timestamp)
+	if info != nil {
+		result.BlockHeight = info.Height
+		result.Confirmations = info.Confirmations
+	}
+	result.ProofValid = root.IsEqual(&header.MerkleRoot)
+	if !result.ProofValid {
+		fail("computed merkle root %s does not match header merkle root %s", root, header.MerkleRoot.String())
+	}
+
+	// Step 3: the header must carry valid proof of work
+	if err = headers.CheckProofOfWork(header); err != nil {
+		fail("header: %v", err)
+	} else {
+		result.HeaderValid = true
+	}
+
+	result.Valid = result.HashFound && result.ProofValid && result.HeaderValid
+	return result
+}
+
+// proveInclusion computes the merkle root from the transaction's proof and
+// fetches the header of the block the proof targets.
+func proveInclusion(ctx context.Context, client whatsonchain.ClientInterface, txid string) (*chainhash.Hash, *block.Header, *whatsonchain.BlockInfo, error) {
+	var root *chainhash.Hash
+	var info *whatsonchain.BlockInfo
+	var err error
+
+	if bumpHex != "" {
+		mp, err := spv.ParseBUMP(bumpHex)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if root, err = spv.BUMPRoot(mp, txid); err != nil {
+			return nil, nil, nil, err
+		}
+		info, err = client.GetBlockByHeight(ctx, int64(mp.BlockHeight))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("fetching header: %w", err)
+		}
+	} else {
+		results, err := client.GetMerkleProofTSC(ctx, txid)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("fetching proof: %w", err)
+		}
+		if len(results) == 0 || results[0] == nil {
+			return nil, nil, nil, fmt.Errorf("no proof available (transaction may be unconfirmed)")
+		}
+		proof := results[0]
+		if root, err = spv.TSCRoot(txid, proof.Index, proof.Nodes); err != nil {
+			return nil, nil, nil, err
+		}
+
+		if len(proof.Target) == block.HeaderSize*2 {
+			header, err := block.NewHeaderFromHex(proof.Target)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			hash := header.Hash()
+			info, _ = client.GetHeaderByHash(ctx, hash.String())
+			return root, header, info, nil
+		}
+		if info, err = client.GetHeaderByHash(ctx, proof.Target); err != nil {
+			return nil, nil, nil, fmt.Errorf("fetching header: %w", err)
+		}
+	}
+
+	header, err := headers.HeaderFromBlockInfo(info)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return root, header, info, nil
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// check renders a pass/fail marker.
+func check(ok bool) string {
+	if ok {
+		return c(colorGreen, "✓")
+	}
+	return c(colorRed, "✗")
+}
+
+// printVerification prints a human-readable verification report.
+func printVerification(r *verification) {
+	fmt.Printf("%s %s\n", c(colorDim, "File:       "), r.File)
+	fmt.Printf("%s %s\n", c(colorDim, "SHA256:     "), r.SHA256)
+	fmt.Printf("%s %s\n", c(colorDim, "Transaction:"), r.TxID)
+	if r.BlockHash != "" {
+		fmt.Printf("%s %s\n", c(colorDim, "Block:      "), r.BlockHash)
+	}
+	if r.BlockHeight > 0 {
+		fmt.Printf("%s %d (%d confirmations)\n", c(colorDim, "Height:     "), r.BlockHeight, r.Confirmations)
+	}
+	fmt.Println()
+
+	if r.HashFound {
+		fmt.Printf("%s Hash found in output %d\n", check(true), r.Output)
+	} else {
+		fmt.Printf("%s Hash found\n", check(false))
+	}
+	fmt.Printf("%s Merkle proof\n", check(r.ProofValid))
+	fmt.Printf("%s Header proof of work\n", check(r.HeaderValid))
+
+	for _, e := range r.Errors {
+		fmt.Printf("  %s\n", c(colorRed, e))
+	}
+
+	fmt.Println()
+	if r.Valid {
+		at := time.Unix(r.BlockTime, 0).UTC().Format(time.RFC3339)
+		fmt.Println(c(colorGreen, "✓ File existed by "+at))
+	} else {
+		fmt.Println(c(colorRed, "✗ Timestamp verification failed"))
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key funding the timestamp")
+	rootCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	rootCmd.Flags().BoolVar(&noBroadcast, "no-broadcast", false, "Print the signed transaction hex instead of broadcasting it")
+	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	verifyCmd.Flags().StringVar(&bumpHex, "bump", "", "BUMP (BRC-74) merkle path in hex instead of fetching a proof")
+	verifyCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	rootCmd.AddCommand(verifyCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the timestamp command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const (
+	testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	// genesisCoinbase is the raw coinbase transaction of the genesis block.
+	genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	genesisHash     = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+)
+
+// fakeClient serves single-transaction-block TSC proofs and headers.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	headers map[string]*whatsonchain.BlockInfo
+}
+
+func (f *fakeClient) GetMerkleProofTSC(_ context.Context, txid string) (whatsonchain.MerkleTSCResults, error) {
+	for hash, info := range f.headers {
+		if info.MerkleRoot == txid {
+			return whatsonchain.MerkleTSCResults{{Index: 0, Target: hash, TxOrID: txid}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeClient) GetHeaderByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	if info, ok := f.headers[hash]; ok {
+		return info, nil
+	}
+	return nil, fmt.Errorf("header %s not found", hash)
+}
+
+// testStamp builds a signed timestamp transaction for digest.
+func testStamp(t *testing.T, digest []byte) (*transaction.Transaction, uint64) {
+	t.Helper()
+
+	priv, source := chaintest.Source(t)
+	key := &keys.WIF{Key: priv, Compressed: true}
+
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	tx, fee, err := buildStamp(builder, key, source, []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000}}, digest)
+	require.NoError(t, err)
+	return tx, fee
+}
+
+func TestHashFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	digest, err := hashFile(path)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, sum[:], digest)
+
+	_, err = hashFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestBuildStamp(t *testing.T) {
+	t.Parallel()
+
+	digest := sha256.Sum256([]byte("hello"))
+	tx, fee := testStamp(t, digest[:])
+
+	require.Len(t, tx.Outputs, 2)
+	assert.True(t, tx.Outputs[0].LockingScript.IsData())
+	assert.Equal(t, uint64(0), tx.Outputs[0].Satoshis)
+	assert.Equal(t, uint64(10000), tx.Outputs[1].Satoshis+fee)
+	assert.Equal(t, 0, findDigest(tx, digest[:]))
+
+	other := sha256.Sum256([]byte("other"))
+	assert.Equal(t, -1, findDigest(tx, other[:]))
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hash missing from a mined transaction", func(t *testing.T) {
+		t.Parallel()
+
+		txs := chain.NewMock()
+		txs.Raw[testTxID] = genesisCoinbase
+		client := &fakeClient{
+			headers: map[string]*whatsonchain.BlockInfo{genesisHash: {
+				Hash: genesisHash, Version: 1, MerkleRoot: testTxID,
+				Time: 1231006505, Bits: "1d00ffff", Nonce: 2083236893, Confirmations: 900000,
+			}},
+		}
+		digest := sha256.Sum256([]byte("hello"))
+
+		r := verify(context.Background(), txs, client, testTxID, digest[:])
+		assert.False(t, r.HashFound)
+		assert.True(t, r.ProofValid)
+		assert.True(t, r.HeaderValid)
+		assert.Equal(t, int64(1231006505), r.BlockTime)
+		assert.False(t, r.Valid)
+		assert.Contains(t, r.Errors, "transaction does not contain the file's SHA-256 hash")
+	})
+
+	t.Run("hash present but header lacks proof of work", func(t *testing.T) {
+		t.Parallel()
+
+		digest := sha256.Sum256([]byte("hello"))
+		tx, _ := testStamp(t, digest[:])
+		txid := tx.TxID().String()
+		blockHash := "00000000000000000000000000000000000000000000000000000000000000ff"
+		txs := chain.NewMock()
+		txs.AddTx(tx)
+		client := &fakeClient{
+			headers: map[string]*whatsonchain.BlockInfo{blockHash: {
+				Hash: blockHash, Version: 1, MerkleRoot: txid, Time: 1700000000, Bits: "1d00ffff",
+			}},
+		}
+
+		r := verify(context.Background(), txs, client, txid, digest[:])
+		assert.True(t, r.HashFound)
+		assert.Equal(t, 0, r.Output)
+		assert.False(t, r.HeaderValid)
+		assert.False(t, r.Valid)
+	})
+
+	t.Run("unconfirmed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		digest := sha256.Sum256([]byte("hello"))
+		tx, _ := testStamp(t, digest[:])
+		txid := tx.TxID().String()
+		txs := chain.NewMock()
+		txs.AddTx(tx)
+
+		r := verify(context.Background(), txs, &fakeClient{}, txid, digest[:])
+		assert.True(t, r.HashFound)
+		assert.False(t, r.Valid)
+		require.Len(t, r.Errors, 1)
+		assert.Contains(t, r.Errors[0], "no proof available")
+	})
+}
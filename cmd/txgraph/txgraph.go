@@ -0,0 +1,436 @@
+// Package main implements a Bitcoin SV transaction graph explorer.
+//
+// Starting from a txid, this tool walks the transaction's ancestors (the
+// transactions whose outputs it spends) and, optionally, its descendants (the
+// transactions spending its outputs) to a configurable depth. The resulting
+// graph is written as Graphviz DOT or JSON, for tracing where funds came from
+// and where they went.
+//
+// Features:
+//   - Ancestor walk via raw transactions from the data provider in config.yaml
+//   - Descendant walk via WhatsOnChain spent-output lookups (20 outputs per request)
+//   - Configurable depth and node limit
+//   - DOT output for Graphviz, JSON output for other tooling
+//   - Edges labeled with value and receiving address
+//   - Mainnet/testnet support
+//
+// Usage:
+//
+//	txgraph <txid>                          # Ancestors, 3 generations, DOT
+//	txgraph <txid> -d 5 --descendants       # Both directions, 5 generations
+//	txgraph <txid> | dot -Tsvg > graph.svg  # Render with Graphviz
+//	txgraph <txid> -j                       # JSON output
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// Command-line flags
+var (
+	testnet     bool // Use testnet instead of mainnet
+	jsonOutput  bool // Output in JSON format
+	depth       int  // Number of generations to walk in each direction
+	descendants bool // Also walk transactions spending this one's outputs
+	maxNodes    int  // Maximum number of transactions in the graph
+)
+
+// node is a transaction in the graph.
+type node struct {
+	TxID       string `json:"txid"`
+	Generation int    `json:"generation"`          // Negative for ancestors, positive for descendants
+	Value      uint64 `json:"value,omitempty"`     // Total output value, when the transaction was fetched
+	Coinbase   bool   `json:"coinbase,omitempty"`  // Transaction has no ancestors
+	Truncated  bool   `json:"truncated,omitempty"` // Walk stopped here before reaching the end of the chain
+}
+
+// edge is an output of one transaction spent by another.
+type edge struct {
+	From     string `json:"from"`
+	Vout     uint32 `json:"vout"`
+	To       string `json:"to"`
+	Vin      int    `json:"vin"`
+	Satoshis uint64 `json:"satoshis"`
+	Address  string `json:"address,omitempty"`
+}
+
+// graph holds the transactions and spends found by a walk.
+type graph struct {
+	Root      string  `json:"root"`
+	Depth     int     `json:"depth"`
+	Truncated bool    `json:"truncated,omitempty"` // Node limit was reached
+	Nodes     []*node `json:"nodes"`
+	Edges     []*edge `json:"edges"`
+
+	nodes    map[string]*node
+	edges    map[string]bool
+	maxNodes int
+}
+
+// newGraph creates a graph holding at most maxNodes transactions.
+func newGraph(root string, depth, maxNodes int) *graph {
+	return &graph{
+		Root:     root,
+		Depth:    depth,
+		Nodes:    []*node{},
+		Edges:    []*edge{},
+		nodes:    make(map[string]*node),
+		edges:    make(map[string]bool),
+		maxNodes: maxNodes,
+	}
+}
+
+// addNode returns the node for txid, adding it at generation gen if it is new.
+// It returns nil when the node limit is reached.
+func (g *graph) addNode(txid string, gen int) (n *node, added bool) {
+	if n, ok := g.nodes[txid]; ok {
+		return n, false
+	}
+	if g.maxNodes > 0 && len(g.Nodes) >= g.maxNodes {
+		g.Truncated = true
+		return nil, false
+	}
+	n = &node{TxID: txid, Generation: gen}
+	g.nodes[txid] = n
+	g.Nodes = append(g.Nodes, n)
+	return n, true
+}
+
+// addEdge records that output vout of from is spent by input vin of to.
+func (g *graph) addEdge(from string, vout uint32, to string, vin int, out *transaction.TransactionOutput, mainnet bool) {
+	key := fmt.Sprintf("%s:%d", from, vout)
+	if g.edges[key] {
+		return
+	}
+	g.edges[key] = true
+
+	e := &edge{From: from, Vout: vout, To: to, Vin: vin, Satoshis: out.Satoshis}
+	if out.LockingScript != nil {
+		if info := scripts.Classify(*out.LockingScript, mainnet); len(info.Addresses) > 0 {
+			e.Address = info.Addresses[0]
+		}
+	}
+	g.Edges = append(g.Edges, e)
+}
+
+// walker fetches transactions for a graph walk, each at most once.
+type walker struct {
+	fetcher chain.TxFetcher              // Fetches transactions
+	client  whatsonchain.ClientInterface // Looks up who spent outputs
+	mainnet bool
+	txs     map[string]*transaction.Transaction
+}
+
+// newWalker creates a walker fetching transactions from fetcher and looking up
+// spent outputs on the WhatsOnChain client.
+func newWalker(fetcher chain.TxFetcher, client whatsonchain.ClientInterface, mainnet bool) *walker {
+	return &walker{fetcher: fetcher, client: client, mainnet: mainnet, txs: make(map[string]*transaction.Transaction)}
+}
+
+// fetch returns the transaction with the given txid.
+func (w *walker) fetch(ctx context.Context, txid string) (*transaction.Transaction, error) {
+	if tx, ok := w.txs[txid]; ok {
+		return tx, nil
+	}
+
+	raw, err := w.fetcher.RawTx(ctx, txid)
+	if err != nil {
+		return nil, fmt.Errorf("getting transaction %s: %w", txid, err)
+	}
+
+	tx, err := transaction.NewTransactionFromHex(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction %s: %w", txid, err)
+	}
+	w.txs[txid] = tx
+	return tx, nil
+}
+
+// walk builds the graph around txid, walking ancestors and, if requested, descendants.
+func (w *walker) walk(ctx context.Context, txid string, depth, maxNodes int, withDescendants bool) (*graph, error) {
+	root, err := w.fetch(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph(txid, depth, maxNodes)
+	n, _ := g.addNode(txid, 0)
+	n.Value = root.TotalOutputSatoshis()
+	n.Coinbase = root.IsCoinbase()
+
+	if err := w.ancestors(ctx, g, root, depth); err != nil {
+		return nil, err
+	}
+	if withDescendants {
+		if err := w.descendants(ctx, g, root, depth); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// ancestors adds the transactions spent by tx, generation by generation.
+func (w *walker) ancestors(ctx context.Context, g *graph, tx *transaction.Transaction, depth int) error {
+	frontier := []*transaction.Transaction{tx}
+	for gen := 1; gen <= depth && len(frontier) > 0; gen++ {
+		var next []*transaction.Transaction
+		for _, child := range frontier {
+			if child.IsCoinbase() {
+				continue
+			}
+			childID := child.TxID().String()
+
+			for vin, in := range child.Inputs {
+				parentID := in.SourceTXID.String()
+				n, added := g.addNode(parentID, -gen)
+				if n == nil {
+					return nil
+				}
+
+				parent, err := w.fetch(ctx, parentID)
+				if err != nil {
+					return err
+				}
+				if int(in.SourceTxOutIndex) >= len(parent.Outputs) {
+					return fmt.Errorf("input %d of %s spends missing output %d of %s", vin, childID, in.SourceTxOutIndex, parentID)
+				}
+				g.addEdge(parentID, in.SourceTxOutIndex, childID, vin, parent.Outputs[in.SourceTxOutIndex], w.mainnet)
+
+				if added {
+					n.Value = parent.TotalOutputSatoshis()
+					n.Coinbase = parent.IsCoinbase()
+					next = append(next, parent)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	for _, tx := range frontier {
+		if n := g.nodes[tx.TxID().String()]; !n.Coinbase {
+			n.Truncated = true
+		}
+	}
+	return nil
+}
+
+// descendants adds the transactions spending the outputs of tx, generation by generation.
+// Data outputs are skipped since they cannot be spent.
+func (w *walker) descendants(ctx context.Context, g *graph, tx *transaction.Transaction, depth int) error {
+	frontier := []*transaction.Transaction{tx}
+	for gen := 1; gen <= depth && len(frontier) > 0; gen++ {
+		outputs := make(map[string]*transaction.TransactionOutput)
+		var utxos []whatsonchain.BulkSpentUTXO
+		for _, parent := range frontier {
+			parentID := parent.TxID().String()
+			for vout, out := range parent.Outputs {
+				if out.LockingScript != nil && out.LockingScript.IsData() {
+					continue
+				}
+				outputs[outpoint(parentID, vout)] = out
+				utxos = append(utxos, whatsonchain.BulkSpentUTXO{TxID: parentID, Vout: vout})
+			}
+		}
+
+		var next []*transaction.Transaction
+		for start := 0; start < len(utxos); start += whatsonchain.MaxTransactionsUTXO {
+			end := min(start+whatsonchain.MaxTransactionsUTXO, len(utxos))
+			results, err := w.client.BulkSpentOutputs(ctx, &whatsonchain.BulkSpentOutputRequest{UTXOs: utxos[start:end]})
+			if err != nil {
+				return fmt.Errorf("looking up spent outputs: %w", err)
+			}
+
+			for _, sr := range results {
+				out := outputs[outpoint(sr.TxID, sr.Vout)]
+				if out == nil || sr.Spent == nil || sr.Spent.TxID == "" {
+					continue
+				}
+
+				n, added := g.addNode(sr.Spent.TxID, gen)
+				if n == nil {
+					return nil
+				}
+				g.addEdge(sr.TxID, uint32(sr.Vout), sr.Spent.TxID, sr.Spent.Vin, out, w.mainnet) //nolint:gosec // vout comes from the output index
+				if !added {
+					continue
+				}
+
+				if gen == depth {
+					n.Truncated = true
+					continue
+				}
+				child, err := w.fetch(ctx, sr.Spent.TxID)
+				if err != nil {
+					return err
+				}
+				n.Value = child.TotalOutputSatoshis()
+				next = append(next, child)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// rootCmd is the main cobra command for the txgraph tool.
+var rootCmd = &cobra.Command{
+	Use:   "txgraph [txid]",
+	Short: "Explore a transaction's ancestors and descendants",
+	Long: `A command line tool that walks the transactions funding a transaction and,
+optionally, the transactions spending its outputs, to a configurable depth. The
+graph is written as Graphviz DOT (pipe it to "dot -Tsvg") or as JSON.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	txid, err := getTxID(args)
+	if err != nil {
+		return err
+	}
+
+	if txid == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no transaction ID provided")
+	}
+
+	if len(txid) != chainhash.MaxHashStringSize || !cli.IsValidHex(txid) {
+		return fmt.Errorf("invalid transaction ID: %s", txid)
+	}
+
+	if depth < 1 {
+		return fmt.Errorf("depth must be at least 1")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain looks up spent outputs, whatever the data provider
+	g, err := newWalker(provider, provider.WOC.Client, !testnet).walk(ctx, txid, depth, maxNodes, descendants)
+	if err != nil {
+		return err
+	}
+
+	if g.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: stopped at %d transactions (--max-nodes)\n", maxNodes)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	}
+	return writeDOT(os.Stdout, g)
+}
+
+// getTxID retrieves the transaction ID from argument or stdin.
+func getTxID(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// writeDOT writes the graph in Graphviz DOT format, oldest transactions on the left.
+func writeDOT(w io.Writer, g *graph) error {
+	var b strings.Builder
+	b.WriteString("digraph txgraph {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	b.WriteString("  edge [fontname=\"monospace\", fontsize=10];\n\n")
+
+	for _, n := range g.Nodes {
+		label := shortID(n.TxID)
+		if n.Value > 0 {
+			label += fmt.Sprintf("\n%d sats", n.Value)
+		}
+		if n.Coinbase {
+			label += "\ncoinbase"
+		}
+
+		var attrs []string
+		attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		switch {
+		case n.TxID == g.Root:
+			attrs = append(attrs, "style=bold")
+		case n.Truncated:
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", n.TxID, strings.Join(attrs, ", "))
+	}
+
+	if len(g.Edges) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range g.Edges {
+		label := fmt.Sprintf("%d → %d\n%d sats", e.Vout, e.Vin, e.Satoshis)
+		if e.Address != "" {
+			label += "\n" + e.Address
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, label)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shortID abbreviates a txid for display.
+func shortID(txid string) string {
+	if len(txid) <= 16 {
+		return txid
+	}
+	return txid[:8] + "…" + txid[len(txid)-8:]
+}
+
+// outpoint formats a txid:vout key.
+func outpoint(txid string, vout int) string {
+	return fmt.Sprintf("%s:%d", txid, vout)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format instead of DOT")
+	rootCmd.Flags().IntVarP(&depth, "depth", "d", 3, "Number of generations to walk in each direction")
+	rootCmd.Flags().BoolVar(&descendants, "descendants", false, "Also walk transactions spending this transaction's outputs")
+	rootCmd.Flags().IntVar(&maxNodes, "max-nodes", 500, "Maximum number of transactions in the graph (0 for no limit)")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the txgraph command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+const testAddress = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+
+// fakeClient serves canned spent outputs; all other client methods are
+// unimplemented.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	spent map[string]*whatsonchain.SpentOutput
+}
+
+func (f *fakeClient) BulkSpentOutputs(_ context.Context, req *whatsonchain.BulkSpentOutputRequest) (whatsonchain.BulkSpentOutputResponse, error) {
+	var resp whatsonchain.BulkSpentOutputResponse
+	for _, u := range req.UTXOs {
+		resp = append(resp, whatsonchain.BulkSpentOutputResult{TxID: u.TxID, Vout: u.Vout, Spent: f.spent[outpoint(u.TxID, u.Vout)]})
+	}
+	return resp, nil
+}
+
+// txChain builds and serves transactions; each spends the given outputs of earlier ones.
+type txChain struct {
+	t      *testing.T
+	txs    *chain.Mock
+	client *fakeClient
+}
+
+// newChain creates an empty chain served by a mock provider and a fake client.
+func newChain(t *testing.T) *txChain {
+	return &txChain{t: t, txs: chain.NewMock(), client: &fakeClient{spent: make(map[string]*whatsonchain.SpentOutput)}}
+}
+
+// walker creates a walker over the chain.
+func (c *txChain) walker() *walker {
+	return newWalker(c.txs, c.client, true)
+}
+
+// tx creates a transaction spending the given outpoints and paying amounts to the test address.
+// A transaction without inputs is a coinbase.
+func (c *txChain) tx(spends []*transaction.TransactionInput, amounts ...uint64) *transaction.Transaction {
+	c.t.Helper()
+
+	tx := transaction.NewTransaction()
+	if len(spends) == 0 {
+		tx.AddInput(&transaction.TransactionInput{SourceTXID: &chainhash.Hash{}, SourceTxOutIndex: 0xffffffff})
+	}
+	for _, in := range spends {
+		tx.AddInput(in)
+	}
+	for _, amount := range amounts {
+		require.NoError(c.t, tx.PayToAddress(testAddress, amount))
+	}
+
+	txid := tx.TxID().String()
+	c.txs.AddTx(tx)
+	for vin, in := range spends {
+		c.client.spent[outpoint(in.SourceTXID.String(), int(in.SourceTxOutIndex))] = &whatsonchain.SpentOutput{TxID: txid, Vin: vin}
+	}
+	return tx
+}
+
+// out returns an input spending output vout of tx.
+func out(tx *transaction.Transaction, vout uint32) *transaction.TransactionInput {
+	return &transaction.TransactionInput{SourceTXID: tx.TxID(), SourceTxOutIndex: vout}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// coinbase -> a -> b (spends a:0 and a:1) -> last
+	c := newChain(t)
+	coinbase := c.tx(nil, 10000)
+	a := c.tx([]*transaction.TransactionInput{out(coinbase, 0)}, 4000, 5000)
+	b := c.tx([]*transaction.TransactionInput{out(a, 0), out(a, 1)}, 8000)
+	last := c.tx([]*transaction.TransactionInput{out(b, 0)}, 7000)
+
+	t.Run("ancestors to the coinbase", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := c.walker().walk(ctx, b.TxID().String(), 5, 0, false)
+		require.NoError(t, err)
+
+		require.Len(t, g.Nodes, 3)
+		assert.Equal(t, 0, g.nodes[b.TxID().String()].Generation)
+		assert.Equal(t, -1, g.nodes[a.TxID().String()].Generation)
+		assert.Equal(t, uint64(9000), g.nodes[a.TxID().String()].Value)
+
+		cb := g.nodes[coinbase.TxID().String()]
+		assert.Equal(t, -2, cb.Generation)
+		assert.True(t, cb.Coinbase)
+		assert.False(t, cb.Truncated)
+
+		require.Len(t, g.Edges, 3)
+		assert.Equal(t, a.TxID().String(), g.Edges[1].From)
+		assert.Equal(t, uint32(1), g.Edges[1].Vout)
+		assert.Equal(t, 1, g.Edges[1].Vin)
+		assert.Equal(t, uint64(5000), g.Edges[1].Satoshis)
+		assert.Equal(t, testAddress, g.Edges[1].Address)
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := c.walker().walk(ctx, b.TxID().String(), 1, 0, false)
+		require.NoError(t, err)
+
+		require.Len(t, g.Nodes, 2)
+		assert.True(t, g.nodes[a.TxID().String()].Truncated)
+		assert.NotContains(t, g.nodes, coinbase.TxID().String())
+	})
+
+	t.Run("descendants", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := c.walker().walk(ctx, a.TxID().String(), 1, 0, true)
+		require.NoError(t, err)
+
+		// coinbase, a, and b spending both outputs of a
+		require.Len(t, g.Nodes, 3)
+		child := g.nodes[b.TxID().String()]
+		assert.Equal(t, 1, child.Generation)
+		assert.True(t, child.Truncated)
+		assert.NotContains(t, g.nodes, last.TxID().String())
+		assert.Len(t, g.Edges, 3)
+	})
+
+	t.Run("node limit", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := c.walker().walk(ctx, last.TxID().String(), 5, 2, false)
+		require.NoError(t, err)
+		assert.Len(t, g.Nodes, 2)
+		assert.True(t, g.Truncated)
+	})
+
+	t.Run("transactions are fetched once", func(t *testing.T) {
+		t.Parallel()
+
+		d := newChain(t)
+		parent := d.tx(nil, 1000, 2000, 3000)
+		child := d.tx([]*transaction.TransactionInput{out(parent, 0), out(parent, 1), out(parent, 2)}, 5000)
+
+		_, err := d.walker().walk(ctx, child.TxID().String(), 3, 0, false)
+		require.NoError(t, err)
+		assert.Len(t, d.txs.Fetched, 2)
+	})
+
+	t.Run("missing transaction", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newChain(t).walker().walk(ctx, a.TxID().String(), 1, 0, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestDescendantsSkipDataOutputs(t *testing.T) {
+	t.Parallel()
+
+	c := newChain(t)
+	data, err := script.NewFromASM("OP_FALSE OP_RETURN 68656c6c6f")
+	require.NoError(t, err)
+
+	root := c.tx(nil, 1000)
+	root.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: data})
+	c.txs.AddTx(root)
+
+	g, err := c.walker().walk(context.Background(), root.TxID().String(), 1, 0, true)
+	require.NoError(t, err)
+	assert.Len(t, g.Nodes, 1)
+	assert.Empty(t, g.Edges)
+}
+
+func TestWriteDOT(t *testing.T) {
+	t.Parallel()
+
+	g := newGraph("aa", 1, 0)
+	root, _ := g.addNode("aa", 0)
+	root.Value = 900
+	parent, _ := g.addNode("bb", -1)
+	parent.Truncated = true
+	g.Edges = append(g.Edges, &edge{From: "bb", Vout: 2, To: "aa", Vin: 0, Satoshis: 1000, Address: testAddress})
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDOT(&buf, g))
+
+	dot := buf.String()
+	assert.Contains(t, dot, "digraph txgraph {")
+	assert.Contains(t, dot, `"aa" [label="aa\n900 sats", style=bold];`)
+	assert.Contains(t, dot, `"bb" [label="bb", style=dashed];`)
+	assert.Contains(t, dot, `"bb" -> "aa" [label="2 → 0\n1000 sats\n`+testAddress+`"];`)
+}
+
+func TestShortID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "4a5e1e4b…fdeda33b", shortID("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"))
+	assert.Equal(t, "abc", shortID("abc"))
+}
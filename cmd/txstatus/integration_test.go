@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/httpmock"
+)
+
+// The integration tests run the command with the config.yaml beside them
+// against recorded ARC responses in testdata; see internal/httpmock to
+// re-record them.
+const testTxID = "d45a84e34a6cf93e9b5b7d1ef1ab91ea08a7ff8daa9fab5b7bdb4796a563b7ed"
+
+func TestIntegrationMined(t *testing.T) {
+	httpmock.Install(t, "mined")
+
+	out, err := httpmock.Execute(t, rootCmd, testTxID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Using mainnet configuration")
+	assert.Contains(t, out, "Status: MINED")
+	assert.Contains(t, out, "Block Height: 870002")
+	assert.Contains(t, out, "✓ Transaction is in final state")
+}
+
+func TestIntegrationAnyNetwork(t *testing.T) {
+	httpmock.Install(t, "any_network")
+
+	out, err := httpmock.Execute(t, rootCmd, testTxID, "--any-network")
+	require.NoError(t, err)
+	assert.Contains(t, out, "⚠ Transaction not found on mainnet; found on testnet")
+	assert.Contains(t, out, "Using testnet configuration")
+	assert.Contains(t, out, "Status: SEEN_ON_NETWORK")
+	assert.Contains(t, out, "⏳ Transaction is still pending")
+}
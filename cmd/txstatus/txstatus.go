@@ -6,8 +6,13 @@
 // Features:
 //   - Config-based mainnet/testnet endpoint management via config.yaml
 //   - Real-time transaction status monitoring with customizable polling
+//   - Monitoring follows config.yaml, reloaded when it changes or on SIGHUP
 //   - Support for stdin, flag, or command-line argument input
 //   - Automatic transaction lifecycle tracking
+//   - Side-by-side comparison across every configured ARC endpoint (--all-endpoints)
+//   - Fallback to the other network when the txid is not found (--any-network)
+//   - JSON summary of a list of txids: counts per status, median time to mine,
+//     and rejected transactions (--stats)
 //
 // Usage:
 //
@@ -16,11 +21,23 @@
 //	echo <txid> | txstatus                   # Check from stdin
 //	txstatus <txid> -t                       # Check on testnet
 //	txstatus <txid> -m                       # Monitor until final state
+//	txstatus <txid> --all-endpoints          # Compare status across ARC endpoints
+//	txstatus <txid> --any-network            # Also check the other network
+//	txstatus --stats txids.txt               # Summarize a broadcast campaign as JSON
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mrz1836/go-template/internal/arc"
@@ -35,8 +52,58 @@ var (
 	testnet  bool   // Use testnet instead of mainnet
 	monitor  bool   // Enable transaction status monitoring
 	pollRate int    // Polling interval in seconds for monitoring
+	all      bool   // Query every configured ARC endpoint and compare
+	anyNet   bool   // Check the other network when the txid is not found
+	stats    string // File of txids to summarize as JSON ("-" for stdin)
 )
 
+// statsWorkers is how many status lookups --stats runs at once.
+const statsWorkers = 8
+
+// Pseudo-statuses counted by --stats for txids ARC could not report on
+const (
+	statusNotFound = "NOT_FOUND"
+	statusError    = "ERROR"
+)
+
+// statsEntry is a txid from a --stats file, with the time it was broadcast
+// when the line gives one.
+type statsEntry struct {
+	TxID string
+	Sent time.Time
+}
+
+// txStats is the --stats summary of a list of txids.
+type txStats struct {
+	Network             string         `json:"network"`
+	Total               int            `json:"total"`
+	Statuses            map[string]int `json:"statuses"`
+	Timed               int            `json:"timed"`                         // Mined txids with a broadcast time
+	MedianSecondsToMine *float64       `json:"medianSecondsToMine,omitempty"` // Over the timed txids
+	Rejected            []txFailure    `json:"rejected"`
+	Errors              []txFailure    `json:"errors"`
+}
+
+// txResult is the status ARC reported for one --stats txid.
+type txResult struct {
+	Status *arc.TransactionStatus // Reported status, nil on error
+	Err    error                  // Query error
+}
+
+// txFailure is a rejected txid, or one whose status could not be fetched.
+type txFailure struct {
+	TxID   string `json:"txid"`
+	Status string `json:"status,omitempty"`
+	Info   string `json:"info,omitempty"`
+}
+
+// endpointStatus is a transaction's status as reported by one ARC endpoint.
+type endpointStatus struct {
+	Name   string                 // Endpoint name from config.yaml
+	Status *arc.TransactionStatus // Reported status, nil on error
+	Err    error                  // Query error
+}
+
 // rootCmd is the main cobra command for the txstatus tool.
 var rootCmd = &cobra.Command{
 	Use:   "txstatus [txid]",
@@ -44,6 +111,10 @@ var rootCmd = &cobra.Command{
 	Long:  "A command line tool that checks transaction status on ARC. Accepts txid as argument or from stdin",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if stats != "" {
+			return runStats(args)
+		}
+
 		transactionID, err := getTransactionID(cmd, args)
 		if err != nil {
 			return err
@@ -93,6 +164,31 @@ func checkTransactionStatus(txid string) error {
 		return fmt.Errorf("loading configuration: %w", err)
 	}
 
+	if anyNet {
+		found, err := findNetwork(cfg, txid, testnet)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if found != testnet {
+			fmt.Printf("⚠ Transaction not found on %s; found on %s\n", networkName(testnet), networkName(found))
+			testnet = found
+		}
+	}
+
+	if all {
+		if monitor {
+			return fmt.Errorf("--all-endpoints cannot be used with --monitor")
+		}
+		endpoints := cfg.GetARCEndpoints(testnet)
+		if len(endpoints) == 0 {
+			return cfg.Validate(testnet)
+		}
+		printNetwork()
+		fmt.Printf("Checking status for transaction: %s\n\n", txid)
+		printComparison(queryEndpoints(endpoints, txid))
+		return nil
+	}
+
 	// Validate config
 	if err := cfg.Validate(testnet); err != nil {
 		return err
@@ -100,11 +196,7 @@ func checkTransactionStatus(txid string) error {
 
 	arcConfig := cfg.GetARCConfig(testnet)
 
-	if testnet {
-		fmt.Println("Using testnet configuration")
-	} else {
-		fmt.Println("Using mainnet configuration")
-	}
+	printNetwork()
 
 	// Create ARC client
 	client := arc.NewARCClient(arcConfig.URL, arcConfig.APIKey)
@@ -118,6 +210,135 @@ func checkTransactionStatus(txid string) error {
 	return getStatus(client, txid)
 }
 
+// printNetwork prints which network's configuration is in use.
+func printNetwork() {
+	fmt.Printf("Using %s configuration\n", networkName(testnet))
+}
+
+// networkName returns "mainnet" or "testnet".
+func networkName(testnet bool) string {
+	if testnet {
+		return "testnet"
+	}
+	return "mainnet"
+}
+
+// findNetwork reports which network's ARC endpoint knows txid, trying the
+// selected network first and skipping a network with no ARC URL configured.
+// It returns the selected network when neither knows the transaction, or when
+// a lookup fails for a reason other than not found.
+func findNetwork(cfg *config.Config, txid string, testnet bool) (bool, error) {
+	for _, network := range []bool{testnet, !testnet} {
+		arcConfig := cfg.GetARCConfig(network)
+		if arcConfig.URL == "" {
+			continue
+		}
+		_, err := arc.NewARCClient(arcConfig.URL, arcConfig.APIKey).GetTransactionStatus(txid)
+		if err == nil {
+			return network, nil
+		}
+		if !errors.Is(err, arc.ErrTransactionNotFound) {
+			return testnet, fmt.Errorf("checking %s: %w", networkName(network), err)
+		}
+	}
+	return testnet, nil
+}
+
+// queryEndpoints fetches the transaction's status from every endpoint
+// concurrently, returning the results in endpoint order.
+func queryEndpoints(endpoints []config.NamedARCConfig, txid string) []endpointStatus {
+	results := make([]endpointStatus, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := arc.NewARCClient(endpoint.URL, endpoint.APIKey)
+			status, err := client.GetTransactionStatus(txid)
+			results[i] = endpointStatus{Name: endpoint.Name, Status: status, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// printComparison prints one row per endpoint followed by whether they agree.
+func printComparison(results []endpointStatus) {
+	width := len("ENDPOINT")
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+
+	fmt.Printf("%-*s  %-22s  %-8s  %s\n", width, "ENDPOINT", "STATUS", "HEIGHT", "INFO")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%-*s  %-22s  %-8s  %v\n", width, r.Name, "ERROR", "-", r.Err)
+			continue
+		}
+		height := "-"
+		if r.Status.BlockHeight > 0 {
+			height = fmt.Sprintf("%d", r.Status.BlockHeight)
+		}
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%-*s  %-22s  %-8s  %s", width, r.Name, r.Status.TxStatus, height, r.Status.ExtraInfo), " "))
+	}
+
+	fmt.Printf("\n%s\n", summarizeComparison(results))
+}
+
+// summarizeComparison reports whether the endpoints that answered agree on the
+// status and block, which points to propagation problems when they do not.
+func summarizeComparison(results []endpointStatus) string {
+	var answered []endpointStatus
+	for _, r := range results {
+		if r.Err == nil {
+			answered = append(answered, r)
+		}
+	}
+	failed := len(results) - len(answered)
+
+	var summary string
+	switch {
+	case len(answered) == 0:
+		return fmt.Sprintf("✗ No endpoint answered (%d failed)", failed)
+	case agree(answered):
+		summary = fmt.Sprintf("✓ %d endpoint(s) agree: %s", len(answered), answered[0].Status.TxStatus)
+		if hash := answered[0].Status.BlockHash; hash != "" {
+			summary += fmt.Sprintf(" in block %d (%s)", answered[0].Status.BlockHeight, hash)
+		}
+	default:
+		counts := make(map[string]int)
+		var statuses []string
+		for _, r := range answered {
+			if counts[r.Status.TxStatus] == 0 {
+				statuses = append(statuses, r.Status.TxStatus)
+			}
+			counts[r.Status.TxStatus]++
+		}
+		parts := make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			parts = append(parts, fmt.Sprintf("%s ×%d", status, counts[status]))
+		}
+		summary = "⚠ Endpoints disagree: " + strings.Join(parts, ", ")
+		if len(statuses) == 1 {
+			summary = "⚠ Endpoints disagree on the block: " + answered[0].Status.TxStatus
+		}
+	}
+	if failed > 0 {
+		summary += fmt.Sprintf(" (%d endpoint(s) failed)", failed)
+	}
+	return summary
+}
+
+// agree reports whether every result has the same status and block hash.
+func agree(results []endpointStatus) bool {
+	for _, r := range results[1:] {
+		if r.Status.TxStatus != results[0].Status.TxStatus || r.Status.BlockHash != results[0].Status.BlockHash {
+			return false
+		}
+	}
+	return true
+}
+
 // getStatus performs a single transaction status check.
 func getStatus(client *arc.ARCClient, txid string) error {
 	fmt.Printf("Checking status for transaction: %s\n\n", txid)
@@ -154,6 +375,9 @@ func getStatus(client *arc.ARCClient, txid string) error {
 
 // monitorTransaction continuously polls the transaction status until it reaches a final state.
 func monitorTransaction(client *arc.ARCClient, txid string) error {
+	// Revalidate with the status's ETag, so an unchanged status is a cheap 304
+	client.SetStatusCache(0)
+
 	fmt.Printf("Monitoring transaction: %s\n", txid)
 	fmt.Printf("Polling every %d seconds...\n", pollRate)
 	fmt.Println("Press Ctrl+C to stop monitoring")
@@ -179,12 +403,26 @@ func monitorTransaction(client *arc.ARCClient, txid string) error {
 		return nil
 	}
 
-	// Continue monitoring
+	// Continue monitoring, following config.yaml as it changes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := config.Watch(ctx, "", config.ReloadInterval, func(err error) {
+		fmt.Fprintf(os.Stderr, "Keeping the current configuration: %v\n", err)
+	})
+
 	ticker := time.NewTicker(time.Duration(pollRate) * time.Second)
 	defer ticker.Stop()
 
 	for {
-		<-ticker.C
+		nextPoll(ticker, reloads, func(cfg *config.Config) {
+			arcConfig := cfg.GetARCConfig(testnet)
+			if arcConfig.URL == "" {
+				fmt.Fprintf(os.Stderr, "Reloaded config.yaml has no ARC URL; still polling the previous endpoint\n")
+				return
+			}
+			client.SetEndpoint(arcConfig.URL, arc.ParseAPIKey(arcConfig.APIKey))
+			fmt.Fprintf(os.Stderr, "Reloaded config.yaml; polling %s\n", arcConfig.URL)
+		})
 
 		status, err := client.GetTransactionStatus(txid)
 		if err != nil {
@@ -210,12 +448,196 @@ func monitorTransaction(client *arc.ARCClient, txid string) error {
 	return nil
 }
 
+// nextPoll waits for the ticker, applying each configuration reloaded
+// meanwhile.
+func nextPoll(ticker *time.Ticker, reloads <-chan *config.Config, apply func(*config.Config)) {
+	for {
+		select {
+		case <-ticker.C:
+			return
+		case cfg, ok := <-reloads:
+			if !ok {
+				reloads = nil
+				continue
+			}
+			apply(cfg)
+		}
+	}
+}
+
+// runStats checks every txid in the --stats file and prints the summary as JSON.
+func runStats(args []string) error {
+	switch {
+	case len(args) > 0 || txid != "":
+		return fmt.Errorf("--stats reads txids from its file and cannot be given a txid")
+	case monitor:
+		return fmt.Errorf("--stats cannot be used with --monitor")
+	case all:
+		return fmt.Errorf("--stats cannot be used with --all-endpoints")
+	case anyNet:
+		return fmt.Errorf("--stats cannot be used with --any-network")
+	}
+
+	r := io.Reader(os.Stdin)
+	if stats != "-" {
+		f, err := os.Open(stats) //nolint:gosec // user-specified file
+		if err != nil {
+			return fmt.Errorf("opening txid list: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	entries, err := readStatsEntries(r)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if err = cfg.Validate(testnet); err != nil {
+		return err
+	}
+	arcConfig := cfg.GetARCConfig(testnet)
+	client := arc.NewARCClient(arcConfig.URL, arcConfig.APIKey)
+
+	summary := summarizeStats(entries, queryStatuses(client, entries))
+	summary.Network = networkName(testnet)
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding summary: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// readStatsEntries reads one txid per line, optionally followed by a comma or
+// whitespace and the time it was broadcast, as RFC 3339 or Unix seconds.
+// Blank lines and lines starting with # are skipped.
+func readStatsEntries(r io.Reader) ([]statsEntry, error) {
+	var entries []statsEntry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		entry := statsEntry{TxID: fields[0]}
+		if len(entry.TxID) != 64 || !cli.IsValidHex(entry.TxID) {
+			return nil, fmt.Errorf("line %d: invalid txid %q", n, entry.TxID)
+		}
+		if len(fields) > 1 {
+			sent, err := parseSentTime(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			entry.Sent = sent
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading txid list: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no txids to summarize")
+	}
+	return entries, nil
+}
+
+// parseSentTime parses a broadcast time given as RFC 3339 or Unix seconds.
+func parseSentTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid broadcast time %q: use RFC 3339 or Unix seconds", s)
+	}
+	return t, nil
+}
+
+// queryStatuses fetches the status of every entry, statsWorkers at a time,
+// returning the results in entry order.
+func queryStatuses(client *arc.ARCClient, entries []statsEntry) []txResult {
+	results := make([]txResult, len(entries))
+	sem := make(chan struct{}, statsWorkers)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			status, err := client.GetTransactionStatus(entry.TxID)
+			results[i] = txResult{Status: status, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// summarizeStats counts the results by status and measures time to mine as
+// the gap between an entry's broadcast time and ARC's timestamp for its
+// MINED status, for entries that have both.
+func summarizeStats(entries []statsEntry, results []txResult) *txStats {
+	summary := &txStats{
+		Total:    len(entries),
+		Statuses: make(map[string]int),
+		Rejected: []txFailure{},
+		Errors:   []txFailure{},
+	}
+
+	var durations []float64
+	for i, r := range results {
+		switch {
+		case errors.Is(r.Err, arc.ErrTransactionNotFound):
+			summary.Statuses[statusNotFound]++
+		case r.Err != nil:
+			summary.Statuses[statusError]++
+			summary.Errors = append(summary.Errors, txFailure{TxID: entries[i].TxID, Info: r.Err.Error()})
+		default:
+			status := r.Status.TxStatus
+			summary.Statuses[status]++
+			if status == arc.StatusRejected || status == arc.StatusDoubleSpend {
+				summary.Rejected = append(summary.Rejected, txFailure{TxID: entries[i].TxID, Status: status, Info: r.Status.ExtraInfo})
+			}
+			if status != arc.StatusMined || entries[i].Sent.IsZero() {
+				continue
+			}
+			mined, err := time.Parse(time.RFC3339, r.Status.Timestamp)
+			if err != nil {
+				continue
+			}
+			durations = append(durations, mined.Sub(entries[i].Sent).Seconds())
+		}
+	}
+
+	summary.Timed = len(durations)
+	if len(durations) > 0 {
+		slices.Sort(durations)
+		median := durations[len(durations)/2]
+		if len(durations)%2 == 0 {
+			median = (durations[len(durations)/2-1] + median) / 2
+		}
+		summary.MedianSecondsToMine = &median
+	}
+	return summary
+}
+
 // init initializes the cobra command flags.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	rootCmd.Flags().StringVarP(&txid, "txid", "i", "", "Transaction ID to check")
 	rootCmd.Flags().BoolVarP(&monitor, "monitor", "m", false, "Monitor transaction status until final state")
 	rootCmd.Flags().IntVarP(&pollRate, "poll-rate", "p", 5, "Polling rate in seconds for monitoring (default: 5)")
 	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet configuration from config.yaml")
+	rootCmd.Flags().BoolVar(&all, "all-endpoints", false, "Query every configured ARC endpoint and compare their status")
+	rootCmd.Flags().BoolVar(&anyNet, "any-network", false, "Check the other network's ARC endpoint when the txid is not found")
+	rootCmd.Flags().StringVar(&stats, "stats", "", "Summarize the status of every txid in this file as JSON (- for stdin)")
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the txstatus command.
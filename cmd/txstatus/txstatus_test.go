@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/arc"
+	"github.com/mrz1836/go-template/internal/config"
+)
+
+// newARCServer serves status for every txid, or a 404 when status is nil.
+func newARCServer(t *testing.T, status *arc.TransactionStatus) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if status == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(arc.ErrorResponse{Status: 404, Error: "transaction not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestQueryEndpoints(t *testing.T) {
+	t.Parallel()
+
+	endpoints := []config.NamedARCConfig{
+		{Name: "taal", ARCConfig: config.ARCConfig{URL: newARCServer(t, &arc.TransactionStatus{TxID: "abc", TxStatus: arc.StatusMined, BlockHeight: 800000})}},
+		{Name: "gorillapool", ARCConfig: config.ARCConfig{URL: newARCServer(t, &arc.TransactionStatus{TxID: "abc", TxStatus: arc.StatusSeenOnNetwork})}},
+		{Name: "missing", ARCConfig: config.ARCConfig{URL: newARCServer(t, nil)}},
+	}
+
+	results := queryEndpoints(endpoints, "abc")
+	require.Len(t, results, 3)
+	assert.Equal(t, "taal", results[0].Name)
+	assert.Equal(t, arc.StatusMined, results[0].Status.TxStatus)
+	assert.Equal(t, arc.StatusSeenOnNetwork, results[1].Status.TxStatus)
+	require.Error(t, results[2].Err)
+	assert.Contains(t, results[2].Err.Error(), "transaction not found")
+}
+
+func TestSummarizeComparison(t *testing.T) {
+	t.Parallel()
+
+	status := func(txStatus, blockHash string, height int64) endpointStatus {
+		return endpointStatus{Status: &arc.TransactionStatus{TxStatus: txStatus, BlockHash: blockHash, BlockHeight: height}}
+	}
+	failed := endpointStatus{Err: errors.New("timeout")}
+
+	tests := []struct {
+		name    string
+		results []endpointStatus
+		want    string
+	}{
+		{
+			"agree on a block",
+			[]endpointStatus{status(arc.StatusMined, "00ab", 800000), status(arc.StatusMined, "00ab", 800000)},
+			"✓ 2 endpoint(s) agree: MINED in block 800000 (00ab)",
+		},
+		{
+			"agree with a failure",
+			[]endpointStatus{status(arc.StatusSeenOnNetwork, "", 0), failed},
+			"✓ 1 endpoint(s) agree: SEEN_ON_NETWORK (1 endpoint(s) failed)",
+		},
+		{
+			"disagree on status",
+			[]endpointStatus{status(arc.StatusMined, "00ab", 800000), status(arc.StatusSeenOnNetwork, "", 0), status(arc.StatusMined, "00ab", 800000)},
+			"⚠ Endpoints disagree: MINED ×2, SEEN_ON_NETWORK ×1",
+		},
+		{
+			"disagree on block",
+			[]endpointStatus{status(arc.StatusMined, "00ab", 800000), status(arc.StatusMined, "00cd", 800000)},
+			"⚠ Endpoints disagree on the block: MINED",
+		},
+		{
+			"none answered",
+			[]endpointStatus{failed, failed},
+			"✗ No endpoint answered (2 failed)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, summarizeComparison(tt.results))
+		})
+	}
+}
+
+func TestFindNetwork(t *testing.T) {
+	t.Parallel()
+
+	found := newARCServer(t, &arc.TransactionStatus{TxID: "abc", TxStatus: arc.StatusMined})
+	missing := newARCServer(t, nil)
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		testnet bool
+		want    bool
+		wantErr string
+	}{
+		{
+			name: "found on the selected network",
+			cfg:  &config.Config{ARCMainnet: config.ARCConfig{URL: found}, ARCTestnet: config.ARCConfig{URL: found}},
+			want: false,
+		},
+		{
+			name: "found on the other network",
+			cfg:  &config.Config{ARCMainnet: config.ARCConfig{URL: missing}, ARCTestnet: config.ARCConfig{URL: found}},
+			want: true,
+		},
+		{
+			name:    "found on mainnet from testnet",
+			cfg:     &config.Config{ARCMainnet: config.ARCConfig{URL: found}, ARCTestnet: config.ARCConfig{URL: missing}},
+			testnet: true,
+			want:    false,
+		},
+		{
+			name: "found on neither",
+			cfg:  &config.Config{ARCMainnet: config.ARCConfig{URL: missing}, ARCTestnet: config.ARCConfig{URL: missing}},
+			want: false,
+		},
+		{
+			name: "other network not configured",
+			cfg:  &config.Config{ARCMainnet: config.ARCConfig{URL: missing}},
+			want: false,
+		},
+		{
+			name:    "lookup error",
+			cfg:     &config.Config{ARCMainnet: config.ARCConfig{URL: "http://localhost:1"}, ARCTestnet: config.ARCConfig{URL: found}},
+			want:    false,
+			wantErr: "checking mainnet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := findNetwork(tt.cfg, "abc", tt.testnet)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadStatsEntries(t *testing.T) {
+	t.Parallel()
+
+	txidA := strings.Repeat("a", 64)
+	txidB := strings.Repeat("b", 64)
+	txidC := strings.Repeat("c", 64)
+
+	entries, err := readStatsEntries(strings.NewReader(fmt.Sprintf("# campaign\n%s\n\n%s,2024-01-15T10:00:00Z\n%s 1705312800\n", txidA, txidB, txidC)))
+	require.NoError(t, err)
+	sent := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	require.Len(t, entries, 3)
+	assert.Equal(t, statsEntry{TxID: txidA}, entries[0])
+	assert.Equal(t, txidB, entries[1].TxID)
+	assert.True(t, sent.Equal(entries[1].Sent))
+	assert.True(t, sent.Equal(entries[2].Sent))
+
+	_, err = readStatsEntries(strings.NewReader(txidA + "\nnot-a-txid\n"))
+	require.ErrorContains(t, err, `line 2: invalid txid "not-a-txid"`)
+
+	_, err = readStatsEntries(strings.NewReader(txidA + ",yesterday\n"))
+	require.ErrorContains(t, err, "line 1: invalid broadcast time")
+
+	_, err = readStatsEntries(strings.NewReader("# nothing\n"))
+	require.ErrorContains(t, err, "no txids")
+}
+
+func TestSummarizeStats(t *testing.T) {
+	t.Parallel()
+
+	sent := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	mined := func(after time.Duration) txResult {
+		return txResult{Status: &arc.TransactionStatus{TxStatus: arc.StatusMined, Timestamp: sent.Add(after).Format(time.RFC3339)}}
+	}
+	entries := []statsEntry{
+		{TxID: "a1", Sent: sent},
+		{TxID: "a2", Sent: sent},
+		{TxID: "a3", Sent: sent},
+		{TxID: "a4"},
+		{TxID: "b1"},
+		{TxID: "b2"},
+		{TxID: "c1"},
+		{TxID: "d1"},
+	}
+	results := []txResult{
+		mined(10 * time.Minute),
+		mined(20 * time.Minute),
+		mined(40 * time.Minute),
+		mined(time.Minute), // No broadcast time, so not timed
+		{Status: &arc.TransactionStatus{TxStatus: arc.StatusSeenOnNetwork}},
+		{Status: &arc.TransactionStatus{TxStatus: arc.StatusRejected, ExtraInfo: "missing inputs"}},
+		{Err: fmt.Errorf("%w: c1", arc.ErrTransactionNotFound)},
+		{Err: errors.New("timeout")},
+	}
+
+	summary := summarizeStats(entries, results)
+	assert.Equal(t, 8, summary.Total)
+	assert.Equal(t, map[string]int{
+		arc.StatusMined:         4,
+		arc.StatusSeenOnNetwork: 1,
+		arc.StatusRejected:      1,
+		statusNotFound:          1,
+		statusError:             1,
+	}, summary.Statuses)
+	assert.Equal(t, 3, summary.Timed)
+	require.NotNil(t, summary.MedianSecondsToMine)
+	assert.InDelta(t, 1200.0, *summary.MedianSecondsToMine, 0.001)
+	assert.Equal(t, []txFailure{{TxID: "b2", Status: arc.StatusRejected, Info: "missing inputs"}}, summary.Rejected)
+	assert.Equal(t, []txFailure{{TxID: "d1", Info: "timeout"}}, summary.Errors)
+
+	// Without timed txids there is no median
+	summary = summarizeStats(entries[3:4], results[3:4])
+	assert.Nil(t, summary.MedianSecondsToMine)
+}
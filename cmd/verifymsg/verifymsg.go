@@ -0,0 +1,300 @@
+// Package main implements a Bitcoin SV message signature verifier.
+//
+// This tool verifies a base64-encoded message signature against a BSV address
+// (or public key). It supports Bitcoin Signed Message (BSM) compact signatures
+// as well as BRC-77 signed messages produced by signmsg or compatible wallets.
+// The exit code is non-zero when verification fails, making it suitable for
+// scripted proof-of-key-ownership checks.
+//
+// Features:
+//   - Bitcoin Signed Message (BSM) verification with public key recovery
+//   - BRC-77 verification (anyone, or a specific recipient via --wif)
+//   - Mainnet and testnet addresses, compressed and uncompressed keys
+//   - Message from argument, flag, file, or stdin
+//   - JSON output support
+//
+// Usage:
+//
+//	verifymsg -a <address> -s <sig> "hello world"        # Verify a BSM signature
+//	verifymsg -a <address> -s <sig> -f statement.txt     # Verify a file's contents
+//	echo -n "hello" | verifymsg -a <address> -s <sig>    # Message from stdin
+//	verifymsg -k <pubkey> -s <sig> --brc77 "hello"       # Verify a BRC-77 signature
+//	verifymsg -a <address> -s <sig> -j "hello"           # JSON output
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	"github.com/bsv-blockchain/go-sdk/message"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/cli"
+)
+
+// Signature formats
+const (
+	formatBSM   = "bsm"
+	formatBRC77 = "brc77"
+)
+
+// BRC-77 layout: version (4) + sender pubkey (33) + recipient marker (1 or 33) + key ID (32) + DER signature
+const (
+	brc77VersionLen   = 4
+	brc77PubKeyLen    = 33
+	brc77KeyIDLen     = 32
+	brc77MinSigLen    = 8
+	brc77MinAnyoneLen = brc77VersionLen + brc77PubKeyLen + 1 + brc77KeyIDLen + brc77MinSigLen
+)
+
+// Command-line flags
+var (
+	address    string // Address the signature must belong to
+	pubKeyHex  string // Public key the signature must belong to
+	signature  string // Base64-encoded signature
+	msg        string // Message provided via flag
+	file       string // Path to a file whose contents were signed
+	brc77      bool   // Verify a BRC-77 signature instead of BSM
+	wif        string // Recipient WIF for BRC-77 signatures addressed to a specific key
+	jsonOutput bool   // Output in JSON format
+)
+
+// verifyResult holds the outcome of a verification.
+type verifyResult struct {
+	Valid           bool   `json:"valid"`
+	Format          string `json:"format"`
+	Address         string `json:"address,omitempty"`
+	PublicKey       string `json:"publicKey,omitempty"`
+	SignerPublicKey string `json:"signerPublicKey,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// rootCmd is the main cobra command for the verifymsg tool.
+var rootCmd = &cobra.Command{
+	Use:   "verifymsg [message]",
+	Short: "Verify a signed message against a BSV address",
+	Long: `A command line tool that verifies a Bitcoin Signed Message (BSM) or BRC-77
+signature against an address or public key. Exits non-zero if verification fails.
+
+The message is taken from the argument, --message, --file, or stdin (in that order).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	if signature == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("--signature is required")
+	}
+
+	if address == "" && pubKeyHex == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("--address or --pubkey is required")
+	}
+
+	if wif != "" && !brc77 {
+		return fmt.Errorf("--wif can only be used with --brc77")
+	}
+
+	data, err := getMessage(args)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not valid base64: %w", err)
+	}
+
+	var result *verifyResult
+	if brc77 {
+		var recipientKey *ec.PrivateKey
+		if wif != "" {
+			if recipientKey, err = ec.PrivateKeyFromWif(wif); err != nil {
+				return fmt.Errorf("failed to parse recipient WIF: %w", err)
+			}
+		}
+		result = verifyBRC77(data, sig, address, pubKeyHex, recipientKey)
+	} else {
+		result = verifyBSM(data, sig, address, pubKeyHex)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else if result.Valid {
+		fmt.Println("✓ Signature is valid")
+		if result.SignerPublicKey != "" {
+			fmt.Printf("  Signer: %s\n", result.SignerPublicKey)
+		}
+	} else {
+		fmt.Println("✗ Signature is NOT valid")
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("verification failed: %s", result.Error)
+	}
+	return nil
+}
+
+// getMessage retrieves the message bytes from argument, flag, file, or stdin.
+// The message is returned exactly as provided; no whitespace is trimmed.
+func getMessage(args []string) ([]byte, error) {
+	if len(args) > 0 {
+		return []byte(args[0]), nil
+	}
+
+	if msg != "" {
+		return []byte(msg), nil
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading message file: %w", err)
+		}
+		return data, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading message from stdin: %w", err)
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("no message provided")
+}
+
+// verifyBSM verifies a Bitcoin Signed Message compact signature.
+// The public key is recovered from the signature and compared against the
+// expected address (network-agnostic, by hash160) or public key.
+func verifyBSM(data, sig []byte, expectAddr, expectPubKey string) *verifyResult {
+	result := &verifyResult{Format: formatBSM, Address: expectAddr, PublicKey: expectPubKey}
+
+	recovered, wasCompressed, err := bsm.PubKeyFromSignature(sig, data)
+	if err != nil {
+		result.Error = fmt.Sprintf("recovering public key: %v", err)
+		return result
+	}
+
+	recoveredBytes := recovered.Compressed()
+	if !wasCompressed {
+		recoveredBytes = recovered.Uncompressed()
+	}
+	result.SignerPublicKey = hex.EncodeToString(recoveredBytes)
+
+	if err := matchSigner(recoveredBytes, expectAddr, expectPubKey); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// verifyBRC77 verifies a BRC-77 signed message and checks the embedded
+// sender identity key against the expected address or public key.
+// recipientKey is only needed when the signature was addressed to a specific verifier.
+func verifyBRC77(data, sig []byte, expectAddr, expectPubKey string, recipientKey *ec.PrivateKey) *verifyResult {
+	result := &verifyResult{Format: formatBRC77, Address: expectAddr, PublicKey: expectPubKey}
+
+	// The SDK slices the signature without bounds checks, so validate the length first
+	if len(sig) < brc77MinAnyoneLen {
+		result.Error = fmt.Sprintf("signature too short for BRC-77 (%d bytes)", len(sig))
+		return result
+	}
+	if sig[brc77VersionLen+brc77PubKeyLen] != 0 && len(sig) < brc77MinAnyoneLen+brc77PubKeyLen-1 {
+		result.Error = fmt.Sprintf("signature too short for BRC-77 (%d bytes)", len(sig))
+		return result
+	}
+
+	signerBytes := sig[brc77VersionLen : brc77VersionLen+brc77PubKeyLen]
+	result.SignerPublicKey = hex.EncodeToString(signerBytes)
+
+	ok, err := message.Verify(data, sig, recipientKey)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !ok {
+		result.Error = "signature does not match message"
+		return result
+	}
+
+	if err := matchSigner(signerBytes, expectAddr, expectPubKey); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// matchSigner checks a serialized signer public key against the expected
+// address and public key (either may be empty). Address comparison uses the
+// hash160 so that mainnet and testnet addresses are both accepted.
+func matchSigner(signerPubKey []byte, expectAddr, expectPubKey string) error {
+	if expectPubKey != "" {
+		expected, err := hex.DecodeString(expectPubKey)
+		if err != nil {
+			return fmt.Errorf("invalid public key hex: %w", err)
+		}
+		if !bytes.Equal(expected, signerPubKey) {
+			return fmt.Errorf("signer public key does not match %s", expectPubKey)
+		}
+	}
+
+	if expectAddr != "" {
+		addr, err := script.NewAddressFromString(expectAddr)
+		if err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
+		if !bytes.Equal(addr.PublicKeyHash, crypto.Hash160(signerPubKey)) {
+			return fmt.Errorf("signer does not match address %s", expectAddr)
+		}
+	}
+
+	return nil
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().StringVarP(&address, "address", "a", "", "Address the signature must belong to")
+	rootCmd.Flags().StringVarP(&pubKeyHex, "pubkey", "k", "", "Public key (hex) the signature must belong to")
+	rootCmd.Flags().StringVarP(&signature, "signature", "s", "", "Base64-encoded signature (required)")
+	rootCmd.Flags().StringVarP(&msg, "message", "m", "", "Message that was signed")
+	rootCmd.Flags().StringVarP(&file, "file", "f", "", "Verify against the contents of a file")
+	rootCmd.Flags().BoolVar(&brc77, "brc77", false, "Verify a BRC-77 signed message instead of BSM")
+	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "Recipient WIF for BRC-77 signatures addressed to a specific key")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the verifymsg command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	"github.com/bsv-blockchain/go-sdk/message"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKey generates a fresh key pair and its mainnet and testnet addresses.
+func newTestKey(t *testing.T) (*ec.PrivateKey, string, string) {
+	t.Helper()
+
+	privKey, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	mainAddr, err := script.NewAddressFromPublicKey(privKey.PubKey(), true)
+	require.NoError(t, err)
+	testAddr, err := script.NewAddressFromPublicKey(privKey.PubKey(), false)
+	require.NoError(t, err)
+
+	return privKey, mainAddr.AddressString, testAddr.AddressString
+}
+
+func TestVerifyBSM(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("I own this key")
+
+	t.Run("valid signature against mainnet address", func(t *testing.T) {
+		t.Parallel()
+		privKey, mainAddr, _ := newTestKey(t)
+		sig, err := bsm.SignMessage(privKey, data)
+		require.NoError(t, err)
+
+		result := verifyBSM(data, sig, mainAddr, "")
+		assert.True(t, result.Valid, result.Error)
+		assert.Equal(t, formatBSM, result.Format)
+		assert.Equal(t, hex.EncodeToString(privKey.PubKey().Compressed()), result.SignerPublicKey)
+	})
+
+	t.Run("valid signature against testnet address", func(t *testing.T) {
+		t.Parallel()
+		privKey, _, testAddr := newTestKey(t)
+		sig, err := bsm.SignMessage(privKey, data)
+		require.NoError(t, err)
+
+		result := verifyBSM(data, sig, testAddr, "")
+		assert.True(t, result.Valid, result.Error)
+	})
+
+	t.Run("valid signature against public key", func(t *testing.T) {
+		t.Parallel()
+		privKey, _, _ := newTestKey(t)
+		sig, err := bsm.SignMessage(privKey, data)
+		require.NoError(t, err)
+
+		result := verifyBSM(data, sig, "", hex.EncodeToString(privKey.PubKey().Compressed()))
+		assert.True(t, result.Valid, result.Error)
+	})
+
+	t.Run("uncompressed key signature", func(t *testing.T) {
+		t.Parallel()
+		privKey, _, _ := newTestKey(t)
+		addr, err := script.NewAddressFromPublicKeyWithCompression(privKey.PubKey(), true, false)
+		require.NoError(t, err)
+		sig, err := bsm.SignMessageWithCompression(privKey, data, false)
+		require.NoError(t, err)
+
+		result := verifyBSM(data, sig, addr.AddressString, "")
+		assert.True(t, result.Valid, result.Error)
+	})
+
+	t.Run("wrong address", func(t *testing.T) {
+		t.Parallel()
+		privKey, _, _ := newTestKey(t)
+		_, otherAddr, _ := newTestKey(t)
+		sig, err := bsm.SignMessage(privKey, data)
+		require.NoError(t, err)
+
+		result := verifyBSM(data, sig, otherAddr, "")
+		assert.False(t, result.Valid)
+		assert.Contains(t, result.Error, "does not match address")
+	})
+
+	t.Run("tampered message", func(t *testing.T) {
+		t.Parallel()
+		privKey, mainAddr, _ := newTestKey(t)
+		sig, err := bsm.SignMessage(privKey, data)
+		require.NoError(t, err)
+
+		result := verifyBSM([]byte("I own this key!"), sig, mainAddr, "")
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("garbage signature", func(t *testing.T) {
+		t.Parallel()
+		_, mainAddr, _ := newTestKey(t)
+
+		result := verifyBSM(data, []byte{0x01, 0x02}, mainAddr, "")
+		assert.False(t, result.Valid)
+		assert.Contains(t, result.Error, "recovering public key")
+	})
+}
+
+func TestVerifyBRC77(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("BRC-77 message")
+
+	t.Run("anyone can verify", func(t *testing.T) {
+		t.Parallel()
+		privKey, mainAddr, _ := newTestKey(t)
+		sig, err := message.Sign(data, privKey, nil)
+		require.NoError(t, err)
+
+		result := verifyBRC77(data, sig, mainAddr, "", nil)
+		assert.True(t, result.Valid, result.Error)
+		assert.Equal(t, formatBRC77, result.Format)
+	})
+
+	t.Run("specific recipient", func(t *testing.T) {
+		t.Parallel()
+		privKey, mainAddr, _ := newTestKey(t)
+		recipientKey, _, _ := newTestKey(t)
+		sig, err := message.Sign(data, privKey, recipientKey.PubKey())
+		require.NoError(t, err)
+
+		result := verifyBRC77(data, sig, mainAddr, "", recipientKey)
+		assert.True(t, result.Valid, result.Error)
+
+		result = verifyBRC77(data, sig, mainAddr, "", nil)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		t.Parallel()
+		privKey, _, _ := newTestKey(t)
+		_, otherAddr, _ := newTestKey(t)
+		sig, err := message.Sign(data, privKey, nil)
+		require.NoError(t, err)
+
+		result := verifyBRC77(data, sig, otherAddr, "", nil)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("short signature does not panic", func(t *testing.T) {
+		t.Parallel()
+		_, mainAddr, _ := newTestKey(t)
+
+		result := verifyBRC77(data, []byte{0x42, 0x42, 0x33, 0x01}, mainAddr, "", nil)
+		assert.False(t, result.Valid)
+		assert.Contains(t, result.Error, "too short")
+	})
+}
+
+func TestMatchSigner(t *testing.T) {
+	t.Parallel()
+
+	privKey, mainAddr, _ := newTestKey(t)
+	pub := privKey.PubKey().Compressed()
+
+	require.NoError(t, matchSigner(pub, mainAddr, ""))
+	require.NoError(t, matchSigner(pub, "", hex.EncodeToString(pub)))
+	require.Error(t, matchSigner(pub, "", "zz"))
+	require.Error(t, matchSigner(pub, "not-an-address", ""))
+}
@@ -0,0 +1,347 @@
+// Package main implements a local Bitcoin SV transaction validator.
+//
+// This tool runs every input of a transaction through the script interpreter
+// against the output it spends, checking signatures and script logic the way
+// a node would, and reports which inputs pass and why the others fail. It is
+// a preflight before broadcasting that explains more than a broadcaster's
+// rejection does.
+//
+// Features:
+//   - Script and signature checks for every input with the SDK interpreter
+//   - Per-input pass/fail with the interpreter's error code and reason
+//   - Checks that the inputs cover the outputs, and reports the fee
+//   - Accepts raw, Extended Format (EF), or BEEF hex, or a txid
+//   - Source outputs taken from EF/BEEF, given with --prevout, or fetched from the data provider in config.yaml
+//   - --no-fetch for strictly offline verification
+//   - Exits non-zero when the transaction does not verify
+//   - Mainnet/testnet support
+//   - JSON output support
+//
+// Usage:
+//
+//	verifytx <rawtx>                                  # Fetch source outputs and verify
+//	carve -w <WIF> -a <addr> | verifytx               # Preflight before broadcast
+//	verifytx <ef|beef> --no-fetch                     # Offline; sources come with the tx
+//	verifytx <rawtx> --prevout <txid>:0:1000:<script> # Supply a source output
+//	verifytx <txid> -j                                # Re-verify a mined transaction as JSON
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter/errs"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+// Command-line flags
+var (
+	testnet    bool     // Use testnet instead of mainnet
+	jsonOutput bool     // Output in JSON format
+	prevouts   []string // Source outputs given as txid:vout:satoshis:script
+	noFetch    bool     // Fail inputs with unknown sources instead of fetching them
+)
+
+// inputResult is the verification outcome of one input.
+type inputResult struct {
+	Index    int    `json:"index"`
+	Outpoint string `json:"outpoint"`
+	Satoshis uint64 `json:"satoshis"`
+	Valid    bool   `json:"valid"`
+	Code     string `json:"code,omitempty"`  // Interpreter error code, e.g. ErrEvalFalse
+	Error    string `json:"error,omitempty"` // Why the input failed
+}
+
+// report is the verification outcome of a transaction.
+type report struct {
+	TxID        string        `json:"txid"`
+	Format      string        `json:"format"`
+	Valid       bool          `json:"valid"`
+	Inputs      []inputResult `json:"inputs"`
+	InputTotal  uint64        `json:"inputTotal"`
+	OutputTotal uint64        `json:"outputTotal"`
+	Fee         *int64        `json:"fee,omitempty"`   // Unset when a source output is unknown
+	Error       string        `json:"error,omitempty"` // Transaction-level failure
+	Fetched     int           `json:"fetched"`         // Transactions fetched from the data provider
+}
+
+// rootCmd is the main cobra command for the verifytx tool.
+var rootCmd = &cobra.Command{
+	Use:   "verifytx [tx|txid]",
+	Short: "Verify a transaction's input scripts and signatures locally",
+	Long: `A command line tool that runs every input of a transaction through the script
+interpreter against the output it spends and reports which inputs pass and why
+the others fail. Source outputs come from EF or BEEF input, --prevout, or the
+data provider in config.yaml. Exits non-zero when the transaction does not verify.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	input, err := getInput(args)
+	if err != nil {
+		return err
+	}
+
+	if input == "" {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no transaction provided")
+	}
+
+	if !cli.IsValidHex(input) {
+		return fmt.Errorf("input is not a valid hex string")
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	r := spv.NewResolver(provider.WOC.Client)
+	r.Txs = provider
+	r.NoFetch = noFetch
+	rep, err := verifyInput(ctx, r, input, prevouts)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		if err = encodeJSON(rep); err != nil {
+			return err
+		}
+	} else {
+		printReport(rep)
+	}
+	return reportError(rep)
+}
+
+// getInput returns the input from arguments or stdin.
+func getInput(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		return cli.ReadHexFromReader(os.Stdin)
+	}
+
+	return "", nil
+}
+
+// verifyInput parses input, attaches the source outputs given in specs,
+// fetches the rest unless r.NoFetch is set, and verifies the transaction.
+func verifyInput(ctx context.Context, r *spv.Resolver, input string, specs []string) (*report, error) {
+	b, err := hex.DecodeString(input)
+	if err != nil {
+		return nil, fmt.Errorf("decoding hex: %w", err)
+	}
+
+	var tx *transaction.Transaction
+	format := spv.DetectFormat(b)
+	if format == spv.FormatTxID {
+		tx, err = r.Fetch(ctx, input, false)
+	} else {
+		tx, _, err = spv.ParseTransaction(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tx.IsCoinbase() {
+		return nil, fmt.Errorf("coinbase transactions spend no outputs to verify against")
+	}
+
+	if err = applyPrevouts(tx, specs); err != nil {
+		return nil, err
+	}
+	if !r.NoFetch {
+		if err = r.Sources(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	rep := verify(tx)
+	rep.Format = format
+	rep.Fetched = r.Fetched
+	return rep, nil
+}
+
+// applyPrevouts sets the source output of the input spending each spec, given
+// as txid:vout:satoshis:lockingScriptHex.
+func applyPrevouts(tx *transaction.Transaction, specs []string) error {
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) != 4 {
+			return fmt.Errorf("invalid --prevout %q: want txid:vout:satoshis:script", spec)
+		}
+		txid, err := chainhash.NewHashFromHex(parts[0])
+		if err != nil {
+			return fmt.Errorf("invalid --prevout %q: %w", spec, err)
+		}
+		vout, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid --prevout %q: bad output index", spec)
+		}
+		satoshis, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid --prevout %q: bad satoshis", spec)
+		}
+		lock, err := script.NewFromHex(parts[3])
+		if err != nil {
+			return fmt.Errorf("invalid --prevout %q: bad script: %w", spec, err)
+		}
+
+		matched := false
+		for _, in := range tx.Inputs {
+			if in.SourceTXID.Equal(*txid) && in.SourceTxOutIndex == uint32(vout) {
+				in.SetSourceTxOutput(&transaction.TransactionOutput{Satoshis: satoshis, LockingScript: lock})
+				matched = true
+			}
+		}
+		if !matched {
+			return fmt.Errorf("--prevout %s:%d is not spent by the transaction", parts[0], vout)
+		}
+	}
+	return nil
+}
+
+// verify runs every input of tx through the interpreter against its source
+// output and checks that the inputs cover the outputs. Inputs whose source
+// output is unknown fail.
+func verify(tx *transaction.Transaction) *report {
+	rep := &report{TxID: tx.TxID().String(), Valid: true, Inputs: make([]inputResult, 0, len(tx.Inputs))}
+	known := true
+	for i, in := range tx.Inputs {
+		res := inputResult{Index: i, Outpoint: fmt.Sprintf("%s:%d", in.SourceTXID, in.SourceTxOutIndex)}
+		prev := in.SourceTxOutput()
+		if prev == nil {
+			known = false
+			res.Error = "source output unknown"
+		} else {
+			res.Satoshis = prev.Satoshis
+			rep.InputTotal += prev.Satoshis
+			res.Code, res.Error = execute(tx, i, prev)
+		}
+		res.Valid = res.Error == ""
+		rep.Valid = rep.Valid && res.Valid
+		rep.Inputs = append(rep.Inputs, res)
+	}
+
+	rep.OutputTotal = tx.TotalOutputSatoshis()
+	if known {
+		fee := int64(rep.InputTotal) - int64(rep.OutputTotal) //nolint:gosec // satoshi totals fit in int64
+		rep.Fee = &fee
+		if fee < 0 {
+			rep.Valid = false
+			rep.Error = fmt.Sprintf("outputs spend %d satoshis more than the inputs provide", -fee)
+		}
+	}
+	return rep
+}
+
+// execute runs input i of tx against prev and returns the interpreter's
+// error code and reason, or empty strings when the input verifies.
+func execute(tx *transaction.Transaction, i int, prev *transaction.TransactionOutput) (string, string) {
+	err := interpreter.NewEngine().Execute(
+		interpreter.WithTx(tx, i, prev),
+		interpreter.WithForkID(),
+		interpreter.WithAfterGenesis(),
+	)
+	if err == nil {
+		return "", ""
+	}
+	var scriptErr errs.Error
+	if errors.As(err, &scriptErr) {
+		return scriptErr.ErrorCode.String(), scriptErr.Description
+	}
+	return "", err.Error()
+}
+
+// reportError returns an error summarizing why rep is invalid, or nil.
+func reportError(rep *report) error {
+	if rep.Valid {
+		return nil
+	}
+	failed := 0
+	for _, in := range rep.Inputs {
+		if !in.Valid {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return errors.New(rep.Error)
+	}
+	return fmt.Errorf("%d of %d inputs failed verification", failed, len(rep.Inputs))
+}
+
+// printReport prints rep for a terminal.
+func printReport(rep *report) {
+	fmt.Printf("TxID: %s\n", rep.TxID)
+	for _, in := range rep.Inputs {
+		if in.Valid {
+			fmt.Printf("  ✓ Input %d  %s  %d sats\n", in.Index, in.Outpoint, in.Satoshis)
+			continue
+		}
+		reason := in.Error
+		if in.Code != "" {
+			reason = in.Code + ": " + reason
+		}
+		fmt.Printf("  ✗ Input %d  %s  %s\n", in.Index, in.Outpoint, reason)
+	}
+
+	if rep.Error != "" {
+		fmt.Printf("  ✗ %s\n", rep.Error)
+	} else if rep.Fee != nil {
+		fmt.Printf("Fee: %d satoshis\n", *rep.Fee)
+	}
+	if rep.Valid {
+		fmt.Println("Valid")
+	} else {
+		fmt.Println("Invalid")
+	}
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.Flags().StringArrayVar(&prevouts, "prevout", nil, "Source output as txid:vout:satoshis:script (repeatable)")
+	rootCmd.Flags().BoolVar(&noFetch, "no-fetch", false, "Fail inputs with unknown source outputs instead of fetching them")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the verifytx command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
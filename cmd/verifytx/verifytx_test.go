@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/chain/chaintest"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+// resolver returns a resolver fetching only the given transactions.
+func resolver(txs ...*transaction.Transaction) *spv.Resolver {
+	mock := chain.NewMock()
+	for _, tx := range txs {
+		mock.AddTx(tx)
+	}
+	r := spv.NewResolver(nil)
+	r.Txs = mock
+	return r
+}
+
+// testSpend returns a parent paying 10000 satoshis to private key 1's
+// address, and a signed child paying pay satoshis back to it.
+func testSpend(t *testing.T, pay uint64) (*transaction.Transaction, *transaction.Transaction) {
+	t.Helper()
+
+	key, addr := chaintest.Source(t)
+	lock, err := p2pkh.Lock(addr)
+	require.NoError(t, err)
+
+	parent := transaction.NewTransaction()
+	parent.Outputs = []*transaction.TransactionOutput{{Satoshis: 10000, LockingScript: lock}}
+
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+	child := transaction.NewTransaction()
+	child.AddInputFromTx(parent, 0, unlocker)
+	child.Outputs = []*transaction.TransactionOutput{{Satoshis: pay, LockingScript: lock}}
+	require.NoError(t, child.Sign())
+	return parent, child
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes a signed spend", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 9900)
+		rep := verify(child)
+		assert.True(t, rep.Valid)
+		require.Len(t, rep.Inputs, 1)
+		assert.True(t, rep.Inputs[0].Valid)
+		assert.Equal(t, uint64(10000), rep.Inputs[0].Satoshis)
+		require.NotNil(t, rep.Fee)
+		assert.Equal(t, int64(100), *rep.Fee)
+		require.NoError(t, reportError(rep))
+	})
+
+	t.Run("fails an input whose signature no longer matches", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 9900)
+		child.Outputs[0].Satoshis = 9000
+		rep := verify(child)
+		assert.False(t, rep.Valid)
+		assert.False(t, rep.Inputs[0].Valid)
+		assert.Equal(t, "ErrEvalFalse", rep.Inputs[0].Code)
+		assert.NotEmpty(t, rep.Inputs[0].Error)
+		require.EqualError(t, reportError(rep), "1 of 1 inputs failed verification")
+	})
+
+	t.Run("fails an input with an unknown source", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 9900)
+		stripped, err := transaction.NewTransactionFromHex(child.Hex())
+		require.NoError(t, err)
+		rep := verify(stripped)
+		assert.False(t, rep.Valid)
+		assert.Equal(t, "source output unknown", rep.Inputs[0].Error)
+		assert.Nil(t, rep.Fee)
+	})
+
+	t.Run("fails outputs that spend more than the inputs", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 12000)
+		rep := verify(child)
+		assert.False(t, rep.Valid)
+		assert.True(t, rep.Inputs[0].Valid)
+		require.EqualError(t, reportError(rep), "outputs spend 2000 satoshis more than the inputs provide")
+	})
+}
+
+func TestVerifyInput(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fetches source transactions", func(t *testing.T) {
+		t.Parallel()
+
+		parent, child := testSpend(t, 9900)
+		r := resolver(parent)
+		rep, err := verifyInput(context.Background(), r, child.Hex(), nil)
+		require.NoError(t, err)
+		assert.True(t, rep.Valid)
+		assert.Equal(t, spv.FormatRaw, rep.Format)
+		assert.Equal(t, 1, rep.Fetched)
+	})
+
+	t.Run("uses supplied prevouts offline", func(t *testing.T) {
+		t.Parallel()
+
+		parent, child := testSpend(t, 9900)
+		r := resolver()
+		r.NoFetch = true
+		prevout := fmt.Sprintf("%s:0:10000:%s", parent.TxID(), parent.Outputs[0].LockingScript.String())
+		rep, err := verifyInput(context.Background(), r, child.Hex(), []string{prevout})
+		require.NoError(t, err)
+		assert.True(t, rep.Valid)
+		assert.Zero(t, rep.Fetched)
+	})
+
+	t.Run("uses source outputs from EF offline", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 9900)
+		ef, err := child.EFHex()
+		require.NoError(t, err)
+		r := resolver()
+		r.NoFetch = true
+		rep, err := verifyInput(context.Background(), r, ef, nil)
+		require.NoError(t, err)
+		assert.True(t, rep.Valid)
+		assert.Equal(t, spv.FormatEF, rep.Format)
+	})
+
+	t.Run("fails when a source cannot be fetched", func(t *testing.T) {
+		t.Parallel()
+
+		_, child := testSpend(t, 9900)
+		_, err := verifyInput(context.Background(), resolver(), child.Hex(), nil)
+		require.ErrorContains(t, err, "input 0:")
+	})
+}
+
+func TestApplyPrevouts(t *testing.T) {
+	t.Parallel()
+
+	parent, child := testSpend(t, 9900)
+	txid := parent.TxID().String()
+
+	for name, spec := range map[string]string{
+		"missing fields": txid + ":0:10000",
+		"bad txid":       "zz:0:10000:51",
+		"bad index":      txid + ":x:10000:51",
+		"bad satoshis":   txid + ":0:-1:51",
+		"bad script":     txid + ":0:10000:zz",
+	} {
+		require.ErrorContains(t, applyPrevouts(child, []string{spec}), "invalid --prevout", name)
+	}
+	require.ErrorContains(t, applyPrevouts(child, []string{txid + ":1:10000:51"}), "is not spent by the transaction")
+}
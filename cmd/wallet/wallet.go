@@ -0,0 +1,569 @@
+// Package main implements a minimal persistent Bitcoin SV wallet.
+//
+// This tool ties the individual utilities into one workflow: it keeps keys,
+// UTXOs and history in a password-encrypted file, derives fresh receive and
+// change addresses, builds transactions with carve's builder and broadcasts
+// them through the broadcaster in config.yaml.
+//
+// Features:
+//   - scrypt + AES-256-GCM encrypted wallet file
+//   - HD receive (m/0/i) and change (m/1/i) addresses
+//   - WIF key import
+//   - UTXO tracking via the data provider in config.yaml
+//   - Sends with split outputs and send-all, broadcast via the broadcaster in config.yaml
+//   - Send and receive history
+//   - Multiple named wallets, mainnet/testnet support
+//   - JSON output support
+//
+// The wallet password is read from BSV_WALLET_PASSWORD or prompted for.
+//
+// Usage:
+//
+//	wallet create                        # Create the default mainnet wallet
+//	wallet create -t --name test         # Create a testnet wallet named "test"
+//	wallet import <WIF> -l paper         # Import a WIF key
+//	wallet receive                       # Derive a fresh receive address
+//	wallet addresses                     # List wallet addresses
+//	wallet balance                       # Sync UTXOs and show the balance
+//	wallet send <address> 1000           # Send 1000 satoshis
+//	wallet send <address> 0.001bsv       # Send 100000 satoshis
+//	wallet send <address>                # Send everything to address
+//	wallet send <address> 1000 --no-broadcast # Print the signed tx hex only
+//	wallet history -j                    # Show history as JSON
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+	"github.com/mrz1836/go-template/internal/wallet"
+)
+
+// Command-line flags
+var (
+	name        string // Wallet name
+	walletPath  string // Wallet file (default: ~/.bsv-cmd-line-utils/wallets/<name>.wallet)
+	jsonOutput  bool   // Output in JSON format
+	testnet     bool   // Create a testnet wallet
+	label       string // Label for imported or derived addresses
+	offline     bool   // Skip syncing UTXOs from the data provider
+	split       int    // Number of outputs to split the amount into (1 = no split)
+	feePerKb    uint64 // Fee rate in satoshis per kilobyte
+	noBroadcast bool   // Print the signed transaction instead of broadcasting it
+	debug       bool   // Enable verbose debug logging
+)
+
+// utxoFetcher fetches the unspent outputs of an address.
+type utxoFetcher func(ctx context.Context, addr string) ([]*txbuilder.UTXO, error)
+
+// balanceInfo is the display form of the wallet balance.
+type balanceInfo struct {
+	Confirmed   uint64    `json:"confirmed"`
+	Unconfirmed uint64    `json:"unconfirmed"`
+	Total       uint64    `json:"total"`
+	UTXOs       int       `json:"utxos"`
+	SyncedAt    time.Time `json:"syncedAt,omitzero"`
+}
+
+// sendResult is the display form of a completed send.
+type sendResult struct {
+	TxID   string `json:"txid"`
+	Status string `json:"status,omitempty"`
+	Amount uint64 `json:"amount"`
+	Fee    uint64 `json:"fee"`
+}
+
+// rootCmd is the main cobra command for the wallet tool.
+var rootCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "A minimal persistent key and UTXO store",
+	Long: `A command line wallet that keeps keys, UTXOs and history in a password-encrypted
+file, derives fresh addresses, and sends with carve's transaction builder through
+the providers in config.yaml.
+
+The password is read from the ` + wallet.PasswordEnv + ` environment variable or prompted for.`,
+}
+
+// createCmd creates a new wallet file.
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a new wallet",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runCreate()
+	},
+}
+
+// importCmd adds a WIF key to the wallet.
+var importCmd = &cobra.Command{
+	Use:   "import <WIF>",
+	Short: "Import a WIF private key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return updateWallet(func(w *wallet.Wallet) error {
+			key, err := w.Import(args[0], label, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			return printKey(key)
+		})
+	},
+}
+
+// receiveCmd derives a fresh receive address.
+var receiveCmd = &cobra.Command{
+	Use:   "receive",
+	Short: "Derive a fresh receive address",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return updateWallet(func(w *wallet.Wallet) error {
+			key, err := w.Derive(false, label, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			return printKey(key)
+		})
+	},
+}
+
+// addressesCmd lists the wallet addresses.
+var addressesCmd = &cobra.Command{
+	Use:   "addresses",
+	Short: "List wallet addresses",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		w, _, err := openWallet()
+		if err != nil {
+			return err
+		}
+		return printAddresses(w)
+	},
+}
+
+// balanceCmd syncs UTXOs and shows the balance.
+var balanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "Sync UTXOs and show the wallet balance",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return updateWallet(func(w *wallet.Wallet) error {
+			if !offline {
+				ctx := context.Background()
+				provider, err := chain.Load(ctx, w.Testnet())
+				if err != nil {
+					return err
+				}
+				if err = syncWallet(ctx, w, newBuilder(w, provider).FetchUTXOs, time.Now().UTC()); err != nil {
+					return err
+				}
+			}
+			return printBalance(w)
+		})
+	},
+}
+
+// sendCmd builds, signs and broadcasts a payment.
+var sendCmd = &cobra.Command{
+	Use:   "send <address> [sats]",
+	Short: "Send satoshis to an address (omit sats to send everything)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runSend(args)
+	},
+}
+
+// historyCmd shows wallet history.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show sends and receives",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		w, _, err := openWallet()
+		if err != nil {
+			return err
+		}
+		return printHistory(w.History)
+	},
+}
+
+// resolvePath returns the wallet file path from flags.
+func resolvePath() (string, error) {
+	if walletPath != "" {
+		return walletPath, nil
+	}
+	return wallet.DefaultPath(name)
+}
+
+// runCreate creates and saves a new wallet with its first receive address.
+func runCreate() error {
+	path, err := resolvePath()
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(path); err == nil {
+		return fmt.Errorf("wallet %s already exists", path)
+	}
+
+	password, err := wallet.ReadPassword("New wallet password: ")
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 {
+		return fmt.Errorf("password must not be empty")
+	}
+	if _, ok := os.LookupEnv(wallet.PasswordEnv); !ok && term.IsTerminal(int(os.Stdin.Fd())) { //nolint:gosec // file descriptors fit in an int
+		confirm, err := wallet.ReadPassword("Confirm password: ")
+		if err != nil {
+			return err
+		}
+		if string(confirm) != string(password) {
+			return fmt.Errorf("passwords do not match")
+		}
+	}
+
+	w, err := wallet.New(testnet)
+	if err != nil {
+		return err
+	}
+	key, err := w.Derive(false, label, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if err = wallet.Save(path, w, password); err != nil {
+		return err
+	}
+
+	log.Printf("Created %snet wallet %s\n", w.Network, path)
+	return printKey(key)
+}
+
+// openWallet prompts for the password and loads the wallet.
+func openWallet() (*wallet.Wallet, []byte, error) {
+	path, err := resolvePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err = os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("wallet %s does not exist: run 'wallet create' first", path)
+	}
+
+	password, err := wallet.ReadPassword("Wallet password: ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, err := wallet.Load(path, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, password, nil
+}
+
+// updateWallet loads the wallet, applies fn and saves the result.
+// The wallet is not saved when fn fails.
+func updateWallet(fn func(w *wallet.Wallet) error) error {
+	w, password, err := openWallet()
+	if err != nil {
+		return err
+	}
+
+	if err = fn(w); err != nil {
+		return err
+	}
+
+	path, err := resolvePath()
+	if err != nil {
+		return err
+	}
+	return wallet.Save(path, w, password)
+}
+
+// newBuilder creates the transaction builder for the wallet's network,
+// fetching UTXOs from provider.
+func newBuilder(w *wallet.Wallet, provider *chain.Provider) *txbuilder.Builder {
+	builder := &txbuilder.Builder{FeePerKb: feePerKb, Testnet: w.Testnet(), UTXOs: provider}
+	if debug {
+		builder.Logf = log.Printf
+	}
+	return builder
+}
+
+// syncWallet refreshes the UTXOs of every wallet address.
+func syncWallet(ctx context.Context, w *wallet.Wallet, fetch utxoFetcher, now time.Time) error {
+	for _, addr := range w.Addresses() {
+		utxos, err := fetch(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("failed to sync %s: %w", addr, err)
+		}
+		w.UpdateUTXOs(addr, utxos, now)
+	}
+	w.SyncedAt = now
+	return nil
+}
+
+// parseAmount parses the optional sats argument of send, which may carry a
+// unit as in 0.001bsv (missing = send all).
+func parseAmount(args []string) (uint64, error) {
+	if len(args) < 2 {
+		return 0, nil
+	}
+	return cli.ParseAmount(args[1])
+}
+
+// runSend builds a payment from the wallet's UTXOs and broadcasts it.
+func runSend(args []string) error {
+	amount, err := parseAmount(args)
+	if err != nil {
+		return err
+	}
+	if split < 1 {
+		return fmt.Errorf("--split must be at least 1")
+	}
+	if split > 1 && amount == 0 {
+		return fmt.Errorf("--split requires a specific amount, cannot be used with send-all")
+	}
+
+	w, password, err := openWallet()
+	if err != nil {
+		return err
+	}
+
+	dest, err := script.NewAddressFromString(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid destination address: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := chain.Load(ctx, w.Testnet())
+	if err != nil {
+		return err
+	}
+	builder := newBuilder(w, provider)
+	now := time.Now().UTC()
+
+	if !offline {
+		if err = syncWallet(ctx, w, builder.FetchUTXOs, now); err != nil {
+			return err
+		}
+	}
+
+	tx, err := buildSend(builder, w, dest, amount, now)
+	if err != nil {
+		return err
+	}
+
+	if noBroadcast {
+		fmt.Println(tx.String())
+		return nil
+	}
+
+	resp, err := provider.Broadcast(ctx, tx.String())
+	if err != nil {
+		return fmt.Errorf("broadcasting transaction: %w", err)
+	}
+
+	if err = w.RecordSend(tx, dest.AddressString, amount, now); err != nil {
+		return err
+	}
+	path, err := resolvePath()
+	if err != nil {
+		return err
+	}
+	if err = wallet.Save(path, w, password); err != nil {
+		return fmt.Errorf("transaction %s was broadcast but the wallet could not be saved: %w", resp.TxID, err)
+	}
+
+	entry := w.History[len(w.History)-1]
+	return printSend(&sendResult{TxID: entry.TxID, Status: resp.Status, Amount: entry.Amount, Fee: entry.Fee})
+}
+
+// buildSend selects wallet UTXOs and builds the signed payment.
+// Change goes to a fresh change address; send-all pays everything to dest.
+func buildSend(builder *txbuilder.Builder, w *wallet.Wallet, dest *script.Address, amount uint64, now time.Time) (*transaction.Transaction, error) {
+	utxos := w.Spendable()
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("wallet has no UTXOs")
+	}
+
+	selected := utxos
+	if amount > 0 {
+		var err error
+		if selected, err = builder.SelectUTXOs(utxos, amount); err != nil {
+			return nil, fmt.Errorf("UTXO selection failed: %w", err)
+		}
+	}
+
+	inputs, err := w.Inputs(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	changeAddr := dest
+	if amount > 0 {
+		key, err := w.Derive(true, "", now)
+		if err != nil {
+			return nil, err
+		}
+		if changeAddr, err = script.NewAddressFromString(key.Address); err != nil {
+			return nil, fmt.Errorf("invalid change address: %w", err)
+		}
+	}
+
+	tx, err := builder.Build(inputs, dest, amount, split, changeAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// printKey prints a newly added wallet key.
+func printKey(key *wallet.Key) error {
+	if jsonOutput {
+		return encodeJSON(key)
+	}
+	fmt.Println(key.Address)
+	return nil
+}
+
+// printAddresses prints every wallet address with its origin and label.
+func printAddresses(w *wallet.Wallet) error {
+	if jsonOutput {
+		return encodeJSON(w.Keys)
+	}
+	for _, k := range w.Keys {
+		origin := k.Path
+		switch {
+		case k.Origin != "":
+			origin = k.Origin
+		case k.Imported():
+			origin = "imported"
+		}
+		line := fmt.Sprintf("%-35s %-9s", k.Address, origin)
+		if k.Label != "" {
+			line += " " + k.Label
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
+
+// printBalance prints the confirmed and unconfirmed balance.
+func printBalance(w *wallet.Wallet) error {
+	confirmed, unconfirmed := w.Balance()
+	info := &balanceInfo{
+		Confirmed:   confirmed,
+		Unconfirmed: unconfirmed,
+		Total:       confirmed + unconfirmed,
+		UTXOs:       len(w.UTXOs),
+		SyncedAt:    w.SyncedAt,
+	}
+
+	if jsonOutput {
+		return encodeJSON(info)
+	}
+
+	fmt.Printf("Confirmed:   %d satoshis\n", info.Confirmed)
+	fmt.Printf("Unconfirmed: %d satoshis\n", info.Unconfirmed)
+	fmt.Printf("Total:       %d satoshis (%d UTXOs)\n", info.Total, info.UTXOs)
+	if !info.SyncedAt.IsZero() {
+		fmt.Printf("Synced:      %s\n", info.SyncedAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// printSend prints the result of a broadcast payment.
+func printSend(result *sendResult) error {
+	if jsonOutput {
+		return encodeJSON(result)
+	}
+	fmt.Printf("✓ Sent %d satoshis (fee %d)\n", result.Amount, result.Fee)
+	fmt.Printf("  TxID: %s\n", result.TxID)
+	if result.Status != "" {
+		fmt.Printf("  Status: %s\n", result.Status)
+	}
+	return nil
+}
+
+// printHistory prints history entries, oldest first.
+func printHistory(entries []*wallet.Entry) error {
+	if jsonOutput {
+		if entries == nil {
+			entries = []*wallet.Entry{}
+		}
+		return encodeJSON(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No history")
+		return nil
+	}
+	for _, e := range entries {
+		sign := "+"
+		if e.Type == wallet.EntrySend {
+			sign = "-"
+		}
+		line := fmt.Sprintf("%s  %-7s %s%d  %s", e.Time.Local().Format(time.DateTime), e.Type, sign, e.Amount, e.TxID)
+		if e.Fee > 0 {
+			line += fmt.Sprintf("  fee %d", e.Fee)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// encodeJSON writes v to stdout as indented JSON.
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// init initializes the cobra commands and flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.PersistentFlags().StringVar(&name, "name", "default", "Wallet name")
+	rootCmd.PersistentFlags().StringVar(&walletPath, "file", "", "Wallet file (default: ~/.bsv-cmd-line-utils/wallets/<name>.wallet)")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	createCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Create a testnet wallet")
+	createCmd.Flags().StringVarP(&label, "label", "l", "", "Label for the first receive address")
+
+	importCmd.Flags().StringVarP(&label, "label", "l", "", "Label for the imported address")
+	receiveCmd.Flags().StringVarP(&label, "label", "l", "", "Label for the new address")
+
+	balanceCmd.Flags().BoolVar(&offline, "offline", false, "Show the stored balance without syncing")
+	balanceCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	sendCmd.Flags().IntVarP(&split, "split", "n", 1, "Number of equal outputs to split the amount into (default: 1 = no split)")
+	sendCmd.Flags().Uint64VarP(&feePerKb, "fee-per-kb", "f", 100, "Fee per kilobyte in satoshis")
+	sendCmd.Flags().BoolVar(&noBroadcast, "no-broadcast", false, "Print the signed transaction hex without broadcasting or updating the wallet")
+	sendCmd.Flags().BoolVar(&offline, "offline", false, "Spend the stored UTXOs without syncing")
+	sendCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+
+	rootCmd.AddCommand(createCmd, importCmd, receiveCmd, addressesCmd, balanceCmd, sendCmd, historyCmd)
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the wallet command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/txbuilder"
+	"github.com/mrz1836/go-template/internal/wallet"
+)
+
+const (
+	testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testDest = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// fundedWallet returns a mainnet wallet with one receive address holding a 10000 sat UTXO.
+func fundedWallet(t *testing.T) (*wallet.Wallet, *wallet.Key) {
+	t.Helper()
+
+	w, err := wallet.New(false)
+	require.NoError(t, err)
+	key, err := w.Derive(false, "", testNow)
+	require.NoError(t, err)
+
+	fetch := func(_ context.Context, addr string) ([]*txbuilder.UTXO, error) {
+		if addr == key.Address {
+			return []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 1}}, nil
+		}
+		return nil, nil
+	}
+	require.NoError(t, syncWallet(context.Background(), w, fetch, testNow))
+
+	return w, key
+}
+
+func TestSyncWallet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records UTXOs and sync time", func(t *testing.T) {
+		t.Parallel()
+
+		w, key := fundedWallet(t)
+		require.Len(t, w.UTXOs, 1)
+		assert.Equal(t, key.Address, w.UTXOs[0].Address)
+		assert.Equal(t, testNow, w.SyncedAt)
+
+		confirmed, _ := w.Balance()
+		assert.Equal(t, uint64(10000), confirmed)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := wallet.New(false)
+		require.NoError(t, err)
+		_, err = w.Derive(false, "", testNow)
+		require.NoError(t, err)
+
+		fetch := func(context.Context, string) ([]*txbuilder.UTXO, error) {
+			return nil, errors.New("boom")
+		}
+		err = syncWallet(context.Background(), w, fetch, testNow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to sync")
+		assert.True(t, w.SyncedAt.IsZero())
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+
+	amount, err := parseAmount([]string{testDest})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), amount)
+
+	amount, err = parseAmount([]string{testDest, "1500"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1500), amount)
+
+	amount, err = parseAmount([]string{testDest, "0.0001bsv"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10000), amount)
+
+	_, err = parseAmount([]string{testDest, "-1"})
+	require.Error(t, err)
+}
+
+func TestBuildSend(t *testing.T) {
+	t.Parallel()
+
+	dest, err := script.NewAddressFromString(testDest)
+	require.NoError(t, err)
+	builder := &txbuilder.Builder{FeePerKb: 100}
+
+	t.Run("change goes to a fresh change address", func(t *testing.T) {
+		t.Parallel()
+
+		w, _ := fundedWallet(t)
+		tx, err := buildSend(builder, w, dest, 4000, testNow)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 2)
+
+		assert.Equal(t, uint32(1), w.NextChange)
+		change := w.Keys[len(w.Keys)-1]
+		assert.True(t, change.Change())
+
+		require.NoError(t, w.RecordSend(tx, dest.AddressString, 4000, testNow))
+		require.Len(t, w.UTXOs, 1)
+		assert.Equal(t, change.Address, w.UTXOs[0].Address)
+		assert.Equal(t, uint64(10000-4000-txbuilder.MinFee), w.UTXOs[0].Value)
+	})
+
+	t.Run("send-all pays the destination", func(t *testing.T) {
+		t.Parallel()
+
+		w, _ := fundedWallet(t)
+		tx, err := buildSend(builder, w, dest, 0, testNow)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, uint64(10000-txbuilder.MinFee), tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint32(0), w.NextChange)
+	})
+
+	t.Run("empty wallet", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := wallet.New(false)
+		require.NoError(t, err)
+		_, err = buildSend(builder, w, dest, 1000, testNow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no UTXOs")
+	})
+}
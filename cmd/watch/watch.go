@@ -0,0 +1,450 @@
+// Package main implements a Bitcoin SV address and transaction monitor.
+//
+// This tool polls WhatsOnChain for new transactions touching the given
+// addresses and for status changes of specific txids, printing one event per
+// line as text or JSON so the output can drive other tools or alerting scripts.
+//
+// Features:
+//   - Watch any mix of addresses and txids
+//   - Events for mempool arrival, mining, added confirmations, and reorgs
+//   - Stops watching a txid once it reaches --confirmations
+//   - Txid-only output for piping into getraw/prettytx
+//   - Mainnet/testnet support
+//   - JSON lines output support
+//
+// Usage:
+//
+//	watch <address>                             # Report new transactions for an address
+//	watch <txid> -c 6                           # Follow a txid until 6 confirmations
+//	watch <address> <txid> -n 30s               # Watch both, polling every 30 seconds
+//	watch <address> -j                          # JSON lines
+//	watch <address> -q | xargs -n1 getraw       # Fetch each new transaction
+//	cat addresses.txt | watch                   # Read targets from stdin
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/cli"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorDim    = "\033[2m"
+)
+
+// Event types
+const (
+	eventMempool       = "mempool"       // Transaction seen unconfirmed
+	eventMined         = "mined"         // Transaction included in a block
+	eventConfirmations = "confirmations" // Confirmation count increased
+	eventReorg         = "reorg"         // Transaction left the block it was mined in
+)
+
+// Command-line flags
+var (
+	testnet       bool          // Use testnet instead of mainnet
+	addresses     []string      // Addresses provided via flag
+	txids         []string      // Transaction IDs provided via flag
+	interval      time.Duration // Polling interval
+	confirmations int64         // Confirmations after which a txid is no longer watched
+	existing      bool          // Report an address's existing history on the first poll
+	once          bool          // Poll once and exit
+	quiet         bool          // Print only txids of newly seen transactions
+	jsonOutput    bool          // Output JSON lines
+	noColor       bool          // Disable colored output
+)
+
+// event is a single change observed while watching.
+type event struct {
+	Time          time.Time `json:"time"`
+	Type          string    `json:"type"`
+	TxID          string    `json:"txid"`
+	Address       string    `json:"address,omitempty"`
+	Height        int64     `json:"height,omitempty"`
+	Confirmations int64     `json:"confirmations,omitempty"`
+}
+
+// watcher polls WhatsOnChain and remembers what it has seen so each poll
+// reports only changes.
+type watcher struct {
+	client   whatsonchain.ClientInterface
+	target   int64                       // Confirmations at which a txid is done
+	existing bool                        // Report history found on the first address poll
+	history  map[string]map[string]int64 // Address -> txid -> block height (0 = mempool)
+	txs      map[string]int64            // Watched txid -> confirmations (-1 = not seen yet)
+	now      func() time.Time
+}
+
+// newWatcher creates a watcher for the given addresses and txids.
+func newWatcher(client whatsonchain.ClientInterface, addrs, ids []string, target int64, reportExisting bool) *watcher {
+	w := &watcher{
+		client:   client,
+		target:   target,
+		existing: reportExisting,
+		history:  make(map[string]map[string]int64, len(addrs)),
+		txs:      make(map[string]int64, len(ids)),
+		now:      time.Now,
+	}
+	for _, a := range addrs {
+		w.history[a] = nil
+	}
+	for _, id := range ids {
+		w.txs[id] = -1
+	}
+	return w
+}
+
+// done reports whether there is nothing left to watch.
+func (w *watcher) done() bool {
+	return len(w.history) == 0 && len(w.txs) == 0
+}
+
+// poll checks every target once and returns the events since the last poll.
+// Targets that fail are skipped and their errors joined; the rest still report.
+func (w *watcher) poll(ctx context.Context) ([]event, error) {
+	var events []event
+	var errs []error
+
+	for addr := range w.history {
+		evs, err := w.pollAddress(ctx, addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("address %s: %w", addr, err))
+			continue
+		}
+		events = append(events, evs...)
+	}
+
+	for id := range w.txs {
+		ev, err := w.pollTx(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tx %s: %w", id, err))
+			continue
+		}
+		events = append(events, ev...)
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// pollAddress diffs an address's history against the previous poll.
+func (w *watcher) pollAddress(ctx context.Context, addr string) ([]event, error) {
+	records, err := w.client.AddressHistory(ctx, addr)
+	if err != nil && !errors.Is(err, whatsonchain.ErrAddressNotFound) {
+		return nil, err
+	}
+
+	seen := w.history[addr]
+	first := seen == nil
+	if first {
+		seen = make(map[string]int64, len(records))
+		w.history[addr] = seen
+	}
+
+	var events []event
+	for _, r := range records {
+		height := max(r.Height, 0)
+		prev, known := seen[r.TxHash]
+		seen[r.TxHash] = height
+
+		if first && !w.existing {
+			continue
+		}
+
+		switch {
+		case !known && height == 0:
+			events = append(events, w.event(eventMempool, r.TxHash, addr, 0, 0))
+		case !known || (prev == 0 && height > 0):
+			events = append(events, w.event(eventMined, r.TxHash, addr, height, 0))
+		case prev != height:
+			events = append(events, w.event(eventReorg, r.TxHash, addr, height, 0))
+		}
+	}
+
+	return events, nil
+}
+
+// pollTx checks a watched txid and stops watching it once it reaches the target.
+func (w *watcher) pollTx(ctx context.Context, id string) ([]event, error) {
+	info, err := w.client.GetTxByHash(ctx, id)
+	if err != nil {
+		if w.notFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prev := w.txs[id]
+	conf := max(info.Confirmations, 0)
+	w.txs[id] = conf
+
+	var events []event
+	switch {
+	case prev < 0 && conf == 0:
+		events = append(events, w.event(eventMempool, id, "", 0, 0))
+	case prev <= 0 && conf > 0:
+		events = append(events, w.event(eventMined, id, "", info.BlockHeight, conf))
+	case prev > 0 && conf == 0:
+		events = append(events, w.event(eventReorg, id, "", 0, 0))
+	case conf > prev:
+		events = append(events, w.event(eventConfirmations, id, "", info.BlockHeight, conf))
+	}
+
+	if conf >= w.target {
+		delete(w.txs, id)
+	}
+
+	return events, nil
+}
+
+// notFound reports whether err means the transaction is not known yet.
+func (w *watcher) notFound(err error) bool {
+	if errors.Is(err, whatsonchain.ErrTransactionNotFound) {
+		return true
+	}
+	last := w.client.LastRequest()
+	return last != nil && last.StatusCode == http.StatusNotFound
+}
+
+// event creates a timestamped event.
+func (w *watcher) event(typ, id, addr string, height, conf int64) event {
+	return event{
+		Time:          w.now().UTC(),
+		Type:          typ,
+		TxID:          id,
+		Address:       addr,
+		Height:        height,
+		Confirmations: conf,
+	}
+}
+
+// rootCmd is the main cobra command for the watch tool.
+var rootCmd = &cobra.Command{
+	Use:   "watch [address|txid...]",
+	Short: "Watch addresses and transactions for new activity",
+	Long: `A command line tool that polls WhatsOnChain for new transactions touching the
+given addresses and for status changes of the given txids, printing one event per
+line. Arguments of 64 hex characters are treated as txids, anything else as an
+address. Targets may also be read from stdin, one or more per line.
+
+Txids are dropped once they reach --confirmations; the tool exits when only txids
+were given and all of them are done. Press Ctrl+C to stop.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return run(cmd, args)
+	},
+}
+
+// run handles the main execution flow.
+func run(cmd *cobra.Command, args []string) error {
+	targets, err := getTargets(args)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		cmd.Help() //nolint:errcheck
+		return fmt.Errorf("no address or txid provided")
+	}
+
+	addrs, ids, err := classifyTargets(targets)
+	if err != nil {
+		return err
+	}
+
+	if interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if confirmations < 1 {
+		return fmt.Errorf("--confirmations must be at least 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	provider, err := chain.Load(ctx, testnet)
+	if err != nil {
+		return err
+	}
+
+	// Only WhatsOnChain lists address history with confirmations, whatever the data provider
+	client := provider.WOC.Client
+
+	if !jsonOutput && !quiet {
+		fmt.Fprintf(os.Stderr, "Watching %d address(es) and %d transaction(s) on %s, polling every %s\n",
+			len(addrs), len(ids), client.Network(), interval)
+	}
+
+	w := newWatcher(client, addrs, ids, confirmations, existing)
+	printed := make(map[string]bool)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.poll(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		for _, ev := range events {
+			if printErr := printEvent(ev, printed); printErr != nil {
+				return printErr
+			}
+		}
+
+		// A single poll reports its errors; a continuous watch logs them and retries
+		if once {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error polling: %v\n", err)
+		}
+		if w.done() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// getTargets retrieves addresses and txids from arguments, flags, or stdin.
+func getTargets(args []string) ([]string, error) {
+	targets := append(append(append([]string{}, args...), addresses...), txids...)
+	if len(targets) > 0 {
+		return targets, nil
+	}
+
+	// Check if stdin has data
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			targets = append(targets, strings.Fields(scanner.Text())...)
+		}
+		return targets, scanner.Err()
+	}
+
+	return nil, nil
+}
+
+// classifyTargets splits targets into addresses and txids, dropping duplicates.
+func classifyTargets(targets []string) (addrs, ids []string, err error) {
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		if len(t) == 64 && cli.IsValidHex(t) {
+			ids = append(ids, strings.ToLower(t))
+			continue
+		}
+		if _, err = script.NewAddressFromString(t); err != nil {
+			return nil, nil, fmt.Errorf("%q is neither a txid nor a valid address: %w", t, err)
+		}
+		addrs = append(addrs, t)
+	}
+	return addrs, ids, nil
+}
+
+// c wraps text in an ANSI color code unless color output is disabled.
+func c(color, text string) string {
+	if noColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// eventColor returns the display color for an event type.
+func eventColor(typ string) string {
+	switch typ {
+	case eventMempool:
+		return colorYellow
+	case eventMined:
+		return colorGreen
+	case eventConfirmations:
+		return colorCyan
+	default:
+		return colorRed
+	}
+}
+
+// printEvent writes an event in the selected output format. In quiet mode only
+// the first event for each txid is printed.
+func printEvent(ev event, printed map[string]bool) error {
+	switch {
+	case quiet:
+		if !printed[ev.TxID] {
+			printed[ev.TxID] = true
+			fmt.Println(ev.TxID)
+		}
+		return nil
+	case jsonOutput:
+		return json.NewEncoder(os.Stdout).Encode(ev)
+	}
+
+	line := fmt.Sprintf("%s %s %s",
+		c(colorDim, "["+ev.Time.Local().Format("15:04:05")+"]"),
+		c(eventColor(ev.Type), fmt.Sprintf("%-13s", ev.Type)),
+		ev.TxID)
+	if ev.Address != "" {
+		line += " " + c(colorDim, "address") + " " + ev.Address
+	}
+	if ev.Height > 0 {
+		line += fmt.Sprintf(" %s %d", c(colorDim, "height"), ev.Height)
+	}
+	if ev.Confirmations > 0 {
+		line += fmt.Sprintf(" %s %d", c(colorDim, "confirmations"), ev.Confirmations)
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// init initializes the cobra command flags.
+func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Use testnet instead of mainnet")
+	rootCmd.Flags().StringSliceVarP(&addresses, "address", "a", nil, "Address to watch (repeatable)")
+	rootCmd.Flags().StringSliceVarP(&txids, "txid", "i", nil, "Transaction ID to watch (repeatable)")
+	rootCmd.Flags().DurationVarP(&interval, "interval", "n", 10*time.Second, "Polling interval")
+	rootCmd.Flags().Int64VarP(&confirmations, "confirmations", "c", 1, "Stop watching a txid after this many confirmations")
+	rootCmd.Flags().BoolVar(&existing, "existing", false, "Also report transactions already in an address's history")
+	rootCmd.Flags().BoolVar(&once, "once", false, "Poll once and exit")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the txid of each newly seen transaction")
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output JSON lines")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cli.AddDocCommands(rootCmd)
+}
+
+// main is the entry point for the watch command.
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAddress = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	testTxA     = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testTxB     = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+)
+
+// fakeClient serves canned address history and transaction info; all other
+// client methods are unimplemented.
+type fakeClient struct {
+	whatsonchain.ClientInterface
+
+	history whatsonchain.AddressHistory
+	txs     map[string]*whatsonchain.TxInfo
+	err     error
+}
+
+func (f *fakeClient) AddressHistory(_ context.Context, _ string) (whatsonchain.AddressHistory, error) {
+	return f.history, f.err
+}
+
+func (f *fakeClient) GetTxByHash(_ context.Context, hash string) (*whatsonchain.TxInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if info, ok := f.txs[hash]; ok {
+		return info, nil
+	}
+	return nil, whatsonchain.ErrTransactionNotFound
+}
+
+// types returns the event types in order.
+func types(events []event) []string {
+	result := make([]string, 0, len(events))
+	for _, ev := range events {
+		result = append(result, ev.Type)
+	}
+	return result
+}
+
+func TestPollAddress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("first poll is a baseline", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeClient{history: whatsonchain.AddressHistory{{TxHash: testTxA, Height: 100}}}
+		w := newWatcher(client, []string{testAddress}, nil, 1, false)
+
+		events, err := w.poll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		client.history = append(client.history, &whatsonchain.HistoryRecord{TxHash: testTxB})
+		events, err = w.poll(ctx)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, eventMempool, events[0].Type)
+		assert.Equal(t, testTxB, events[0].TxID)
+		assert.Equal(t, testAddress, events[0].Address)
+	})
+
+	t.Run("existing history is reported", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeClient{history: whatsonchain.AddressHistory{
+			{TxHash: testTxA, Height: 100},
+			{TxHash: testTxB, Height: 0},
+		}}
+		w := newWatcher(client, []string{testAddress}, nil, 1, true)
+
+		events, err := w.poll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{eventMined, eventMempool}, types(events))
+		assert.Equal(t, int64(100), events[0].Height)
+	})
+
+	t.Run("mempool then mined then reorg", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeClient{}
+		w := newWatcher(client, []string{testAddress}, nil, 1, false)
+
+		_, err := w.poll(ctx)
+		require.NoError(t, err)
+
+		client.history = whatsonchain.AddressHistory{{TxHash: testTxA, Height: -1}}
+		events, err := w.poll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{eventMempool}, types(events))
+
+		events, err = w.poll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		client.history = whatsonchain.AddressHistory{{TxHash: testTxA, Height: 101}}
+		events, err = w.poll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{eventMined}, types(events))
+		assert.Equal(t, int64(101), events[0].Height)
+
+		client.history = whatsonchain.AddressHistory{{TxHash: testTxA, Height: 0}}
+		events, err = w.poll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{eventReorg}, types(events))
+		assert.False(t, w.done())
+	})
+
+	t.Run("errors are reported and polling continues", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeClient{err: errors.New("rate limited")}
+		w := newWatcher(client, []string{testAddress}, nil, 1, false)
+
+		_, err := w.poll(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), testAddress)
+
+		client.err = nil
+		client.history = whatsonchain.AddressHistory{{TxHash: testTxA}}
+		_, err = w.poll(ctx)
+		require.NoError(t, err)
+
+		client.history = append(client.history, &whatsonchain.HistoryRecord{TxHash: testTxB})
+		events, err := w.poll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{eventMempool}, types(events))
+	})
+}
+
+func TestPollTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := &fakeClient{txs: map[string]*whatsonchain.TxInfo{}}
+	w := newWatcher(client, nil, []string{testTxA}, 3, false)
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	events, err := w.poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events, "unknown txid produces no event")
+
+	client.txs[testTxA] = &whatsonchain.TxInfo{TxID: testTxA}
+	events, err = w.poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{eventMempool}, types(events))
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), events[0].Time)
+
+	client.txs[testTxA] = &whatsonchain.TxInfo{TxID: testTxA, BlockHeight: 500, Confirmations: 1}
+	events, err = w.poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{eventMined}, types(events))
+	assert.Equal(t, int64(500), events[0].Height)
+
+	client.txs[testTxA] = &whatsonchain.TxInfo{TxID: testTxA, BlockHeight: 500, Confirmations: 1}
+	events, err = w.poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	client.txs[testTxA] = &whatsonchain.TxInfo{TxID: testTxA, BlockHeight: 500, Confirmations: 3}
+	events, err = w.poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{eventConfirmations}, types(events))
+	assert.Equal(t, int64(3), events[0].Confirmations)
+	assert.True(t, w.done(), "txid is dropped once it reaches the target")
+}
+
+func TestPollTxReorg(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := &fakeClient{txs: map[string]*whatsonchain.TxInfo{
+		testTxA: {TxID: testTxA, BlockHeight: 500, Confirmations: 1},
+	}}
+	w := newWatcher(client, nil, []string{testTxA}, 6, false)
+
+	events, err := w.poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{eventMined}, types(events))
+
+	client.txs[testTxA] = &whatsonchain.TxInfo{TxID: testTxA}
+	events, err = w.poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{eventReorg}, types(events))
+	assert.False(t, w.done())
+}
+
+func TestClassifyTargets(t *testing.T) {
+	t.Parallel()
+
+	upper := "4A5E1E4BAAB89F3A32518A88C31BC87F618F76673E2CC77AB2127B7AFDEDA33B"
+	addrs, ids, err := classifyTargets([]string{testAddress, upper, testTxB, testAddress})
+	require.NoError(t, err)
+	assert.Equal(t, []string{testAddress}, addrs)
+	assert.Equal(t, []string{testTxA, testTxB}, ids)
+
+	_, _, err = classifyTargets([]string{"not-an-address"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "neither a txid nor a valid address")
+}
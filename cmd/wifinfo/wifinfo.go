@@ -4,12 +4,17 @@
 // public keys, addresses, and WIF representations for both mainnet and testnet.
 // It detects the original network and compression format of the input WIF.
 //
+// A BIP32 extended key (xprv/xpub/tprv/tpub) or a 64-byte seed given as hex
+// is recognized automatically and reported as an extended key instead: its
+// fingerprint, chain code, depth, and the first receive addresses (0/i).
+//
 // Features:
 //   - Parses and validates WIF private keys
 //   - Detects network (mainnet/testnet) and compression from input
 //   - Displays compressed and uncompressed public keys
 //   - Shows mainnet and testnet addresses (compressed and uncompressed)
 //   - Shows mainnet and testnet WIF (compressed and uncompressed)
+//   - Decodes BIP32 extended keys and 64-byte seeds, deriving their first receive addresses
 //   - JSON output support
 //   - Flexible input: argument, flag, or stdin
 //
@@ -19,30 +24,27 @@
 //	wifinfo -w <wif>                 # Parse WIF from flag
 //	echo <wif> | wifinfo             # Parse WIF from stdin
 //	wifinfo -j <wif>                 # Output as JSON
+//	wifinfo <xprv|xpub>              # Inspect an extended key
+//	wifinfo -c 10 <seed-hex>         # Inspect a seed, deriving 10 addresses
+//	wifinfo -t <seed-hex>            # Derive testnet keys from a seed
 package main
 
 import (
-	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
-	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
 	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
-	"github.com/bsv-blockchain/go-sdk/script"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
 	"github.com/spf13/cobra"
 
 	"github.com/mrz1836/go-template/internal/cli"
-)
-
-// Network prefix bytes for WIF encoding
-const (
-	mainnetWIFPrefix byte = 0x80
-	testnetWIFPrefix byte = 0xef
-	compressMagic    byte = 0x01
-	privateKeyLen         = 32
+	"github.com/mrz1836/go-template/internal/keys"
 )
 
 // ANSI color codes for terminal output styling
@@ -59,8 +61,20 @@ var (
 	jsonFlag    bool   // Output in JSON format
 	showUncompr bool   // Include uncompressed keys, WIFs, and addresses
 	noColor     bool   // Disable colored output
+	count       int    // Receive addresses to derive from an extended key or seed
+	testnet     bool   // Derive testnet keys from a seed
+)
+
+// Extended key and seed detection
+const (
+	seedBytes    = bip32.MaxSeedBytes // Seed length recognized as hex input
+	maxDerived   = 100                // Most receive addresses derived
+	receiveChain = bip32.DefaultExternalChain
 )
 
+// extendedKeyPrefixes are the base58 prefixes of mainnet and testnet extended keys.
+var extendedKeyPrefixes = []string{"xprv", "xpub", "tprv", "tpub"}
+
 // wifInput holds the parsed properties of the input WIF.
 type wifInput struct {
 	WIF        string `json:"wif"`
@@ -94,9 +108,31 @@ type wifInfoResult struct {
 	Testnet   networkInfo   `json:"testnet"`
 }
 
+// derivedAddress is a receive address derived from an extended key.
+type derivedAddress struct {
+	Path    string `json:"path"` // Relative to the input key
+	Address string `json:"address"`
+}
+
+// extendedKeyResult holds the report for an extended key or seed.
+type extendedKeyResult struct {
+	Input             string           `json:"input"`
+	Type              string           `json:"type"` // "xprv", "xpub", or "seed"
+	Network           string           `json:"network"`
+	Depth             uint8            `json:"depth"`
+	ChildNumber       uint32           `json:"child_number"`
+	Fingerprint       string           `json:"fingerprint"`
+	ParentFingerprint string           `json:"parent_fingerprint"`
+	ChainCode         string           `json:"chain_code"`
+	PublicKey         string           `json:"public_key"`
+	XPrv              string           `json:"xprv,omitempty"`
+	XPub              string           `json:"xpub"`
+	Addresses         []derivedAddress `json:"addresses"`
+}
+
 // rootCmd is the main cobra command for the wifinfo tool.
 var rootCmd = &cobra.Command{
-	Use:   "wifinfo [wif]",
+	Use:   "wifinfo [wif|xprv|xpub|seed]",
 	Short: "Display mainnet and testnet details for a BSV private key in WIF format",
 	Long:  "A command line tool that parses a WIF-encoded BSV private key and displays public keys, addresses, and WIF representations for both mainnet and testnet",
 	Args:  cobra.MaximumNArgs(1),
@@ -117,6 +153,26 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no WIF provided")
 	}
 
+	if count < 1 || count > maxDerived {
+		return fmt.Errorf("--count must be between 1 and %d", maxDerived)
+	}
+
+	if testnet && !isSeed(wifString) {
+		return fmt.Errorf("--testnet only applies to a seed; WIFs and extended keys carry their network")
+	}
+
+	if isExtendedKey(wifString) || isSeed(wifString) {
+		result, err := getExtendedKeyInfo(wifString, count, testnet)
+		if err != nil {
+			return err
+		}
+		if jsonFlag {
+			return printJSON(result)
+		}
+		printExtendedHuman(result)
+		return nil
+	}
+
 	result, err := getWIFInfo(wifString)
 	if err != nil {
 		return err
@@ -148,102 +204,20 @@ func getWIF(cmd *cobra.Command, args []string) (string, error) {
 	return "", nil
 }
 
-// parseWIF decodes and validates a WIF string, returning the private key bytes,
-// network, and compression flag.
-func parseWIF(wifString string) (privKeyBytes []byte, isTestnet bool, isCompressed bool, err error) {
-	decoded, err := base58.Decode(wifString)
-	if err != nil {
-		return nil, false, false, fmt.Errorf("invalid base58 encoding: %w", err)
-	}
-
-	decodedLen := len(decoded)
-
-	// Validate length: 1 prefix + 32 privkey + 4 checksum = 37 (uncompressed)
-	//                   1 prefix + 32 privkey + 1 compress + 4 checksum = 38 (compressed)
-	switch decodedLen {
-	case 1 + privateKeyLen + 1 + 4:
-		if decoded[33] != compressMagic {
-			return nil, false, false, fmt.Errorf("invalid compression flag: 0x%02x", decoded[33])
-		}
-		isCompressed = true
-	case 1 + privateKeyLen + 4:
-		isCompressed = false
-	default:
-		return nil, false, false, fmt.Errorf("invalid WIF length: %d bytes", decodedLen)
-	}
-
-	// Detect network
-	switch decoded[0] {
-	case mainnetWIFPrefix:
-		isTestnet = false
-	case testnetWIFPrefix:
-		isTestnet = true
-	default:
-		return nil, false, false, fmt.Errorf("unknown network prefix: 0x%02x", decoded[0])
-	}
-
-	// Validate checksum
-	var payload []byte
-	if isCompressed {
-		payload = decoded[:1+privateKeyLen+1]
-	} else {
-		payload = decoded[:1+privateKeyLen]
-	}
-	expectedChecksum := crypto.Sha256d(payload)[:4]
-	actualChecksum := decoded[decodedLen-4:]
-	if !bytes.Equal(expectedChecksum, actualChecksum) {
-		return nil, false, false, fmt.Errorf("invalid WIF checksum")
-	}
-
-	privKeyBytes = decoded[1 : 1+privateKeyLen]
-	return privKeyBytes, isTestnet, isCompressed, nil
-}
-
-// encodeWIF generates a WIF string from a private key with the given network and compression.
-func encodeWIF(privKeyBytes []byte, isTestnet bool, isCompressed bool) string {
-	prefix := mainnetWIFPrefix
-	if isTestnet {
-		prefix = testnetWIFPrefix
-	}
-
-	size := 1 + privateKeyLen + 4
-	if isCompressed {
-		size++
-	}
-
-	buf := make([]byte, 0, size)
-	buf = append(buf, prefix)
-	buf = append(buf, privKeyBytes...)
-	if isCompressed {
-		buf = append(buf, compressMagic)
-	}
-
-	checksum := crypto.Sha256d(buf)[:4]
-	buf = append(buf, checksum...)
-	return base58.Encode(buf)
-}
-
 // getWIFInfo parses a WIF string and returns all derived information.
 func getWIFInfo(wifString string) (*wifInfoResult, error) {
-	privKeyBytes, isTestnet, isCompressed, err := parseWIF(wifString)
+	w, err := keys.ParseWIF(wifString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse WIF: %w", err)
 	}
-
-	privKey, _ := ec.PrivateKeyFromBytes(privKeyBytes)
-	pubKey := privKey.PubKey()
-
-	network := "mainnet"
-	if isTestnet {
-		network = "testnet"
-	}
+	pubKey := w.Key.PubKey()
 
 	// Generate compressed addresses for both networks
-	mainnetAddrCompressed, err := script.NewAddressFromPublicKeyWithCompression(pubKey, true, true)
+	mainnetAddrCompressed, err := keys.Address(pubKey, false, true)
 	if err != nil {
 		return nil, fmt.Errorf("generating mainnet compressed address: %w", err)
 	}
-	testnetAddrCompressed, err := script.NewAddressFromPublicKeyWithCompression(pubKey, false, true)
+	testnetAddrCompressed, err := keys.Address(pubKey, true, true)
 	if err != nil {
 		return nil, fmt.Errorf("generating testnet compressed address: %w", err)
 	}
@@ -251,32 +225,32 @@ func getWIFInfo(wifString string) (*wifInfoResult, error) {
 	result := &wifInfoResult{
 		Input: wifInput{
 			WIF:        wifString,
-			Network:    network,
-			Compressed: isCompressed,
+			Network:    w.Network(),
+			Compressed: w.Compressed,
 		},
 		PublicKey: publicKeyInfo{
-			Compressed: hex.EncodeToString(pubKey.Compressed()),
+			Compressed: keys.PublicKeyHex(pubKey, true),
 		},
 		Mainnet: networkInfo{
-			WIF:     keyPair{Compressed: encodeWIF(privKeyBytes, false, true)},
+			WIF:     keyPair{Compressed: keys.EncodeWIF(w.Key, false, true)},
 			Address: keyPair{Compressed: mainnetAddrCompressed.AddressString},
 		},
 		Testnet: networkInfo{
-			WIF:     keyPair{Compressed: encodeWIF(privKeyBytes, true, true)},
+			WIF:     keyPair{Compressed: keys.EncodeWIF(w.Key, true, true)},
 			Address: keyPair{Compressed: testnetAddrCompressed.AddressString},
 		},
 	}
 
 	if showUncompr {
-		result.PublicKey.Uncompressed = hex.EncodeToString(pubKey.Uncompressed())
-		result.Mainnet.WIF.Uncompressed = encodeWIF(privKeyBytes, false, false)
-		result.Testnet.WIF.Uncompressed = encodeWIF(privKeyBytes, true, false)
+		result.PublicKey.Uncompressed = keys.PublicKeyHex(pubKey, false)
+		result.Mainnet.WIF.Uncompressed = keys.EncodeWIF(w.Key, false, false)
+		result.Testnet.WIF.Uncompressed = keys.EncodeWIF(w.Key, true, false)
 
-		mainnetAddrUncompressed, err := script.NewAddressFromPublicKeyWithCompression(pubKey, true, false)
+		mainnetAddrUncompressed, err := keys.Address(pubKey, false, false)
 		if err != nil {
 			return nil, fmt.Errorf("generating mainnet uncompressed address: %w", err)
 		}
-		testnetAddrUncompressed, err := script.NewAddressFromPublicKeyWithCompression(pubKey, false, false)
+		testnetAddrUncompressed, err := keys.Address(pubKey, true, false)
 		if err != nil {
 			return nil, fmt.Errorf("generating testnet uncompressed address: %w", err)
 		}
@@ -287,8 +261,127 @@ func getWIFInfo(wifString string) (*wifInfoResult, error) {
 	return result, nil
 }
 
+// isExtendedKey reports whether input looks like a base58 BIP32 extended key.
+func isExtendedKey(input string) bool {
+	for _, prefix := range extendedKeyPrefixes {
+		if strings.HasPrefix(input, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeed reports whether input looks like a 64-byte seed in hex. Shorter hex
+// is not matched, so a raw 32-byte private key is not mistaken for a seed.
+func isSeed(input string) bool {
+	if len(input) != seedBytes*2 {
+		return false
+	}
+	_, err := hex.DecodeString(input)
+	return err == nil
+}
+
+// getExtendedKeyInfo decodes an extended key or seed and derives its first
+// count receive addresses. A seed's master key is for testnet when seedTestnet
+// is set; an extended key carries its own network.
+func getExtendedKeyInfo(input string, count int, seedTestnet bool) (*extendedKeyResult, error) {
+	var (
+		key     *bip32.ExtendedKey
+		keyType string
+		err     error
+	)
+	if isSeed(input) {
+		seed, _ := hex.DecodeString(input)
+		net := &chaincfg.MainNet
+		if seedTestnet {
+			net = &chaincfg.TestNet
+		}
+		if key, err = bip32.NewMaster(seed, net); err != nil {
+			return nil, fmt.Errorf("failed to derive master key from seed: %w", err)
+		}
+		keyType = "seed"
+	} else {
+		if key, err = bip32.NewKeyFromString(input); err != nil {
+			return nil, fmt.Errorf("failed to parse extended key: %w", err)
+		}
+		keyType = "xpub"
+		if key.IsPrivate() {
+			keyType = "xprv"
+		}
+	}
+
+	isTestnet := key.IsForNet(&chaincfg.TestNet)
+	if !isTestnet && !key.IsForNet(&chaincfg.MainNet) {
+		return nil, fmt.Errorf("unknown extended key version")
+	}
+
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("reading public key: %w", err)
+	}
+	xpub, err := key.Neuter()
+	if err != nil {
+		return nil, fmt.Errorf("deriving extended public key: %w", err)
+	}
+
+	// The chain code and child number are only exposed through the serialization:
+	//   version (4) || depth (1) || parent fingerprint (4) ||
+	//   child num (4) || chain code (32) || key data (33) || checksum (4)
+	serialized, err := base58.Decode(key.String())
+	if err != nil {
+		return nil, fmt.Errorf("decoding extended key: %w", err)
+	}
+
+	var parentFP [4]byte
+	binary.BigEndian.PutUint32(parentFP[:], key.ParentFingerprint())
+
+	result := &extendedKeyResult{
+		Input:             input,
+		Type:              keyType,
+		Network:           "mainnet",
+		Depth:             key.Depth(),
+		ChildNumber:       binary.BigEndian.Uint32(serialized[9:13]),
+		Fingerprint:       hex.EncodeToString(crypto.Hash160(pub.Compressed())[:4]),
+		ParentFingerprint: hex.EncodeToString(parentFP[:]),
+		ChainCode:         hex.EncodeToString(serialized[13:45]),
+		PublicKey:         keys.PublicKeyHex(pub, true),
+		XPub:              xpub.String(),
+	}
+	if isTestnet {
+		result.Network = "testnet"
+	}
+	if key.IsPrivate() {
+		result.XPrv = key.String()
+	}
+
+	receive, err := key.Child(receiveChain)
+	if err != nil {
+		return nil, fmt.Errorf("deriving receive chain: %w", err)
+	}
+	for i := range count {
+		child, err := receive.Child(uint32(i)) //nolint:gosec // count is at most maxDerived
+		if err != nil {
+			return nil, fmt.Errorf("deriving %d/%d: %w", receiveChain, i, err)
+		}
+		childPub, err := child.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("deriving %d/%d: %w", receiveChain, i, err)
+		}
+		addr, err := keys.Address(childPub, isTestnet, true)
+		if err != nil {
+			return nil, fmt.Errorf("generating address %d/%d: %w", receiveChain, i, err)
+		}
+		result.Addresses = append(result.Addresses, derivedAddress{
+			Path:    fmt.Sprintf("%d/%d", receiveChain, i),
+			Address: addr.AddressString,
+		})
+	}
+
+	return result, nil
+}
+
 // printJSON outputs the result as formatted JSON.
-func printJSON(result *wifInfoResult) error {
+func printJSON(result any) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	return enc.Encode(result)
@@ -339,12 +432,46 @@ func printHuman(result *wifInfoResult) {
 	fmt.Println(c(colorWhite, line))
 }
 
+// printExtendedHuman outputs an extended key report in human-readable format.
+func printExtendedHuman(result *extendedKeyResult) {
+	line := "────────────────────────────────────────────────────────────────────────"
+
+	fmt.Println(c(colorWhite, line))
+	fmt.Printf("%s %s\n", c(colorDim, "Input:"), c(colorGreen, result.Input))
+	fmt.Printf("%s %s\n", c(colorDim, "Type:"), c(colorGreen, result.Type))
+	fmt.Printf("%s %s\n", c(colorDim, "Network:"), c(colorGreen, result.Network))
+	fmt.Printf("%s %s\n", c(colorDim, "Depth:"), c(colorGreen, fmt.Sprint(result.Depth)))
+	fmt.Printf("%s %s\n", c(colorDim, "Child Number:"), c(colorGreen, fmt.Sprint(result.ChildNumber)))
+	fmt.Printf("%s %s\n", c(colorDim, "Fingerprint:"), c(colorGreen, result.Fingerprint))
+	fmt.Printf("%s %s\n", c(colorDim, "Parent Fingerprint:"), c(colorGreen, result.ParentFingerprint))
+	fmt.Printf("%s %s\n", c(colorDim, "Chain Code:"), c(colorGreen, result.ChainCode))
+	fmt.Printf("%s %s\n", c(colorDim, "Public Key:"), c(colorGreen, result.PublicKey))
+
+	fmt.Println()
+	if result.XPrv != "" {
+		fmt.Printf("%s %s\n", c(colorDim, "xprv:"), c(colorGreen, result.XPrv))
+	}
+	fmt.Printf("%s %s\n", c(colorDim, "xpub:"), c(colorGreen, result.XPub))
+
+	fmt.Printf("\n%s\n", c(colorWhite, "RECEIVE ADDRESSES"))
+	for _, a := range result.Addresses {
+		fmt.Printf("  %s %s\n", c(colorDim, a.Path+":"), c(colorGreen, a.Address))
+	}
+	fmt.Println(c(colorWhite, line))
+}
+
 // init initializes the cobra command flags.
 func init() {
+	rootCmd.PersistentPreRunE = cli.ApplyConfigDefaults
+
 	rootCmd.Flags().StringVarP(&wif, "wif", "w", "", "WIF private key to analyze")
 	rootCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
 	rootCmd.Flags().BoolVarP(&showUncompr, "uncompressed", "u", false, "Include uncompressed keys, WIFs, and addresses")
 	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.Flags().IntVarP(&count, "count", "c", 5, "Receive addresses to derive from an extended key or seed")
+	rootCmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "Derive testnet keys from a seed (default: mainnet)")
+
+	cli.AddDocCommands(rootCmd)
 }
 
 // main is the entry point for the wifinfo command.
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BIP32 test vector 2: a 64-byte seed, its master key, and the child m/0.
+const (
+	testSeed       = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542"
+	testMasterXPrv = "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"
+	testMasterXPub = "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB"
+	testChildXPub  = "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH"
+)
+
+func TestIsExtendedInput(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isExtendedKey(testMasterXPrv))
+	assert.True(t, isExtendedKey(testMasterXPub))
+	assert.True(t, isExtendedKey("tpubD6NzVbkrYhZ4"))
+	assert.False(t, isExtendedKey("L1aW4aubDFB7yfras2S1mN3bqg9nwySY8nkoLmJebSLD5BWv3ENZ"))
+
+	assert.True(t, isSeed(testSeed))
+	assert.False(t, isSeed(testSeed[:64]), "32 bytes of hex is a private key, not a seed")
+	assert.False(t, isSeed(testSeed[:126]+"zz"))
+}
+
+func TestGetExtendedKeyInfo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("seed derives the master key", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := getExtendedKeyInfo(testSeed, 3, false)
+		require.NoError(t, err)
+		assert.Equal(t, "seed", result.Type)
+		assert.Equal(t, "mainnet", result.Network)
+		assert.Equal(t, testMasterXPrv, result.XPrv)
+		assert.Equal(t, testMasterXPub, result.XPub)
+		assert.Equal(t, uint8(0), result.Depth)
+		assert.Equal(t, "bd16bee5", result.Fingerprint)
+		assert.Equal(t, "00000000", result.ParentFingerprint)
+		assert.Equal(t, "60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689", result.ChainCode)
+		require.Len(t, result.Addresses, 3)
+		assert.Equal(t, "0/0", result.Addresses[0].Path)
+		assert.Equal(t, "1NyMg76BQxDvV6vRsQugNS4ED2hpZCJtwK", result.Addresses[0].Address)
+	})
+
+	t.Run("xprv and xpub derive the same addresses", func(t *testing.T) {
+		t.Parallel()
+
+		priv, err := getExtendedKeyInfo(testMasterXPrv, 2, false)
+		require.NoError(t, err)
+		pub, err := getExtendedKeyInfo(testMasterXPub, 2, false)
+		require.NoError(t, err)
+		assert.Equal(t, "xprv", priv.Type)
+		assert.Equal(t, "xpub", pub.Type)
+		assert.Empty(t, pub.XPrv)
+		assert.Equal(t, priv.Fingerprint, pub.Fingerprint)
+		assert.Equal(t, priv.Addresses, pub.Addresses)
+	})
+
+	t.Run("child key reports its parent", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := getExtendedKeyInfo(testChildXPub, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(1), result.Depth)
+		assert.Equal(t, uint32(0), result.ChildNumber)
+		assert.Equal(t, "bd16bee5", result.ParentFingerprint)
+		assert.Equal(t, "f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c", result.ChainCode)
+	})
+
+	t.Run("testnet seed", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := getExtendedKeyInfo(testSeed, 1, true)
+		require.NoError(t, err)
+		assert.Equal(t, "testnet", result.Network)
+		assert.Contains(t, result.XPrv, "tprv")
+		assert.Contains(t, "mn", result.Addresses[0].Address[:1])
+	})
+
+	t.Run("invalid extended key", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := getExtendedKeyInfo(testMasterXPub[:len(testMasterXPub)-1]+"C", 1, false)
+		require.ErrorContains(t, err, "failed to parse extended key")
+	})
+}
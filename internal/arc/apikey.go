@@ -0,0 +1,131 @@
+package arc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefixes of an api_key value that select a provider other than a fixed key
+const (
+	EnvKeyPrefix     = "env:" // env:NAME reads the variable NAME on every request
+	CommandKeyPrefix = "cmd:" // cmd:COMMAND runs COMMAND for a token, refreshed as it ages
+)
+
+// Token lifetimes
+const (
+	commandTokenTTL = 5 * time.Minute  // How long a token printed by a command is used
+	refreshMargin   = 30 * time.Second // How long before expiry a token is refetched
+)
+
+// APIKeyProvider supplies the bearer token sent with each request. It is
+// asked on every request, so a provider can rotate short-lived tokens without
+// the client being recreated.
+type APIKeyProvider interface {
+	APIKey() (string, error)
+}
+
+// invalidator is a provider whose token can be discarded, so the next request
+// fetches a fresh one. The client invalidates the token when ARC rejects it.
+type invalidator interface {
+	Invalidate()
+}
+
+// StaticKey is a fixed API key. An empty key sends no Authorization header.
+type StaticKey string
+
+// APIKey returns the key.
+func (k StaticKey) APIKey() (string, error) {
+	return string(k), nil
+}
+
+// EnvKey is the name of an environment variable holding the API key, read on
+// every request so a token refreshed in the environment is picked up.
+type EnvKey string
+
+// APIKey returns the variable's value, or an error when it is not set.
+func (e EnvKey) APIKey() (string, error) {
+	key, ok := os.LookupEnv(string(e))
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return key, nil
+}
+
+// TokenFetcher fetches a new token and the time it expires. A zero expiry
+// keeps the token until it is rejected.
+type TokenFetcher func() (token string, expires time.Time, err error)
+
+// RotatingKey caches a token from a TokenFetcher and fetches a new one shortly
+// before the cached one expires, or after ARC rejects it.
+type RotatingKey struct {
+	fetch TokenFetcher
+	now   func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewRotatingKey creates a provider that rotates tokens from fetch.
+func NewRotatingKey(fetch TokenFetcher) *RotatingKey {
+	return &RotatingKey{fetch: fetch, now: time.Now}
+}
+
+// APIKey returns the cached token, fetching a new one when there is none or
+// it expires within refreshMargin.
+func (r *RotatingKey) APIKey() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token != "" && (r.expires.IsZero() || r.now().Before(r.expires.Add(-refreshMargin))) {
+		return r.token, nil
+	}
+
+	token, expires, err := r.fetch()
+	if err != nil {
+		return "", fmt.Errorf("fetching API token: %w", err)
+	}
+	if token == "" {
+		return "", errors.New("fetching API token: empty token")
+	}
+	r.token, r.expires = token, expires
+	return token, nil
+}
+
+// Invalidate discards the cached token.
+func (r *RotatingKey) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.token = ""
+}
+
+// CommandFetcher returns a TokenFetcher that runs command with sh and uses
+// the first line it prints as a token valid for commandTokenTTL.
+func CommandFetcher(command string) TokenFetcher {
+	return func() (string, time.Time, error) {
+		out, err := exec.Command("sh", "-c", command).Output() //nolint:gosec // the command comes from the user's config
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("running %q: %w", command, err)
+		}
+		token, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		return strings.TrimSpace(token), time.Now().Add(commandTokenTTL), nil
+	}
+}
+
+// ParseAPIKey returns the provider an api_key config value selects:
+// env:NAME for an environment variable, cmd:COMMAND for tokens printed by a
+// command, and anything else as a fixed key.
+func ParseAPIKey(value string) APIKeyProvider {
+	if name, ok := strings.CutPrefix(value, EnvKeyPrefix); ok {
+		return EnvKey(name)
+	}
+	if command, ok := strings.CutPrefix(value, CommandKeyPrefix); ok {
+		return NewRotatingKey(CommandFetcher(command))
+	}
+	return StaticKey(value)
+}
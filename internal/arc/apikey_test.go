@@ -0,0 +1,140 @@
+package arc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, StaticKey("secret"), ParseAPIKey("secret"))
+	assert.Equal(t, StaticKey(""), ParseAPIKey(""))
+	assert.Equal(t, EnvKey("ARC_TOKEN"), ParseAPIKey("env:ARC_TOKEN"))
+	assert.IsType(t, &RotatingKey{}, ParseAPIKey("cmd:echo token"))
+}
+
+func TestEnvKey(t *testing.T) {
+	t.Setenv("ARC_TEST_TOKEN", "from-env")
+
+	key, err := EnvKey("ARC_TEST_TOKEN").APIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", key)
+
+	_, err = EnvKey("ARC_TEST_TOKEN_UNSET").APIKey()
+	require.ErrorContains(t, err, "ARC_TEST_TOKEN_UNSET is not set")
+}
+
+func TestRotatingKey(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newKey := func(ttl time.Duration) (*RotatingKey, *int, *time.Time) {
+		fetches := 0
+		now := start
+		r := NewRotatingKey(func() (string, time.Time, error) {
+			fetches++
+			expires := time.Time{}
+			if ttl > 0 {
+				expires = now.Add(ttl)
+			}
+			return "token-" + string(rune('0'+fetches)), expires, nil
+		})
+		r.now = func() time.Time { return now }
+		return r, &fetches, &now
+	}
+
+	t.Run("caches the token until shortly before expiry", func(t *testing.T) {
+		t.Parallel()
+		r, fetches, now := newKey(time.Minute)
+
+		key, err := r.APIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", key)
+
+		*now = start.Add(29 * time.Second)
+		key, _ = r.APIKey()
+		assert.Equal(t, "token-1", key)
+
+		*now = start.Add(30 * time.Second)
+		key, _ = r.APIKey()
+		assert.Equal(t, "token-2", key)
+		assert.Equal(t, 2, *fetches)
+	})
+
+	t.Run("keeps a token without expiry until invalidated", func(t *testing.T) {
+		t.Parallel()
+		r, fetches, now := newKey(0)
+
+		_, _ = r.APIKey()
+		*now = start.Add(24 * time.Hour)
+		key, _ := r.APIKey()
+		assert.Equal(t, "token-1", key)
+
+		r.Invalidate()
+		key, _ = r.APIKey()
+		assert.Equal(t, "token-2", key)
+		assert.Equal(t, 2, *fetches)
+	})
+
+	t.Run("reports fetch failures and empty tokens", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewRotatingKey(func() (string, time.Time, error) {
+			return "", time.Time{}, errors.New("denied")
+		}).APIKey()
+		require.ErrorContains(t, err, "fetching API token: denied")
+
+		_, err = NewRotatingKey(func() (string, time.Time, error) {
+			return "", time.Time{}, nil
+		}).APIKey()
+		require.ErrorContains(t, err, "empty token")
+	})
+}
+
+func TestCommandFetcher(t *testing.T) {
+	t.Parallel()
+
+	token, expires, err := CommandFetcher("printf 'abc\\nignored\\n'")()
+	require.NoError(t, err)
+	assert.Equal(t, "abc", token)
+	assert.WithinDuration(t, time.Now().Add(commandTokenTTL), expires, 5*time.Second)
+
+	_, _, err = CommandFetcher("exit 3")()
+	require.ErrorContains(t, err, `running "exit 3"`)
+}
+
+func TestClientRotatesRejectedKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"txid":"abc","txStatus":"MINED"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var fetches atomic.Int32
+	client := NewARCClientWithKeys(server.URL, NewRotatingKey(func() (string, time.Time, error) {
+		return "token-" + string(rune('0'+fetches.Add(1))), time.Time{}, nil
+	}))
+
+	status, err := client.GetTransactionStatus("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", status.TxID)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// A fixed key is not retried
+	client.SetAPIKeyProvider(StaticKey("wrong"))
+	_, err = client.GetTransactionStatus("def")
+	require.Error(t, err)
+}
@@ -5,15 +5,24 @@
 // The package supports:
 //   - Broadcasting raw transactions to the BSV network via ARC
 //   - Checking transaction status and tracking transaction lifecycle
+//   - Optional status caching with a short TTL and ETag revalidation, for polling
+//   - Fetching the node's transaction policy (mining fee, size limits)
+//   - API keys that are fixed, read from the environment, or rotated by a token fetcher
+//   - Receiving status callbacks pushed by ARC to a registered URL, validated and
+//     convertible to the polled TransactionStatus
+//   - Holding a broadcast response until the transaction reaches a chosen status
 //   - Full ARC status enumeration (RECEIVED, STORED, ANNOUNCED_TO_NETWORK, SEEN_ON_NETWORK, MINED, etc.)
 //   - Helper functions for status visualization and description
 package arc
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,11 +38,29 @@ const (
 	StatusDoubleSpend        = "DOUBLE_SPEND_ATTEMPTED"
 )
 
+// ErrTransactionNotFound is returned by GetTransactionStatus when ARC does not know the transaction
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 // ARCClient handles communication with ARC endpoints
 type ARCClient struct {
-	baseURL string
-	apiKey  string
-	client  *http.Client
+	baseURL       string
+	keys          APIKeyProvider // Bearer token for each request
+	callbackURL   string
+	callbackToken string
+	waitFor       string // Status ARC waits for before answering a broadcast
+	client        *http.Client
+
+	cacheMu  sync.Mutex
+	cache    map[string]*cachedStatus // Status by txid, nil when caching is off
+	cacheTTL time.Duration            // How long a cached status is served without a request
+	now      func() time.Time
+}
+
+// cachedStatus is a status response kept for reuse and revalidation.
+type cachedStatus struct {
+	status  TransactionStatus
+	etag    string    // ETag from the response, sent back as If-None-Match
+	fetched time.Time // When the status was last fetched or revalidated
 }
 
 // TransactionRequest represents a transaction broadcast request
@@ -59,21 +86,118 @@ type TransactionStatus struct {
 	BlockHeight int64  `json:"blockHeight,omitempty"`
 }
 
+// MiningFee is a fee rate expressed as satoshis per number of bytes
+type MiningFee struct {
+	Satoshis uint64 `json:"satoshis"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// Policy represents the transaction acceptance policy of an ARC node
+type Policy struct {
+	MaxScriptSizePolicy     uint64    `json:"maxscriptsizepolicy"`
+	MaxTxSigOpsCountsPolicy uint64    `json:"maxtxsigopscountspolicy"`
+	MaxTxSizePolicy         uint64    `json:"maxtxsizepolicy"`
+	MiningFee               MiningFee `json:"miningFee"`
+}
+
+// PolicyResponse represents the response from the ARC policy endpoint
+type PolicyResponse struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Policy    Policy `json:"policy"`
+}
+
 // ErrorResponse represents an error response from ARC
 type ErrorResponse struct {
 	Status int    `json:"status"`
 	Code   int    `json:"code"`
 	Error  string `json:"error"`
+	Detail string `json:"detail"`
 }
 
-// NewARCClient creates a new ARC client
+// NewARCClient creates a new ARC client. The API key is a config value, as
+// parsed by ParseAPIKey.
 func NewARCClient(baseURL, apiKey string) *ARCClient {
+	return NewARCClientWithKeys(baseURL, ParseAPIKey(apiKey))
+}
+
+// NewARCClientWithKeys creates a new ARC client that asks keys for the API
+// key on every request.
+func NewARCClientWithKeys(baseURL string, keys APIKeyProvider) *ARCClient {
 	return &ARCClient{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
-		apiKey:  apiKey,
+		keys:    keys,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		now: time.Now,
+	}
+}
+
+// SetStatusCache enables caching in GetTransactionStatus. A status fetched
+// within ttl is returned without a request; an older one is revalidated with
+// its ETag, so an unchanged status costs ARC a 304 rather than a lookup.
+// A ttl of zero keeps only the revalidation.
+func (c *ARCClient) SetStatusCache(ttl time.Duration) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.cache = make(map[string]*cachedStatus)
+	c.cacheTTL = ttl
+}
+
+// SetCallback registers a URL that ARC posts status updates to for every
+// transaction broadcast afterwards. ARC sends token as a bearer token.
+func (c *ARCClient) SetCallback(url, token string) {
+	c.callbackURL = url
+	c.callbackToken = token
+}
+
+// SetWaitFor asks ARC to hold the response to every transaction broadcast
+// afterwards until the transaction reaches status, or ARC's timeout passes.
+// An empty status restores ARC's default.
+func (c *ARCClient) SetWaitFor(status string) {
+	c.waitFor = status
+}
+
+// SetEndpoint points the client at another ARC endpoint and API key, as when
+// config.yaml is reloaded. Cached statuses are kept, since they describe
+// transactions rather than the endpoint.
+func (c *ARCClient) SetEndpoint(baseURL string, keys APIKeyProvider) {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	c.keys = keys
+}
+
+// SetAPIKeyProvider replaces the provider the API key is asked from.
+func (c *ARCClient) SetAPIKeyProvider(keys APIKeyProvider) {
+	c.keys = keys
+}
+
+// do sends the request built by newReq with the current API key. When ARC
+// answers 401 and the key can be rotated, the key is discarded and the
+// request rebuilt and sent once more with a fresh one.
+func (c *ARCClient) do(newReq func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		key, err := c.keys.APIKey()
+		if err != nil {
+			return nil, fmt.Errorf("getting API key: %w", err)
+		}
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		rotator, ok := c.keys.(invalidator)
+		if resp.StatusCode != http.StatusUnauthorized || !ok || attempt > 0 {
+			return resp, nil
+		}
+		resp.Body.Close()
+		rotator.Invalidate()
 	}
 }
 
@@ -90,19 +214,25 @@ func (c *ARCClient) BroadcastTransaction(rawTx string) (*TransactionResponse, er
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.do(func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.callbackURL != "" {
+			req.Header.Set("X-CallbackUrl", c.callbackURL)
+			if c.callbackToken != "" {
+				req.Header.Set("X-CallbackToken", c.callbackToken)
+			}
+		}
+		if c.waitFor != "" {
+			req.Header.Set("X-WaitFor", c.waitFor)
+		}
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -129,20 +259,43 @@ func (c *ARCClient) BroadcastTransaction(rawTx string) (*TransactionResponse, er
 func (c *ARCClient) GetTransactionStatus(txid string) (*TransactionStatus, error) {
 	url := c.baseURL + "/v1/tx/" + txid
 
-	req, err := http.NewRequest("GET", url, nil)
+	cached := c.lookupCache(txid)
+	if cached != nil && c.now().Sub(cached.fetched) < c.cacheTTL {
+		status := cached.status
+		return &status, nil
+	}
+
+	resp, err := c.do(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil && cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.storeStatus(txid, &cachedStatus{status: cached.status, etag: cached.etag, fetched: c.now()})
+		status := cached.status
+		return &status, nil
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	if resp.StatusCode == http.StatusNotFound {
+		// ARC explains a 404 in detail, and older versions in error; the body
+		// is optional
+		var errorResp ErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errorResp)
+		if detail := cmp.Or(errorResp.Detail, errorResp.Error); detail != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrTransactionNotFound, txid, detail)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, txid)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errorResp ErrorResponse
@@ -160,9 +313,66 @@ func (c *ARCClient) GetTransactionStatus(txid string) (*TransactionStatus, error
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.storeStatus(txid, &cachedStatus{status: status, etag: resp.Header.Get("ETag"), fetched: c.now()})
 	return &status, nil
 }
 
+// lookupCache returns the cached status of txid, or nil when there is none
+// or caching is off.
+func (c *ARCClient) lookupCache(txid string) *cachedStatus {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	return c.cache[txid]
+}
+
+// storeStatus caches a status if caching is on.
+func (c *ARCClient) storeStatus(txid string, entry *cachedStatus) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if c.cache != nil {
+		c.cache[txid] = entry
+	}
+}
+
+// GetPolicy fetches the transaction acceptance policy, including the mining fee
+func (c *ARCClient) GetPolicy() (*PolicyResponse, error) {
+	url := c.baseURL + "/v1/policy"
+
+	resp, err := c.do(func() (*http.Request, error) {
+		return http.NewRequest("GET", url, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
+			return nil, fmt.Errorf("request failed with status %d: %w", resp.StatusCode, err)
+		}
+		if errorResp.Error == "" {
+			return nil, fmt.Errorf("request failed with HTTP status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("ARC error: %s (HTTP %d, code: %d)", errorResp.Error, resp.StatusCode, errorResp.Code)
+	}
+
+	var policy PolicyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// FeePerKb returns the mining fee as a rate in satoshis per 1000 bytes
+func (f MiningFee) FeePerKb() float64 {
+	if f.Bytes == 0 {
+		return 0
+	}
+	return float64(f.Satoshis) * 1000 / float64(f.Bytes)
+}
+
 // IsTransactionFinal returns true if the transaction has reached a final state
 func IsTransactionFinal(status string) bool {
 	switch status {
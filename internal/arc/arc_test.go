@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +19,7 @@ func TestNewARCClient(t *testing.T) {
 		client := NewARCClient("https://api.taal.com/arc", "test-api-key")
 		require.NotNil(t, client)
 		assert.Equal(t, "https://api.taal.com/arc", client.baseURL)
-		assert.Equal(t, "test-api-key", client.apiKey)
+		assert.Equal(t, StaticKey("test-api-key"), client.keys)
 		require.NotNil(t, client.client)
 	})
 
@@ -31,7 +32,7 @@ func TestNewARCClient(t *testing.T) {
 	t.Run("handles empty API key", func(t *testing.T) {
 		t.Parallel()
 		client := NewARCClient("https://api.taal.com/arc", "")
-		assert.Equal(t, "", client.apiKey)
+		assert.Equal(t, StaticKey(""), client.keys)
 	})
 
 	t.Run("handles multiple trailing slashes", func(t *testing.T) {
@@ -40,6 +41,14 @@ func TestNewARCClient(t *testing.T) {
 		// TrimSuffix only removes one slash
 		assert.Equal(t, "https://api.taal.com/arc//", client.baseURL)
 	})
+
+	t.Run("switches endpoint", func(t *testing.T) {
+		t.Parallel()
+		client := NewARCClient("https://api.taal.com/arc", "old-key")
+		client.SetEndpoint("https://arc.gorillapool.io/", EnvKey("ARC_TOKEN"))
+		assert.Equal(t, "https://arc.gorillapool.io", client.baseURL)
+		assert.Equal(t, EnvKey("ARC_TOKEN"), client.keys)
+	})
 }
 
 func TestBroadcastTransaction(t *testing.T) {
@@ -104,6 +113,45 @@ func TestBroadcastTransaction(t *testing.T) {
 		assert.Equal(t, "abc123", resp.TxID)
 	})
 
+	t.Run("sends callback headers when a callback is set", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "https://example.com/callback", r.Header.Get("X-CallbackUrl"))
+			assert.Equal(t, "secret", r.Header.Get("X-CallbackToken"))
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(TransactionResponse{TxID: "abc"})
+		}))
+		defer server.Close()
+
+		client := NewARCClient(server.URL, "")
+		client.SetCallback("https://example.com/callback", "secret")
+		_, err := client.BroadcastTransaction("0100000001...")
+		require.NoError(t, err)
+	})
+
+	t.Run("sends the wait-for header when a status is set", func(t *testing.T) {
+		t.Parallel()
+
+		var waitFor []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			waitFor = append(waitFor, r.Header.Get("X-WaitFor"))
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(TransactionResponse{TxID: "abc", TxStatus: StatusSeenOnNetwork})
+		}))
+		defer server.Close()
+
+		client := NewARCClient(server.URL, "")
+		client.SetWaitFor(StatusSeenOnNetwork)
+		_, err := client.BroadcastTransaction("0100000001...")
+		require.NoError(t, err)
+		client.SetWaitFor("")
+		_, err = client.BroadcastTransaction("0100000001...")
+		require.NoError(t, err)
+		assert.Equal(t, []string{StatusSeenOnNetwork, ""}, waitFor)
+	})
+
 	t.Run("no authorization header when API key is empty", func(t *testing.T) {
 		t.Parallel()
 
@@ -245,9 +293,24 @@ func TestGetTransactionStatus(t *testing.T) {
 		client := NewARCClient(server.URL, "test-key")
 		result, err := client.GetTransactionStatus("nonexistent")
 
-		require.Error(t, err)
+		require.ErrorIs(t, err, ErrTransactionNotFound)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "Transaction not found")
+		assert.Contains(t, err.Error(), "nonexistent")
+	})
+
+	t.Run("not found with a problem detail", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Status: 404, Detail: "The requested resource could not be found"})
+		}))
+		defer server.Close()
+
+		_, err := NewARCClient(server.URL, "test-key").GetTransactionStatus("nonexistent")
+		require.ErrorIs(t, err, ErrTransactionNotFound)
+		assert.Equal(t, "transaction not found: nonexistent: The requested resource could not be found", err.Error())
 	})
 
 	t.Run("no authorization header when API key is empty", func(t *testing.T) {
@@ -278,6 +341,146 @@ func TestGetTransactionStatus(t *testing.T) {
 	})
 }
 
+func TestGetTransactionStatusCache(t *testing.T) {
+	t.Parallel()
+
+	// newServer serves a MINED status with an ETag, answering 304 when the
+	// client already has it, and counts requests and revalidations
+	newServer := func(t *testing.T, etag string) (string, *int, *int) {
+		t.Helper()
+		var requests, revalidated int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if etag != "" && r.Header.Get("If-None-Match") == etag {
+				revalidated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			if etag != "" {
+				w.Header().Set("ETag", etag)
+			}
+			_ = json.NewEncoder(w).Encode(TransactionStatus{TxID: "abc", TxStatus: StatusMined, BlockHeight: 800000})
+		}))
+		t.Cleanup(server.Close)
+		return server.URL, &requests, &revalidated
+	}
+
+	t.Run("serves fresh statuses without a request", func(t *testing.T) {
+		t.Parallel()
+
+		url, requests, _ := newServer(t, "")
+		client := NewARCClient(url, "")
+		client.SetStatusCache(time.Minute)
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		first, err := client.GetTransactionStatus("abc")
+		require.NoError(t, err)
+		first.TxStatus = "changed by caller"
+
+		second, err := client.GetTransactionStatus("abc")
+		require.NoError(t, err)
+		assert.Equal(t, StatusMined, second.TxStatus, "callers get copies")
+		assert.Equal(t, 1, *requests)
+
+		now = now.Add(time.Minute)
+		_, err = client.GetTransactionStatus("abc")
+		require.NoError(t, err)
+		assert.Equal(t, 2, *requests, "expired statuses are fetched again")
+	})
+
+	t.Run("revalidates with the ETag", func(t *testing.T) {
+		t.Parallel()
+
+		url, requests, revalidated := newServer(t, `"v1"`)
+		client := NewARCClient(url, "")
+		client.SetStatusCache(0)
+
+		for range 3 {
+			status, err := client.GetTransactionStatus("abc")
+			require.NoError(t, err)
+			assert.Equal(t, StatusMined, status.TxStatus)
+			assert.Equal(t, int64(800000), status.BlockHeight)
+		}
+		assert.Equal(t, 3, *requests)
+		assert.Equal(t, 2, *revalidated)
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+
+		url, requests, revalidated := newServer(t, `"v1"`)
+		client := NewARCClient(url, "")
+		for range 2 {
+			_, err := client.GetTransactionStatus("abc")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *requests)
+		assert.Zero(t, *revalidated)
+	})
+}
+
+func TestGetPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful policy fetch", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "/v1/policy", r.URL.Path)
+			assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"timestamp": "2024-01-15T10:30:00Z",
+				"policy": {
+					"maxscriptsizepolicy": 100000000,
+					"maxtxsigopscountspolicy": 4294967295,
+					"maxtxsizepolicy": 100000000,
+					"miningFee": {"satoshis": 1, "bytes": 1000}
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		client := NewARCClient(server.URL, "test-key")
+		result, err := client.GetPolicy()
+
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, uint64(100000000), result.Policy.MaxTxSizePolicy)
+		assert.Equal(t, uint64(1), result.Policy.MiningFee.Satoshis)
+		assert.Equal(t, uint64(1000), result.Policy.MiningFee.Bytes)
+		assert.InDelta(t, 1.0, result.Policy.MiningFee.FeePerKb(), 0.0001)
+	})
+
+	t.Run("handles error response", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(ErrorResponse{Status: 401, Code: 401, Error: "Unauthorized"})
+		}))
+		defer server.Close()
+
+		client := NewARCClient(server.URL, "bad-key")
+		result, err := client.GetPolicy()
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "Unauthorized")
+	})
+}
+
+func TestMiningFeePerKb(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 50.0, MiningFee{Satoshis: 50, Bytes: 1000}.FeePerKb(), 0.0001)
+	assert.InDelta(t, 500.0, MiningFee{Satoshis: 1, Bytes: 2}.FeePerKb(), 0.0001)
+	assert.Zero(t, MiningFee{Satoshis: 1}.FeePerKb())
+}
+
 func TestIsTransactionFinal(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,129 @@
+package arc
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxCallbackSize bounds the body of a callback request.
+const maxCallbackSize = 1 << 20
+
+// Errors returned by ParseCallback for requests that are not callbacks at all,
+// as opposed to callbacks with an invalid body.
+var (
+	ErrCallbackMethod       = errors.New("callback must be a POST")
+	ErrCallbackUnauthorized = errors.New("callback token missing or wrong")
+)
+
+// Callback is a status update ARC posts to a transaction's callback URL
+type Callback struct {
+	TxID         string   `json:"txid"`
+	TxStatus     string   `json:"txStatus"`
+	ExtraInfo    string   `json:"extraInfo,omitempty"`
+	Timestamp    string   `json:"timestamp,omitempty"`
+	BlockHash    string   `json:"blockHash,omitempty"`
+	BlockHeight  int64    `json:"blockHeight,omitempty"`
+	MerklePath   string   `json:"merklePath,omitempty"`
+	CompetingTxs []string `json:"competingTxs,omitempty"`
+}
+
+// Status returns the callback as the TransactionStatus GetTransactionStatus
+// reports, so pushed and polled updates can be handled alike.
+func (c *Callback) Status() *TransactionStatus {
+	return &TransactionStatus{
+		TxID:        c.TxID,
+		TxStatus:    c.TxStatus,
+		ExtraInfo:   c.ExtraInfo,
+		Timestamp:   c.Timestamp,
+		BlockHash:   c.BlockHash,
+		BlockHeight: c.BlockHeight,
+	}
+}
+
+// ParseCallback reads and validates an ARC callback request. When token is
+// set, the request must carry it as a bearer token, as ARC sends the
+// X-CallbackToken given at broadcast. It returns ErrCallbackMethod or
+// ErrCallbackUnauthorized for a request that is not a callback, and any other
+// error for a body that is not a valid status update.
+func ParseCallback(r *http.Request, token string) (*Callback, error) {
+	if r.Method != http.MethodPost {
+		return nil, ErrCallbackMethod
+	}
+
+	if token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, ErrCallbackUnauthorized
+		}
+	}
+
+	var callback Callback
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxCallbackSize)).Decode(&callback); err != nil {
+		return nil, fmt.Errorf("invalid callback: %w", err)
+	}
+	if err := callback.validate(); err != nil {
+		return nil, fmt.Errorf("invalid callback: %w", err)
+	}
+	return &callback, nil
+}
+
+// validate checks the fields every consumer relies on.
+func (c *Callback) validate() error {
+	switch {
+	case c.TxID == "":
+		return errors.New("missing txid")
+	case !isTxID(c.TxID):
+		return fmt.Errorf("txid %q is not 64 hex characters", c.TxID)
+	case c.TxStatus == "":
+		return errors.New("missing txStatus")
+	case c.BlockHeight < 0:
+		return fmt.Errorf("negative blockHeight %d", c.BlockHeight)
+	case c.TxStatus == StatusMined && c.BlockHash == "":
+		return errors.New("MINED callback without blockHash")
+	case c.BlockHash != "" && !isTxID(c.BlockHash):
+		return fmt.Errorf("blockHash %q is not 64 hex characters", c.BlockHash)
+	}
+	if c.Timestamp != "" {
+		if _, err := time.Parse(time.RFC3339Nano, c.Timestamp); err != nil {
+			return fmt.Errorf("timestamp %q is not RFC 3339", c.Timestamp)
+		}
+	}
+	return nil
+}
+
+// isTxID reports whether s is a 32-byte hash in hex, as txids and block
+// hashes are.
+func isTxID(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == 32
+}
+
+// CallbackHandler returns an HTTP handler that receives ARC callbacks and
+// passes each valid one to fn. When token is set, requests must carry it as
+// a bearer token; see ParseCallback.
+func CallbackHandler(token string, fn func(*Callback)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callback, err := ParseCallback(r, token)
+		switch {
+		case errors.Is(err, ErrCallbackMethod):
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case errors.Is(err, ErrCallbackUnauthorized):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fn(callback)
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,126 @@
+package arc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Hashes used in callback bodies.
+const (
+	callbackTxID  = "d45a84e34a6cf93e9b5b7d1ef1ab91ea08a7ff8daa9fab5b7bdb4796a563b7ed"
+	callbackBlock = "000000000000000004ad1ef8e5e8d0bd5b5e07a20b0d32e5a7a4e5a7b9ec0c31"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var received []*Callback
+	server := httptest.NewServer(CallbackHandler("secret", func(cb *Callback) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, cb)
+	}))
+	defer server.Close()
+
+	post := func(t *testing.T, auth, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	mined := `{"txid":"` + callbackTxID + `","txStatus":"MINED","blockHash":"` + callbackBlock + `","blockHeight":800000,"merklePath":"fe"}`
+	status := post(t, "Bearer secret", mined)
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.Equal(t, http.StatusUnauthorized, post(t, "", mined))
+	assert.Equal(t, http.StatusUnauthorized, post(t, "Bearer wrong", mined))
+	assert.Equal(t, http.StatusBadRequest, post(t, "Bearer secret", `not json`))
+	assert.Equal(t, http.StatusBadRequest, post(t, "Bearer secret", `{"txStatus":"MINED"}`))
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, &Callback{
+		TxID:        callbackTxID,
+		TxStatus:    StatusMined,
+		BlockHash:   callbackBlock,
+		BlockHeight: 800000,
+		MerklePath:  "fe",
+	}, received[0])
+}
+
+func TestParseCallback(t *testing.T) {
+	t.Parallel()
+
+	request := func(method, auth, body string) *http.Request {
+		req := httptest.NewRequest(method, "/callback", strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		return req
+	}
+
+	t.Run("valid callback converts to a status", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"txid":"` + callbackTxID + `","txStatus":"SEEN_ON_NETWORK","timestamp":"2024-03-26T16:02:29.655390092Z","extraInfo":"x"}`
+		cb, err := ParseCallback(request(http.MethodPost, "Bearer secret", body), "secret")
+		require.NoError(t, err)
+		assert.Equal(t, &TransactionStatus{
+			TxID:      callbackTxID,
+			TxStatus:  StatusSeenOnNetwork,
+			Timestamp: "2024-03-26T16:02:29.655390092Z",
+			ExtraInfo: "x",
+		}, cb.Status())
+	})
+
+	t.Run("no token accepts any request", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseCallback(request(http.MethodPost, "", `{"txid":"`+callbackTxID+`","txStatus":"STORED"}`), "")
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects requests that are not callbacks", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseCallback(request(http.MethodGet, "Bearer secret", ""), "secret")
+		require.ErrorIs(t, err, ErrCallbackMethod)
+		_, err = ParseCallback(request(http.MethodPost, "Bearer other", "{}"), "secret")
+		require.ErrorIs(t, err, ErrCallbackUnauthorized)
+	})
+
+	for body, msg := range map[string]string{
+		`{"txid":`:                                           "invalid callback: unexpected EOF",
+		`{"txStatus":"STORED"}`:                              "missing txid",
+		`{"txid":"abc","txStatus":"STORED"}`:                 `txid "abc" is not 64 hex characters`,
+		`{"txid":"` + callbackTxID + `"}`:                    "missing txStatus",
+		`{"txid":"` + callbackTxID + `","txStatus":"MINED"}`: "MINED callback without blockHash",
+		`{"txid":"` + callbackTxID + `","txStatus":"MINED","blockHash":"00"}`:                                            `blockHash "00" is not 64 hex characters`,
+		`{"txid":"` + callbackTxID + `","txStatus":"STORED","blockHeight":-1}`:                                           "negative blockHeight -1",
+		`{"txid":"` + callbackTxID + `","txStatus":"STORED","timestamp":"yesterday"}`:                                    `timestamp "yesterday" is not RFC 3339`,
+		`{"txid":"` + callbackTxID + `","txStatus":"STORED","extraInfo":"` + strings.Repeat("x", maxCallbackSize) + `"}`: "request body too large",
+	} {
+		_, err := ParseCallback(request(http.MethodPost, "", body), "")
+		require.ErrorContains(t, err, msg, body[:min(len(body), 80)])
+	}
+}
@@ -0,0 +1,27 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrz1836/go-template/internal/arc"
+)
+
+// ARC broadcasts transactions through an ARC endpoint.
+type ARC struct {
+	Client *arc.ARCClient
+}
+
+// NewARC creates an ARC broadcaster for the endpoint.
+func NewARC(url, apiKey string) *ARC {
+	return &ARC{Client: arc.NewARCClient(url, apiKey)}
+}
+
+// Broadcast submits a raw, EF, or BEEF transaction to ARC.
+func (a *ARC) Broadcast(_ context.Context, rawTx string) (*BroadcastResult, error) {
+	resp, err := a.Client.BroadcastTransaction(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting via ARC: %w", err)
+	}
+	return &BroadcastResult{TxID: resp.TxID, Status: resp.TxStatus, Info: resp.ExtraInfo}, nil
+}
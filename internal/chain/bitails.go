@@ -0,0 +1,152 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Public Bitails API endpoints
+const (
+	BitailsMainnetURL = "https://api.bitails.io"
+	BitailsTestnetURL = "https://test-api.bitails.io"
+)
+
+// bitailsUnspentLimit is the most unspent outputs requested per address.
+const bitailsUnspentLimit = 10000
+
+// Bitails reads chain data from and broadcasts through the Bitails API.
+type Bitails struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// bitailsUnspent is the response from the address unspent endpoint.
+type bitailsUnspent struct {
+	Unspent []struct {
+		TxID        string `json:"txid"`
+		Vout        uint32 `json:"vout"`
+		Satoshis    uint64 `json:"satoshis"`
+		BlockHeight int64  `json:"blockheight"`
+	} `json:"unspent"`
+}
+
+// bitailsBroadcast is the response from the broadcast endpoint.
+type bitailsBroadcast struct {
+	TxID  string `json:"txid"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewBitails creates a Bitails provider. An empty baseURL selects the public
+// API for the network.
+func NewBitails(baseURL, apiKey string, testnet bool) *Bitails {
+	if baseURL == "" {
+		baseURL = BitailsMainnetURL
+		if testnet {
+			baseURL = BitailsTestnetURL
+		}
+	}
+	return &Bitails{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// UTXOs fetches the unspent outputs of an address.
+func (b *Bitails) UTXOs(ctx context.Context, address string) ([]*UTXO, error) {
+	body, err := b.do(ctx, http.MethodGet, fmt.Sprintf("/address/%s/unspent?limit=%d", address, bitailsUnspentLimit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching UTXOs: %w", err)
+	}
+
+	var response bitailsUnspent
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse UTXOs: %w", err)
+	}
+
+	utxos := make([]*UTXO, 0, len(response.Unspent))
+	for _, u := range response.Unspent {
+		utxos = append(utxos, &UTXO{TxHash: u.TxID, TxPos: u.Vout, Value: u.Satoshis, Height: max(u.BlockHeight, 0)})
+	}
+	return utxos, nil
+}
+
+// RawTx fetches the raw transaction hex for txid.
+func (b *Bitails) RawTx(ctx context.Context, txid string) (string, error) {
+	body, err := b.do(ctx, http.MethodGet, "/download/tx/"+txid+"/hex", nil)
+	if err != nil {
+		return "", fmt.Errorf("fetching transaction %s: %w", txid, err)
+	}
+	raw := strings.Trim(strings.TrimSpace(string(body)), "\"")
+	if raw == "" {
+		return "", fmt.Errorf("transaction %s not found", txid)
+	}
+	return raw, nil
+}
+
+// Broadcast submits a raw transaction through Bitails.
+func (b *Bitails) Broadcast(ctx context.Context, rawTx string) (*BroadcastResult, error) {
+	payload, err := json.Marshal(map[string]string{"raw": rawTx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	body, err := b.do(ctx, http.MethodPost, "/tx/broadcast", payload)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting via Bitails: %w", err)
+	}
+
+	var response bitailsBroadcast
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse broadcast response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("broadcasting via Bitails: %s (code %d)", response.Error.Message, response.Error.Code)
+	}
+	return &BroadcastResult{TxID: response.TxID, Status: StatusAccepted}, nil
+}
+
+// do sends a request to the Bitails API and returns the response body.
+func (b *Bitails) do(ctx context.Context, method, path string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.apiKey != "" {
+		req.Header.Set("apikey", b.apiKey)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("Bitails API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,70 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitails(t *testing.T) {
+	t.Parallel()
+
+	var broadcast map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("apikey"))
+		switch r.URL.Path {
+		case "/address/1addr/unspent":
+			assert.Equal(t, "10000", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{"unspent":[{"txid":"aa","vout":1,"satoshis":1000,"blockheight":800000},{"txid":"bb","vout":0,"satoshis":500,"blockheight":-1}]}`))
+		case "/download/tx/" + genesisTxID + "/hex":
+			_, _ = w.Write([]byte(genesisCoinbase))
+		case "/tx/broadcast":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&broadcast))
+			if broadcast["raw"] == "bad" {
+				_, _ = w.Write([]byte(`{"error":{"code":16,"message":"mandatory-script-verify-flag-failed"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"txid":"` + genesisTxID + `"}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	b := NewBitails(srv.URL+"/", "secret", false)
+
+	utxos, err := b.UTXOs(ctx, "1addr")
+	require.NoError(t, err)
+	assert.Equal(t, []*UTXO{
+		{TxHash: "aa", TxPos: 1, Value: 1000, Height: 800000},
+		{TxHash: "bb", TxPos: 0, Value: 500, Height: 0},
+	}, utxos)
+
+	raw, err := b.RawTx(ctx, genesisTxID)
+	require.NoError(t, err)
+	assert.Equal(t, genesisCoinbase, raw)
+
+	_, err = b.RawTx(ctx, genesisHash)
+	require.ErrorContains(t, err, "Bitails API error (status 404)")
+
+	res, err := b.Broadcast(ctx, genesisCoinbase)
+	require.NoError(t, err)
+	assert.Equal(t, genesisCoinbase, broadcast["raw"])
+	assert.Equal(t, &BroadcastResult{TxID: genesisTxID, Status: StatusAccepted}, res)
+
+	_, err = b.Broadcast(ctx, "bad")
+	require.ErrorContains(t, err, "mandatory-script-verify-flag-failed (code 16)")
+}
+
+func TestNewBitailsDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, BitailsMainnetURL, NewBitails("", "", false).baseURL)
+	assert.Equal(t, BitailsTestnetURL, NewBitails("", "", true).baseURL)
+}
@@ -0,0 +1,89 @@
+// Package chain defines the chain data providers BSV CLI tools read from and
+// broadcast through, so commands can switch backends via config.yaml.
+//
+// The package supports:
+//   - UTXOProvider, TxFetcher, HeaderSource, and Broadcaster interfaces
+//   - WhatsOnChain, Bitails, and SV Node (JSON-RPC) data providers
+//   - GorillaPool as a UTXO source, with transactions from WhatsOnChain
+//   - ARC, mAPI, WhatsOnChain, Bitails, and SV Node broadcasters
+//   - Building a Provider from the providers section of config.yaml
+//   - Overriding the configured data provider, as from a command-line flag
+//   - Defaults (WhatsOnChain data, ARC broadcasting) when no config exists
+//   - An in-memory Mock for testing commands offline
+package chain
+
+import (
+	"context"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+)
+
+// Provider names accepted in the providers section of config.yaml
+const (
+	NameWOC         = "whatsonchain"
+	NameARC         = "arc"
+	NameMAPI        = "mapi"
+	NameBitails     = "bitails"
+	NameGorillaPool = "gorillapool"
+	NameNode        = "node"
+)
+
+// StatusAccepted is reported by broadcasters that only say whether a
+// transaction was accepted, without ARC's lifecycle statuses.
+const StatusAccepted = "ACCEPTED"
+
+// UTXO represents an unspent transaction output.
+type UTXO struct {
+	TxHash string `json:"tx_hash"`          // Transaction ID containing this output
+	TxPos  uint32 `json:"tx_pos"`           // Output index (vout) within the transaction
+	Value  uint64 `json:"value"`            // Value in satoshis
+	Height int64  `json:"height,omitempty"` // Block height (0 = unconfirmed)
+}
+
+// BroadcastResult is a broadcaster's response to a submitted transaction.
+type BroadcastResult struct {
+	TxID   string `json:"txid"`
+	Status string `json:"status"`
+	Info   string `json:"info,omitempty"`
+}
+
+// UTXOProvider lists the unspent outputs of addresses.
+type UTXOProvider interface {
+	// UTXOs returns the spendable outputs of address. Outputs already spent
+	// in the mempool are left out; an address without UTXOs yields none.
+	UTXOs(ctx context.Context, address string) ([]*UTXO, error)
+}
+
+// TxFetcher retrieves transactions by txid.
+type TxFetcher interface {
+	// RawTx returns the raw transaction hex for txid.
+	RawTx(ctx context.Context, txid string) (string, error)
+}
+
+// HeaderSource retrieves best-chain block headers.
+type HeaderSource interface {
+	// HeaderByHeight returns the best-chain header at height.
+	HeaderByHeight(ctx context.Context, height uint32) (*block.Header, error)
+
+	// HeaderByHash returns the header of the block with hash.
+	HeaderByHash(ctx context.Context, hash string) (*block.Header, error)
+
+	// TipHeight returns the height of the best-chain tip.
+	TipHeight(ctx context.Context) (uint32, error)
+}
+
+// Broadcaster submits transactions to the network.
+type Broadcaster interface {
+	// Broadcast submits a raw (or, where supported, EF or BEEF) transaction.
+	Broadcast(ctx context.Context, rawTx string) (*BroadcastResult, error)
+}
+
+// Provider bundles the backends a command uses for each capability.
+type Provider struct {
+	UTXOProvider
+	TxFetcher
+	HeaderSource
+	Broadcaster
+
+	WOC *WOC // WhatsOnChain, which backs the defaults, whatever is selected
+}
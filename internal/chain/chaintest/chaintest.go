@@ -0,0 +1,29 @@
+// Package chaintest provides deterministic fixtures shared by the tests of
+// commands that build and fund transactions.
+package chaintest
+
+import (
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+)
+
+// sourceKey is private key 1, whose compressed mainnet address is
+// 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH.
+const sourceKey = "0000000000000000000000000000000000000000000000000000000000000001"
+
+// Source returns a deterministic funding key and its mainnet address.
+func Source(t testing.TB) (*ec.PrivateKey, *script.Address) {
+	t.Helper()
+
+	key, err := ec.PrivateKeyFromHex(sourceKey)
+	if err != nil {
+		t.Fatalf("chaintest: parsing source key: %v", err)
+	}
+	addr, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	if err != nil {
+		t.Fatalf("chaintest: deriving source address: %v", err)
+	}
+	return key, addr
+}
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Public GorillaPool 1Sat API endpoints
+const (
+	GorillaPoolMainnetURL = "https://ordinals.gorillapool.io/api"
+	GorillaPoolTestnetURL = "https://testnet.ordinals.gorillapool.io/api"
+)
+
+// gorillaPoolPageSize is the most unspent outputs requested per page.
+const gorillaPoolPageSize = 1000
+
+// GorillaPool lists UTXOs through GorillaPool's 1Sat indexer API.
+type GorillaPool struct {
+	baseURL string
+	client  *http.Client
+}
+
+// gorillaPoolTxo is an output from the address unspent endpoint.
+type gorillaPoolTxo struct {
+	TxID     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+	Satoshis uint64 `json:"satoshis"`
+	Height   int64  `json:"height"`
+	Origin   *struct {
+		Outpoint string `json:"outpoint"`
+	} `json:"origin"`
+}
+
+// NewGorillaPool creates a GorillaPool provider. An empty baseURL selects the
+// public API for the network.
+func NewGorillaPool(baseURL string, testnet bool) *GorillaPool {
+	if baseURL == "" {
+		baseURL = GorillaPoolMainnetURL
+		if testnet {
+			baseURL = GorillaPoolTestnetURL
+		}
+	}
+	return &GorillaPool{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// UTXOs fetches the unspent outputs of an address, a page at a time. Outputs
+// carrying a 1Sat Ordinals inscription are left out, so they are never spent
+// as plain satoshis.
+func (g *GorillaPool) UTXOs(ctx context.Context, address string) ([]*UTXO, error) {
+	var utxos []*UTXO
+	for offset := 0; ; offset += gorillaPoolPageSize {
+		path := fmt.Sprintf("/txos/address/%s/unspent?bsv20=false&limit=%d&offset=%d", address, gorillaPoolPageSize, offset)
+		body, err := g.get(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("fetching UTXOs: %w", err)
+		}
+
+		var page []gorillaPoolTxo
+		if err = json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse UTXOs: %w", err)
+		}
+		for _, u := range page {
+			if u.Origin != nil {
+				continue
+			}
+			utxos = append(utxos, &UTXO{TxHash: u.TxID, TxPos: u.Vout, Value: u.Satoshis, Height: max(u.Height, 0)})
+		}
+		if len(page) < gorillaPoolPageSize {
+			return utxos, nil
+		}
+	}
+}
+
+// get sends a GET request to the GorillaPool API and returns the response body.
+func (g *GorillaPool) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GorillaPool API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
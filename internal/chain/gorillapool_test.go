@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGorillaPool(t *testing.T) {
+	t.Parallel()
+
+	var offsets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/txos/address/1addr/unspent":
+			assert.Equal(t, "false", r.URL.Query().Get("bsv20"))
+			assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+			offset := r.URL.Query().Get("offset")
+			offsets = append(offsets, offset)
+			if offset == "0" {
+				// A full page, so the next is requested
+				page := make([]string, 0, gorillaPoolPageSize)
+				page = append(page,
+					`{"txid":"aa","vout":1,"satoshis":1000,"height":800000}`,
+					`{"txid":"bb","vout":0,"satoshis":1,"height":800001,"origin":{"outpoint":"bb_0"}}`)
+				for i := len(page); i < gorillaPoolPageSize; i++ {
+					page = append(page, fmt.Sprintf(`{"txid":"cc","vout":%d,"satoshis":10,"height":800002}`, i))
+				}
+				_, _ = w.Write([]byte("[" + strings.Join(page, ",") + "]"))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"txid":"dd","vout":2,"satoshis":500}]`))
+		case "/api/txos/address/1empty/unspent":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	g := NewGorillaPool(srv.URL+"/api/", false)
+
+	utxos, err := g.UTXOs(ctx, "1addr")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1000"}, offsets)
+	// The inscription is left out
+	require.Len(t, utxos, gorillaPoolPageSize)
+	assert.Equal(t, &UTXO{TxHash: "aa", TxPos: 1, Value: 1000, Height: 800000}, utxos[0])
+	assert.Equal(t, "cc", utxos[1].TxHash)
+	assert.Equal(t, &UTXO{TxHash: "dd", TxPos: 2, Value: 500}, utxos[len(utxos)-1])
+
+	utxos, err = g.UTXOs(ctx, "1empty")
+	require.NoError(t, err)
+	assert.Empty(t, utxos)
+
+	_, err = g.UTXOs(ctx, "1missing")
+	require.ErrorContains(t, err, "GorillaPool API error (status 404)")
+}
+
+func TestNewGorillaPoolDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, GorillaPoolMainnetURL, NewGorillaPool("", false).baseURL)
+	assert.Equal(t, GorillaPoolTestnetURL, NewGorillaPool("", true).baseURL)
+}
@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrz1836/go-template/internal/mapi"
+)
+
+// MAPI broadcasts transactions through a miner's legacy mAPI endpoint.
+type MAPI struct {
+	Client *mapi.Client
+}
+
+// NewMAPI creates an mAPI broadcaster for the endpoint. When minerKey is set,
+// responses must be signed by that identity key.
+func NewMAPI(url, apiKey, minerKey string) (*MAPI, error) {
+	client := mapi.NewClient(url, apiKey)
+	if minerKey != "" {
+		if err := client.SetMinerKey(minerKey); err != nil {
+			return nil, err
+		}
+	}
+	return &MAPI{Client: client}, nil
+}
+
+// Broadcast submits a raw transaction to the miner. mAPI does not accept EF or BEEF.
+func (m *MAPI) Broadcast(_ context.Context, rawTx string) (*BroadcastResult, error) {
+	resp, err := m.Client.BroadcastTransaction(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting via mAPI: %w", err)
+	}
+	if resp.ReturnResult != mapi.ResultSuccess {
+		return nil, fmt.Errorf("broadcasting via mAPI: %s", resp.ResultDescription)
+	}
+	return &BroadcastResult{TxID: resp.TxID, Status: StatusAccepted, Info: resp.ResultDescription}, nil
+}
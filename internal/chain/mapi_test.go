@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMAPI(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/mapi/tx", r.URL.Path)
+		var req map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		payload := `{"txid":"` + genesisTxID + `","returnResult":"success","resultDescription":""}`
+		if req["rawtx"] == "bad" {
+			payload = `{"txid":"","returnResult":"failure","resultDescription":"Missing inputs"}`
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"payload": payload, "signature": nil, "publicKey": nil}))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	m, err := NewMAPI(srv.URL, "", "")
+	require.NoError(t, err)
+
+	res, err := m.Broadcast(ctx, genesisCoinbase)
+	require.NoError(t, err)
+	assert.Equal(t, &BroadcastResult{TxID: genesisTxID, Status: StatusAccepted}, res)
+
+	_, err = m.Broadcast(ctx, "bad")
+	require.ErrorContains(t, err, "broadcasting via mAPI: Missing inputs")
+}
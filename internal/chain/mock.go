@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Mock is an in-memory provider implementing every interface, for testing
+// commands without network access. Broadcast transactions become fetchable,
+// so a test can build, broadcast, and look up a chain of transactions.
+type Mock struct {
+	Unspent      map[string][]*UTXO       // UTXOs by address
+	Raw          map[string]string        // Raw transaction hex by txid
+	Headers      map[uint32]*block.Header // Best-chain headers by height
+	BroadcastErr error                    // Error returned by Broadcast, if set
+	Broadcasts   []string                 // Transactions passed to Broadcast, in order
+	Fetched      []string                 // Txids passed to RawTx, in order
+
+	mu sync.Mutex
+}
+
+// NewMock creates an empty Mock.
+func NewMock() *Mock {
+	return &Mock{
+		Unspent: make(map[string][]*UTXO),
+		Raw:     make(map[string]string),
+		Headers: make(map[uint32]*block.Header),
+	}
+}
+
+// AddTx makes a transaction fetchable by its txid.
+func (m *Mock) AddTx(tx *transaction.Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Raw[tx.TxID().String()] = tx.Hex()
+}
+
+// UTXOs returns the UTXOs registered for address.
+func (m *Mock) UTXOs(_ context.Context, address string) ([]*UTXO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*UTXO(nil), m.Unspent[address]...), nil
+}
+
+// RawTx returns a registered or broadcast transaction.
+func (m *Mock) RawTx(_ context.Context, txid string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Fetched = append(m.Fetched, txid)
+	raw, ok := m.Raw[txid]
+	if !ok {
+		return "", fmt.Errorf("transaction %s not found", txid)
+	}
+	return raw, nil
+}
+
+// HeaderByHeight returns the registered header at height.
+func (m *Mock) HeaderByHeight(_ context.Context, height uint32) (*block.Header, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	header, ok := m.Headers[height]
+	if !ok {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	return header, nil
+}
+
+// HeaderByHash returns the registered header with hash.
+func (m *Mock) HeaderByHash(_ context.Context, hash string) (*block.Header, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, header := range m.Headers {
+		if h := header.Hash(); h.String() == hash {
+			return header, nil
+		}
+	}
+	return nil, fmt.Errorf("header %s not found", hash)
+}
+
+// TipHeight returns the highest registered header height.
+func (m *Mock) TipHeight(_ context.Context) (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Headers) == 0 {
+		return 0, fmt.Errorf("no headers")
+	}
+	var tip uint32
+	for height := range m.Headers {
+		tip = max(tip, height)
+	}
+	return tip, nil
+}
+
+// Broadcast records a raw or EF transaction and makes it fetchable.
+func (m *Mock) Broadcast(_ context.Context, rawTx string) (*BroadcastResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.BroadcastErr != nil {
+		return nil, m.BroadcastErr
+	}
+
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("parsing transaction: %w", err)
+	}
+	m.Broadcasts = append(m.Broadcasts, rawTx)
+	txid := tx.TxID().String()
+	m.Raw[txid] = tx.Hex()
+	return &BroadcastResult{TxID: txid, Status: StatusAccepted}, nil
+}
@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewMock()
+
+	// Broadcast transactions become fetchable
+	res, err := m.Broadcast(ctx, genesisCoinbase)
+	require.NoError(t, err)
+	assert.Equal(t, genesisTxID, res.TxID)
+	assert.Equal(t, []string{genesisCoinbase}, m.Broadcasts)
+	raw, err := m.RawTx(ctx, genesisTxID)
+	require.NoError(t, err)
+	assert.Equal(t, genesisCoinbase, raw)
+
+	_, err = m.RawTx(ctx, genesisHash)
+	require.ErrorContains(t, err, "not found")
+	assert.Equal(t, []string{genesisTxID, genesisHash}, m.Fetched)
+
+	tx, err := transaction.NewTransactionFromHex(genesisCoinbase)
+	require.NoError(t, err)
+	other := NewMock()
+	other.AddTx(tx)
+	_, err = other.RawTx(ctx, genesisTxID)
+	require.NoError(t, err)
+
+	m.Unspent["addr"] = []*UTXO{{TxHash: genesisTxID, Value: 5000000000}}
+	utxos, err := m.UTXOs(ctx, "addr")
+	require.NoError(t, err)
+	assert.Len(t, utxos, 1)
+	utxos, err = m.UTXOs(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, utxos)
+
+	_, err = m.TipHeight(ctx)
+	require.Error(t, err)
+	header, err := block.NewHeaderFromHex(genesisHeader)
+	require.NoError(t, err)
+	m.Headers[0] = header
+	m.Headers[5] = header
+	tip, err := m.TipHeight(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), tip)
+	got, err := m.HeaderByHash(ctx, genesisHash)
+	require.NoError(t, err)
+	assert.Equal(t, header, got)
+	_, err = m.HeaderByHeight(ctx, 1)
+	require.ErrorContains(t, err, "no header at height 1")
+
+	m.BroadcastErr = errors.New("rejected")
+	_, err = m.Broadcast(ctx, genesisCoinbase)
+	require.EqualError(t, err, "rejected")
+}
@@ -0,0 +1,193 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/mrz1836/go-template/internal/config"
+)
+
+// unavailable stands in for a backend that is selected but not configured,
+// so commands that never use it still run.
+type unavailable struct {
+	err error
+}
+
+func (u unavailable) Broadcast(context.Context, string) (*BroadcastResult, error) {
+	return nil, u.err
+}
+
+// LoadOptions overrides config.yaml for one run. Empty fields keep its settings.
+type LoadOptions struct {
+	Data    string            // Data provider, in place of providers.data
+	Headers string            // Headers provider, in place of providers.headers
+	WOC     WOCOptions        // WhatsOnChain settings; an empty APIKey keeps whatsonchain-<network>'s
+	Node    config.NodeConfig // SV Node settings; each field set replaces node-<network>'s
+}
+
+// New builds the providers selected in cfg for the network. A nil cfg
+// selects the defaults: WhatsOnChain for data and headers, ARC for broadcasting.
+func New(ctx context.Context, cfg *config.Config, testnet bool) (*Provider, error) {
+	return newProvider(ctx, cfg, testnet, WOCOptions{})
+}
+
+// newProvider is New with wocOpts applied to WhatsOnChain.
+func newProvider(ctx context.Context, cfg *config.Config, testnet bool, wocOpts WOCOptions) (*Provider, error) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	network := "mainnet"
+	if testnet {
+		network = "testnet"
+	}
+
+	// WhatsOnChain backs every default, so it is always available
+	if wocOpts.APIKey == "" {
+		wocOpts.APIKey = cfg.GetWhatsOnChainConfig(testnet).APIKey
+	}
+	woc, err := NewWOCWith(ctx, testnet, wocOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var node *Node
+	nodeFor := func(role string) (*Node, error) {
+		if node == nil {
+			nodeConfig := cfg.GetNodeConfig(testnet)
+			if nodeConfig.URL == "" {
+				return nil, fmt.Errorf("node-%s url is required in config.yaml for %s", network, role)
+			}
+			var err error
+			if node, err = NewNodeFromConfig(nodeConfig); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	}
+	bitailsConfig := cfg.GetBitailsConfig(testnet)
+	bitails := NewBitails(bitailsConfig.URL, bitailsConfig.APIKey, testnet)
+
+	p := &Provider{WOC: woc}
+	switch name := orDefault(cfg.Providers.Data, NameWOC); name {
+	case NameWOC:
+		p.UTXOProvider, p.TxFetcher = woc, woc
+	case NameBitails:
+		p.UTXOProvider, p.TxFetcher = bitails, bitails
+	case NameGorillaPool:
+		// GorillaPool's API lists UTXOs; transactions still come from WhatsOnChain
+		gorillaConfig := cfg.GetGorillaPoolConfig(testnet)
+		p.UTXOProvider, p.TxFetcher = NewGorillaPool(gorillaConfig.URL, testnet), woc
+	case NameNode:
+		n, err := nodeFor("data")
+		if err != nil {
+			return nil, err
+		}
+		p.UTXOProvider, p.TxFetcher = n, n
+	default:
+		return nil, fmt.Errorf("unknown data provider %q: must be whatsonchain, bitails, gorillapool, or node", name)
+	}
+
+	switch name := orDefault(cfg.Providers.Headers, NameWOC); name {
+	case NameWOC:
+		p.HeaderSource = woc
+	case NameNode:
+		n, err := nodeFor("headers")
+		if err != nil {
+			return nil, err
+		}
+		p.HeaderSource = n
+	default:
+		return nil, fmt.Errorf("unknown headers provider %q: must be whatsonchain or node", name)
+	}
+
+	switch name := orDefault(cfg.Providers.Broadcast, NameARC); name {
+	case NameARC:
+		arcConfig := cfg.GetARCConfig(testnet)
+		if arcConfig.URL == "" {
+			p.Broadcaster = unavailable{fmt.Errorf("ARC URL is required for %s in config.yaml", network)}
+		} else {
+			p.Broadcaster = NewARC(arcConfig.URL, arcConfig.APIKey)
+		}
+	case NameMAPI:
+		mapiConfig := cfg.GetMAPIConfig(testnet)
+		if mapiConfig.URL == "" {
+			return nil, fmt.Errorf("mapi-%s url is required in config.yaml for broadcast", network)
+		}
+		if p.Broadcaster, err = NewMAPI(mapiConfig.URL, mapiConfig.APIKey, mapiConfig.MinerKey); err != nil {
+			return nil, err
+		}
+	case NameWOC:
+		p.Broadcaster = woc
+	case NameBitails:
+		p.Broadcaster = bitails
+	case NameNode:
+		n, err := nodeFor("broadcast")
+		if err != nil {
+			return nil, err
+		}
+		p.Broadcaster = n
+	default:
+		return nil, fmt.Errorf("unknown broadcast provider %q: must be arc, mapi, whatsonchain, bitails, or node", name)
+	}
+
+	return p, nil
+}
+
+// Load builds the providers selected in config.yaml, or the defaults when
+// there is no config file.
+func Load(ctx context.Context, testnet bool) (*Provider, error) {
+	return LoadWith(ctx, testnet, LoadOptions{})
+}
+
+// LoadWith is Load with opts in place of the matching settings in
+// config.yaml. The rest of the file, such as the chosen data provider's URL
+// and API key, still applies.
+func LoadWith(ctx context.Context, testnet bool, opts LoadOptions) (*Provider, error) {
+	cfg, err := config.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg = &config.Config{}
+	} else if err != nil {
+		return nil, fmt.Errorf("loading configuration: %w", err)
+	}
+	if opts.Data != "" {
+		cfg.Providers.Data = opts.Data
+	}
+	if opts.Headers != "" {
+		cfg.Providers.Headers = opts.Headers
+	}
+	overrideNode(cfg, testnet, opts.Node)
+	return newProvider(ctx, cfg, testnet, opts.WOC)
+}
+
+// overrideNode replaces the network's node settings in cfg with each field
+// set in node.
+func overrideNode(cfg *config.Config, testnet bool, node config.NodeConfig) {
+	merged := cfg.GetNodeConfig(testnet)
+	if node.URL != "" {
+		merged.URL = node.URL
+	}
+	if node.User != "" || node.Password != "" {
+		merged.User, merged.Password = node.User, node.Password
+	}
+	if node.Cookie != "" {
+		merged.Cookie = node.Cookie
+	}
+	merged.Scan = merged.Scan || node.Scan
+	if testnet {
+		cfg.NodeTestnet = merged
+	} else {
+		cfg.NodeMainnet = merged
+	}
+}
+
+// orDefault returns name in lower case, or def when name is empty.
+func orDefault(name, def string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return def
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/config"
+)
+
+const (
+	// genesisCoinbase is the raw coinbase transaction of the genesis block.
+	genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	genesisTxID     = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	genesisHash     = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	genesisHeader   = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		p, err := New(ctx, nil, false)
+		require.NoError(t, err)
+		assert.IsType(t, &WOC{}, p.UTXOProvider)
+		assert.IsType(t, &WOC{}, p.TxFetcher)
+		assert.IsType(t, &WOC{}, p.HeaderSource)
+
+		// ARC is the default broadcaster but needs a URL
+		_, err = p.Broadcast(ctx, genesisCoinbase)
+		require.ErrorContains(t, err, "ARC URL is required for mainnet")
+	})
+
+	t.Run("configured ARC", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{ARCTestnet: config.ARCConfig{URL: "https://arc.example.com"}}
+		p, err := New(ctx, cfg, true)
+		require.NoError(t, err)
+		assert.IsType(t, &ARC{}, p.Broadcaster)
+	})
+
+	t.Run("bitails and node", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Providers:   config.ProvidersConfig{Data: "Bitails", Headers: "node", Broadcast: "node"},
+			NodeTestnet: config.NodeConfig{URL: "http://127.0.0.1:18332"},
+		}
+		p, err := New(ctx, cfg, true)
+		require.NoError(t, err)
+		require.IsType(t, &Bitails{}, p.UTXOProvider)
+		assert.Equal(t, BitailsTestnetURL, p.UTXOProvider.(*Bitails).baseURL)
+		assert.IsType(t, &Node{}, p.HeaderSource)
+		assert.Same(t, p.HeaderSource, p.Broadcaster)
+	})
+
+	t.Run("gorillapool", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Providers:          config.ProvidersConfig{Data: NameGorillaPool},
+			GorillaPoolMainnet: config.GorillaPoolConfig{URL: "https://gorillapool.example.com/api"},
+		}
+		p, err := New(ctx, cfg, false)
+		require.NoError(t, err)
+		require.IsType(t, &GorillaPool{}, p.UTXOProvider)
+		assert.Equal(t, "https://gorillapool.example.com/api", p.UTXOProvider.(*GorillaPool).baseURL)
+		assert.IsType(t, &WOC{}, p.TxFetcher)
+	})
+
+	t.Run("mapi", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Providers:   config.ProvidersConfig{Broadcast: NameMAPI},
+			MAPIMainnet: config.MAPIConfig{URL: "https://mapi.example.com"},
+		}
+		p, err := New(ctx, cfg, false)
+		require.NoError(t, err)
+		assert.IsType(t, &MAPI{}, p.Broadcaster)
+
+		_, err = New(ctx, cfg, true)
+		require.ErrorContains(t, err, "mapi-testnet url is required")
+
+		cfg.MAPIMainnet.MinerKey = "not-a-key"
+		_, err = New(ctx, cfg, false)
+		require.ErrorContains(t, err, "invalid miner key")
+	})
+
+	t.Run("node overridden for one run", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Providers:   config.ProvidersConfig{Data: NameNode},
+			NodeTestnet: config.NodeConfig{URL: "http://127.0.0.1:18332", User: "rpc", Password: "pass"},
+		}
+		overrideNode(cfg, true, config.NodeConfig{URL: "http://127.0.0.1:18443", Scan: true})
+		assert.Equal(t, config.NodeConfig{URL: "http://127.0.0.1:18443", User: "rpc", Password: "pass", Scan: true}, cfg.NodeTestnet)
+		assert.Equal(t, config.NodeConfig{}, cfg.NodeMainnet)
+
+		p, err := New(ctx, cfg, true)
+		require.NoError(t, err)
+		require.IsType(t, &Node{}, p.UTXOProvider)
+		assert.True(t, p.UTXOProvider.(*Node).scan)
+	})
+
+	t.Run("node without a URL", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{Providers: config.ProvidersConfig{Data: NameNode}}
+		_, err := New(ctx, cfg, false)
+		require.ErrorContains(t, err, "node-mainnet url is required")
+	})
+
+	t.Run("unknown providers", func(t *testing.T) {
+		t.Parallel()
+		for _, providers := range []config.ProvidersConfig{{Data: "arc"}, {Headers: "bitails"}, {Broadcast: "carrier-pigeon"}} {
+			_, err := New(ctx, &config.Config{Providers: providers}, false)
+			require.ErrorContains(t, err, "unknown")
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/mrz1836/go-whatsonchain"
+
+	"github.com/mrz1836/go-template/internal/headers"
+)
+
+// wocBaseURL is the WhatsOnChain REST API root; the network name is appended.
+const wocBaseURL = "https://api.whatsonchain.com/v1/bsv/"
+
+// WhatsOnChain client settings
+const (
+	wocTimeout        = 30 * time.Second
+	wocAPIKeyHeader   = "woc-api-key"
+	DefaultWOCRetries = 3 // Retries of a request failing with 429 or 5xx
+)
+
+// wocBackoff spaces out retries: 500ms, 1s, 2s and so on up to 8s, each plus
+// up to 250ms of jitter.
+var wocBackoff = whatsonchain.NewExponentialBackoff(500*time.Millisecond, 8*time.Second, 2, 250*time.Millisecond)
+
+// WOCOptions adjusts how WhatsOnChain is called.
+type WOCOptions struct {
+	APIKey  string // Sent with every request when set
+	Retries int    // Retries of a 429 or 5xx response; 0 selects DefaultWOCRetries, negative none
+
+	// Progress, when set, is called with the number of UTXOs on each page
+	// of an address's unspent outputs as it arrives, one call at a time.
+	Progress func(address string, n int)
+}
+
+// WOC reads chain data from and broadcasts through WhatsOnChain.
+type WOC struct {
+	Client whatsonchain.ClientInterface
+
+	baseURL  string        // REST root for endpoints the client does not cover
+	apiKey   string        // Sent with those requests too
+	http     wocHTTPClient // Sends them, retrying; http.DefaultClient when nil
+	progress func(address string, n int)
+}
+
+// wocHTTPClient sends a request, as *http.Client and the library's retrying
+// client both do.
+type wocHTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// wocUnspent represents a single UTXO from the WhatsOnChain API.
+type wocUnspent struct {
+	Height             int64  `json:"height"`
+	TxPos              int    `json:"tx_pos"`
+	TxHash             string `json:"tx_hash"`
+	Value              uint64 `json:"value"`
+	IsSpentInMempoolTx bool   `json:"isSpentInMempoolTx"`
+	Status             string `json:"status"`
+}
+
+// wocUnspentResponse is a page from the confirmed or unconfirmed unspent endpoints.
+type wocUnspentResponse struct {
+	Address       string       `json:"address"`
+	Script        string       `json:"script"`
+	Result        []wocUnspent `json:"result"`
+	Error         string       `json:"error"`
+	NextPageToken string       `json:"nextPageToken"`
+}
+
+// wocHistoryResponse is a page from the confirmed or unconfirmed history endpoints.
+type wocHistoryResponse struct {
+	Result []struct {
+		TxHash string `json:"tx_hash"`
+		Height int64  `json:"height"`
+	} `json:"result"`
+	Error         string `json:"error"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// HistoryTx is a transaction in an address's history.
+type HistoryTx struct {
+	TxHash string `json:"tx_hash"`
+	Height int64  `json:"height"` // 0 while unconfirmed
+}
+
+// wocHistoryPageSize is the most history entries requested per page.
+const wocHistoryPageSize = 1000
+
+// wocUnspentPageSize is the most unspent outputs requested per page.
+const wocUnspentPageSize = 1000
+
+// NewWOC creates a WhatsOnChain provider for the network.
+func NewWOC(ctx context.Context, testnet bool) (*WOC, error) {
+	return NewWOCWith(ctx, testnet, WOCOptions{})
+}
+
+// NewWOCWith creates a WhatsOnChain provider for the network with opts.
+func NewWOCWith(ctx context.Context, testnet bool, opts WOCOptions) (*WOC, error) {
+	httpClient := newWOCHTTPClient(opts)
+	client, err := newWOCClient(ctx, testnet, opts, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &WOC{Client: client, baseURL: wocBaseURL + string(client.Network()), apiKey: opts.APIKey, http: httpClient, progress: opts.Progress}, nil
+}
+
+// newWOCClient creates a WhatsOnChain API client sending through httpClient.
+// Unlike the library's own transport, httpClient sends through
+// http.DefaultTransport like every other API client here, so tests can replay
+// recorded responses to it.
+func newWOCClient(ctx context.Context, testnet bool, opts WOCOptions, httpClient *whatsonchain.RetryableHTTPClient) (whatsonchain.ClientInterface, error) {
+	network := whatsonchain.NetworkMain
+	if testnet {
+		network = whatsonchain.NetworkTest
+	}
+	options := []whatsonchain.ClientOption{whatsonchain.WithNetwork(network), whatsonchain.WithHTTPClient(httpClient)}
+	if opts.APIKey != "" {
+		options = append(options, whatsonchain.WithAPIKey(opts.APIKey))
+	}
+	client, err := whatsonchain.NewClient(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating WhatsOnChain client: %w", err)
+	}
+	return client, nil
+}
+
+// newWOCHTTPClient returns an HTTP client that retries 429 and 5xx responses
+// and network errors opts.Retries times, backing off exponentially.
+func newWOCHTTPClient(opts WOCOptions) *whatsonchain.RetryableHTTPClient {
+	retries := opts.Retries
+	if retries == 0 {
+		retries = DefaultWOCRetries
+	}
+	return whatsonchain.NewRetryableHTTPClient(&http.Client{Timeout: wocTimeout}, max(retries, 0), wocBackoff)
+}
+
+// UTXOs fetches the unspent outputs of an address, including unconfirmed
+// ones. Both lists are paged, so an address with many thousands of outputs is
+// listed in full rather than truncated or timed out, and the unconfirmed list
+// is fetched while the confirmed pages are. Each page's token leads to the
+// next, so the pages of one list come one after another.
+func (w *WOC) UTXOs(ctx context.Context, address string) ([]*UTXO, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	report := func(n int) {
+		if w.progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.progress(address, n)
+	}
+
+	var unconfirmed []*UTXO
+	var unconfirmedErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unconfirmed, unconfirmedErr = w.unspentPages(ctx, "/address/"+address+"/unconfirmed/unspent", report)
+	}()
+	confirmed, err := w.unspentPages(ctx, "/address/"+address+"/confirmed/unspent", report)
+	if err != nil {
+		cancel()
+	}
+	<-done
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch confirmed UTXOs: %w", err)
+	}
+	if unconfirmedErr != nil {
+		return nil, fmt.Errorf("failed to fetch unconfirmed UTXOs: %w", unconfirmedErr)
+	}
+	return append(confirmed, unconfirmed...), nil
+}
+
+// unspentPages fetches every page of an unspent endpoint, calling report
+// with the number of outputs on each.
+func (w *WOC) unspentPages(ctx context.Context, path string, report func(int)) ([]*UTXO, error) {
+	var utxos []*UTXO
+	token := ""
+	for {
+		page := fmt.Sprintf("%s?limit=%d", path, wocUnspentPageSize)
+		if token != "" {
+			page += "&pageToken=" + url.QueryEscape(token)
+		}
+		body, err := w.get(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		found, next, err := parseWOCUnspent(body)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, found...)
+		report(len(found))
+		if next == "" || next == token {
+			return utxos, nil
+		}
+		token = next
+	}
+}
+
+// History lists every transaction involving an address, confirmed ones first
+// in the order WhatsOnChain pages them, then unconfirmed ones.
+func (w *WOC) History(ctx context.Context, address string) ([]*HistoryTx, error) {
+	var history []*HistoryTx
+	token := ""
+	for {
+		path := fmt.Sprintf("/address/%s/confirmed/history?limit=%d", address, wocHistoryPageSize)
+		if token != "" {
+			path += "&pageToken=" + url.QueryEscape(token)
+		}
+		page, err := w.historyPage(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("fetching confirmed history: %w", err)
+		}
+		for _, r := range page.Result {
+			history = append(history, &HistoryTx{TxHash: r.TxHash, Height: max(r.Height, 0)})
+		}
+		if page.NextPageToken == "" || page.NextPageToken == token {
+			break
+		}
+		token = page.NextPageToken
+	}
+
+	page, err := w.historyPage(ctx, "/address/"+address+"/unconfirmed/history")
+	if err != nil {
+		return nil, fmt.Errorf("fetching unconfirmed history: %w", err)
+	}
+	for _, r := range page.Result {
+		history = append(history, &HistoryTx{TxHash: r.TxHash})
+	}
+	return history, nil
+}
+
+// historyPage fetches and parses one page of address history.
+func (w *WOC) historyPage(ctx context.Context, path string) (*wocHistoryResponse, error) {
+	body, err := w.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	var page wocHistoryResponse
+	if err = json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	if page.Error != "" {
+		return nil, fmt.Errorf("API error: %s", page.Error)
+	}
+	return &page, nil
+}
+
+// get fetches a REST endpoint the client does not cover.
+func (w *WOC) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if w.apiKey != "" {
+		req.Header.Set(wocAPIKeyHeader, w.apiKey)
+	}
+
+	var client wocHTTPClient = http.DefaultClient
+	if w.http != nil {
+		client = w.http
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("WhatsOnChain API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// parseWOCUnspent parses a page of unspent outputs, skipping those already
+// spent in the mempool, and returns the token of the next page ("" = last).
+func parseWOCUnspent(body []byte) ([]*UTXO, string, error) {
+	var response wocUnspentResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to parse UTXOs: %w", err)
+	}
+
+	if response.Error != "" {
+		return nil, "", fmt.Errorf("API error: %s", response.Error)
+	}
+
+	utxos := make([]*UTXO, 0, len(response.Result))
+	for _, u := range response.Result {
+		if u.IsSpentInMempoolTx {
+			continue
+		}
+		utxos = append(utxos, &UTXO{
+			TxHash: u.TxHash,
+			TxPos:  uint32(u.TxPos), //nolint:gosec // output indexes are 32-bit
+			Value:  u.Value,
+			Height: max(u.Height, 0),
+		})
+	}
+	return utxos, response.NextPageToken, nil
+}
+
+// RawTx fetches the raw transaction hex for txid.
+func (w *WOC) RawTx(ctx context.Context, txid string) (string, error) {
+	raw, err := w.Client.GetRawTransactionData(ctx, txid)
+	if err != nil {
+		return "", fmt.Errorf("fetching transaction %s: %w", txid, err)
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("transaction %s not found", txid)
+	}
+	return raw, nil
+}
+
+// HeaderByHeight fetches the best-chain header at height.
+func (w *WOC) HeaderByHeight(ctx context.Context, height uint32) (*block.Header, error) {
+	info, err := w.Client.GetBlockByHeight(ctx, int64(height))
+	if err != nil {
+		return nil, fmt.Errorf("fetching header at height %d: %w", height, err)
+	}
+	return headers.HeaderFromBlockInfo(info)
+}
+
+// HeaderByHash fetches the header of the block with hash.
+func (w *WOC) HeaderByHash(ctx context.Context, hash string) (*block.Header, error) {
+	info, err := w.Client.GetHeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("fetching header %s: %w", hash, err)
+	}
+	return headers.HeaderFromBlockInfo(info)
+}
+
+// TipHeight fetches the height of the best-chain tip.
+func (w *WOC) TipHeight(ctx context.Context) (uint32, error) {
+	info, err := w.Client.GetChainInfo(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("fetching chain info: %w", err)
+	}
+	return uint32(info.Blocks), nil //nolint:gosec // block heights fit in uint32
+}
+
+// Broadcast submits a raw transaction through WhatsOnChain.
+func (w *WOC) Broadcast(ctx context.Context, rawTx string) (*BroadcastResult, error) {
+	txid, err := w.Client.BroadcastTx(ctx, rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasting via WhatsOnChain: %w", err)
+	}
+	return &BroadcastResult{TxID: strings.Trim(txid, "\" \n"), Status: StatusAccepted}, nil
+}
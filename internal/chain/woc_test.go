@@ -0,0 +1,348 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWOC serves the genesis block and coinbase.
+type fakeWOC struct {
+	whatsonchain.ClientInterface
+
+	broadcast string
+}
+
+func (f *fakeWOC) GetRawTransactionData(_ context.Context, txid string) (string, error) {
+	if txid == genesisTxID {
+		return genesisCoinbase + "\n", nil
+	}
+	return "", nil
+}
+
+func (f *fakeWOC) GetBlockByHeight(_ context.Context, height int64) (*whatsonchain.BlockInfo, error) {
+	if height != 0 {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	return genesisInfo(), nil
+}
+
+func (f *fakeWOC) GetHeaderByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	if hash != genesisHash {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return genesisInfo(), nil
+}
+
+func (f *fakeWOC) GetChainInfo(_ context.Context) (*whatsonchain.ChainInfo, error) {
+	return &whatsonchain.ChainInfo{Blocks: 900000}, nil
+}
+
+func (f *fakeWOC) BroadcastTx(_ context.Context, txHex string) (string, error) {
+	f.broadcast = txHex
+	return `"` + genesisTxID + `"`, nil
+}
+
+// genesisInfo returns the genesis block as WhatsOnChain reports it.
+func genesisInfo() *whatsonchain.BlockInfo {
+	return &whatsonchain.BlockInfo{
+		Hash:       genesisHash,
+		Version:    1,
+		MerkleRoot: genesisTxID,
+		Time:       1231006505,
+		Bits:       "1d00ffff",
+		Nonce:      2083236893,
+	}
+}
+
+func TestWOC(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeWOC{}
+	w := &WOC{Client: fake}
+	ctx := context.Background()
+
+	raw, err := w.RawTx(ctx, genesisTxID)
+	require.NoError(t, err)
+	assert.Equal(t, genesisCoinbase, raw)
+
+	_, err = w.RawTx(ctx, genesisHash)
+	require.ErrorContains(t, err, "not found")
+
+	header, err := w.HeaderByHeight(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, genesisHash, header.Hash().String())
+
+	header, err = w.HeaderByHash(ctx, genesisHash)
+	require.NoError(t, err)
+	assert.Equal(t, genesisTxID, header.MerkleRoot.String())
+
+	tip, err := w.TipHeight(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(900000), tip)
+
+	res, err := w.Broadcast(ctx, genesisCoinbase)
+	require.NoError(t, err)
+	assert.Equal(t, &BroadcastResult{TxID: genesisTxID, Status: StatusAccepted}, res)
+	assert.Equal(t, genesisCoinbase, fake.broadcast)
+}
+
+func TestWOCUTXOs(t *testing.T) {
+	t.Parallel()
+
+	const addr = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+		switch r.URL.Path {
+		case "/address/" + addr + "/confirmed/unspent":
+			if r.URL.Query().Get("pageToken") == "" {
+				_, _ = w.Write([]byte(`{"result": [
+					{"height": 850000, "tx_pos": 1, "tx_hash": "abc123", "value": 10000, "isSpentInMempoolTx": false},
+					{"height": 850001, "tx_pos": 0, "tx_hash": "spent", "value": 700, "isSpentInMempoolTx": true}
+				], "nextPageToken": "page 2"}`))
+				return
+			}
+			assert.Equal(t, "page 2", r.URL.Query().Get("pageToken"))
+			_, _ = w.Write([]byte(`{"result": [{"height": 850002, "tx_pos": 2, "tx_hash": "fed789", "value": 300}]}`))
+		case "/address/" + addr + "/unconfirmed/unspent":
+			_, _ = w.Write([]byte(`{"result": [{"height": -1, "tx_pos": 0, "tx_hash": "def456", "value": 500, "isSpentInMempoolTx": false}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var pages, listed int
+	w := &WOC{baseURL: server.URL, progress: func(address string, n int) {
+		assert.Equal(t, addr, address)
+		pages++
+		listed += n
+	}}
+	utxos, err := w.UTXOs(context.Background(), addr)
+	require.NoError(t, err)
+	assert.Equal(t, []*UTXO{
+		{TxHash: "abc123", TxPos: 1, Value: 10000, Height: 850000},
+		{TxHash: "fed789", TxPos: 2, Value: 300, Height: 850002},
+		{TxHash: "def456", TxPos: 0, Value: 500, Height: 0},
+	}, utxos)
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, 3, listed)
+
+	_, err = (&WOC{baseURL: server.URL}).UTXOs(context.Background(), "1unknown")
+	require.ErrorContains(t, err, "status 404")
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+
+	_, err = (&WOC{baseURL: failing.URL}).UTXOs(context.Background(), addr)
+	require.ErrorContains(t, err, "status 429")
+}
+
+func TestWOCRetries(t *testing.T) {
+	t.Parallel()
+
+	// Only the confirmed list is rate limited; its requests come one at a time
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get(wocAPIKeyHeader))
+		if strings.HasSuffix(r.URL.Path, "/unconfirmed/unspent") {
+			_, _ = w.Write([]byte(`{"result": []}`))
+			return
+		}
+		if calls++; calls < 3 {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result": [{"height": 850000, "tx_pos": 1, "tx_hash": "abc123", "value": 10000}]}`))
+	}))
+	defer server.Close()
+
+	backoff := whatsonchain.NewExponentialBackoff(time.Millisecond, 2*time.Millisecond, 2, 0)
+	retrying := func(retries int) *WOC {
+		return &WOC{baseURL: server.URL, apiKey: "secret", http: whatsonchain.NewRetryableHTTPClient(&http.Client{}, retries, backoff)}
+	}
+
+	utxos, err := retrying(2).UTXOs(context.Background(), "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	require.NoError(t, err)
+	assert.Len(t, utxos, 1)
+	assert.Equal(t, 3, calls)
+
+	// One retry is not enough for two rate-limited responses
+	calls = 0
+	_, err = retrying(1).UTXOs(context.Background(), "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	require.ErrorContains(t, err, "status 429")
+	assert.Equal(t, 2, calls)
+}
+
+func TestWOCHistory(t *testing.T) {
+	t.Parallel()
+
+	const addr = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/address/" + addr + "/confirmed/history":
+			assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+			if r.URL.Query().Get("pageToken") == "" {
+				_, _ = w.Write([]byte(`{"result":[{"tx_hash":"aa","height":100},{"tx_hash":"bb","height":101}],"nextPageToken":"page 2"}`))
+				return
+			}
+			assert.Equal(t, "page 2", r.URL.Query().Get("pageToken"))
+			_, _ = w.Write([]byte(`{"result":[{"tx_hash":"cc","height":102}]}`))
+		case "/address/" + addr + "/unconfirmed/history":
+			_, _ = w.Write([]byte(`{"result":[{"tx_hash":"dd"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	history, err := (&WOC{baseURL: server.URL}).History(context.Background(), addr)
+	require.NoError(t, err)
+	assert.Equal(t, []*HistoryTx{
+		{TxHash: "aa", Height: 100},
+		{TxHash: "bb", Height: 101},
+		{TxHash: "cc", Height: 102},
+		{TxHash: "dd"},
+	}, history)
+
+	_, err = (&WOC{baseURL: server.URL}).History(context.Background(), "1unknown")
+	require.ErrorContains(t, err, "status 404")
+}
+
+func TestParseWOCUnspent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid response with multiple UTXOs", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`{
+			"address": "1ABC...",
+			"script": "76a914...",
+			"result": [
+				{"height": 850000, "tx_pos": 0, "tx_hash": "abc123", "value": 10000, "isSpentInMempoolTx": false, "status": "confirmed"},
+				{"height": 850001, "tx_pos": 1, "tx_hash": "def456", "value": 20000, "isSpentInMempoolTx": false, "status": "confirmed"}
+			],
+			"error": ""
+		}`)
+
+		utxos, _, err := parseWOCUnspent(jsonResponse)
+		require.NoError(t, err)
+		require.Len(t, utxos, 2)
+
+		assert.Equal(t, "abc123", utxos[0].TxHash)
+		assert.Equal(t, uint32(0), utxos[0].TxPos)
+		assert.Equal(t, uint64(10000), utxos[0].Value)
+		assert.Equal(t, int64(850000), utxos[0].Height)
+
+		assert.Equal(t, "def456", utxos[1].TxHash)
+		assert.Equal(t, uint32(1), utxos[1].TxPos)
+		assert.Equal(t, uint64(20000), utxos[1].Value)
+	})
+
+	t.Run("filters out spent in mempool", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`{
+			"address": "1ABC...",
+			"script": "76a914...",
+			"result": [
+				{"height": 850000, "tx_pos": 0, "tx_hash": "available", "value": 10000, "isSpentInMempoolTx": false, "status": "confirmed"},
+				{"height": 850001, "tx_pos": 1, "tx_hash": "spent", "value": 20000, "isSpentInMempoolTx": true, "status": "confirmed"}
+			],
+			"error": ""
+		}`)
+
+		utxos, _, err := parseWOCUnspent(jsonResponse)
+		require.NoError(t, err)
+		require.Len(t, utxos, 1)
+		assert.Equal(t, "available", utxos[0].TxHash)
+	})
+
+	t.Run("empty result array", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`{
+			"address": "1ABC...",
+			"script": "76a914...",
+			"result": [],
+			"error": ""
+		}`)
+
+		utxos, _, err := parseWOCUnspent(jsonResponse)
+		require.NoError(t, err)
+		assert.Len(t, utxos, 0)
+	})
+
+	t.Run("API error in response", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`{
+			"address": "",
+			"script": "",
+			"result": [],
+			"error": "Address not found"
+		}`)
+
+		_, _, err := parseWOCUnspent(jsonResponse)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Address not found")
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`not valid json`)
+
+		_, _, err := parseWOCUnspent(jsonResponse)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse")
+	})
+
+	t.Run("all UTXOs spent in mempool", func(t *testing.T) {
+		t.Parallel()
+
+		jsonResponse := []byte(`{
+			"address": "1ABC...",
+			"script": "76a914...",
+			"result": [
+				{"height": 850000, "tx_pos": 0, "tx_hash": "spent1", "value": 10000, "isSpentInMempoolTx": true, "status": "confirmed"},
+				{"height": 850001, "tx_pos": 1, "tx_hash": "spent2", "value": 20000, "isSpentInMempoolTx": true, "status": "confirmed"}
+			],
+			"error": ""
+		}`)
+
+		utxos, _, err := parseWOCUnspent(jsonResponse)
+		require.NoError(t, err)
+		assert.Len(t, utxos, 0)
+	})
+}
+
+// Benchmarks
+
+func BenchmarkParseWOCUnspent(b *testing.B) {
+	jsonResponse := []byte(`{
+		"address": "1ABC...",
+		"script": "76a914...",
+		"result": [
+			{"height": 850000, "tx_pos": 0, "tx_hash": "abc123", "value": 10000, "isSpentInMempoolTx": false, "status": "confirmed"},
+			{"height": 850001, "tx_pos": 1, "tx_hash": "def456", "value": 20000, "isSpentInMempoolTx": false, "status": "confirmed"},
+			{"height": 850002, "tx_pos": 2, "tx_hash": "ghi789", "value": 30000, "isSpentInMempoolTx": false, "status": "confirmed"}
+		],
+		"error": ""
+	}`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = parseWOCUnspent(jsonResponse)
+	}
+}
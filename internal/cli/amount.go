@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bsvDecimals is the number of decimal places in a BSV amount: satoshis.
+const bsvDecimals = 8
+
+// feeRateDecimals is the number of decimal places in a fee rate in satoshis
+// per byte, the precision of a rate in satoshis per kilobyte.
+const feeRateDecimals = 3
+
+// ParseAmount parses a satoshi amount written as a plain integer ("1500"), an
+// integer with a sat or sats unit ("1500sat"), or a decimal with a bsv unit
+// ("0.001bsv", "0.001 BSV"). Units are case-insensitive. A decimal without a
+// unit is refused rather than guessed at, as is a BSV amount finer than a
+// satoshi or one that overflows.
+func ParseAmount(s string) (uint64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	number, unit := value, ""
+	for _, u := range []string{"sats", "sat", "bsv"} {
+		if n, ok := strings.CutSuffix(value, u); ok {
+			number, unit = strings.TrimSpace(n), u
+			break
+		}
+	}
+	if number == "" {
+		return 0, fmt.Errorf("invalid amount %q: missing number", s)
+	}
+	if strings.HasPrefix(number, "-") {
+		return 0, fmt.Errorf("invalid amount %q: must not be negative", s)
+	}
+
+	if unit == "bsv" {
+		sats, err := parseDecimal(number, bsvDecimals, "BSV")
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+		}
+		return sats, nil
+	}
+
+	if strings.Contains(number, ".") {
+		if unit == "" {
+			return 0, fmt.Errorf("invalid amount %q: a decimal amount needs the bsv unit, such as %sbsv", s, number)
+		}
+		return 0, fmt.Errorf("invalid amount %q: satoshis are whole numbers", s)
+	}
+	sats, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: expected satoshis, or a number with a sat or bsv unit", s)
+	}
+	return sats, nil
+}
+
+// ParseFeeRate parses a fee rate in satoshis per byte, such as "0.05" or
+// "1", and returns it in satoshis per kilobyte. Fractions finer than a
+// satoshi per kilobyte are refused rather than rounded.
+func ParseFeeRate(s string) (uint64, error) {
+	number := strings.TrimSpace(s)
+	if strings.HasPrefix(number, "-") {
+		return 0, fmt.Errorf("invalid fee rate %q: must not be negative", s)
+	}
+	perKb, err := parseDecimal(number, feeRateDecimals, "sat/byte")
+	if err != nil {
+		return 0, fmt.Errorf("invalid fee rate %q: %w", s, err)
+	}
+	return perKb, nil
+}
+
+// parseDecimal converts a decimal number of unit to an integer count of
+// 10^-decimals units exactly, without going through floating point.
+func parseDecimal(number string, decimals int, unit string) (uint64, error) {
+	whole, frac, _ := strings.Cut(number, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("missing number")
+	}
+	if len(frac) > decimals {
+		return 0, fmt.Errorf("%s has at most %d decimal places", unit, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("expected a decimal number of %s", unit)
+		}
+	}
+	sats, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("too large")
+	}
+	return sats, nil
+}
+
+// Amount is a satoshi amount flag accepting everything ParseAmount does.
+// Convert a *uint64 to register it: cmd.Flags().Var((*cli.Amount)(&sats), ...).
+type Amount uint64
+
+// Set parses s as the flag's value.
+func (a *Amount) Set(s string) error {
+	sats, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = Amount(sats)
+	return nil
+}
+
+// String returns the amount in satoshis.
+func (a *Amount) String() string {
+	return strconv.FormatUint(uint64(*a), 10)
+}
+
+// Type names the flag's value in help output.
+func (a *Amount) Type() string {
+	return "amount"
+}
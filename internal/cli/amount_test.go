@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+
+	valid := []struct {
+		input string
+		sats  uint64
+	}{
+		{"1500", 1500},
+		{"0", 0},
+		{"1500sat", 1500},
+		{"1500sats", 1500},
+		{" 1500 SAT ", 1500},
+		{"0.001bsv", 100000},
+		{"0.001 BSV", 100000},
+		{"1bsv", 100000000},
+		{".5bsv", 50000000},
+		{"21000000bsv", 2100000000000000},
+		{"0.00000001bsv", 1},
+		{"1.10000000bsv", 110000000},
+	}
+	for _, tt := range valid {
+		sats, err := ParseAmount(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.sats, sats, tt.input)
+	}
+
+	invalid := []struct {
+		input string
+		err   string
+	}{
+		{"", "missing number"},
+		{"bsv", "missing number"},
+		{"-5", "must not be negative"},
+		{"-0.1bsv", "must not be negative"},
+		{"0.5", "a decimal amount needs the bsv unit, such as 0.5bsv"},
+		{"1.5sat", "satoshis are whole numbers"},
+		{"0.000000001bsv", "at most 8 decimal places"},
+		{"1e3", "expected satoshis"},
+		{"1000btc", "expected satoshis"},
+		{"1,000", "expected satoshis"},
+		{"1.2.3bsv", "expected a decimal number of BSV"},
+		{"99999999999999bsv", "too large"},
+		{"18446744073709551616", "expected satoshis"},
+	}
+	for _, tt := range invalid {
+		_, err := ParseAmount(tt.input)
+		require.ErrorContains(t, err, tt.err, tt.input)
+	}
+}
+
+func TestAmountFlag(t *testing.T) {
+	t.Parallel()
+
+	var sats uint64
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Var((*Amount)(&sats), "sats", "")
+
+	require.NoError(t, flags.Parse([]string{"--sats", "0.25bsv"}))
+	assert.Equal(t, uint64(25000000), sats)
+	assert.Equal(t, "25000000", flags.Lookup("sats").Value.String())
+	assert.Equal(t, "amount", flags.Lookup("sats").Value.Type())
+
+	require.ErrorContains(t, flags.Parse([]string{"--sats", "0.5"}), "needs the bsv unit")
+}
+
+func TestParseFeeRate(t *testing.T) {
+	t.Parallel()
+
+	valid := []struct {
+		input string
+		perKb uint64
+	}{
+		{"0.05", 50},
+		{"1", 1000},
+		{" 0.5 ", 500},
+		{".001", 1},
+		{"0", 0},
+		{"1.250", 1250},
+	}
+	for _, tc := range valid {
+		perKb, err := ParseFeeRate(tc.input)
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.perKb, perKb, tc.input)
+	}
+
+	invalid := map[string]string{
+		"":       "missing number",
+		"-1":     "must not be negative",
+		"0.0005": "sat/byte has at most 3 decimal places",
+		"1sat":   "expected a decimal number of sat/byte",
+		"1.2.3":  "expected a decimal number of sat/byte",
+	}
+	for input, want := range invalid {
+		_, err := ParseFeeRate(input)
+		require.ErrorContains(t, err, want, input)
+	}
+}
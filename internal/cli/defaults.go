@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrz1836/go-template/internal/config"
+)
+
+// ApplyConfigDefaults is a PersistentPreRunE hook that seeds the running
+// command's flags from the tool's section of the commands block in
+// config.yaml. Flags given on the command line win, and tools run as before
+// when there is no config file.
+func ApplyConfigDefaults(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	return applyDefaults(cmd, cfg.CommandDefaults(cmd.Root().Name()))
+}
+
+// applyDefaults sets each default on cmd's flags unless the flag was given.
+// A default for a flag only a sibling subcommand has is skipped, and one for
+// a flag no command of the tool has is an error, so typos do not go unnoticed.
+func applyDefaults(cmd *cobra.Command, defaults map[string]string) error {
+	tool := cmd.Root().Name()
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			if !hasFlag(cmd.Root(), name) {
+				return fmt.Errorf("config.yaml commands.%s: %s has no --%s flag", tool, tool, name)
+			}
+			continue
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(defaults[name]); err != nil {
+			return fmt.Errorf("config.yaml commands.%s.%s: %w", tool, name, err)
+		}
+	}
+	return nil
+}
+
+// hasFlag reports whether cmd or any of its subcommands defines a flag.
+func hasFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if hasFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
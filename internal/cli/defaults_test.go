@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTool builds a carve-like root command with a subcommand, parsing args
+// against the subcommand when sub is set.
+func newTool(t *testing.T, sub bool, args ...string) (*cobra.Command, *cobra.Command) {
+	t.Helper()
+
+	root := &cobra.Command{Use: "carve"}
+	root.PersistentFlags().Bool("testnet", false, "")
+	root.Flags().Uint64("fee-per-kb", 100, "")
+	root.Flags().IntSlice("output", nil, "")
+
+	child := &cobra.Command{Use: "sweep"}
+	child.Flags().String("to", "", "")
+	root.AddCommand(child)
+
+	cmd := root
+	if sub {
+		cmd = child
+	}
+	cmd.InheritedFlags() // merge the root's persistent flags, as Execute does
+	require.NoError(t, cmd.ParseFlags(args))
+	return root, cmd
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	t.Run("seeds flags not given on the command line", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := newTool(t, false, "--testnet=false")
+		err := applyDefaults(cmd, map[string]string{"fee-per-kb": "50", "testnet": "true", "output": "0,2", "to": "x"})
+		require.NoError(t, err)
+
+		fee, _ := cmd.Flags().GetUint64("fee-per-kb")
+		assert.Equal(t, uint64(50), fee)
+		outputs, _ := cmd.Flags().GetIntSlice("output")
+		assert.Equal(t, []int{0, 2}, outputs)
+		testnet, _ := cmd.Flags().GetBool("testnet")
+		assert.False(t, testnet, "the command line wins")
+		assert.False(t, cmd.Flags().Changed("fee-per-kb"), "defaults do not count as given")
+	})
+
+	t.Run("applies inherited flags to subcommands", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := newTool(t, true)
+		require.NoError(t, applyDefaults(cmd, map[string]string{"testnet": "true", "fee-per-kb": "50"}))
+		testnet, _ := cmd.Flags().GetBool("testnet")
+		assert.True(t, testnet)
+	})
+
+	t.Run("rejects unknown flags and bad values", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := newTool(t, false)
+		require.EqualError(t, applyDefaults(cmd, map[string]string{"fee-per-kbb": "50"}),
+			"config.yaml commands.carve: carve has no --fee-per-kbb flag")
+		require.ErrorContains(t, applyDefaults(cmd, map[string]string{"fee-per-kb": "lots"}),
+			"config.yaml commands.carve.fee-per-kb")
+	})
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// Documentation formats written by gen-docs
+const (
+	DocsMan      = "man"
+	DocsMarkdown = "markdown"
+)
+
+// AddDocCommands gives a tool hidden completion and gen-docs subcommands, for
+// shell tab completion and man or markdown pages. Call it after the tool's
+// own subcommands are added: a root without subcommands keeps accepting
+// arbitrary arguments, as cobra allowed before it had any.
+func AddDocCommands(root *cobra.Command) {
+	if !root.HasSubCommands() && root.Args == nil {
+		root.Args = cobra.ArbitraryArgs
+	}
+	root.CompletionOptions.HiddenDefaultCmd = true
+	root.DisableAutoGenTag = true
+
+	var dir, format string
+	genDocs := &cobra.Command{
+		Use:    "gen-docs",
+		Short:  "Generate man pages or markdown documentation",
+		Long:   "Writes a page for " + root.Name() + " and each of its subcommands into --dir, as man pages (section 1) or markdown",
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return GenerateDocs(root, dir, format)
+		},
+	}
+	genDocs.Flags().StringVarP(&dir, "dir", "d", ".", "Directory to write the pages into")
+	genDocs.Flags().StringVar(&format, "format", DocsMan, "Page format: man or markdown")
+
+	root.AddCommand(genDocs)
+}
+
+// GenerateDocs writes a page for root and each visible subcommand into dir in
+// the format, creating dir if needed.
+func GenerateDocs(root *cobra.Command, dir, format string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating docs directory: %w", err)
+	}
+
+	var err error
+	switch strings.ToLower(format) {
+	case DocsMan:
+		err = doc.GenManTree(root, &doc.GenManHeader{
+			Title:   strings.ToUpper(root.Name()),
+			Section: "1",
+			Source:  "bsv-cmd-line-utils",
+			Manual:  "BSV Command Line Utilities",
+		}, dir)
+	case DocsMarkdown:
+		err = doc.GenMarkdownTree(root, dir)
+	default:
+		return fmt.Errorf("invalid --format %q: must be man or markdown", format)
+	}
+	if err != nil {
+		return fmt.Errorf("generating %s pages: %w", format, err)
+	}
+	return nil
+}
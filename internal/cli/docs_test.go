@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDocCommands(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hides the doc commands and keeps positional arguments", func(t *testing.T) {
+		t.Parallel()
+
+		var got []string
+		root := &cobra.Command{Use: "balance", RunE: func(_ *cobra.Command, args []string) error {
+			got = args
+			return nil
+		}}
+		AddDocCommands(root)
+
+		root.SetArgs([]string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "mtest"})
+		require.NoError(t, root.Execute())
+		assert.Equal(t, []string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "mtest"}, got)
+
+		var help bytes.Buffer
+		root.SetOut(&help)
+		root.SetArgs([]string{"--help"})
+		require.NoError(t, root.Execute())
+		assert.NotContains(t, help.String(), "gen-docs")
+		assert.NotContains(t, help.String(), "completion")
+	})
+
+	t.Run("leaves argument checks of tools with subcommands alone", func(t *testing.T) {
+		t.Parallel()
+
+		root := &cobra.Command{Use: "wallet"}
+		root.AddCommand(&cobra.Command{Use: "create", Run: func(*cobra.Command, []string) {}})
+		AddDocCommands(root)
+		assert.Nil(t, root.Args)
+	})
+}
+
+func TestGenerateDocs(t *testing.T) {
+	t.Parallel()
+
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "keygen", Short: "Generate BSV key pairs", Run: func(*cobra.Command, []string) {}}
+		root.Flags().Bool("testnet", false, "Generate testnet keys")
+		root.AddCommand(&cobra.Command{Use: "derive", Short: "Derive addresses", Run: func(*cobra.Command, []string) {}})
+		AddDocCommands(root)
+		return root
+	}
+
+	t.Run("man pages", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "man")
+		require.NoError(t, GenerateDocs(newRoot(), dir, DocsMan))
+		page, err := os.ReadFile(filepath.Join(dir, "keygen.1"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `.TH "KEYGEN" "1"`)
+		assert.Contains(t, string(page), "testnet")
+		assert.FileExists(t, filepath.Join(dir, "keygen-derive.1"))
+		assert.NoFileExists(t, filepath.Join(dir, "keygen-gen-docs.1"), "hidden commands are skipped")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, GenerateDocs(newRoot(), dir, "Markdown"))
+		page, err := os.ReadFile(filepath.Join(dir, "keygen.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), "Generate BSV key pairs")
+		assert.NotContains(t, string(page), "Auto generated")
+		assert.FileExists(t, filepath.Join(dir, "keygen_derive.md"))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, GenerateDocs(newRoot(), t.TempDir(), "html"), `invalid --format "html": must be man or markdown`)
+	})
+}
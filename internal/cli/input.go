@@ -4,6 +4,12 @@
 //   - Hex validation with pre-compiled regex for performance
 //   - Stdin reading and sanitization
 //   - String cleaning utilities
+//   - Flag defaults from the commands section of config.yaml
+//   - Satoshi amounts with a sat or bsv unit, as arguments or flags
+//   - Fee rates in satoshis per byte, with up to three decimal places
+//   - Hidden completion and gen-docs subcommands for every tool
+//   - Progress bars and spinners for long-running operations, drawn only on a terminal
+//   - Hidden prompts for secrets such as private keys
 package cli
 
 import (
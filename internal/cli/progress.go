@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Progress drawing
+const (
+	progressBarWidth = 30
+	progressInterval = 100 * time.Millisecond // Redraw rate, so a stalled call still shows time passing
+)
+
+// spinnerFrames animate a Progress whose total is unknown.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress draws a progress bar, or a spinner when the total is unknown, with
+// the elapsed time on one redrawn line. It only draws on a terminal: piped to a
+// file or another tool, every method is a no-op, so callers can keep their
+// plain log output when Enabled is false.
+type Progress struct {
+	w       io.Writer
+	label   string
+	total   int
+	enabled bool
+	start   time.Time
+
+	mu    sync.Mutex
+	done  int
+	frame int
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewProgress starts a progress line on stderr for total units of work, or a
+// spinner when total is 0. Call Finish when the work ends.
+func NewProgress(label string, total int) *Progress {
+	return newProgress(os.Stderr, label, total, IsTerminal(os.Stderr))
+}
+
+// newProgress starts a progress line on w, drawing only when enabled.
+func newProgress(w io.Writer, label string, total int, enabled bool) *Progress {
+	p := &Progress{w: w, label: label, total: total, enabled: enabled, start: time.Now(), stop: make(chan struct{})}
+	if !enabled {
+		return p
+	}
+
+	p.draw()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.frame++
+				p.draw()
+				p.mu.Unlock()
+			}
+		}
+	}()
+	return p
+}
+
+// IsTerminal reports whether f is attached to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd())) //nolint:gosec // file descriptors fit in an int
+}
+
+// Enabled reports whether the progress line is drawn.
+func (p *Progress) Enabled() bool {
+	return p.enabled
+}
+
+// Add records n more finished units of work.
+func (p *Progress) Add(n int) {
+	if !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.draw()
+}
+
+// Finish stops the progress line and clears it, so the caller's next output
+// starts on a clean line.
+func (p *Progress) Finish() {
+	if !p.enabled {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+// draw redraws the line; p.mu must be held once the ticker is running.
+func (p *Progress) draw() {
+	fmt.Fprint(p.w, "\r\033[K"+p.line(time.Since(p.start)))
+}
+
+// line renders the progress line after elapsed time.
+func (p *Progress) line(elapsed time.Duration) string {
+	seconds := fmt.Sprintf("%.1fs", elapsed.Seconds())
+	if p.total <= 0 {
+		line := p.label + " " + spinnerFrames[p.frame%len(spinnerFrames)]
+		if p.done > 0 {
+			line += fmt.Sprintf(" %d", p.done)
+		}
+		return line + " " + seconds
+	}
+
+	done := min(p.done, p.total)
+	filled := done * progressBarWidth / p.total
+	return fmt.Sprintf("%s [%s%s] %d/%d %3d%% %s", p.label,
+		strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled),
+		done, p.total, done*100/p.total, seconds)
+}
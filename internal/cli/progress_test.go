@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressLine(t *testing.T) {
+	t.Parallel()
+
+	bar := &Progress{label: "Exporting", total: 40, done: 10}
+	assert.Equal(t, "Exporting [=======                       ] 10/40  25% 1.5s", bar.line(1500*time.Millisecond))
+
+	bar.done = 50
+	assert.Equal(t, "Exporting [==============================] 40/40 100% 2.0s", bar.line(2*time.Second))
+
+	spinner := &Progress{label: "Fetching UTXOs", frame: 11}
+	assert.Equal(t, "Fetching UTXOs ⠙ 0.3s", spinner.line(300*time.Millisecond))
+
+	spinner.done = 7
+	assert.Equal(t, "Fetching UTXOs ⠙ 7 0.3s", spinner.line(300*time.Millisecond))
+}
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("draws nothing when disabled", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		p := newProgress(&buf, "Working", 3, false)
+		p.Add(2)
+		p.Finish()
+		assert.False(t, p.Enabled())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("redraws and clears the line", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		p := newProgress(&buf, "Working", 3, true)
+		p.Add(2)
+		p.Finish()
+		assert.True(t, p.Enabled())
+		assert.Contains(t, buf.String(), "\r\033[KWorking [")
+		assert.Contains(t, buf.String(), "2/3  66%")
+		assert.Equal(t, "\r\033[K", buf.String()[buf.Len()-4:])
+	})
+}
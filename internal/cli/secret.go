@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrNoTerminal is returned by ReadSecret when stdin is not a terminal.
+var ErrNoTerminal = errors.New("stdin is not a terminal")
+
+// ReadSecret prints prompt to stderr and reads a line from the terminal
+// without echoing it, trimming surrounding whitespace. Unlike a password,
+// a secret is never read from a pipe, which may be carrying other input.
+func ReadSecret(prompt string) (string, error) {
+	if !IsTerminal(os.Stdin) {
+		return "", ErrNoTerminal
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd())) //nolint:gosec // file descriptors fit in an int
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSecretWithoutTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(path, []byte("secret\n"), 0o600))
+	f, err := os.Open(path) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	_, err = ReadSecret("WIF: ")
+	require.ErrorIs(t, err, ErrNoTerminal)
+}
@@ -1,13 +1,17 @@
 // Package config provides shared configuration management for BSV CLI tools.
 //
 // This package handles loading and parsing of config.yaml files used by
-// broadcast, txstatus, and other ARC-based CLI tools.
+// broadcast, txstatus, headers, and other CLI tools that need service endpoints,
+// including the chain data providers selected in its providers section, and the
+// per-tool flag defaults in its commands section. Watch reloads the file for
+// long-running commands when it changes or the process receives SIGHUP.
 package config
 
 import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +23,61 @@ type ARCConfig struct {
 	Timeout string `yaml:"timeout"` // HTTP timeout duration (e.g., "30s")
 }
 
+// NamedARCConfig is an additional ARC endpoint, such as another miner's,
+// that txstatus --all-endpoints compares against the primary one.
+type NamedARCConfig struct {
+	Name      string `yaml:"name"` // Label shown in comparisons (default: the URL)
+	ARCConfig `yaml:",inline"`
+}
+
+// HeadersConfig holds the Block Headers Service endpoint used by the headers tool.
+// When URL is empty, headers are synced from WhatsOnChain instead.
+type HeadersConfig struct {
+	URL    string `yaml:"url"`     // Block Headers Service URL (e.g., "https://headers.example.com")
+	APIKey string `yaml:"api_key"` // API key for authentication
+}
+
+// ProvidersConfig selects the backends that commands read chain data from and
+// broadcast through. Empty fields select the defaults.
+type ProvidersConfig struct {
+	Data      string `yaml:"data"`      // UTXOs and transactions: whatsonchain (default), bitails, gorillapool, or node
+	Headers   string `yaml:"headers"`   // Block headers: whatsonchain (default) or node
+	Broadcast string `yaml:"broadcast"` // Broadcasting: arc (default), mapi, whatsonchain, bitails, or node
+}
+
+// MAPIConfig holds the configuration for a miner's legacy mAPI endpoint.
+type MAPIConfig struct {
+	URL      string `yaml:"url"`       // mAPI endpoint URL (e.g., "https://mapi.example.com")
+	APIKey   string `yaml:"api_key"`   // API key for authentication
+	MinerKey string `yaml:"miner_key"` // Miner identity public key (hex) responses must be signed by
+}
+
+// BitailsConfig holds the configuration for a Bitails API endpoint.
+// When URL is empty, the public Bitails API for the network is used.
+type BitailsConfig struct {
+	URL    string `yaml:"url"`     // Bitails API URL (e.g., "https://api.bitails.io")
+	APIKey string `yaml:"api_key"` // API key for higher rate limits
+}
+
+// WhatsOnChainConfig holds settings for the WhatsOnChain API.
+type WhatsOnChainConfig struct {
+	APIKey string `yaml:"api_key"` // API key for higher rate limits
+}
+
+// GorillaPoolConfig holds settings for GorillaPool's 1Sat indexer API.
+type GorillaPoolConfig struct {
+	URL string `yaml:"url"` // API URL (e.g., "https://ordinals.gorillapool.io/api")
+}
+
+// NodeConfig holds the JSON-RPC endpoint of an SV Node.
+type NodeConfig struct {
+	URL      string `yaml:"url"`      // RPC URL (e.g., "http://127.0.0.1:8332"), optionally with user:password@
+	User     string `yaml:"user"`     // RPC user name
+	Password string `yaml:"password"` // RPC password
+	Cookie   string `yaml:"cookie"`   // Cookie file the node writes, holding user:password, instead of User and Password
+	Scan     bool   `yaml:"scan"`     // List UTXOs with scantxoutset, for addresses not in the node's wallet
+}
+
 // PollingConfig defines parameters for transaction status polling when monitoring is enabled.
 type PollingConfig struct {
 	Interval      string  `yaml:"interval"`       // Time between status checks (e.g., "3s")
@@ -38,6 +97,26 @@ type Config struct {
 	ARCTestnet ARCConfig     `yaml:"arc-testnet"` // Testnet ARC configuration
 	Polling    PollingConfig `yaml:"polling"`     // Polling parameters for monitoring
 	Targets    TargetsConfig `yaml:"targets"`     // Target status configuration
+
+	ARCEndpointsMainnet []NamedARCConfig `yaml:"arc-endpoints-mainnet"` // Additional mainnet ARC endpoints
+	ARCEndpointsTestnet []NamedARCConfig `yaml:"arc-endpoints-testnet"` // Additional testnet ARC endpoints
+
+	HeadersMainnet HeadersConfig `yaml:"headers-mainnet"` // Mainnet Block Headers Service
+	HeadersTestnet HeadersConfig `yaml:"headers-testnet"` // Testnet Block Headers Service
+
+	Providers           ProvidersConfig    `yaml:"providers"`            // Chain data and broadcast backends
+	WhatsOnChainMainnet WhatsOnChainConfig `yaml:"whatsonchain-mainnet"` // Mainnet WhatsOnChain API
+	WhatsOnChainTestnet WhatsOnChainConfig `yaml:"whatsonchain-testnet"` // Testnet WhatsOnChain API
+	BitailsMainnet      BitailsConfig      `yaml:"bitails-mainnet"`      // Mainnet Bitails API
+	BitailsTestnet      BitailsConfig      `yaml:"bitails-testnet"`      // Testnet Bitails API
+	GorillaPoolMainnet  GorillaPoolConfig  `yaml:"gorillapool-mainnet"`  // Mainnet GorillaPool API
+	GorillaPoolTestnet  GorillaPoolConfig  `yaml:"gorillapool-testnet"`  // Testnet GorillaPool API
+	NodeMainnet         NodeConfig         `yaml:"node-mainnet"`         // Mainnet SV Node RPC
+	NodeTestnet         NodeConfig         `yaml:"node-testnet"`         // Testnet SV Node RPC
+	MAPIMainnet         MAPIConfig         `yaml:"mapi-mainnet"`         // Mainnet miner mAPI
+	MAPITestnet         MAPIConfig         `yaml:"mapi-testnet"`         // Testnet miner mAPI
+
+	Commands map[string]map[string]any `yaml:"commands"` // Flag defaults by tool name, then flag name
 }
 
 // Load reads and parses a config.yaml file.
@@ -51,24 +130,11 @@ func Load() (*Config, error) {
 // If path is empty, it searches the executable directory then the current working directory.
 // Returns the parsed config or an error if the config file cannot be found or parsed.
 func LoadFromPath(path string) (*Config, error) {
-	var configPath string
-
-	if path != "" {
-		configPath = path
-	} else {
-		// Get the executable directory
-		exePath, err := os.Executable()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get executable path: %w", err)
-		}
-		exeDir := filepath.Dir(exePath)
-
-		// Try config.yaml in the executable directory first
-		configPath = filepath.Join(exeDir, "config.yaml")
-
-		// If not found, try the current working directory
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			configPath = "config.yaml"
+	configPath := path
+	if configPath == "" {
+		var err error
+		if configPath, err = Path(); err != nil {
+			return nil, err
 		}
 	}
 
@@ -85,6 +151,24 @@ func LoadFromPath(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Path returns the config.yaml that Load reads: the one in the executable
+// directory when it exists, otherwise the one in the current working directory.
+func Path() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	// Try config.yaml in the executable directory first
+	configPath := filepath.Join(filepath.Dir(exePath), "config.yaml")
+
+	// If not found, try the current working directory
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		configPath = "config.yaml"
+	}
+	return configPath, nil
+}
+
 // GetARCConfig returns the appropriate ARC configuration based on the testnet flag.
 func (c *Config) GetARCConfig(testnet bool) ARCConfig {
 	if testnet {
@@ -93,6 +177,107 @@ func (c *Config) GetARCConfig(testnet bool) ARCConfig {
 	return c.ARCMainnet
 }
 
+// GetARCEndpoints returns every configured ARC endpoint for the network: the
+// primary arc-mainnet or arc-testnet endpoint first, then the additional ones.
+// Endpoints without a URL are skipped and unnamed ones are named by their URL.
+func (c *Config) GetARCEndpoints(testnet bool) []NamedARCConfig {
+	primaryName, extra := "arc-mainnet", c.ARCEndpointsMainnet
+	if testnet {
+		primaryName, extra = "arc-testnet", c.ARCEndpointsTestnet
+	}
+
+	endpoints := make([]NamedARCConfig, 0, len(extra)+1)
+	if primary := c.GetARCConfig(testnet); primary.URL != "" {
+		endpoints = append(endpoints, NamedARCConfig{Name: primaryName, ARCConfig: primary})
+	}
+	for _, endpoint := range extra {
+		if endpoint.URL == "" {
+			continue
+		}
+		if endpoint.Name == "" {
+			endpoint.Name = endpoint.URL
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// GetHeadersConfig returns the appropriate Block Headers Service configuration based on the testnet flag.
+func (c *Config) GetHeadersConfig(testnet bool) HeadersConfig {
+	if testnet {
+		return c.HeadersTestnet
+	}
+	return c.HeadersMainnet
+}
+
+// GetWhatsOnChainConfig returns the appropriate WhatsOnChain configuration based on the testnet flag.
+func (c *Config) GetWhatsOnChainConfig(testnet bool) WhatsOnChainConfig {
+	if testnet {
+		return c.WhatsOnChainTestnet
+	}
+	return c.WhatsOnChainMainnet
+}
+
+// GetBitailsConfig returns the appropriate Bitails configuration based on the testnet flag.
+func (c *Config) GetBitailsConfig(testnet bool) BitailsConfig {
+	if testnet {
+		return c.BitailsTestnet
+	}
+	return c.BitailsMainnet
+}
+
+// GetGorillaPoolConfig returns the appropriate GorillaPool configuration based on the testnet flag.
+func (c *Config) GetGorillaPoolConfig(testnet bool) GorillaPoolConfig {
+	if testnet {
+		return c.GorillaPoolTestnet
+	}
+	return c.GorillaPoolMainnet
+}
+
+// GetNodeConfig returns the appropriate SV Node RPC configuration based on the testnet flag.
+func (c *Config) GetNodeConfig(testnet bool) NodeConfig {
+	if testnet {
+		return c.NodeTestnet
+	}
+	return c.NodeMainnet
+}
+
+// GetMAPIConfig returns the appropriate mAPI configuration based on the testnet flag.
+func (c *Config) GetMAPIConfig(testnet bool) MAPIConfig {
+	if testnet {
+		return c.MAPITestnet
+	}
+	return c.MAPIMainnet
+}
+
+// CommandDefaults returns the flag defaults configured for a tool, keyed by
+// flag name. Keys may use underscores for the flag's hyphens (fee_per_kb for
+// --fee-per-kb), and lists are joined with commas as slice flags expect.
+func (c *Config) CommandDefaults(tool string) map[string]string {
+	section := c.Commands[tool]
+	if len(section) == 0 {
+		return nil
+	}
+
+	defaults := make(map[string]string, len(section))
+	for key, value := range section {
+		name := strings.ReplaceAll(key, "_", "-")
+		switch v := value.(type) {
+		case nil:
+			defaults[name] = ""
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			defaults[name] = strings.Join(items, ",")
+		default:
+			defaults[name] = fmt.Sprint(v)
+		}
+	}
+	return defaults
+}
+
 // Validate checks that required configuration fields are present.
 // Returns an error if required fields are missing.
 func (c *Config) Validate(testnet bool) error {
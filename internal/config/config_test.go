@@ -182,6 +182,131 @@ func TestGetARCConfig(t *testing.T) {
 	})
 }
 
+func TestGetHeadersConfig(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+headers-mainnet:
+  url: "https://headers.example.com"
+  api_key: "headers-key"
+headers-testnet:
+  url: "https://headers-test.example.com"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+
+	mainnet := cfg.GetHeadersConfig(false)
+	assert.Equal(t, "https://headers.example.com", mainnet.URL)
+	assert.Equal(t, "headers-key", mainnet.APIKey)
+
+	testnet := cfg.GetHeadersConfig(true)
+	assert.Equal(t, "https://headers-test.example.com", testnet.URL)
+	assert.Equal(t, "", testnet.APIKey)
+
+	assert.Equal(t, "", (&Config{}).GetHeadersConfig(false).URL)
+}
+
+func TestProvidersConfig(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+providers:
+  data: bitails
+  headers: node
+  broadcast: node
+whatsonchain-mainnet:
+  api_key: "woc-key"
+bitails-mainnet:
+  api_key: "bitails-key"
+gorillapool-testnet:
+  url: "https://gorillapool.example.com/api"
+node-testnet:
+  url: "http://127.0.0.1:18332"
+  user: "rpc"
+  password: "secret"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, ProvidersConfig{Data: "bitails", Headers: "node", Broadcast: "node"}, cfg.Providers)
+	assert.Equal(t, "woc-key", cfg.GetWhatsOnChainConfig(false).APIKey)
+	assert.Equal(t, "", cfg.GetWhatsOnChainConfig(true).APIKey)
+	assert.Equal(t, "bitails-key", cfg.GetBitailsConfig(false).APIKey)
+	assert.Equal(t, "", cfg.GetBitailsConfig(true).APIKey)
+	assert.Equal(t, "https://gorillapool.example.com/api", cfg.GetGorillaPoolConfig(true).URL)
+	assert.Equal(t, "", cfg.GetGorillaPoolConfig(false).URL)
+	assert.Equal(t, NodeConfig{URL: "http://127.0.0.1:18332", User: "rpc", Password: "secret"}, cfg.GetNodeConfig(true))
+	assert.Equal(t, "", cfg.GetNodeConfig(false).URL)
+}
+
+func TestCommandDefaults(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+commands:
+  carve:
+    fee_per_kb: 50
+    testnet: true
+  pick:
+    output: [0, 2]
+    format: "json"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
+
+	cfg, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"fee-per-kb": "50", "testnet": "true"}, cfg.CommandDefaults("carve"))
+	assert.Equal(t, map[string]string{"output": "0,2", "format": "json"}, cfg.CommandDefaults("pick"))
+	assert.Nil(t, cfg.CommandDefaults("broadcast"))
+}
+
+func TestGetARCEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+arc-mainnet:
+  url: "https://arc.taal.com"
+  api_key: "taal-key"
+arc-endpoints-mainnet:
+  - name: gorillapool
+    url: "https://arc.gorillapool.io"
+  - url: "https://arc.example.com"
+    api_key: "example-key"
+  - name: unconfigured
+arc-endpoints-testnet:
+  - name: test
+    url: "https://arc-test.example.com"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []NamedARCConfig{
+		{Name: "arc-mainnet", ARCConfig: ARCConfig{URL: "https://arc.taal.com", APIKey: "taal-key"}},
+		{Name: "gorillapool", ARCConfig: ARCConfig{URL: "https://arc.gorillapool.io"}},
+		{Name: "https://arc.example.com", ARCConfig: ARCConfig{URL: "https://arc.example.com", APIKey: "example-key"}},
+	}, cfg.GetARCEndpoints(false))
+
+	// No primary testnet endpoint
+	assert.Equal(t, []NamedARCConfig{
+		{Name: "test", ARCConfig: ARCConfig{URL: "https://arc-test.example.com"}},
+	}, cfg.GetARCEndpoints(true))
+}
+
 func TestValidate(t *testing.T) {
 	t.Parallel()
 
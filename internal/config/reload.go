@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ReloadInterval is how often Watch checks config.yaml for changes when a
+// command has no reason to pick another.
+const ReloadInterval = 2 * time.Second
+
+// Watch reloads the config file at path, or the one Load reads when path is
+// empty, whenever the process receives SIGHUP or the file's modification time
+// or size changes, checked every interval. It lets long-running commands pick
+// up rotated endpoints and API keys without a restart.
+//
+// Each configuration that loads is sent on the returned channel, which holds
+// only the latest, so a reader that falls behind never applies a stale one. A
+// file that cannot be read or parsed is passed to onError, when set, and the
+// previous configuration stays in use. Until ctx is done, when the channel is
+// closed, SIGHUP reloads instead of stopping the process.
+func Watch(ctx context.Context, path string, interval time.Duration, onError func(error)) <-chan *Config {
+	updates := make(chan *Config, 1)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	last := fileVersion(path)
+
+	go func() {
+		defer close(updates)
+		defer signal.Stop(hup)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				last = fileVersion(path)
+			case <-ticker.C:
+				version := fileVersion(path)
+				if version == last {
+					continue
+				}
+				last = version
+			}
+
+			cfg, err := LoadFromPath(path)
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+
+			// Replace an update the reader has not taken yet
+			select {
+			case <-updates:
+			default:
+			}
+			updates <- cfg
+		}
+	}()
+
+	return updates
+}
+
+// version identifies one state of a file, changing when it is rewritten.
+type version struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileVersion returns the version of the config file at path, or of the one
+// Load reads when path is empty. A missing file has the zero version.
+func fileVersion(path string) version {
+	if path == "" {
+		var err error
+		if path, err = Path(); err != nil {
+			return version{}
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return version{}
+	}
+	return version{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextConfig waits for a reloaded configuration from updates.
+func nextConfig(t *testing.T, updates <-chan *Config) *Config {
+	t.Helper()
+	select {
+	case cfg := <-updates:
+		require.NotNil(t, cfg)
+		return cfg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no configuration reloaded")
+		return nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+	// Not parallel: SIGHUP reaches every watcher in the process
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	start := time.Now().Add(-time.Hour)
+	write("arc-mainnet:\n  url: https://one.example.com\n", start)
+
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := Watch(ctx, path, 10*time.Millisecond, func(err error) { errs <- err })
+
+	t.Run("reloads a changed file", func(t *testing.T) {
+		write("arc-mainnet:\n  url: https://two.example.com\n  api_key: rotated\n", start.Add(time.Minute))
+		cfg := nextConfig(t, updates)
+		assert.Equal(t, "https://two.example.com", cfg.ARCMainnet.URL)
+		assert.Equal(t, "rotated", cfg.ARCMainnet.APIKey)
+	})
+
+	t.Run("keeps the configuration when the file is invalid", func(t *testing.T) {
+		write("arc-mainnet: [", start.Add(2*time.Minute))
+		select {
+		case err := <-errs:
+			require.ErrorContains(t, err, "failed to parse config file")
+		case <-time.After(5 * time.Second):
+			t.Fatal("invalid file not reported")
+		}
+		assert.Empty(t, updates)
+	})
+
+	t.Run("reloads on SIGHUP", func(t *testing.T) {
+		// Let polling take the rewrite first, so the next reload is the signal's
+		write("arc-mainnet:\n  url: https://three.example.com\n", start.Add(3*time.Minute))
+		nextConfig(t, updates)
+
+		process, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, process.Signal(syscall.SIGHUP))
+		assert.Equal(t, "https://three.example.com", nextConfig(t, updates).ARCMainnet.URL)
+	})
+}
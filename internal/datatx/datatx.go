@@ -0,0 +1,236 @@
+// Package datatx encodes and decodes files stored in OP_RETURN outputs.
+//
+// The package supports:
+//   - B:// — a whole file in one output: data, media type, encoding, filename
+//   - Bcat — large files split across Bcat part transactions, tied together
+//     by a Bcat transaction that lists the part txids in order
+//   - Locating and decoding either protocol in a transaction
+//
+// All outputs are safe data outputs (OP_FALSE OP_RETURN).
+package datatx
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Protocol prefixes (Bitcom addresses)
+const (
+	BPrefix        = "19HxigV4QyBv3tHpQVcUEQyq1pzZVdoAut"
+	BcatPrefix     = "15DHFxWZJT58f9nhyGnsRBqrgwK4W6h4Up"
+	BcatPartPrefix = "1ChDHzdd1H4wSjgGMHyndZm6qxEDGjqpJL"
+	MAPPrefix      = "1PuQa7K62MiKCtssSLKy1kh56WWU7MtUR5"
+	AIPPrefix      = "15PciHG22SNLQJXMoSUaWVi7WSqc7hCfva"
+)
+
+// Protocol names reported in Payload.Protocol
+const (
+	ProtocolB        = "b"
+	ProtocolBcat     = "bcat"
+	ProtocolBcatPart = "bcat-part"
+)
+
+// placeholder fills empty Bcat fields; the protocol uses a single space for "none".
+const placeholder = " "
+
+// ErrNotFound is returned when a transaction carries no B:// or Bcat output.
+var ErrNotFound = errors.New("no B:// or Bcat output found")
+
+// File is a file and its metadata.
+type File struct {
+	Data     []byte `json:"-"`
+	MimeType string `json:"mimeType"`
+	Encoding string `json:"encoding,omitempty"` // e.g. "utf-8" or "binary"
+	Filename string `json:"filename,omitempty"`
+}
+
+// Payload is a decoded data output.
+type Payload struct {
+	Protocol string   `json:"protocol"` // One of the Protocol* constants
+	Output   int      `json:"output"`   // Index of the data output
+	File              // Metadata, plus Data for B:// and Bcat parts
+	Info     string   `json:"info,omitempty"`  // Bcat info field
+	Flag     string   `json:"flag,omitempty"`  // Bcat flag field, e.g. "gzip"
+	Parts    []string `json:"parts,omitempty"` // Bcat part txids, in order
+}
+
+// dataScript builds OP_FALSE OP_RETURN followed by pushes.
+func dataScript(pushes ...[]byte) (*script.Script, error) {
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.OpFALSE, script.OpRETURN); err != nil {
+		return nil, err
+	}
+	for _, p := range pushes {
+		if err := s.AppendPushData(p); err != nil {
+			return nil, fmt.Errorf("failed to push data: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// BScript builds the B:// output script for a file.
+func BScript(f *File) (*script.Script, error) {
+	pushes := [][]byte{[]byte(BPrefix), f.Data, []byte(f.MimeType), []byte(f.Encoding)}
+	if f.Filename != "" {
+		pushes = append(pushes, []byte(f.Filename))
+	}
+	return dataScript(pushes...)
+}
+
+// BcatPartScript builds the output script of one Bcat part.
+func BcatPartScript(chunk []byte) (*script.Script, error) {
+	return dataScript([]byte(BcatPartPrefix), chunk)
+}
+
+// BcatScript builds the Bcat output script that references the part
+// transactions in order. Part txids are pushed as 32 bytes in the usual
+// (display) byte order.
+func BcatScript(f *File, info, flag string, parts []string) (*script.Script, error) {
+	pushes := [][]byte{
+		[]byte(BcatPrefix),
+		[]byte(orPlaceholder(info)),
+		[]byte(f.MimeType),
+		[]byte(orPlaceholder(f.Encoding)),
+		[]byte(orPlaceholder(f.Filename)),
+		[]byte(orPlaceholder(flag)),
+	}
+	for _, txid := range parts {
+		b, err := hex.DecodeString(txid)
+		if err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid part txid: %s", txid)
+		}
+		pushes = append(pushes, b)
+	}
+	return dataScript(pushes...)
+}
+
+// Chunk splits data into chunks of at most size bytes.
+func Chunk(data []byte, size int) [][]byte {
+	if size <= 0 || len(data) <= size {
+		return [][]byte{data}
+	}
+	chunks := make([][]byte, 0, (len(data)+size-1)/size)
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return append(chunks, data)
+}
+
+// Find returns the first B://, Bcat or Bcat part output of a transaction.
+func Find(tx *transaction.Transaction) (*Payload, error) {
+	for i, out := range tx.Outputs {
+		p, err := Decode(out.LockingScript)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		p.Output = i
+		return p, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Decode decodes a B://, Bcat or Bcat part output script.
+// It returns ErrNotFound for any other script.
+func Decode(s *script.Script) (*Payload, error) {
+	pushes, ok := Pushes(s)
+	if !ok || len(pushes) == 0 {
+		return nil, ErrNotFound
+	}
+
+	switch string(pushes[0]) {
+	case BPrefix:
+		if len(pushes) < 3 {
+			return nil, fmt.Errorf("B:// output has %d fields, want at least 3", len(pushes))
+		}
+		p := &Payload{Protocol: ProtocolB, File: File{Data: pushes[1], MimeType: string(pushes[2])}}
+		if len(pushes) > 3 {
+			p.Encoding = string(pushes[3])
+		}
+		if len(pushes) > 4 {
+			p.Filename = string(pushes[4])
+		}
+		return p, nil
+
+	case BcatPartPrefix:
+		if len(pushes) < 2 {
+			return nil, fmt.Errorf("Bcat part output has no data")
+		}
+		return &Payload{Protocol: ProtocolBcatPart, File: File{Data: pushes[1]}}, nil
+
+	case BcatPrefix:
+		if len(pushes) < 7 {
+			return nil, fmt.Errorf("Bcat output has %d fields, want at least 7", len(pushes))
+		}
+		p := &Payload{
+			Protocol: ProtocolBcat,
+			Info:     fromPlaceholder(pushes[1]),
+			File: File{
+				MimeType: string(pushes[2]),
+				Encoding: fromPlaceholder(pushes[3]),
+				Filename: fromPlaceholder(pushes[4]),
+			},
+			Flag: fromPlaceholder(pushes[5]),
+		}
+		for _, b := range pushes[6:] {
+			if len(b) != 32 {
+				return nil, fmt.Errorf("Bcat part reference has %d bytes, want 32", len(b))
+			}
+			p.Parts = append(p.Parts, hex.EncodeToString(b))
+		}
+		return p, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// Pushes returns the pushes following OP_RETURN in a data output, or false
+// for any other script or one whose pushes do not parse.
+func Pushes(s *script.Script) ([][]byte, bool) {
+	if s == nil || !s.IsData() {
+		return nil, false
+	}
+
+	b := *s
+	offset := 1
+	if b[0] == script.OpFALSE {
+		offset = 2
+	}
+
+	rest := script.Script(b[offset:])
+	var pushes [][]byte
+	pos := 0
+	for pos < len(rest) {
+		op, err := rest.ReadOp(&pos)
+		if err != nil {
+			return nil, false
+		}
+		pushes = append(pushes, op.Data)
+	}
+	return pushes, true
+}
+
+// orPlaceholder substitutes the Bcat "none" value for an empty field.
+func orPlaceholder(s string) string {
+	if s == "" {
+		return placeholder
+	}
+	return s
+}
+
+// fromPlaceholder reads a Bcat field, mapping the "none" value to "".
+func fromPlaceholder(b []byte) string {
+	s := string(b)
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	return s
+}
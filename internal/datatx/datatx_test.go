@@ -0,0 +1,141 @@
+package datatx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTxA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testTxB = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+)
+
+func TestBRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	f := &File{Data: []byte("hello world"), MimeType: "text/plain", Encoding: "utf-8", Filename: "hello.txt"}
+	s, err := BScript(f)
+	require.NoError(t, err)
+	assert.True(t, s.IsData())
+
+	p, err := Decode(s)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolB, p.Protocol)
+	assert.Equal(t, f.Data, p.Data)
+	assert.Equal(t, "text/plain", p.MimeType)
+	assert.Equal(t, "utf-8", p.Encoding)
+	assert.Equal(t, "hello.txt", p.Filename)
+}
+
+func TestBcatRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	f := &File{MimeType: "image/png", Filename: "cat.png"}
+	s, err := BcatScript(f, "", "", []string{testTxA, testTxB})
+	require.NoError(t, err)
+
+	p, err := Decode(s)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolBcat, p.Protocol)
+	assert.Equal(t, "image/png", p.MimeType)
+	assert.Equal(t, "cat.png", p.Filename)
+	assert.Empty(t, p.Encoding)
+	assert.Empty(t, p.Info)
+	assert.Empty(t, p.Flag)
+	assert.Equal(t, []string{testTxA, testTxB}, p.Parts)
+
+	t.Run("part", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := BcatPartScript([]byte{1, 2, 3})
+		require.NoError(t, err)
+		p, err := Decode(s)
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolBcatPart, p.Protocol)
+		assert.Equal(t, []byte{1, 2, 3}, p.Data)
+	})
+
+	t.Run("invalid part txid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BcatScript(f, "", "", []string{"abcd"})
+		require.Error(t, err)
+	})
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	t.Parallel()
+
+	other, err := script.NewFromASM("OP_FALSE OP_RETURN 68656c6c6f")
+	require.NoError(t, err)
+	p2pkh, err := script.NewFromHex("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+	require.NoError(t, err)
+
+	for _, s := range []*script.Script{other, p2pkh, nil} {
+		_, err := Decode(s)
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	t.Parallel()
+
+	s, err := dataScript([]byte(BPrefix), []byte("data"))
+	require.NoError(t, err)
+	_, err = Decode(s)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	change, err := script.NewFromHex("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+	require.NoError(t, err)
+	data, err := BScript(&File{Data: []byte("x"), MimeType: "text/plain"})
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1000, LockingScript: change})
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: data})
+
+	p, err := Find(tx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Output)
+	assert.Equal(t, []byte("x"), p.Data)
+
+	_, err = Find(transaction.NewTransaction())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestChunk(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("abcdefghij")
+
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{"fits", 10, 1},
+		{"exact split", 5, 2},
+		{"remainder", 3, 4},
+		{"no limit", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			chunks := Chunk(data, tt.size)
+			assert.Len(t, chunks, tt.want)
+			assert.Equal(t, data, bytes.Join(chunks, nil))
+		})
+	}
+}
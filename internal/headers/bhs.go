@@ -0,0 +1,180 @@
+package headers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// stateLongestChain is the Block Headers Service state for best-chain headers.
+const stateLongestChain = "LONGEST_CHAIN"
+
+// BHSSource syncs headers from a Block Headers Service, which serves ranges
+// of headers per request and is suitable for a full sync from genesis.
+type BHSSource struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// bhsHeader is a header as returned by the Block Headers Service.
+type bhsHeader struct {
+	Hash          string `json:"hash"`
+	Version       int32  `json:"version"`
+	PrevBlockHash string `json:"prevBlockHash"`
+	MerkleRoot    string `json:"merkleRoot"`
+	Timestamp     uint32 `json:"creationTimestamp"`
+	Bits          uint32 `json:"difficultyTarget"`
+	Nonce         uint32 `json:"nonce"`
+}
+
+// bhsState is a header state as returned by the Block Headers Service.
+type bhsState struct {
+	State  string `json:"state"`
+	Height uint32 `json:"height"`
+}
+
+// NewBHSSource creates a Block Headers Service header source.
+func NewBHSSource(baseURL, apiKey string) *BHSSource {
+	return &BHSSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// HeaderAt returns the best-chain header at height.
+func (b *BHSSource) HeaderAt(ctx context.Context, height uint32) (*block.Header, error) {
+	candidates, err := b.byHeight(ctx, height, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Several headers can exist at one height when forks are known
+	for _, candidate := range candidates {
+		state, err := b.state(ctx, candidate.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if state.State == stateLongestChain {
+			return candidate.toHeader()
+		}
+	}
+
+	return nil, fmt.Errorf("no best-chain header at height %d", height)
+}
+
+// Next returns up to limit headers following prev.
+func (b *BHSSource) Next(ctx context.Context, prev chainhash.Hash, prevHeight uint32, limit int) ([]*block.Header, error) {
+	state, err := b.state(ctx, prev.String())
+	if err != nil {
+		return nil, err
+	}
+	if state.State != stateLongestChain {
+		return nil, ErrNotOnChain
+	}
+
+	candidates, err := b.byHeight(ctx, prevHeight+1, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Follow links from prev, skipping headers from known forks
+	var result []*block.Header
+	working := prev
+	for _, candidate := range candidates {
+		header, err := candidate.toHeader()
+		if err != nil {
+			return nil, err
+		}
+		if !header.PrevHash.IsEqual(&working) {
+			continue
+		}
+		result = append(result, header)
+		working = header.Hash()
+	}
+
+	return result, nil
+}
+
+// byHeight fetches up to count headers starting at height.
+func (b *BHSSource) byHeight(ctx context.Context, height uint32, count int) ([]bhsHeader, error) {
+	var result []bhsHeader
+	url := fmt.Sprintf("%s/api/v1/chain/header/byHeight?height=%d&count=%d", b.baseURL, height, count)
+	if err := b.get(ctx, url, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// state fetches the chain state of a header.
+func (b *BHSSource) state(ctx context.Context, hash string) (*bhsState, error) {
+	var result bhsState
+	if err := b.get(ctx, b.baseURL+"/api/v1/chain/header/state/"+hash, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// get performs an authenticated GET and decodes the JSON response into out.
+func (b *BHSSource) get(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if b.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with HTTP status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// toHeader converts a service header into a block header, checking its hash.
+func (h *bhsHeader) toHeader() (*block.Header, error) {
+	header := &block.Header{
+		Version:   h.Version,
+		Timestamp: h.Timestamp,
+		Bits:      h.Bits,
+		Nonce:     h.Nonce,
+	}
+
+	prev, err := chainhash.NewHashFromHex(h.PrevBlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous block hash: %w", err)
+	}
+	header.PrevHash = *prev
+
+	root, err := chainhash.NewHashFromHex(h.MerkleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merkle root: %w", err)
+	}
+	header.MerkleRoot = *root
+
+	if hash := header.Hash(); hash.String() != h.Hash {
+		return nil, fmt.Errorf("header fields hash to %s, not the reported %s", hash.String(), h.Hash)
+	}
+
+	return header, nil
+}
@@ -0,0 +1,78 @@
+package headers
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// PowLimit is the easiest target a header may claim (compact bits 0x1d00ffff),
+// shared by mainnet and testnet.
+var PowLimit = new(big.Int).Lsh(big.NewInt(0xffff), 208)
+
+// BitsToTarget expands a compact difficulty representation into the full target.
+func BitsToTarget(bits uint32) (*big.Int, error) {
+	if bits&0x00800000 != 0 {
+		return nil, fmt.Errorf("negative target in bits %08x", bits)
+	}
+
+	mantissa := big.NewInt(int64(bits & 0x007fffff))
+	exponent := uint(bits >> 24)
+	if exponent <= 3 {
+		return mantissa.Rsh(mantissa, 8*(3-exponent)), nil
+	}
+	return mantissa.Lsh(mantissa, 8*(exponent-3)), nil
+}
+
+// HashToBig interprets a block hash as a big-endian integer for target comparison.
+func HashToBig(hash chainhash.Hash) *big.Int {
+	reversed := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		reversed[i] = hash[chainhash.HashSize-1-i]
+	}
+	return new(big.Int).SetBytes(reversed)
+}
+
+// headerTarget returns the header's target, rejecting zero targets and targets
+// easier than PowLimit.
+func headerTarget(header *block.Header) (*big.Int, error) {
+	target, err := BitsToTarget(header.Bits)
+	if err != nil {
+		return nil, err
+	}
+	if target.Sign() <= 0 {
+		return nil, fmt.Errorf("zero target in bits %08x", header.Bits)
+	}
+	if target.Cmp(PowLimit) > 0 {
+		return nil, fmt.Errorf("bits %08x are easier than the proof-of-work limit", header.Bits)
+	}
+	return target, nil
+}
+
+// Work returns the expected number of hashes needed to produce the header,
+// computed as 2^256 / (target + 1).
+func Work(header *block.Header) (*big.Int, error) {
+	target, err := headerTarget(header)
+	if err != nil {
+		return nil, err
+	}
+	denominator := new(big.Int).Add(target, big.NewInt(1))
+	return new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 256), denominator), nil
+}
+
+// CheckProofOfWork verifies that the header's difficulty is within the
+// proof-of-work limit and that its hash meets its own target.
+func CheckProofOfWork(header *block.Header) error {
+	target, err := headerTarget(header)
+	if err != nil {
+		return err
+	}
+
+	hash := header.Hash()
+	if HashToBig(hash).Cmp(target) > 0 {
+		return fmt.Errorf("hash %s does not meet target for bits %08x", hash.String(), header.Bits)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package headers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitsToTarget(t *testing.T) {
+	t.Parallel()
+
+	target, err := BitsToTarget(0x1d00ffff)
+	require.NoError(t, err)
+	expected, _ := new(big.Int).SetString("00000000ffff0000000000000000000000000000000000000000000000000000", 16)
+	assert.Equal(t, 0, expected.Cmp(target))
+	assert.Equal(t, 0, PowLimit.Cmp(target))
+
+	target, err = BitsToTarget(0x03123456)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0x123456), target.Int64())
+
+	target, err = BitsToTarget(0x02123456)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0x1234), target.Int64())
+
+	_, err = BitsToTarget(0x04923456)
+	require.Error(t, err)
+}
+
+func TestCheckProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	genesis := testHeaders(t)[0]
+	require.NoError(t, CheckProofOfWork(genesis))
+
+	work, err := Work(genesis)
+	require.NoError(t, err)
+	assert.Equal(t, "100010001", work.Text(16))
+
+	t.Run("hash above target", func(t *testing.T) {
+		t.Parallel()
+		bad := *genesis
+		bad.Nonce++
+		err := CheckProofOfWork(&bad)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not meet target")
+	})
+
+	t.Run("target easier than limit", func(t *testing.T) {
+		t.Parallel()
+		easy := *genesis
+		easy.Bits = 0x1e00ffff
+		err := CheckProofOfWork(&easy)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "proof-of-work limit")
+	})
+
+	t.Run("zero target", func(t *testing.T) {
+		t.Parallel()
+		zero := *genesis
+		zero.Bits = 0
+		require.Error(t, CheckProofOfWork(&zero))
+	})
+}
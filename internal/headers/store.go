@@ -0,0 +1,334 @@
+// Package headers provides a local, verified block header store for BSV CLI tools.
+//
+// Headers are kept in a single append-only file and every header is checked
+// before it is stored, so the store can serve as a trust anchor for SPV.
+//
+// The package supports:
+//   - Appending headers with chain continuity and proof-of-work checks
+//   - Starting from genesis or from a trusted checkpoint height
+//   - Querying headers by height or by hash
+//   - Rewinding the tip to follow chain reorganizations
+//   - Syncing from WhatsOnChain or a Block Headers Service
+package headers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// Genesis block hashes used to anchor stores that start at height 0.
+const (
+	MainnetGenesis = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	TestnetGenesis = "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943"
+)
+
+// Store file layout: magic (8) + base height (4) + reserved (4), then 80-byte headers.
+const (
+	fileHeaderSize = 16
+	fileMagic      = "BSVHDRS\x01"
+)
+
+// ErrEmpty is returned when querying a store that holds no headers.
+var ErrEmpty = errors.New("header store is empty")
+
+// Store is a file-backed chain of verified block headers.
+// Headers are stored contiguously from Base() to TipHeight().
+type Store struct {
+	path    string                    // Backing file path
+	genesis chainhash.Hash            // Expected genesis hash for stores based at height 0
+	base    uint32                    // Height of the first stored header
+	data    []byte                    // Concatenated raw headers
+	index   map[chainhash.Hash]uint32 // Hash to height, built on first lookup
+}
+
+// DefaultPath returns the default header store location for a network:
+// ~/.bsv-cmd-line-utils/headers/<network>.bin
+func DefaultPath(testnet bool) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+
+	network := "mainnet"
+	if testnet {
+		network = "testnet"
+	}
+	return filepath.Join(home, ".bsv-cmd-line-utils", "headers", network+".bin"), nil
+}
+
+// Open loads the header store at path. A missing file yields an empty store
+// that is created on the first Init.
+func Open(path string, testnet bool) (*Store, error) {
+	genesisHex := MainnetGenesis
+	if testnet {
+		genesisHex = TestnetGenesis
+	}
+	genesis, err := chainhash.NewHashFromHex(genesisHex)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, genesis: *genesis}
+
+	raw, err := os.ReadFile(path) //nolint:gosec // path is chosen by the user
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header store: %w", err)
+	}
+
+	if len(raw) < fileHeaderSize || string(raw[:8]) != fileMagic {
+		return nil, fmt.Errorf("%s is not a header store", path)
+	}
+	s.base = binary.LittleEndian.Uint32(raw[8:12])
+
+	// A partial trailing header means an interrupted write; drop it
+	body := raw[fileHeaderSize:]
+	s.data = body[:len(body)-len(body)%block.HeaderSize]
+
+	return s, nil
+}
+
+// Path returns the backing file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Len returns the number of stored headers.
+func (s *Store) Len() int {
+	return len(s.data) / block.HeaderSize
+}
+
+// Base returns the height of the first stored header.
+func (s *Store) Base() uint32 {
+	return s.base
+}
+
+// TipHeight returns the height of the last stored header.
+func (s *Store) TipHeight() (uint32, error) {
+	if s.Len() == 0 {
+		return 0, ErrEmpty
+	}
+	return s.base + uint32(s.Len()-1), nil //nolint:gosec // header count fits in uint32
+}
+
+// Tip returns the last stored header and its height.
+func (s *Store) Tip() (*block.Header, uint32, error) {
+	height, err := s.TipHeight()
+	if err != nil {
+		return nil, 0, err
+	}
+	header, err := s.Header(height)
+	return header, height, err
+}
+
+// Has reports whether a header at height is stored.
+func (s *Store) Has(height uint32) bool {
+	return height >= s.base && int(height-s.base) < s.Len()
+}
+
+// Raw returns the 80 raw bytes of the header at height.
+func (s *Store) Raw(height uint32) ([]byte, error) {
+	if !s.Has(height) {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	offset := int(height-s.base) * block.HeaderSize
+	return s.data[offset : offset+block.HeaderSize], nil
+}
+
+// Header returns the parsed header at height.
+func (s *Store) Header(height uint32) (*block.Header, error) {
+	raw, err := s.Raw(height)
+	if err != nil {
+		return nil, err
+	}
+	return block.NewHeaderFromBytes(raw)
+}
+
+// HeightOf returns the height of the header with the given hash.
+func (s *Store) HeightOf(hash chainhash.Hash) (uint32, bool) {
+	if s.index == nil {
+		s.index = make(map[chainhash.Hash]uint32, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			raw := s.data[i*block.HeaderSize : (i+1)*block.HeaderSize]
+			s.index[chainhash.DoubleHashH(raw)] = s.base + uint32(i) //nolint:gosec // header count fits in uint32
+		}
+	}
+	height, ok := s.index[hash]
+	return height, ok
+}
+
+// Init starts an empty store at the given header and height. A store based at
+// height 0 must start with the network's genesis block; any other base header
+// is trusted as a checkpoint.
+func (s *Store) Init(header *block.Header, height uint32) error {
+	if s.Len() > 0 {
+		return fmt.Errorf("header store is not empty")
+	}
+
+	hash := header.Hash()
+	if height == 0 && !hash.IsEqual(&s.genesis) {
+		return fmt.Errorf("header %s is not the genesis block", hash.String())
+	}
+	if err := CheckProofOfWork(header); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("creating header store directory: %w", err)
+	}
+
+	fileHeader := make([]byte, fileHeaderSize)
+	copy(fileHeader, fileMagic)
+	binary.LittleEndian.PutUint32(fileHeader[8:12], height)
+	if err := os.WriteFile(s.path, append(fileHeader, header.Bytes()...), 0o600); err != nil {
+		return fmt.Errorf("writing header store: %w", err)
+	}
+
+	s.base = height
+	s.data = header.Bytes()
+	s.index = nil
+	return nil
+}
+
+// Append verifies and stores headers that extend the current tip.
+// Each header must link to its predecessor and carry valid proof of work;
+// nothing is written if any header fails.
+func (s *Store) Append(headers []*block.Header) error {
+	tip, tipHeight, err := s.Tip()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	prevHash := tip.Hash()
+	for i, header := range headers {
+		height := tipHeight + uint32(i) + 1 //nolint:gosec // batch sizes are small
+		if !header.PrevHash.IsEqual(&prevHash) {
+			return fmt.Errorf("header at height %d does not link to %s", height, prevHash.String())
+		}
+		if err := CheckProofOfWork(header); err != nil {
+			return fmt.Errorf("header at height %d: %w", height, err)
+		}
+		buf.Write(header.Bytes())
+		prevHash = header.Hash()
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path is chosen by the user
+	if err != nil {
+		return fmt.Errorf("opening header store: %w", err)
+	}
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing header store: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("writing header store: %w", err)
+	}
+
+	if s.index != nil {
+		for i := range headers {
+			s.index[headers[i].Hash()] = tipHeight + uint32(i) + 1 //nolint:gosec // batch sizes are small
+		}
+	}
+	s.data = append(s.data, buf.Bytes()...)
+	return nil
+}
+
+// Rewind removes every header above height, so that a competing chain can be
+// appended after a reorganization. The base header cannot be removed.
+func (s *Store) Rewind(height uint32) error {
+	tipHeight, err := s.TipHeight()
+	if err != nil {
+		return err
+	}
+	if height < s.base {
+		return fmt.Errorf("cannot rewind below base height %d", s.base)
+	}
+	if height >= tipHeight {
+		return nil
+	}
+
+	keep := int(height-s.base+1) * block.HeaderSize
+	if err = os.Truncate(s.path, int64(fileHeaderSize+keep)); err != nil {
+		return fmt.Errorf("truncating header store: %w", err)
+	}
+
+	s.data = s.data[:keep]
+	s.index = nil
+	return nil
+}
+
+// Verify rechecks chain continuity and proof of work for every stored header
+// from height from to the tip. progress, if non-nil, is called periodically
+// with the height being checked.
+func (s *Store) Verify(from uint32, progress func(height uint32)) error {
+	tipHeight, err := s.TipHeight()
+	if err != nil {
+		return err
+	}
+	if from < s.base {
+		from = s.base
+	}
+
+	var prevHash chainhash.Hash
+	if from > s.base {
+		prev, err := s.Header(from - 1)
+		if err != nil {
+			return err
+		}
+		prevHash = prev.Hash()
+	}
+
+	for height := from; height <= tipHeight; height++ {
+		header, err := s.Header(height)
+		if err != nil {
+			return err
+		}
+
+		hash := header.Hash()
+		switch {
+		case height == 0 && !hash.IsEqual(&s.genesis):
+			return fmt.Errorf("height 0: header %s is not the genesis block", hash.String())
+		case height > s.base && !header.PrevHash.IsEqual(&prevHash):
+			return fmt.Errorf("height %d: header does not link to %s", height, prevHash.String())
+		}
+		if err = CheckProofOfWork(header); err != nil {
+			return fmt.Errorf("height %d: %w", height, err)
+		}
+
+		if progress != nil && height%10000 == 0 {
+			progress(height)
+		}
+		prevHash = hash
+	}
+
+	return nil
+}
+
+// ChainWork returns the total work of the stored headers from height from to
+// height to, inclusive.
+func (s *Store) ChainWork(from, to uint32) (*big.Int, error) {
+	total := new(big.Int)
+	for height := from; height <= to; height++ {
+		header, err := s.Header(height)
+		if err != nil {
+			return nil, err
+		}
+		work, err := Work(header)
+		if err != nil {
+			return nil, fmt.Errorf("height %d: %w", height, err)
+		}
+		total.Add(total, work)
+	}
+	return total, nil
+}
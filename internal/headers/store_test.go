@@ -0,0 +1,243 @@
+package headers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlockInfos are the first three mainnet headers as WhatsOnChain reports them.
+func testBlockInfos() []*whatsonchain.BlockInfo {
+	return []*whatsonchain.BlockInfo{
+		{
+			Hash:          MainnetGenesis,
+			Version:       1,
+			MerkleRoot:    "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+			Time:          1231006505,
+			Bits:          "1d00ffff",
+			Nonce:         2083236893,
+			Confirmations: 3,
+			NextBlockHash: "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+		},
+		{
+			Hash:              "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+			Height:            1,
+			Version:           1,
+			PreviousBlockHash: MainnetGenesis,
+			MerkleRoot:        "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098",
+			Time:              1231469665,
+			Bits:              "1d00ffff",
+			Nonce:             2573394689,
+			Confirmations:     2,
+			NextBlockHash:     "000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd",
+		},
+		{
+			Hash:              "000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd",
+			Height:            2,
+			Version:           1,
+			PreviousBlockHash: "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+			MerkleRoot:        "9b0fc92260312ce44e74ef369f5c66bbb85848f2eddd5a7a1cde251e54ccfdd5",
+			Time:              1231469744,
+			Bits:              "1d00ffff",
+			Nonce:             1639830024,
+			Confirmations:     1,
+		},
+	}
+}
+
+// testHeaders returns the first three mainnet headers.
+func testHeaders(t *testing.T) []*block.Header {
+	t.Helper()
+
+	var result []*block.Header
+	for _, info := range testBlockInfos() {
+		header, err := HeaderFromBlockInfo(info)
+		require.NoError(t, err)
+		result = append(result, header)
+	}
+	return result
+}
+
+// newTestStore opens an empty store in a temporary directory.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(filepath.Join(t.TempDir(), "headers", "mainnet.bin"), false)
+	require.NoError(t, err)
+	return store
+}
+
+func TestStoreInitAndAppend(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+	store := newTestStore(t)
+
+	_, err := store.TipHeight()
+	require.ErrorIs(t, err, ErrEmpty)
+
+	require.NoError(t, store.Init(hdrs[0], 0))
+	require.NoError(t, store.Append(hdrs[1:]))
+
+	assert.Equal(t, 3, store.Len())
+	tip, height, err := store.Tip()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), height)
+	assert.Equal(t, hdrs[2].Hash(), tip.Hash())
+
+	got, ok := store.HeightOf(hdrs[1].Hash())
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), got)
+
+	_, ok = store.HeightOf(chainhash.Hash{})
+	assert.False(t, ok)
+
+	require.NoError(t, store.Verify(0, nil))
+	require.NoError(t, store.Verify(2, nil))
+
+	work, err := store.ChainWork(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "300030003", work.Text(16))
+}
+
+func TestStorePersistence(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+	store := newTestStore(t)
+	require.NoError(t, store.Init(hdrs[0], 0))
+	require.NoError(t, store.Append(hdrs[1:]))
+
+	reopened, err := Open(store.Path(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Len())
+	assert.Equal(t, uint32(0), reopened.Base())
+
+	raw, err := reopened.Raw(1)
+	require.NoError(t, err)
+	assert.Equal(t, hdrs[1].Bytes(), raw)
+
+	t.Run("partial trailing header is ignored", func(t *testing.T) {
+		f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.Write([]byte{1, 2, 3})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reopened, err := Open(store.Path(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, reopened.Len())
+	})
+}
+
+func TestStoreRejectsInvalidHeaders(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+
+	t.Run("non-genesis at height 0", func(t *testing.T) {
+		t.Parallel()
+		err := newTestStore(t).Init(hdrs[1], 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not the genesis block")
+	})
+
+	t.Run("testnet store rejects mainnet genesis", func(t *testing.T) {
+		t.Parallel()
+		store, err := Open(filepath.Join(t.TempDir(), "testnet.bin"), true)
+		require.NoError(t, err)
+		require.Error(t, store.Init(hdrs[0], 0))
+	})
+
+	t.Run("broken link", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+		require.NoError(t, store.Init(hdrs[0], 0))
+
+		err := store.Append([]*block.Header{hdrs[2]})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not link")
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("bad proof of work", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+		require.NoError(t, store.Init(hdrs[0], 0))
+
+		bad := *hdrs[1]
+		bad.Nonce++
+		err := store.Append([]*block.Header{&bad})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not meet target")
+	})
+
+	t.Run("not a store file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "junk.bin")
+		require.NoError(t, os.WriteFile(path, []byte("not a header store"), 0o600))
+		_, err := Open(path, false)
+		require.Error(t, err)
+	})
+}
+
+func TestStoreCheckpointBase(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+	store := newTestStore(t)
+	require.NoError(t, store.Init(hdrs[1], 1))
+	require.NoError(t, store.Append(hdrs[2:]))
+
+	assert.Equal(t, uint32(1), store.Base())
+	assert.False(t, store.Has(0))
+	assert.True(t, store.Has(2))
+
+	_, err := store.Header(0)
+	require.Error(t, err)
+
+	require.NoError(t, store.Verify(0, nil))
+}
+
+func TestStoreRewind(t *testing.T) {
+	t.Parallel()
+
+	hdrs := testHeaders(t)
+	store := newTestStore(t)
+	require.NoError(t, store.Init(hdrs[0], 0))
+	require.NoError(t, store.Append(hdrs[1:]))
+
+	// Build the index so Rewind must invalidate it
+	_, ok := store.HeightOf(hdrs[2].Hash())
+	require.True(t, ok)
+
+	require.NoError(t, store.Rewind(0))
+	assert.Equal(t, 1, store.Len())
+	_, ok = store.HeightOf(hdrs[2].Hash())
+	assert.False(t, ok)
+
+	reopened, err := Open(store.Path(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reopened.Len())
+
+	require.NoError(t, store.Append(hdrs[1:]))
+	assert.Equal(t, 3, store.Len())
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Parallel()
+
+	mainPath, err := DefaultPath(false)
+	require.NoError(t, err)
+	testPath, err := DefaultPath(true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mainnet.bin", filepath.Base(mainPath))
+	assert.Equal(t, "testnet.bin", filepath.Base(testPath))
+}
@@ -0,0 +1,96 @@
+package headers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// ErrNotOnChain is returned by a Source when the requested block is not on
+// its best chain, which means the local tip was reorganized away.
+var ErrNotOnChain = errors.New("block is not on the best chain")
+
+// MaxReorgDepth is the deepest reorganization Sync will follow before giving up.
+const MaxReorgDepth = 100
+
+// Source provides block headers for syncing.
+type Source interface {
+	// HeaderAt returns the best-chain header at height.
+	HeaderAt(ctx context.Context, height uint32) (*block.Header, error)
+
+	// Next returns up to limit best-chain headers following the block prev at
+	// height prevHeight, in order. An empty result means prev is the tip.
+	// It returns ErrNotOnChain if prev is not on the best chain.
+	Next(ctx context.Context, prev chainhash.Hash, prevHeight uint32, limit int) ([]*block.Header, error)
+}
+
+// SyncOptions controls a Sync run.
+type SyncOptions struct {
+	From     uint32              // Checkpoint height used when the store is empty
+	Batch    int                 // Headers requested per source call
+	Progress func(height uint32) // Called with the new tip height after each batch
+}
+
+// Sync extends the store to the source's tip. An empty store is first
+// initialized at opts.From. When the local tip has been reorganized away, the
+// store is rewound one block at a time (up to MaxReorgDepth) until it rejoins
+// the source's best chain. It returns the number of headers added.
+func Sync(ctx context.Context, store *Store, src Source, opts SyncOptions) (int, error) {
+	if opts.Batch <= 0 {
+		opts.Batch = 1000
+	}
+
+	if store.Len() == 0 {
+		header, err := src.HeaderAt(ctx, opts.From)
+		if err != nil {
+			return 0, fmt.Errorf("fetching checkpoint header at %d: %w", opts.From, err)
+		}
+		if err = store.Init(header, opts.From); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	rewound := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return added, err
+		}
+
+		tip, tipHeight, err := store.Tip()
+		if err != nil {
+			return added, err
+		}
+
+		next, err := src.Next(ctx, tip.Hash(), tipHeight, opts.Batch)
+		if errors.Is(err, ErrNotOnChain) {
+			if rewound >= MaxReorgDepth || tipHeight == store.Base() {
+				return added, fmt.Errorf("local chain diverges below height %d: %w", tipHeight, err)
+			}
+			if err = store.Rewind(tipHeight - 1); err != nil {
+				return added, err
+			}
+			rewound++
+			continue
+		}
+		if err != nil {
+			return added, fmt.Errorf("fetching headers after %d: %w", tipHeight, err)
+		}
+
+		if len(next) == 0 {
+			return added, nil
+		}
+
+		if err = store.Append(next); err != nil {
+			return added, err
+		}
+		added += len(next)
+
+		if opts.Progress != nil {
+			opts.Progress(tipHeight + uint32(len(next))) //nolint:gosec // batch sizes are small
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package headers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/mrz1836/go-whatsonchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainSource serves a fixed best chain; hashes in orphaned are reported as off-chain.
+type chainSource struct {
+	chain    []*block.Header
+	orphaned map[chainhash.Hash]bool
+}
+
+func (c *chainSource) HeaderAt(_ context.Context, height uint32) (*block.Header, error) {
+	if int(height) >= len(c.chain) {
+		return nil, fmt.Errorf("no header at %d", height)
+	}
+	return c.chain[height], nil
+}
+
+func (c *chainSource) Next(_ context.Context, prev chainhash.Hash, prevHeight uint32, limit int) ([]*block.Header, error) {
+	if c.orphaned[prev] {
+		return nil, ErrNotOnChain
+	}
+	start := int(prevHeight) + 1
+	end := min(start+limit, len(c.chain))
+	if start >= end {
+		return nil, nil
+	}
+	return c.chain[start:end], nil
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	hdrs := testHeaders(t)
+
+	t.Run("from genesis in batches", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+		src := &chainSource{chain: hdrs}
+
+		var progress []uint32
+		added, err := Sync(ctx, store, src, SyncOptions{Batch: 1, Progress: func(h uint32) { progress = append(progress, h) }})
+		require.NoError(t, err)
+		assert.Equal(t, 2, added)
+		assert.Equal(t, 3, store.Len())
+		assert.Equal(t, []uint32{1, 2}, progress)
+
+		// Already synced: no headers added
+		added, err = Sync(ctx, store, src, SyncOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, added)
+	})
+
+	t.Run("from checkpoint", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+
+		added, err := Sync(ctx, store, &chainSource{chain: hdrs}, SyncOptions{From: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 1, added)
+		assert.Equal(t, uint32(1), store.Base())
+	})
+
+	t.Run("rewinds orphaned tip", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+		require.NoError(t, store.Init(hdrs[0], 0))
+		require.NoError(t, store.Append(hdrs[1:]))
+
+		// The source's best chain ends at block 1 and no longer contains block 2
+		src := &chainSource{chain: hdrs[:2], orphaned: map[chainhash.Hash]bool{hdrs[2].Hash(): true}}
+		added, err := Sync(ctx, store, src, SyncOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, added)
+
+		tipHeight, err := store.TipHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), tipHeight)
+	})
+
+	t.Run("diverges at base", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+		require.NoError(t, store.Init(hdrs[0], 0))
+
+		src := &chainSource{chain: hdrs, orphaned: map[chainhash.Hash]bool{hdrs[0].Hash(): true}}
+		_, err := Sync(ctx, store, src, SyncOptions{})
+		require.ErrorIs(t, err, ErrNotOnChain)
+	})
+}
+
+// wocClient serves block info from the fixture chain; other methods are unimplemented.
+type wocClient struct {
+	whatsonchain.ClientInterface
+
+	infos []*whatsonchain.BlockInfo
+}
+
+func (w *wocClient) GetHeaderByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	for _, info := range w.infos {
+		if info.Hash == hash {
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("header %s not found", hash)
+}
+
+func (w *wocClient) GetBlockByHeight(_ context.Context, height int64) (*whatsonchain.BlockInfo, error) {
+	if int(height) >= len(w.infos) {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	return w.infos[height], nil
+}
+
+func TestWOCSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	hdrs := testHeaders(t)
+
+	t.Run("syncs by following next links", func(t *testing.T) {
+		t.Parallel()
+		store := newTestStore(t)
+
+		added, err := Sync(ctx, store, NewWOCSource(&wocClient{infos: testBlockInfos()}), SyncOptions{Batch: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, added)
+	})
+
+	t.Run("orphaned block", func(t *testing.T) {
+		t.Parallel()
+		infos := testBlockInfos()
+		infos[2].Confirmations = -1
+
+		_, err := NewWOCSource(&wocClient{infos: infos}).Next(ctx, hdrs[2].Hash(), 2, 10)
+		require.ErrorIs(t, err, ErrNotOnChain)
+	})
+
+	t.Run("tampered header fields", func(t *testing.T) {
+		t.Parallel()
+		infos := testBlockInfos()
+		infos[1].Nonce++
+
+		_, err := NewWOCSource(&wocClient{infos: infos}).Next(ctx, hdrs[0].Hash(), 0, 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not the reported")
+	})
+}
+
+// newBHSServer serves the fixture chain plus a fork header at height 1.
+func newBHSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	hdrs := testHeaders(t)
+	toJSON := func(h *block.Header) bhsHeader {
+		return bhsHeader{
+			Hash:          h.Hash().String(),
+			Version:       h.Version,
+			PrevBlockHash: h.PrevHash.String(),
+			MerkleRoot:    h.MerkleRoot.String(),
+			Timestamp:     h.Timestamp,
+			Bits:          h.Bits,
+			Nonce:         h.Nonce,
+		}
+	}
+
+	// A fork header that does not link to genesis, so it must be skipped
+	fork := toJSON(hdrs[2])
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v1/chain/header/state/"):
+			_ = json.NewEncoder(w).Encode(bhsState{State: stateLongestChain})
+
+		case r.URL.Path == "/api/v1/chain/header/byHeight":
+			var height, count int
+			_, _ = fmt.Sscan(r.URL.Query().Get("height"), &height)
+			_, _ = fmt.Sscan(r.URL.Query().Get("count"), &count)
+
+			var result []bhsHeader
+			for i := height; i < len(hdrs) && i < height+count; i++ {
+				if i == 1 {
+					result = append(result, fork)
+				}
+				result = append(result, toJSON(hdrs[i]))
+			}
+			_ = json.NewEncoder(w).Encode(result)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBHSSource(t *testing.T) {
+	t.Parallel()
+
+	server := newBHSServer(t)
+	defer server.Close()
+
+	store := newTestStore(t)
+	added, err := Sync(context.Background(), store, NewBHSSource(server.URL+"/", "test-key"), SyncOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	require.NoError(t, store.Verify(0, nil))
+}
@@ -0,0 +1,100 @@
+package headers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/mrz1836/go-whatsonchain"
+)
+
+// WOCSource syncs headers from WhatsOnChain by following next-block links.
+// It makes one request per header, so it suits incremental syncs and
+// checkpoint starts rather than a full sync from genesis.
+type WOCSource struct {
+	Client whatsonchain.ClientInterface
+}
+
+// NewWOCSource creates a WhatsOnChain header source.
+func NewWOCSource(client whatsonchain.ClientInterface) *WOCSource {
+	return &WOCSource{Client: client}
+}
+
+// HeaderAt returns the best-chain header at height.
+func (w *WOCSource) HeaderAt(ctx context.Context, height uint32) (*block.Header, error) {
+	info, err := w.Client.GetBlockByHeight(ctx, int64(height))
+	if err != nil {
+		return nil, err
+	}
+	return HeaderFromBlockInfo(info)
+}
+
+// Next returns up to limit headers following prev.
+func (w *WOCSource) Next(ctx context.Context, prev chainhash.Hash, _ uint32, limit int) ([]*block.Header, error) {
+	info, err := w.Client.GetHeaderByHash(ctx, prev.String())
+	if err != nil {
+		return nil, err
+	}
+	if info.Confirmations <= 0 {
+		return nil, ErrNotOnChain
+	}
+
+	var result []*block.Header
+	next := info.NextBlockHash
+	for len(result) < limit && next != "" {
+		if info, err = w.Client.GetHeaderByHash(ctx, next); err != nil {
+			return nil, err
+		}
+		header, err := HeaderFromBlockInfo(info)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, header)
+		next = info.NextBlockHash
+	}
+
+	return result, nil
+}
+
+// HeaderFromBlockInfo rebuilds an 80-byte header from WhatsOnChain block fields
+// and checks that it hashes to the reported block hash.
+func HeaderFromBlockInfo(info *whatsonchain.BlockInfo) (*block.Header, error) {
+	if info == nil {
+		return nil, fmt.Errorf("empty header response")
+	}
+
+	bits, err := strconv.ParseUint(info.Bits, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bits %q: %w", info.Bits, err)
+	}
+
+	header := &block.Header{
+		Version:   int32(info.Version), //nolint:gosec // header version is a 32-bit field
+		Timestamp: uint32(info.Time),   //nolint:gosec // header time is a 32-bit field
+		Bits:      uint32(bits),
+		Nonce:     uint32(info.Nonce), //nolint:gosec // header nonce is a 32-bit field
+	}
+
+	// The genesis block has no previous block hash
+	if info.PreviousBlockHash != "" {
+		prev, err := chainhash.NewHashFromHex(info.PreviousBlockHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous block hash: %w", err)
+		}
+		header.PrevHash = *prev
+	}
+
+	root, err := chainhash.NewHashFromHex(info.MerkleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merkle root: %w", err)
+	}
+	header.MerkleRoot = *root
+
+	if hash := header.Hash(); hash.String() != info.Hash {
+		return nil, fmt.Errorf("header fields hash to %s, not the reported %s", hash.String(), info.Hash)
+	}
+
+	return header, nil
+}
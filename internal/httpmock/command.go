@@ -0,0 +1,75 @@
+package httpmock
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Execute runs cmd with args as its command line and returns what it wrote to
+// stdout. Its own flags are reset to their defaults first, so a test can run the
+// same command several times, stdin is empty, and cobra does not print
+// the error or usage.
+func Execute(t testing.TB, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	return ExecuteWithInput(t, cmd, "", args...)
+}
+
+// ExecuteWithInput is Execute with input as the command's stdin.
+func ExecuteWithInput(t testing.TB, cmd *cobra.Command, input string, args ...string) (string, error) {
+	t.Helper()
+
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	cmd.SetArgs(args)
+
+	// The error is returned, so cobra need not print it and the usage too
+	silenceErrors, silenceUsage := cmd.SilenceErrors, cmd.SilenceUsage
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	defer func() { cmd.SilenceErrors, cmd.SilenceUsage = silenceErrors, silenceUsage }()
+
+	stdinPath := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(stdinPath, []byte(input), 0o600); err != nil {
+		t.Fatalf("httpmock: writing stdin: %v", err)
+	}
+	stdin, err := os.Open(stdinPath) //nolint:gosec // test temp file
+	if err != nil {
+		t.Fatalf("httpmock: opening stdin: %v", err)
+	}
+	defer stdin.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("httpmock: creating stdout pipe: %v", err)
+	}
+
+	// Drain the pipe while the command runs, so large output cannot block it
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, r)
+		close(done)
+	}()
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, w
+	err = cmd.Execute()
+	os.Stdin, os.Stdout = oldStdin, oldStdout
+
+	w.Close()
+	<-done
+	r.Close()
+	return out.String(), err
+}
@@ -0,0 +1,235 @@
+// Package httpmock records and replays HTTP traffic, so commands that call
+// WhatsOnChain, ARC, and the other external APIs can be tested end to end
+// without network access.
+//
+// A cassette is a JSON file of recorded requests and responses kept in a
+// package's testdata directory. Install puts a cassette in place of
+// http.DefaultTransport, which every API client in this repository sends
+// through, for the length of a test. Setting HTTPMOCK_RECORD sends the
+// requests to the real APIs instead and rewrites the cassette with their
+// responses:
+//
+//	HTTPMOCK_RECORD=1 go test ./cmd/getraw -run TestIntegration
+//
+// The package supports:
+//   - Replaying responses matched by method, URL, and body, in recorded order
+//   - Recording live traffic without request headers, so API keys stay out of cassettes
+//   - Failing a test on a request the cassette does not hold, or on one it holds that was never made
+//   - Running a cobra command with arguments and optional stdin, capturing its stdout
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// RecordEnv is the environment variable that makes Install record.
+const RecordEnv = "HTTPMOCK_RECORD"
+
+// recordedHeaders are the response headers kept in a recording.
+var recordedHeaders = []string{"Content-Type", "ETag"}
+
+// Request is the recorded part of an HTTP request. Headers are left out so
+// API keys never reach a cassette.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// Interaction is one request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is an http.RoundTripper that answers each request with the first
+// unused recorded interaction matching it. With Real set it sends requests
+// on instead, and records them.
+type Cassette struct {
+	Interactions []*Interaction    `json:"interactions"`
+	Real         http.RoundTripper `json:"-"` // Transport to record from; nil to replay
+
+	mu   sync.Mutex
+	used map[int]bool
+}
+
+// Load reads a cassette from path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var c Cassette
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating its directory.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// RoundTrip answers req from the cassette, or records it when Real is set.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.Real != nil {
+		return c.record(req, recorded)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, in := range c.Interactions {
+		if !c.used[i] && in.Request == recorded {
+			if c.used == nil {
+				c.used = make(map[int]bool)
+			}
+			c.used[i] = true
+			return in.Response.build(req), nil
+		}
+	}
+	return nil, fmt.Errorf("httpmock: no recorded response for %s %s", recorded.Method, recorded.URL)
+}
+
+// Unused returns the recorded requests that have not been replayed.
+func (c *Cassette) Unused() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var unused []Request
+	for i, in := range c.Interactions {
+		if !c.used[i] {
+			unused = append(unused, in.Request)
+		}
+	}
+	return unused
+}
+
+// record sends req through Real and appends the exchange to the cassette.
+func (c *Cassette) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := c.Real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpmock: reading response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	out := Response{Status: resp.StatusCode, Body: string(body)}
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			if out.Header == nil {
+				out.Header = make(map[string]string)
+			}
+			out.Header[name] = value
+		}
+	}
+
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, &Interaction{Request: recorded, Response: out})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// recordRequest captures the matched parts of req, leaving its body readable.
+func recordRequest(req *http.Request) (Request, error) {
+	recorded := Request{Method: req.Method, URL: req.URL.String()}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return recorded, fmt.Errorf("httpmock: reading request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	recorded.Body = string(body)
+	return recorded, nil
+}
+
+// build returns r as a response to req.
+func (r Response) build(req *http.Request) *http.Response {
+	header := make(http.Header, len(r.Header))
+	for name, value := range r.Header {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.Status) + " " + http.StatusText(r.Status),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// Install replaces http.DefaultTransport with the cassette testdata/<name>.json
+// until the test ends. The test fails if a recorded request was never made.
+// With HTTPMOCK_RECORD set, requests go to the real APIs and the cassette is
+// rewritten when the test ends. Tests that install a cassette must not run in
+// parallel.
+func Install(t testing.TB, name string) *Cassette {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".json")
+	c := &Cassette{}
+	if os.Getenv(RecordEnv) != "" {
+		c.Real = http.DefaultTransport
+	} else {
+		var err error
+		if c, err = Load(path); err != nil {
+			t.Fatalf("httpmock: %v", err)
+		}
+	}
+
+	previous := http.DefaultTransport
+	http.DefaultTransport = c
+	t.Cleanup(func() {
+		http.DefaultTransport = previous
+		if c.Real != nil {
+			if err := c.Save(path); err != nil {
+				t.Errorf("httpmock: saving cassette: %v", err)
+			}
+			return
+		}
+		for _, r := range c.Unused() {
+			t.Errorf("httpmock: %s: recorded request never made: %s %s", name, r.Method, r.URL)
+		}
+	})
+	return c
+}
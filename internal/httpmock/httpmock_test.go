@@ -0,0 +1,147 @@
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// get sends a GET through rt and returns the status and body.
+func get(t *testing.T, rt http.RoundTripper, url string) (int, string) {
+	t.Helper()
+	resp, err := (&http.Client{Transport: rt}).Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestCassetteReplay(t *testing.T) {
+	t.Parallel()
+
+	c := &Cassette{Interactions: []*Interaction{
+		{Request: Request{Method: http.MethodGet, URL: "https://api.example.com/tx"}, Response: Response{Status: http.StatusOK, Body: "first"}},
+		{Request: Request{Method: http.MethodGet, URL: "https://api.example.com/tx"}, Response: Response{Status: http.StatusNotFound, Body: "second"}},
+		{Request: Request{Method: http.MethodPost, URL: "https://api.example.com/tx", Body: `{"rawTx":"00"}`}, Response: Response{
+			Status: http.StatusOK,
+			Header: map[string]string{"Content-Type": "application/json"},
+			Body:   `{"txid":"ab"}`,
+		}},
+	}}
+
+	t.Run("replays matching requests in order", func(t *testing.T) {
+		status, body := get(t, c, "https://api.example.com/tx")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "first", body)
+
+		status, body = get(t, c, "https://api.example.com/tx")
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, "second", body)
+	})
+
+	t.Run("fails a request with no unused recording", func(t *testing.T) {
+		_, err := (&http.Client{Transport: c}).Get("https://api.example.com/tx")
+		require.ErrorContains(t, err, "httpmock: no recorded response for GET https://api.example.com/tx")
+	})
+
+	t.Run("matches the request body", func(t *testing.T) {
+		require.Len(t, c.Unused(), 1)
+
+		client := &http.Client{Transport: c}
+		_, err := client.Post("https://api.example.com/tx", "application/json", strings.NewReader(`{"rawTx":"01"}`))
+		require.Error(t, err)
+
+		resp, err := client.Post("https://api.example.com/tx", "application/json", strings.NewReader(`{"rawTx":"00"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Empty(t, c.Unused())
+	})
+}
+
+func TestCassetteRecord(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Request-Id", "dropped")
+		fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	recorder := &Cassette{Real: http.DefaultTransport}
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/tx/ab", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := (&http.Client{Transport: recorder}).Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "GET /tx/ab", string(body))
+
+	path := filepath.Join(t.TempDir(), "testdata", "recorded.json")
+	require.NoError(t, recorder.Save(path))
+	replay, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, replay.Interactions, 1)
+	assert.Equal(t, Request{Method: http.MethodGet, URL: server.URL + "/tx/ab"}, replay.Interactions[0].Request)
+	assert.Equal(t, map[string]string{"Content-Type": "text/plain"}, replay.Interactions[0].Response.Header)
+
+	status, replayed := get(t, replay, server.URL+"/tx/ab")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "GET /tx/ab", replayed)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "reading cassette")
+}
+
+func TestExecute(t *testing.T) {
+	var name string
+	var tags []string
+	cmd := &cobra.Command{
+		Use: "greet",
+		RunE: func(_ *cobra.Command, args []string) error {
+			fmt.Printf("hello %s %v %v\n", name, tags, args)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "world", "")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "")
+
+	out, err := Execute(t, cmd, "--name", "alice", "--tag", "a", "x")
+	require.NoError(t, err)
+	assert.Equal(t, "hello alice [a] [x]\n", out)
+
+	out, err = Execute(t, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world [] []\n", out)
+}
+
+func TestExecuteWithInput(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "echo",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			_, err := io.Copy(os.Stdout, os.Stdin)
+			return err
+		},
+	}
+
+	out, err := ExecuteWithInput(t, cmd, "piped input\n")
+	require.NoError(t, err)
+	assert.Equal(t, "piped input\n", out)
+}
@@ -0,0 +1,136 @@
+// Package keys parses and encodes BSV private keys in Wallet Import Format
+// and derives their public keys and P2PKH addresses.
+//
+// A WIF records the network and whether the key's public key is compressed,
+// and both change the address: the same private key has four P2PKH addresses.
+package keys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+)
+
+// Network prefix bytes for WIF encoding
+const (
+	MainnetWIFPrefix byte = 0x80
+	TestnetWIFPrefix byte = 0xef
+	compressMagic    byte = 0x01
+	privateKeyLen         = 32
+)
+
+// Errors returned by ParseWIF, wrapped with the offending detail
+var (
+	ErrEncoding        = errors.New("invalid base58 encoding")
+	ErrLength          = errors.New("invalid WIF length")
+	ErrCompressionFlag = errors.New("invalid compression flag")
+	ErrNetwork         = errors.New("unknown network prefix")
+	ErrChecksum        = errors.New("invalid WIF checksum")
+)
+
+// WIF is a parsed WIF private key.
+type WIF struct {
+	Key        *ec.PrivateKey
+	Testnet    bool // Encoded with the testnet prefix
+	Compressed bool // Flagged for the compressed public key
+}
+
+// ParseWIF decodes and validates a WIF string, detecting its network and
+// compression flag.
+func ParseWIF(wif string) (*WIF, error) {
+	decoded, err := base58.Decode(wif)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncoding, err)
+	}
+
+	decodedLen := len(decoded)
+	w := &WIF{}
+
+	// Validate length: 1 prefix + 32 privkey + 4 checksum = 37 (uncompressed)
+	//                   1 prefix + 32 privkey + 1 compress + 4 checksum = 38 (compressed)
+	switch decodedLen {
+	case 1 + privateKeyLen + 1 + 4:
+		if decoded[33] != compressMagic {
+			return nil, fmt.Errorf("%w: 0x%02x", ErrCompressionFlag, decoded[33])
+		}
+		w.Compressed = true
+	case 1 + privateKeyLen + 4:
+	default:
+		return nil, fmt.Errorf("%w: %d bytes", ErrLength, decodedLen)
+	}
+
+	// Detect network
+	switch decoded[0] {
+	case MainnetWIFPrefix:
+	case TestnetWIFPrefix:
+		w.Testnet = true
+	default:
+		return nil, fmt.Errorf("%w: 0x%02x", ErrNetwork, decoded[0])
+	}
+
+	// Validate checksum
+	payload := decoded[:decodedLen-4]
+	if !bytes.Equal(crypto.Sha256d(payload)[:4], decoded[decodedLen-4:]) {
+		return nil, ErrChecksum
+	}
+
+	w.Key, _ = ec.PrivateKeyFromBytes(decoded[1 : 1+privateKeyLen])
+	return w, nil
+}
+
+// EncodeWIF encodes a private key as a WIF for the network and compression.
+func EncodeWIF(key *ec.PrivateKey, testnet, compressed bool) string {
+	prefix := MainnetWIFPrefix
+	if testnet {
+		prefix = TestnetWIFPrefix
+	}
+
+	buf := make([]byte, 0, 1+privateKeyLen+1+4)
+	buf = append(buf, prefix)
+	buf = append(buf, key.Serialize()...)
+	if compressed {
+		buf = append(buf, compressMagic)
+	}
+
+	buf = append(buf, crypto.Sha256d(buf)[:4]...)
+	return base58.Encode(buf)
+}
+
+// PublicKeyHex returns the compressed or uncompressed public key as hex.
+func PublicKeyHex(pub *ec.PublicKey, compressed bool) string {
+	if compressed {
+		return hex.EncodeToString(pub.Compressed())
+	}
+	return hex.EncodeToString(pub.Uncompressed())
+}
+
+// Address returns the P2PKH address of the compressed or uncompressed public
+// key on the network.
+func Address(pub *ec.PublicKey, testnet, compressed bool) (*script.Address, error) {
+	return script.NewAddressFromPublicKeyWithCompression(pub, !testnet, compressed)
+}
+
+// PublicKeyHex returns the public key the WIF is flagged for, as hex.
+func (w *WIF) PublicKeyHex() string {
+	return PublicKeyHex(w.Key.PubKey(), w.Compressed)
+}
+
+// Address returns the P2PKH address the WIF's funds are held on: the one for
+// its own network and compression.
+func (w *WIF) Address() (*script.Address, error) {
+	return Address(w.Key.PubKey(), w.Testnet, w.Compressed)
+}
+
+// Network returns "mainnet" or "testnet".
+func (w *WIF) Network() string {
+	if w.Testnet {
+		return "testnet"
+	}
+	return "mainnet"
+}
@@ -0,0 +1,98 @@
+package keys
+
+import (
+	"testing"
+
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// WIFs and addresses of private key 1
+const (
+	mainnetCompressedWIF     = "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"
+	mainnetUncompressedWIF   = "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+	testnetCompressedWIF     = "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"
+	testnetUncompressedWIF   = "91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgwmaKkrx"
+	compressedPubKey         = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	mainnetCompressedAddress = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+)
+
+// wifWith encodes private key 1 with a checksum but the given prefix and flag.
+func wifWith(prefix, flag byte) string {
+	buf := append([]byte{prefix}, make([]byte, privateKeyLen)...)
+	buf[privateKeyLen] = 1
+	buf = append(buf, flag)
+	return base58.Encode(append(buf, crypto.Sha256d(buf)[:4]...))
+}
+
+func TestParseWIF(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wif        string
+		testnet    bool
+		compressed bool
+		address    string
+	}{
+		{mainnetCompressedWIF, false, true, mainnetCompressedAddress},
+		{mainnetUncompressedWIF, false, false, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+		{testnetCompressedWIF, true, true, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"},
+		{testnetUncompressedWIF, true, false, "mtoKs9V381UAhUia3d7Vb9GNak8Qvmcsme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wif, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := ParseWIF(tt.wif)
+			require.NoError(t, err)
+			assert.Equal(t, tt.testnet, w.Testnet)
+			assert.Equal(t, tt.compressed, w.Compressed)
+			assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000001", w.Key.Hex())
+
+			address, err := w.Address()
+			require.NoError(t, err)
+			assert.Equal(t, tt.address, address.AddressString)
+			assert.Equal(t, tt.wif, EncodeWIF(w.Key, tt.testnet, tt.compressed))
+		})
+	}
+}
+
+func TestParseWIFErrors(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, mainnetCompressedWIF, wifWith(MainnetWIFPrefix, compressMagic))
+
+	tests := map[string]struct {
+		wif string
+		err error
+	}{
+		"not base58":      {"0OIl", ErrEncoding},
+		"bad length":      {"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", ErrLength},
+		"bad checksum":    {mainnetCompressedWIF[:len(mainnetCompressedWIF)-1] + "o", ErrChecksum},
+		"bad flag":        {wifWith(MainnetWIFPrefix, 0x02), ErrCompressionFlag},
+		"unknown network": {wifWith(0x00, compressMagic), ErrNetwork},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseWIF(tt.wif)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestWIFPublicKey(t *testing.T) {
+	t.Parallel()
+
+	w, err := ParseWIF(mainnetCompressedWIF)
+	require.NoError(t, err)
+	assert.Equal(t, compressedPubKey, w.PublicKeyHex())
+	assert.Equal(t, "mainnet", w.Network())
+
+	uncompressed := PublicKeyHex(w.Key.PubKey(), false)
+	assert.Len(t, uncompressed, 130)
+	assert.Equal(t, "04"+compressedPubKey[2:], uncompressed[:66])
+}
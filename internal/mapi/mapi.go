@@ -0,0 +1,281 @@
+// Package mapi provides a client for the Merchant API (mAPI), the legacy
+// protocol some miners still expose instead of ARC for fee quotes,
+// transaction submission, and status queries.
+//
+// The package supports:
+//   - Fetching a miner's fee quote (mining and relay fees by fee type)
+//   - Submitting raw transactions and checking their status
+//   - Verifying the signature of the JSON envelope every response arrives in
+//   - Pinning the miner's identity key, so unsigned or foreign responses are refused
+package mapi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+// Values of a response's returnResult
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Errors returned when a response envelope cannot be trusted
+var (
+	ErrInvalidSignature = errors.New("invalid envelope signature")
+	ErrUnsigned         = errors.New("envelope is not signed")
+	ErrUntrustedKey     = errors.New("envelope signed by an untrusted key")
+)
+
+// Client handles communication with a miner's mAPI endpoint
+type Client struct {
+	baseURL  string
+	apiKey   string
+	minerKey *ec.PublicKey // Key every response must be signed by, nil to accept any valid signature
+	client   *http.Client
+}
+
+// Envelope is the JSON envelope wrapping every mAPI response. The signature,
+// when present, is a DER ECDSA signature of the SHA-256 of the payload.
+type Envelope struct {
+	Payload   string  `json:"payload"`
+	Signature *string `json:"signature"`
+	PublicKey *string `json:"publicKey"`
+	Encoding  string  `json:"encoding"`
+	MimeType  string  `json:"mimetype"`
+}
+
+// FeeRate is a fee expressed as satoshis per number of bytes
+type FeeRate struct {
+	Satoshis uint64 `json:"satoshis"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// Fee is the mining and relay fee for one kind of transaction data
+type Fee struct {
+	FeeType   string  `json:"feeType"` // "standard" or "data"
+	MiningFee FeeRate `json:"miningFee"`
+	RelayFee  FeeRate `json:"relayFee"`
+}
+
+// FeeQuote represents the payload of a fee quote response
+type FeeQuote struct {
+	APIVersion                string `json:"apiVersion"`
+	Timestamp                 string `json:"timestamp"`
+	ExpiryTime                string `json:"expiryTime"`
+	MinerID                   string `json:"minerId"`
+	CurrentHighestBlockHash   string `json:"currentHighestBlockHash"`
+	CurrentHighestBlockHeight int64  `json:"currentHighestBlockHeight"`
+	Fees                      []Fee  `json:"fees"`
+}
+
+// TransactionRequest represents a transaction submission request
+type TransactionRequest struct {
+	RawTx string `json:"rawtx"`
+}
+
+// ConflictedTx is a transaction a rejected submission double spends
+type ConflictedTx struct {
+	TxID string `json:"txid"`
+	Size int64  `json:"size,omitempty"`
+	Hex  string `json:"hex,omitempty"`
+}
+
+// TransactionResponse represents the payload of a submission response
+type TransactionResponse struct {
+	APIVersion                string         `json:"apiVersion"`
+	Timestamp                 string         `json:"timestamp"`
+	TxID                      string         `json:"txid"`
+	ReturnResult              string         `json:"returnResult"`
+	ResultDescription         string         `json:"resultDescription"`
+	MinerID                   string         `json:"minerId"`
+	CurrentHighestBlockHash   string         `json:"currentHighestBlockHash"`
+	CurrentHighestBlockHeight int64          `json:"currentHighestBlockHeight"`
+	TxSecondMempoolExpiry     int64          `json:"txSecondMempoolExpiry"`
+	ConflictedWith            []ConflictedTx `json:"conflictedWith,omitempty"`
+}
+
+// TransactionStatus represents the payload of a status response
+type TransactionStatus struct {
+	APIVersion            string `json:"apiVersion"`
+	Timestamp             string `json:"timestamp"`
+	TxID                  string `json:"txid"`
+	ReturnResult          string `json:"returnResult"`
+	ResultDescription     string `json:"resultDescription"`
+	BlockHash             string `json:"blockHash,omitempty"`
+	BlockHeight           int64  `json:"blockHeight,omitempty"`
+	Confirmations         int64  `json:"confirmations,omitempty"`
+	MinerID               string `json:"minerId"`
+	TxSecondMempoolExpiry int64  `json:"txSecondMempoolExpiry"`
+}
+
+// NewClient creates a new mAPI client
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetEndpoint points the client at another mAPI endpoint and API key, as
+// when config.yaml is reloaded. The pinned miner key is kept.
+func (c *Client) SetEndpoint(baseURL, apiKey string) {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	c.apiKey = apiKey
+}
+
+// SetMinerKey pins the miner's identity public key, given as hex. Responses
+// must then be signed by that key; unsigned ones are refused.
+func (c *Client) SetMinerKey(pubKeyHex string) error {
+	key, err := parsePublicKey(pubKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid miner key: %w", err)
+	}
+	c.minerKey = key
+	return nil
+}
+
+// GetFeeQuote fetches the miner's current fee quote
+func (c *Client) GetFeeQuote() (*FeeQuote, error) {
+	var quote FeeQuote
+	if err := c.do("GET", "/mapi/feeQuote", nil, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// BroadcastTransaction submits a raw transaction. A transaction the miner
+// refuses is not an error: its ReturnResult is ResultFailure.
+func (c *Client) BroadcastTransaction(rawTx string) (*TransactionResponse, error) {
+	jsonData, err := json.Marshal(TransactionRequest{RawTx: rawTx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var txResp TransactionResponse
+	if err := c.do("POST", "/mapi/tx", jsonData, &txResp); err != nil {
+		return nil, err
+	}
+	return &txResp, nil
+}
+
+// GetTransactionStatus checks the status of a transaction
+func (c *Client) GetTransactionStatus(txid string) (*TransactionStatus, error) {
+	var status TransactionStatus
+	if err := c.do("GET", "/mapi/tx/"+txid, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// do sends a request, verifies the response envelope, and decodes its payload into result.
+func (c *Client) do(method, path string, body []byte, result any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = strings.NewReader(string(body))
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mAPI error (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	payload, err := envelope.Open(c.minerKey)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	return nil
+}
+
+// Open verifies the envelope's signature and returns its payload. An unsigned
+// envelope is accepted only when minerKey is nil; a signed one must verify,
+// and be signed by minerKey when it is set.
+func (e *Envelope) Open(minerKey *ec.PublicKey) ([]byte, error) {
+	payload := []byte(e.Payload)
+	if e.Signature == nil || *e.Signature == "" {
+		if minerKey != nil {
+			return nil, ErrUnsigned
+		}
+		return payload, nil
+	}
+	if e.PublicKey == nil {
+		return nil, fmt.Errorf("%w: no public key", ErrInvalidSignature)
+	}
+
+	key, err := parsePublicKey(*e.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if minerKey != nil && !key.IsEqual(minerKey) {
+		return nil, fmt.Errorf("%w: %s", ErrUntrustedKey, *e.PublicKey)
+	}
+
+	sigBytes, err := hex.DecodeString(*e.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	sig, err := ec.ParseDERSignature(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !sig.Verify(crypto.Sha256(payload), key) {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
+
+// parsePublicKey decodes a hex public key.
+func parsePublicKey(pubKeyHex string) (*ec.PublicKey, error) {
+	b, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	return ec.ParsePubKey(b)
+}
+
+// FeePerKb returns the fee as a rate in satoshis per 1000 bytes
+func (f FeeRate) FeePerKb() float64 {
+	if f.Bytes == 0 {
+		return 0
+	}
+	return float64(f.Satoshis) * 1000 / float64(f.Bytes)
+}
@@ -0,0 +1,166 @@
+package mapi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey returns a deterministic miner identity key.
+func testKey(t *testing.T, hexKey string) *ec.PrivateKey {
+	t.Helper()
+	key, err := ec.PrivateKeyFromHex(hexKey)
+	require.NoError(t, err)
+	return key
+}
+
+// sealEnvelope wraps payload in an envelope signed by key, or unsigned when key is nil.
+func sealEnvelope(t *testing.T, key *ec.PrivateKey, payload any) *Envelope {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	envelope := &Envelope{Payload: string(data), Encoding: "UTF-8", MimeType: "application/json"}
+	if key != nil {
+		sig, err := key.Sign(crypto.Sha256(data))
+		require.NoError(t, err)
+		der, err := sig.ToDER()
+		require.NoError(t, err)
+		sigHex := hex.EncodeToString(der)
+		pubHex := hex.EncodeToString(key.PubKey().Compressed())
+		envelope.Signature, envelope.PublicKey = &sigHex, &pubHex
+	}
+	return envelope
+}
+
+func TestEnvelopeOpen(t *testing.T) {
+	t.Parallel()
+
+	miner := testKey(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	other := testKey(t, "0000000000000000000000000000000000000000000000000000000000000002")
+	payload := map[string]string{"txid": "abc"}
+
+	t.Run("verifies a signed envelope", func(t *testing.T) {
+		t.Parallel()
+		data, err := sealEnvelope(t, miner, payload).Open(nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"txid":"abc"}`, string(data))
+
+		_, err = sealEnvelope(t, miner, payload).Open(miner.PubKey())
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts an unsigned envelope without a pinned key", func(t *testing.T) {
+		t.Parallel()
+		_, err := sealEnvelope(t, nil, payload).Open(nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("refuses an unsigned envelope with a pinned key", func(t *testing.T) {
+		t.Parallel()
+		_, err := sealEnvelope(t, nil, payload).Open(miner.PubKey())
+		require.ErrorIs(t, err, ErrUnsigned)
+	})
+
+	t.Run("refuses another key", func(t *testing.T) {
+		t.Parallel()
+		_, err := sealEnvelope(t, other, payload).Open(miner.PubKey())
+		require.ErrorIs(t, err, ErrUntrustedKey)
+	})
+
+	t.Run("refuses a tampered payload", func(t *testing.T) {
+		t.Parallel()
+		envelope := sealEnvelope(t, miner, payload)
+		envelope.Payload = `{"txid":"def"}`
+		_, err := envelope.Open(nil)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("refuses a malformed signature", func(t *testing.T) {
+		t.Parallel()
+		envelope := sealEnvelope(t, miner, payload)
+		bad := "zz"
+		envelope.Signature = &bad
+		_, err := envelope.Open(nil)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	miner := testKey(t, "0000000000000000000000000000000000000000000000000000000000000001")
+
+	var submitted TransactionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var payload any
+		switch r.Method + " " + r.URL.Path {
+		case "GET /mapi/feeQuote":
+			payload = FeeQuote{
+				MinerID: "miner",
+				Fees: []Fee{
+					{FeeType: "standard", MiningFee: FeeRate{Satoshis: 50, Bytes: 1000}, RelayFee: FeeRate{Satoshis: 25, Bytes: 1000}},
+				},
+			}
+		case "POST /mapi/tx":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&submitted))
+			if submitted.RawTx == "bad" {
+				payload = TransactionResponse{ReturnResult: ResultFailure, ResultDescription: "Missing inputs"}
+			} else {
+				payload = TransactionResponse{TxID: "abc", ReturnResult: ResultSuccess}
+			}
+		case "GET /mapi/tx/abc":
+			payload = TransactionStatus{TxID: "abc", ReturnResult: ResultSuccess, BlockHeight: 800000, Confirmations: 2}
+		default:
+			http.Error(w, `{"status":404,"title":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(sealEnvelope(t, miner, payload)))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/", "test-key")
+	require.NoError(t, client.SetMinerKey(hex.EncodeToString(miner.PubKey().Compressed())))
+
+	quote, err := client.GetFeeQuote()
+	require.NoError(t, err)
+	require.Len(t, quote.Fees, 1)
+	assert.InDelta(t, 50.0, quote.Fees[0].MiningFee.FeePerKb(), 0.001)
+
+	resp, err := client.BroadcastTransaction("0100")
+	require.NoError(t, err)
+	assert.Equal(t, "0100", submitted.RawTx)
+	assert.Equal(t, ResultSuccess, resp.ReturnResult)
+	assert.Equal(t, "abc", resp.TxID)
+
+	resp, err = client.BroadcastTransaction("bad")
+	require.NoError(t, err)
+	assert.Equal(t, ResultFailure, resp.ReturnResult)
+	assert.Equal(t, "Missing inputs", resp.ResultDescription)
+
+	status, err := client.GetTransactionStatus("abc")
+	require.NoError(t, err)
+	assert.Equal(t, int64(800000), status.BlockHeight)
+	assert.Equal(t, int64(2), status.Confirmations)
+
+	_, err = client.GetTransactionStatus("missing")
+	require.ErrorContains(t, err, "mAPI error (HTTP 404)")
+
+	// A client pinned to another miner refuses the responses
+	pinned := NewClient(server.URL, "test-key")
+	other := testKey(t, "0000000000000000000000000000000000000000000000000000000000000002")
+	require.NoError(t, pinned.SetMinerKey(hex.EncodeToString(other.PubKey().Compressed())))
+	_, err = pinned.GetFeeQuote()
+	require.ErrorIs(t, err, ErrUntrustedKey)
+
+	require.ErrorContains(t, pinned.SetMinerKey("not-hex"), "invalid miner key")
+}
@@ -0,0 +1,397 @@
+// Package multisig coordinates m-of-n bare multisig spends between signers.
+//
+// A spend starts as a Proposal: an unsigned transaction in Extended Format,
+// so each signer can compute signature hashes without network access, plus
+// the cosigner public keys. Proposals are JSON files passed between machines.
+// Each signer adds its signatures, and once m signatures per input are
+// collected the proposal is finalized into a broadcastable transaction.
+//
+// The package supports:
+//   - Building and parsing m-of-n locking scripts
+//   - Building funded spends with size-aware fee estimation
+//   - Signing with any cosigner key, in any order
+//   - Merging signatures from proposal copies signed in parallel
+//   - Verifying signatures before assembling unlocking scripts
+package multisig
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/bsv-blockchain/go-sdk/util"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// ProposalVersion is the current proposal file format version.
+const ProposalVersion = 1
+
+// MaxKeys is the largest n supported, the highest small-integer opcode.
+const MaxKeys = 16
+
+// sigHashFlag is the sighash type used for all signatures.
+const sigHashFlag = sighash.AllForkID
+
+// maxSigSize is the largest DER signature plus its sighash byte.
+const maxSigSize = 73
+
+// Common errors
+var (
+	ErrNotCosigner   = errors.New("key is not a cosigner")
+	ErrNotEnoughSigs = errors.New("not enough signatures")
+	ErrMismatch      = errors.New("proposals are for different transactions")
+)
+
+// LockingScript builds an m-of-n bare multisig locking script. Keys are used in
+// the order given; signatures must later appear in the same order.
+func LockingScript(m int, pubKeys []*ec.PublicKey) (*script.Script, error) {
+	n := len(pubKeys)
+	if n < 1 || n > MaxKeys {
+		return nil, fmt.Errorf("need between 1 and %d public keys, got %d", MaxKeys, n)
+	}
+	if m < 1 || m > n {
+		return nil, fmt.Errorf("required signatures must be between 1 and %d, got %d", n, m)
+	}
+
+	seen := make(map[string]bool, n)
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.Op1 + byte(m-1)); err != nil {
+		return nil, err
+	}
+	for _, pk := range pubKeys {
+		b := pk.Compressed()
+		if seen[string(b)] {
+			return nil, fmt.Errorf("duplicate public key %x", b)
+		}
+		seen[string(b)] = true
+		if err := s.AppendPushData(b); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.AppendOpcodes(script.Op1+byte(n-1), script.OpCHECKMULTISIG); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseLockingScript returns the required signature count and public keys of a
+// multisig locking script.
+func ParseLockingScript(s *script.Script) (int, []*ec.PublicKey, error) {
+	info := scripts.Classify(*s, true)
+	if info.Type != scripts.TemplateMultisig {
+		return 0, nil, fmt.Errorf("not a multisig locking script")
+	}
+
+	pubKeys := make([]*ec.PublicKey, 0, len(info.PubKeys))
+	for _, h := range info.PubKeys {
+		pk, err := ec.PublicKeyFromString(h)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid public key in script: %w", err)
+		}
+		pubKeys = append(pubKeys, pk)
+	}
+	return info.RequiredSigs, pubKeys, nil
+}
+
+// UnlockingSize returns the largest unlocking script size for m signatures.
+func UnlockingSize(m int) int {
+	return 1 + m*(1+maxSigSize)
+}
+
+// InputSize returns the largest serialized size of an input spending an m-of-n output.
+func InputSize(m int) int {
+	n := UnlockingSize(m)
+	return 32 + 4 + len(util.VarInt(n).Bytes()) + n + 4
+}
+
+// EstimateFee estimates the fee of a spend with numInputs multisig inputs, the
+// given outputs and a change output paying change.
+func EstimateFee(m, numInputs int, outputs []*transaction.TransactionOutput, change *script.Script, feePerKb uint64) uint64 {
+	all := append([]*transaction.TransactionOutput{{LockingScript: change}}, outputs...)
+	size := uint64(txbuilder.BaseTxSize + numInputs*InputSize(m) + txbuilder.OutputsSize(all))
+	return max(size*feePerKb/1000, txbuilder.MinFee)
+}
+
+// BuildSpend builds an unsigned transaction spending multisig UTXOs locked by
+// lock to outputs, with any remainder after the fee paid to change. With no
+// outputs every UTXO is spent and everything after the fee goes to change.
+// UTXOs are selected largest first.
+func BuildSpend(lock *script.Script, utxos []*txbuilder.UTXO, outputs []*transaction.TransactionOutput, change *script.Script, feePerKb uint64) (*transaction.Transaction, error) {
+	m, _, err := ParseLockingScript(lock)
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("no UTXOs available")
+	}
+
+	var amount uint64
+	for _, out := range outputs {
+		amount += out.Satoshis
+	}
+
+	sorted := make([]*txbuilder.UTXO, len(utxos))
+	copy(sorted, utxos)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Value > sorted[j].Value })
+
+	// Send-all spends every UTXO; otherwise stop once amount and fee are covered
+	var selected []*txbuilder.UTXO
+	var total, fee uint64
+	for _, u := range sorted {
+		selected = append(selected, u)
+		total += u.Value
+		fee = EstimateFee(m, len(selected), outputs, change, feePerKb)
+		if amount > 0 && total >= amount+fee {
+			break
+		}
+	}
+	if total < amount+fee {
+		return nil, fmt.Errorf("insufficient funds: have %d satoshis, need %d (amount: %d + fee: ~%d)", total, amount+fee, amount, fee)
+	}
+
+	tx := transaction.NewTransaction()
+	for _, u := range selected {
+		if err := tx.AddInputFrom(u.TxHash, u.TxPos, lock.String(), u.Value, nil); err != nil {
+			return nil, fmt.Errorf("adding input %s:%d: %w", u.TxHash, u.TxPos, err)
+		}
+	}
+	for _, out := range outputs {
+		tx.AddOutput(out)
+	}
+
+	if remainder := total - amount - fee; remainder > 0 {
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: remainder, LockingScript: change})
+	} else if len(outputs) == 0 {
+		return nil, fmt.Errorf("insufficient funds: fee exceeds balance")
+	}
+	return tx, nil
+}
+
+// Proposal is a multisig spend being passed between signers.
+type Proposal struct {
+	Version       int                 `json:"version"`
+	Testnet       bool                `json:"testnet"`
+	LockingScript string              `json:"lockingScript"` // Hex multisig script every input spends
+	Tx            string              `json:"tx"`            // Unsigned transaction in Extended Format
+	Signatures    []map[string]string `json:"signatures"`    // Per input: compressed public key hex -> signature hex
+}
+
+// NewProposal creates a proposal for tx, whose inputs must all spend lock and
+// carry their source outputs.
+func NewProposal(lock *script.Script, tx *transaction.Transaction, testnet bool) (*Proposal, error) {
+	if _, _, err := ParseLockingScript(lock); err != nil {
+		return nil, err
+	}
+	for i, in := range tx.Inputs {
+		out := in.SourceTxOutput()
+		if out == nil {
+			return nil, fmt.Errorf("input %d has no source output", i)
+		}
+		if !out.LockingScript.Equals(lock) {
+			return nil, fmt.Errorf("input %d does not spend the multisig script", i)
+		}
+	}
+
+	ef, err := tx.EFHex()
+	if err != nil {
+		return nil, fmt.Errorf("encoding transaction: %w", err)
+	}
+
+	p := &Proposal{
+		Version:       ProposalVersion,
+		Testnet:       testnet,
+		LockingScript: lock.String(),
+		Tx:            ef,
+		Signatures:    make([]map[string]string, len(tx.Inputs)),
+	}
+	for i := range p.Signatures {
+		p.Signatures[i] = make(map[string]string)
+	}
+	return p, nil
+}
+
+// Transaction parses the proposal's transaction.
+func (p *Proposal) Transaction() (*transaction.Transaction, error) {
+	tx, err := transaction.NewTransactionFromHex(p.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proposal transaction: %w", err)
+	}
+	if len(tx.Inputs) != len(p.Signatures) {
+		return nil, fmt.Errorf("proposal has %d signature sets for %d inputs", len(p.Signatures), len(tx.Inputs))
+	}
+	return tx, nil
+}
+
+// script parses the proposal's locking script.
+func (p *Proposal) script() (*script.Script, int, []*ec.PublicKey, error) {
+	lock, err := script.NewFromHex(p.LockingScript)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("parsing locking script: %w", err)
+	}
+	m, pubKeys, err := ParseLockingScript(lock)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return lock, m, pubKeys, nil
+}
+
+// Required returns the number of signatures needed per input.
+func (p *Proposal) Required() (int, error) {
+	_, m, _, err := p.script()
+	return m, err
+}
+
+// Signers returns the public keys that have signed every input.
+func (p *Proposal) Signers() []string {
+	var signers []string
+	if len(p.Signatures) == 0 {
+		return signers
+	}
+	for pk := range p.Signatures[0] {
+		all := true
+		for _, sigs := range p.Signatures[1:] {
+			if _, ok := sigs[pk]; !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			signers = append(signers, pk)
+		}
+	}
+	sort.Strings(signers)
+	return signers
+}
+
+// Sign adds key's signature to every input. It fails if key is not one of the
+// cosigners.
+func (p *Proposal) Sign(key *ec.PrivateKey) error {
+	_, _, pubKeys, err := p.script()
+	if err != nil {
+		return err
+	}
+	pub := hex.EncodeToString(key.PubKey().Compressed())
+	if !contains(pubKeys, pub) {
+		return ErrNotCosigner
+	}
+
+	tx, err := p.Transaction()
+	if err != nil {
+		return err
+	}
+
+	for i := range tx.Inputs {
+		hash, err := tx.CalcInputSignatureHash(uint32(i), sigHashFlag) //nolint:gosec // input index fits in uint32
+		if err != nil {
+			return fmt.Errorf("computing signature hash for input %d: %w", i, err)
+		}
+		sig, err := key.Sign(hash)
+		if err != nil {
+			return fmt.Errorf("signing input %d: %w", i, err)
+		}
+		p.Signatures[i][pub] = hex.EncodeToString(append(sig.Serialize(), byte(sigHashFlag)))
+	}
+	return nil
+}
+
+// Merge copies signatures from other, which must be for the same transaction.
+func (p *Proposal) Merge(other *Proposal) error {
+	if other.Tx != p.Tx || other.LockingScript != p.LockingScript || len(other.Signatures) != len(p.Signatures) {
+		return ErrMismatch
+	}
+	for i, sigs := range other.Signatures {
+		for pk, sig := range sigs {
+			p.Signatures[i][pk] = sig
+		}
+	}
+	return nil
+}
+
+// Finalize verifies the collected signatures and returns the transaction with
+// unlocking scripts built from the first m valid signatures per input, in
+// public key order.
+func (p *Proposal) Finalize() (*transaction.Transaction, error) {
+	_, m, pubKeys, err := p.script()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := p.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, in := range tx.Inputs {
+		hash, err := tx.CalcInputSignatureHash(uint32(i), sigHashFlag) //nolint:gosec // input index fits in uint32
+		if err != nil {
+			return nil, fmt.Errorf("computing signature hash for input %d: %w", i, err)
+		}
+
+		var sigs [][]byte
+		for _, pk := range pubKeys {
+			if len(sigs) == m {
+				break
+			}
+			sigHex, ok := p.Signatures[i][hex.EncodeToString(pk.Compressed())]
+			if !ok {
+				continue
+			}
+			sig, err := verify(sigHex, hash, pk)
+			if err != nil {
+				return nil, fmt.Errorf("input %d: signature from %x: %w", i, pk.Compressed(), err)
+			}
+			sigs = append(sigs, sig)
+		}
+		if len(sigs) < m {
+			return nil, fmt.Errorf("input %d: %w (have %d of %d)", i, ErrNotEnoughSigs, len(sigs), m)
+		}
+
+		// OP_CHECKMULTISIG pops one extra item, hence the leading OP_0
+		unlock := &script.Script{}
+		if err := unlock.AppendOpcodes(script.OpFALSE); err != nil {
+			return nil, err
+		}
+		for _, sig := range sigs {
+			if err := unlock.AppendPushData(sig); err != nil {
+				return nil, err
+			}
+		}
+		in.UnlockingScript = unlock
+	}
+	return tx, nil
+}
+
+// verify decodes a signature with its sighash byte and checks it against hash.
+func verify(sigHex string, hash []byte, pk *ec.PublicKey) ([]byte, error) {
+	b, err := hex.DecodeString(sigHex)
+	if err != nil || len(b) < 2 {
+		return nil, fmt.Errorf("malformed signature")
+	}
+	if sighash.Flag(b[len(b)-1]) != sigHashFlag {
+		return nil, fmt.Errorf("unsupported sighash type %#x", b[len(b)-1])
+	}
+	sig, err := ec.ParseDERSignature(b[:len(b)-1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+	if !sig.Verify(hash, pk) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return b, nil
+}
+
+// contains reports whether pubKeys includes the compressed key hex.
+func contains(pubKeys []*ec.PublicKey, pub string) bool {
+	for _, pk := range pubKeys {
+		if hex.EncodeToString(pk.Compressed()) == pub {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package multisig
+
+import (
+	"encoding/hex"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// testKeys returns n deterministic private keys.
+func testKeys(t *testing.T, n int) []*ec.PrivateKey {
+	t.Helper()
+
+	keys := make([]*ec.PrivateKey, n)
+	for i := range keys {
+		b := make([]byte, 32)
+		b[31] = byte(i + 1)
+		keys[i], _ = ec.PrivateKeyFromBytes(b)
+	}
+	return keys
+}
+
+// pubKeys returns the public keys of keys.
+func pubKeys(keys []*ec.PrivateKey) []*ec.PublicKey {
+	pks := make([]*ec.PublicKey, len(keys))
+	for i, k := range keys {
+		pks[i] = k.PubKey()
+	}
+	return pks
+}
+
+// testProposal builds a 2-of-3 proposal spending two UTXOs.
+func testProposal(t *testing.T) ([]*ec.PrivateKey, *Proposal) {
+	t.Helper()
+
+	keys := testKeys(t, 3)
+	lock, err := LockingScript(2, pubKeys(keys))
+	require.NoError(t, err)
+
+	dest, err := script.NewFromHex("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+	require.NoError(t, err)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 6000}, {TxHash: testTxID, TxPos: 1, Value: 5000}}
+	tx, err := BuildSpend(lock, utxos, []*transaction.TransactionOutput{{Satoshis: 10000, LockingScript: dest}}, lock, 100)
+	require.NoError(t, err)
+
+	p, err := NewProposal(lock, tx, false)
+	require.NoError(t, err)
+	return keys, p
+}
+
+func TestLockingScript(t *testing.T) {
+	t.Parallel()
+
+	keys := testKeys(t, 3)
+	lock, err := LockingScript(2, pubKeys(keys))
+	require.NoError(t, err)
+	assert.True(t, lock.IsMultiSigOut())
+
+	m, pks, err := ParseLockingScript(lock)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m)
+	require.Len(t, pks, 3)
+	assert.Equal(t, keys[2].PubKey().Compressed(), pks[2].Compressed())
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := LockingScript(0, pubKeys(keys))
+		require.Error(t, err)
+		_, err = LockingScript(4, pubKeys(keys))
+		require.Error(t, err)
+		_, err = LockingScript(1, nil)
+		require.Error(t, err)
+		_, err = LockingScript(1, []*ec.PublicKey{keys[0].PubKey(), keys[0].PubKey()})
+		require.ErrorContains(t, err, "duplicate")
+	})
+
+	t.Run("not multisig", func(t *testing.T) {
+		t.Parallel()
+
+		p2pkh, err := script.NewFromHex("76a914e2a623699e81b291c0327f408fea765d534baa2a88ac")
+		require.NoError(t, err)
+		_, _, err = ParseLockingScript(p2pkh)
+		require.Error(t, err)
+	})
+}
+
+func TestBuildSpend(t *testing.T) {
+	t.Parallel()
+
+	keys := testKeys(t, 3)
+	lock, err := LockingScript(2, pubKeys(keys))
+	require.NoError(t, err)
+	utxos := []*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 1000}, {TxHash: testTxID, TxPos: 1, Value: 50000}}
+
+	t.Run("largest first with change", func(t *testing.T) {
+		t.Parallel()
+
+		out := &transaction.TransactionOutput{Satoshis: 20000, LockingScript: lock}
+		tx, err := BuildSpend(lock, utxos, []*transaction.TransactionOutput{out}, lock, 100)
+		require.NoError(t, err)
+
+		require.Len(t, tx.Inputs, 1)
+		assert.Equal(t, uint32(1), tx.Inputs[0].SourceTxOutIndex)
+		require.Len(t, tx.Outputs, 2)
+		fee := 50000 - tx.TotalOutputSatoshis()
+		assert.Equal(t, EstimateFee(2, 1, []*transaction.TransactionOutput{out}, lock, 100), fee)
+	})
+
+	t.Run("send all", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := BuildSpend(lock, utxos, nil, lock, 100)
+		require.NoError(t, err)
+		assert.Len(t, tx.Inputs, 2)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, 51000-EstimateFee(2, 2, nil, lock, 100), tx.Outputs[0].Satoshis)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		t.Parallel()
+
+		out := &transaction.TransactionOutput{Satoshis: 51000, LockingScript: lock}
+		_, err := BuildSpend(lock, utxos, []*transaction.TransactionOutput{out}, lock, 100)
+		require.ErrorContains(t, err, "insufficient funds")
+	})
+}
+
+func TestProposalSignAndFinalize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("two of three signers", func(t *testing.T) {
+		t.Parallel()
+
+		keys, p := testProposal(t)
+		require.NoError(t, p.Sign(keys[2]))
+		_, err := p.Finalize()
+		require.ErrorIs(t, err, ErrNotEnoughSigs)
+
+		require.NoError(t, p.Sign(keys[0]))
+		assert.Len(t, p.Signers(), 2)
+
+		tx, err := p.Finalize()
+		require.NoError(t, err)
+		for i, in := range tx.Inputs {
+			// Every input must satisfy the multisig script
+			err := interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, in.SourceTxOutput()),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			)
+			require.NoError(t, err, "input %d", i)
+		}
+	})
+
+	t.Run("parallel copies merge", func(t *testing.T) {
+		t.Parallel()
+
+		keys, p := testProposal(t)
+		a, b := *p, *p
+		a.Signatures = cloneSigs(p.Signatures)
+		b.Signatures = cloneSigs(p.Signatures)
+		require.NoError(t, a.Sign(keys[0]))
+		require.NoError(t, b.Sign(keys[1]))
+
+		require.NoError(t, a.Merge(&b))
+		_, err := a.Finalize()
+		require.NoError(t, err)
+	})
+
+	t.Run("outsider key", func(t *testing.T) {
+		t.Parallel()
+
+		_, p := testProposal(t)
+		outsider := testKeys(t, 4)[3]
+		require.ErrorIs(t, p.Sign(outsider), ErrNotCosigner)
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		t.Parallel()
+
+		keys, p := testProposal(t)
+		require.NoError(t, p.Sign(keys[0]))
+		require.NoError(t, p.Sign(keys[1]))
+
+		pub := hex.EncodeToString(keys[0].PubKey().Compressed())
+		p.Signatures[1][pub] = p.Signatures[0][pub]
+		_, err := p.Finalize()
+		require.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("different transactions do not merge", func(t *testing.T) {
+		t.Parallel()
+
+		_, p := testProposal(t)
+		other := *p
+		other.Tx = "00"
+		require.ErrorIs(t, p.Merge(&other), ErrMismatch)
+	})
+}
+
+// cloneSigs deep-copies a proposal's signature sets.
+func cloneSigs(sigs []map[string]string) []map[string]string {
+	out := make([]map[string]string, len(sigs))
+	for i, m := range sigs {
+		out[i] = make(map[string]string, len(m))
+		for k, v := range m {
+			out[i][k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,358 @@
+// Package paymail provides a client for resolving and paying paymail handles.
+// Paymail maps human-readable handles (alias@domain.tld) to payment endpoints
+// advertised by the handle's host through the bsvalias capability document.
+//
+// The package supports:
+//   - Host discovery via the _bsvalias._tcp SRV record, falling back to the domain itself
+//   - Capability discovery from /.well-known/bsvalias
+//   - PKI lookup (the handle's identity public key)
+//   - Basic payment destination requests, signed when the host requires sender validation
+//   - P2P payment destinations and P2P transaction submission
+package paymail
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// Capability identifiers (BRFC IDs) used by this package
+const (
+	CapabilityPKI                   = "pki"
+	CapabilityPKIAlt                = "0c4339ef99c2"
+	CapabilityPaymentDestination    = "paymentDestination"
+	CapabilityPaymentDestinationAlt = "759684b1a19a"
+	CapabilitySenderValidation      = "6745385c3fc0"
+	CapabilityPublicProfile         = "f12f968c92d6"
+	CapabilityVerifyPublicKey       = "a9f510c16bde"
+	CapabilityP2PPaymentDestination = "2a40af698840"
+	CapabilityP2PReceiveTransaction = "5f1323cddf31"
+)
+
+// capabilityNames gives a readable name for well-known capability IDs.
+var capabilityNames = map[string]string{
+	CapabilityPKI:                   "PKI",
+	CapabilityPKIAlt:                "PKI",
+	CapabilityPaymentDestination:    "Payment Destination",
+	CapabilityPaymentDestinationAlt: "Payment Destination",
+	CapabilitySenderValidation:      "Sender Validation",
+	CapabilityPublicProfile:         "Public Profile",
+	CapabilityVerifyPublicKey:       "Verify Public Key Owner",
+	CapabilityP2PPaymentDestination: "P2P Payment Destination",
+	CapabilityP2PReceiveTransaction: "P2P Receive Transaction",
+}
+
+// Handle is a parsed paymail address.
+type Handle struct {
+	Alias  string
+	Domain string
+}
+
+// ParseHandle parses and normalizes a paymail handle such as "alice@example.com".
+func ParseHandle(s string) (Handle, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	alias, domain, ok := strings.Cut(s, "@")
+	if !ok || alias == "" || domain == "" || strings.Contains(domain, "@") || !strings.Contains(domain, ".") {
+		return Handle{}, fmt.Errorf("invalid paymail handle: %q", s)
+	}
+	return Handle{Alias: alias, Domain: domain}, nil
+}
+
+// String returns the handle in alias@domain form.
+func (h Handle) String() string {
+	return h.Alias + "@" + h.Domain
+}
+
+// Capabilities is the bsvalias capability document of a paymail host.
+type Capabilities struct {
+	BSVAlias     string         `json:"bsvalias"`
+	Capabilities map[string]any `json:"capabilities"`
+}
+
+// URL returns the endpoint template of the first of ids that the host advertises.
+func (c *Capabilities) URL(ids ...string) string {
+	for _, id := range ids {
+		if s, ok := c.Capabilities[id].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Has reports whether the capability is advertised as true or as an endpoint.
+func (c *Capabilities) Has(id string) bool {
+	switch v := c.Capabilities[id].(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	default:
+		return false
+	}
+}
+
+// CapabilityName returns a readable name for a capability ID, or the ID itself.
+func CapabilityName(id string) string {
+	if name, ok := capabilityNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// PKIResponse is the identity key of a handle.
+type PKIResponse struct {
+	BSVAlias string `json:"bsvalias"`
+	Handle   string `json:"handle"`
+	PubKey   string `json:"pubkey"`
+}
+
+// PaymentRequest is a basic payment destination request.
+type PaymentRequest struct {
+	SenderName   string `json:"senderName,omitempty"`
+	SenderHandle string `json:"senderHandle"`
+	DT           string `json:"dt"`
+	Amount       uint64 `json:"amount,omitempty"`
+	Purpose      string `json:"purpose,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+}
+
+// Sign sets the request signature, a Bitcoin Signed Message over the sender
+// handle, amount, timestamp and purpose.
+func (r *PaymentRequest) Sign(key *ec.PrivateKey) error {
+	amount := ""
+	if r.Amount > 0 {
+		amount = strconv.FormatUint(r.Amount, 10)
+	}
+	sig, err := bsm.SignMessageString(key, []byte(r.SenderHandle+amount+r.DT+r.Purpose))
+	if err != nil {
+		return fmt.Errorf("signing payment request: %w", err)
+	}
+	r.Signature = sig
+	return nil
+}
+
+// PaymentDestination is the output script returned for a basic payment request.
+type PaymentDestination struct {
+	Output string `json:"output"`
+}
+
+// P2POutput is one output requested by a P2P payment destination.
+type P2POutput struct {
+	Script   string `json:"script"`
+	Satoshis uint64 `json:"satoshis"`
+}
+
+// P2PDestination is the set of outputs a P2P payment must pay, and the
+// reference to submit the transaction with.
+type P2PDestination struct {
+	Outputs   []*P2POutput `json:"outputs"`
+	Reference string       `json:"reference"`
+}
+
+// P2PMetadata describes the sender of a P2P transaction.
+type P2PMetadata struct {
+	Sender    string `json:"sender,omitempty"`
+	PubKey    string `json:"pubkey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// P2PTransaction is a transaction submitted to the receiver's host.
+type P2PTransaction struct {
+	Hex       string       `json:"hex"`
+	Metadata  *P2PMetadata `json:"metadata,omitempty"`
+	Reference string       `json:"reference"`
+}
+
+// Sign fills the metadata public key and signature, a Bitcoin Signed Message over the txid.
+func (t *P2PTransaction) Sign(key *ec.PrivateKey, txid string) error {
+	if t.Metadata == nil {
+		t.Metadata = &P2PMetadata{}
+	}
+	sig, err := bsm.SignMessageString(key, []byte(txid))
+	if err != nil {
+		return fmt.Errorf("signing transaction: %w", err)
+	}
+	t.Metadata.PubKey = hex.EncodeToString(key.PubKey().Compressed())
+	t.Metadata.Signature = sig
+	return nil
+}
+
+// P2PTransactionResponse is the receiver's acknowledgement of a P2P transaction.
+type P2PTransactionResponse struct {
+	TxID string `json:"txid"`
+	Note string `json:"note,omitempty"`
+}
+
+// ErrorResponse represents an error response from a paymail host
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Client talks to paymail hosts.
+type Client struct {
+	client    *http.Client
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewClient creates a paymail client using the system resolver.
+func NewClient() *Client {
+	return &Client{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		lookupSRV: net.DefaultResolver.LookupSRV,
+	}
+}
+
+// Host returns the host:port serving paymail for domain. The _bsvalias._tcp SRV
+// record is used when present, otherwise the domain itself on port 443.
+func (c *Client) Host(ctx context.Context, domain string) string {
+	if _, records, err := c.lookupSRV(ctx, "bsvalias", "tcp", domain); err == nil && len(records) > 0 {
+		target := strings.TrimSuffix(records[0].Target, ".")
+		return net.JoinHostPort(target, strconv.Itoa(int(records[0].Port)))
+	}
+	return net.JoinHostPort(domain, "443")
+}
+
+// Capabilities fetches the capability document of domain's paymail host.
+func (c *Client) Capabilities(ctx context.Context, domain string) (*Capabilities, error) {
+	url := "https://" + c.Host(ctx, domain) + "/.well-known/bsvalias"
+
+	var caps Capabilities
+	if err := c.do(ctx, http.MethodGet, url, nil, &caps); err != nil {
+		return nil, err
+	}
+	if caps.BSVAlias == "" || caps.Capabilities == nil {
+		return nil, fmt.Errorf("%s does not serve a bsvalias capability document", domain)
+	}
+	return &caps, nil
+}
+
+// PKI looks up the identity public key of h.
+func (c *Client) PKI(ctx context.Context, caps *Capabilities, h Handle) (*PKIResponse, error) {
+	url, err := endpoint(caps, h, CapabilityPKI, CapabilityPKIAlt)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PKIResponse
+	if err := c.do(ctx, http.MethodGet, url, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.PubKey == "" {
+		return nil, fmt.Errorf("no public key returned for %s", h)
+	}
+	return &resp, nil
+}
+
+// PaymentDestination requests an output script to pay h.
+func (c *Client) PaymentDestination(ctx context.Context, caps *Capabilities, h Handle, req *PaymentRequest) (*PaymentDestination, error) {
+	url, err := endpoint(caps, h, CapabilityPaymentDestination, CapabilityPaymentDestinationAlt)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PaymentDestination
+	if err := c.do(ctx, http.MethodPost, url, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Output == "" {
+		return nil, fmt.Errorf("no output returned for %s", h)
+	}
+	return &resp, nil
+}
+
+// P2PPaymentDestination requests the outputs for a P2P payment of satoshis to h.
+func (c *Client) P2PPaymentDestination(ctx context.Context, caps *Capabilities, h Handle, satoshis uint64) (*P2PDestination, error) {
+	url, err := endpoint(caps, h, CapabilityP2PPaymentDestination)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp P2PDestination
+	if err := c.do(ctx, http.MethodPost, url, map[string]uint64{"satoshis": satoshis}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Outputs) == 0 || resp.Reference == "" {
+		return nil, fmt.Errorf("no outputs returned for %s", h)
+	}
+	return &resp, nil
+}
+
+// SendP2PTransaction submits a transaction paying a P2P destination to h's host,
+// which broadcasts it.
+func (c *Client) SendP2PTransaction(ctx context.Context, caps *Capabilities, h Handle, tx *P2PTransaction) (*P2PTransactionResponse, error) {
+	url, err := endpoint(caps, h, CapabilityP2PReceiveTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp P2PTransactionResponse
+	if err := c.do(ctx, http.MethodPost, url, tx, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// endpoint expands the URL template of the first advertised capability in ids for h.
+func endpoint(caps *Capabilities, h Handle, ids ...string) (string, error) {
+	tmpl := caps.URL(ids...)
+	if tmpl == "" {
+		return "", fmt.Errorf("%s does not support %s", h.Domain, CapabilityName(ids[0]))
+	}
+	r := strings.NewReplacer("{alias}", h.Alias, "{domain.tld}", h.Domain)
+	return r.Replace(tmpl), nil
+}
+
+// do sends a JSON request and decodes a JSON response into out.
+func (c *Client) do(ctx context.Context, method, url string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errorResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil || errorResp.Message == "" {
+			return fmt.Errorf("request failed with HTTP status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("paymail error: %s (HTTP %d, code: %s)", errorResp.Message, resp.StatusCode, errorResp.Code)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package paymail
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPubKey = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testOutput = "76a914e2a623699e81b291c0327f408fea765d534baa2a88ac"
+)
+
+// testHost starts a paymail host advertising caps, with base URLs rewritten to
+// the test server, and returns a client whose SRV lookups point at it.
+func testHost(t *testing.T, caps map[string]any, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	for id, v := range caps {
+		if s, ok := v.(string); ok {
+			caps[id] = server.URL + s
+		}
+	}
+	mux.HandleFunc("/.well-known/bsvalias", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(Capabilities{BSVAlias: "1.0", Capabilities: caps})
+	})
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	return &Client{
+		client: server.Client(),
+		lookupSRV: func(context.Context, string, string, string) (string, []*net.SRV, error) {
+			return "", []*net.SRV{{Target: host + ".", Port: uint16(port)}}, nil //nolint:gosec // test port
+		},
+	}
+}
+
+func TestParseHandle(t *testing.T) {
+	t.Parallel()
+
+	h, err := ParseHandle(" Alice@Example.COM ")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", h.Alias)
+	assert.Equal(t, "example.com", h.Domain)
+	assert.Equal(t, "alice@example.com", h.String())
+
+	for _, s := range []string{"", "alice", "@example.com", "alice@", "a@b@c.com", "alice@localhost"} {
+		_, err := ParseHandle(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestHost(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SRV record", func(t *testing.T) {
+		t.Parallel()
+
+		c := &Client{lookupSRV: func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			assert.Equal(t, "bsvalias", service)
+			assert.Equal(t, "tcp", proto)
+			assert.Equal(t, "example.com", name)
+			return "", []*net.SRV{{Target: "paymail.example.net.", Port: 8443}}, nil
+		}}
+		assert.Equal(t, "paymail.example.net:8443", c.Host(context.Background(), "example.com"))
+	})
+
+	t.Run("falls back to the domain", func(t *testing.T) {
+		t.Parallel()
+
+		c := &Client{lookupSRV: func(context.Context, string, string, string) (string, []*net.SRV, error) {
+			return "", nil, errors.New("no such host")
+		}}
+		assert.Equal(t, "example.com:443", c.Host(context.Background(), "example.com"))
+	})
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	c := testHost(t, map[string]any{
+		CapabilityPKIAlt:           "/id/{alias}@{domain.tld}",
+		CapabilitySenderValidation: true,
+		CapabilityPublicProfile:    false,
+	}, http.NewServeMux())
+
+	caps, err := c.Capabilities(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", caps.BSVAlias)
+	assert.True(t, caps.Has(CapabilitySenderValidation))
+	assert.True(t, caps.Has(CapabilityPKIAlt))
+	assert.False(t, caps.Has(CapabilityPublicProfile))
+	assert.False(t, caps.Has(CapabilityP2PPaymentDestination))
+	assert.NotEmpty(t, caps.URL(CapabilityPKI, CapabilityPKIAlt))
+}
+
+func TestPKI(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/id/alice@example.com", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(PKIResponse{BSVAlias: "1.0", Handle: "alice@example.com", PubKey: testPubKey})
+	})
+	mux.HandleFunc("/id/bob@example.com", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Code: "not-found", Message: "Paymail not found"})
+	})
+	c := testHost(t, map[string]any{CapabilityPKI: "/id/{alias}@{domain.tld}"}, mux)
+
+	ctx := context.Background()
+	caps, err := c.Capabilities(ctx, "example.com")
+	require.NoError(t, err)
+
+	resp, err := c.PKI(ctx, caps, Handle{Alias: "alice", Domain: "example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, testPubKey, resp.PubKey)
+
+	_, err = c.PKI(ctx, caps, Handle{Alias: "bob", Domain: "example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Paymail not found")
+	assert.Contains(t, err.Error(), "HTTP 404")
+}
+
+func TestPaymentDestination(t *testing.T) {
+	t.Parallel()
+
+	key, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	sender, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/address/alice@example.com", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var req PaymentRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "bob@example.org", req.SenderHandle)
+		assert.Equal(t, uint64(1000), req.Amount)
+
+		sig, err := base64.StdEncoding.DecodeString(req.Signature)
+		require.NoError(t, err)
+		assert.NoError(t, bsm.VerifyMessage(sender.AddressString, sig, []byte("bob@example.org1000"+req.DT+"lunch")))
+
+		json.NewEncoder(w).Encode(PaymentDestination{Output: testOutput})
+	})
+	c := testHost(t, map[string]any{CapabilityPaymentDestinationAlt: "/address/{alias}@{domain.tld}"}, mux)
+
+	ctx := context.Background()
+	caps, err := c.Capabilities(ctx, "example.com")
+	require.NoError(t, err)
+
+	req := &PaymentRequest{SenderHandle: "bob@example.org", DT: "2024-01-15T10:30:00Z", Amount: 1000, Purpose: "lunch"}
+	require.NoError(t, req.Sign(key))
+
+	dest, err := c.PaymentDestination(ctx, caps, Handle{Alias: "alice", Domain: "example.com"}, req)
+	require.NoError(t, err)
+	assert.Equal(t, testOutput, dest.Output)
+}
+
+func TestP2P(t *testing.T) {
+	t.Parallel()
+
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	key, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/p2p-payment-destination/alice@example.com", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]uint64
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, uint64(5000), req["satoshis"])
+
+		json.NewEncoder(w).Encode(P2PDestination{Outputs: []*P2POutput{{Script: testOutput, Satoshis: 5000}}, Reference: "ref-1"})
+	})
+	mux.HandleFunc("/receive-transaction/alice@example.com", func(w http.ResponseWriter, r *http.Request) {
+		var tx P2PTransaction
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&tx))
+		assert.Equal(t, "ref-1", tx.Reference)
+		assert.Equal(t, "bob@example.org", tx.Metadata.Sender)
+		assert.Equal(t, testPubKey, tx.Metadata.PubKey)
+		assert.NotEmpty(t, tx.Metadata.Signature)
+
+		json.NewEncoder(w).Encode(P2PTransactionResponse{TxID: txid, Note: "thanks"})
+	})
+	c := testHost(t, map[string]any{
+		CapabilityP2PPaymentDestination: "/p2p-payment-destination/{alias}@{domain.tld}",
+		CapabilityP2PReceiveTransaction: "/receive-transaction/{alias}@{domain.tld}",
+	}, mux)
+
+	ctx := context.Background()
+	h := Handle{Alias: "alice", Domain: "example.com"}
+	caps, err := c.Capabilities(ctx, "example.com")
+	require.NoError(t, err)
+
+	dest, err := c.P2PPaymentDestination(ctx, caps, h, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, "ref-1", dest.Reference)
+	require.Len(t, dest.Outputs, 1)
+
+	tx := &P2PTransaction{Hex: "00", Reference: dest.Reference, Metadata: &P2PMetadata{Sender: "bob@example.org"}}
+	require.NoError(t, tx.Sign(key, txid))
+	resp, err := c.SendP2PTransaction(ctx, caps, h, tx)
+	require.NoError(t, err)
+	assert.Equal(t, txid, resp.TxID)
+	assert.Equal(t, "thanks", resp.Note)
+}
+
+func TestUnsupportedCapability(t *testing.T) {
+	t.Parallel()
+
+	_, err := endpoint(&Capabilities{}, Handle{Alias: "alice", Domain: "example.com"}, CapabilityP2PPaymentDestination)
+	require.Error(t, err)
+	assert.Equal(t, "example.com does not support P2P Payment Destination", err.Error())
+	assert.Equal(t, "unknown", CapabilityName("unknown"))
+}
@@ -0,0 +1,298 @@
+// Package scripts provides Bitcoin SV script assembly, disassembly, and
+// standard template recognition shared by the CLI tools.
+//
+// The package supports:
+//   - Disassembling script bytes into ASM with precise error offsets
+//   - Assembling ASM (opcode names and hex pushes) into script bytes
+//   - Recognizing standard templates (P2PKH, P2PK, P2SH, bare multisig, data)
+//   - Computing script hashes (SHA-256, WhatsOnChain script hash, HASH160)
+package scripts
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+)
+
+// Template names returned by Classify.
+const (
+	TemplateEmpty       = "empty"
+	TemplateP2PKH       = "p2pkh"
+	TemplateP2PK        = "p2pk"
+	TemplateP2SH        = "p2sh"
+	TemplateMultisig    = "multisig"
+	TemplateData        = "nulldata"
+	TemplateNonStandard = "nonstandard"
+)
+
+// TemplateInfo describes a recognized locking script template.
+type TemplateInfo struct {
+	Type         string   `json:"type"`                   // One of the Template* constants
+	Addresses    []string `json:"addresses,omitempty"`    // Addresses paid by the script (P2PKH, P2PK)
+	PubKeys      []string `json:"pubkeys,omitempty"`      // Public keys in the script (P2PK, multisig)
+	ScriptHash   string   `json:"scriptHash,omitempty"`   // Redeem script hash (P2SH)
+	RequiredSigs int      `json:"requiredSigs,omitempty"` // Signatures required (multisig)
+	Safe         bool     `json:"safe,omitempty"`         // Data output prefixed with OP_FALSE (unspendable)
+	Data         []string `json:"data,omitempty"`         // Hex data pushes following OP_RETURN
+}
+
+// Hashes holds the common hash representations of a script.
+type Hashes struct {
+	SHA256     string `json:"sha256"`     // SHA-256 of the script bytes
+	ScriptHash string `json:"scriptHash"` // Byte-reversed SHA-256, as used by WhatsOnChain script endpoints
+	Hash160    string `json:"hash160"`    // RIPEMD160(SHA256(script)), as used by P2SH
+}
+
+// Disassemble converts script bytes into space-separated ASM.
+// Data pushes are rendered as hex and opcodes by name. Bytes following a
+// top-level OP_RETURN that cannot be parsed as pushes are rendered as a single
+// raw hex token, since post-Genesis nodes never execute them.
+func Disassemble(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", nil
+	}
+
+	s := script.Script(b)
+	parts := make([]string, 0, len(b))
+	pos := 0
+	afterReturn := false
+	depth := 0
+
+	for pos < len(s) {
+		start := pos
+		op, err := s.ReadOp(&pos)
+		if err != nil {
+			if afterReturn {
+				parts = append(parts, hex.EncodeToString(b[start:]))
+				break
+			}
+			return "", fmt.Errorf("invalid script at byte %d: %w", start, err)
+		}
+
+		parts = append(parts, ChunkString(op))
+
+		switch op.Op {
+		case script.OpIF, script.OpNOTIF:
+			depth++
+		case script.OpENDIF:
+			depth--
+		case script.OpRETURN:
+			if depth == 0 {
+				afterReturn = true
+			}
+		}
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// ChunkString renders a single parsed script chunk as an ASM token.
+func ChunkString(op *script.ScriptChunk) string {
+	if op.Op > script.Op0 && op.Op <= script.OpPUSHDATA4 {
+		return hex.EncodeToString(op.Data)
+	}
+	// The SDK names these OP_FALSE and OP_TRUE; use the numeric form like OP_2..OP_16
+	if op.Op == script.Op0 {
+		return "OP_0"
+	}
+	if op.Op == script.Op1 {
+		return "OP_1"
+	}
+	if name, ok := script.OpCodeValues[op.Op]; ok {
+		return name
+	}
+	return fmt.Sprintf("OP_UNKNOWN%d", op.Op)
+}
+
+// Assemble converts ASM into script bytes.
+// Tokens may be opcode names (case-insensitive, the OP_ prefix is optional) or
+// hex data, which is pushed using the minimal push encoding. Explicit push
+// opcodes (OP_DATA_N, OP_PUSHDATAn) are rejected because the push length is
+// implied by the hex data that follows them.
+func Assemble(asm string) ([]byte, error) {
+	s := &script.Script{}
+
+	for i, token := range strings.Fields(asm) {
+		if op, ok := lookupOpcode(token); ok {
+			if op > script.Op0 && op <= script.OpPUSHDATA4 {
+				return nil, fmt.Errorf("token %d (%s): explicit push opcodes are not allowed, write the data as hex", i+1, token)
+			}
+			if err := s.AppendOpcodes(op); err != nil {
+				return nil, fmt.Errorf("token %d (%s): %w", i+1, token, err)
+			}
+			continue
+		}
+
+		data, err := hex.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("token %d (%s): unknown opcode or invalid hex", i+1, token)
+		}
+		if err := s.AppendPushData(data); err != nil {
+			return nil, fmt.Errorf("token %d: %w", i+1, err)
+		}
+	}
+
+	return *s, nil
+}
+
+// lookupOpcode resolves an opcode name, accepting lower case and a missing OP_ prefix.
+func lookupOpcode(token string) (byte, bool) {
+	name := strings.ToUpper(token)
+	if !strings.HasPrefix(name, "OP_") {
+		name = "OP_" + name
+	}
+	op, ok := script.OpCodeStrings[name]
+	return op, ok
+}
+
+// ValidateOpcodes checks that a script parses cleanly and contains no
+// undefined opcodes outside of OP_RETURN data. It returns one message per issue.
+func ValidateOpcodes(b []byte) []string {
+	var issues []string
+
+	s := script.Script(b)
+	pos := 0
+	depth := 0
+	for pos < len(s) {
+		start := pos
+		op, err := s.ReadOp(&pos)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("byte %d: %v", start, err))
+			return issues
+		}
+
+		switch op.Op {
+		case script.OpIF, script.OpNOTIF:
+			depth++
+		case script.OpENDIF:
+			depth--
+		case script.OpRETURN:
+			if depth == 0 {
+				return issues
+			}
+		}
+
+		if _, ok := script.OpCodeValues[op.Op]; !ok || isUndefinedOpcode(op.Op) {
+			issues = append(issues, fmt.Sprintf("byte %d: undefined opcode 0x%02x", start, op.Op))
+		}
+	}
+
+	if depth != 0 {
+		issues = append(issues, "unbalanced OP_IF/OP_ENDIF")
+	}
+
+	return issues
+}
+
+// isUndefinedOpcode reports whether an opcode has no defined behavior
+// (the OP_UNKNOWN range and the template placeholders above it).
+func isUndefinedOpcode(op byte) bool {
+	return op >= script.OpUNKNOWN186
+}
+
+// Classify recognizes the standard template of a locking script.
+// Addresses are derived for the given network.
+func Classify(b []byte, mainnet bool) TemplateInfo {
+	s := script.Script(b)
+
+	switch {
+	case len(b) == 0:
+		return TemplateInfo{Type: TemplateEmpty}
+
+	case s.IsP2PKH():
+		info := TemplateInfo{Type: TemplateP2PKH}
+		if addr, err := script.NewAddressFromPublicKeyHash(b[3:23], mainnet); err == nil {
+			info.Addresses = []string{addr.AddressString}
+		}
+		return info
+
+	case s.IsP2PK():
+		info := TemplateInfo{Type: TemplateP2PK}
+		pubKey := b[1 : len(b)-1]
+		info.PubKeys = []string{hex.EncodeToString(pubKey)}
+		if addr, err := script.NewAddressFromPublicKeyHash(crypto.Hash160(pubKey), mainnet); err == nil {
+			info.Addresses = []string{addr.AddressString}
+		}
+		return info
+
+	case s.IsP2SH():
+		return TemplateInfo{Type: TemplateP2SH, ScriptHash: hex.EncodeToString(b[2:22])}
+
+	case s.IsData():
+		return classifyData(b)
+
+	case s.IsMultiSigOut():
+		return classifyMultisig(b)
+	}
+
+	return TemplateInfo{Type: TemplateNonStandard}
+}
+
+// classifyData extracts the pushes that follow OP_RETURN in a data output.
+func classifyData(b []byte) TemplateInfo {
+	info := TemplateInfo{Type: TemplateData}
+
+	offset := 1
+	if b[0] == script.OpFALSE {
+		info.Safe = true
+		offset = 2
+	}
+
+	rest := script.Script(b[offset:])
+	pos := 0
+	for pos < len(rest) {
+		op, err := rest.ReadOp(&pos)
+		if err != nil {
+			break
+		}
+		if op.Data != nil {
+			info.Data = append(info.Data, hex.EncodeToString(op.Data))
+		}
+	}
+
+	return info
+}
+
+// classifyMultisig extracts the threshold and public keys of a bare multisig script.
+func classifyMultisig(b []byte) TemplateInfo {
+	info := TemplateInfo{Type: TemplateMultisig}
+
+	parts, err := script.DecodeScript(b)
+	if err != nil || len(parts) < 3 {
+		return TemplateInfo{Type: TemplateNonStandard}
+	}
+
+	info.RequiredSigs = smallIntValue(parts[0].Op)
+	for _, p := range parts[1 : len(parts)-2] {
+		info.PubKeys = append(info.PubKeys, hex.EncodeToString(p.Data))
+	}
+
+	return info
+}
+
+// smallIntValue returns the integer value of OP_0 or OP_1 through OP_16.
+func smallIntValue(op byte) int {
+	if op == script.Op0 {
+		return 0
+	}
+	return int(op-script.Op1) + 1
+}
+
+// ComputeHashes returns the common hash representations of a script.
+func ComputeHashes(b []byte) Hashes {
+	sum := crypto.Sha256(b)
+
+	reversed := make([]byte, len(sum))
+	for i := range sum {
+		reversed[i] = sum[len(sum)-1-i]
+	}
+
+	return Hashes{
+		SHA256:     hex.EncodeToString(sum),
+		ScriptHash: hex.EncodeToString(reversed),
+		Hash160:    hex.EncodeToString(crypto.Hash160(b)),
+	}
+}
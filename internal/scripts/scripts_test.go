@@ -0,0 +1,206 @@
+package scripts
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testP2PKHHex = "76a9146d2b9a9bd8bc45bd7e2b0c6ff5b5ffb1a83ef43a88ac"
+	testP2PKHASM = "OP_DUP OP_HASH160 6d2b9a9bd8bc45bd7e2b0c6ff5b5ffb1a83ef43a OP_EQUALVERIFY OP_CHECKSIG"
+	testPubKey   = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestDisassemble(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		hex     string
+		want    string
+		wantErr string
+	}{
+		{"empty", "", "", ""},
+		{"p2pkh", testP2PKHHex, testP2PKHASM, ""},
+		{"op_0", "00", "OP_0", ""},
+		{"small ints", "5160", "OP_1 OP_16", ""},
+		{"pushdata1", "4c03010203", "010203", ""},
+		{"data after return", "006a0568656c6c6f", "OP_0 OP_RETURN 68656c6c6f", ""},
+		{"raw bytes after return", "6a4c", "OP_RETURN 4c", ""},
+		{"truncated push", "76a914aabb", "", "invalid script at byte 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Disassemble(mustHex(t, tt.hex))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		asm     string
+		want    string
+		wantErr string
+	}{
+		{"p2pkh", testP2PKHASM, testP2PKHHex, ""},
+		{"lower case without prefix", "dup hash160 6d2b9a9bd8bc45bd7e2b0c6ff5b5ffb1a83ef43a equalverify checksig", testP2PKHHex, ""},
+		{"op_0 and return", "OP_FALSE OP_RETURN 68656c6c6f", "006a0568656c6c6f", ""},
+		{"empty", "", "", ""},
+		{"explicit push opcode", "OP_PUSHDATA1 0102", "", "explicit push opcodes"},
+		{"unknown token", "OP_DUP OP_BOGUS", "", "token 2 (OP_BOGUS)"},
+		{"odd hex", "abc", "", "token 1 (abc)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Assemble(tt.asm)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func TestAssembleDisassembleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, asm := range []string{
+		testP2PKHASM,
+		"OP_2 " + testPubKey + " " + testPubKey + " OP_2 OP_CHECKMULTISIG",
+		"OP_IF OP_1 OP_ELSE OP_0 OP_ENDIF",
+	} {
+		b, err := Assemble(asm)
+		require.NoError(t, err)
+		got, err := Disassemble(b)
+		require.NoError(t, err)
+		assert.Equal(t, asm, got)
+	}
+}
+
+func TestValidateOpcodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		hex    string
+		issues int
+		want   string
+	}{
+		{"valid p2pkh", testP2PKHHex, 0, ""},
+		{"truncated push", "76a914aabb", 1, "byte 2"},
+		{"undefined opcode", "51ba", 1, "undefined opcode 0xba"},
+		{"undefined after return ignored", "6aba", 0, ""},
+		{"unbalanced if", "6351", 1, "unbalanced"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			issues := ValidateOpcodes(mustHex(t, tt.hex))
+			require.Len(t, issues, tt.issues)
+			if tt.want != "" {
+				assert.Contains(t, issues[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("p2pkh mainnet and testnet", func(t *testing.T) {
+		t.Parallel()
+		b := mustHex(t, testP2PKHHex)
+
+		main := Classify(b, true)
+		assert.Equal(t, TemplateP2PKH, main.Type)
+		require.Len(t, main.Addresses, 1)
+		assert.Equal(t, "1", main.Addresses[0][:1])
+
+		test := Classify(b, false)
+		require.Len(t, test.Addresses, 1)
+		assert.Contains(t, "mn", test.Addresses[0][:1])
+	})
+
+	t.Run("p2pk", func(t *testing.T) {
+		t.Parallel()
+		info := Classify(mustHex(t, "21"+testPubKey+"ac"), true)
+		assert.Equal(t, TemplateP2PK, info.Type)
+		assert.Equal(t, []string{testPubKey}, info.PubKeys)
+		assert.Equal(t, []string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"}, info.Addresses)
+	})
+
+	t.Run("p2sh", func(t *testing.T) {
+		t.Parallel()
+		info := Classify(mustHex(t, "a914"+"11223344556677889900aabbccddeeff00112233"+"87"), true)
+		assert.Equal(t, TemplateP2SH, info.Type)
+		assert.Equal(t, "11223344556677889900aabbccddeeff00112233", info.ScriptHash)
+	})
+
+	t.Run("safe data", func(t *testing.T) {
+		t.Parallel()
+		info := Classify(mustHex(t, "006a0568656c6c6f02abcd"), true)
+		assert.Equal(t, TemplateData, info.Type)
+		assert.True(t, info.Safe)
+		assert.Equal(t, []string{"68656c6c6f", "abcd"}, info.Data)
+	})
+
+	t.Run("multisig", func(t *testing.T) {
+		t.Parallel()
+		b, err := Assemble("OP_1 " + testPubKey + " " + testPubKey + " OP_2 OP_CHECKMULTISIG")
+		require.NoError(t, err)
+		info := Classify(b, true)
+		assert.Equal(t, TemplateMultisig, info.Type)
+		assert.Equal(t, 1, info.RequiredSigs)
+		assert.Len(t, info.PubKeys, 2)
+	})
+
+	t.Run("empty and nonstandard", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, TemplateEmpty, Classify(nil, true).Type)
+		assert.Equal(t, TemplateNonStandard, Classify(mustHex(t, "5193"), true).Type)
+	})
+}
+
+func TestComputeHashes(t *testing.T) {
+	t.Parallel()
+
+	h := ComputeHashes(nil)
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", h.SHA256)
+	assert.Equal(t, "55b852781b9995a44c939b64e441ae2724b96f99c8f4fb9a141cfc9842c4b0e3", h.ScriptHash)
+	assert.Equal(t, "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb", h.Hash160)
+}
+
+func BenchmarkDisassemble(b *testing.B) {
+	script, _ := hex.DecodeString(testP2PKHHex)
+	for i := 0; i < b.N; i++ {
+		_, _ = Disassemble(script)
+	}
+}
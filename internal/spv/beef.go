@@ -0,0 +1,119 @@
+package spv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// BEEFReport summarizes a structurally valid BEEF.
+type BEEFReport struct {
+	Version      uint32                     `json:"version"`
+	Atomic       bool                       `json:"atomic"`
+	Transactions int                        `json:"transactions"`
+	BUMPs        int                        `json:"bumps"`
+	Roots        map[uint32]*chainhash.Hash `json:"roots"` // Merkle root each block's BUMPs commit to, by height
+}
+
+// Heights returns the block heights of the report's roots in ascending order.
+func (r *BEEFReport) Heights() []uint32 {
+	heights := make([]uint32, 0, len(r.Roots))
+	for height := range r.Roots {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// ValidateBEEF checks that a BEEF is complete and self-consistent: every
+// unproven transaction's inputs are in the BEEF, every ancestry ends in a
+// transaction with a BUMP, each BUMP holds the transactions that reference it,
+// and all BUMPs for a block agree on its merkle root.
+//
+// The roots are not checked against any block headers; callers with a header
+// source compare Roots to it.
+func ValidateBEEF(b []byte) (*BEEFReport, error) {
+	if len(b) < 4 || !isBEEFVersion(binary.LittleEndian.Uint32(b[:4])) {
+		return nil, fmt.Errorf("not a BEEF: unknown version prefix")
+	}
+	report := &BEEFReport{Version: binary.LittleEndian.Uint32(b[:4]), Roots: make(map[uint32]*chainhash.Hash)}
+
+	if report.Version == transaction.ATOMIC_BEEF {
+		if len(b) < 40 {
+			return nil, fmt.Errorf("atomic BEEF is too short")
+		}
+		report.Atomic = true
+		report.Version = binary.LittleEndian.Uint32(b[36:40])
+	}
+
+	beef, err := transaction.NewBeefFromBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing BEEF: %w", err)
+	}
+	report.Transactions = len(beef.Transactions)
+	report.BUMPs = len(beef.BUMPs)
+
+	if report.Atomic {
+		subject, err := chainhash.NewHash(b[4:36])
+		if err != nil {
+			return nil, fmt.Errorf("invalid atomic txid: %w", err)
+		}
+		if beef.FindTransactionByHash(subject) == nil {
+			return nil, fmt.Errorf("atomic subject %s is not in the BEEF", subject)
+		}
+	}
+
+	vr := beef.ValidateTransactions()
+	switch {
+	case len(vr.MissingInputs) > 0:
+		return nil, fmt.Errorf("missing source transaction(s): %s", strings.Join(vr.MissingInputs, ", "))
+	case len(vr.TxidOnly) > 0:
+		return nil, fmt.Errorf("txid-only transaction(s): %s", strings.Join(vr.TxidOnly, ", "))
+	case len(vr.NotValid) > 0:
+		return nil, fmt.Errorf("transaction(s) without a proven ancestry: %s", strings.Join(vr.NotValid, ", "))
+	}
+
+	for i, mp := range beef.BUMPs {
+		if len(mp.Path) == 0 {
+			return nil, fmt.Errorf("BUMP %d has no levels", i)
+		}
+		for _, leaf := range mp.Path[0] {
+			if leaf.Txid == nil || !*leaf.Txid || leaf.Hash == nil {
+				continue
+			}
+			root, err := mp.ComputeRoot(leaf.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("BUMP %d: %w", i, err)
+			}
+			if existing, ok := report.Roots[mp.BlockHeight]; ok && !existing.IsEqual(root) {
+				return nil, fmt.Errorf("BUMPs disagree on the merkle root of block %d", mp.BlockHeight)
+			}
+			report.Roots[mp.BlockHeight] = root
+		}
+	}
+
+	for txid, btx := range beef.Transactions {
+		if btx.DataFormat != transaction.RawTxAndBumpIndex {
+			continue
+		}
+		if btx.BumpIndex < 0 || btx.BumpIndex >= len(beef.BUMPs) || !hasLeaf(beef.BUMPs[btx.BumpIndex], txid) {
+			return nil, fmt.Errorf("transaction %s is not in its BUMP", txid)
+		}
+	}
+
+	return report, nil
+}
+
+// hasLeaf reports whether txid is a leaf of mp.
+func hasLeaf(mp *transaction.MerklePath, txid chainhash.Hash) bool {
+	for _, leaf := range mp.Path[0] {
+		if leaf.Hash != nil && leaf.Hash.IsEqual(&txid) {
+			return true
+		}
+	}
+	return false
+}
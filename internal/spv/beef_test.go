@@ -0,0 +1,88 @@
+package spv
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/spv/spvtest"
+)
+
+func TestValidateBEEF(t *testing.T) {
+	t.Parallel()
+
+	coinbase, _, grandchild := provenChain(t)
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		b, err := grandchild.BEEF()
+		require.NoError(t, err)
+
+		report, err := ValidateBEEF(b)
+		require.NoError(t, err)
+		assert.Equal(t, transaction.BEEF_V1, report.Version)
+		assert.False(t, report.Atomic)
+		assert.Equal(t, 3, report.Transactions)
+		assert.Equal(t, 1, report.BUMPs)
+		assert.Equal(t, []uint32{0}, report.Heights())
+		assert.Equal(t, spvtest.GenesisTxID, report.Roots[0].String())
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		t.Parallel()
+		beef, err := transaction.NewBeefFromTransaction(grandchild)
+		require.NoError(t, err)
+		b, err := beef.AtomicBytes(grandchild.TxID())
+		require.NoError(t, err)
+
+		report, err := ValidateBEEF(b)
+		require.NoError(t, err)
+		assert.True(t, report.Atomic)
+		assert.Equal(t, transaction.BEEF_V2, report.Version)
+
+		// The named subject must be present
+		other := chainhash.Hash{0x01}
+		b, err = beef.AtomicBytes(&other)
+		require.NoError(t, err)
+		_, err = ValidateBEEF(b)
+		require.ErrorContains(t, err, "not in the BEEF")
+	})
+
+	t.Run("missing ancestor", func(t *testing.T) {
+		t.Parallel()
+		beef, err := transaction.NewBeefFromTransaction(grandchild)
+		require.NoError(t, err)
+		beef.RemoveExistingTxid(coinbase.TxID())
+		b, err := beef.Bytes()
+		require.NoError(t, err)
+
+		_, err = ValidateBEEF(b)
+		require.ErrorContains(t, err, "missing source transaction")
+	})
+
+	t.Run("conflicting roots", func(t *testing.T) {
+		t.Parallel()
+		beef, err := transaction.NewBeefFromTransaction(grandchild)
+		require.NoError(t, err)
+		other, err := TSCPath("0000000000000000000000000000000000000000000000000000000000000001", 0, nil, 0)
+		require.NoError(t, err)
+		beef.BUMPs = append(beef.BUMPs, other)
+		b, err := beef.Bytes()
+		require.NoError(t, err)
+
+		_, err = ValidateBEEF(b)
+		require.ErrorContains(t, err, "disagree on the merkle root of block 0")
+	})
+
+	t.Run("not BEEF", func(t *testing.T) {
+		t.Parallel()
+		_, err := ValidateBEEF(grandchild.Bytes())
+		require.ErrorContains(t, err, "not a BEEF")
+
+		_, err = ValidateBEEF([]byte{0x01, 0x00})
+		require.ErrorContains(t, err, "not a BEEF")
+	})
+}
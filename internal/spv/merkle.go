@@ -0,0 +1,99 @@
+package spv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// DuplicateNode marks a TSC proof node that duplicates the working hash.
+const DuplicateNode = "*"
+
+// TSCRoot folds TSC merkle proof nodes into a merkle root.
+// Node hashes are in display (reversed) byte order; "*" duplicates the working hash.
+func TSCRoot(txid string, index int, nodes []string) (*chainhash.Hash, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("invalid proof index %d", index)
+	}
+
+	working, err := chainhash.NewHashFromHex(txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid txid: %w", err)
+	}
+
+	for i, node := range nodes {
+		sibling := working
+		if node != DuplicateNode {
+			if sibling, err = chainhash.NewHashFromHex(node); err != nil {
+				return nil, fmt.Errorf("node %d: %w", i, err)
+			}
+		}
+
+		if index&1 == 1 {
+			working = transaction.MerkleTreeParent(sibling, working)
+		} else {
+			working = transaction.MerkleTreeParent(working, sibling)
+		}
+		index >>= 1
+	}
+
+	return working, nil
+}
+
+// TSCPath converts a TSC merkle proof into a BUMP (BRC-74) merkle path for the
+// block at height, so TSC proofs can be embedded in BEEF.
+func TSCPath(txid string, index int, nodes []string, height uint32) (*transaction.MerklePath, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("invalid proof index %d", index)
+	}
+
+	hash, err := chainhash.NewHashFromHex(txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid txid: %w", err)
+	}
+
+	isTxid := true
+	leaf := &transaction.PathElement{Offset: uint64(index), Hash: hash, Txid: &isTxid}
+	path := make([][]*transaction.PathElement, max(len(nodes), 1))
+	path[0] = []*transaction.PathElement{leaf}
+
+	for level, node := range nodes {
+		sibling := &transaction.PathElement{Offset: uint64(index>>level) ^ 1}
+		if node == DuplicateNode {
+			duplicate := true
+			sibling.Duplicate = &duplicate
+		} else if sibling.Hash, err = chainhash.NewHashFromHex(node); err != nil {
+			return nil, fmt.Errorf("node %d: %w", level, err)
+		}
+		path[level] = append(path[level], sibling)
+	}
+
+	return transaction.NewMerklePath(height, path), nil
+}
+
+// ParseBUMP decodes a hex BUMP (BRC-74) merkle path.
+func ParseBUMP(bumpHex string) (*transaction.MerklePath, error) {
+	mp, err := transaction.NewMerklePathFromHex(strings.TrimSpace(bumpHex))
+	if err != nil {
+		return nil, fmt.Errorf("parsing BUMP: %w", err)
+	}
+	if len(mp.Path) == 0 {
+		return nil, fmt.Errorf("parsing BUMP: path has no levels")
+	}
+	return mp, nil
+}
+
+// BUMPRoot computes the merkle root a BUMP commits to for txid.
+func BUMPRoot(mp *transaction.MerklePath, txid string) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromHex(txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid txid: %w", err)
+	}
+	root, err := mp.ComputeRoot(hash)
+	if err != nil {
+		return nil, fmt.Errorf("computing root from BUMP: %w", err)
+	}
+	return root, nil
+}
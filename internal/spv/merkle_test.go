@@ -0,0 +1,152 @@
+package spv
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/spv/spvtest"
+)
+
+// mustHash parses a display-order hash.
+func mustHash(t *testing.T, s string) *chainhash.Hash {
+	t.Helper()
+	h, err := chainhash.NewHashFromHex(s)
+	require.NoError(t, err)
+	return h
+}
+
+func TestTSCRoot(t *testing.T) {
+	t.Parallel()
+
+	// Build a three-leaf tree: root = H(H(a,b), H(c,c))
+	a := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	b := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000002")
+	cc := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000003")
+	ab := transaction.MerkleTreeParent(a, b)
+	ccDup := transaction.MerkleTreeParent(cc, cc)
+	root := transaction.MerkleTreeParent(ab, ccDup)
+
+	tests := []struct {
+		name  string
+		txid  *chainhash.Hash
+		index int
+		nodes []string
+	}{
+		{"left leaf", a, 0, []string{b.String(), ccDup.String()}},
+		{"right leaf", b, 1, []string{a.String(), ccDup.String()}},
+		{"duplicated last leaf", cc, 2, []string{DuplicateNode, ab.String()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := TSCRoot(tt.txid.String(), tt.index, tt.nodes)
+			require.NoError(t, err)
+			assert.Equal(t, root.String(), got.String())
+		})
+	}
+
+	t.Run("single transaction block", func(t *testing.T) {
+		t.Parallel()
+		got, err := TSCRoot(spvtest.GenesisTxID, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, spvtest.GenesisTxID, got.String())
+	})
+
+	t.Run("wrong index", func(t *testing.T) {
+		t.Parallel()
+		got, err := TSCRoot(a.String(), 1, []string{b.String(), ccDup.String()})
+		require.NoError(t, err)
+		assert.NotEqual(t, root.String(), got.String())
+	})
+
+	t.Run("invalid node", func(t *testing.T) {
+		t.Parallel()
+		_, err := TSCRoot(a.String(), 0, []string{"zz"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "node 0")
+	})
+}
+
+func TestTSCPath(t *testing.T) {
+	t.Parallel()
+
+	a := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	b := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000002")
+	cc := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000003")
+	ab := transaction.MerkleTreeParent(a, b)
+	ccDup := transaction.MerkleTreeParent(cc, cc)
+	root := transaction.MerkleTreeParent(ab, ccDup)
+
+	tests := []struct {
+		name  string
+		txid  *chainhash.Hash
+		index int
+		nodes []string
+	}{
+		{"left leaf", a, 0, []string{b.String(), ccDup.String()}},
+		{"right leaf", b, 1, []string{a.String(), ccDup.String()}},
+		{"duplicated last leaf", cc, 2, []string{DuplicateNode, ab.String()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mp, err := TSCPath(tt.txid.String(), tt.index, tt.nodes, 800000)
+			require.NoError(t, err)
+			assert.Equal(t, uint32(800000), mp.BlockHeight)
+
+			// The path survives a BUMP round trip and folds to the same root
+			parsed, err := transaction.NewMerklePathFromHex(mp.Hex())
+			require.NoError(t, err)
+			got, err := parsed.ComputeRoot(tt.txid)
+			require.NoError(t, err)
+			assert.Equal(t, root.String(), got.String())
+		})
+	}
+
+	t.Run("single transaction block", func(t *testing.T) {
+		t.Parallel()
+		mp, err := TSCPath(spvtest.GenesisTxID, 0, nil, 0)
+		require.NoError(t, err)
+		got, err := mp.ComputeRoot(mustHash(t, spvtest.GenesisTxID))
+		require.NoError(t, err)
+		assert.Equal(t, spvtest.GenesisTxID, got.String())
+	})
+
+	t.Run("invalid node", func(t *testing.T) {
+		t.Parallel()
+		_, err := TSCPath(a.String(), 0, []string{"zz"}, 1)
+		require.ErrorContains(t, err, "node 0")
+	})
+}
+
+func TestParseBUMP(t *testing.T) {
+	t.Parallel()
+
+	a := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	b := mustHash(t, "0000000000000000000000000000000000000000000000000000000000000002")
+	mp, err := TSCPath(a.String(), 0, []string{b.String()}, 100)
+	require.NoError(t, err)
+
+	parsed, err := ParseBUMP(" " + mp.Hex() + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), parsed.BlockHeight)
+
+	root, err := BUMPRoot(parsed, a.String())
+	require.NoError(t, err)
+	assert.Equal(t, transaction.MerkleTreeParent(a, b).String(), root.String())
+
+	_, err = BUMPRoot(parsed, "0000000000000000000000000000000000000000000000000000000000000003")
+	require.ErrorContains(t, err, "computing root from BUMP")
+
+	_, err = BUMPRoot(parsed, "zz")
+	require.ErrorContains(t, err, "invalid txid")
+
+	_, err = ParseBUMP("00")
+	require.ErrorContains(t, err, "parsing BUMP")
+}
@@ -0,0 +1,210 @@
+package spv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+// DefaultMaxDepth is the default limit on unconfirmed ancestry when assembling BEEF.
+const DefaultMaxDepth = 10
+
+// ErrNoFetch is returned when a transaction is missing and fetching is disabled.
+var ErrNoFetch = errors.New("fetching is disabled")
+
+// Resolver completes transactions for EF and BEEF by fetching missing source
+// transactions and merkle proofs from WhatsOnChain. Each transaction and proof
+// is fetched at most once.
+type Resolver struct {
+	NoFetch  bool // Fail with ErrNoFetch instead of fetching
+	MaxDepth int  // Maximum unconfirmed ancestry depth for BEEF
+	Fetched  int  // Transactions and proofs fetched so far
+
+	// Txs, when set, fetches transactions in place of WhatsOnChain, as from
+	// the data provider in config.yaml. Merkle proofs still come from WhatsOnChain.
+	Txs chain.TxFetcher
+
+	client whatsonchain.ClientInterface
+	txs    map[string]*transaction.Transaction
+	proven map[string]bool
+}
+
+// NewResolver creates a Resolver that fetches from client.
+func NewResolver(client whatsonchain.ClientInterface) *Resolver {
+	return &Resolver{
+		MaxDepth: DefaultMaxDepth,
+		client:   client,
+		txs:      make(map[string]*transaction.Transaction),
+		proven:   make(map[string]bool),
+	}
+}
+
+// EF serializes tx in Extended Format, fetching the source transaction of
+// every input whose source output is not already known.
+func (r *Resolver) EF(ctx context.Context, tx *transaction.Transaction) (string, error) {
+	if err := r.Sources(ctx, tx); err != nil {
+		return "", err
+	}
+	ef, err := tx.EFHex()
+	if err != nil {
+		return "", fmt.Errorf("serializing EF: %w", err)
+	}
+	return ef, nil
+}
+
+// Sources fetches the source transaction of every input whose source output
+// is not already known, so each input's locking script and value are set.
+func (r *Resolver) Sources(ctx context.Context, tx *transaction.Transaction) error {
+	return r.attachSources(ctx, tx, true, false)
+}
+
+// BEEF serializes tx as BEEF, fetching unconfirmed ancestors until every path
+// ends in a transaction with a merkle proof. Ancestors already linked to tx
+// keep the proofs they came with.
+func (r *Resolver) BEEF(ctx context.Context, tx *transaction.Transaction) (string, error) {
+	if err := r.prove(ctx, tx, r.MaxDepth); err != nil {
+		return "", err
+	}
+	beef, err := tx.BEEFHex()
+	if err != nil {
+		return "", fmt.Errorf("serializing BEEF: %w", err)
+	}
+	return beef, nil
+}
+
+// Fetch returns a transaction by txid, fetching it once. With proof, its
+// merkle path is also looked up; unconfirmed transactions are left without one.
+func (r *Resolver) Fetch(ctx context.Context, txid string, proof bool) (*transaction.Transaction, error) {
+	tx, ok := r.txs[txid]
+	if !ok {
+		if r.NoFetch {
+			return nil, fmt.Errorf("transaction %s is missing: %w", txid, ErrNoFetch)
+		}
+
+		raw, err := r.raw(ctx, txid)
+		if err != nil {
+			return nil, err
+		}
+		if tx, err = transaction.NewTransactionFromHex(raw); err != nil {
+			return nil, fmt.Errorf("parsing transaction %s: %w", txid, err)
+		}
+		if tx.TxID().String() != txid {
+			return nil, fmt.Errorf("fetched transaction hashes to %s, expected %s", tx.TxID(), txid)
+		}
+		r.Fetched++
+		r.txs[txid] = tx
+	}
+
+	if proof && !r.proven[txid] && tx.MerklePath == nil {
+		mp, err := r.proof(ctx, txid)
+		if err != nil {
+			return nil, err
+		}
+		tx.MerklePath = mp
+		r.proven[txid] = true
+	}
+	return tx, nil
+}
+
+// raw fetches the raw transaction hex for txid from Txs, or WhatsOnChain.
+func (r *Resolver) raw(ctx context.Context, txid string) (string, error) {
+	if r.Txs != nil {
+		return r.Txs.RawTx(ctx, txid)
+	}
+	raw, err := r.client.GetRawTransactionData(ctx, txid)
+	if err != nil {
+		return "", fmt.Errorf("fetching transaction %s: %w", txid, err)
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("transaction %s not found", txid)
+	}
+	return raw, nil
+}
+
+// attachSources sets the source transaction of every input that has neither
+// a source transaction nor, when outputsSuffice, a source output from EF.
+func (r *Resolver) attachSources(ctx context.Context, tx *transaction.Transaction, outputsSuffice, proofs bool) error {
+	if tx.IsCoinbase() {
+		return nil
+	}
+	for i, in := range tx.Inputs {
+		if in.SourceTransaction != nil || (outputsSuffice && in.SourceTxOutput() != nil) {
+			continue
+		}
+		parent, err := r.Fetch(ctx, in.SourceTXID.String(), proofs)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		if int(in.SourceTxOutIndex) >= len(parent.Outputs) {
+			return fmt.Errorf("input %d: %s has no output %d", i, in.SourceTXID, in.SourceTxOutIndex)
+		}
+		in.SourceTransaction = parent
+	}
+	return nil
+}
+
+// prove links tx to ancestors until every path ends in a transaction with a
+// merkle proof. depth bounds the unconfirmed ancestry.
+func (r *Resolver) prove(ctx context.Context, tx *transaction.Transaction, depth int) error {
+	if tx.MerklePath != nil {
+		return nil
+	}
+	if depth < 0 {
+		return fmt.Errorf("transaction %s: unconfirmed ancestry deeper than %d", tx.TxID(), r.MaxDepth)
+	}
+	if tx.IsCoinbase() {
+		return fmt.Errorf("transaction %s: unconfirmed coinbase", tx.TxID())
+	}
+
+	if err := r.attachSources(ctx, tx, false, true); err != nil {
+		return err
+	}
+	for _, in := range tx.Inputs {
+		if err := r.prove(ctx, in.SourceTransaction, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proof returns a transaction's merkle path built from its TSC proof, or nil
+// if it is unconfirmed.
+func (r *Resolver) proof(ctx context.Context, txid string) (*transaction.MerklePath, error) {
+	results, err := r.client.GetMerkleProofTSC(ctx, txid)
+	if err != nil {
+		return nil, fmt.Errorf("fetching proof for %s: %w", txid, err)
+	}
+	if len(results) == 0 || results[0] == nil {
+		return nil, nil
+	}
+	p := results[0]
+
+	// The target is a block hash, or a full header when requested that way
+	target := p.Target
+	if len(target) == block.HeaderSize*2 {
+		header, err := block.NewHeaderFromHex(target)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proof target for %s: %w", txid, err)
+		}
+		target = header.Hash().String()
+	}
+	info, err := r.client.GetHeaderByHash(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("fetching header %s: %w", target, err)
+	}
+	r.Fetched++
+
+	mp, err := TSCPath(txid, p.Index, p.Nodes, uint32(info.Height)) //nolint:gosec // block heights fit in uint32
+	if err != nil {
+		return nil, fmt.Errorf("proof for %s: %w", txid, err)
+	}
+	return mp, nil
+}
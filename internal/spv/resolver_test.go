@@ -0,0 +1,122 @@
+package spv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+	"github.com/mrz1836/go-template/internal/spv/spvtest"
+)
+
+func TestResolverEF(t *testing.T) {
+	t.Parallel()
+
+	client, child, _ := spvtest.Chain(t)
+
+	r := NewResolver(client)
+	ef, err := r.EF(context.Background(), child)
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Fetched)
+
+	parsed, format, err := ParseTransaction(mustDecode(t, ef))
+	require.NoError(t, err)
+	assert.Equal(t, FormatEF, format)
+	assert.Equal(t, uint64(5000000000), *parsed.Inputs[0].SourceTxSatoshis())
+
+	// Source outputs from EF are enough; nothing more is fetched
+	r = NewResolver(client)
+	r.NoFetch = true
+	_, err = r.EF(context.Background(), parsed)
+	require.NoError(t, err)
+
+	_, err = r.EF(context.Background(), spvtest.Spend(t, child, 1))
+	require.ErrorIs(t, err, ErrNoFetch)
+}
+
+func TestResolverBEEF(t *testing.T) {
+	t.Parallel()
+
+	client, child, grandchild := spvtest.Chain(t)
+
+	beef, err := NewResolver(client).BEEF(context.Background(), grandchild)
+	require.NoError(t, err)
+
+	tx, err := transaction.NewTransactionFromBEEFHex(beef)
+	require.NoError(t, err)
+	assert.Equal(t, grandchild.TxID().String(), tx.TxID().String())
+	parent := tx.Inputs[0].SourceTransaction
+	require.NotNil(t, parent)
+	assert.Equal(t, child.TxID().String(), parent.TxID().String())
+	assert.Nil(t, parent.MerklePath)
+	grandparent := parent.Inputs[0].SourceTransaction
+	require.NotNil(t, grandparent)
+	require.NotNil(t, grandparent.MerklePath)
+
+	report, err := ValidateBEEF(mustDecode(t, beef))
+	require.NoError(t, err)
+	assert.Equal(t, spvtest.GenesisTxID, report.Roots[0].String())
+}
+
+func TestResolverDepth(t *testing.T) {
+	t.Parallel()
+
+	client, _, grandchild := spvtest.Chain(t)
+
+	r := NewResolver(client)
+	r.MaxDepth = 0
+	_, err := r.BEEF(context.Background(), grandchild)
+	require.ErrorContains(t, err, "deeper than 0")
+
+	r = NewResolver(client)
+	r.MaxDepth = 1
+	_, err = r.BEEF(context.Background(), grandchild)
+	require.NoError(t, err)
+}
+
+func TestResolverFetch(t *testing.T) {
+	t.Parallel()
+
+	client, _, _ := spvtest.Chain(t)
+	r := NewResolver(client)
+
+	tx, err := r.Fetch(context.Background(), spvtest.GenesisTxID, false)
+	require.NoError(t, err)
+	assert.Nil(t, tx.MerklePath)
+
+	// The proof is looked up once it is asked for, without refetching the transaction
+	requests := client.Requests()
+	tx, err = r.Fetch(context.Background(), spvtest.GenesisTxID, true)
+	require.NoError(t, err)
+	assert.NotNil(t, tx.MerklePath)
+	assert.Equal(t, requests+2, client.Requests())
+
+	_, err = r.Fetch(context.Background(), spvtest.GenesisTxID, true)
+	require.NoError(t, err)
+	assert.Equal(t, requests+2, client.Requests())
+
+	_, err = r.Fetch(context.Background(), "00", false)
+	require.ErrorContains(t, err, "fetching transaction")
+}
+
+func TestResolverTxs(t *testing.T) {
+	t.Parallel()
+
+	client, child, _ := spvtest.Chain(t)
+	txs := chain.NewMock()
+	txs.Raw[spvtest.GenesisTxID] = spvtest.GenesisCoinbase
+
+	// Transactions come from Txs, leaving the client for proofs
+	r := NewResolver(client)
+	r.Txs = txs
+	_, err := r.EF(context.Background(), child)
+	require.NoError(t, err)
+	assert.Equal(t, []string{spvtest.GenesisTxID}, txs.Fetched)
+	assert.Zero(t, client.Requests())
+
+	_, err = r.Fetch(context.Background(), "00", false)
+	require.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,132 @@
+// Package spv provides the SPV and transaction format logic shared by BSV CLI tools.
+//
+// Transactions move between tools as raw hex, Extended Format (EF, BRC-30) or
+// BEEF (BRC-62, BRC-95, BRC-96); merkle proofs arrive as TSC JSON or as BUMPs
+// (BRC-74). This package keeps the conversions between them in one place.
+//
+// The package supports:
+//   - Detecting and parsing raw, EF, and BEEF (V1, V2, Atomic) transactions
+//   - Computing merkle roots from TSC proofs and BUMPs
+//   - Converting TSC proofs to BUMPs
+//   - Validating BEEF ancestry and BUMP consistency
+//   - Assembling EF and BEEF by fetching missing sources and proofs
+package spv
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Transaction formats
+const (
+	FormatRaw  = "raw"
+	FormatEF   = "ef"
+	FormatBEEF = "beef"
+	FormatTxID = "txid"
+)
+
+// efMarker follows the version in an Extended Format transaction.
+var efMarker = []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0xef}
+
+// DetectFormat identifies the format of hex-decoded transaction bytes.
+// Exactly 32 bytes is taken to be a txid.
+func DetectFormat(b []byte) string {
+	switch {
+	case len(b) == chainhash.HashSize:
+		return FormatTxID
+	case len(b) >= 4 && isBEEFVersion(binary.LittleEndian.Uint32(b[:4])):
+		return FormatBEEF
+	case len(b) >= 10 && string(b[4:10]) == string(efMarker):
+		return FormatEF
+	default:
+		return FormatRaw
+	}
+}
+
+// isBEEFVersion reports whether v is a BEEF version prefix.
+func isBEEFVersion(v uint32) bool {
+	return v == transaction.BEEF_V1 || v == transaction.BEEF_V2 || v == transaction.ATOMIC_BEEF
+}
+
+// ParseTransaction parses raw, EF, or BEEF transaction bytes and reports the
+// detected format. Transactions parsed from EF carry their source outputs and
+// those parsed from BEEF carry their ancestors.
+func ParseTransaction(b []byte) (*transaction.Transaction, string, error) {
+	format := DetectFormat(b)
+	switch format {
+	case FormatTxID:
+		return nil, format, fmt.Errorf("input is a txid, not a transaction")
+
+	case FormatBEEF:
+		tx, err := parseBEEF(b)
+		if err != nil {
+			return nil, format, fmt.Errorf("parsing BEEF: %w", err)
+		}
+		return tx, format, nil
+
+	default:
+		tx, err := transaction.NewTransactionFromBytes(b)
+		if err != nil {
+			return nil, format, fmt.Errorf("parsing transaction: %w", err)
+		}
+		return tx, format, nil
+	}
+}
+
+// parseBEEF returns the subject transaction of a BEEF with its ancestors
+// linked. V2 BEEF does not name its subject, so it must be the only
+// transaction that no other transaction in the BEEF spends.
+func parseBEEF(b []byte) (*transaction.Transaction, error) {
+	if binary.LittleEndian.Uint32(b[:4]) != transaction.BEEF_V2 {
+		tx, err := transaction.NewTransactionFromBEEF(b)
+		if err == nil && tx == nil {
+			err = fmt.Errorf("subject transaction not found")
+		}
+		return tx, err
+	}
+
+	beef, err := transaction.NewBeefFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := beefSubject(beef)
+	if err != nil {
+		return nil, err
+	}
+	tx := beef.FindAtomicTransaction(subject.String())
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s has no raw transaction", subject)
+	}
+	return tx, nil
+}
+
+// beefSubject finds the one transaction in beef that no other spends.
+func beefSubject(beef *transaction.Beef) (*chainhash.Hash, error) {
+	spent := make(map[chainhash.Hash]bool)
+	for _, btx := range beef.Transactions {
+		if btx.Transaction == nil {
+			continue
+		}
+		for _, in := range btx.Transaction.Inputs {
+			spent[*in.SourceTXID] = true
+		}
+	}
+
+	var subject *chainhash.Hash
+	for txid := range beef.Transactions {
+		if spent[txid] {
+			continue
+		}
+		if subject != nil {
+			return nil, fmt.Errorf("BEEF holds more than one unspent transaction; use Atomic BEEF to name the subject")
+		}
+		subject = &txid
+	}
+	if subject == nil {
+		return nil, fmt.Errorf("BEEF holds no transactions")
+	}
+	return subject, nil
+}
@@ -0,0 +1,119 @@
+package spv
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/spv/spvtest"
+)
+
+// mustDecode decodes a hex string.
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// provenChain returns the genesis coinbase with its merkle path, an unconfirmed
+// child, and an unbroadcast grandchild linked to their sources.
+func provenChain(t *testing.T) (*transaction.Transaction, *transaction.Transaction, *transaction.Transaction) {
+	t.Helper()
+
+	coinbase, err := transaction.NewTransactionFromHex(spvtest.GenesisCoinbase)
+	require.NoError(t, err)
+	coinbase.MerklePath, err = TSCPath(spvtest.GenesisTxID, 0, nil, 0)
+	require.NoError(t, err)
+
+	child := spvtest.Spend(t, coinbase, 4000000000)
+	child.Inputs[0].SourceTransaction = coinbase
+	grandchild := spvtest.Spend(t, child, 3000000000)
+	grandchild.Inputs[0].SourceTransaction = child
+	return coinbase, child, grandchild
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, FormatRaw, DetectFormat(mustDecode(t, spvtest.GenesisCoinbase)))
+	assert.Equal(t, FormatTxID, DetectFormat(make([]byte, 32)))
+	assert.Equal(t, FormatBEEF, DetectFormat([]byte{0x01, 0x00, 0xbe, 0xef, 0x00}))
+	assert.Equal(t, FormatBEEF, DetectFormat([]byte{0x02, 0x00, 0xbe, 0xef, 0x00}))
+	assert.Equal(t, FormatBEEF, DetectFormat([]byte{0x01, 0x01, 0x01, 0x01, 0x00}))
+	assert.Equal(t, FormatEF, DetectFormat([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xef, 0x01}))
+	assert.Equal(t, FormatRaw, DetectFormat(nil))
+}
+
+func TestParseTransaction(t *testing.T) {
+	t.Parallel()
+
+	_, child, grandchild := provenChain(t)
+	want := grandchild.TxID().String()
+
+	ef, err := grandchild.EF()
+	require.NoError(t, err)
+	v1, err := grandchild.BEEF()
+	require.NoError(t, err)
+	beef, err := transaction.NewBeefFromTransaction(grandchild)
+	require.NoError(t, err)
+	v2, err := beef.Bytes()
+	require.NoError(t, err)
+	atomic, err := beef.AtomicBytes(grandchild.TxID())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		format  string
+		sources bool
+	}{
+		{"raw", grandchild.Bytes(), FormatRaw, false},
+		{"extended format", ef, FormatEF, false},
+		{"BEEF V1", v1, FormatBEEF, true},
+		{"BEEF V2", v2, FormatBEEF, true},
+		{"atomic BEEF", atomic, FormatBEEF, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tx, format, err := ParseTransaction(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, want, tx.TxID().String())
+			if tt.sources {
+				require.NotNil(t, tx.Inputs[0].SourceTransaction)
+				assert.Equal(t, child.TxID().String(), tx.Inputs[0].SourceTransaction.TxID().String())
+			}
+		})
+	}
+
+	t.Run("txid", func(t *testing.T) {
+		t.Parallel()
+		_, format, err := ParseTransaction(grandchild.TxID().CloneBytes())
+		require.ErrorContains(t, err, "not a transaction")
+		assert.Equal(t, FormatTxID, format)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := ParseTransaction(grandchild.Bytes()[:20])
+		require.ErrorContains(t, err, "parsing transaction")
+	})
+
+	t.Run("V2 without a single subject", func(t *testing.T) {
+		t.Parallel()
+		other := spvtest.Spend(t, child, 1)
+		other.Inputs[0].SourceTransaction = child
+		_, err := beef.MergeTransaction(other)
+		require.NoError(t, err)
+		b, err := beef.Bytes()
+		require.NoError(t, err)
+		_, _, err = ParseTransaction(b)
+		require.ErrorContains(t, err, "more than one unspent transaction")
+	})
+}
@@ -0,0 +1,115 @@
+// Package spvtest provides a small chain of transactions rooted in the genesis
+// coinbase, and a fake WhatsOnChain client serving it, for testing code built on
+// the spv package without network access.
+package spvtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/mrz1836/go-whatsonchain"
+)
+
+// The genesis block's coinbase transaction, which is the only transaction in
+// its block, so its TSC proof is empty.
+const (
+	GenesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	GenesisTxID     = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	GenesisHash     = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+)
+
+// spendLockingScript is the P2PKH locking script of every output Spend creates.
+const spendLockingScript = "76a914e2a623699e81b291c0327f408fea765d534baa2a88ac"
+
+// Client is a fake WhatsOnChain client serving raw transactions, and
+// single-transaction-block TSC proofs for the transactions listed in Mined.
+// Methods it does not implement panic.
+type Client struct {
+	whatsonchain.ClientInterface
+
+	Raw   map[string]string // Raw transaction hex by txid
+	Mined map[string]bool   // Txids with a proof
+
+	mu       sync.Mutex
+	requests int
+}
+
+// GetRawTransactionData returns a transaction in Raw.
+func (c *Client) GetRawTransactionData(_ context.Context, txid string) (string, error) {
+	c.count()
+	if raw, ok := c.Raw[txid]; ok {
+		return raw, nil
+	}
+	return "", fmt.Errorf("transaction %s not found", txid)
+}
+
+// GetMerkleProofTSC returns an empty proof in the genesis block for a mined
+// transaction, and no proof otherwise.
+func (c *Client) GetMerkleProofTSC(_ context.Context, txid string) (whatsonchain.MerkleTSCResults, error) {
+	c.count()
+	if c.Mined[txid] {
+		return whatsonchain.MerkleTSCResults{{Index: 0, Target: GenesisHash, TxOrID: txid}}, nil
+	}
+	return nil, nil
+}
+
+// GetHeaderByHash returns a header at height 0 for any hash.
+func (c *Client) GetHeaderByHash(_ context.Context, hash string) (*whatsonchain.BlockInfo, error) {
+	c.count()
+	return &whatsonchain.BlockInfo{Hash: hash, Height: 0}, nil
+}
+
+// Requests returns the number of calls made to the client.
+func (c *Client) Requests() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+// count records a call.
+func (c *Client) count() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+}
+
+// Spend returns an unsigned transaction spending output 0 of parent into a
+// single output of satoshis.
+func Spend(t testing.TB, parent *transaction.Transaction, satoshis uint64) *transaction.Transaction {
+	t.Helper()
+
+	lock, err := script.NewFromHex(spendLockingScript)
+	if err != nil {
+		t.Fatalf("spvtest: parsing locking script: %v", err)
+	}
+	unlock := script.Script{script.OpTRUE}
+
+	tx := transaction.NewTransaction()
+	tx.Inputs = []*transaction.TransactionInput{{SourceTXID: parent.TxID(), UnlockingScript: &unlock, SequenceNumber: 0xffffffff}}
+	tx.Outputs = []*transaction.TransactionOutput{{Satoshis: satoshis, LockingScript: lock}}
+	return tx
+}
+
+// Chain returns a client knowing the mined genesis coinbase and an unconfirmed
+// child spending it, along with the child and an unbroadcast grandchild
+// spending the child.
+func Chain(t testing.TB) (*Client, *transaction.Transaction, *transaction.Transaction) {
+	t.Helper()
+
+	coinbase, err := transaction.NewTransactionFromHex(GenesisCoinbase)
+	if err != nil {
+		t.Fatalf("spvtest: parsing genesis coinbase: %v", err)
+	}
+	child := Spend(t, coinbase, 4000000000)
+	grandchild := Spend(t, child, 3000000000)
+
+	client := &Client{
+		Raw:   map[string]string{GenesisTxID: GenesisCoinbase, child.TxID().String(): child.Hex()},
+		Mined: map[string]bool{GenesisTxID: true},
+	}
+	return client, child, grandchild
+}
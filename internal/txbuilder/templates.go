@@ -0,0 +1,89 @@
+package txbuilder
+
+import (
+	"bytes"
+	"fmt"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/bsv-blockchain/go-sdk/util"
+)
+
+// p2pkUnlocker signs inputs locked with <pubkey> OP_CHECKSIG, whose
+// unlocking script is the signature alone.
+type p2pkUnlocker struct {
+	key  *ec.PrivateKey
+	flag sighash.Flag
+}
+
+// Sign produces the unlocking script <sig>.
+func (u *p2pkUnlocker) Sign(tx *transaction.Transaction, inputIndex uint32) (*script.Script, error) {
+	if tx.Inputs[inputIndex].SourceTxOutput() == nil {
+		return nil, transaction.ErrEmptyPreviousTx
+	}
+
+	sh, err := tx.CalcInputSignatureHash(inputIndex, u.flag)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := u.key.Sign(sh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign input %d: %w", inputIndex, err)
+	}
+
+	s := &script.Script{}
+	if err = s.AppendPushData(append(sig.Serialize(), byte(u.flag))); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// EstimateLength returns the maximum unlocking script length: a 73-byte
+// signature push.
+func (u *p2pkUnlocker) EstimateLength(*transaction.Transaction, uint32) uint32 {
+	return 73
+}
+
+// scriptUnlocker unlocks an input with a fixed script, such as a hash
+// puzzle's preimage, signing nothing.
+type scriptUnlocker struct {
+	script *script.Script
+}
+
+// Sign returns the fixed unlocking script.
+func (u *scriptUnlocker) Sign(*transaction.Transaction, uint32) (*script.Script, error) {
+	return u.script, nil
+}
+
+// EstimateLength returns the fixed script's length.
+func (u *scriptUnlocker) EstimateLength(*transaction.Transaction, uint32) uint32 {
+	return uint32(len(*u.script)) //nolint:gosec // scripts are far below 4 GiB
+}
+
+// templateUnlocker returns the unlocker of an input whose LockingScript is
+// not P2PKH to its key's address: its fixed Unlocking script when given,
+// else a P2PK signature by its Key signed with flag.
+func templateUnlocker(in Input, flag sighash.Flag) (transaction.UnlockingScriptTemplate, error) {
+	if in.Unlocking != nil {
+		return &scriptUnlocker{script: in.Unlocking}, nil
+	}
+	if !in.LockingScript.IsP2PK() {
+		return nil, fmt.Errorf("input %s:%d is not P2PK and has no unlocking script", in.UTXO.TxHash, in.UTXO.TxPos)
+	}
+	if in.Key == nil {
+		return nil, fmt.Errorf("P2PK input %s:%d has no key", in.UTXO.TxHash, in.UTXO.TxPos)
+	}
+	pubKey := (*in.LockingScript)[1 : len(*in.LockingScript)-1]
+	if !bytes.Equal(pubKey, in.Key.PubKey().Compressed()) && !bytes.Equal(pubKey, in.Key.PubKey().Uncompressed()) {
+		return nil, fmt.Errorf("P2PK input %s:%d is locked to another key", in.UTXO.TxHash, in.UTXO.TxPos)
+	}
+	return &p2pkUnlocker{key: in.Key, flag: flag}, nil
+}
+
+// templateInputSize returns the size of an input whose unlocking script is
+// n bytes long.
+func templateInputSize(n uint32) int {
+	return 32 + 4 + len(util.VarInt(n).Bytes()) + int(n) + 4
+}
@@ -0,0 +1,587 @@
+// Package txbuilder builds and signs P2PKH transactions from a set of UTXOs.
+//
+// NO SATOSHI LEFT BEHIND — every satoshi is accounted for. If there is change,
+// it always gets its own output. No dust thresholds, no silent fee absorption;
+// absorbing small change into the fee is opt-in and reported.
+//
+// The package provides:
+//   - Largest-first UTXO selection
+//   - Size-based fee estimation with a 100 satoshi minimum floor, or a floor of the caller's choosing
+//   - Exact fees for signed transactions, re-signed until the fee matches the serialized size
+//   - Payments split across multiple equal outputs with remainder handling
+//   - Arbitrary outputs (e.g. OP_RETURN data) with size-aware fees
+//   - A change output for every non-zero remainder, or opt-in absorption of small change
+//   - Signing with compressed or uncompressed (legacy WIF) keys, or unsigned builds for cold signing
+//   - P2PK inputs and inputs unlocked by a fixed script, beside the P2PKH ones
+//   - Time-locked transactions and non-final inputs via nLockTime and sequence numbers
+//   - Caps on the fee and fee rate, refusing builds that would overpay
+//   - Child-pays-for-parent fees, sized so an unconfirmed parent and its child meet the rate together
+//   - Canonical BIP69 ordering of inputs and outputs for reproducible builds
+//   - UTXO fetching from any chain provider (WhatsOnChain by default)
+package txbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/bsv-blockchain/go-sdk/util"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+// Transaction size estimation constants
+const (
+	InputSize             = 148 // Approximate bytes per input
+	InputSizeUncompressed = 180 // Approximate bytes per input signed with an uncompressed key
+	OutputSize            = 34  // Approximate bytes per output
+	BaseTxSize            = 10  // Base transaction overhead
+	MinFee                = 100 // Minimum fee in satoshis
+)
+
+// maxSignPasses bounds how often a transaction is re-signed while its fee
+// settles on its serialized size.
+const maxSignPasses = 10
+
+// UTXO represents an unspent transaction output.
+type UTXO = chain.UTXO
+
+// Input is a UTXO together with the key that unlocks it.
+type Input struct {
+	UTXO         *UTXO
+	Key          *ec.PrivateKey
+	Uncompressed bool            // UTXO is locked to the uncompressed public key's address
+	Address      *script.Address // Address the UTXO pays, used instead of Key by unsigned builds
+
+	// LockingScript is the UTXO's script when it is not P2PKH to Key's
+	// address: P2PK to Key, or any script Unlocking satisfies.
+	LockingScript *script.Script
+	// Unlocking is a fixed unlocking script for LockingScript, signing nothing.
+	Unlocking *script.Script
+}
+
+// Builder selects UTXOs and builds signed transactions at a fixed fee rate.
+type Builder struct {
+	FeePerKb     uint64                           // Fee rate in satoshis per kilobyte
+	Testnet      bool                             // Fetch UTXOs from testnet instead of mainnet
+	UTXOs        chain.UTXOProvider               // UTXO source (default: WhatsOnChain)
+	AbsorbChange uint64                           // Change below this is added to the fee (0 = never)
+	Uncompressed bool                             // SelectUTXOs sizes inputs for uncompressed keys
+	Unsigned     bool                             // Leave inputs unsigned, sized as if signed with compressed keys
+	DryRun       bool                             // Skip signing; inputs keep their unlockers, and the fee its estimate
+	LockTime     uint32                           // nLockTime: a block height below 500000000, else a Unix time (0 = none)
+	Sequence     *uint32                          // Sequence number of every input (nil = final, 0xffffffff)
+	FeeFloor     *uint64                          // Minimum fee in satoshis (nil = MinFee)
+	SigHash      sighash.Flag                     // SIGHASH flag of every signature (0 = ALL|FORKID)
+	InputSigHash map[int]sighash.Flag             // SIGHASH flags of single inputs by index before any Sort, overriding SigHash
+	MaxFee       uint64                           // Largest fee in satoshis, absorbed change included (0 = no cap)
+	MaxFeePerKb  uint64                           // Largest fee rate in satoshis per kilobyte (0 = no cap)
+	Sort         bool                             // Order inputs and outputs by BIP69 before signing
+	Required     []*UTXO                          // UTXOs SelectUTXOs always selects, ahead of the others
+	ParentSize   int                              // Bytes of an unconfirmed parent the fee also pays for (0 = none)
+	ParentFee    uint64                           // Fee the ParentSize bytes already pay
+	Logf         func(format string, args ...any) // Optional debug logger
+
+	// Absorbed is the change added to the fee by the most recent build.
+	Absorbed uint64
+	// Change is the index of the change output the most recent build
+	// added, or -1 when it added none.
+	Change int
+}
+
+// logf writes a debug message when a logger is configured.
+func (b *Builder) logf(format string, args ...any) {
+	if b.Logf != nil {
+		b.Logf(format, args...)
+	}
+}
+
+// SigHashFor returns the SIGHASH flag input index is signed with.
+func (b *Builder) SigHashFor(index int) sighash.Flag {
+	if flag, ok := b.InputSigHash[index]; ok {
+		return flag
+	}
+	if b.SigHash != 0 {
+		return b.SigHash
+	}
+	return sighash.AllForkID
+}
+
+// minFee returns the smallest fee the builder pays.
+func (b *Builder) minFee() uint64 {
+	if b.FeeFloor != nil {
+		return *b.FeeFloor
+	}
+	return MinFee
+}
+
+// feeFor returns the fee of a transaction of size bytes: its own size at
+// FeePerKb, or with a ParentSize, whatever more brings the parent and the
+// transaction together to FeePerKb, so a miner takes both (child pays for
+// parent). The fee is never below the floor.
+func (b *Builder) feeFor(size int) uint64 {
+	fee := uint64(size) * b.FeePerKb / 1000 //nolint:gosec // sizes are never negative
+	if b.ParentSize > 0 {
+		pkg := uint64(b.ParentSize+size) * b.FeePerKb / 1000 //nolint:gosec // sizes are never negative
+		if pkg > b.ParentFee {
+			fee = max(fee, pkg-b.ParentFee)
+		}
+	}
+	return max(fee, b.minFee())
+}
+
+// CalculateFee estimates the transaction fee based on size, at FeePerKb and
+// never below the floor.
+func (b *Builder) CalculateFee(numInputs, numOutputs int) uint64 {
+	estimatedSize := uint64(numInputs*InputSize + numOutputs*OutputSize + BaseTxSize) //nolint:gosec // sizes are never negative
+	return max((estimatedSize*b.FeePerKb)/1000, b.minFee())
+}
+
+// OutputsSize returns the serialized size of outputs in bytes.
+func OutputsSize(outputs []*transaction.TransactionOutput) int {
+	size := 0
+	for _, out := range outputs {
+		n := 0
+		if out.LockingScript != nil {
+			n = len(*out.LockingScript)
+		}
+		size += 8 + len(util.VarInt(n).Bytes()) + n
+	}
+	return size
+}
+
+// EstimateSize returns the approximate size of tx once its inputs are signed,
+// the size its fee is based on.
+func EstimateSize(tx *transaction.Transaction) int {
+	return inputsSize(tx) + OutputsSize(tx.Outputs) + BaseTxSize
+}
+
+// EstimateFee estimates the fee of a transaction with numInputs inputs, the
+// given outputs and a change output, at FeePerKb and never below the floor.
+func (b *Builder) EstimateFee(numInputs int, outputs []*transaction.TransactionOutput) uint64 {
+	estimatedSize := uint64(numInputs*InputSize + OutputsSize(outputs) + OutputSize + BaseTxSize) //nolint:gosec // sizes are never negative
+	return max((estimatedSize*b.FeePerKb)/1000, b.minFee())
+}
+
+// SelectUTXOs implements a largest-first UTXO selection algorithm, after
+// the Required UTXOs, which are selected first whatever their value.
+func (b *Builder) SelectUTXOs(utxos []*UTXO, targetAmount uint64) ([]*UTXO, error) {
+	if len(utxos)+len(b.Required) == 0 {
+		return nil, fmt.Errorf("no UTXOs available")
+	}
+
+	// Sort UTXOs by value (largest first)
+	sortedUTXOs := make([]*UTXO, len(utxos))
+	copy(sortedUTXOs, utxos)
+	sort.Slice(sortedUTXOs, func(i, j int) bool {
+		return sortedUTXOs[i].Value > sortedUTXOs[j].Value
+	})
+
+	selected := append([]*UTXO(nil), b.Required...)
+	var totalValue uint64
+	for _, utxo := range selected {
+		totalValue += utxo.Value
+	}
+	if len(selected) > 0 && totalValue >= targetAmount+b.selectionFee(len(selected)) {
+		b.logf("Required UTXO(s) cover the target: %d satoshis", totalValue)
+		return selected, nil
+	}
+
+	for _, utxo := range sortedUTXOs {
+		selected = append(selected, utxo)
+		totalValue += utxo.Value
+
+		// Calculate estimated fee with current number of inputs
+		estimatedFee := b.selectionFee(len(selected)) // 2 outputs: payment + change
+
+		// Check if we have enough to cover target amount + fee
+		if totalValue >= targetAmount+estimatedFee {
+			b.logf("Selected %d UTXO(s) totaling %d satoshis (target: %d + fee: ~%d)",
+				len(selected), totalValue, targetAmount, estimatedFee)
+			return selected, nil
+		}
+	}
+
+	// Not enough funds
+	estimatedFee := b.selectionFee(len(selected))
+	return nil, fmt.Errorf("insufficient funds: have %d satoshis, need %d (amount: %d + fee: ~%d)",
+		totalValue, targetAmount+estimatedFee, targetAmount, estimatedFee)
+}
+
+// selectionFee estimates the fee of spending numInputs selected UTXOs to a
+// payment and a change output.
+func (b *Builder) selectionFee(numInputs int) uint64 {
+	inputSize := InputSize
+	if b.Uncompressed {
+		inputSize = InputSizeUncompressed
+	}
+	return b.feeFor(numInputs*inputSize + 2*OutputSize + BaseTxSize)
+}
+
+// Build constructs and signs a transaction spending inputs, or leaves it
+// unsigned when the builder is Unsigned.
+//
+// A non-zero amount is paid to dest, split evenly across numOutputs outputs.
+// Whatever remains after the fee goes to change; for send-all (amount == 0)
+// the caller passes dest as the change address.
+func (b *Builder) Build(inputs []Input, dest *script.Address, amount uint64, numOutputs int, change *script.Address) (*transaction.Transaction, error) {
+	// Create a new transaction
+	tx := transaction.NewTransaction()
+
+	// Add all UTXOs as inputs
+	totalInput, err := b.addInputs(tx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add payment outputs
+	if amount > 0 {
+		if err := b.addPaymentOutputs(tx, dest, amount, numOutputs); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.finish(tx, change, totalInput, amount)
+}
+
+// BuildOutputs constructs and signs (unless Unsigned) a transaction spending
+// inputs to the given outputs, such as OP_RETURN data outputs. Whatever
+// remains after the fee goes to change.
+func (b *Builder) BuildOutputs(inputs []Input, outputs []*transaction.TransactionOutput, change *script.Address) (*transaction.Transaction, error) {
+	tx := transaction.NewTransaction()
+
+	totalInput, err := b.addInputs(tx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount uint64
+	for _, out := range outputs {
+		tx.AddOutput(out)
+		amount += out.Satoshis
+	}
+
+	return b.finish(tx, change, totalInput, amount)
+}
+
+// finish adds the change output, sets the lock time and sequence numbers,
+// and signs the transaction.
+//
+// The first fee is based on the estimated size with a change output. Once
+// signed, the fee is recomputed from the serialized size and the change
+// adjusted and re-signed, until the size no longer grows past the one the
+// fee paid for. Unsigned and dry-run builds keep the estimate. A Sort
+// builder orders the inputs and outputs before each signing.
+func (b *Builder) finish(tx *transaction.Transaction, change *script.Address, totalInput, amount uint64) (*transaction.Transaction, error) {
+	// Both are signed over, so they are set first
+	tx.LockTime = b.LockTime
+	if b.Sequence != nil {
+		for _, input := range tx.Inputs {
+			input.SequenceNumber = *b.Sequence
+		}
+	}
+
+	// Sorting moves the payments, so each pass starts again from them
+	payments := tx.Outputs
+	size := EstimateSize(tx) + OutputSize
+	tried := make(map[int]bool)
+	for pass := 1; ; pass++ {
+		tried[size] = true
+		tx.Outputs = append([]*transaction.TransactionOutput(nil), payments...)
+		if err := b.addChangeOutput(tx, change, totalInput, amount, size); err != nil {
+			return nil, err
+		}
+		b.Change = -1
+		if len(tx.Outputs) > len(payments) {
+			b.Change = len(payments)
+		}
+		if b.Sort {
+			b.Change = sortBIP69(tx, b.Change)
+		}
+
+		if err := b.CheckSigHashes(tx); err != nil {
+			return nil, err
+		}
+		if b.Unsigned || b.DryRun {
+			if err := b.checkMaxFee(tx, totalInput, size); err != nil {
+				return nil, err
+			}
+			b.logf("Unsigned transaction ID: %s", tx.TxID().String())
+			return tx, nil
+		}
+
+		// Sign all inputs
+		if err := tx.Sign(); err != nil {
+			return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+
+		// Signature lengths vary by a byte with the signed content, so a
+		// smaller size already tried is accepted rather than cycling
+		signed := tx.Size()
+		if signed == size || (signed < size && tried[signed]) {
+			break
+		}
+		if pass == maxSignPasses {
+			if signed < size {
+				break
+			}
+			return nil, fmt.Errorf("fee did not settle after %d signing passes: %d bytes signed, fee paid for %d", pass, signed, size)
+		}
+		b.logf("Signed size %d bytes, fee was based on %d; re-signing", signed, size)
+		size = signed
+	}
+	if err := b.checkMaxFee(tx, totalInput, tx.Size()); err != nil {
+		return nil, err
+	}
+
+	b.logf("Transaction ID: %s", tx.TxID().String())
+
+	return tx, nil
+}
+
+// CheckSigHashes reports InputSigHash flags for inputs tx lacks, and
+// SIGHASH_SINGLE inputs without an output at their index: such a signature
+// commits to no output, or under the legacy algorithm to the constant 1,
+// letting anyone redirect the funds.
+func (b *Builder) CheckSigHashes(tx *transaction.Transaction) error {
+	for index := range b.InputSigHash {
+		if index < 0 || index >= len(tx.Inputs) {
+			return fmt.Errorf("SIGHASH flag given for input %d, but the transaction has %d input(s)", index, len(tx.Inputs))
+		}
+	}
+	for i := range tx.Inputs {
+		if b.SigHashFor(i).HasWithMask(sighash.Single) && i >= len(tx.Outputs) {
+			return fmt.Errorf("input %d is signed SIGHASH_SINGLE, but the transaction has no output %d", i, i)
+		}
+	}
+	return nil
+}
+
+// sortBIP69 orders tx's inputs by previous txid, as displayed, then output
+// index, and its outputs by amount, then locking script bytes, as BIP69
+// specifies. It returns the new index of output change (-1 = none).
+func sortBIP69(tx *transaction.Transaction, change int) int {
+	sort.SliceStable(tx.Inputs, func(i, j int) bool {
+		a, b := tx.Inputs[i], tx.Inputs[j]
+		if txidA, txidB := a.SourceTXID.String(), b.SourceTXID.String(); txidA != txidB {
+			return txidA < txidB
+		}
+		return a.SourceTxOutIndex < b.SourceTxOutIndex
+	})
+
+	var changeOut *transaction.TransactionOutput
+	if change >= 0 {
+		changeOut = tx.Outputs[change]
+	}
+	sort.SliceStable(tx.Outputs, func(i, j int) bool {
+		a, b := tx.Outputs[i], tx.Outputs[j]
+		if a.Satoshis != b.Satoshis {
+			return a.Satoshis < b.Satoshis
+		}
+		return bytes.Compare(*a.LockingScript, *b.LockingScript) < 0
+	})
+	for i, out := range tx.Outputs {
+		if out == changeOut {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkMaxFee reports a fee over MaxFee, or over MaxFeePerKb for a
+// transaction of size bytes. The fee is what the inputs leave over after the
+// outputs, so absorbed change counts toward it. With a ParentSize, the rate
+// capped is that of the parent and the transaction together, which a child
+// paying for its parent exceeds on its own.
+func (b *Builder) checkMaxFee(tx *transaction.Transaction, totalInput uint64, size int) error {
+	fee := totalInput - tx.TotalOutputSatoshis()
+	if b.MaxFee > 0 && fee > b.MaxFee {
+		return fmt.Errorf("fee of %d satoshis exceeds the maximum of %d", fee, b.MaxFee)
+	}
+	if b.ParentSize > 0 {
+		fee, size = fee+b.ParentFee, size+b.ParentSize
+	}
+	if b.MaxFeePerKb > 0 && fee*1000 > b.MaxFeePerKb*uint64(size) {
+		return fmt.Errorf("fee of %d satoshis for %d bytes is %.3f sat/byte, over the maximum of %g sat/byte",
+			fee, size, float64(fee)/float64(size), float64(b.MaxFeePerKb)/1000)
+	}
+	return nil
+}
+
+// addInputs adds all UTXOs as transaction inputs, each unlocked by its own
+// key with its SIGHASH flag. Unsigned builds add them without unlockers,
+// locked to their Address. Inputs with a LockingScript keep it, unlocked by
+// their template.
+func (b *Builder) addInputs(tx *transaction.Transaction, inputs []Input) (uint64, error) {
+	var totalInput uint64
+
+	for i, in := range inputs {
+		if in.LockingScript != nil {
+			unlocker, err := templateUnlocker(in, b.SigHashFor(i))
+			if err != nil {
+				return 0, err
+			}
+			if err = tx.AddInputFrom(in.UTXO.TxHash, in.UTXO.TxPos, in.LockingScript.String(), in.UTXO.Value, unlocker); err != nil {
+				return 0, fmt.Errorf("failed to add input: %w", err)
+			}
+			totalInput += in.UTXO.Value
+			continue
+		}
+
+		if b.Unsigned {
+			if in.Address == nil {
+				return 0, fmt.Errorf("unsigned input %s:%d has no address", in.UTXO.TxHash, in.UTXO.TxPos)
+			}
+			lockingScript, err := p2pkh.Lock(in.Address)
+			if err != nil {
+				return 0, fmt.Errorf("failed to create locking script: %w", err)
+			}
+			if err = tx.AddInputFrom(in.UTXO.TxHash, in.UTXO.TxPos, lockingScript.String(), in.UTXO.Value, nil); err != nil {
+				return 0, fmt.Errorf("failed to add input: %w", err)
+			}
+			totalInput += in.UTXO.Value
+			continue
+		}
+
+		// Create P2PKH unlocker for signing
+		var unlocker transaction.UnlockingScriptTemplate
+		flag := b.SigHashFor(i)
+		if in.Uncompressed {
+			unlocker = &uncompressedUnlocker{key: in.Key, flag: flag}
+		} else {
+			p2pkhUnlocker, err := p2pkh.Unlock(in.Key, &flag)
+			if err != nil {
+				return 0, fmt.Errorf("failed to create unlocker: %w", err)
+			}
+			unlocker = p2pkhUnlocker
+		}
+
+		// Create the locking script from the key's address (P2PKH)
+		sourceAddr, err := script.NewAddressFromPublicKeyWithCompression(in.Key.PubKey(), true, !in.Uncompressed)
+		if err != nil {
+			return 0, fmt.Errorf("failed to derive source address: %w", err)
+		}
+		lockingScript, err := p2pkh.Lock(sourceAddr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create locking script: %w", err)
+		}
+
+		// Add input
+		err = tx.AddInputFrom(
+			in.UTXO.TxHash,
+			in.UTXO.TxPos,
+			lockingScript.String(),
+			in.UTXO.Value,
+			unlocker,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add input: %w", err)
+		}
+
+		totalInput += in.UTXO.Value
+	}
+
+	b.logf("Total input: %d satoshis", totalInput)
+
+	return totalInput, nil
+}
+
+// addPaymentOutputs adds payment outputs to the destination address.
+func (b *Builder) addPaymentOutputs(tx *transaction.Transaction, destAddr *script.Address, amount uint64, numOutputs int) error {
+	destLockingScript, err := p2pkh.Lock(destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to create destination locking script: %w", err)
+	}
+
+	if numOutputs < 1 {
+		numOutputs = 1
+	}
+
+	// Calculate amount per output and remainder
+	amountPerOutput := amount / uint64(numOutputs)
+	remainder := amount % uint64(numOutputs)
+
+	// Add outputs with equal amounts
+	for i := 0; i < numOutputs; i++ {
+		outputAmount := amountPerOutput
+
+		// Add remainder to the last output
+		if i == numOutputs-1 {
+			outputAmount += remainder
+		}
+
+		tx.AddOutput(&transaction.TransactionOutput{
+			Satoshis:      outputAmount,
+			LockingScript: destLockingScript,
+		})
+
+		b.logf("Output %d to %s: %d satoshis", i+1, destAddr.AddressString, outputAmount)
+	}
+
+	if remainder > 0 {
+		b.logf("Remainder of %d satoshis added to last output", remainder)
+	}
+
+	return nil
+}
+
+// addChangeOutput calculates the fee for a transaction of size bytes and
+// adds a change output if needed.
+// NO SATOSHI LEFT BEHIND: if change > 0, always create a change output, unless
+// it is below AbsorbChange and the transaction already has another output.
+func (b *Builder) addChangeOutput(tx *transaction.Transaction, changeAddr *script.Address, totalInput, amount uint64, size int) error {
+	b.Absorbed = 0
+
+	fee := b.feeFor(size)
+
+	b.logf("Size: %d bytes, Fee: %d satoshis", size, fee)
+
+	if totalInput < amount+fee {
+		return fmt.Errorf("insufficient funds: have %d satoshis, need %d (amount: %d + fee: %d)",
+			totalInput, amount+fee, amount, fee)
+	}
+	change := totalInput - amount - fee
+
+	if change > 0 && change < b.AbsorbChange && len(tx.Outputs) > 0 {
+		b.Absorbed = change
+		b.logf("Change of %d satoshis absorbed into the fee (%d satoshis total)", change, fee+change)
+		return nil
+	}
+
+	if change > 0 {
+		changeLockingScript, err := p2pkh.Lock(changeAddr)
+		if err != nil {
+			return fmt.Errorf("failed to create change locking script: %w", err)
+		}
+
+		tx.AddOutput(&transaction.TransactionOutput{
+			Satoshis:      change,
+			LockingScript: changeLockingScript,
+		})
+
+		b.logf("Change to %s: %d satoshis", changeAddr.AddressString, change)
+	}
+
+	return nil
+}
+
+// inputsSize returns the approximate size of the transaction's signed inputs.
+func inputsSize(tx *transaction.Transaction) int {
+	size := 0
+	for i, in := range tx.Inputs {
+		switch unlocker := in.UnlockingScriptTemplate.(type) {
+		case *uncompressedUnlocker:
+			size += InputSizeUncompressed
+		case *p2pkUnlocker, *scriptUnlocker:
+			size += templateInputSize(unlocker.EstimateLength(tx, uint32(i))) //nolint:gosec // input indexes are 32-bit
+		default:
+			size += InputSize
+		}
+	}
+	return size
+}
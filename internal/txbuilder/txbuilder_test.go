@@ -0,0 +1,899 @@
+package txbuilder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	crypto "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFee(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		numInputs  int
+		numOutputs int
+		feePerKb   uint64
+		expected   uint64
+	}{
+		// Basic calculations
+		{
+			name:       "single input single output standard fee",
+			numInputs:  1,
+			numOutputs: 1,
+			feePerKb:   1000,
+			// (1*148 + 1*34 + 10) * 1000 / 1000 = 192
+			expected: 192,
+		},
+		{
+			name:       "two inputs two outputs",
+			numInputs:  2,
+			numOutputs: 2,
+			feePerKb:   1000,
+			// (2*148 + 2*34 + 10) * 1000 / 1000 = 374
+			expected: 374,
+		},
+		{
+			name:       "large transaction",
+			numInputs:  10,
+			numOutputs: 5,
+			feePerKb:   1000,
+			// (10*148 + 5*34 + 10) * 1000 / 1000 = 1660
+			expected: 1660,
+		},
+
+		// Minimum fee enforcement
+		{
+			name:       "enforces minimum fee with low fee rate",
+			numInputs:  1,
+			numOutputs: 1,
+			feePerKb:   1, // Very low fee rate
+			// Calculated: (192 * 1) / 1000 = 0, but minimum is 100
+			expected: MinFee,
+		},
+		{
+			name:       "enforces minimum fee with zero fee rate",
+			numInputs:  1,
+			numOutputs: 1,
+			feePerKb:   0,
+			expected:   MinFee,
+		},
+
+		// Edge cases
+		{
+			name:       "zero inputs zero outputs",
+			numInputs:  0,
+			numOutputs: 0,
+			feePerKb:   1000,
+			// (0*148 + 0*34 + 10) * 1000 / 1000 = 10, minimum is 100
+			expected: MinFee,
+		},
+		{
+			name:       "high fee rate",
+			numInputs:  1,
+			numOutputs: 1,
+			feePerKb:   10000,
+			// (192) * 10000 / 1000 = 1920
+			expected: 1920,
+		},
+		{
+			name:       "BSV typical fee rate (100 sat/kb)",
+			numInputs:  1,
+			numOutputs: 2,
+			feePerKb:   100,
+			// (1*148 + 2*34 + 10) * 100 / 1000 = 22.6 -> 22, minimum is 100
+			expected: MinFee,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := (&Builder{FeePerKb: tt.feePerKb}).CalculateFee(tt.numInputs, tt.numOutputs)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("fee floor replaces the minimum fee", func(t *testing.T) {
+		t.Parallel()
+
+		// (1*148 + 2*34 + 10) * 100 / 1000 = 22, and 19 without the payment output
+		for _, floor := range []uint64{0, 500} {
+			builder := &Builder{FeePerKb: 100, FeeFloor: &floor}
+			assert.Equal(t, max(22, floor), builder.CalculateFee(1, 2), "floor %d", floor)
+			assert.Equal(t, max(19, floor), builder.EstimateFee(1, nil), "floor %d", floor)
+		}
+	})
+}
+
+func TestSelectUTXOs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("single UTXO sufficient", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 10000},
+		}
+
+		selected, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 5000)
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "tx1", selected[0].TxHash)
+	})
+
+	t.Run("multiple UTXOs needed", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx2", TxPos: 0, Value: 2000},
+			{TxHash: "tx3", TxPos: 0, Value: 3000},
+		}
+
+		// Target 4000 + fee, needs at least 2 UTXOs
+		selected, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 4000)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(selected), 2)
+
+		// Should select largest first (3000, then 2000)
+		var totalValue uint64
+		for _, u := range selected {
+			totalValue += u.Value
+		}
+		assert.GreaterOrEqual(t, totalValue, uint64(4000))
+	})
+
+	t.Run("selects largest first", func(t *testing.T) {
+		t.Parallel()
+
+		// UTXOs not in order by value
+		utxos := []*UTXO{
+			{TxHash: "small", TxPos: 0, Value: 100},
+			{TxHash: "large", TxPos: 0, Value: 10000},
+			{TxHash: "medium", TxPos: 0, Value: 5000},
+		}
+
+		selected, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 1000)
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "large", selected[0].TxHash)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx2", TxPos: 0, Value: 2000},
+		}
+
+		// Target much more than available
+		_, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 100000)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+	})
+
+	t.Run("empty UTXO list", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{}
+
+		_, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 1000)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no UTXOs available")
+	})
+
+	t.Run("exact amount match", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 5100}, // Just enough for 5000 + ~100 fee
+		}
+
+		selected, err := (&Builder{FeePerKb: 100}).SelectUTXOs(utxos, 5000)
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+	})
+
+	t.Run("preserves original slice order", func(t *testing.T) {
+		t.Parallel()
+
+		original := []*UTXO{
+			{TxHash: "first", TxPos: 0, Value: 100},
+			{TxHash: "second", TxPos: 0, Value: 200},
+			{TxHash: "third", TxPos: 0, Value: 300},
+		}
+
+		// Make a copy to verify original isn't modified
+		originalCopy := make([]*UTXO, len(original))
+		copy(originalCopy, original)
+
+		_, _ = (&Builder{FeePerKb: 100}).SelectUTXOs(original, 50)
+
+		// Original should be unchanged
+		for i, u := range original {
+			assert.Equal(t, originalCopy[i].TxHash, u.TxHash)
+		}
+	})
+
+	t.Run("accounts for increasing fee with more inputs", func(t *testing.T) {
+		t.Parallel()
+
+		// Many small UTXOs - fee increases as more are added
+		utxos := make([]*UTXO, 20)
+		for i := 0; i < 20; i++ {
+			utxos[i] = &UTXO{TxHash: "tx", TxPos: uint32(i), Value: 1000}
+		}
+
+		// Target that requires multiple UTXOs
+		selected, err := (&Builder{FeePerKb: 1000}).SelectUTXOs(utxos, 15000)
+		require.NoError(t, err)
+
+		var totalValue uint64
+		for _, u := range selected {
+			totalValue += u.Value
+		}
+
+		// Total should cover target + fee for all selected inputs
+		expectedMinFee := (&Builder{FeePerKb: 1000}).CalculateFee(len(selected), 2)
+		assert.GreaterOrEqual(t, totalValue, uint64(15000)+expectedMinFee)
+	})
+
+	t.Run("required UTXOs come first, topped up as needed", func(t *testing.T) {
+		t.Parallel()
+
+		required := &UTXO{TxHash: "required", TxPos: 0, Value: 2000}
+		utxos := []*UTXO{
+			{TxHash: "small", TxPos: 0, Value: 1000},
+			{TxHash: "large", TxPos: 0, Value: 5000},
+		}
+		builder := &Builder{FeePerKb: 100, Required: []*UTXO{required}}
+
+		selected, err := builder.SelectUTXOs(utxos, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, []*UTXO{required}, selected)
+
+		selected, err = builder.SelectUTXOs(utxos, 3000)
+		require.NoError(t, err)
+		assert.Equal(t, []*UTXO{required, utxos[1]}, selected)
+
+		selected, err = builder.SelectUTXOs(nil, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, []*UTXO{required}, selected)
+	})
+}
+
+func TestUTXOStruct(t *testing.T) {
+	t.Parallel()
+
+	utxo := &UTXO{
+		TxHash: "0123456789abcdef",
+		TxPos:  2,
+		Value:  123456789,
+	}
+
+	assert.Equal(t, "0123456789abcdef", utxo.TxHash)
+	assert.Equal(t, uint32(2), utxo.TxPos)
+	assert.Equal(t, uint64(123456789), utxo.Value)
+}
+
+func TestConstants(t *testing.T) {
+	t.Parallel()
+
+	// Verify constants have expected values
+	assert.Equal(t, 148, InputSize)
+	assert.Equal(t, 34, OutputSize)
+	assert.Equal(t, 10, BaseTxSize)
+	assert.Equal(t, 100, MinFee)
+}
+
+// testKey returns a deterministic private key and its mainnet address.
+func testKey(t *testing.T, n int) (*ec.PrivateKey, *script.Address) {
+	t.Helper()
+	key, err := ec.PrivateKeyFromHex(strings.Repeat("0", 63) + string(rune('0'+n)))
+	require.NoError(t, err)
+	addr, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	return key, addr
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	const txB = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+
+	keyA, addrA := testKey(t, 1)
+	keyB, _ := testKey(t, 2)
+	_, dest := testKey(t, 3)
+
+	t.Run("split payment with change, inputs from two keys", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{
+			{UTXO: &UTXO{TxHash: txA, TxPos: 0, Value: 10000}, Key: keyA},
+			{UTXO: &UTXO{TxHash: txB, TxPos: 1, Value: 5000}, Key: keyB},
+		}
+
+		tx, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 12001, 2, addrA)
+		require.NoError(t, err)
+
+		require.Len(t, tx.Inputs, 2)
+		require.Len(t, tx.Outputs, 3)
+		assert.Equal(t, uint64(6000), tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(6001), tx.Outputs[1].Satoshis)
+		assert.Equal(t, uint64(15000-12001-MinFee), tx.Outputs[2].Satoshis)
+
+		// Each input is signed by its own key
+		for i, key := range []*ec.PrivateKey{keyA, keyB} {
+			require.NotNil(t, tx.Inputs[i].UnlockingScript)
+			chunks, err := tx.Inputs[i].UnlockingScript.Chunks()
+			require.NoError(t, err)
+			require.Len(t, chunks, 2)
+			assert.Equal(t, key.PubKey().Compressed(), chunks[1].Data)
+		}
+	})
+
+	t.Run("send-all pays everything but the fee to change", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 10000}, Key: keyA}}
+
+		tx, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 0, 1, dest)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, uint64(10000-MinFee), tx.Outputs[0].Satoshis)
+	})
+
+	t.Run("exact amount leaves no change output", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 5000 + MinFee}, Key: keyA}}
+
+		tx, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 5000, 1, addrA)
+		require.NoError(t, err)
+		assert.Len(t, tx.Outputs, 1)
+	})
+
+	t.Run("change below the absorb threshold goes to the fee", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 5000 + MinFee + 545}, Key: keyA}}
+
+		builder := &Builder{FeePerKb: 100, AbsorbChange: 546}
+		tx, err := builder.Build(inputs, dest, 5000, 1, addrA)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, uint64(5000), tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(545), builder.Absorbed)
+
+		// Change at the threshold keeps its output
+		inputs[0].UTXO.Value++
+		tx, err = builder.Build(inputs, dest, 5000, 1, addrA)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 2)
+		assert.Equal(t, uint64(546), tx.Outputs[1].Satoshis)
+		assert.Zero(t, builder.Absorbed)
+	})
+
+	t.Run("send-all is never absorbed", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 500}, Key: keyA}}
+
+		builder := &Builder{FeePerKb: 100, AbsorbChange: 1000}
+		tx, err := builder.Build(inputs, dest, 0, 1, dest)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, uint64(500-MinFee), tx.Outputs[0].Satoshis)
+		assert.Zero(t, builder.Absorbed)
+	})
+
+	t.Run("unsigned build locks inputs to their address", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 10000}, Address: addrA}}
+
+		tx, err := (&Builder{FeePerKb: 100, Unsigned: true}).Build(inputs, dest, 4000, 1, addrA)
+		require.NoError(t, err)
+		require.Len(t, tx.Inputs, 1)
+		assert.Nil(t, tx.Inputs[0].UnlockingScript)
+		require.NotNil(t, tx.Inputs[0].SourceTxOutput())
+		assert.Equal(t, uint64(10000), tx.Inputs[0].SourceTxOutput().Satoshis)
+		require.Len(t, tx.Outputs, 2)
+		assert.Equal(t, uint64(10000-4000-MinFee), tx.Outputs[1].Satoshis)
+
+		// Signing afterwards gives the same outputs as a signed build
+		signed, err := (&Builder{FeePerKb: 100}).Build([]Input{{UTXO: inputs[0].UTXO, Key: keyA}}, dest, 4000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, signed.Outputs, tx.Outputs)
+
+		_, err = (&Builder{FeePerKb: 100, Unsigned: true}).Build([]Input{{UTXO: inputs[0].UTXO, Key: keyA}}, dest, 4000, 1, addrA)
+		require.ErrorContains(t, err, "has no address")
+	})
+
+	t.Run("dry run skips signing and keeps the estimated fee", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 10000}, Key: keyA}}
+
+		tx, err := (&Builder{FeePerKb: 1000, DryRun: true}).Build(inputs, dest, 4000, 1, addrA)
+		require.NoError(t, err)
+		assert.Nil(t, tx.Inputs[0].UnlockingScript)
+		assert.Equal(t, 148+2*34+10, EstimateSize(tx))
+		estimated, err := tx.GetFee()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(EstimateSize(tx)), estimated)
+
+		// Signed, the fee is at most the estimate and only a few bytes' worth less
+		signed, err := (&Builder{FeePerKb: 1000}).Build(inputs, dest, 4000, 1, addrA)
+		require.NoError(t, err)
+		require.Len(t, signed.Outputs, 2)
+		assert.Equal(t, tx.Outputs[0], signed.Outputs[0])
+		fee, err := signed.GetFee()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, fee, estimated)
+		assert.InDelta(t, estimated, fee, 2)
+	})
+
+	t.Run("fee floor replaces the minimum fee", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 10000}, Key: keyA}}
+		for _, floor := range []uint64{0, 500} {
+			tx, err := (&Builder{FeePerKb: 50, FeeFloor: &floor}).Build(inputs, dest, 4000, 1, addrA)
+			require.NoError(t, err)
+			fee, err := tx.GetFee()
+			require.NoError(t, err)
+			assert.Equal(t, max(uint64(tx.Size())*50/1000, floor), fee, "floor %d", floor)
+		}
+	})
+
+	t.Run("signed fee matches the serialized size", func(t *testing.T) {
+		t.Parallel()
+
+		// Ten inputs, where the estimate overpays most, and a large OP_RETURN
+		var inputs []Input
+		for i := range 10 {
+			inputs = append(inputs, Input{UTXO: &UTXO{TxHash: txA, TxPos: uint32(i), Value: 10000}, Key: keyA}) //nolint:gosec // test index
+		}
+		data, err := script.NewFromASM("OP_FALSE OP_RETURN " + strings.Repeat("ab", 300))
+		require.NoError(t, err)
+
+		for _, rate := range []uint64{1000, 500, 50000} {
+			tx, err := (&Builder{FeePerKb: rate}).BuildOutputs(inputs, []*transaction.TransactionOutput{{LockingScript: data}}, addrA)
+			require.NoError(t, err)
+			fee, err := tx.GetFee()
+			require.NoError(t, err)
+			// Signatures a byte shorter after the last pass may leave up to
+			// two bytes paid for, never fewer than signed
+			size := uint64(tx.Size())
+			assert.GreaterOrEqual(t, fee, max(size*rate/1000, MinFee), "rate %d", rate)
+			assert.LessOrEqual(t, fee, max((size+2)*rate/1000, MinFee), "rate %d", rate)
+		}
+	})
+
+	t.Run("lock time and sequence numbers", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{
+			{UTXO: &UTXO{TxHash: txA, Value: 10000}, Key: keyA},
+			{UTXO: &UTXO{TxHash: txB, TxPos: 1, Value: 5000}, Key: keyB},
+		}
+
+		tx, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 12001, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), tx.LockTime)
+		for _, input := range tx.Inputs {
+			assert.Equal(t, uint32(0xffffffff), input.SequenceNumber)
+		}
+
+		sequence := uint32(0xfffffffe)
+		tx, err = (&Builder{FeePerKb: 100, LockTime: 840000, Sequence: &sequence}).Build(inputs, dest, 12001, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(840000), tx.LockTime)
+		for _, input := range tx.Inputs {
+			assert.Equal(t, sequence, input.SequenceNumber)
+		}
+
+		// Both are serialized with the transaction
+		parsed, err := transaction.NewTransactionFromHex(tx.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, uint32(840000), parsed.LockTime)
+		assert.Equal(t, sequence, parsed.Inputs[1].SequenceNumber)
+	})
+
+	t.Run("inputs below amount plus fee", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 50}, Key: keyA}}
+
+		_, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 0, 1, dest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+	})
+}
+
+func TestBuildSigHash(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	const txB = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+
+	keyA, addrA := testKey(t, 1)
+	_, dest := testKey(t, 3)
+	inputs := []Input{
+		{UTXO: &UTXO{TxHash: txA, TxPos: 0, Value: 10000}, Key: keyA},
+		{UTXO: &UTXO{TxHash: txB, TxPos: 1, Value: 5000}, Key: keyA},
+	}
+
+	// sigFlag returns the SIGHASH byte at the end of input i's signature
+	sigFlag := func(t *testing.T, tx *transaction.Transaction, i int) sighash.Flag {
+		chunks, err := tx.Inputs[i].UnlockingScript.Chunks()
+		require.NoError(t, err)
+		sig := chunks[0].Data
+		return sighash.Flag(sig[len(sig)-1])
+	}
+
+	t.Run("defaults to ALL|FORKID", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 1000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, sighash.AllForkID, sigFlag(t, tx, 0))
+		assert.Equal(t, sighash.AllForkID, sigFlag(t, tx, 1))
+	})
+
+	t.Run("per-input flags override the default", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{
+			FeePerKb:     100,
+			SigHash:      sighash.NoneForkID | sighash.AnyOneCanPay,
+			InputSigHash: map[int]sighash.Flag{1: sighash.SingleForkID},
+		}
+		tx, err := builder.Build(inputs, dest, 1000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, sighash.NoneForkID|sighash.AnyOneCanPay, sigFlag(t, tx, 0))
+		assert.Equal(t, sighash.SingleForkID, sigFlag(t, tx, 1))
+		for i := range tx.Inputs {
+			require.NoError(t, interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, tx.Inputs[i].SourceTxOutput()),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			))
+		}
+	})
+
+	t.Run("rejects SINGLE without a matching output", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 100, InputSigHash: map[int]sighash.Flag{1: sighash.SingleForkID}}
+		_, err := builder.Build(inputs, dest, 0, 1, dest)
+		require.EqualError(t, err, "input 1 is signed SIGHASH_SINGLE, but the transaction has no output 1")
+	})
+
+	t.Run("rejects a flag for a missing input", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 100, InputSigHash: map[int]sighash.Flag{2: sighash.AllForkID}}
+		_, err := builder.Build(inputs, dest, 1000, 1, addrA)
+		require.EqualError(t, err, "SIGHASH flag given for input 2, but the transaction has 2 input(s)")
+	})
+}
+
+func TestBuildMaxFee(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	keyA, addrA := testKey(t, 1)
+	_, dest := testKey(t, 3)
+	inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 100000}, Key: keyA}}
+
+	t.Run("fees within the caps build", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := (&Builder{FeePerKb: 1000, MaxFee: 226, MaxFeePerKb: 1000}).Build(inputs, dest, 1000, 1, addrA)
+		require.NoError(t, err)
+		fee, err := tx.GetFee()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, fee, uint64(226))
+	})
+
+	t.Run("rejects a fee over MaxFee", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := (&Builder{FeePerKb: 100000, MaxFee: 5000}).Build(inputs, dest, 1000, 1, addrA)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the maximum of 5000")
+	})
+
+	t.Run("absorbed change counts toward the fee", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 100, AbsorbChange: 1000, MaxFee: 500}
+		_, err := builder.Build([]Input{{UTXO: &UTXO{TxHash: txA, Value: 1800}, Key: keyA}}, dest, 1000, 1, addrA)
+		require.EqualError(t, err, "fee of 800 satoshis exceeds the maximum of 500")
+	})
+
+	t.Run("rejects a rate over MaxFeePerKb, unsigned too", func(t *testing.T) {
+		t.Parallel()
+
+		// The 100 satoshi floor is over 0.44 sat/byte of a 226 byte transaction
+		unsigned := []Input{{UTXO: inputs[0].UTXO, Address: addrA}}
+		_, err := (&Builder{FeePerKb: 1, Unsigned: true, MaxFeePerKb: 400}).Build(unsigned, dest, 1000, 1, addrA)
+		require.EqualError(t, err, "fee of 100 satoshis for 226 bytes is 0.442 sat/byte, over the maximum of 0.4 sat/byte")
+	})
+}
+
+func TestBuildParent(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	keyA, addrA := testKey(t, 1)
+	_, dest := testKey(t, 3)
+	unsigned := []Input{{UTXO: &UTXO{TxHash: txA, Value: 100000}, Address: addrA}}
+
+	for _, tc := range []struct {
+		name       string
+		parentSize int
+		parentFee  uint64
+		wantFee    uint64
+	}{
+		{"no parent pays its own 226 bytes", 0, 0, 226},
+		{"an underpaying parent's shortfall is added", 200, 20, 406},
+		{"a parent paying its way adds nothing", 200, 200, 226},
+		{"an overpaying parent still leaves the child's own fee", 200, 1000, 226},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &Builder{FeePerKb: 1000, Unsigned: true, ParentSize: tc.parentSize, ParentFee: tc.parentFee}
+			tx, err := builder.Build(unsigned, dest, 1000, 1, addrA)
+			require.NoError(t, err)
+			fee, err := tx.GetFee()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantFee, fee)
+		})
+	}
+
+	t.Run("selection counts the shortfall", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 1000, ParentSize: 1000}
+		_, err := builder.SelectUTXOs([]*UTXO{{TxHash: txA, Value: 1500}}, 1000)
+		require.ErrorContains(t, err, "need 2226")
+	})
+
+	t.Run("MaxFeePerKb caps the rate of parent and child together", func(t *testing.T) {
+		t.Parallel()
+
+		// The child alone pays 1.8 sat/byte, the pair 1 sat/byte
+		signed := []Input{{UTXO: unsigned[0].UTXO, Key: keyA}}
+		builder := &Builder{FeePerKb: 1000, ParentSize: 200, ParentFee: 20, MaxFeePerKb: 1100}
+		_, err := builder.Build(signed, dest, 1000, 1, addrA)
+		require.NoError(t, err)
+
+	})
+}
+
+func TestBuildSort(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	const txB = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+
+	keyA, addrA := testKey(t, 1)
+	keyB, _ := testKey(t, 2)
+	_, dest := testKey(t, 3)
+	inputs := []Input{
+		{UTXO: &UTXO{TxHash: txA, TxPos: 1, Value: 10000}, Key: keyA},
+		{UTXO: &UTXO{TxHash: txB, TxPos: 0, Value: 2000}, Key: keyB},
+		{UTXO: &UTXO{TxHash: txA, TxPos: 0, Value: 3000}, Key: keyA},
+	}
+
+	t.Run("unsorted keeps the given order, change last", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 100}
+		tx, err := builder.Build(inputs, dest, 12000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, txA, tx.Inputs[0].SourceTXID.String())
+		assert.Equal(t, 1, builder.Change)
+		assert.Equal(t, uint64(15000-12000-MinFee), tx.Outputs[1].Satoshis)
+	})
+
+	t.Run("orders inputs by outpoint and outputs by amount", func(t *testing.T) {
+		t.Parallel()
+
+		builder := &Builder{FeePerKb: 100, Sort: true}
+		tx, err := builder.Build(inputs, dest, 12000, 1, addrA)
+		require.NoError(t, err)
+
+		outpoints := make([]string, 0, len(tx.Inputs))
+		for _, in := range tx.Inputs {
+			outpoints = append(outpoints, fmt.Sprintf("%s:%d", in.SourceTXID, in.SourceTxOutIndex))
+		}
+		assert.Equal(t, []string{txB + ":0", txA + ":0", txA + ":1"}, outpoints)
+		require.Len(t, tx.Outputs, 2)
+		assert.Equal(t, 0, builder.Change)
+		assert.Equal(t, uint64(15000-12000-MinFee), tx.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(12000), tx.Outputs[1].Satoshis)
+
+		// Each input moved with its own key and source output
+		for i := range tx.Inputs {
+			require.NoError(t, interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, tx.Inputs[i].SourceTxOutput()),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			))
+		}
+
+		// Reversed inputs build the same transaction
+		reversed := []Input{inputs[2], inputs[1], inputs[0]}
+		again, err := (&Builder{FeePerKb: 100, Sort: true}).Build(reversed, dest, 12000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, tx.TxID().String(), again.TxID().String())
+	})
+
+	t.Run("equal amounts order by locking script", func(t *testing.T) {
+		t.Parallel()
+
+		outputs := []*transaction.TransactionOutput{
+			{Satoshis: 500, LockingScript: &script.Script{script.OpTRUE}},
+			{Satoshis: 500, LockingScript: &script.Script{script.OpFALSE, script.OpRETURN}},
+		}
+		builder := &Builder{FeePerKb: 100, Sort: true}
+		tx, err := builder.BuildOutputs(inputs[:1], outputs, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, &script.Script{script.OpFALSE, script.OpRETURN}, tx.Outputs[0].LockingScript)
+		assert.Equal(t, &script.Script{script.OpTRUE}, tx.Outputs[1].LockingScript)
+		assert.Equal(t, 2, builder.Change)
+	})
+}
+
+func TestBuildTemplates(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	const txB = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+
+	keyA, addrA := testKey(t, 1)
+	keyB, _ := testKey(t, 2)
+	_, dest := testKey(t, 3)
+
+	p2pk := &script.Script{}
+	require.NoError(t, p2pk.AppendPushData(keyA.PubKey().Compressed()))
+	require.NoError(t, p2pk.AppendOpcodes(script.OpCHECKSIG))
+
+	// A hash puzzle anyone knowing the preimage can spend
+	preimage := []byte("carve")
+	puzzle := &script.Script{script.OpSHA256}
+	require.NoError(t, puzzle.AppendPushData(crypto.Sha256(preimage)))
+	require.NoError(t, puzzle.AppendOpcodes(script.OpEQUAL))
+	solution := &script.Script{}
+	require.NoError(t, solution.AppendPushData(preimage))
+
+	t.Run("signs P2PK and unlocks fixed scripts beside P2PKH", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{
+			{UTXO: &UTXO{TxHash: txA, TxPos: 0, Value: 5000}, Key: keyA, LockingScript: p2pk},
+			{UTXO: &UTXO{TxHash: txA, TxPos: 1, Value: 3000}, LockingScript: puzzle, Unlocking: solution},
+			{UTXO: &UTXO{TxHash: txB, TxPos: 0, Value: 2000}, Key: keyB},
+		}
+		tx, err := (&Builder{FeePerKb: 1000}).Build(inputs, dest, 6000, 1, addrA)
+		require.NoError(t, err)
+
+		assert.Equal(t, solution, tx.Inputs[1].UnlockingScript)
+		for i := range tx.Inputs {
+			require.NoError(t, interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, tx.Inputs[i].SourceTxOutput()),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			), "input %d", i)
+		}
+
+		// The fee pays for the smaller P2PK and puzzle inputs
+		fee, err := tx.GetFee()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, fee, uint64(tx.Size()))
+		assert.LessOrEqual(t, fee, uint64(tx.Size()+2))
+	})
+
+	t.Run("sizes unsigned template inputs from their scripts", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 5000}, LockingScript: puzzle, Unlocking: solution}}
+		tx, err := (&Builder{FeePerKb: 1000, DryRun: true}).Build(inputs, dest, 1000, 1, addrA)
+		require.NoError(t, err)
+		assert.Equal(t, 32+4+1+len(*solution)+4+2*OutputSize+BaseTxSize, EstimateSize(tx))
+	})
+
+	t.Run("rejects a P2PK input locked to another key", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 5000}, Key: keyB, LockingScript: p2pk}}
+		_, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 1000, 1, addrA)
+		require.EqualError(t, err, "P2PK input "+txA+":0 is locked to another key")
+	})
+
+	t.Run("rejects other scripts without an unlocking script", func(t *testing.T) {
+		t.Parallel()
+
+		inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 5000}, Key: keyA, LockingScript: puzzle}}
+		_, err := (&Builder{FeePerKb: 100}).Build(inputs, dest, 1000, 1, addrA)
+		require.EqualError(t, err, "input "+txA+":0 is not P2PK and has no unlocking script")
+	})
+}
+
+func TestBuildOutputs(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	keyA, addrA := testKey(t, 1)
+
+	data := &script.Script{}
+	require.NoError(t, data.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+	require.NoError(t, data.AppendPushData(make([]byte, 10000)))
+	outputs := []*transaction.TransactionOutput{{Satoshis: 0, LockingScript: data}}
+
+	builder := &Builder{FeePerKb: 100}
+	inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 10000}, Key: keyA}}
+
+	tx, err := builder.BuildOutputs(inputs, outputs, addrA)
+	require.NoError(t, err)
+	require.Len(t, tx.Outputs, 2)
+
+	// The fee covers the data output's actual size, not a fixed 34 bytes
+	fee := builder.EstimateFee(1, outputs)
+	assert.Greater(t, fee, uint64(MinFee))
+	assert.Equal(t, 10000-fee, tx.Outputs[1].Satoshis)
+	assert.GreaterOrEqual(t, fee, uint64(tx.Size())*builder.FeePerKb/1000)
+}
+
+func TestOutputsSize(t *testing.T) {
+	t.Parallel()
+
+	_, addr := testKey(t, 1)
+	lock, err := p2pkh.Lock(addr)
+	require.NoError(t, err)
+
+	// A P2PKH output is exactly OutputSize bytes
+	assert.Equal(t, OutputSize, OutputsSize([]*transaction.TransactionOutput{{LockingScript: lock}}))
+}
+
+// Benchmarks
+
+func BenchmarkCalculateFee(b *testing.B) {
+	builder := &Builder{FeePerKb: 1000}
+	for i := 0; i < b.N; i++ {
+		_ = builder.CalculateFee(5, 3)
+	}
+}
+
+func BenchmarkSelectUTXOs(b *testing.B) {
+	utxos := make([]*UTXO, 100)
+	for i := 0; i < 100; i++ {
+		utxos[i] = &UTXO{TxHash: "tx", TxPos: uint32(i), Value: uint64((i + 1) * 1000)}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = (&Builder{FeePerKb: 1000}).SelectUTXOs(utxos, 50000)
+	}
+}
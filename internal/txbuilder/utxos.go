@@ -0,0 +1,62 @@
+package txbuilder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+// FetchUTXOs fetches the unspent outputs of an address from the builder's
+// UTXO provider, or from WhatsOnChain when none is set. Outputs already spent
+// in the mempool are skipped and duplicates removed; an address without UTXOs
+// yields an empty slice.
+func (b *Builder) FetchUTXOs(ctx context.Context, addr string) ([]*UTXO, error) {
+	provider := b.UTXOs
+	if provider == nil {
+		woc, err := chain.NewWOC(ctx, b.Testnet)
+		if err != nil {
+			return nil, err
+		}
+		provider = woc
+	}
+
+	network := "mainnet"
+	if b.Testnet {
+		network = "testnet"
+	}
+	b.logf("Fetching UTXOs (%s)...", network)
+
+	utxos, err := provider.UTXOs(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range utxos {
+		b.logf("  UTXO %d: %s:%d = %d satoshis (height %d)", i+1, u.TxHash, u.TxPos, u.Value, u.Height)
+	}
+
+	return b.dedupeUTXOs(utxos), nil
+}
+
+// dedupeUTXOs removes duplicate UTXOs, keeping the first occurrence of each outpoint.
+func (b *Builder) dedupeUTXOs(utxos []*UTXO) []*UTXO {
+	// Deduplicate by txid:vout
+	seen := make(map[string]bool)
+	dedupedUTXOs := make([]*UTXO, 0, len(utxos))
+
+	for _, utxo := range utxos {
+		key := fmt.Sprintf("%s:%d", utxo.TxHash, utxo.TxPos)
+		if !seen[key] {
+			seen[key] = true
+			dedupedUTXOs = append(dedupedUTXOs, utxo)
+		} else {
+			b.logf("  Skipping duplicate UTXO: %s", key)
+		}
+	}
+
+	if len(dedupedUTXOs) < len(utxos) {
+		b.logf("Removed %d duplicate UTXO(s)", len(utxos)-len(dedupedUTXOs))
+	}
+
+	return dedupedUTXOs
+}
@@ -0,0 +1,126 @@
+package txbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/chain"
+)
+
+func TestFetchUTXOs(t *testing.T) {
+	t.Parallel()
+
+	mock := chain.NewMock()
+	mock.Unspent["1addr"] = []*UTXO{
+		{TxHash: "tx1", TxPos: 0, Value: 1000, Height: 100},
+		{TxHash: "tx1", TxPos: 0, Value: 1000, Height: 100}, // Duplicate
+		{TxHash: "tx2", TxPos: 1, Value: 2000},
+	}
+	builder := &Builder{UTXOs: mock}
+
+	utxos, err := builder.FetchUTXOs(context.Background(), "1addr")
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	assert.Equal(t, "tx1", utxos[0].TxHash)
+	assert.Equal(t, "tx2", utxos[1].TxHash)
+
+	utxos, err = builder.FetchUTXOs(context.Background(), "1empty")
+	require.NoError(t, err)
+	assert.Empty(t, utxos)
+}
+
+func TestDedupeUTXOs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx2", TxPos: 0, Value: 2000},
+			{TxHash: "tx3", TxPos: 0, Value: 3000},
+		}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("removes duplicates by txid:vout", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx1", TxPos: 0, Value: 1000}, // Duplicate
+			{TxHash: "tx1", TxPos: 1, Value: 2000}, // Different vout, not duplicate
+			{TxHash: "tx2", TxPos: 0, Value: 3000},
+		}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("preserves order of first occurrence", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "first", TxPos: 0, Value: 1000},
+			{TxHash: "second", TxPos: 0, Value: 2000},
+			{TxHash: "first", TxPos: 0, Value: 1000}, // Duplicate
+		}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		require.Len(t, result, 2)
+		assert.Equal(t, "first", result[0].TxHash)
+		assert.Equal(t, "second", result[1].TxHash)
+	})
+
+	t.Run("empty UTXO list", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		assert.Empty(t, result)
+	})
+
+	t.Run("single UTXO", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+		}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		assert.Len(t, result, 1)
+	})
+
+	t.Run("all duplicates become single", func(t *testing.T) {
+		t.Parallel()
+
+		utxos := []*UTXO{
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+			{TxHash: "tx1", TxPos: 0, Value: 1000},
+		}
+
+		result := (&Builder{}).dedupeUTXOs(utxos)
+		assert.Len(t, result, 1)
+	})
+}
+
+// Benchmarks
+
+func BenchmarkDedupeUTXOs(b *testing.B) {
+	utxos := make([]*UTXO, 100)
+	for i := 0; i < 100; i++ {
+		utxos[i] = &UTXO{TxHash: "tx", TxPos: uint32(i % 50), Value: uint64(i * 1000)} // Some duplicates
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = (&Builder{}).dedupeUTXOs(utxos)
+	}
+}
@@ -0,0 +1,49 @@
+package txbuilder
+
+import (
+	"fmt"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	sighash "github.com/bsv-blockchain/go-sdk/transaction/sighash"
+)
+
+// uncompressedUnlocker signs P2PKH inputs locked to the hash of an
+// uncompressed public key. The SDK's p2pkh template always pushes the
+// compressed key, which would not match that hash.
+type uncompressedUnlocker struct {
+	key  *ec.PrivateKey
+	flag sighash.Flag
+}
+
+// Sign produces the unlocking script <sig> <uncompressed pubkey>.
+func (u *uncompressedUnlocker) Sign(tx *transaction.Transaction, inputIndex uint32) (*script.Script, error) {
+	if tx.Inputs[inputIndex].SourceTxOutput() == nil {
+		return nil, transaction.ErrEmptyPreviousTx
+	}
+
+	sh, err := tx.CalcInputSignatureHash(inputIndex, u.flag)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := u.key.Sign(sh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign input %d: %w", inputIndex, err)
+	}
+
+	s := &script.Script{}
+	if err = s.AppendPushData(append(sig.Serialize(), byte(u.flag))); err != nil {
+		return nil, err
+	}
+	if err = s.AppendPushData(u.key.PubKey().Uncompressed()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// EstimateLength returns the maximum unlocking script length: a 73-byte
+// signature push and a 66-byte public key push.
+func (u *uncompressedUnlocker) EstimateLength(*transaction.Transaction, uint32) uint32 {
+	return 139
+}
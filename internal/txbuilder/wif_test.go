@@ -0,0 +1,65 @@
+package txbuilder
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/script/interpreter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/keys"
+)
+
+// uncompressedWIF is the uncompressed WIF of private key 1.
+const uncompressedWIF = "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+
+func TestBuildUncompressed(t *testing.T) {
+	t.Parallel()
+
+	const txA = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	w, err := keys.ParseWIF(uncompressedWIF)
+	require.NoError(t, err)
+	key := w.Key
+	source, err := script.NewAddressFromPublicKeyWithCompression(key.PubKey(), true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm", source.AddressString)
+	_, dest := testKey(t, 3)
+
+	inputs := []Input{{UTXO: &UTXO{TxHash: txA, Value: 100000}, Key: key, Uncompressed: true}}
+	tx, err := (&Builder{FeePerKb: 1000}).Build(inputs, dest, 50000, 1, source)
+	require.NoError(t, err)
+
+	// The input is locked to the uncompressed address and its signature verifies
+	prevOutput := tx.Inputs[0].SourceTxOutput()
+	pkh, err := prevOutput.LockingScript.PublicKeyHash()
+	require.NoError(t, err)
+	assert.Equal(t, []byte(source.PublicKeyHash), pkh)
+	require.NoError(t, interpreter.NewEngine().Execute(
+		interpreter.WithTx(tx, 0, prevOutput),
+		interpreter.WithForkID(),
+		interpreter.WithAfterGenesis(),
+	))
+
+	// The fee is paid for the signed size of the larger uncompressed input
+	fee, err := tx.GetFee()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(tx.Size()), fee)
+	assert.Greater(t, tx.Size(), InputSize+2*OutputSize+BaseTxSize)
+}
+
+func TestSelectUTXOsUncompressed(t *testing.T) {
+	t.Parallel()
+
+	// 50 inputs and two outputs: 7478 bytes compressed, 9078 uncompressed
+	utxos := make([]*UTXO, 50)
+	for i := range utxos {
+		utxos[i] = &UTXO{TxHash: "tx", TxPos: uint32(i), Value: 1000} //nolint:gosec // test index
+	}
+
+	_, err := (&Builder{FeePerKb: 1000}).SelectUTXOs(utxos, 42500)
+	require.NoError(t, err)
+	_, err = (&Builder{FeePerKb: 1000, Uncompressed: true}).SelectUTXOs(utxos, 42500)
+	require.ErrorContains(t, err, "insufficient funds")
+}
@@ -0,0 +1,337 @@
+package txinspect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+// ANSI color codes for terminal output styling
+const (
+	colorReset  = "\033[0m"  // Reset to default
+	colorRed    = "\033[31m" // Red text (errors)
+	colorGreen  = "\033[32m" // Green text (values, addresses)
+	colorYellow = "\033[33m" // Yellow text (warnings)
+	colorWhite  = "\033[37m" // White text (headers, structure)
+	colorDim    = "\033[2m"  // Dimmed text (labels, annotations)
+)
+
+// rule separates the transaction breakdown from its header and footer.
+const rule = "────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────"
+
+// Printer writes the human-readable breakdown of a transaction.
+type Printer struct {
+	W       io.Writer
+	NoColor bool // Write plain text without ANSI colors
+	Compact bool // Truncate script hex past 64 characters and ASM past 96
+	ASMOnly bool // Show scripts as ASM alone, without their hex
+	Testnet bool // Derive testnet addresses instead of mainnet
+}
+
+// OutputStatus is the spent status of an output, as WhatsOnChain reports it.
+type OutputStatus struct {
+	Unspendable bool   `json:"unspendable,omitempty"` // OP_RETURN data output, never looked up
+	SpentBy     string `json:"spentBy,omitempty"`     // Spending txid, empty while unspent
+	SpentVin    int    `json:"spentVin"`              // Input index within the spending transaction, set with SpentBy
+}
+
+// Print writes the breakdown of tx, decoded from format, with each output's
+// spent status when status is not nil.
+func (p *Printer) Print(tx *transaction.Transaction, format string, status map[int]*OutputStatus) {
+	p.printHeader(tx.TxID().String())
+	if format != spv.FormatRaw {
+		p.printf("%s %s\n", p.c(colorDim, "Format:"), strings.ToUpper(format))
+	}
+	p.printVersion(tx)
+	p.printInputs(tx)
+	p.printOutputs(tx, status)
+	p.printLocktime(tx)
+	p.printLint(tx)
+	p.printSummary(tx)
+	p.printFooter(tx)
+}
+
+// printf writes formatted text; write errors are ignored, as with fmt.Printf.
+func (p *Printer) printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(p.W, format, a...)
+}
+
+// c applies ANSI color codes to text if color output is enabled.
+// Returns plain text if NoColor is set, otherwise returns colorized text.
+func (p *Printer) c(color, text string) string {
+	if p.NoColor {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// printHeader prints the transaction breakdown header.
+func (p *Printer) printHeader(txid string) {
+	p.printf("%s %s\n",
+		p.c(colorDim, "TX ID:"),
+		p.c(colorGreen, txid))
+	p.printf("%s\n", p.c(colorWhite, rule))
+}
+
+// printVersion prints the transaction version.
+func (p *Printer) printVersion(tx *transaction.Transaction) {
+	p.printf("%s %d %s\n",
+		p.c(colorDim, "Version:"),
+		tx.Version,
+		p.c(colorDim, fmt.Sprintf("(0x%08x)", tx.Version)))
+}
+
+// printInputs prints the transaction inputs section.
+func (p *Printer) printInputs(tx *transaction.Transaction) {
+	inputCount := len(tx.Inputs)
+	p.printf("%s %d\n", p.c(colorDim, "Inputs:"), inputCount)
+
+	for i, input := range tx.Inputs {
+		p.printInput(i, input, tx.Version)
+	}
+}
+
+// printInput prints a single transaction input.
+func (p *Printer) printInput(index int, input *transaction.TransactionInput, version uint32) {
+	p.printf("\n%s\n", p.c(colorWhite, fmt.Sprintf("INPUT #%d", index)))
+
+	// Previous transaction ID and output index on same line
+	if input.SourceTXID != nil {
+		p.printf("  %s %s:%d\n",
+			p.c(colorDim, "Prev:"),
+			p.c(colorGreen, input.SourceTXID.String()),
+			input.SourceTxOutIndex)
+	} else {
+		p.printf("  %s %s\n",
+			p.c(colorDim, "Prev:"),
+			p.c(colorRed, "(null)"))
+	}
+
+	// Spent value, known when EF or BEEF carries the source output
+	if source := input.SourceTxOutput(); source != nil {
+		p.printf("  %s %s %s\n",
+			p.c(colorDim, "Value:"),
+			p.c(colorGreen, fmt.Sprintf("%d sats", source.Satoshis)),
+			p.c(colorDim, fmt.Sprintf("(%.8f BSV)", float64(source.Satoshis)/100000000.0)))
+	}
+
+	// Script
+	p.printUnlockingScript(input.UnlockingScript)
+
+	// Sequence number
+	p.printf("  %s %d %s\n",
+		p.c(colorDim, "Sequence:"),
+		input.SequenceNumber,
+		p.c(colorDim, fmt.Sprintf("(0x%08x, %s)", input.SequenceNumber, DescribeSequence(input.SequenceNumber, version))))
+}
+
+// truncate truncates script hex or ASM if compact mode is enabled and it
+// exceeds maxLen.
+func (p *Printer) truncate(text string, maxLen int) string {
+	if !p.Compact || len(text) <= maxLen {
+		return text
+	}
+	return text[:maxLen] + "..."
+}
+
+// printScript prints a script's hex and length followed by its ASM, or with
+// ASMOnly its ASM and length alone. A script that does not parse keeps its
+// hex, with the parse error in place of the ASM.
+func (p *Printer) printScript(s script.Script) {
+	size := p.c(colorDim, fmt.Sprintf("(%d bytes)", len(s)))
+	asm, err := scripts.Disassemble(s)
+	if err != nil || !p.ASMOnly {
+		p.printf("  %s %s %s\n", p.c(colorDim, "Script:"), p.c(colorDim, p.truncate(s.String(), 64)), size)
+	}
+
+	switch {
+	case err != nil:
+		p.printf("  %s %s\n", p.c(colorDim, "ASM:"), p.c(colorYellow, err.Error()))
+	case asm == "":
+		p.printf("  %s %s\n", p.c(colorDim, "ASM:"), p.c(colorDim, "(empty)"))
+	case p.ASMOnly:
+		p.printf("  %s %s %s\n", p.c(colorDim, "ASM:"), p.truncate(asm, 96), size)
+	default:
+		p.printf("  %s %s\n", p.c(colorDim, "ASM:"), p.truncate(asm, 96))
+	}
+}
+
+// printUnlockingScript prints the unlocking script details for an input.
+func (p *Printer) printUnlockingScript(unlockingScript *script.Script) {
+	kind := ClassifyUnlockingScript(unlockingScript)
+	if unlockingScript == nil {
+		p.printf("  %s %s\n", p.c(colorDim, "Script:"), p.c(colorDim, "(empty)"))
+		p.printf("  %s %s\n", p.c(colorDim, "Type:"), p.c(colorYellow, kind))
+		return
+	}
+
+	p.printScript(*unlockingScript)
+
+	color := colorGreen
+	if kind == SpendUnsigned || kind == SpendCustom {
+		color = colorYellow
+	}
+	p.printf("  %s %s\n", p.c(colorDim, "Type:"), p.c(color, kind))
+
+	// Only a P2PKH spend reveals the key, and so the address, it spends from
+	if kind != SpendP2PKH {
+		return
+	}
+	addr := UnlockingScriptAddress(unlockingScript, !p.Testnet)
+	if addr != "" {
+		p.printf("  %s %s\n", p.c(colorDim, "Address:"), p.c(colorGreen, addr))
+	}
+}
+
+// printOutputs prints the transaction outputs section, with each output's
+// spent status when status is not nil.
+func (p *Printer) printOutputs(tx *transaction.Transaction, status map[int]*OutputStatus) {
+	outputCount := len(tx.Outputs)
+	p.printf("%s %d\n", p.c(colorDim, "Outputs:"), outputCount)
+
+	for i, output := range tx.Outputs {
+		p.printOutput(i, output)
+		if status != nil {
+			p.printOutputStatus(status[i])
+		}
+	}
+}
+
+// printOutput prints a single transaction output.
+func (p *Printer) printOutput(index int, output *transaction.TransactionOutput) {
+	p.printf("\n%s\n", p.c(colorWhite, fmt.Sprintf("OUTPUT #%d", index)))
+
+	// Value in satoshis
+	satoshis := output.Satoshis
+	btc := float64(satoshis) / 100000000.0
+	p.printf("  %s %s %s\n",
+		p.c(colorDim, "Value:"),
+		p.c(colorGreen, fmt.Sprintf("%d sats", satoshis)),
+		p.c(colorDim, fmt.Sprintf("(%.8f BSV)", btc)))
+
+	// Locking script
+	p.printLockingScript(output.LockingScript)
+	p.printData(output.LockingScript)
+}
+
+// printData prints the protocols of a data output and their fields, for
+// anything but a data output printing nothing.
+func (p *Printer) printData(lockingScript *script.Script) {
+	for _, protocol := range DecodeData(lockingScript) {
+		name := protocol.Name
+		if protocol.Prefix != "" && protocol.Name != ProtocolRun {
+			name += " " + p.c(colorDim, "("+protocol.Prefix+")")
+		}
+		p.printf("  %s %s\n", p.c(colorDim, "Data:"), p.c(colorGreen, name))
+		for _, field := range protocol.Fields {
+			p.printf("    %s %s\n", p.c(colorDim, field.Name+":"), p.truncate(field.Value, 96))
+		}
+	}
+}
+
+// printLockingScript prints the locking script details for an output.
+func (p *Printer) printLockingScript(lockingScript *script.Script) {
+	if lockingScript == nil {
+		p.printf("  %s %s\n", p.c(colorDim, "Script:"), p.c(colorDim, "(empty)"))
+		return
+	}
+
+	p.printScript(*lockingScript)
+
+	// Try to extract P2PKH address
+	addr := P2PKHAddress(lockingScript, !p.Testnet)
+	if addr != "" {
+		p.printf("  %s %s\n", p.c(colorDim, "Address:"), p.c(colorGreen, addr))
+	}
+}
+
+// printOutputStatus prints whether an output is spent, and by which input.
+func (p *Printer) printOutputStatus(s *OutputStatus) {
+	switch {
+	case s.Unspendable:
+		p.printf("  %s %s\n", p.c(colorDim, "Status:"), p.c(colorDim, "unspendable (data)"))
+	case s.SpentBy != "":
+		p.printf("  %s %s %s\n",
+			p.c(colorDim, "Status:"),
+			p.c(colorRed, "spent"),
+			p.c(colorDim, fmt.Sprintf("by %s:%d", s.SpentBy, s.SpentVin)))
+	default:
+		p.printf("  %s %s\n", p.c(colorDim, "Status:"), p.c(colorGreen, "unspent"))
+	}
+}
+
+// printLocktime prints the transaction locktime and whether it is enforced.
+func (p *Printer) printLocktime(tx *transaction.Transaction) {
+	p.printf("\n%s %d %s\n",
+		p.c(colorDim, "nLockTime:"),
+		tx.LockTime,
+		p.c(colorDim, DescribeLockTime(tx.LockTime)))
+
+	if tx.LockTime != 0 {
+		p.printf("  %s %s\n", p.c(colorDim, "Enforced:"), DescribeLockEnforcement(tx))
+	}
+}
+
+// printLint prints the issues found by Lint.
+func (p *Printer) printLint(tx *transaction.Transaction) {
+	issues := Lint(tx)
+	if len(issues) == 0 {
+		p.printf("\n%s %s\n", p.c(colorDim, "Lint:"), p.c(colorGreen, "no issues"))
+		return
+	}
+
+	p.printf("\n%s %d issue(s)\n", p.c(colorDim, "Lint:"), len(issues))
+	for _, issue := range issues {
+		p.printf("  %s %s\n", p.c(colorRed, "!"), issue)
+	}
+}
+
+// printSummary prints the value totals and the value each address receives,
+// with what it spends and its net flow once every input's source output is
+// known.
+func (p *Printer) printSummary(tx *transaction.Transaction) {
+	s := Summarize(tx, p.Testnet)
+	p.printf("\n%s\n", p.c(colorDim, "Summary:"))
+	p.printf("  %s %s %s\n",
+		p.c(colorDim, "Total out:"),
+		p.c(colorGreen, fmt.Sprintf("%d sats", s.OutputTotal)),
+		p.c(colorDim, fmt.Sprintf("(%.8f BSV)", float64(s.OutputTotal)/100000000.0)))
+	if s.InputTotal != nil {
+		p.printf("  %s %s %s\n",
+			p.c(colorDim, "Total in: "),
+			p.c(colorGreen, fmt.Sprintf("%d sats", *s.InputTotal)),
+			p.c(colorDim, fmt.Sprintf("(%.8f BSV)", float64(*s.InputTotal)/100000000.0)))
+		color := colorGreen
+		if *s.Fee < 0 {
+			color = colorRed
+		}
+		p.printf("  %s %s\n", p.c(colorDim, "Fee:      "), p.c(color, fmt.Sprintf("%d sats", *s.Fee)))
+	}
+
+	width := 0
+	for _, a := range s.Addresses {
+		width = max(width, len(a.Address))
+	}
+	for _, a := range s.Addresses {
+		addr := p.c(colorGreen, fmt.Sprintf("%-*s", width, a.Address))
+		if s.InputTotal == nil {
+			p.printf("  %s  received %d sats\n", addr, a.Received)
+			continue
+		}
+		p.printf("  %s  spent %d, received %d, net %+d sats\n", addr, a.Spent, a.Received, a.Net)
+	}
+}
+
+// printFooter prints the transaction footer with TXID.
+func (p *Printer) printFooter(tx *transaction.Transaction) {
+	p.printf("%s\n", p.c(colorWhite, rule))
+	p.printf("%s %s\n",
+		p.c(colorDim, "TX ID:"),
+		p.c(colorGreen, tx.TxID().String()))
+}
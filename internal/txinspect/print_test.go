@@ -0,0 +1,130 @@
+package txinspect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/datatx"
+	"github.com/mrz1836/go-template/internal/spv"
+)
+
+func TestC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("color enabled", func(t *testing.T) {
+		t.Parallel()
+
+		result := (&Printer{}).c(colorRed, "test")
+		assert.Contains(t, result, colorRed)
+		assert.Contains(t, result, "test")
+		assert.Contains(t, result, colorReset)
+	})
+
+	t.Run("color disabled", func(t *testing.T) {
+		t.Parallel()
+
+		result := (&Printer{NoColor: true}).c(colorRed, "test")
+		assert.Equal(t, "test", result)
+		assert.NotContains(t, result, colorRed)
+		assert.NotContains(t, result, colorReset)
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		t.Parallel()
+
+		result := (&Printer{}).c(colorGreen, "")
+		assert.Equal(t, colorGreen+colorReset, result)
+	})
+}
+
+func TestColorConstants(t *testing.T) {
+	t.Parallel()
+
+	// Verify ANSI color codes are correct
+	assert.Equal(t, "\033[0m", colorReset)
+	assert.Equal(t, "\033[31m", colorRed)
+	assert.Equal(t, "\033[32m", colorGreen)
+	assert.Equal(t, "\033[33m", colorYellow)
+	assert.Equal(t, "\033[37m", colorWhite)
+	assert.Equal(t, "\033[2m", colorDim)
+}
+
+func TestPrint(t *testing.T) {
+	t.Parallel()
+
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	p2pkh, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	require.NoError(t, tx.AddInputFrom(txid, 0, p2pkh.String(), 1000, nil))
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 900, LockingScript: p2pkh})
+
+	t.Run("mainnet", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		(&Printer{W: &out, NoColor: true}).Print(tx, spv.FormatEF, map[int]*OutputStatus{0: {SpentBy: txid, SpentVin: 2}})
+		assert.Contains(t, out.String(), "TX ID: "+tx.TxID().String())
+		assert.Contains(t, out.String(), "Format: EF")
+		assert.Contains(t, out.String(), "Value: 1000 sats")
+		assert.Contains(t, out.String(), "Type: "+SpendUnsigned)
+		assert.Contains(t, out.String(), "Script: 76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac (25 bytes)")
+		assert.Contains(t, out.String(), "ASM: OP_DUP OP_HASH160 62e907b15cbf27d5425399ebf6f0fb50ebb88f18 OP_EQUALVERIFY OP_CHECKSIG\n")
+		assert.Contains(t, out.String(), "Address: 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+		assert.Contains(t, out.String(), "Status: spent by "+txid+":2")
+		assert.Contains(t, out.String(), "Lint: no issues")
+		assert.Contains(t, out.String(), "Fee:       100 sats")
+		assert.Contains(t, out.String(), "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa  spent 1000, received 900, net -100 sats")
+		assert.NotContains(t, out.String(), colorReset)
+	})
+
+	t.Run("testnet addresses", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		(&Printer{W: &out, NoColor: true, Testnet: true}).Print(tx, spv.FormatRaw, nil)
+		assert.NotContains(t, out.String(), "Format:")
+		assert.NotContains(t, out.String(), "Status:")
+		assert.Contains(t, out.String(), "Address: mpXwg4jMtRhuSpVq4xS3HFHmCmWp9NyGKt")
+	})
+
+	t.Run("ASM only", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		(&Printer{W: &out, NoColor: true, ASMOnly: true}).Print(tx, spv.FormatRaw, nil)
+		assert.NotContains(t, out.String(), "Script: 76a914")
+		assert.Contains(t, out.String(), "ASM: OP_DUP OP_HASH160 62e907b15cbf27d5425399ebf6f0fb50ebb88f18 OP_EQUALVERIFY OP_CHECKSIG (25 bytes)")
+	})
+
+	t.Run("data output protocols", func(t *testing.T) {
+		t.Parallel()
+
+		data := transaction.NewTransaction()
+		data.AddOutput(&transaction.TransactionOutput{LockingScript: dataOutput(t, datatx.BPrefix, "hi", "text/plain")})
+
+		var out bytes.Buffer
+		(&Printer{W: &out, NoColor: true}).Print(data, spv.FormatRaw, nil)
+		assert.Contains(t, out.String(), "  Data: B:// ("+datatx.BPrefix+")\n    content: \"hi\"\n    media type: \"text/plain\"\n")
+	})
+
+	t.Run("unparseable script keeps its hex", func(t *testing.T) {
+		t.Parallel()
+
+		// A push of 5 bytes with only 1 following
+		truncated := script.Script{0x05, 0x01}
+		broken := transaction.NewTransaction()
+		broken.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: &truncated})
+
+		var out bytes.Buffer
+		(&Printer{W: &out, NoColor: true, ASMOnly: true}).Print(broken, spv.FormatRaw, nil)
+		assert.Contains(t, out.String(), "Script: 0501 (2 bytes)")
+		assert.Contains(t, out.String(), "ASM: invalid script at byte 0")
+	})
+}
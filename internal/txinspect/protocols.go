@@ -0,0 +1,237 @@
+package txinspect
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+
+	"github.com/mrz1836/go-template/internal/datatx"
+)
+
+// Names of the data protocols DecodeData recognizes
+const (
+	ProtocolB        = "B://"
+	ProtocolBcat     = "Bcat"
+	ProtocolBcatPart = "Bcat part"
+	ProtocolMAP      = "MAP"
+	ProtocolAIP      = "AIP"
+	ProtocolRun      = "Run"
+	ProtocolBitcom   = "Bitcom" // A Bitcom address prefix of a protocol not decoded further
+	ProtocolRaw      = "raw"    // Pushes without a recognized prefix
+)
+
+// Data protocol details
+const (
+	runPrefix      = "run" // First push of a Run token output
+	pipe           = "|"   // Bitcom separator between protocols in one output
+	textPreviewLen = 80    // Characters of text shown before it is cut
+	hexPreviewLen  = 64    // Hex characters of binary data shown before it is cut
+)
+
+// DataProtocol is one protocol of a data output: Bitcom outputs chain
+// several, such as B:// | MAP | AIP, separated by a "|" push.
+type DataProtocol struct {
+	Name   string      `json:"name"`             // One of the Protocol* constants
+	Prefix string      `json:"prefix,omitempty"` // Bitcom address or other prefix push
+	Fields []DataField `json:"fields"`
+}
+
+// DataField is one decoded push of a DataProtocol, with text shown quoted
+// and binary data as hex, both cut when long.
+type DataField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DecodeData splits a data output into its protocols and names their
+// fields. It returns nil for any script that is not a data output, or whose
+// pushes do not parse.
+func DecodeData(s *script.Script) []DataProtocol {
+	pushes, ok := datatx.Pushes(s)
+	if !ok || len(pushes) == 0 {
+		return nil
+	}
+
+	var protocols []DataProtocol
+	start := 0
+	for i := 0; i <= len(pushes); i++ {
+		if i < len(pushes) && string(pushes[i]) != pipe {
+			continue
+		}
+		if i > start {
+			protocols = append(protocols, decodeProtocol(pushes[start:i]))
+		}
+		start = i + 1
+	}
+	return protocols
+}
+
+// decodeProtocol names the fields of one protocol's pushes by their prefix.
+func decodeProtocol(pushes [][]byte) DataProtocol {
+	prefix, fields := string(pushes[0]), pushes[1:]
+	p := DataProtocol{Name: ProtocolRaw}
+	switch {
+	case prefix == datatx.BPrefix:
+		p.Name = ProtocolB
+		p.addNamed(fields, "content", "media type", "encoding", "filename")
+	case prefix == datatx.BcatPrefix:
+		p.Name = ProtocolBcat
+		p.addNamed(fields[:min(len(fields), 5)], "info", "media type", "encoding", "filename", "flag")
+		if len(fields) > 5 {
+			for _, part := range fields[5:] {
+				p.add("part", hex.EncodeToString(part))
+			}
+		}
+	case prefix == datatx.BcatPartPrefix:
+		p.Name = ProtocolBcatPart
+		p.addNamed(fields, "data")
+	case prefix == datatx.MAPPrefix:
+		p.Name = ProtocolMAP
+		p.addMAP(fields)
+	case prefix == datatx.AIPPrefix:
+		p.Name = ProtocolAIP
+		p.addAIP(fields)
+	case prefix == runPrefix:
+		p.Name = ProtocolRun
+		if len(fields) > 0 && len(fields[0]) == 1 {
+			p.add("version", strconv.Itoa(int(fields[0][0])))
+			fields = fields[1:]
+		}
+		p.addNamed(fields, "app", "payload")
+	case isBitcomAddress(prefix):
+		p.Name = ProtocolBitcom
+		p.addNamed(fields)
+	default:
+		p.addNamed(pushes)
+		return p
+	}
+	p.Prefix = prefix
+	return p
+}
+
+// add appends a field.
+func (p *DataProtocol) add(name, value string) {
+	p.Fields = append(p.Fields, DataField{Name: name, Value: value})
+}
+
+// addNamed appends pushes as fields named in order, and any beyond the
+// names by their index.
+func (p *DataProtocol) addNamed(pushes [][]byte, names ...string) {
+	for i, push := range pushes {
+		name := fmt.Sprintf("#%d", i)
+		if i < len(names) {
+			name = names[i]
+		}
+		p.add(name, preview(push))
+	}
+}
+
+// addMAP appends a MAP command's fields: SET pairs named by their keys,
+// REMOVE keys, and for ADD and DELETE a key followed by its values.
+func (p *DataProtocol) addMAP(pushes [][]byte) {
+	if len(pushes) == 0 {
+		return
+	}
+	command := strings.ToUpper(string(pushes[0]))
+	p.add("command", command)
+	rest := pushes[1:]
+	switch command {
+	case "SET":
+		for i := 0; i+1 < len(rest); i += 2 {
+			p.add(label(rest[i]), preview(rest[i+1]))
+		}
+		if len(rest)%2 == 1 {
+			p.add("unpaired key", preview(rest[len(rest)-1]))
+		}
+	case "REMOVE":
+		for _, key := range rest {
+			p.add("key", preview(key))
+		}
+	default:
+		for i, push := range rest {
+			name := "value"
+			if i == 0 {
+				name = "key"
+			}
+			p.add(name, preview(push))
+		}
+	}
+}
+
+// addAIP appends an AIP signature's algorithm, signing address, signature
+// and the indexes of the pushes it signs, all of them when none are listed.
+func (p *DataProtocol) addAIP(pushes [][]byte) {
+	p.addNamed(pushes[:min(len(pushes), 3)], "algorithm", "address", "signature")
+	if len(pushes) <= 3 {
+		return
+	}
+	indexes := make([]string, 0, len(pushes)-3)
+	for _, push := range pushes[3:] {
+		switch {
+		case isText(push):
+			indexes = append(indexes, string(push))
+		case len(push) == 1:
+			indexes = append(indexes, strconv.Itoa(int(push[0])))
+		default:
+			indexes = append(indexes, hex.EncodeToString(push))
+		}
+	}
+	p.add("signed fields", strings.Join(indexes, ", "))
+}
+
+// label returns a MAP key as a field name: as is when it is text, else as hex.
+func label(b []byte) string {
+	if isText(b) && len(b) > 0 {
+		return string(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// preview shows a push readably: printable UTF-8 quoted, anything else as
+// hex, both cut when long, with the length of whatever was cut.
+func preview(b []byte) string {
+	if len(b) == 0 {
+		return "(empty)"
+	}
+	if isText(b) {
+		runes := []rune(string(b))
+		if len(runes) <= textPreviewLen {
+			return strconv.Quote(string(b))
+		}
+		return fmt.Sprintf("%s... (%d bytes)", strconv.Quote(string(runes[:textPreviewLen])), len(b))
+	}
+	h := hex.EncodeToString(b)
+	if len(h) > hexPreviewLen {
+		h = h[:hexPreviewLen] + "..."
+	}
+	return fmt.Sprintf("%s (%d bytes)", h, len(b))
+}
+
+// isText reports whether b is UTF-8 made of printable characters and
+// whitespace.
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBitcomAddress reports whether s looks like the Bitcoin address that
+// prefixes a Bitcom protocol.
+func isBitcomAddress(s string) bool {
+	if len(s) < 26 || len(s) > 35 || s[0] != '1' {
+		return false
+	}
+	_, err := script.NewAddressFromString(s)
+	return err == nil
+}
@@ -0,0 +1,100 @@
+package txinspect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/datatx"
+)
+
+// dataOutput builds OP_FALSE OP_RETURN followed by pushes.
+func dataOutput(t *testing.T, pushes ...string) *script.Script {
+	t.Helper()
+	s := &script.Script{}
+	require.NoError(t, s.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+	for _, push := range pushes {
+		require.NoError(t, s.AppendPushData([]byte(push)))
+	}
+	return s
+}
+
+func TestDecodeData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("B:// with MAP and AIP", func(t *testing.T) {
+		t.Parallel()
+
+		s := dataOutput(t,
+			datatx.BPrefix, "hello world", "text/plain", "utf-8", "hello.txt", "|",
+			datatx.MAPPrefix, "SET", "app", "carve", "type", "post", "|",
+			datatx.AIPPrefix, "BITCOIN_ECDSA", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "H+sig=", "0", "1")
+		assert.Equal(t, []DataProtocol{
+			{Name: ProtocolB, Prefix: datatx.BPrefix, Fields: []DataField{
+				{"content", `"hello world"`}, {"media type", `"text/plain"`}, {"encoding", `"utf-8"`}, {"filename", `"hello.txt"`},
+			}},
+			{Name: ProtocolMAP, Prefix: datatx.MAPPrefix, Fields: []DataField{
+				{"command", "SET"}, {"app", `"carve"`}, {"type", `"post"`},
+			}},
+			{Name: ProtocolAIP, Prefix: datatx.AIPPrefix, Fields: []DataField{
+				{"algorithm", `"BITCOIN_ECDSA"`}, {"address", `"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"`}, {"signature", `"H+sig="`},
+				{"signed fields", "0, 1"},
+			}},
+		}, DecodeData(s))
+	})
+
+	t.Run("Bcat lists its parts", func(t *testing.T) {
+		t.Parallel()
+
+		const part = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+		s, err := datatx.BcatScript(&datatx.File{MimeType: "video/mp4"}, "", "gzip", []string{part})
+		require.NoError(t, err)
+		protocols := DecodeData(s)
+		require.Len(t, protocols, 1)
+		assert.Equal(t, ProtocolBcat, protocols[0].Name)
+		assert.Contains(t, protocols[0].Fields, DataField{"media type", `"video/mp4"`})
+		assert.Contains(t, protocols[0].Fields, DataField{"flag", `"gzip"`})
+		assert.Equal(t, DataField{"part", part}, protocols[0].Fields[len(protocols[0].Fields)-1])
+	})
+
+	t.Run("Run token", func(t *testing.T) {
+		t.Parallel()
+
+		s := dataOutput(t, "run", "\x05", "", `{"in":0,"ref":[],"out":[],"del":[],"cre":[],"exec":[]}`)
+		assert.Equal(t, []DataProtocol{{Name: ProtocolRun, Prefix: "run", Fields: []DataField{
+			{"version", "5"}, {"app", "(empty)"}, {"payload", `"{\"in\":0,\"ref\":[],\"out\":[],\"del\":[],\"cre\":[],\"exec\":[]}"`},
+		}}}, DecodeData(s))
+	})
+
+	t.Run("unknown Bitcom prefix and raw pushes", func(t *testing.T) {
+		t.Parallel()
+
+		s := dataOutput(t, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "x", "|", "hello", "\x00\x01")
+		assert.Equal(t, []DataProtocol{
+			{Name: ProtocolBitcom, Prefix: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", Fields: []DataField{{"#0", `"x"`}}},
+			{Name: ProtocolRaw, Fields: []DataField{{"#0", `"hello"`}, {"#1", "0001 (2 bytes)"}}},
+		}, DecodeData(s))
+	})
+
+	t.Run("long values are cut", func(t *testing.T) {
+		t.Parallel()
+
+		protocols := DecodeData(dataOutput(t, strings.Repeat("a", 100), strings.Repeat("\xff", 40)))
+		require.Len(t, protocols, 1)
+		assert.Equal(t, `"`+strings.Repeat("a", 80)+`"... (100 bytes)`, protocols[0].Fields[0].Value)
+		assert.Equal(t, strings.Repeat("ff", 32)+"... (40 bytes)", protocols[0].Fields[1].Value)
+	})
+
+	t.Run("other scripts", func(t *testing.T) {
+		t.Parallel()
+
+		p2pkh, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+		require.NoError(t, err)
+		assert.Nil(t, DecodeData(p2pkh))
+		assert.Nil(t, DecodeData(nil))
+		assert.Nil(t, DecodeData(dataOutput(t)))
+	})
+}
@@ -0,0 +1,85 @@
+package txinspect
+
+import (
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// Summary totals the value a transaction moves, overall and per address.
+type Summary struct {
+	OutputTotal uint64        `json:"outputTotal"`
+	InputTotal  *uint64       `json:"inputTotal,omitempty"` // Known when every input carries its source output
+	Fee         *int64        `json:"fee,omitempty"`        // Input total less output total, known with the input total
+	Addresses   []AddressFlow `json:"addresses"`
+}
+
+// AddressFlow is the value one address spends and receives in a transaction.
+// Scripts that pay no single address are grouped under their template name
+// in parentheses, such as "(nulldata)".
+type AddressFlow struct {
+	Address  string `json:"address"`
+	Spent    uint64 `json:"spent"`    // Value of the inputs spending from the address, where known
+	Received uint64 `json:"received"` // Value of the outputs paying the address
+	Net      int64  `json:"net"`      // Received less spent
+}
+
+// Summarize totals the value tx moves, grouping inputs and outputs by the
+// testnet or mainnet address their locking script pays. Inputs count toward
+// an address only when their source output is known, so net flow is complete
+// only when InputTotal is set.
+func Summarize(tx *transaction.Transaction, testnet bool) *Summary {
+	s := &Summary{Addresses: []AddressFlow{}}
+	index := make(map[string]int)
+	flow := func(lockingScript *script.Script) *AddressFlow {
+		addr := summaryAddress(lockingScript, !testnet)
+		i, ok := index[addr]
+		if !ok {
+			i = len(s.Addresses)
+			index[addr] = i
+			s.Addresses = append(s.Addresses, AddressFlow{Address: addr})
+		}
+		return &s.Addresses[i]
+	}
+
+	var inputTotal uint64
+	known := true
+	for _, input := range tx.Inputs {
+		source := input.SourceTxOutput()
+		if source == nil {
+			known = false
+			continue
+		}
+		inputTotal += source.Satoshis
+		flow(source.LockingScript).Spent += source.Satoshis
+	}
+	for _, output := range tx.Outputs {
+		s.OutputTotal += output.Satoshis
+		flow(output.LockingScript).Received += output.Satoshis
+	}
+	for i := range s.Addresses {
+		s.Addresses[i].Net = int64(s.Addresses[i].Received) - int64(s.Addresses[i].Spent)
+	}
+
+	if known && len(tx.Inputs) > 0 {
+		fee := int64(inputTotal) - int64(s.OutputTotal)
+		s.InputTotal = &inputTotal
+		s.Fee = &fee
+	}
+	return s
+}
+
+// summaryAddress names what a locking script pays: its address when it pays
+// exactly one, otherwise its template in parentheses.
+func summaryAddress(lockingScript *script.Script, mainnet bool) string {
+	var b []byte
+	if lockingScript != nil {
+		b = *lockingScript
+	}
+	info := scripts.Classify(b, mainnet)
+	if len(info.Addresses) == 1 {
+		return info.Addresses[0]
+	}
+	return "(" + info.Type + ")"
+}
@@ -0,0 +1,75 @@
+package txinspect
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	sender, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	require.NoError(t, err)
+	recipient, err := script.NewFromHex("76a914751e76e8199196d454941c45d1b3a323f1433bd688ac")
+	require.NoError(t, err)
+	data, err := script.NewFromHex("006a0568656c6c6f")
+	require.NoError(t, err)
+
+	// newTx spends 1000 and 500 satoshis from sender, leaving the second
+	// source output unknown unless known is set
+	newTx := func(known bool) *transaction.Transaction {
+		tx := transaction.NewTransaction()
+		require.NoError(t, tx.AddInputFrom(txid, 0, sender.String(), 1000, nil))
+		if known {
+			require.NoError(t, tx.AddInputFrom(txid, 1, sender.String(), 500, nil))
+		} else {
+			tx.AddInput(&transaction.TransactionInput{SourceTXID: tx.Inputs[0].SourceTXID, SourceTxOutIndex: 1})
+		}
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 600, LockingScript: recipient})
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: data})
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 850, LockingScript: sender})
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 10, LockingScript: recipient})
+		return tx
+	}
+
+	t.Run("all source outputs known", func(t *testing.T) {
+		t.Parallel()
+
+		s := Summarize(newTx(true), false)
+		assert.Equal(t, uint64(1460), s.OutputTotal)
+		require.NotNil(t, s.InputTotal)
+		assert.Equal(t, uint64(1500), *s.InputTotal)
+		require.NotNil(t, s.Fee)
+		assert.Equal(t, int64(40), *s.Fee)
+		assert.Equal(t, []AddressFlow{
+			{Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", Spent: 1500, Received: 850, Net: -650},
+			{Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Received: 610, Net: 610},
+			{Address: "(nulldata)"},
+		}, s.Addresses)
+	})
+
+	t.Run("missing source output", func(t *testing.T) {
+		t.Parallel()
+
+		s := Summarize(newTx(false), true)
+		assert.Equal(t, uint64(1460), s.OutputTotal)
+		assert.Nil(t, s.InputTotal)
+		assert.Nil(t, s.Fee)
+		assert.Equal(t, "mpXwg4jMtRhuSpVq4xS3HFHmCmWp9NyGKt", s.Addresses[0].Address)
+		assert.Equal(t, uint64(1000), s.Addresses[0].Spent)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		t.Parallel()
+
+		s := Summarize(transaction.NewTransaction(), false)
+		assert.Zero(t, s.OutputTotal)
+		assert.Nil(t, s.InputTotal)
+		assert.Empty(t, s.Addresses)
+	})
+}
@@ -0,0 +1,505 @@
+// Package txinspect breaks a transaction down for display: the colorized
+// listing prettytx prints, or the same breakdown as a JSON report. It is
+// shared by the tools that show a transaction they decoded or fetched.
+//
+// The package supports:
+//   - Printing version, inputs, outputs, scripts, locktime, lint issues, and a value summary
+//   - Classifying each input's unlocking script by spend type
+//   - Decoding sequence numbers and whether nLockTime is enforced
+//   - Linting for dust outputs, oversized or non-push scripts, and duplicate inputs
+//   - Separating the policy violations nodes reject a transaction for from advisory lint
+//   - Totaling value in, out, and per address, with the fee and net flow when source outputs are known
+//   - Decoding data outputs into their protocols: B://, Bcat, MAP, AIP, Run, and other Bitcom prefixes
+//   - Building a JSON report of the same breakdown
+package txinspect
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/scripts"
+)
+
+// Locktime and sequence semantics
+const (
+	lockTimeThreshold  = 500000000  // nLockTime values below this are block heights, at or above are timestamps
+	sequenceFinal      = 0xffffffff // An input with this sequence is final and ignores nLockTime
+	seqDisableFlag     = 1 << 31    // BIP68: relative locktime disabled
+	seqTypeFlag        = 1 << 22    // BIP68: relative locktime in 512-second units rather than blocks
+	seqLockMask        = 0x0000ffff // BIP68: relative locktime value
+	seqTimeGranularity = 512        // BIP68: seconds per time-based unit
+)
+
+// Node policy limits checked by Lint and PolicyViolations
+const (
+	dustLimit         = 1        // Current BSV policy dust limit in satoshis
+	legacyDustLimit   = 546      // Dust threshold some wallets and services still enforce
+	maxScriptSize     = 500000   // SV Node default maxscriptsizepolicy in bytes
+	maxStandardTxSize = 10000000 // SV Node default maxtxsizepolicy in bytes
+)
+
+// Unlocking script spend types returned by ClassifyUnlockingScript
+const (
+	SpendUnsigned = "unsigned (empty script)"
+	SpendP2PKH    = "P2PKH (signature + public key)"
+	SpendP2PK     = "P2PK (signature)"
+	SpendData     = "data-only pushes"
+	SpendCustom   = "custom (non-push opcodes)"
+)
+
+// Report is the JSON breakdown of a transaction.
+type Report struct {
+	TxID             string   `json:"txid"`
+	Format           string   `json:"format"` // raw, ef, or beef, as decoded
+	Version          uint32   `json:"version"`
+	Size             int      `json:"size"`
+	Inputs           []Input  `json:"inputs"`
+	Outputs          []Output `json:"outputs"`
+	LockTime         uint32   `json:"lockTime"`
+	LockTimeEnforced bool     `json:"lockTimeEnforced"` // Non-zero and at least one input non-final
+	Lint             []string `json:"lint"`
+	Summary          *Summary `json:"summary"`
+}
+
+// Input is one input of a Report.
+type Input struct {
+	PrevTxID        string  `json:"prevTxid,omitempty"`
+	PrevVout        uint32  `json:"prevVout"`
+	Satoshis        *uint64 `json:"satoshis,omitempty"` // Spent value, known when EF or BEEF carries the source output
+	UnlockingScript string  `json:"unlockingScript"`
+	UnlockingASM    string  `json:"unlockingAsm,omitempty"` // Disassembled, when the script parses
+	SpendType       string  `json:"spendType"`
+	Address         string  `json:"address,omitempty"` // Spending address, shown for P2PKH spends only
+	Sequence        uint32  `json:"sequence"`
+	SequenceInfo    string  `json:"sequenceInfo"`
+}
+
+// Output is one output of a Report.
+type Output struct {
+	Satoshis      uint64               `json:"satoshis"`
+	LockingScript string               `json:"lockingScript"`
+	LockingASM    string               `json:"lockingAsm,omitempty"` // Disassembled, when the script parses
+	Template      scripts.TemplateInfo `json:"template"`
+	Data          []DataProtocol       `json:"data,omitempty"`   // Protocols of a data output
+	Status        *OutputStatus        `json:"status,omitempty"` // Spent status, when looked up
+}
+
+// Inspect builds the report of tx, decoded from format, with addresses for
+// testnet or mainnet.
+func Inspect(tx *transaction.Transaction, format string, testnet bool) *Report {
+	r := &Report{
+		TxID:             tx.TxID().String(),
+		Format:           format,
+		Version:          tx.Version,
+		Size:             tx.Size(),
+		Inputs:           make([]Input, 0, len(tx.Inputs)),
+		Outputs:          make([]Output, 0, len(tx.Outputs)),
+		LockTime:         tx.LockTime,
+		LockTimeEnforced: tx.LockTime != 0 && nonFinalInputs(tx) > 0,
+		Lint:             append([]string{}, Lint(tx)...),
+		Summary:          Summarize(tx, testnet),
+	}
+
+	for _, input := range tx.Inputs {
+		in := Input{
+			PrevVout:     input.SourceTxOutIndex,
+			SpendType:    ClassifyUnlockingScript(input.UnlockingScript),
+			Sequence:     input.SequenceNumber,
+			SequenceInfo: DescribeSequence(input.SequenceNumber, tx.Version),
+		}
+		if input.SourceTXID != nil {
+			in.PrevTxID = input.SourceTXID.String()
+		}
+		if source := input.SourceTxOutput(); source != nil {
+			sats := source.Satoshis
+			in.Satoshis = &sats
+		}
+		if input.UnlockingScript != nil {
+			in.UnlockingScript = input.UnlockingScript.String()
+			in.UnlockingASM, _ = scripts.Disassemble(*input.UnlockingScript)
+		}
+		if in.SpendType == SpendP2PKH {
+			in.Address = UnlockingScriptAddress(input.UnlockingScript, !testnet)
+		}
+		r.Inputs = append(r.Inputs, in)
+	}
+
+	for _, output := range tx.Outputs {
+		var b []byte
+		if output.LockingScript != nil {
+			b = *output.LockingScript
+		}
+		asm, _ := scripts.Disassemble(b)
+		r.Outputs = append(r.Outputs, Output{
+			Satoshis:      output.Satoshis,
+			LockingScript: hex.EncodeToString(b),
+			LockingASM:    asm,
+			Template:      scripts.Classify(b, !testnet),
+			Data:          DecodeData(output.LockingScript),
+		})
+	}
+	return r
+}
+
+// SetStatus adds each output's spent status to the report, as Print shows it.
+func (r *Report) SetStatus(status map[int]*OutputStatus) {
+	for i := range r.Outputs {
+		r.Outputs[i].Status = status[i]
+	}
+}
+
+// DescribeSequence explains a sequence number. Any non-final input makes
+// nLockTime enforceable; version 2+ transactions also carry BIP68 relative
+// locktime semantics, which BSV stopped enforcing at the Genesis upgrade.
+func DescribeSequence(seq, version uint32) string {
+	if seq == sequenceFinal {
+		return "final"
+	}
+	if version < 2 || seq&seqDisableFlag != 0 {
+		return "non-final"
+	}
+
+	value := seq & seqLockMask
+	if seq&seqTypeFlag != 0 {
+		d := time.Duration(value) * seqTimeGranularity * time.Second
+		return fmt.Sprintf("non-final, BIP68 relative lock %s, not enforced since Genesis", d)
+	}
+	return fmt.Sprintf("non-final, BIP68 relative lock %d blocks, not enforced since Genesis", value)
+}
+
+// DescribeLockTime explains what a locktime value locks to.
+func DescribeLockTime(lockTime uint32) string {
+	switch {
+	case lockTime == 0:
+		return "(Not locked)"
+	case lockTime < lockTimeThreshold:
+		return fmt.Sprintf("(Block %d)", lockTime)
+	}
+	return fmt.Sprintf("(Timestamp %d, %s)", lockTime, time.Unix(int64(lockTime), 0).UTC().Format(time.RFC3339))
+}
+
+// DescribeLockEnforcement reports whether a non-zero nLockTime applies. It is
+// ignored unless at least one input is non-final.
+func DescribeLockEnforcement(tx *transaction.Transaction) string {
+	nonFinal := nonFinalInputs(tx)
+	if nonFinal == 0 {
+		return "no, every input is final (sequence 0xffffffff), so nLockTime is ignored"
+	}
+	until := fmt.Sprintf("block %d has been mined", tx.LockTime)
+	if tx.LockTime >= lockTimeThreshold {
+		until = "median time past exceeds " + time.Unix(int64(tx.LockTime), 0).UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("yes, %d of %d input(s) non-final; the transaction is not final until %s",
+		nonFinal, len(tx.Inputs), until)
+}
+
+// nonFinalInputs counts the inputs whose sequence is not final.
+func nonFinalInputs(tx *transaction.Transaction) int {
+	nonFinal := 0
+	for _, input := range tx.Inputs {
+		if input.SequenceNumber != sequenceFinal {
+			nonFinal++
+		}
+	}
+	return nonFinal
+}
+
+// ClassifyUnlockingScript names the kind of spend an unlocking script makes
+// from the shape of its pushes. A multisig bundle is OP_0 followed by one or
+// more signatures; push-only scripts matching no template are data-only.
+func ClassifyUnlockingScript(unlockingScript *script.Script) string {
+	if unlockingScript == nil || len(*unlockingScript) == 0 {
+		return SpendUnsigned
+	}
+	chunks, err := unlockingScript.Chunks()
+	if err != nil || CheckPushOnly(*unlockingScript) != "" {
+		return SpendCustom
+	}
+
+	switch {
+	case len(chunks) == 1 && isSignature(chunks[0].Data):
+		return SpendP2PK
+	case len(chunks) == 2 && isSignature(chunks[0].Data) && isPublicKey(chunks[1].Data):
+		return SpendP2PKH
+	case len(chunks) >= 2 && chunks[0].Op == script.Op0:
+		for _, chunk := range chunks[1:] {
+			if !isSignature(chunk.Data) {
+				return SpendData
+			}
+		}
+		return fmt.Sprintf("multisig (%d signatures)", len(chunks)-1)
+	}
+	return SpendData
+}
+
+// isSignature reports whether data looks like a DER signature followed by a
+// sighash byte: a sequence tag whose length covers the rest but that byte.
+func isSignature(data []byte) bool {
+	return len(data) >= 9 && len(data) <= 73 && data[0] == 0x30 && int(data[1]) == len(data)-3
+}
+
+// isPublicKey reports whether data is a compressed or uncompressed public key.
+func isPublicKey(data []byte) bool {
+	switch len(data) {
+	case 33:
+		return data[0] == 0x02 || data[0] == 0x03
+	case 65:
+		return data[0] == 0x04
+	}
+	return false
+}
+
+// lintIssue is one Lint finding; reject marks the policy violations nodes
+// refuse a transaction for, as opposed to likely mistakes.
+type lintIssue struct {
+	message string
+	reject  bool
+}
+
+// Lint flags elements that nodes would reject or that are likely mistakes:
+// duplicate inputs, non-push or malformed unlocking scripts, outputs below
+// dust thresholds, and scripts or transactions over policy limits.
+func Lint(tx *transaction.Transaction) []string {
+	issues := lint(tx)
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.message)
+	}
+	return messages
+}
+
+// PolicyViolations returns the Lint issues that SV Node's default policy
+// rejects a transaction for: no inputs or outputs, duplicate inputs, non-push
+// unlocking scripts, outputs below the dust limit, and scripts or the
+// transaction over the size limits. The rest of Lint is advisory.
+func PolicyViolations(tx *transaction.Transaction) []string {
+	var violations []string
+	for _, issue := range lint(tx) {
+		if issue.reject {
+			violations = append(violations, issue.message)
+		}
+	}
+	return violations
+}
+
+// lint collects every Lint issue in transaction order.
+func lint(tx *transaction.Transaction) []lintIssue {
+	var issues []lintIssue
+	add := func(reject bool, format string, args ...any) {
+		issues = append(issues, lintIssue{message: fmt.Sprintf(format, args...), reject: reject})
+	}
+
+	if len(tx.Inputs) == 0 {
+		add(true, "transaction has no inputs")
+	}
+	if len(tx.Outputs) == 0 {
+		add(true, "transaction has no outputs")
+	}
+	if size := tx.Size(); size > maxStandardTxSize {
+		add(true, "transaction is %d bytes, over the %d byte policy limit", size, maxStandardTxSize)
+	}
+
+	seen := make(map[string]int, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		if input.SourceTXID != nil {
+			outpoint := fmt.Sprintf("%s:%d", input.SourceTXID, input.SourceTxOutIndex)
+			if first, ok := seen[outpoint]; ok {
+				add(true, "input #%d spends %s, already spent by input #%d", i, outpoint, first)
+			} else {
+				seen[outpoint] = i
+			}
+		}
+
+		if input.UnlockingScript == nil {
+			continue
+		}
+		if n := len(*input.UnlockingScript); n > maxScriptSize {
+			add(true, "input #%d unlocking script is %d bytes, over the %d byte policy limit", i, n, maxScriptSize)
+		}
+		if issue := CheckPushOnly(*input.UnlockingScript); issue != "" {
+			add(true, "input #%d unlocking script %s", i, issue)
+		}
+	}
+
+	for i, output := range tx.Outputs {
+		var b []byte
+		if output.LockingScript != nil {
+			b = *output.LockingScript
+		}
+		isData := output.LockingScript != nil && output.LockingScript.IsData()
+
+		switch {
+		case isData:
+			// Data outputs carry no spendable value, so dust rules do not apply
+		case output.Satoshis < dustLimit:
+			add(true, "output #%d is %d sats, below the %d sat dust limit", i, output.Satoshis, dustLimit)
+		case output.Satoshis < legacyDustLimit:
+			add(false, "output #%d is %d sats, below the legacy %d sat dust threshold some services still enforce", i, output.Satoshis, legacyDustLimit)
+		}
+
+		if !isData && len(b) > maxScriptSize {
+			add(true, "output #%d locking script is %d bytes, over the %d byte policy limit", i, len(b), maxScriptSize)
+		}
+		for _, issue := range scripts.ValidateOpcodes(b) {
+			add(false, "output #%d locking script %s", i, issue)
+		}
+	}
+
+	return issues
+}
+
+// CheckPushOnly describes why an unlocking script is not push-only, which
+// standard nodes require, or returns "" when it is.
+func CheckPushOnly(s script.Script) string {
+	pos := 0
+	for pos < len(s) {
+		start := pos
+		op, err := s.ReadOp(&pos)
+		if err != nil {
+			return fmt.Sprintf("is malformed at byte %d: %v", start, err)
+		}
+		if op.Op > script.Op16 {
+			name := script.OpCodeValues[op.Op]
+			if name == "" {
+				name = fmt.Sprintf("0x%02x", op.Op)
+			}
+			return fmt.Sprintf("contains non-push opcode %s at byte %d", name, start)
+		}
+	}
+	return ""
+}
+
+// IsP2PKH checks if a locking script is a standard P2PKH (Pay-to-PubKey-Hash) script.
+// P2PKH scripts have the pattern: OP_DUP OP_HASH160 <20-byte-hash> OP_EQUALVERIFY OP_CHECKSIG
+// This is exactly 25 bytes: 76 a9 14 <20 bytes> 88 ac
+func IsP2PKH(scriptBytes *script.Script) bool {
+	if scriptBytes == nil {
+		return false
+	}
+
+	bytes := []byte(*scriptBytes)
+
+	// Check length (must be exactly 25 bytes)
+	if len(bytes) != 25 {
+		return false
+	}
+
+	// Check the P2PKH pattern
+	return bytes[0] == 0x76 && // OP_DUP
+		bytes[1] == 0xa9 && // OP_HASH160
+		bytes[2] == 0x14 && // Push 20 bytes
+		bytes[23] == 0x88 && // OP_EQUALVERIFY
+		bytes[24] == 0xac // OP_CHECKSIG
+}
+
+// P2PKHAddress extracts the address from a P2PKH locking script.
+// Returns the address string if successful, empty string otherwise.
+func P2PKHAddress(scriptBytes *script.Script, mainnet bool) string {
+	if !IsP2PKH(scriptBytes) {
+		return ""
+	}
+
+	bytes := []byte(*scriptBytes)
+
+	// Extract the 20-byte public key hash (bytes 3-22)
+	pubKeyHash := bytes[3:23]
+
+	// Create an address from the hash
+	addr, err := script.NewAddressFromPublicKeyHash(pubKeyHash, mainnet)
+	if err != nil {
+		return ""
+	}
+
+	return addr.AddressString
+}
+
+// UnlockingScriptAddress attempts to extract an address from a P2PKH unlocking script.
+// P2PKH unlocking scripts contain: <signature> <pubKey>
+// This function extracts the public key and derives the address from it.
+// Returns the address string if successful, empty string otherwise.
+func UnlockingScriptAddress(scriptBytes *script.Script, mainnet bool) string {
+	if scriptBytes == nil {
+		return ""
+	}
+
+	bytes := []byte(*scriptBytes)
+	if len(bytes) == 0 {
+		return ""
+	}
+
+	// Parse the script to extract the public key
+	pubKeyBytes := extractPublicKeyFromScript(bytes)
+	if len(pubKeyBytes) == 0 {
+		return ""
+	}
+
+	// Try to parse the public key
+	pubKey, err := ec.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return ""
+	}
+
+	// Derive the address from the public key
+	addr, err := script.NewAddressFromPublicKey(pubKey, mainnet)
+	if err != nil {
+		return ""
+	}
+
+	return addr.AddressString
+}
+
+// extractPublicKeyFromScript parses a script to extract the public key.
+// In a typical P2PKH unlocking script:
+// - First comes the signature (variable length, typically ~72 bytes)
+// - Then comes the public key (33 or 65 bytes)
+func extractPublicKeyFromScript(bytes []byte) []byte {
+	var pubKeyBytes []byte
+	i := 0
+
+	for i < len(bytes) {
+		if i >= len(bytes) {
+			break
+		}
+
+		opcode := bytes[i]
+		i++
+
+		// Handle push data opcodes
+		if opcode > 0 && opcode <= 75 {
+			// Direct push of N bytes
+			length := int(opcode)
+			if i+length > len(bytes) {
+				break
+			}
+			data := bytes[i : i+length]
+			i += length
+
+			// Check if this looks like a public key (33 or 65 bytes)
+			if length == 33 || length == 65 {
+				pubKeyBytes = data
+			}
+		} else if opcode == 0x4c { // OP_PUSHDATA1
+			if i >= len(bytes) {
+				break
+			}
+			length := int(bytes[i])
+			i++
+			if i+length > len(bytes) {
+				break
+			}
+			data := bytes[i : i+length]
+			i += length
+
+			if length == 33 || length == 65 {
+				pubKeyBytes = data
+			}
+		}
+	}
+
+	return pubKeyBytes
+}
@@ -0,0 +1,660 @@
+package txinspect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsP2PKH(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid P2PKH script", func(t *testing.T) {
+		t.Parallel()
+
+		// Standard P2PKH: OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+		// 76 a9 14 [20 bytes pubkey hash] 88 ac
+		scriptBytes := []byte{
+			0x76, 0xa9, 0x14, // OP_DUP, OP_HASH160, PUSH 20 bytes
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+			0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			0x10, 0x11, 0x12, 0x13, // 20 byte pubkey hash
+			0x88, 0xac, // OP_EQUALVERIFY, OP_CHECKSIG
+		}
+		s := script.Script(scriptBytes)
+
+		assert.True(t, IsP2PKH(&s))
+	})
+
+	t.Run("nil script", func(t *testing.T) {
+		t.Parallel()
+		assert.False(t, IsP2PKH(nil))
+	})
+
+	t.Run("empty script", func(t *testing.T) {
+		t.Parallel()
+		s := script.Script([]byte{})
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		t.Parallel()
+		s := script.Script([]byte{0x76, 0xa9, 0x14})
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 26)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x14
+		scriptBytes[23] = 0x88
+		scriptBytes[24] = 0xac
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("wrong first opcode", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 25)
+		scriptBytes[0] = 0x00 // Wrong - should be 0x76 (OP_DUP)
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x14
+		scriptBytes[23] = 0x88
+		scriptBytes[24] = 0xac
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("wrong second opcode", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 25)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0x00 // Wrong - should be 0xa9 (OP_HASH160)
+		scriptBytes[2] = 0x14
+		scriptBytes[23] = 0x88
+		scriptBytes[24] = 0xac
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("wrong push length", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 25)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x15 // Wrong - should be 0x14 (push 20 bytes)
+		scriptBytes[23] = 0x88
+		scriptBytes[24] = 0xac
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("wrong equalverify opcode", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 25)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x14
+		scriptBytes[23] = 0x00 // Wrong - should be 0x88 (OP_EQUALVERIFY)
+		scriptBytes[24] = 0xac
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("wrong checksig opcode", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 25)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x14
+		scriptBytes[23] = 0x88
+		scriptBytes[24] = 0x00 // Wrong - should be 0xac (OP_CHECKSIG)
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+
+	t.Run("exactly 24 bytes (one too short)", func(t *testing.T) {
+		t.Parallel()
+		scriptBytes := make([]byte, 24)
+		scriptBytes[0] = 0x76
+		scriptBytes[1] = 0xa9
+		scriptBytes[2] = 0x14
+		s := script.Script(scriptBytes)
+		assert.False(t, IsP2PKH(&s))
+	})
+}
+
+func TestP2PKHAddress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid P2PKH mainnet", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a valid P2PKH script with a known pubkey hash
+		// Using a recognizable pattern
+		pubKeyHash := []byte{
+			0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67,
+			0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67,
+			0x89, 0xab, 0xcd, 0xef,
+		}
+
+		scriptBytes := append([]byte{0x76, 0xa9, 0x14}, pubKeyHash...)
+		scriptBytes = append(scriptBytes, 0x88, 0xac)
+
+		s := script.Script(scriptBytes)
+		addr := P2PKHAddress(&s, true) // mainnet
+
+		// Should return a valid address string starting with '1' for mainnet
+		assert.NotEmpty(t, addr)
+		if addr != "" {
+			assert.True(t, addr[0] == '1' || addr[0] == '3', "Mainnet address should start with 1 or 3")
+		}
+	})
+
+	t.Run("valid P2PKH testnet", func(t *testing.T) {
+		t.Parallel()
+
+		pubKeyHash := []byte{
+			0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67,
+			0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67,
+			0x89, 0xab, 0xcd, 0xef,
+		}
+
+		scriptBytes := append([]byte{0x76, 0xa9, 0x14}, pubKeyHash...)
+		scriptBytes = append(scriptBytes, 0x88, 0xac)
+
+		s := script.Script(scriptBytes)
+		addr := P2PKHAddress(&s, false) // testnet
+
+		// Should return a valid address string starting with 'm' or 'n' for testnet
+		assert.NotEmpty(t, addr)
+		if addr != "" {
+			assert.True(t, addr[0] == 'm' || addr[0] == 'n', "Testnet address should start with m or n")
+		}
+	})
+
+	t.Run("non-P2PKH script", func(t *testing.T) {
+		t.Parallel()
+
+		// Not a P2PKH script (too short)
+		scriptBytes := []byte{0x76, 0xa9}
+		s := script.Script(scriptBytes)
+
+		addr := P2PKHAddress(&s, true)
+		assert.Empty(t, addr)
+	})
+
+	t.Run("nil script", func(t *testing.T) {
+		t.Parallel()
+		addr := P2PKHAddress(nil, true)
+		assert.Empty(t, addr)
+	})
+}
+
+func TestExtractPublicKeyFromScript(t *testing.T) {
+	t.Parallel()
+
+	t.Run("typical P2PKH unlocking script with compressed pubkey", func(t *testing.T) {
+		t.Parallel()
+
+		// Simulated P2PKH unlocking script: <sig 72 bytes> <pubkey 33 bytes>
+		sig := make([]byte, 72)
+		pubKey := make([]byte, 33)
+		pubKey[0] = 0x02 // Compressed pubkey prefix
+
+		// Script: <push 72> <sig> <push 33> <pubkey>
+		scriptBytes := append([]byte{72}, sig...)
+		scriptBytes = append(scriptBytes, 33)
+		scriptBytes = append(scriptBytes, pubKey...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		require.Len(t, result, 33)
+		assert.Equal(t, byte(0x02), result[0])
+	})
+
+	t.Run("uncompressed pubkey (65 bytes)", func(t *testing.T) {
+		t.Parallel()
+
+		sig := make([]byte, 72)
+		pubKey := make([]byte, 65)
+		pubKey[0] = 0x04 // Uncompressed pubkey prefix
+
+		scriptBytes := append([]byte{72}, sig...)
+		scriptBytes = append(scriptBytes, 65)
+		scriptBytes = append(scriptBytes, pubKey...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		require.Len(t, result, 65)
+		assert.Equal(t, byte(0x04), result[0])
+	})
+
+	t.Run("empty script", func(t *testing.T) {
+		t.Parallel()
+
+		result := extractPublicKeyFromScript([]byte{})
+		assert.Empty(t, result)
+	})
+
+	t.Run("script with only signature (no pubkey)", func(t *testing.T) {
+		t.Parallel()
+
+		// Only a signature, no pubkey
+		sig := make([]byte, 72)
+		scriptBytes := append([]byte{72}, sig...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		assert.Empty(t, result) // 72 bytes is not a valid pubkey length
+	})
+
+	t.Run("script with non-standard length data", func(t *testing.T) {
+		t.Parallel()
+
+		// Data that's not 33 or 65 bytes
+		data := make([]byte, 50)
+		scriptBytes := append([]byte{50}, data...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		assert.Empty(t, result)
+	})
+
+	t.Run("truncated script", func(t *testing.T) {
+		t.Parallel()
+
+		// Push 72 bytes but only provide 50
+		scriptBytes := append([]byte{72}, make([]byte, 50)...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		// Should handle gracefully, not panic
+		assert.Empty(t, result)
+	})
+
+	t.Run("OP_PUSHDATA1 with compressed pubkey", func(t *testing.T) {
+		t.Parallel()
+
+		sig := make([]byte, 72)
+		pubKey := make([]byte, 33)
+		pubKey[0] = 0x03 // Compressed pubkey prefix (odd y)
+
+		// Using OP_PUSHDATA1 (0x4c) for pubkey
+		scriptBytes := append([]byte{72}, sig...)
+		scriptBytes = append(scriptBytes, 0x4c, 33) // OP_PUSHDATA1, length 33
+		scriptBytes = append(scriptBytes, pubKey...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		require.Len(t, result, 33)
+		assert.Equal(t, byte(0x03), result[0])
+	})
+
+	t.Run("multiple push operations - extracts last valid pubkey", func(t *testing.T) {
+		t.Parallel()
+
+		data1 := make([]byte, 20)
+		pubKey1 := make([]byte, 33)
+		pubKey1[0] = 0x02
+		pubKey2 := make([]byte, 33)
+		pubKey2[0] = 0x03 // Different prefix
+
+		// <push 20> <data> <push 33> <pubkey1> <push 33> <pubkey2>
+		scriptBytes := append([]byte{20}, data1...)
+		scriptBytes = append(scriptBytes, 33)
+		scriptBytes = append(scriptBytes, pubKey1...)
+		scriptBytes = append(scriptBytes, 33)
+		scriptBytes = append(scriptBytes, pubKey2...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		require.Len(t, result, 33)
+		// Should get the last valid pubkey
+		assert.Equal(t, byte(0x03), result[0])
+	})
+
+	t.Run("OP_PUSHDATA1 truncated length byte", func(t *testing.T) {
+		t.Parallel()
+
+		// OP_PUSHDATA1 but no length byte following
+		scriptBytes := []byte{0x4c}
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		assert.Empty(t, result)
+	})
+
+	t.Run("OP_PUSHDATA1 truncated data", func(t *testing.T) {
+		t.Parallel()
+
+		// OP_PUSHDATA1, length 33, but only 10 bytes of data
+		scriptBytes := []byte{0x4c, 33}
+		scriptBytes = append(scriptBytes, make([]byte, 10)...)
+
+		result := extractPublicKeyFromScript(scriptBytes)
+		assert.Empty(t, result)
+	})
+}
+
+// Benchmark tests
+
+func BenchmarkIsP2PKH(b *testing.B) {
+	scriptBytes := []byte{
+		0x76, 0xa9, 0x14,
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+		0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+		0x10, 0x11, 0x12, 0x13,
+		0x88, 0xac,
+	}
+	s := script.Script(scriptBytes)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = IsP2PKH(&s)
+	}
+}
+
+func BenchmarkExtractPublicKeyFromScript(b *testing.B) {
+	sig := make([]byte, 72)
+	pubKey := make([]byte, 33)
+	pubKey[0] = 0x02
+
+	scriptBytes := append([]byte{72}, sig...)
+	scriptBytes = append(scriptBytes, 33)
+	scriptBytes = append(scriptBytes, pubKey...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = extractPublicKeyFromScript(scriptBytes)
+	}
+}
+
+// Test with real-world-like data
+
+func TestIsP2PKHWithRealPattern(t *testing.T) {
+	t.Parallel()
+
+	// Real P2PKH locking script pattern for a known address
+	// This is the script pattern, not actual address data
+	validP2PKH := []byte{
+		0x76,                                           // OP_DUP
+		0xa9,                                           // OP_HASH160
+		0x14,                                           // Push 20 bytes
+		0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, // 20 byte hash (example)
+		0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0,
+		0x12, 0x34, 0x56, 0x78,
+		0x88, // OP_EQUALVERIFY
+		0xac, // OP_CHECKSIG
+	}
+
+	s := script.Script(validP2PKH)
+	assert.True(t, IsP2PKH(&s))
+}
+
+func TestUnlockingScriptAddress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil script", func(t *testing.T) {
+		t.Parallel()
+		addr := UnlockingScriptAddress(nil, true)
+		assert.Empty(t, addr)
+	})
+
+	t.Run("empty script", func(t *testing.T) {
+		t.Parallel()
+		s := script.Script([]byte{})
+		addr := UnlockingScriptAddress(&s, true)
+		assert.Empty(t, addr)
+	})
+
+	t.Run("script without valid pubkey", func(t *testing.T) {
+		t.Parallel()
+
+		// Just a signature, no pubkey
+		sig := make([]byte, 72)
+		scriptBytes := append([]byte{72}, sig...)
+		s := script.Script(scriptBytes)
+
+		addr := UnlockingScriptAddress(&s, true)
+		assert.Empty(t, addr)
+	})
+
+	t.Run("script with invalid pubkey bytes", func(t *testing.T) {
+		t.Parallel()
+
+		// 33 bytes but not a valid pubkey format
+		sig := make([]byte, 72)
+		invalidPubKey := make([]byte, 33)
+		invalidPubKey[0] = 0xFF // Invalid prefix
+
+		scriptBytes := append([]byte{72}, sig...)
+		scriptBytes = append(scriptBytes, 33)
+		scriptBytes = append(scriptBytes, invalidPubKey...)
+		s := script.Script(scriptBytes)
+
+		addr := UnlockingScriptAddress(&s, true)
+		// May or may not return empty depending on SDK behavior
+		// The important thing is it doesn't panic
+		_ = addr
+	})
+}
+
+func TestDescribeSequence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		seq     uint32
+		version uint32
+		want    string
+	}{
+		{"final", 0xffffffff, 2, "final"},
+		{"non-final version 1", 0xfffffffe, 1, "non-final"},
+		{"relative lock disabled", 0xfffffffe, 2, "non-final"},
+		{"relative blocks", 144, 2, "non-final, BIP68 relative lock 144 blocks, not enforced since Genesis"},
+		{"relative time", 1<<22 | 7, 2, "non-final, BIP68 relative lock 59m44s, not enforced since Genesis"},
+		{"relative value ignored in version 1", 144, 1, "non-final"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, DescribeSequence(tt.seq, tt.version))
+		})
+	}
+}
+
+func TestDescribeLockEnforcement(t *testing.T) {
+	t.Parallel()
+
+	newTx := func(lockTime uint32, sequences ...uint32) *transaction.Transaction {
+		tx := transaction.NewTransaction()
+		tx.LockTime = lockTime
+		for _, seq := range sequences {
+			tx.Inputs = append(tx.Inputs, &transaction.TransactionInput{SequenceNumber: seq})
+		}
+		return tx
+	}
+
+	t.Run("all inputs final", func(t *testing.T) {
+		t.Parallel()
+		got := DescribeLockEnforcement(newTx(800000, 0xffffffff, 0xffffffff))
+		assert.Equal(t, "no, every input is final (sequence 0xffffffff), so nLockTime is ignored", got)
+	})
+
+	t.Run("block height", func(t *testing.T) {
+		t.Parallel()
+		got := DescribeLockEnforcement(newTx(800000, 0xffffffff, 0xfffffffe))
+		assert.Equal(t, "yes, 1 of 2 input(s) non-final; the transaction is not final until block 800000 has been mined", got)
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		t.Parallel()
+		got := DescribeLockEnforcement(newTx(1700000000, 0))
+		assert.Equal(t, "yes, 1 of 1 input(s) non-final; the transaction is not final until median time past exceeds 2023-11-14T22:13:20Z", got)
+	})
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	p2pkh, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	require.NoError(t, err)
+	data, err := script.NewFromHex("006a0568656c6c6f")
+	require.NoError(t, err)
+	pushOnly, err := script.NewFromHex("0101" + "0102")
+	require.NoError(t, err)
+	nonPush, err := script.NewFromHex("0101" + "76")
+	require.NoError(t, err)
+
+	t.Run("clean transaction", func(t *testing.T) {
+		t.Parallel()
+
+		tx := transaction.NewTransaction()
+		require.NoError(t, tx.AddInputFrom(txid, 0, p2pkh.String(), 1000, nil))
+		tx.Inputs[0].UnlockingScript = pushOnly
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1000, LockingScript: p2pkh})
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: data})
+
+		assert.Empty(t, Lint(tx))
+	})
+
+	t.Run("every issue", func(t *testing.T) {
+		t.Parallel()
+
+		tx := transaction.NewTransaction()
+		require.NoError(t, tx.AddInputFrom(txid, 1, p2pkh.String(), 1000, nil))
+		require.NoError(t, tx.AddInputFrom(txid, 1, p2pkh.String(), 1000, nil))
+		tx.Inputs[0].UnlockingScript = pushOnly
+		tx.Inputs[1].UnlockingScript = nonPush
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: p2pkh})
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 100, LockingScript: p2pkh})
+		bad := script.Script{0xba}
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1000, LockingScript: &bad})
+
+		assert.Equal(t, []string{
+			"input #1 spends " + txid + ":1, already spent by input #0",
+			"input #1 unlocking script contains non-push opcode OP_DUP at byte 2",
+			"output #0 is 0 sats, below the 1 sat dust limit",
+			"output #1 is 100 sats, below the legacy 546 sat dust threshold some services still enforce",
+			"output #2 locking script byte 0: undefined opcode 0xba",
+		}, Lint(tx))
+
+		assert.Equal(t, []string{
+			"input #1 spends " + txid + ":1, already spent by input #0",
+			"input #1 unlocking script contains non-push opcode OP_DUP at byte 2",
+			"output #0 is 0 sats, below the 1 sat dust limit",
+		}, PolicyViolations(tx), "legacy dust and locking script opcodes are advisory")
+	})
+
+	t.Run("empty transaction", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, []string{"transaction has no inputs", "transaction has no outputs"}, PolicyViolations(transaction.NewTransaction()))
+	})
+}
+
+func TestCheckPushOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, CheckPushOnly(script.Script{}))
+	assert.Empty(t, CheckPushOnly(script.Script{script.Op0, script.Op16, 0x01, 0xff}))
+	assert.Equal(t, "contains non-push opcode OP_CHECKSIG at byte 1", CheckPushOnly(script.Script{script.Op1, script.OpCHECKSIG}))
+	assert.Contains(t, CheckPushOnly(script.Script{0x05, 0x01}), "is malformed at byte 0")
+}
+
+func TestClassifyUnlockingScript(t *testing.T) {
+	t.Parallel()
+
+	// push returns a direct push of data
+	push := func(data []byte) []byte {
+		return append([]byte{byte(len(data))}, data...)
+	}
+	sig := append([]byte{0x30, 68}, make([]byte, 69)...)
+	pubKey := append([]byte{0x02}, make([]byte, 32)...)
+	concat := func(parts ...[]byte) *script.Script {
+		var s script.Script
+		for _, p := range parts {
+			s = append(s, p...)
+		}
+		return &s
+	}
+
+	tests := []struct {
+		name     string
+		script   *script.Script
+		expected string
+	}{
+		{"nil", nil, SpendUnsigned},
+		{"empty", concat(), SpendUnsigned},
+		{"P2PKH", concat(push(sig), push(pubKey)), SpendP2PKH},
+		{"P2PK", concat(push(sig)), SpendP2PK},
+		{"2-of-3 multisig", concat([]byte{script.Op0}, push(sig), push(sig)), "multisig (2 signatures)"},
+		{"data pushes", concat(push([]byte("hello")), push([]byte("world"))), SpendData},
+		{"OP_0 then data", concat([]byte{script.Op0}, push([]byte("hello"))), SpendData},
+		{"non-push opcode", concat(push(sig), []byte{script.OpCHECKSIG}), SpendCustom},
+		{"malformed", concat([]byte{0x05, 0x01}), SpendCustom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, ClassifyUnlockingScript(tt.script))
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	p2pkh, err := script.NewFromHex("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	require.NoError(t, err)
+	data, err := script.NewFromHex("006a0568656c6c6f")
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	tx.LockTime = 800000
+	require.NoError(t, tx.AddInputFrom(txid, 1, p2pkh.String(), 1000, nil))
+	tx.Inputs[0].SequenceNumber = 0xfffffffe
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 100, LockingScript: p2pkh})
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 0, LockingScript: data})
+
+	r := Inspect(tx, "ef", false)
+	assert.Equal(t, tx.TxID().String(), r.TxID)
+	assert.Equal(t, "ef", r.Format)
+	assert.Equal(t, tx.Size(), r.Size)
+	assert.True(t, r.LockTimeEnforced)
+
+	require.Len(t, r.Inputs, 1)
+	assert.Equal(t, txid, r.Inputs[0].PrevTxID)
+	assert.Equal(t, uint32(1), r.Inputs[0].PrevVout)
+	require.NotNil(t, r.Inputs[0].Satoshis)
+	assert.Equal(t, uint64(1000), *r.Inputs[0].Satoshis)
+	assert.Equal(t, SpendUnsigned, r.Inputs[0].SpendType)
+	assert.Equal(t, "non-final", r.Inputs[0].SequenceInfo)
+
+	require.Len(t, r.Outputs, 2)
+	assert.Equal(t, p2pkh.String(), r.Outputs[0].LockingScript)
+	assert.Equal(t, "OP_DUP OP_HASH160 62e907b15cbf27d5425399ebf6f0fb50ebb88f18 OP_EQUALVERIFY OP_CHECKSIG", r.Outputs[0].LockingASM)
+	assert.Equal(t, "OP_0 OP_RETURN 68656c6c6f", r.Outputs[1].LockingASM)
+	assert.Equal(t, []string{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"}, r.Outputs[0].Template.Addresses)
+	assert.Equal(t, []string{"68656c6c6f"}, r.Outputs[1].Template.Data)
+	assert.Equal(t, []string{"output #0 is 100 sats, below the legacy 546 sat dust threshold some services still enforce"}, r.Lint)
+
+	// A clean transaction reports an empty list rather than null
+	tx.Outputs[0].Satoshis = 1000
+	assert.NotNil(t, Inspect(tx, "raw", true).Lint)
+	assert.Empty(t, Inspect(tx, "raw", true).Lint)
+	assert.Equal(t, []string{"mpXwg4jMtRhuSpVq4xS3HFHmCmWp9NyGKt"}, Inspect(tx, "raw", true).Outputs[0].Template.Addresses)
+
+	// Spent status is added per output
+	r.SetStatus(map[int]*OutputStatus{0: {SpentBy: txid, SpentVin: 2}, 1: {Unspendable: true}})
+	assert.Equal(t, &OutputStatus{SpentBy: txid, SpentVin: 2}, r.Outputs[0].Status)
+	assert.True(t, r.Outputs[1].Status.Unspendable)
+
+	// Input 0 of the spending transaction is kept in the JSON
+	r.SetStatus(map[int]*OutputStatus{0: {SpentBy: txid, SpentVin: 0}})
+	encoded, err := json.Marshal(r.Outputs[0].Status)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spentBy":"`+txid+`","spentVin":0}`, string(encoded))
+}
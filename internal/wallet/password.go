@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PasswordEnv names the environment variable holding the wallet password.
+const PasswordEnv = "BSV_WALLET_PASSWORD"
+
+// ReadPassword reads the wallet password from PasswordEnv or, after printing
+// prompt to stderr, the terminal. When stdin is not a terminal, a single line
+// is read from it.
+func ReadPassword(prompt string) ([]byte, error) {
+	if pw, ok := os.LookupEnv(PasswordEnv); ok {
+		return []byte(pw), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in an int
+	if term.IsTerminal(fd) {
+		pw, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password: %w", err)
+		}
+		return pw, nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read password: %w", err)
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
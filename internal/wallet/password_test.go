@@ -0,0 +1,16 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPasswordFromEnv(t *testing.T) {
+	t.Setenv(PasswordEnv, "correct horse")
+
+	pw, err := ReadPassword("Wallet password: ")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("correct horse"), pw)
+}
@@ -0,0 +1,168 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// File format and key derivation parameters
+const (
+	fileVersion = 1
+	kdfScrypt   = "scrypt"
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptP     = 1
+	keyLen      = 32 // AES-256
+	saltLen     = 16
+)
+
+// ErrWrongPassword is returned by Load when the wallet cannot be decrypted.
+var ErrWrongPassword = errors.New("wrong password or corrupted wallet file")
+
+// envelope is the on-disk wallet file. Everything except the KDF parameters
+// is encrypted with AES-256-GCM under a key derived from the password.
+type envelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DefaultPath returns the path of a named wallet in the user's home directory.
+func DefaultPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".bsv-cmd-line-utils", "wallets", name+".wallet"), nil
+}
+
+// Save encrypts the wallet with password and writes it to path.
+// The file is replaced atomically and is readable only by the owner.
+func Save(path string, w *Wallet, password []byte) error {
+	plaintext, err := json.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("failed to encode wallet: %w", err)
+	}
+
+	env := envelope{Version: fileVersion, KDF: kdfScrypt, N: scryptN, R: scryptR, P: scryptP}
+
+	env.Salt = make([]byte, saltLen)
+	if _, err = rand.Read(env.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newAEAD(password, &env)
+	if err != nil {
+		return err
+	}
+
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode wallet file: %w", err)
+	}
+
+	return writeFile(path, data)
+}
+
+// Load reads the wallet at path and decrypts it with password.
+func Load(path string, password []byte) (*Wallet, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-specified wallet file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet: %w", err)
+	}
+
+	var env envelope
+	if err = json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet file: %w", err)
+	}
+	if env.Version != fileVersion || env.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported wallet file (version %d, kdf %q)", env.Version, env.KDF)
+	}
+
+	aead, err := newAEAD(password, &env)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassword
+	}
+
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	var w Wallet
+	if err = json.Unmarshal(plaintext, &w); err != nil {
+		return nil, fmt.Errorf("failed to decode wallet: %w", err)
+	}
+
+	return &w, nil
+}
+
+// newAEAD derives the file key from password and returns an AES-GCM cipher.
+func newAEAD(password []byte, env *envelope) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, env.Salt, env.N, env.R, env.P, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// writeFile writes data to path via a temporary file and rename.
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".wallet-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // no-op after a successful rename
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write wallet: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write wallet: %w", err)
+	}
+
+	// CreateTemp already uses 0600, but be explicit about the wallet's permissions
+	if err = os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("failed to set wallet permissions: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save wallet: %w", err)
+	}
+
+	return nil
+}
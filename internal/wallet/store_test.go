@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, true)
+	_, err := w.Derive(false, "savings", testNow)
+	require.NoError(t, err)
+	_, err = w.Import(testTestnetWIF, "", testNow)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "wallets", "test.wallet")
+	require.NoError(t, Save(path, w, []byte("hunter2")))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Secrets never appear in the file
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), w.XPriv)
+	assert.NotContains(t, string(data), testTestnetWIF)
+
+	t.Run("correct password", func(t *testing.T) {
+		t.Parallel()
+
+		loaded, err := Load(path, []byte("hunter2"))
+		require.NoError(t, err)
+		assert.Equal(t, w.XPriv, loaded.XPriv)
+		assert.Equal(t, NetworkTest, loaded.Network)
+		assert.Equal(t, w.Addresses(), loaded.Addresses())
+		assert.Equal(t, uint32(1), loaded.NextReceive)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(path, []byte("hunter3"))
+		require.ErrorIs(t, err, ErrWrongPassword)
+	})
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(filepath.Join(dir, "missing.wallet"), []byte("x"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read wallet")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "future.wallet")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":99,"kdf":"scrypt"}`), 0o600))
+
+		_, err := Load(path, []byte("x"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported wallet file")
+	})
+}
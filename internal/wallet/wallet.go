@@ -0,0 +1,404 @@
+// Package wallet implements a minimal single-user BSV wallet.
+//
+// A wallet holds an HD master key for derived receive and change addresses,
+// any number of imported WIF keys, the UTXOs it has seen for those addresses,
+// and a history of sends and receives. The whole wallet is persisted as one
+// password-encrypted file (see Save and Load).
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/mrz1836/go-template/internal/keys"
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+// Networks a wallet can belong to
+const (
+	NetworkMain = "main"
+	NetworkTest = "test"
+)
+
+// OriginKeygen marks an imported key generated by keygen --into-wallet.
+const OriginKeygen = "keygen"
+
+// History entry types
+const (
+	EntryReceive = "receive"
+	EntrySend    = "send"
+)
+
+// Wallet is the decrypted contents of a wallet file.
+type Wallet struct {
+	Network     string    `json:"network"`           // "main" or "test"
+	XPriv       string    `json:"xpriv"`             // HD master key for derived addresses
+	NextReceive uint32    `json:"nextReceive"`       // Next index on the receive chain (m/0/i)
+	NextChange  uint32    `json:"nextChange"`        // Next index on the change chain (m/1/i)
+	Keys        []*Key    `json:"keys"`              // Derived and imported keys
+	UTXOs       []*UTXO   `json:"utxos"`             // Tracked unspent outputs
+	History     []*Entry  `json:"history"`           // Sends and receives, oldest first
+	SyncedAt    time.Time `json:"syncedAt,omitzero"` // Last successful UTXO refresh
+}
+
+// Key is an address the wallet can spend from.
+type Key struct {
+	Address string    `json:"address"`
+	Label   string    `json:"label,omitempty"`
+	Path    string    `json:"path,omitempty"`   // HD path for derived keys, e.g. m/0/3
+	WIF     string    `json:"wif,omitempty"`    // Private key for imported keys
+	Origin  string    `json:"origin,omitempty"` // Tool that generated an imported key, e.g. keygen
+	Created time.Time `json:"created"`
+}
+
+// Imported reports whether the key was imported rather than derived.
+func (k *Key) Imported() bool {
+	return k.WIF != ""
+}
+
+// Uncompressed reports whether the key is an imported WIF flagged for the
+// uncompressed public key, whose address its UTXOs pay.
+func (k *Key) Uncompressed() bool {
+	if !k.Imported() {
+		return false
+	}
+	parsed, err := keys.ParseWIF(k.WIF)
+	return err == nil && !parsed.Compressed
+}
+
+// Change reports whether the key is on the internal (change) chain.
+func (k *Key) Change() bool {
+	return len(k.Path) > 3 && k.Path[:4] == "m/1/"
+}
+
+// UTXO is a tracked unspent output and the wallet address it pays.
+type UTXO struct {
+	txbuilder.UTXO
+	Address string `json:"address"`
+}
+
+// Entry is a wallet history record.
+type Entry struct {
+	TxID    string    `json:"txid"`
+	Type    string    `json:"type"`              // "receive" or "send"
+	Amount  uint64    `json:"amount"`            // Satoshis received, or paid to the recipient
+	Fee     uint64    `json:"fee,omitempty"`     // Fee paid (sends only)
+	Address string    `json:"address,omitempty"` // Receiving wallet address, or send destination
+	Time    time.Time `json:"time"`              // When the wallet first saw the transaction
+}
+
+// New creates an empty wallet with a freshly generated HD master key.
+func New(testnet bool) (*Wallet, error) {
+	hdKey, err := bip32.GenerateHDKey(bip32.RecommendedSeedLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+
+	network := NetworkMain
+	if testnet {
+		network = NetworkTest
+	}
+
+	return &Wallet{Network: network, XPriv: hdKey.String()}, nil
+}
+
+// Testnet reports whether the wallet belongs to testnet.
+func (w *Wallet) Testnet() bool {
+	return w.Network == NetworkTest
+}
+
+// Derive derives the next address on the receive or change chain and adds it to the wallet.
+func (w *Wallet) Derive(change bool, label string, now time.Time) (*Key, error) {
+	chain, next := uint32(bip32.DefaultExternalChain), &w.NextReceive
+	if change {
+		chain, next = uint32(bip32.DefaultInternalChain), &w.NextChange
+	}
+
+	priv, err := w.derivedKey(chain, *next)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := script.NewAddressFromPublicKey(priv.PubKey(), !w.Testnet())
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive address: %w", err)
+	}
+
+	key := &Key{
+		Address: addr.AddressString,
+		Label:   label,
+		Path:    fmt.Sprintf("m/%d/%d", chain, *next),
+		Created: now,
+	}
+	*next++
+	w.Keys = append(w.Keys, key)
+
+	return key, nil
+}
+
+// Import adds a WIF private key to the wallet. The WIF must be for the
+// wallet's network, and its compression flag picks the address.
+func (w *Wallet) Import(wif, label string, now time.Time) (*Key, error) {
+	parsed, err := keys.ParseWIF(wif)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WIF: %w", err)
+	}
+	if parsed.Testnet != w.Testnet() {
+		return nil, fmt.Errorf("cannot import a %s WIF into a %snet wallet", parsed.Network(), w.Network)
+	}
+
+	addr, err := parsed.Address()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive address: %w", err)
+	}
+
+	if w.Key(addr.AddressString) != nil {
+		return nil, fmt.Errorf("address %s is already in the wallet", addr.AddressString)
+	}
+
+	key := &Key{
+		Address: addr.AddressString,
+		Label:   label,
+		WIF:     wif,
+		Created: now,
+	}
+	w.Keys = append(w.Keys, key)
+
+	return key, nil
+}
+
+// PrivateKey returns the private key for a wallet key.
+func (w *Wallet) PrivateKey(k *Key) (*ec.PrivateKey, error) {
+	if k.Imported() {
+		parsed, err := keys.ParseWIF(k.WIF)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WIF for %s: %w", k.Address, err)
+		}
+		return parsed.Key, nil
+	}
+
+	var chain, num uint32
+	if _, err := fmt.Sscanf(k.Path, "m/%d/%d", &chain, &num); err != nil {
+		return nil, fmt.Errorf("invalid derivation path %q: %w", k.Path, err)
+	}
+
+	return w.derivedKey(chain, num)
+}
+
+// derivedKey derives the private key at m/chain/num from the master key.
+func (w *Wallet) derivedKey(chain, num uint32) (*ec.PrivateKey, error) {
+	hdKey, err := bip32.NewKeyFromString(w.XPriv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+
+	priv, err := bip32.GetPrivateKeyByPath(hdKey, chain, num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive m/%d/%d: %w", chain, num, err)
+	}
+
+	return priv, nil
+}
+
+// Key returns the wallet key for an address, or nil if the address is not in the wallet.
+func (w *Wallet) Key(address string) *Key {
+	for _, k := range w.Keys {
+		if k.Address == address {
+			return k
+		}
+	}
+	return nil
+}
+
+// Addresses returns every wallet address in the order they were added.
+func (w *Wallet) Addresses() []string {
+	addrs := make([]string, len(w.Keys))
+	for i, k := range w.Keys {
+		addrs[i] = k.Address
+	}
+	return addrs
+}
+
+// Balance returns the confirmed and unconfirmed totals of the tracked UTXOs.
+func (w *Wallet) Balance() (confirmed, unconfirmed uint64) {
+	for _, u := range w.UTXOs {
+		if u.Height > 0 {
+			confirmed += u.Value
+		} else {
+			unconfirmed += u.Value
+		}
+	}
+	return confirmed, unconfirmed
+}
+
+// UpdateUTXOs replaces the tracked UTXOs of an address with a freshly fetched set.
+// Transactions the wallet has not seen before are recorded as receives.
+func (w *Wallet) UpdateUTXOs(address string, utxos []*txbuilder.UTXO, now time.Time) {
+	known := make(map[string]bool, len(w.History))
+	for _, e := range w.History {
+		known[e.TxID] = true
+	}
+
+	kept := w.UTXOs[:0]
+	for _, u := range w.UTXOs {
+		if u.Address != address {
+			kept = append(kept, u)
+		}
+	}
+	w.UTXOs = kept
+
+	received := make(map[string]*Entry)
+	for _, u := range utxos {
+		w.UTXOs = append(w.UTXOs, &UTXO{UTXO: *u, Address: address})
+
+		if known[u.TxHash] {
+			continue
+		}
+		entry := received[u.TxHash]
+		if entry == nil {
+			entry = &Entry{TxID: u.TxHash, Type: EntryReceive, Address: address, Time: now}
+			received[u.TxHash] = entry
+			w.History = append(w.History, entry)
+		}
+		entry.Amount += u.Value
+	}
+}
+
+// Spendable returns the tracked UTXOs as builder UTXOs for coin selection.
+func (w *Wallet) Spendable() []*txbuilder.UTXO {
+	utxos := make([]*txbuilder.UTXO, len(w.UTXOs))
+	for i, u := range w.UTXOs {
+		utxos[i] = &u.UTXO
+	}
+	return utxos
+}
+
+// Inputs pairs selected UTXOs with the keys that unlock them.
+func (w *Wallet) Inputs(selected []*txbuilder.UTXO) ([]txbuilder.Input, error) {
+	owners := make(map[string]string, len(w.UTXOs))
+	for _, u := range w.UTXOs {
+		owners[outpoint(u.TxHash, u.TxPos)] = u.Address
+	}
+
+	signers := make(map[string]txbuilder.Input)
+	inputs := make([]txbuilder.Input, 0, len(selected))
+	for _, u := range selected {
+		address, ok := owners[outpoint(u.TxHash, u.TxPos)]
+		if !ok {
+			return nil, fmt.Errorf("UTXO %s:%d is not tracked by the wallet", u.TxHash, u.TxPos)
+		}
+
+		signer, ok := signers[address]
+		if !ok {
+			k := w.Key(address)
+			if k == nil {
+				return nil, fmt.Errorf("no key for address %s", address)
+			}
+			var err error
+			if signer.Key, err = w.PrivateKey(k); err != nil {
+				return nil, err
+			}
+			signer.Uncompressed = k.Uncompressed()
+			signers[address] = signer
+		}
+
+		signer.UTXO = u
+		inputs = append(inputs, signer)
+	}
+
+	return inputs, nil
+}
+
+// RecordSend applies a broadcast transaction to the wallet: spent UTXOs are
+// removed, outputs paying wallet addresses are tracked as unconfirmed UTXOs,
+// and a send entry is added to the history.
+func (w *Wallet) RecordSend(tx *transaction.Transaction, dest string, amount uint64, now time.Time) error {
+	txid := tx.TxID().String()
+
+	spent := make(map[string]bool, len(tx.Inputs))
+	var totalIn uint64
+	for _, in := range tx.Inputs {
+		op := outpoint(in.SourceTXID.String(), in.SourceTxOutIndex)
+		spent[op] = true
+		for _, u := range w.UTXOs {
+			if outpoint(u.TxHash, u.TxPos) == op {
+				totalIn += u.Value
+				break
+			}
+		}
+	}
+
+	kept := w.UTXOs[:0]
+	for _, u := range w.UTXOs {
+		if !spent[outpoint(u.TxHash, u.TxPos)] {
+			kept = append(kept, u)
+		}
+	}
+	w.UTXOs = kept
+
+	var totalOut uint64
+	for i, out := range tx.Outputs {
+		totalOut += out.Satoshis
+
+		address, err := w.outputAddress(out.LockingScript)
+		if err != nil {
+			return err
+		}
+		if address == "" {
+			continue
+		}
+		w.UTXOs = append(w.UTXOs, &UTXO{
+			UTXO:    txbuilder.UTXO{TxHash: txid, TxPos: uint32(i), Value: out.Satoshis},
+			Address: address,
+		})
+	}
+
+	var fee uint64
+	if totalIn > totalOut {
+		fee = totalIn - totalOut
+	}
+
+	w.History = append(w.History, &Entry{
+		TxID:    txid,
+		Type:    EntrySend,
+		Amount:  amount,
+		Fee:     fee,
+		Address: dest,
+		Time:    now,
+	})
+
+	return nil
+}
+
+// outputAddress returns the wallet address a P2PKH locking script pays, or ""
+// if it pays elsewhere.
+func (w *Wallet) outputAddress(lockingScript *script.Script) (string, error) {
+	if !lockingScript.IsP2PKH() {
+		return "", nil
+	}
+
+	pkh, err := lockingScript.PublicKeyHash()
+	if err != nil {
+		return "", fmt.Errorf("failed to read output script: %w", err)
+	}
+
+	addr, err := script.NewAddressFromPublicKeyHash(pkh, !w.Testnet())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode output address: %w", err)
+	}
+
+	if w.Key(addr.AddressString) == nil {
+		return "", nil
+	}
+	return addr.AddressString, nil
+}
+
+// outpoint formats a txid:vout key.
+func outpoint(txid string, vout uint32) string {
+	return fmt.Sprintf("%s:%d", txid, vout)
+}
@@ -0,0 +1,240 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrz1836/go-template/internal/txbuilder"
+)
+
+const (
+	testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	// testWIF is the mainnet WIF for private key 1
+	testWIF     = "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"
+	testWIFAddr = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+
+	// testUncompressedWIF is the same key flagged for the uncompressed public key
+	testUncompressedWIF  = "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+	testUncompressedAddr = "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"
+
+	// testTestnetWIF is the testnet WIF for private key 1
+	testTestnetWIF = "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestWallet(t *testing.T, testnet bool) *Wallet {
+	t.Helper()
+	w, err := New(testnet)
+	require.NoError(t, err)
+	return w
+}
+
+func TestDerive(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, false)
+
+	r0, err := w.Derive(false, "first", testNow)
+	require.NoError(t, err)
+	r1, err := w.Derive(false, "", testNow)
+	require.NoError(t, err)
+	c0, err := w.Derive(true, "", testNow)
+	require.NoError(t, err)
+
+	assert.Equal(t, "m/0/0", r0.Path)
+	assert.Equal(t, "m/0/1", r1.Path)
+	assert.Equal(t, "m/1/0", c0.Path)
+	assert.Equal(t, "first", r0.Label)
+	assert.False(t, r0.Change())
+	assert.True(t, c0.Change())
+	assert.Equal(t, uint32(2), w.NextReceive)
+	assert.Equal(t, uint32(1), w.NextChange)
+	assert.Equal(t, []string{r0.Address, r1.Address, c0.Address}, w.Addresses())
+	assert.NotEqual(t, r0.Address, r1.Address)
+
+	// The stored path must reproduce the address
+	priv, err := w.PrivateKey(r1)
+	require.NoError(t, err)
+	addr, err := script.NewAddressFromPublicKey(priv.PubKey(), true)
+	require.NoError(t, err)
+	assert.Equal(t, r1.Address, addr.AddressString)
+}
+
+func TestDeriveTestnet(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, true)
+	assert.True(t, w.Testnet())
+
+	k, err := w.Derive(false, "", testNow)
+	require.NoError(t, err)
+	assert.Contains(t, "mn", k.Address[:1])
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid WIF", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWallet(t, false)
+		k, err := w.Import(testWIF, "paper", testNow)
+		require.NoError(t, err)
+
+		assert.Equal(t, testWIFAddr, k.Address)
+		assert.True(t, k.Imported())
+		assert.Same(t, k, w.Key(testWIFAddr))
+
+		priv, err := w.PrivateKey(k)
+		require.NoError(t, err)
+		assert.Equal(t, testWIF, priv.Wif())
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWallet(t, false)
+		_, err := w.Import(testWIF, "", testNow)
+		require.NoError(t, err)
+		_, err = w.Import(testWIF, "", testNow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already in the wallet")
+	})
+
+	t.Run("invalid WIF", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWallet(t, false)
+		_, err := w.Import("not-a-wif", "", testNow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid WIF")
+	})
+
+	t.Run("wrong network", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWallet(t, true)
+		_, err := w.Import(testWIF, "", testNow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot import a mainnet WIF into a testnet wallet")
+	})
+
+	t.Run("uncompressed WIF", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWallet(t, false)
+		k, err := w.Import(testUncompressedWIF, "", testNow)
+		require.NoError(t, err)
+		assert.Equal(t, testUncompressedAddr, k.Address)
+		assert.True(t, k.Uncompressed())
+
+		w.UpdateUTXOs(k.Address, []*txbuilder.UTXO{
+			{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 1},
+		}, testNow)
+		inputs, err := w.Inputs(w.Spendable())
+		require.NoError(t, err)
+		require.Len(t, inputs, 1)
+		assert.True(t, inputs[0].Uncompressed)
+	})
+}
+
+func TestUpdateUTXOs(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, false)
+	a, err := w.Derive(false, "", testNow)
+	require.NoError(t, err)
+	b, err := w.Derive(false, "", testNow)
+	require.NoError(t, err)
+
+	w.UpdateUTXOs(a.Address, []*txbuilder.UTXO{
+		{TxHash: testTxID, TxPos: 0, Value: 1000, Height: 100},
+		{TxHash: testTxID, TxPos: 1, Value: 500, Height: 100},
+	}, testNow)
+	w.UpdateUTXOs(b.Address, []*txbuilder.UTXO{
+		{TxHash: "aa", TxPos: 0, Value: 250},
+	}, testNow)
+
+	confirmed, unconfirmed := w.Balance()
+	assert.Equal(t, uint64(1500), confirmed)
+	assert.Equal(t, uint64(250), unconfirmed)
+
+	require.Len(t, w.History, 2)
+	assert.Equal(t, EntryReceive, w.History[0].Type)
+	assert.Equal(t, uint64(1500), w.History[0].Amount)
+	assert.Equal(t, a.Address, w.History[0].Address)
+
+	// A refresh replaces the address's UTXOs without duplicating history
+	w.UpdateUTXOs(a.Address, []*txbuilder.UTXO{
+		{TxHash: testTxID, TxPos: 0, Value: 1000, Height: 100},
+	}, testNow)
+
+	confirmed, unconfirmed = w.Balance()
+	assert.Equal(t, uint64(1000), confirmed)
+	assert.Equal(t, uint64(250), unconfirmed)
+	assert.Len(t, w.History, 2)
+	assert.Len(t, w.UTXOs, 2)
+}
+
+func TestSendRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, false)
+	src, err := w.Import(testWIF, "", testNow)
+	require.NoError(t, err)
+	w.UpdateUTXOs(src.Address, []*txbuilder.UTXO{
+		{TxHash: testTxID, TxPos: 0, Value: 10000, Height: 1},
+	}, testNow)
+
+	change, err := w.Derive(true, "", testNow)
+	require.NoError(t, err)
+	changeAddr, err := script.NewAddressFromString(change.Address)
+	require.NoError(t, err)
+
+	destKey, err := ec.PrivateKeyFromHex("0000000000000000000000000000000000000000000000000000000000000002")
+	require.NoError(t, err)
+	dest, err := script.NewAddressFromPublicKey(destKey.PubKey(), true)
+	require.NoError(t, err)
+
+	builder := &txbuilder.Builder{FeePerKb: 100}
+	selected, err := builder.SelectUTXOs(w.Spendable(), 4000)
+	require.NoError(t, err)
+	inputs, err := w.Inputs(selected)
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+
+	tx, err := builder.Build(inputs, dest, 4000, 1, changeAddr)
+	require.NoError(t, err)
+	require.NoError(t, w.RecordSend(tx, dest.AddressString, 4000, testNow))
+
+	// The spent UTXO is gone and the change is tracked as unconfirmed
+	require.Len(t, w.UTXOs, 1)
+	assert.Equal(t, change.Address, w.UTXOs[0].Address)
+	assert.Equal(t, tx.TxID().String(), w.UTXOs[0].TxHash)
+
+	confirmed, unconfirmed := w.Balance()
+	assert.Equal(t, uint64(0), confirmed)
+	assert.Equal(t, uint64(10000-4000-txbuilder.MinFee), unconfirmed)
+
+	last := w.History[len(w.History)-1]
+	assert.Equal(t, EntrySend, last.Type)
+	assert.Equal(t, uint64(4000), last.Amount)
+	assert.Equal(t, uint64(txbuilder.MinFee), last.Fee)
+	assert.Equal(t, dest.AddressString, last.Address)
+}
+
+func TestInputsUntracked(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t, false)
+	_, err := w.Inputs([]*txbuilder.UTXO{{TxHash: testTxID, TxPos: 0, Value: 1}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not tracked")
+}